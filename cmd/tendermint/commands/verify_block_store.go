@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/spf13/cobra"
+
+	cfg "github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/store"
+)
+
+var (
+	verifyFromHeight int64
+	verifyToHeight   int64
+)
+
+// VerifyBlockStoreCmd checks the block store for missing or corrupt blocks.
+// The node must not be running, since it holds a lock on the database.
+var VerifyBlockStoreCmd = &cobra.Command{
+	Use:   "verify-block-store",
+	Short: "Check the block store for missing or corrupt blocks",
+	RunE:  verifyBlockStore,
+}
+
+func init() {
+	VerifyBlockStoreCmd.Flags().Int64Var(&verifyFromHeight, "from-height", 1, "first height to check")
+	VerifyBlockStoreCmd.Flags().Int64Var(&verifyToHeight, "to-height", math.MaxInt64, "last height to check")
+}
+
+func verifyBlockStore(cmd *cobra.Command, args []string) error {
+	db, err := cfg.DefaultDBProvider(&cfg.DBContext{ID: "blockstore", Config: config})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	blockStore := store.NewBlockStore(db)
+	problems, err := blockStore.Verify(verifyFromHeight, verifyToHeight)
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		logger.Error("found missing or corrupt blocks", "heights", problems)
+		return fmt.Errorf("found %d missing or corrupt blocks", len(problems))
+	}
+
+	logger.Info("verified block store", "base", blockStore.Base(), "height", blockStore.Height())
+	return nil
+}
@@ -30,6 +30,12 @@ const (
 
 	MempoolV0 = "v0"
 	MempoolV1 = "v1"
+
+	MempoolTxOrderPriority = "priority"
+	MempoolTxOrderGasPrice = "gas-price"
+
+	VoteGossipPush     = "push"
+	VoteGossipBitArray = "bit-array"
 )
 
 // NOTE: Most of the structs & relevant comments + the
@@ -74,6 +80,7 @@ type Config struct {
 	StateSync       *StateSyncConfig       `mapstructure:"statesync"`
 	FastSync        *FastSyncConfig        `mapstructure:"fastsync"`
 	Consensus       *ConsensusConfig       `mapstructure:"consensus"`
+	Evidence        *EvidenceConfig        `mapstructure:"evidence"`
 	TxIndex         *TxIndexConfig         `mapstructure:"tx-index"`
 	Instrumentation *InstrumentationConfig `mapstructure:"instrumentation"`
 	PrivValidator   *PrivValidatorConfig   `mapstructure:"priv-validator"`
@@ -89,6 +96,7 @@ func DefaultConfig() *Config {
 		StateSync:       DefaultStateSyncConfig(),
 		FastSync:        DefaultFastSyncConfig(),
 		Consensus:       DefaultConsensusConfig(),
+		Evidence:        DefaultEvidenceConfig(),
 		TxIndex:         DefaultTxIndexConfig(),
 		Instrumentation: DefaultInstrumentationConfig(),
 		PrivValidator:   DefaultPrivValidatorConfig(),
@@ -112,6 +120,7 @@ func TestConfig() *Config {
 		StateSync:       TestStateSyncConfig(),
 		FastSync:        TestFastSyncConfig(),
 		Consensus:       TestConsensusConfig(),
+		Evidence:        TestEvidenceConfig(),
 		TxIndex:         TestTxIndexConfig(),
 		Instrumentation: TestInstrumentationConfig(),
 		PrivValidator:   DefaultPrivValidatorConfig(),
@@ -153,6 +162,9 @@ func (cfg *Config) ValidateBasic() error {
 	if err := cfg.Consensus.ValidateBasic(); err != nil {
 		return fmt.Errorf("error in [consensus] section: %w", err)
 	}
+	if err := cfg.Evidence.ValidateBasic(); err != nil {
+		return fmt.Errorf("error in [evidence] section: %w", err)
+	}
 	if err := cfg.Instrumentation.ValidateBasic(); err != nil {
 		return fmt.Errorf("error in [instrumentation] section: %w", err)
 	}
@@ -616,6 +628,15 @@ type P2PConfig struct { //nolint: maligned
 	// attempts per IP address.
 	MaxIncomingConnectionAttempts uint `mapstructure:"max-incoming-connection-attempts"`
 
+	// MaxIncomingHandshakes limits the number of incoming connections that can
+	// be handshaking at once. Connections beyond it wait briefly for a slot, and
+	// are dropped otherwise. Established peers don't count towards it.
+	MaxIncomingHandshakes uint `mapstructure:"max-incoming-handshakes"`
+
+	// IncomingHandshakeWait is how long an incoming connection waits for a
+	// handshake slot before it is dropped.
+	IncomingHandshakeWait time.Duration `mapstructure:"incoming-handshake-wait"`
+
 	// List of node IDs, to which a connection will be (re)established ignoring any existing limits
 	UnconditionalPeerIDs string `mapstructure:"unconditional-peer-ids"`
 
@@ -644,10 +665,37 @@ type P2PConfig struct { //nolint: maligned
 	// Toggle to disable guard against peers connecting from the same ip.
 	AllowDuplicateIP bool `mapstructure:"allow-duplicate-ip"`
 
+	// Maximum time a peer address can go without a successful dial before it
+	// is removed from the peer store and no longer gossiped. Mostly useful for
+	// seed nodes. 0 disables expiry.
+	AddressTTL time.Duration `mapstructure:"address-ttl"`
+
+	// Only gossip peer addresses that this node has successfully dialed at
+	// least once. Other addresses are still dialed, but not gossiped.
+	AdvertiseDialedOnly bool `mapstructure:"advertise-dialed-only"`
+
 	// Peer connection configuration.
 	HandshakeTimeout time.Duration `mapstructure:"handshake-timeout"`
 	DialTimeout      time.Duration `mapstructure:"dial-timeout"`
 
+	// Dial timeouts for persistent and bootstrap peers, so that e.g. persistent
+	// peers can be given longer than discovered ones. 0 uses DialTimeout.
+	PersistentPeersDialTimeout time.Duration `mapstructure:"persistent-peers-dial-timeout"`
+	BootstrapPeersDialTimeout  time.Duration `mapstructure:"bootstrap-peers-dial-timeout"`
+
+	// Maximum difference between a peer's clock and ours, as measured when
+	// handshaking, beyond which the peer is rejected. 0 disables the check.
+	MaxClockOffset time.Duration `mapstructure:"max-clock-offset"`
+
+	// Interval at which connected peers are pinged, to detect dead connections
+	// at the application level. A peer that doesn't respond to MaxMissedPongs
+	// consecutive pings within PingTimeout is disconnected. 0 disables pings.
+	// Only supported by the new p2p stack, and only peers that enable it too are
+	// pinged.
+	PingInterval   time.Duration `mapstructure:"ping-interval"`
+	PingTimeout    time.Duration `mapstructure:"ping-timeout"`
+	MaxMissedPongs uint          `mapstructure:"max-missed-pongs"`
+
 	// Testing params.
 	// Force dial to fail
 	TestDialFail bool `mapstructure:"test-dial-fail"`
@@ -674,6 +722,8 @@ func DefaultP2PConfig() *P2PConfig {
 		MaxNumOutboundPeers:           10,
 		MaxConnections:                64,
 		MaxIncomingConnectionAttempts: 100,
+		MaxIncomingHandshakes:         64,
+		IncomingHandshakeWait:         time.Second,
 		PersistentPeersMaxDialPeriod:  0 * time.Second,
 		FlushThrottleTimeout:          100 * time.Millisecond,
 		// The MTU (Maximum Transmission Unit) for Ethernet is 1500 bytes.
@@ -688,6 +738,10 @@ func DefaultP2PConfig() *P2PConfig {
 		AllowDuplicateIP:        false,
 		HandshakeTimeout:        20 * time.Second,
 		DialTimeout:             3 * time.Second,
+		MaxClockOffset:          10 * time.Second,
+		PingInterval:            0,
+		PingTimeout:             10 * time.Second,
+		MaxMissedPongs:          3,
 		TestDialFail:            false,
 		QueueType:               "priority",
 	}
@@ -719,6 +773,9 @@ func (cfg *P2PConfig) ValidateBasic() error {
 	if cfg.FlushThrottleTimeout < 0 {
 		return errors.New("flush-throttle-timeout can't be negative")
 	}
+	if cfg.IncomingHandshakeWait < 0 {
+		return errors.New("incoming-handshake-wait can't be negative")
+	}
 	if cfg.PersistentPeersMaxDialPeriod < 0 {
 		return errors.New("persistent-peers-max-dial-period can't be negative")
 	}
@@ -731,6 +788,30 @@ func (cfg *P2PConfig) ValidateBasic() error {
 	if cfg.RecvRate < 0 {
 		return errors.New("recv-rate can't be negative")
 	}
+	if cfg.AddressTTL < 0 {
+		return errors.New("address-ttl can't be negative")
+	}
+	if cfg.DialTimeout < 0 {
+		return errors.New("dial-timeout can't be negative")
+	}
+	if cfg.PersistentPeersDialTimeout < 0 {
+		return errors.New("persistent-peers-dial-timeout can't be negative")
+	}
+	if cfg.BootstrapPeersDialTimeout < 0 {
+		return errors.New("bootstrap-peers-dial-timeout can't be negative")
+	}
+	if cfg.MaxClockOffset < 0 {
+		return errors.New("max-clock-offset can't be negative")
+	}
+	if cfg.PingInterval < 0 {
+		return errors.New("ping-interval can't be negative")
+	}
+	if cfg.PingInterval > 0 && cfg.PingTimeout <= 0 {
+		return errors.New("ping-timeout must be positive when ping-interval is set")
+	}
+	if cfg.PingInterval > 0 && cfg.MaxMissedPongs == 0 {
+		return errors.New("max-missed-pongs can't be 0 when ping-interval is set")
+	}
 	return nil
 }
 
@@ -743,6 +824,9 @@ type MempoolConfig struct {
 	RootDir   string `mapstructure:"home"`
 	Recheck   bool   `mapstructure:"recheck"`
 	Broadcast bool   `mapstructure:"broadcast"`
+	// Recheck all transactions after a block that changed the validator set,
+	// even if recheck is disabled, evicting the ones that are no longer valid.
+	RecheckOnValidatorSetChange bool `mapstructure:"recheck-on-validator-set-change"`
 	// Maximum number of transactions in the mempool
 	Size int `mapstructure:"size"`
 	// Limit the total size of all txs in the mempool.
@@ -751,6 +835,17 @@ type MempoolConfig struct {
 	MaxTxsBytes int64 `mapstructure:"max-txs-bytes"`
 	// Size of the cache (used to filter transactions we saw earlier) in transactions
 	CacheSize int `mapstructure:"cache-size"`
+	// Size of the cache of transactions recently received from peers, used to
+	// avoid checking them again and gossiping them back to the peers they
+	// were received from.
+	GossipCacheSize int `mapstructure:"gossip-cache-size"`
+	// How long a transaction is kept in the gossip cache after it was first
+	// received. A transaction received again after that is treated as new.
+	// 0 keeps transactions until the cache is full.
+	GossipCacheTTL time.Duration `mapstructure:"gossip-cache-ttl"`
+	// Number of recent transaction rejections to retain, along with their
+	// reasons, so that they can be queried by tx hash via RPC. 0 disables it.
+	RejectionCacheSize int `mapstructure:"rejection-cache-size"`
 	// Do not remove invalid transactions from the cache (default: false)
 	// Set to true if it's not possible for any invalid transaction to become
 	// valid again in the future.
@@ -758,10 +853,26 @@ type MempoolConfig struct {
 	// Maximum size of a single transaction
 	// NOTE: the max size of a tx transmitted over the network is {max-tx-bytes}.
 	MaxTxBytes int `mapstructure:"max-tx-bytes"`
+	// Minimum gas price of the transactions accepted into the mempool, where
+	// the gas price of a transaction is the priority (i.e. the fee) the app
+	// reports for it in CheckTx divided by its gas wanted. 0 disables it.
+	MinGasPrice float64 `mapstructure:"min-gas-price"`
+	// Minimum gas price of the transactions the v1 mempool reaps for a block
+	// this node proposes. Unlike MinGasPrice, cheaper transactions are kept
+	// in the mempool, e.g. to be included once congestion is over. 0
+	// disables it.
+	ProposalMinGasPrice float64 `mapstructure:"proposal-min-gas-price"`
+	// Order in which the v1 mempool reaps transactions for a block, either
+	// "priority" or "gas-price".
+	TxOrder string `mapstructure:"tx-order"`
 	// Maximum size of a batch of transactions to send to a peer
 	// Including space needed by encoding (one varint per transaction).
 	// XXX: Unused due to https://github.com/tendermint/tendermint/issues/5796
 	MaxBatchBytes int `mapstructure:"max-batch-bytes"`
+	// Path to a file where the mempool will be persisted on shutdown and
+	// restored from (re-running CheckTx on each transaction) on startup.
+	// An empty path disables the snapshot.
+	SnapshotFile string `mapstructure:"snapshot-file"`
 }
 
 // DefaultMempoolConfig returns a default configuration for the Tendermint mempool.
@@ -772,13 +883,25 @@ func DefaultMempoolConfig() *MempoolConfig {
 		Broadcast: true,
 		// Each signature verification takes .5ms, Size reduced until we implement
 		// ABCI Recheck
-		Size:        5000,
-		MaxTxsBytes: 1024 * 1024 * 1024, // 1GB
-		CacheSize:   10000,
-		MaxTxBytes:  1024 * 1024, // 1MB
+		Size:            5000,
+		MaxTxsBytes:     1024 * 1024 * 1024, // 1GB
+		CacheSize:       10000,
+		GossipCacheSize: 10000,
+		GossipCacheTTL:  time.Minute,
+		MaxTxBytes:      1024 * 1024, // 1MB
+		TxOrder:         MempoolTxOrderPriority,
 	}
 }
 
+// SnapshotFilePath returns the full path of the mempool snapshot file, or an
+// empty string if the snapshot is disabled.
+func (cfg *MempoolConfig) SnapshotFilePath() string {
+	if cfg.SnapshotFile == "" {
+		return ""
+	}
+	return rootify(cfg.SnapshotFile, cfg.RootDir)
+}
+
 // TestMempoolConfig returns a configuration for testing the Tendermint mempool
 func TestMempoolConfig() *MempoolConfig {
 	cfg := DefaultMempoolConfig()
@@ -798,9 +921,29 @@ func (cfg *MempoolConfig) ValidateBasic() error {
 	if cfg.CacheSize < 0 {
 		return errors.New("cache-size can't be negative")
 	}
+	if cfg.GossipCacheSize < 0 {
+		return errors.New("gossip-cache-size can't be negative")
+	}
+	if cfg.GossipCacheTTL < 0 {
+		return errors.New("gossip-cache-ttl can't be negative")
+	}
+	if cfg.RejectionCacheSize < 0 {
+		return errors.New("rejection-cache-size can't be negative")
+	}
 	if cfg.MaxTxBytes < 0 {
 		return errors.New("max-tx-bytes can't be negative")
 	}
+	if cfg.MinGasPrice < 0 {
+		return errors.New("min-gas-price can't be negative")
+	}
+	if cfg.ProposalMinGasPrice < 0 {
+		return errors.New("proposal-min-gas-price can't be negative")
+	}
+	switch cfg.TxOrder {
+	case MempoolTxOrderPriority, MempoolTxOrderGasPrice:
+	default:
+		return fmt.Errorf("unknown tx-order %q", cfg.TxOrder)
+	}
 	return nil
 }
 
@@ -809,15 +952,26 @@ func (cfg *MempoolConfig) ValidateBasic() error {
 
 // StateSyncConfig defines the configuration for the Tendermint state sync service
 type StateSyncConfig struct {
-	Enable              bool          `mapstructure:"enable"`
-	TempDir             string        `mapstructure:"temp-dir"`
-	RPCServers          []string      `mapstructure:"rpc-servers"`
-	TrustPeriod         time.Duration `mapstructure:"trust-period"`
-	TrustHeight         int64         `mapstructure:"trust-height"`
-	TrustHash           string        `mapstructure:"trust-hash"`
-	DiscoveryTime       time.Duration `mapstructure:"discovery-time"`
-	ChunkRequestTimeout time.Duration `mapstructure:"chunk-request-timeout"`
-	Fetchers            int32         `mapstructure:"fetchers"`
+	Enable                   bool          `mapstructure:"enable"`
+	DryRun                   bool          `mapstructure:"dry-run"`
+	TempDir                  string        `mapstructure:"temp-dir"`
+	ChunkDir                 string        `mapstructure:"chunk-dir"`
+	RPCServers               []string      `mapstructure:"rpc-servers"`
+	TrustPeriod              time.Duration `mapstructure:"trust-period"`
+	TrustHeight              int64         `mapstructure:"trust-height"`
+	TrustHash                string        `mapstructure:"trust-hash"`
+	DiscoveryTime            time.Duration `mapstructure:"discovery-time"`
+	ChunkRequestTimeout      time.Duration `mapstructure:"chunk-request-timeout"`
+	Fetchers                 int32         `mapstructure:"fetchers"`
+	MinFetchers              int32         `mapstructure:"min-fetchers"`
+	ChunkFetchers            int32         `mapstructure:"chunk-fetchers"`
+	VerifyWorkers            int32         `mapstructure:"verify-workers"`
+	VerifyTimeout            time.Duration `mapstructure:"verify-timeout"`
+	Witnesses                int32         `mapstructure:"witnesses"`
+	MinSnapshotProviders     int32         `mapstructure:"min-snapshot-providers"`
+	SnapshotProvidersTimeout time.Duration `mapstructure:"snapshot-providers-timeout"`
+	ShufflePeers             bool          `mapstructure:"shuffle-peers"`
+	BackgroundBackfill       bool          `mapstructure:"background-backfill"`
 }
 
 func (cfg *StateSyncConfig) TrustHashBytes() []byte {
@@ -832,10 +986,12 @@ func (cfg *StateSyncConfig) TrustHashBytes() []byte {
 // DefaultStateSyncConfig returns a default configuration for the state sync service
 func DefaultStateSyncConfig() *StateSyncConfig {
 	return &StateSyncConfig{
-		TrustPeriod:         168 * time.Hour,
-		DiscoveryTime:       15 * time.Second,
-		ChunkRequestTimeout: 15 * time.Second,
-		Fetchers:            4,
+		TrustPeriod:              168 * time.Hour,
+		DiscoveryTime:            15 * time.Second,
+		ChunkRequestTimeout:      15 * time.Second,
+		Fetchers:                 4,
+		VerifyTimeout:            1 * time.Minute,
+		SnapshotProvidersTimeout: 2 * time.Minute,
 	}
 }
 
@@ -889,6 +1045,38 @@ func (cfg *StateSyncConfig) ValidateBasic() error {
 		if cfg.Fetchers <= 0 {
 			return errors.New("fetchers is required")
 		}
+
+		if cfg.MinFetchers < 0 {
+			return errors.New("min-fetchers can't be negative")
+		}
+
+		if cfg.MinFetchers > cfg.Fetchers {
+			return errors.New("min-fetchers can't be greater than fetchers")
+		}
+
+		if cfg.ChunkFetchers < 0 {
+			return errors.New("chunk-fetchers can't be negative")
+		}
+
+		if cfg.VerifyWorkers < 0 {
+			return errors.New("verify-workers can't be negative")
+		}
+
+		if cfg.VerifyTimeout < 0 {
+			return errors.New("verify-timeout can't be negative")
+		}
+
+		if cfg.Witnesses < 0 {
+			return errors.New("witnesses can't be negative")
+		}
+
+		if cfg.MinSnapshotProviders < 0 {
+			return errors.New("min-snapshot-providers can't be negative")
+		}
+
+		if cfg.SnapshotProvidersTimeout < 0 {
+			return errors.New("snapshot-providers-timeout can't be negative")
+		}
 	}
 
 	return nil
@@ -936,6 +1124,13 @@ type ConsensusConfig struct {
 	WalPath string `mapstructure:"wal-file"`
 	walFile string // overrides WalPath if set
 
+	// Total WAL size in bytes above which a warning is logged. 0 disables
+	// the check.
+	WalMaxSize int64 `mapstructure:"wal-max-size"`
+	// Remove the WAL files preceding the last height boundary once
+	// WalMaxSize is exceeded.
+	WalForceCompaction bool `mapstructure:"wal-force-compaction"`
+
 	// TODO: remove timeout configs, these should be global not local
 	// How long we wait for a proposal block before prevoting nil
 	TimeoutPropose time.Duration `mapstructure:"timeout-propose"`
@@ -949,6 +1144,10 @@ type ConsensusConfig struct {
 	TimeoutPrecommit time.Duration `mapstructure:"timeout-precommit"`
 	// How much the timeout-precommit increases with each round
 	TimeoutPrecommitDelta time.Duration `mapstructure:"timeout-precommit-delta"`
+	// Maximum random jitter added to the propose, prevote and precommit
+	// timeouts, as a fraction of the timeout, so that validators don't all
+	// time out at once. 0 disables the jitter.
+	TimeoutJitter float64 `mapstructure:"timeout-jitter"`
 	// How long we wait after committing a block, before starting on the new
 	// height (this gives us a chance to receive some more precommits, even
 	// though we already have +2/3).
@@ -966,6 +1165,55 @@ type ConsensusConfig struct {
 	PeerQueryMaj23SleepDuration time.Duration `mapstructure:"peer-query-maj23-sleep-duration"`
 
 	DoubleSignCheckHeight int64 `mapstructure:"double-sign-check-height"`
+
+	// Maximum number of verified vote signatures to cache, so that a vote
+	// received several times is only verified once. 0 disables the cache.
+	SignatureCacheSize int `mapstructure:"signature-cache-size"`
+
+	// Maximum number of proposals for the next height to buffer until the
+	// height is reached. Proposals not signed by the proposer of the next
+	// height, or for later heights, are dropped. 0 drops them all.
+	ProposalBufferSize int `mapstructure:"proposal-buffer-size"`
+
+	// Number of heights over which to audit that proposers are selected in
+	// proportion to their voting power. 0 disables the audit.
+	ProposerAuditWindow int `mapstructure:"proposer-audit-window"`
+
+	// Maximum number of recently added votes to remember, so that exact
+	// duplicates of them are dropped without being processed again. 0
+	// disables the window.
+	VoteReplayWindow int `mapstructure:"vote-replay-window"`
+
+	// Maximum number of heights the application may be behind the block store
+	// on startup, e.g. after the application rolled its state back, for the
+	// missing blocks to be replayed. Beyond it, the handshake fails instead of
+	// replaying them. 0 disables the check.
+	MaxRewindDepth int64 `mapstructure:"max-rewind-depth"`
+
+	// If true, the last block replayed from the block store on startup is
+	// applied without verifying the signatures of its commit again, as they
+	// were verified before it was saved. The blocks replayed before it are
+	// additionally checked to hash to their IDs and link up, without verifying
+	// signatures. Blocks received from peers are always fully verified.
+	ReplaySkipSignatureVerification bool `mapstructure:"replay-skip-signature-verification"`
+
+	// Number of heights a validator must lag behind the majority of its peers
+	// for it to stop proposing and voting and catch up through fast sync (or,
+	// with a fast sync version that can't resume, on the blocks its peers
+	// gossip). Only peers that have served us verified votes or block parts
+	// count. It resumes once the lag is down to half of it. 0 disables the
+	// check.
+	CatchUpLag int64 `mapstructure:"catch-up-lag"`
+
+	// Minimum number of peers that must count towards the lag for the
+	// validator to catch up, so that a single peer can't make it stop voting.
+	CatchUpMinPeers int `mapstructure:"catch-up-min-peers"`
+
+	// How votes for the current height are gossiped: "push" sends peers the
+	// votes they are not known to have, "bit-array" periodically sends peers
+	// bit arrays of the votes we have, and only sends the votes peers report
+	// missing in theirs.
+	VoteGossip string `mapstructure:"vote-gossip"`
 }
 
 // DefaultConsensusConfig returns a default configuration for the consensus service
@@ -985,6 +1233,11 @@ func DefaultConsensusConfig() *ConsensusConfig {
 		PeerGossipSleepDuration:     100 * time.Millisecond,
 		PeerQueryMaj23SleepDuration: 2000 * time.Millisecond,
 		DoubleSignCheckHeight:       int64(0),
+		SignatureCacheSize:          10000,
+		ProposalBufferSize:          10,
+		VoteReplayWindow:            10000,
+		CatchUpMinPeers:             2,
+		VoteGossip:                  VoteGossipPush,
 	}
 }
 
@@ -1071,12 +1324,39 @@ func (cfg *ConsensusConfig) ValidateBasic() error {
 	if cfg.TimeoutPrecommitDelta < 0 {
 		return errors.New("timeout-precommit-delta can't be negative")
 	}
+	if cfg.TimeoutJitter < 0 || cfg.TimeoutJitter > 1 {
+		return errors.New("timeout-jitter must be between 0 and 1")
+	}
 	if cfg.TimeoutCommit < 0 {
 		return errors.New("timeout-commit can't be negative")
 	}
 	if cfg.CreateEmptyBlocksInterval < 0 {
 		return errors.New("create-empty-blocks-interval can't be negative")
 	}
+	if cfg.WalMaxSize < 0 {
+		return errors.New("wal-max-size can't be negative")
+	}
+	if cfg.SignatureCacheSize < 0 {
+		return errors.New("signature-cache-size can't be negative")
+	}
+	if cfg.ProposalBufferSize < 0 {
+		return errors.New("proposal-buffer-size can't be negative")
+	}
+	if cfg.ProposerAuditWindow < 0 {
+		return errors.New("proposer-audit-window can't be negative")
+	}
+	if cfg.VoteReplayWindow < 0 {
+		return errors.New("vote-replay-window can't be negative")
+	}
+	if cfg.MaxRewindDepth < 0 {
+		return errors.New("max-rewind-depth can't be negative")
+	}
+	if cfg.CatchUpLag < 0 {
+		return errors.New("catch-up-lag can't be negative")
+	}
+	if cfg.CatchUpMinPeers < 0 {
+		return errors.New("catch-up-min-peers can't be negative")
+	}
 	if cfg.PeerGossipSleepDuration < 0 {
 		return errors.New("peer-gossip-sleep-duration can't be negative")
 	}
@@ -1086,6 +1366,50 @@ func (cfg *ConsensusConfig) ValidateBasic() error {
 	if cfg.DoubleSignCheckHeight < 0 {
 		return errors.New("double-sign-check-height can't be negative")
 	}
+	switch cfg.VoteGossip {
+	case VoteGossipPush, VoteGossipBitArray:
+	default:
+		return fmt.Errorf("unknown vote-gossip mode %q", cfg.VoteGossip)
+	}
+	return nil
+}
+
+//-----------------------------------------------------------------------------
+// EvidenceConfig
+
+// EvidenceConfig defines the configuration for the evidence pool and reactor.
+type EvidenceConfig struct {
+	// StrictTimestamps rejects evidence whose timestamp does not match the
+	// time of the committed block at its height. By default, such evidence is
+	// rectified and stored instead.
+	StrictTimestamps bool `mapstructure:"strict-timestamps"`
+
+	// BroadcastInterval is how often the evidence that is still pending is
+	// gossiped again to each peer.
+	BroadcastInterval time.Duration `mapstructure:"broadcast-interval"`
+}
+
+// DefaultEvidenceConfig returns a default configuration for the evidence pool
+// and reactor.
+func DefaultEvidenceConfig() *EvidenceConfig {
+	return &EvidenceConfig{
+		StrictTimestamps:  false,
+		BroadcastInterval: 10 * time.Second,
+	}
+}
+
+// TestEvidenceConfig returns a configuration for the evidence pool and
+// reactor to be used for testing.
+func TestEvidenceConfig() *EvidenceConfig {
+	return DefaultEvidenceConfig()
+}
+
+// ValidateBasic performs basic validation (checking param bounds, etc.) and
+// returns an error if any check fails.
+func (cfg *EvidenceConfig) ValidateBasic() error {
+	if cfg.BroadcastInterval < 0 {
+		return errors.New("broadcast-interval can't be negative")
+	}
 	return nil
 }
 
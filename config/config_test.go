@@ -93,6 +93,11 @@ func TestP2PConfigValidateBasic(t *testing.T) {
 		"MaxPacketMsgPayloadSize",
 		"SendRate",
 		"RecvRate",
+		"DialTimeout",
+		"PersistentPeersDialTimeout",
+		"BootstrapPeersDialTimeout",
+		"MaxClockOffset",
+		"PingInterval",
 	}
 
 	for _, fieldName := range fieldsToTest {
@@ -100,6 +105,15 @@ func TestP2PConfigValidateBasic(t *testing.T) {
 		assert.Error(t, cfg.ValidateBasic())
 		reflect.ValueOf(cfg).Elem().FieldByName(fieldName).SetInt(0)
 	}
+
+	// pings need a timeout and a number of missed pongs
+	cfg.PingInterval = time.Second
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.PingTimeout = 0
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.PingTimeout = time.Second
+	cfg.MaxMissedPongs = 0
+	assert.Error(t, cfg.ValidateBasic())
 }
 
 func TestMempoolConfigValidateBasic(t *testing.T) {
@@ -110,6 +124,9 @@ func TestMempoolConfigValidateBasic(t *testing.T) {
 		"Size",
 		"MaxTxsBytes",
 		"CacheSize",
+		"GossipCacheSize",
+		"GossipCacheTTL",
+		"RejectionCacheSize",
 		"MaxTxBytes",
 	}
 
@@ -118,6 +135,19 @@ func TestMempoolConfigValidateBasic(t *testing.T) {
 		assert.Error(t, cfg.ValidateBasic())
 		reflect.ValueOf(cfg).Elem().FieldByName(fieldName).SetInt(0)
 	}
+
+	cfg.MinGasPrice = -0.5
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.MinGasPrice = 0
+
+	cfg.ProposalMinGasPrice = -0.5
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.ProposalMinGasPrice = 0
+
+	cfg.TxOrder = MempoolTxOrderGasPrice
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.TxOrder = "nonce"
+	assert.Error(t, cfg.ValidateBasic())
 }
 
 func TestStateSyncConfigValidateBasic(t *testing.T) {
@@ -162,6 +192,23 @@ func TestConsensusConfig_ValidateBasic(t *testing.T) {
 		"PeerQueryMaj23SleepDuration":          {func(c *ConsensusConfig) { c.PeerQueryMaj23SleepDuration = time.Second }, false},
 		"PeerQueryMaj23SleepDuration negative": {func(c *ConsensusConfig) { c.PeerQueryMaj23SleepDuration = -1 }, true},
 		"DoubleSignCheckHeight negative":       {func(c *ConsensusConfig) { c.DoubleSignCheckHeight = -1 }, true},
+		"WalMaxSize negative":                  {func(c *ConsensusConfig) { c.WalMaxSize = -1 }, true},
+		"SignatureCacheSize disabled":          {func(c *ConsensusConfig) { c.SignatureCacheSize = 0 }, false},
+		"SignatureCacheSize negative":          {func(c *ConsensusConfig) { c.SignatureCacheSize = -1 }, true},
+		"ProposalBufferSize disabled":          {func(c *ConsensusConfig) { c.ProposalBufferSize = 0 }, false},
+		"ProposalBufferSize negative":          {func(c *ConsensusConfig) { c.ProposalBufferSize = -1 }, true},
+		"ProposerAuditWindow":                  {func(c *ConsensusConfig) { c.ProposerAuditWindow = 1000 }, false},
+		"ProposerAuditWindow negative":         {func(c *ConsensusConfig) { c.ProposerAuditWindow = -1 }, true},
+		"VoteReplayWindow disabled":            {func(c *ConsensusConfig) { c.VoteReplayWindow = 0 }, false},
+		"VoteReplayWindow negative":            {func(c *ConsensusConfig) { c.VoteReplayWindow = -1 }, true},
+		"MaxRewindDepth":                       {func(c *ConsensusConfig) { c.MaxRewindDepth = 100 }, false},
+		"MaxRewindDepth negative":              {func(c *ConsensusConfig) { c.MaxRewindDepth = -1 }, true},
+		"CatchUpMinPeers negative":             {func(c *ConsensusConfig) { c.CatchUpMinPeers = -1 }, true},
+		"TimeoutJitter":                        {func(c *ConsensusConfig) { c.TimeoutJitter = 0.1 }, false},
+		"TimeoutJitter negative":               {func(c *ConsensusConfig) { c.TimeoutJitter = -0.1 }, true},
+		"TimeoutJitter above 1":                {func(c *ConsensusConfig) { c.TimeoutJitter = 1.1 }, true},
+		"VoteGossip bit-array":                 {func(c *ConsensusConfig) { c.VoteGossip = VoteGossipBitArray }, false},
+		"VoteGossip unknown":                   {func(c *ConsensusConfig) { c.VoteGossip = "pull" }, true},
 	}
 	for desc, tc := range testcases {
 		tc := tc // appease linter
@@ -179,6 +226,15 @@ func TestConsensusConfig_ValidateBasic(t *testing.T) {
 	}
 }
 
+func TestEvidenceConfigValidateBasic(t *testing.T) {
+	cfg := TestEvidenceConfig()
+	assert.NoError(t, cfg.ValidateBasic())
+
+	// tamper with broadcast interval
+	cfg.BroadcastInterval = -1
+	assert.Error(t, cfg.ValidateBasic())
+}
+
 func TestInstrumentationConfigValidateBasic(t *testing.T) {
 	cfg := TestInstrumentationConfig()
 	assert.NoError(t, cfg.ValidateBasic())
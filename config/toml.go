@@ -326,6 +326,13 @@ max-connections = {{ .P2P.MaxConnections }}
 # Rate limits the number of incoming connection attempts per IP address.
 max-incoming-connection-attempts = {{ .P2P.MaxIncomingConnectionAttempts }}
 
+# Maximum number of incoming connections handshaking at once. Connections beyond
+# it wait briefly for a handshake to complete, and are dropped otherwise.
+max-incoming-handshakes = {{ .P2P.MaxIncomingHandshakes }}
+
+# How long an incoming connection waits for a handshake slot before it is dropped.
+incoming-handshake-wait = "{{ .P2P.IncomingHandshakeWait }}"
+
 # List of node IDs, to which a connection will be (re)established ignoring any existing limits
 unconditional-peer-ids = "{{ .P2P.UnconditionalPeerIDs }}"
 
@@ -354,10 +361,37 @@ private-peer-ids = "{{ .P2P.PrivatePeerIDs }}"
 # Toggle to disable guard against peers connecting from the same ip.
 allow-duplicate-ip = {{ .P2P.AllowDuplicateIP }}
 
+# Maximum time a peer address can go without a successful dial before it is
+# removed from the peer store and no longer gossiped. Mostly useful for seed
+# nodes, to keep the crawled address set fresh. 0 disables expiry.
+address-ttl = "{{ .P2P.AddressTTL }}"
+
+# Only gossip peer addresses that this node has successfully dialed at least
+# once, rather than every address it has been told about. Other addresses are
+# still dialed, but not gossiped.
+advertise-dialed-only = {{ .P2P.AdvertiseDialedOnly }}
+
 # Peer connection configuration.
 handshake-timeout = "{{ .P2P.HandshakeTimeout }}"
 dial-timeout = "{{ .P2P.DialTimeout }}"
 
+# Dial timeouts for persistent and bootstrap peers, e.g. to give persistent
+# peers longer than the peers discovered through PEX. 0 uses dial-timeout.
+persistent-peers-dial-timeout = "{{ .P2P.PersistentPeersDialTimeout }}"
+bootstrap-peers-dial-timeout = "{{ .P2P.BootstrapPeersDialTimeout }}"
+
+# Maximum difference between a peer's clock and ours, as measured when
+# handshaking, beyond which the peer is rejected. 0 disables the check.
+max-clock-offset = "{{ .P2P.MaxClockOffset }}"
+
+# Interval at which connected peers are pinged, to detect dead connections.
+# A peer that doesn't respond to max-missed-pongs consecutive pings within
+# ping-timeout is disconnected. 0 disables pings. Only supported with
+# disable-legacy = true. Only peers that enabled it too are pinged.
+ping-interval = "{{ .P2P.PingInterval }}"
+ping-timeout = "{{ .P2P.PingTimeout }}"
+max-missed-pongs = {{ .P2P.MaxMissedPongs }}
+
 #######################################################
 ###          Mempool Configuration Option          ###
 #######################################################
@@ -371,6 +405,12 @@ version = "{{ .Mempool.Version }}"
 recheck = {{ .Mempool.Recheck }}
 broadcast = {{ .Mempool.Broadcast }}
 
+# Recheck all transactions after a block that changed the validator set, even if
+# recheck is disabled, for applications whose rules for valid transactions change
+# along with the validator set. Transactions failing that recheck are evicted
+# with the "validator_set_change" reason.
+recheck-on-validator-set-change = {{ .Mempool.RecheckOnValidatorSetChange }}
+
 # Maximum number of transactions in the mempool
 size = {{ .Mempool.Size }}
 
@@ -382,6 +422,21 @@ max-txs-bytes = {{ .Mempool.MaxTxsBytes }}
 # Size of the cache (used to filter transactions we saw earlier) in transactions
 cache-size = {{ .Mempool.CacheSize }}
 
+# Size of the cache of transactions recently received from peers. A transaction
+# received again from another peer is neither re-checked nor gossiped back to
+# any of the peers it was received from. Set to 0 to disable.
+gossip-cache-size = {{ .Mempool.GossipCacheSize }}
+
+# How long a transaction is kept in the gossip cache after it was first
+# received. Set to 0 to only evict transactions once the cache is full.
+gossip-cache-ttl = "{{ .Mempool.GossipCacheTTL }}"
+
+# Number of recent transaction rejections to retain, along with the reason each
+# transaction was rejected (e.g. a failed CheckTx or recheck, or an eviction),
+# such that they can be queried by transaction hash via the tx_rejection RPC
+# endpoint. Set to 0 to disable.
+rejection-cache-size = {{ .Mempool.RejectionCacheSize }}
+
 # Do not remove invalid transactions from the cache (default: false)
 # Set to true if it's not possible for any invalid transaction to become valid
 # again in the future.
@@ -391,11 +446,35 @@ keep-invalid-txs-in-cache = {{ .Mempool.KeepInvalidTxsInCache }}
 # NOTE: the max size of a tx transmitted over the network is {max-tx-bytes}.
 max-tx-bytes = {{ .Mempool.MaxTxBytes }}
 
+# Minimum gas price of the transactions accepted into the mempool. The gas price
+# of a transaction is the priority the app reports for it in CheckTx, which is
+# expected to be the fee it pays, divided by its gas wanted. Transactions below
+# it are rejected with the "mempool" codespace. Set to 0 to disable.
+min-gas-price = {{ .Mempool.MinGasPrice }}
+
+# Minimum gas price of the transactions the v1 mempool reaps for a block this
+# node proposes. Unlike min-gas-price, cheaper transactions are kept in the
+# mempool rather than rejected. Set to 0 to disable.
+proposal-min-gas-price = {{ .Mempool.ProposalMinGasPrice }}
+
+# The order in which the v1 mempool reaps transactions for a block. Options:
+#   1) "priority" (default) - by decreasing priority, and then in the order
+#      they were received
+#   2) "gas-price" - by decreasing gas price, i.e. priority divided by gas
+#      wanted, and then in the order they were received
+tx-order = "{{ .Mempool.TxOrder }}"
+
 # Maximum size of a batch of transactions to send to a peer
 # Including space needed by encoding (one varint per transaction).
 # XXX: Unused due to https://github.com/tendermint/tendermint/issues/5796
 max-batch-bytes = {{ .Mempool.MaxBatchBytes }}
 
+# Path to a file (relative to the home directory, or absolute) where the
+# mempool is persisted on shutdown. On startup, the transactions in the file are
+# re-checked with the application and the valid ones are restored.
+# Leave empty to disable.
+snapshot-file = "{{ js .Mempool.SnapshotFile }}"
+
 #######################################################
 ###         State Sync Configuration Options        ###
 #######################################################
@@ -407,6 +486,13 @@ max-batch-bytes = {{ .Mempool.MaxBatchBytes }}
 # starting from the height of the snapshot.
 enable = {{ .StateSync.Enable }}
 
+# If true, and state sync is enabled, the node only discovers snapshots and
+# requests a few of the light blocks needed to backfill the best one from each
+# peer. It logs the discovered snapshots, how many of those light blocks each
+# peer served, and whether a sync looks feasible. No chunks are fetched and
+# nothing is restored, and the node does not sync any further.
+dry-run = {{ .StateSync.DryRun }}
+
 # RPC servers (comma-separated) for light client verification of the synced state machine and
 # retrieval of state data for node bootstrapping. Also needs a trusted height and corresponding
 # header hash obtained from a trusted source, and a period during which validators can be trusted.
@@ -425,6 +511,14 @@ discovery-time = "{{ .StateSync.DiscoveryTime }}"
 # Will create a new, randomly named directory within, and remove it when done.
 temp-dir = "{{ .StateSync.TempDir }}"
 
+# Directory to keep state sync snapshot chunks in, so that a restore interrupted by a restart
+# resumes with the chunks already fetched. Chunks are only resumed if the snapshot metadata is the
+# concatenation of their SHA-256 hashes, and are checked against it first. Will create a directory
+# within named after the snapshot, removing those of other snapshots, and remove it once the
+# snapshot is restored or rejected. If empty, chunks are kept in temp-dir instead and fetched again
+# after a restart.
+chunk-dir = "{{ .StateSync.ChunkDir }}"
+
 # The timeout duration before re-requesting a chunk, possibly from a different
 # peer (default: 15 seconds).
 chunk-request-timeout = "{{ .StateSync.ChunkRequestTimeout }}"
@@ -432,6 +526,63 @@ chunk-request-timeout = "{{ .StateSync.ChunkRequestTimeout }}"
 # The number of concurrent chunk and block fetchers to run (default: 4).
 fetchers = "{{ .StateSync.Fetchers }}"
 
+# If greater than 0, the number of block fetchers used when backfilling adapts
+# to how peers respond, between min-fetchers and fetchers: it grows while
+# blocks are fetched without retries and shrinks when retries spike
+# (default: 0).
+min-fetchers = {{ .StateSync.MinFetchers }}
+
+# The number of snapshot chunks requested in parallel when restoring a
+# snapshot, across the peers providing it. If 0, fetchers is used instead
+# (default: 0).
+chunk-fetchers = {{ .StateSync.ChunkFetchers }}
+
+# The number of workers verifying the commit signatures of the light blocks
+# fetched when backfilling, as they arrive. If 0, the commits aren't verified,
+# only the hashes linking each header to the one above it (default: 0).
+verify-workers = {{ .StateSync.VerifyWorkers }}
+
+# The time to wait for the next light block to become verifiable when
+# backfilling before the backfill is considered stalled, in which case the
+# awaited block is requested again from another peer. If 0, stalls aren't
+# detected (default: 1 minute).
+verify-timeout = "{{ .StateSync.VerifyTimeout }}"
+
+# The number of peers, other than the one serving it, that each light block
+# fetched when backfilling is cross-checked against. Backfill waits up to 10s
+# for that many witnesses to serve the same block, and otherwise fetches the
+# block again. If any of them serves a different one, the block is fetched
+# again from other peers, and once the genuine block is verified, evidence of
+# the attack is submitted and the peers that served fake blocks are
+# disconnected. Both retries count towards the backfill's retry budget. If 0,
+# blocks aren't cross-checked (default: 0).
+witnesses = {{ .StateSync.Witnesses }}
+
+# The minimum number of peers that must offer the same snapshot for it to be
+# restored, so that a single lying provider can't have its snapshot picked. If
+# 0 or 1, a snapshot offered by a single peer is restored (default: 0).
+min-snapshot-providers = {{ .StateSync.MinSnapshotProviders }}
+
+# The time to keep discovering snapshots for one to be offered by
+# min-snapshot-providers peers, after which state sync fails. If 0, snapshots
+# are discovered until there is one (default: 2 minutes).
+snapshot-providers-timeout = "{{ .StateSync.SnapshotProvidersTimeout }}"
+
+# If true, light blocks are requested from peers picked at random rather than
+# from each peer in turn, in the order they connected, to spread the load of
+# backfilling evenly over the peers (default: false).
+shuffle-peers = {{ .StateSync.ShufflePeers }}
+
+# If true, the node switches to block sync or consensus right after restoring
+# the snapshot, and backfills the blocks below it in the background. Until the
+# backfill completes, the node only serves the light blocks it has backfilled
+# so far, which are stored along with their validator sets as soon as they are
+# verified. The background backfill runs at a low priority: it fetches one
+# light block at a time, ignoring fetchers and min-fetchers, and retries more
+# patiently before giving up. It's interrupted when the node stops
+# (default: false).
+background-backfill = {{ .StateSync.BackgroundBackfill }}
+
 #######################################################
 ###       Fast Sync Configuration Connections       ###
 #######################################################
@@ -449,6 +600,14 @@ version = "{{ .FastSync.Version }}"
 
 wal-file = "{{ js .Consensus.WalPath }}"
 
+# Total size of the WAL in bytes above which a warning is logged, 0 to disable.
+# A WAL can grow past this while a height is stuck.
+wal-max-size = {{ .Consensus.WalMaxSize }}
+
+# If true, remove the WAL files preceding the last completed height once
+# wal-max-size is exceeded. The current height is always kept.
+wal-force-compaction = {{ .Consensus.WalForceCompaction }}
+
 # How long we wait for a proposal block before prevoting nil
 timeout-propose = "{{ .Consensus.TimeoutPropose }}"
 # How much timeout-propose increases with each round
@@ -461,6 +620,11 @@ timeout-prevote-delta = "{{ .Consensus.TimeoutPrevoteDelta }}"
 timeout-precommit = "{{ .Consensus.TimeoutPrecommit }}"
 # How much the timeout-precommit increases with each round
 timeout-precommit-delta = "{{ .Consensus.TimeoutPrecommitDelta }}"
+# Maximum random jitter added to the propose, prevote and precommit timeouts,
+# as a fraction of the timeout (e.g. 0.1 for up to 10% longer), so that
+# validators with synchronized clocks don't all time out at once. Set to 0 to
+# disable the jitter.
+timeout-jitter = {{ .Consensus.TimeoutJitter }}
 # How long we wait after committing a block, before starting on the new
 # height (this gives us a chance to receive some more precommits, even
 # though we already have +2/3).
@@ -472,6 +636,51 @@ timeout-commit = "{{ .Consensus.TimeoutCommit }}"
 # So, validators should stop the state machine, wait for some blocks, and then restart the state machine to avoid panic.
 double-sign-check-height = {{ .Consensus.DoubleSignCheckHeight }}
 
+# Maximum number of verified vote signatures to cache, so that votes received
+# more than once are not verified again. Set to 0 to disable the cache.
+signature-cache-size = {{ .Consensus.SignatureCacheSize }}
+
+# Maximum number of proposals for the next height to buffer until the node
+# reaches that height. Proposals not signed by the proposer of the next height,
+# or for later heights, are dropped. Set to 0 to drop them all.
+proposal-buffer-size = {{ .Consensus.ProposalBufferSize }}
+
+# Number of heights over which to compare how often each validator proposed a
+# block to its share of the voting power, reporting the deviation in the logs
+# and metrics. Set to 0 to disable the audit.
+proposer-audit-window = {{ .Consensus.ProposerAuditWindow }}
+
+# Maximum number of recently added votes to remember, so that exact duplicates
+# of them gossiped again are dropped before being verified and processed again.
+# Set to 0 to disable the window.
+vote-replay-window = {{ .Consensus.VoteReplayWindow }}
+
+# Maximum number of heights the application may be behind the block store when
+# the node starts, e.g. because the application rolled its state back, for the
+# node to replay the missing blocks. If the application is further behind, the
+# node refuses to start rather than replaying them. Set to 0 to disable the check.
+max-rewind-depth = {{ .Consensus.MaxRewindDepth }}
+
+# If true, the last block replayed from the block store when the node starts is
+# applied to the state without verifying the signatures of its commit again, as
+# they were verified before the block was saved. It is still checked to link up.
+# The blocks replayed before it, when the app is further behind, are executed
+# without being validated against the state either way; with this set, they are
+# additionally checked to hash to their IDs and link up, without verifying
+# signatures, which slows their replay down. This only applies to the local
+# block store: blocks received from peers are always fully verified.
+replay-skip-signature-verification = {{ .Consensus.ReplaySkipSignatureVerification }}
+
+# Number of heights a validator must lag behind the majority of its peers for
+# it to stop proposing and voting and catch up through fast sync. Only peers
+# that have served us verified votes or block parts count. It resumes once the
+# lag is down to half of it. Set to 0 to disable the check.
+catch-up-lag = {{ .Consensus.CatchUpLag }}
+
+# Minimum number of peers that must count towards the lag for the node to
+# catch up.
+catch-up-min-peers = {{ .Consensus.CatchUpMinPeers }}
+
 # Make progress as soon as we have all the precommits (as if TimeoutCommit = 0)
 skip-timeout-commit = {{ .Consensus.SkipTimeoutCommit }}
 
@@ -483,6 +692,29 @@ create-empty-blocks-interval = "{{ .Consensus.CreateEmptyBlocksInterval }}"
 peer-gossip-sleep-duration = "{{ .Consensus.PeerGossipSleepDuration }}"
 peer-query-maj23-sleep-duration = "{{ .Consensus.PeerQueryMaj23SleepDuration }}"
 
+# How votes for the current height are gossiped to peers. Options:
+#   1) "push" (default) - send each peer the votes it is not known to have yet
+#   2) "bit-array" - periodically send each peer bit arrays of the votes we
+#      have, and only send the votes it reports missing in the bit arrays it
+#      sends back. This avoids sending the same vote twice in large validator
+#      sets, but peers in "push" mode do not send bit arrays, so all nodes of
+#      a network should use the same mode.
+vote-gossip = "{{ .Consensus.VoteGossip }}"
+
+#######################################################
+###         Evidence Configuration Options          ###
+#######################################################
+[evidence]
+
+# Reject evidence whose timestamp does not match the time of the committed
+# block at its height. By default, such evidence is rectified and stored
+# instead.
+strict-timestamps = {{ .Evidence.StrictTimestamps }}
+
+# How often the evidence that is still pending is gossiped again to each peer.
+# Within an interval, each piece of evidence is sent to a peer once.
+broadcast-interval = "{{ .Evidence.BroadcastInterval }}"
+
 #######################################################
 ###   Transaction Indexer Configuration Options     ###
 #######################################################
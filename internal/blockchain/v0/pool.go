@@ -48,6 +48,16 @@ const (
 
 var peerTimeout = 15 * time.Second // not const so we can override with tests
 
+// errNoPeerHasHeight is returned when none of the peers can serve a block,
+// e.g. because they all pruned it.
+type errNoPeerHasHeight struct {
+	height int64
+}
+
+func (e errNoPeerHasHeight) Error() string {
+	return fmt.Sprintf("no peer has height %d", e.height)
+}
+
 /*
 	Peers self report their heights when we join the block pool.
 	Starting from our latest pool.height, we request blocks
@@ -111,6 +121,34 @@ func (pool *BlockPool) OnStart() error {
 	return nil
 }
 
+// OnReset implements service.Service, so that the pool can be started again
+// when the consensus reactor hands back to fast sync after falling behind. It
+// drops the requests and the peers left from the previous run, as the heights
+// the peers reported back then are stale; they're added back as they respond
+// to status requests.
+func (pool *BlockPool) OnReset() error {
+	pool.mtx.Lock()
+	defer pool.mtx.Unlock()
+
+	for _, requester := range pool.requesters {
+		if requester.IsRunning() {
+			_ = requester.Stop()
+		}
+	}
+	pool.requesters = make(map[int64]*bpRequester)
+	atomic.StoreInt32(&pool.numPending, 0)
+
+	for _, peer := range pool.peers {
+		if peer.timeout != nil {
+			peer.timeout.Stop()
+		}
+	}
+	pool.peers = make(map[p2p.NodeID]*bpPeer)
+	pool.maxPeerHeight = 0
+
+	return nil
+}
+
 // spawns requesters as needed
 func (pool *BlockPool) makeRequestersRoutine() {
 	for {
@@ -349,25 +387,45 @@ func (pool *BlockPool) updateMaxPeerHeight() {
 	pool.maxPeerHeight = max
 }
 
-// Pick an available peer with the given height available.
-// If no peers are available, returns nil.
-func (pool *BlockPool) pickIncrAvailablePeer(height int64) *bpPeer {
+// Pick an available peer with the given height available, i.e. between the
+// base and height it advertised. If no peers are available, returns nil, along
+// with an errNoPeerHasHeight error if none of them has the height at all
+// rather than all being busy.
+func (pool *BlockPool) pickIncrAvailablePeer(height int64) (*bpPeer, error) {
 	pool.mtx.Lock()
 	defer pool.mtx.Unlock()
 
+	hasHeight := false
 	for _, peer := range pool.peers {
 		if peer.didTimeout {
 			pool.removePeer(peer.id)
 			continue
 		}
-		if peer.numPending >= maxPendingRequestsPerPeer {
+		if height < peer.base || height > peer.height {
 			continue
 		}
-		if height < peer.base || height > peer.height {
+		hasHeight = true
+		if peer.numPending >= maxPendingRequestsPerPeer {
 			continue
 		}
 		peer.incrPending()
-		return peer
+		return peer, nil
+	}
+
+	if !hasHeight && len(pool.peers) > 0 {
+		return nil, errNoPeerHasHeight{height: height}
+	}
+	return nil, nil
+}
+
+// HeightUnavailable returns an errNoPeerHasHeight error if none of the peers
+// can serve the block at the pool's height, which it needs to advance.
+func (pool *BlockPool) HeightUnavailable() error {
+	pool.mtx.RLock()
+	defer pool.mtx.RUnlock()
+
+	if r := pool.requesters[pool.height]; r != nil {
+		return r.getErr()
 	}
 	return nil
 }
@@ -515,6 +573,7 @@ type bpRequester struct {
 	mtx    tmsync.Mutex
 	peerID p2p.NodeID
 	block  *types.Block
+	err    error // set while no peer can serve the block
 }
 
 func newBPRequester(pool *BlockPool, height int64) *bpRequester {
@@ -565,6 +624,22 @@ func (bpr *bpRequester) getPeerID() p2p.NodeID {
 	return bpr.peerID
 }
 
+func (bpr *bpRequester) getErr() error {
+	bpr.mtx.Lock()
+	defer bpr.mtx.Unlock()
+	return bpr.err
+}
+
+func (bpr *bpRequester) setErr(err error) {
+	bpr.mtx.Lock()
+	defer bpr.mtx.Unlock()
+
+	if err != nil && bpr.err == nil {
+		bpr.Logger.Error("failed to request block", "err", err)
+	}
+	bpr.err = err
+}
+
 // This is called from the requestRoutine, upon redo().
 func (bpr *bpRequester) reset() {
 	bpr.mtx.Lock()
@@ -600,7 +675,11 @@ OUTER_LOOP:
 			if !bpr.IsRunning() || !bpr.pool.IsRunning() {
 				return
 			}
-			peer = bpr.pool.pickIncrAvailablePeer(bpr.height)
+			var err error
+			peer, err = bpr.pool.pickIncrAvailablePeer(bpr.height)
+			// keep looking, as peers may still join or advertise a lower
+			// base, but expose the error rather than stalling silently
+			bpr.setErr(err)
 			if peer == nil {
 				time.Sleep(requestIntervalMS * time.Millisecond)
 				continue PICK_PEER_LOOP
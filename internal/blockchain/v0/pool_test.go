@@ -201,6 +201,63 @@ func TestBlockPoolTimeout(t *testing.T) {
 	}
 }
 
+func TestBlockPoolPeerBase(t *testing.T) {
+	requestsCh := make(chan BlockRequest, 100)
+	errorsCh := make(chan peerError, 100)
+
+	pool := NewBlockPool(1, requestsCh, errorsCh)
+	pool.SetLogger(log.TestingLogger())
+	require.NoError(t, pool.Start())
+	t.Cleanup(func() {
+		if err := pool.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	// neither peer has the block at height 4
+	archive, pruned := p2p.NodeID("archive"), p2p.NodeID("pruned")
+	pool.SetPeerRange(archive, 1, 3)
+	pool.SetPeerRange(pruned, 5, 10)
+
+	// each block is requested from the only peer that can serve it
+	requested := make(map[int64]p2p.NodeID)
+	for len(requested) < 9 {
+		select {
+		case request := <-requestsCh:
+			requested[request.Height] = request.PeerID
+		case err := <-errorsCh:
+			t.Fatal(err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected blocks to be requested, got %v", requested)
+		}
+	}
+	for height := int64(1); height <= 10; height++ {
+		switch {
+		case height <= 3:
+			require.Equal(t, archive, requested[height], height)
+		case height >= 5:
+			require.Equal(t, pruned, requested[height], height)
+		default:
+			require.NotContains(t, requested, height)
+		}
+	}
+
+	_, err := pool.pickIncrAvailablePeer(4)
+	require.Equal(t, errNoPeerHasHeight{height: 4}, err)
+	require.EqualError(t, err, "no peer has height 4")
+	require.NoError(t, pool.HeightUnavailable())
+
+	// once the pool reaches height 4, it reports that it can't advance
+	for height := int64(1); height <= 3; height++ {
+		pool.AddBlock(archive, &types.Block{Header: types.Header{Height: height}}, 123)
+		pool.PopRequest()
+	}
+	require.Eventually(t, func() bool {
+		return pool.HeightUnavailable() != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, errNoPeerHasHeight{height: 4}, pool.HeightUnavailable())
+}
+
 func TestBlockPoolRemovePeer(t *testing.T) {
 	peers := make(testPeers, 10)
 	for i := 0; i < 10; i++ {
@@ -241,3 +298,40 @@ func TestBlockPoolRemovePeer(t *testing.T) {
 
 	assert.EqualValues(t, 0, pool.MaxPeerHeight())
 }
+
+func TestBlockPoolReset(t *testing.T) {
+	requestsCh := make(chan BlockRequest, 10)
+	errorsCh := make(chan peerError, 10)
+
+	pool := NewBlockPool(1, requestsCh, errorsCh)
+	pool.SetLogger(log.TestingLogger())
+	require.NoError(t, pool.Start())
+
+	pool.SetPeerRange(p2p.NodeID("1"), 1, 10)
+	assert.EqualValues(t, 10, pool.MaxPeerHeight())
+	require.Eventually(t, func() bool {
+		_, _, lenRequesters := pool.GetStatus()
+		return lenRequesters > 0
+	}, time.Second, 10*time.Millisecond)
+
+	// the pool can't be reset while running
+	require.Error(t, pool.Reset())
+
+	require.NoError(t, pool.Stop())
+	require.NoError(t, pool.Reset())
+
+	// the stale peers and requests are gone, and the pool can start again
+	height, numPending, lenRequesters := pool.GetStatus()
+	assert.EqualValues(t, 1, height)
+	assert.Zero(t, numPending)
+	assert.Zero(t, lenRequesters)
+	assert.Zero(t, pool.MaxPeerHeight())
+	assert.False(t, pool.IsCaughtUp())
+
+	require.NoError(t, pool.Start())
+	t.Cleanup(func() {
+		if err := pool.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+}
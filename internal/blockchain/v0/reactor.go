@@ -35,6 +35,7 @@ var (
 				SendQueueCapacity:   1000,
 				RecvBufferCapacity:  50 * 4096,
 				RecvMessageCapacity: bc.MaxMsgSize,
+				Compressed:          true,
 
 				MaxSendBytes: 100,
 			},
@@ -357,8 +358,24 @@ func (r *Reactor) processPeerUpdates() {
 }
 
 // SwitchToFastSync is called by the state sync reactor when switching to fast
-// sync.
+// sync, and by the consensus reactor when it falls too far behind its peers.
+// Blocks are fetched from the height above the state. In particular, the base
+// that state sync backfilled down to isn't taken into account: the backfill
+// only saves the headers and commits below the restored state, and blocks
+// can't be applied below the state anyway.
 func (r *Reactor) SwitchToFastSync(state sm.State) error {
+	// The pool is stopped once we've caught up, so it must be reset if we're
+	// switching back to fast sync, and learn our peers' heights again.
+	resumed := false
+	select {
+	case <-r.pool.Quit():
+		if err := r.pool.Reset(); err != nil {
+			return err
+		}
+		resumed = true
+	default:
+	}
+
 	r.fastSync = true
 	r.initialState = state
 	r.pool.height = state.LastBlockHeight + 1
@@ -370,6 +387,18 @@ func (r *Reactor) SwitchToFastSync(state sm.State) error {
 	r.poolWG.Add(1)
 	go r.poolRoutine(true)
 
+	if resumed {
+		r.poolWG.Add(1)
+		go func() {
+			defer r.poolWG.Done()
+
+			r.blockchainCh.Out <- p2p.Envelope{
+				Broadcast: true,
+				Message:   &bcproto.StatusRequest{},
+			}
+		}()
+	}
+
 	return nil
 }
 
@@ -449,6 +478,7 @@ FOR_LOOP:
 			var (
 				height, numPending, lenRequesters = r.pool.GetStatus()
 				lastAdvance                       = r.pool.LastAdvance()
+				unavailableErr                    = r.pool.HeightUnavailable()
 			)
 
 			r.Logger.Debug(
@@ -465,6 +495,19 @@ FOR_LOOP:
 			case time.Since(lastAdvance) > syncTimeout:
 				r.Logger.Error("no progress since last advance", "last_advance", lastAdvance)
 
+			case unavailableErr != nil:
+				// None of the peers can serve the next block right now, e.g.
+				// because they pruned it. Report it rather than silently
+				// stalling, but keep waiting for the sync timeout, since a peer
+				// which has the block may still connect.
+				r.Logger.Error(
+					"unable to sync further",
+					"height", height,
+					"err", unavailableErr,
+					"timeout_in", syncTimeout-time.Since(lastAdvance),
+				)
+				continue
+
 			default:
 				r.Logger.Info(
 					"not caught up yet",
@@ -332,3 +332,74 @@ func TestReactor_BadBlockStopsPeer(t *testing.T) {
 		len(rts.reactors[newNode.NodeID].pool.peers),
 	)
 }
+
+func TestReactor_SwitchToFastSync(t *testing.T) {
+	config := cfg.ResetTestRoot("blockchain_reactor_test")
+	defer os.RemoveAll(config.RootDir)
+
+	genDoc, _ := factory.RandGenesisDoc(config, 1, false, 30)
+	state, err := sm.MakeGenesisState(genDoc)
+	require.NoError(t, err)
+
+	peerUpdates := p2p.NewPeerUpdates(make(chan p2p.PeerUpdate), 1)
+	defer peerUpdates.Close()
+
+	blockchainCh := p2p.NewChannel(
+		BlockchainChannel,
+		new(bcproto.Message),
+		make(chan p2p.Envelope),
+		make(chan p2p.Envelope, 1),
+		make(chan p2p.PeerError, 1),
+	)
+
+	// state sync starts block sync with an empty store, so the reactor is not
+	// fast syncing when it starts
+	blockStore := store.NewBlockStore(dbm.NewMemDB())
+	reactor, err := NewReactor(
+		log.TestingLogger(),
+		state.Copy(),
+		nil,
+		blockStore,
+		nil,
+		blockchainCh,
+		peerUpdates,
+		false,
+		cons.NopMetrics(),
+	)
+	require.NoError(t, err)
+	require.NoError(t, reactor.Start())
+	defer func() { require.NoError(t, reactor.Stop()) }()
+
+	// the state sync restored the state at height 20 and backfilled the headers
+	// down to height 5, below its stop height as it also had to reach the stop
+	// time. Block sync only fetches the blocks above the state, however far
+	// back the backfill went.
+	const (
+		stateHeight int64 = 20
+		base        int64 = 5
+	)
+	for h := stateHeight - 1; h >= base; h-- {
+		require.NoError(t, blockStore.SaveSignedHeader(&types.SignedHeader{
+			Header: &types.Header{ChainID: state.ChainID, Height: h},
+			Commit: &types.Commit{Height: h},
+		}, types.BlockID{}))
+	}
+	require.Equal(t, base, blockStore.Base())
+	syncedState := state.Copy()
+	syncedState.LastBlockHeight = stateHeight
+
+	require.NoError(t, reactor.SwitchToFastSync(syncedState))
+	height, _, _ := reactor.pool.GetStatus()
+	require.Equal(t, stateHeight+1, height)
+
+	// add a fake peer at the same height so that the reactor considers itself
+	// caught up and stops the pool
+	reactor.pool.SetPeerRange("00ff", 1, stateHeight)
+	require.Eventually(
+		t,
+		func() bool { return !reactor.pool.IsRunning() },
+		10*time.Second,
+		10*time.Millisecond,
+		"expected block sync to finish",
+	)
+}
@@ -0,0 +1,68 @@
+package consensus
+
+import (
+	"sort"
+)
+
+// catchUpDetector detects when the node lags so far behind the majority of
+// its peers that it should stop proposing and voting, as it would only do so
+// for heights the network has long moved past, and catch up on the blocks it
+// missed instead until it's close to them again. To avoid flapping between
+// the two, catch-up starts once the lag exceeds its threshold, but only ends
+// once the lag is down to half of it.
+//
+// Only the peers the Reactor passes in count, see Reactor.updateCatchUp, and
+// there must be at least minPeers of them, so that a single peer claiming to
+// be far ahead can't make us stop voting.
+//
+// It is not thread-safe: the Reactor accesses it under its own mutex.
+type catchUpDetector struct {
+	lag        int64 // 0 disables detection
+	minPeers   int
+	catchingUp bool
+}
+
+func newCatchUpDetector(lag int64, minPeers int) *catchUpDetector {
+	return &catchUpDetector{lag: lag, minPeers: minPeers}
+}
+
+// update updates the detector with our current height and the heights of the
+// peers that count. It returns whether we're catching up, and whether that
+// just changed.
+func (d *catchUpDetector) update(height int64, peerHeights []int64) (catchingUp, changed bool) {
+	if d.lag <= 0 {
+		return false, false
+	}
+
+	was := d.catchingUp
+	lag := majorityHeight(peerHeights) - height
+	switch {
+	case len(peerHeights) == 0 || len(peerHeights) < d.minPeers:
+		d.catchingUp = false
+	case !d.catchingUp && lag > d.lag:
+		d.catchingUp = true
+	case d.catchingUp && lag <= d.lag/2:
+		d.catchingUp = false
+	}
+
+	return d.catchingUp, d.catchingUp != was
+}
+
+// reset ends catch-up, e.g. once fast sync has caught up and handed back to
+// consensus.
+func (d *catchUpDetector) reset() {
+	d.catchingUp = false
+}
+
+// majorityHeight returns the greatest height that a majority of the given
+// heights are at or above, or 0 if there are none.
+func majorityHeight(heights []int64) int64 {
+	if len(heights) == 0 {
+		return 0
+	}
+
+	sorted := make([]int64, len(heights))
+	copy(sorted, heights)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] > sorted[j] })
+	return sorted[len(sorted)/2]
+}
@@ -0,0 +1,184 @@
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/libs/log"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestCatchUpDetector(t *testing.T) {
+	d := newCatchUpDetector(10, 1)
+
+	steps := []struct {
+		height      int64
+		peerHeights []int64
+		catchingUp  bool
+		changed     bool
+	}{
+		{5, nil, false, false},
+		{5, []int64{15}, false, false},
+		// a minority of peers far ahead isn't enough
+		{5, []int64{100, 100, 6, 5, 5}, false, false},
+		{5, []int64{100, 100, 100, 5, 5}, true, true},
+		{50, []int64{100, 100, 100, 5, 5}, true, false},
+		// the lag must be down to half of the threshold to resume
+		{94, []int64{104, 104, 104}, true, false},
+		{98, []int64{104, 104, 104}, true, false},
+		{99, []int64{104, 104, 104}, false, true},
+		{99, []int64{115, 109, 104}, false, false},
+		{99, []int64{115, 110, 104}, true, true},
+		// losing all peers ends catch-up
+		{99, nil, false, true},
+	}
+	for i, step := range steps {
+		catchingUp, changed := d.update(step.height, step.peerHeights)
+		require.Equal(t, step.catchingUp, catchingUp, "step %d", i)
+		require.Equal(t, step.changed, changed, "step %d", i)
+	}
+
+	// a threshold of 0 disables detection
+	d = newCatchUpDetector(0, 1)
+	catchingUp, changed := d.update(1, []int64{100})
+	require.False(t, catchingUp)
+	require.False(t, changed)
+
+	// too few peers aren't enough, however far ahead they are
+	d = newCatchUpDetector(10, 3)
+	catchingUp, _ = d.update(1, []int64{100, 100})
+	require.False(t, catchingUp)
+	catchingUp, changed = d.update(1, []int64{100, 100, 100})
+	require.True(t, catchingUp)
+	require.True(t, changed)
+	catchingUp, changed = d.update(1, []int64{100, 100})
+	require.False(t, catchingUp)
+	require.True(t, changed)
+}
+
+func TestReactorCatchUp(t *testing.T) {
+	config := configSetup(t)
+
+	cs, _ := randState(config, 1)
+	r := NewReactor(log.TestingLogger(), cs, nil, nil, nil, nil, nil, false)
+	r.catchUp = newCatchUpDetector(10, 2)
+
+	setPeerHeights := func(heights ...int64) {
+		r.mtx.Lock()
+		defer r.mtx.Unlock()
+		r.peers = make(map[p2p.NodeID]*PeerState)
+		for i, height := range heights {
+			ps := NewPeerState(log.TestingLogger(), p2p.NodeID(string(rune('a'+i))))
+			ps.PRS.Height = height
+			ps.RecordServedHeight(cs.Height)
+			r.peers[ps.peerID] = ps
+		}
+	}
+
+	// We vote while close to our peers.
+	setPeerHeights(cs.Height+5, cs.Height+5, cs.Height)
+	r.updateCatchUp()
+	require.False(t, cs.isCatchingUp())
+	require.NotNil(t, cs.signAddVote(tmproto.PrevoteType, nil, types.PartSetHeader{}))
+
+	// Once far behind most of them, we stop voting.
+	setPeerHeights(cs.Height+100, cs.Height+100, cs.Height)
+	r.updateCatchUp()
+	require.True(t, cs.isCatchingUp())
+	require.Nil(t, cs.signAddVote(tmproto.PrevoteType, nil, types.PartSetHeader{}))
+
+	// Nor do we resume until we're within half of the threshold.
+	setPeerHeights(cs.Height+6, cs.Height+6, cs.Height)
+	r.updateCatchUp()
+	require.True(t, cs.isCatchingUp())
+
+	setPeerHeights(cs.Height+5, cs.Height+5, cs.Height)
+	r.updateCatchUp()
+	require.False(t, cs.isCatchingUp())
+	require.NotNil(t, cs.signAddVote(tmproto.PrecommitType, nil, types.PartSetHeader{}))
+
+	// Peers which claim to be far ahead, but never served us anything we
+	// could verify, don't count.
+	setPeerHeights(cs.Height+100, cs.Height+100, cs.Height)
+	r.mtx.Lock()
+	for _, ps := range r.peers {
+		ps.servedHeight = 0
+	}
+	r.mtx.Unlock()
+	r.updateCatchUp()
+	require.False(t, cs.isCatchingUp())
+}
+
+type mockFastSyncer struct {
+	stateCh chan sm.State
+}
+
+func (m *mockFastSyncer) SwitchToFastSync(state sm.State) error {
+	m.stateCh <- state
+	return nil
+}
+
+func TestReactorCatchUpFastSync(t *testing.T) {
+	config := configSetup(t)
+
+	cs, _ := randState(config, 1)
+	r := NewReactor(log.TestingLogger(), cs, nil, nil, nil, nil, nil, false)
+	r.catchUp = newCatchUpDetector(10, 2)
+	fastSyncer := &mockFastSyncer{stateCh: make(chan sm.State, 1)}
+	r.SetFastSyncer(fastSyncer)
+
+	require.NoError(t, cs.Start())
+	t.Cleanup(func() {
+		if err := cs.Stop(); err == nil {
+			cs.Wait()
+		}
+	})
+
+	for i := 0; i < 2; i++ {
+		ps := NewPeerState(log.TestingLogger(), p2p.NodeID(string(rune('a'+i))))
+		ps.PRS.Height = cs.Height + 100
+		ps.RecordServedHeight(cs.Height)
+		r.peers[ps.peerID] = ps
+	}
+
+	// Falling far behind stops consensus and hands off to fast sync.
+	r.updateCatchUp()
+	require.True(t, r.WaitSync())
+
+	var state sm.State
+	select {
+	case state = <-fastSyncer.stateCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the switch to fast sync")
+	}
+	require.Equal(t, cs.GetState().LastBlockHeight, state.LastBlockHeight)
+	require.False(t, cs.IsRunning())
+	require.False(t, cs.isCatchingUp())
+
+	// Fast sync hands back to consensus once it's caught up, which restarts
+	// the consensus state.
+	r.SwitchToConsensus(state, true)
+	require.False(t, r.WaitSync())
+	require.True(t, cs.IsRunning())
+}
+
+func TestStateEnterProposeCatchingUp(t *testing.T) {
+	config := configSetup(t)
+
+	cs, _ := randState(config, 1)
+	cs.setCatchingUp(true)
+	height, round := cs.Height, cs.Round
+
+	timeoutCh := subscribe(cs.eventBus, types.EventQueryTimeoutPropose)
+
+	startTestRound(cs, height, round)
+
+	// though it's our turn to propose, we don't while catching up
+	ensureNewTimeout(timeoutCh, height, round, cs.config.TimeoutPropose.Nanoseconds())
+	require.Nil(t, cs.GetRoundState().Proposal)
+}
@@ -58,9 +58,19 @@ type Metrics struct {
 	FastSyncing metrics.Gauge
 	// Whether or not a node is state syncing. 1 if yes, 0 if no.
 	StateSyncing metrics.Gauge
+	// Whether or not a node is catching up with its peers, without proposing
+	// or voting. 1 if yes, 0 if no.
+	CatchingUp metrics.Gauge
 
 	// Number of blockparts transmitted by peer.
 	BlockParts metrics.Counter
+
+	// Deviation of a validator's share of the proposals over the last proposer
+	// audit window from its share of the voting power.
+	ProposerDeviation metrics.Gauge
+
+	// Number of votes dropped as exact duplicates of recently added votes.
+	DuplicateVotes metrics.Counter
 }
 
 // PrometheusMetrics returns Metrics build using Prometheus client library.
@@ -181,12 +191,32 @@ func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 			Name:      "state_syncing",
 			Help:      "Whether or not a node is state syncing. 1 if yes, 0 if no.",
 		}, labels).With(labelsAndValues...),
+		CatchingUp: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "catching_up",
+			Help: "Whether or not a node is catching up with its peers, without proposing or voting. " +
+				"1 if yes, 0 if no.",
+		}, labels).With(labelsAndValues...),
 		BlockParts: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "block_parts",
 			Help:      "Number of blockparts transmitted by peer.",
 		}, append(labels, "peer_id")).With(labelsAndValues...),
+		ProposerDeviation: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "proposer_deviation",
+			Help: "Deviation of a validator's share of the proposals over the last proposer audit window " +
+				"from its share of the voting power.",
+		}, append(labels, "validator_address")).With(labelsAndValues...),
+		DuplicateVotes: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "duplicate_votes",
+			Help:      "Number of votes dropped as exact duplicates of recently added votes.",
+		}, labels).With(labelsAndValues...),
 	}
 }
 
@@ -216,7 +246,11 @@ func NopMetrics() *Metrics {
 		CommittedHeight: discard.NewGauge(),
 		FastSyncing:     discard.NewGauge(),
 		StateSyncing:    discard.NewGauge(),
+		CatchingUp:      discard.NewGauge(),
 		BlockParts:      discard.NewCounter(),
+
+		ProposerDeviation: discard.NewGauge(),
+		DuplicateVotes:    discard.NewCounter(),
 	}
 }
 
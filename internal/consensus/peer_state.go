@@ -46,6 +46,10 @@ type PeerState struct {
 	PRS     cstypes.PeerRoundState `json:"round_state"`
 	Stats   *peerStateStats        `json:"stats"`
 
+	// servedHeight is the greatest height of the votes and block parts the
+	// peer sent us that our consensus state verified and added.
+	servedHeight int64
+
 	broadcastWG sync.WaitGroup
 	closer      *tmsync.Closer
 }
@@ -200,6 +204,16 @@ func (ps *PeerState) PickVoteToSend(votes types.VoteSetReader) (*types.Vote, boo
 	return nil, false
 }
 
+// GetVoteBitArray returns a copy of the bit array of the votes the peer is
+// known to have for the given height, round and vote type, or nil if they
+// are not tracked.
+func (ps *PeerState) GetVoteBitArray(height int64, round int32, votesType tmproto.SignedMsgType) *bits.BitArray {
+	ps.mtx.Lock()
+	defer ps.mtx.Unlock()
+
+	return ps.getVoteBitArray(height, round, votesType).Copy()
+}
+
 func (ps *PeerState) getVoteBitArray(height int64, round int32, votesType tmproto.SignedMsgType) *bits.BitArray {
 	if !types.IsVoteTypeValid(votesType) {
 		return nil
@@ -357,6 +371,26 @@ func (ps *PeerState) BlockPartsSent() int {
 	return ps.Stats.BlockParts
 }
 
+// RecordServedHeight records that the peer sent us a vote or block part for
+// the given height, which our consensus state verified and added.
+func (ps *PeerState) RecordServedHeight(height int64) {
+	ps.mtx.Lock()
+	defer ps.mtx.Unlock()
+
+	if height > ps.servedHeight {
+		ps.servedHeight = height
+	}
+}
+
+// ServedHeight returns the greatest height the peer sent us a verified vote or
+// block part for, see RecordServedHeight.
+func (ps *PeerState) ServedHeight() int64 {
+	ps.mtx.Lock()
+	defer ps.mtx.Unlock()
+
+	return ps.servedHeight
+}
+
 // SetHasVote sets the given vote as known by the peer
 func (ps *PeerState) SetHasVote(vote *types.Vote) {
 	ps.mtx.Lock()
@@ -0,0 +1,96 @@
+package consensus
+
+import (
+	"github.com/tendermint/tendermint/types"
+)
+
+type heightRound struct {
+	height int64
+	round  int32
+}
+
+type bufferedProposal struct {
+	proposal *types.Proposal
+	verified bool // signed by the proposer of its height and round
+}
+
+// proposalBuffer holds proposals for the next height, which the node hasn't
+// reached yet, until it reaches that height. It keeps at most one proposal per
+// height and round, and at most size proposals in total, preferring those for
+// the nearest heights. A proposal whose signature could be verified replaces
+// one whose signature couldn't, so that the latter can't crowd out the former.
+//
+// It is not thread-safe: the State accesses it under its own mutex.
+type proposalBuffer struct {
+	size      int
+	proposals map[heightRound]bufferedProposal
+}
+
+func newProposalBuffer(size int) *proposalBuffer {
+	return &proposalBuffer{
+		size:      size,
+		proposals: make(map[heightRound]bufferedProposal),
+	}
+}
+
+// add buffers the proposal, returning false if it was dropped, because one is
+// already buffered for its height and round that is verified or the new one
+// isn't, or because the buffer only holds proposals for nearer heights.
+func (b *proposalBuffer) add(proposal *types.Proposal, verified bool) bool {
+	if b.size <= 0 {
+		return false
+	}
+
+	key := heightRound{proposal.Height, proposal.Round}
+	if existing, ok := b.proposals[key]; ok {
+		if existing.verified || !verified {
+			return false
+		}
+		b.proposals[key] = bufferedProposal{proposal, verified}
+		return true
+	}
+
+	if len(b.proposals) >= b.size {
+		// evict the proposal furthest ahead, unless it's this one
+		furthest := key
+		for hr := range b.proposals {
+			if hr.height > furthest.height || (hr.height == furthest.height && hr.round > furthest.round) {
+				furthest = hr
+			}
+		}
+		if furthest == key {
+			return false
+		}
+		delete(b.proposals, furthest)
+	}
+
+	b.proposals[key] = bufferedProposal{proposal, verified}
+	return true
+}
+
+// take removes and returns the proposal buffered for the given height and
+// round, or nil if there is none. The proposal is only returned if verify
+// accepts it, as it may have been buffered without being verified, or against
+// a validator set other than the one of the height once reached.
+func (b *proposalBuffer) take(height int64, round int32, verify func(*types.Proposal) bool) *types.Proposal {
+	key := heightRound{height, round}
+	buffered, ok := b.proposals[key]
+	if !ok {
+		return nil
+	}
+	delete(b.proposals, key)
+
+	if !verify(buffered.proposal) {
+		return nil
+	}
+	return buffered.proposal
+}
+
+// prune removes the proposals for heights below the given one.
+func (b *proposalBuffer) prune(height int64) {
+	for hr := range b.proposals {
+		if hr.height < height {
+			delete(b.proposals, hr)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestProposalBuffer(t *testing.T) {
+	newProposal := func(height int64, round int32) *types.Proposal {
+		return types.NewProposal(height, round, -1, types.BlockID{})
+	}
+	acceptAll := func(*types.Proposal) bool { return true }
+
+	buffer := newProposalBuffer(2)
+
+	p1, p2 := newProposal(3, 0), newProposal(4, 0)
+	require.True(t, buffer.add(p1, true))
+	require.True(t, buffer.add(p2, true))
+
+	// only one proposal is kept per height and round
+	require.False(t, buffer.add(newProposal(3, 0), true))
+
+	// when full, the proposal furthest ahead is dropped
+	require.False(t, buffer.add(newProposal(5, 0), true))
+	p3 := newProposal(3, 1)
+	require.True(t, buffer.add(p3, true))
+	require.Nil(t, buffer.take(4, 0, acceptAll))
+
+	require.Equal(t, p1, buffer.take(3, 0, acceptAll))
+	require.Nil(t, buffer.take(3, 0, acceptAll))
+
+	buffer.prune(4)
+	require.Nil(t, buffer.take(3, 1, acceptAll))
+
+	// a buffer of size 0 drops all proposals
+	buffer = newProposalBuffer(0)
+	require.False(t, buffer.add(p1, true))
+	require.Nil(t, buffer.take(3, 0, acceptAll))
+}
+
+func TestProposalBuffer_Verified(t *testing.T) {
+	newProposal := func(height int64, round int32) *types.Proposal {
+		return types.NewProposal(height, round, -1, types.BlockID{})
+	}
+
+	buffer := newProposalBuffer(2)
+
+	// a verified proposal replaces an unverified one, but not the other way
+	// around
+	unverified, verified := newProposal(3, 0), newProposal(3, 0)
+	require.True(t, buffer.add(unverified, false))
+	require.False(t, buffer.add(newProposal(3, 0), false))
+	require.True(t, buffer.add(verified, true))
+	require.False(t, buffer.add(newProposal(3, 0), false))
+	require.False(t, buffer.add(newProposal(3, 0), true))
+	require.Same(t, verified, buffer.take(3, 0, func(*types.Proposal) bool { return true }))
+
+	// a proposal is dropped when taken if it doesn't verify
+	require.True(t, buffer.add(unverified, false))
+	require.Nil(t, buffer.take(3, 0, func(*types.Proposal) bool { return false }))
+	require.Nil(t, buffer.take(3, 0, func(*types.Proposal) bool { return true }))
+}
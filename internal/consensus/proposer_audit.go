@@ -0,0 +1,67 @@
+package consensus
+
+import (
+	"github.com/tendermint/tendermint/types"
+)
+
+// proposerAudit audits proposer selection, which is meant to pick each
+// validator as proposer in proportion to its voting power. Over a window of
+// heights, it counts the blocks each validator proposed and compares that to
+// the number of blocks it was expected to propose given its share of the
+// voting power at each height.
+//
+// It is not thread-safe: the State accesses it under its own mutex.
+type proposerAudit struct {
+	window    int
+	heights   int
+	proposals map[string]int64   // proposals per validator address
+	expected  map[string]float64 // expected proposals per validator address
+}
+
+func newProposerAudit(window int) *proposerAudit {
+	a := &proposerAudit{window: window}
+	a.reset()
+	return a
+}
+
+func (a *proposerAudit) reset() {
+	a.heights = 0
+	a.proposals = make(map[string]int64)
+	a.expected = make(map[string]float64)
+}
+
+// record records the proposer of a block committed by the given validator
+// set. Once the window is complete, it returns the deviation of each
+// validator's share of the proposals from its expected share, as a fraction
+// of the heights in the window, and starts a new window. Otherwise, and if
+// the audit is disabled by a window of 0, it returns nil.
+func (a *proposerAudit) record(vals *types.ValidatorSet, proposer types.Address) map[string]float64 {
+	if a.window <= 0 {
+		return nil
+	}
+
+	totalPower := float64(vals.TotalVotingPower())
+	for _, val := range vals.Validators {
+		a.expected[val.Address.String()] += float64(val.VotingPower) / totalPower
+	}
+	a.proposals[proposer.String()]++
+	a.heights++
+
+	if a.heights < a.window {
+		return nil
+	}
+
+	deviations := make(map[string]float64, len(a.expected))
+	for address, expected := range a.expected {
+		deviations[address] = (float64(a.proposals[address]) - expected) / float64(a.heights)
+	}
+	// a proposer outside of the validator set is a deviation in itself
+	for address, proposals := range a.proposals {
+		if _, ok := a.expected[address]; !ok {
+			deviations[address] = float64(proposals) / float64(a.heights)
+		}
+	}
+
+	a.reset()
+	return deviations
+}
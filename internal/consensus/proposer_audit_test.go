@@ -0,0 +1,73 @@
+package consensus
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestProposerAudit(t *testing.T) {
+	const (
+		window    = 1000
+		windows   = 10
+		tolerance = 0.005
+	)
+
+	newValidator := func(power int64) *types.Validator {
+		return types.NewValidator(ed25519.GenPrivKey().PubKey(), power)
+	}
+	vals := types.NewValidatorSet([]*types.Validator{
+		newValidator(1), newValidator(2), newValidator(3), newValidator(10),
+	})
+
+	// Proposers selected by the validator set must stay within tolerance of
+	// their voting power, over every window.
+	audit := newProposerAudit(window)
+	reports := 0
+	for height := 1; height <= window*windows; height++ {
+		// change the validator set halfway through
+		if height == window*windows/2 {
+			require.NoError(t, vals.UpdateWithChangeSet([]*types.Validator{newValidator(5)}))
+		}
+
+		deviations := audit.record(vals, vals.GetProposer().Address)
+		vals.IncrementProposerPriority(1)
+
+		if height%window != 0 {
+			require.Nil(t, deviations)
+			continue
+		}
+		reports++
+		require.Len(t, deviations, vals.Size())
+		for address, deviation := range deviations {
+			require.LessOrEqual(t, math.Abs(deviation), tolerance, "validator %v at height %v", address, height)
+		}
+	}
+	require.Equal(t, windows, reports)
+
+	// A proposer selection that always favours the same validator is reported.
+	audit = newProposerAudit(window)
+	var deviations map[string]float64
+	for height := 1; height <= window; height++ {
+		deviations = audit.record(vals, vals.Validators[0].Address)
+	}
+	require.Len(t, deviations, vals.Size())
+	for _, val := range vals.Validators {
+		expected := float64(val.VotingPower) / float64(vals.TotalVotingPower())
+		if val == vals.Validators[0] {
+			require.InDelta(t, 1-expected, deviations[val.Address.String()], 1e-9)
+		} else {
+			require.InDelta(t, -expected, deviations[val.Address.String()], 1e-9)
+		}
+	}
+
+	// The audit is disabled by a window of 0.
+	audit = newProposerAudit(0)
+	for height := 1; height <= 10; height++ {
+		require.Nil(t, audit.record(vals, vals.GetProposer().Address))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/tendermint/tendermint/config"
 	cstypes "github.com/tendermint/tendermint/internal/consensus/types"
 	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
 	"github.com/tendermint/tendermint/internal/p2p"
@@ -98,6 +99,12 @@ const (
 
 type ReactorOption func(*Reactor)
 
+// FastSyncer is implemented by the fast sync reactor, which the Reactor hands
+// off to when it falls too far behind its peers, see SetFastSyncer.
+type FastSyncer interface {
+	SwitchToFastSync(sm.State) error
+}
+
 // Reactor defines a reactor for the consensus service.
 type Reactor struct {
 	service.BaseService
@@ -106,9 +113,11 @@ type Reactor struct {
 	eventBus *types.EventBus
 	Metrics  *Metrics
 
-	mtx      tmsync.RWMutex
-	peers    map[p2p.NodeID]*PeerState
-	waitSync bool
+	mtx        tmsync.RWMutex
+	peers      map[p2p.NodeID]*PeerState
+	waitSync   bool
+	catchUp    *catchUpDetector
+	fastSyncer FastSyncer
 
 	stateCh       *p2p.Channel
 	dataCh        *p2p.Channel
@@ -144,6 +153,7 @@ func NewReactor(
 		state:         cs,
 		waitSync:      waitSync,
 		peers:         make(map[p2p.NodeID]*PeerState),
+		catchUp:       newCatchUpDetector(cs.config.CatchUpLag, cs.config.CatchUpMinPeers),
 		Metrics:       NopMetrics(),
 		stateCh:       stateCh,
 		dataCh:        dataCh,
@@ -243,6 +253,12 @@ func (r *Reactor) SetEventBus(b *types.EventBus) {
 	r.state.SetEventBus(b)
 }
 
+// SetFastSyncer sets the fast syncer the reactor hands off to when it falls
+// too far behind its peers, see ConsensusConfig.CatchUpLag.
+func (r *Reactor) SetFastSyncer(fs FastSyncer) {
+	r.fastSyncer = fs
+}
+
 // WaitSync returns whether the consensus reactor is waiting for state/fast sync.
 func (r *Reactor) WaitSync() bool {
 	r.mtx.RLock()
@@ -261,6 +277,16 @@ func ReactorMetrics(metrics *Metrics) ReactorOption {
 func (r *Reactor) SwitchToConsensus(state sm.State, skipWAL bool) {
 	r.Logger.Info("switching to consensus")
 
+	// If we handed off to fast sync after falling behind, the consensus state
+	// was stopped and must be reset before starting it again.
+	select {
+	case <-r.state.Quit():
+		if err := r.state.Reset(); err != nil {
+			panic(fmt.Sprintf("failed to reset consensus state: %v", err))
+		}
+	default:
+	}
+
 	// we have no votes, so reconstruct LastCommit from SeenCommit
 	if state.LastBlockHeight > 0 {
 		r.state.reconstructLastCommit(state)
@@ -272,10 +298,12 @@ func (r *Reactor) SwitchToConsensus(state sm.State, skipWAL bool) {
 
 	r.mtx.Lock()
 	r.waitSync = false
+	r.catchUp.reset()
 	r.mtx.Unlock()
 
 	r.Metrics.FastSyncing.Set(0)
 	r.Metrics.StateSyncing.Set(0)
+	r.Metrics.CatchingUp.Set(0)
 
 	if skipWAL {
 		r.state.doWALCatchup = false
@@ -701,11 +729,96 @@ func (r *Reactor) gossipVotesForHeight(rs *cstypes.RoundState, prs *cstypes.Peer
 	return false
 }
 
+// voteSetKey identifies a vote set of the current height.
+type voteSetKey struct {
+	round     int32
+	votesType tmproto.SignedMsgType
+}
+
+// gossipVoteBitsForHeight is the counterpart of gossipVotesForHeight when
+// votes are gossiped as bit arrays: rather than sending the peer the votes it
+// is not known to have, it sends it the bit arrays of the vote sets it is
+// interested in, to which the peer replies with the votes missing from them.
+// Only the LastCommit is still sent vote by vote, since bit arrays are only
+// reconciled for the current height. It returns true if a vote was sent.
+func (r *Reactor) gossipVoteBitsForHeight(
+	rs *cstypes.RoundState,
+	prs *cstypes.PeerRoundState,
+	ps *PeerState,
+	advertised map[voteSetKey]string,
+) bool {
+	if prs.Step == cstypes.RoundStepNewHeight {
+		if r.pickSendVote(ps, rs.LastCommit) {
+			r.Logger.Debug("picked rs.LastCommit to send", "height", prs.Height, "peer", ps.peerID)
+			return true
+		}
+	}
+
+	if prs.Round != -1 && prs.Round <= rs.Round {
+		r.sendVoteBits(ps, rs.Votes.Prevotes(prs.Round), advertised)
+		r.sendVoteBits(ps, rs.Votes.Precommits(prs.Round), advertised)
+	}
+
+	if prs.ProposalPOLRound != -1 {
+		if polPrevotes := rs.Votes.Prevotes(prs.ProposalPOLRound); polPrevotes != nil {
+			r.sendVoteBits(ps, polPrevotes, advertised)
+		}
+	}
+
+	return false
+}
+
+// sendVoteBits sends the peer the bit array of the votes we have in the given
+// vote set, unless neither it nor the votes the peer is known to have and we
+// lack changed since it was last sent. The bit array is sent for the zero
+// BlockID, i.e. it covers votes for any block, which nodes gossiping votes by
+// pushing them apply as they would a reply to a VoteSetMaj23 for nil.
+func (r *Reactor) sendVoteBits(ps *PeerState, votes *types.VoteSet, advertised map[voteSetKey]string) {
+	var (
+		height    = votes.GetHeight()
+		round     = votes.GetRound()
+		votesType = tmproto.SignedMsgType(votes.Type())
+	)
+
+	ps.EnsureVoteBitArrays(height, votes.Size())
+
+	ourVotes := votes.BitArray()
+	wanted := ps.GetVoteBitArray(height, round, votesType).Sub(ourVotes)
+
+	key := voteSetKey{round: round, votesType: votesType}
+	state := ourVotes.String() + wanted.String()
+	if advertised[key] == state {
+		return
+	}
+	advertised[key] = state
+
+	msg := &tmcons.VoteSetBits{
+		Height: height,
+		Round:  round,
+		Type:   votesType,
+	}
+	if votesProto := ourVotes.ToProto(); votesProto != nil {
+		msg.Votes = *votesProto
+	}
+
+	r.Logger.Debug("sending vote set bits", "peer", ps.peerID, "height", height, "round", round, "type", votesType)
+	r.voteSetBitsCh.Out <- p2p.Envelope{
+		To:      ps.peerID,
+		Message: msg,
+	}
+}
+
 func (r *Reactor) gossipVotesRoutine(ps *PeerState) {
 	logger := r.Logger.With("peer", ps.peerID)
 
 	defer ps.broadcastWG.Done()
 
+	// the vote set bit arrays last sent to the peer, when gossiping bit arrays
+	var (
+		advertisedHeight int64
+		advertised       map[voteSetKey]string
+	)
+
 	// XXX: simple hack to throttle logs upon sleep
 	logThrottle := 0
 
@@ -736,7 +849,14 @@ OUTER_LOOP:
 
 		// if height matches, then send LastCommit, Prevotes, and Precommits
 		if rs.Height == prs.Height {
-			if r.gossipVotesForHeight(rs, prs, ps) {
+			if r.state.config.VoteGossip == config.VoteGossipBitArray {
+				if advertised == nil || advertisedHeight != rs.Height {
+					advertisedHeight, advertised = rs.Height, make(map[voteSetKey]string)
+				}
+				if r.gossipVoteBitsForHeight(rs, prs, ps, advertised) {
+					continue OUTER_LOOP
+				}
+			} else if r.gossipVotesForHeight(rs, prs, ps) {
 				continue OUTER_LOOP
 			}
 		}
@@ -965,6 +1085,9 @@ func (r *Reactor) processPeerUpdate(peerUpdate p2p.PeerUpdate) {
 				r.mtx.Unlock()
 
 				ps.SetRunning(false)
+
+				// the peer may have been one of those we were catching up with
+				r.updateCatchUp()
 			}()
 		}
 	}
@@ -994,6 +1117,7 @@ func (r *Reactor) handleStateMessage(envelope p2p.Envelope, msgI Message) error
 		}
 
 		ps.ApplyNewRoundStepMessage(msgI.(*NewRoundStepMessage))
+		r.updateCatchUp()
 
 	case *tmcons.NewValidBlock:
 		ps.ApplyNewValidBlockMessage(msgI.(*NewValidBlockMessage))
@@ -1163,20 +1287,36 @@ func (r *Reactor) handleVoteSetBitsMessage(envelope p2p.Envelope, msgI Message)
 		vsbMsg := msgI.(*VoteSetBitsMessage)
 
 		if height == msg.Height {
-			var ourVotes *bits.BitArray
+			var voteSet *types.VoteSet
 
 			switch msg.Type {
 			case tmproto.PrevoteType:
-				ourVotes = votes.Prevotes(msg.Round).BitArrayByBlockID(vsbMsg.BlockID)
+				voteSet = votes.Prevotes(msg.Round)
 
 			case tmproto.PrecommitType:
-				ourVotes = votes.Precommits(msg.Round).BitArrayByBlockID(vsbMsg.BlockID)
+				voteSet = votes.Precommits(msg.Round)
 
 			default:
 				panic("bad VoteSetBitsMessage field type; forgot to add a check in ValidateBasic?")
 			}
 
-			ps.ApplyVoteSetBitsMessage(vsbMsg, ourVotes)
+			ourVotes := voteSet.BitArrayByBlockID(vsbMsg.BlockID)
+
+			// when gossiping bit arrays, votes are only sent in reply to the
+			// peer's bit arrays, and only those missing from them
+			if r.state.config.VoteGossip == config.VoteGossipBitArray && voteSet != nil {
+				// the bit arrays may be the first we hear of the peer's votes
+				ps.EnsureVoteBitArrays(height, voteSet.Size())
+				ps.ApplyVoteSetBitsMessage(vsbMsg, ourVotes)
+
+				for {
+					if !r.pickSendVote(ps, voteSet) {
+						break
+					}
+				}
+			} else {
+				ps.ApplyVoteSetBitsMessage(vsbMsg, ourVotes)
+			}
 		} else {
 			ps.ApplyVoteSetBitsMessage(vsbMsg, nil)
 		}
@@ -1366,6 +1506,80 @@ func (r *Reactor) processPeerUpdates() {
 	}
 }
 
+// updateCatchUp switches the consensus state in or out of catch-up, see
+// catchUpDetector, depending on how far behind our peers we are. Only peers
+// that have recently served us votes or block parts our consensus state
+// verified count, rather than all the peers claiming to be ahead of us.
+//
+// If a fast syncer is set, catching up stops the consensus state and hands
+// off to it, and it switches back to consensus once it has caught up.
+// Otherwise, we just stop proposing and voting while catching up on the blocks
+// our peers gossip.
+func (r *Reactor) updateCatchUp() {
+	if r.WaitSync() {
+		return
+	}
+	height := r.state.GetLastHeight() + 1
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	peerHeights := make([]int64, 0, len(r.peers))
+	for _, ps := range r.peers {
+		// Peers ahead of us help us catch up by sending us the commits and
+		// block parts of our current height, or we just added the last one.
+		if served := ps.ServedHeight(); served > 0 && served >= height-1 {
+			peerHeights = append(peerHeights, ps.GetHeight())
+		}
+	}
+
+	catchingUp, changed := r.catchUp.update(height, peerHeights)
+	if !changed {
+		return
+	}
+
+	switch {
+	case catchingUp && r.fastSyncer != nil:
+		r.Logger.Info("far behind peers, switching to fast sync",
+			"height", height, "peer_height", majorityHeight(peerHeights))
+		r.Metrics.CatchingUp.Set(1)
+
+		r.waitSync = true
+		go r.switchToFastSync()
+		return
+
+	case catchingUp:
+		r.Logger.Info("far behind peers, catching up without proposing or voting",
+			"height", height, "peer_height", majorityHeight(peerHeights))
+		r.Metrics.CatchingUp.Set(1)
+
+	default:
+		r.Logger.Info("caught up with peers, resuming proposing and voting",
+			"height", height, "peer_height", majorityHeight(peerHeights))
+		r.Metrics.CatchingUp.Set(0)
+	}
+	r.state.setCatchingUp(catchingUp)
+}
+
+// switchToFastSync stops the consensus state and hands off to the fast syncer
+// to catch up with our peers. It expects waitSync to be set already, so that
+// we ignore consensus messages in the meantime.
+func (r *Reactor) switchToFastSync() {
+	if err := r.state.Stop(); err != nil {
+		r.Logger.Error("failed to stop consensus state", "err", err)
+		return
+	}
+	r.state.Wait()
+
+	r.Metrics.FastSyncing.Set(1)
+
+	state := r.state.GetState()
+	if err := r.fastSyncer.SwitchToFastSync(state); err != nil {
+		r.Logger.Error("failed to switch to fast sync; resuming consensus", "err", err)
+		r.SwitchToConsensus(state, true)
+	}
+}
+
 func (r *Reactor) peerStatsRoutine() {
 	for {
 		if !r.IsRunning() {
@@ -1381,8 +1595,9 @@ func (r *Reactor) peerStatsRoutine() {
 				continue
 			}
 
-			switch msg.Msg.(type) {
+			switch m := msg.Msg.(type) {
 			case *VoteMessage:
+				ps.RecordServedHeight(m.Vote.Height)
 				if numVotes := ps.RecordVote(); numVotes%votesToContributeToBecomeGoodPeer == 0 {
 					r.peerUpdates.SendUpdate(p2p.PeerUpdate{
 						NodeID: msg.PeerID,
@@ -1391,6 +1606,7 @@ func (r *Reactor) peerStatsRoutine() {
 				}
 
 			case *BlockPartMessage:
+				ps.RecordServedHeight(m.Height)
 				if numParts := ps.RecordBlockPart(); numParts%blocksToContributeToBecomeGoodPeer == 0 {
 					r.peerUpdates.SendUpdate(p2p.PeerUpdate{
 						NodeID: msg.PeerID,
@@ -18,6 +18,8 @@ import (
 	abci "github.com/tendermint/tendermint/abci/types"
 	cfg "github.com/tendermint/tendermint/config"
 	cryptoenc "github.com/tendermint/tendermint/crypto/encoding"
+	"github.com/tendermint/tendermint/crypto/tmhash"
+	cstypes "github.com/tendermint/tendermint/internal/consensus/types"
 	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
 	"github.com/tendermint/tendermint/internal/mempool"
 	mempoolv0 "github.com/tendermint/tendermint/internal/mempool/v0"
@@ -26,6 +28,7 @@ import (
 	"github.com/tendermint/tendermint/internal/test/factory"
 	"github.com/tendermint/tendermint/libs/log"
 	tmcons "github.com/tendermint/tendermint/proto/tendermint/consensus"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
 	sm "github.com/tendermint/tendermint/state"
 	statemocks "github.com/tendermint/tendermint/state/mocks"
 	"github.com/tendermint/tendermint/store"
@@ -719,3 +722,141 @@ func TestReactorValidatorSetChanges(t *testing.T) {
 
 	waitForBlockWithUpdatedValsAndValidateIt(t, nPeers, activeVals, blocksSubs, states)
 }
+
+func TestReactorVoteBitArrayGossip(t *testing.T) {
+	config := configSetup(t)
+
+	states, cleanup := randConsensusState(t, config, 4, "consensus_reactor_test",
+		newMockTickerFunc(true), newCounter, func(c *cfg.Config) {
+			c.Consensus.VoteGossip = cfg.VoteGossipBitArray
+		})
+	t.Cleanup(cleanup)
+
+	// every validator prevotes for the same block
+	hash := tmhash.Sum([]byte("block"))
+	header := types.PartSetHeader{Total: 1, Hash: tmhash.Sum([]byte("part"))}
+	prevotes := make([]*types.Vote, len(states))
+	for _, cs := range states {
+		pubKey, err := cs.privValidator.GetPubKey(context.Background())
+		require.NoError(t, err)
+		index, _ := cs.Validators.GetByAddress(pubKey.Address())
+
+		vs := newValidatorStub(cs.privValidator, index)
+		vs.Height = cs.Height
+		prevotes[index] = signVote(vs, config, tmproto.PrevoteType, hash, header)
+	}
+
+	type node struct {
+		id        p2p.NodeID
+		state     *State
+		reactor   *Reactor
+		peer      *PeerState
+		voteOut   chan p2p.Envelope
+		bitsOut   chan p2p.Envelope
+		announced map[voteSetKey]string
+	}
+
+	newNode := func(id p2p.NodeID, cs *State, prevotes ...*types.Vote) *node {
+		n := &node{
+			id:        id,
+			state:     cs,
+			voteOut:   make(chan p2p.Envelope, len(states)),
+			bitsOut:   make(chan p2p.Envelope, 10),
+			announced: make(map[voteSetKey]string),
+		}
+		newChannel := func(chID p2p.ChannelID, outCh chan p2p.Envelope) *p2p.Channel {
+			return p2p.NewChannel(chID, new(tmcons.Message), make(chan p2p.Envelope), outCh, make(chan p2p.PeerError))
+		}
+		n.reactor = NewReactor(
+			log.TestingLogger(),
+			cs,
+			newChannel(StateChannel, make(chan p2p.Envelope, 10)),
+			newChannel(DataChannel, make(chan p2p.Envelope, 10)),
+			newChannel(VoteChannel, n.voteOut),
+			newChannel(VoteSetBitsChannel, n.bitsOut),
+			p2p.NewPeerUpdates(make(chan p2p.PeerUpdate), 1),
+			false,
+		)
+
+		for _, vote := range prevotes {
+			added, err := cs.Votes.AddVote(vote, "")
+			require.NoError(t, err)
+			require.True(t, added)
+		}
+		return n
+	}
+
+	// a and b hold overlapping sets of prevotes: both have those of validators
+	// 1 and 2, only a has that of validator 0 and only b that of validator 3
+	a := newNode("aa", states[0], prevotes[0], prevotes[1], prevotes[2])
+	b := newNode("bb", states[1], prevotes[1], prevotes[2], prevotes[3])
+	for _, pair := range [][2]*node{{a, b}, {b, a}} {
+		n, peer := pair[0], pair[1]
+		n.peer = NewPeerState(log.TestingLogger(), peer.id)
+		n.peer.ApplyNewRoundStepMessage(&NewRoundStepMessage{
+			Height: peer.state.Height,
+			Round:  0,
+			Step:   cstypes.RoundStepPrevote,
+		})
+		n.reactor.peers[peer.id] = n.peer
+	}
+
+	// reconcile has from send its bit arrays to to, which replies with the
+	// votes from is missing. It returns the number of bit arrays sent and the
+	// votes sent back, which from adds to its own.
+	reconcile := func(from, to *node) (int, []*types.Vote) {
+		from.reactor.gossipVoteBitsForHeight(from.state.GetRoundState(), from.peer.GetRoundState(), from.peer, from.announced)
+
+		var sent int
+	BITS:
+		for {
+			select {
+			case envelope := <-from.bitsOut:
+				require.Equal(t, to.id, envelope.To)
+				envelope.From, envelope.To = from.id, ""
+				require.NoError(t, to.reactor.handleMessage(VoteSetBitsChannel, envelope))
+				sent++
+			default:
+				break BITS
+			}
+		}
+
+		var votes []*types.Vote
+		for {
+			select {
+			case envelope := <-to.voteOut:
+				require.Equal(t, from.id, envelope.To)
+				vote, err := types.VoteFromProto(envelope.Message.(*tmcons.Vote).Vote)
+				require.NoError(t, err)
+				_, err = from.state.Votes.AddVote(vote, to.id)
+				require.NoError(t, err)
+				votes = append(votes, vote)
+			default:
+				return sent, votes
+			}
+		}
+	}
+
+	// both nodes send their prevote and precommit bit arrays, and only
+	// receive the prevote they are missing
+	sent, votes := reconcile(a, b)
+	require.Equal(t, 2, sent)
+	require.Equal(t, []*types.Vote{prevotes[3]}, votes)
+
+	sent, votes = reconcile(b, a)
+	require.Equal(t, 2, sent)
+	require.Equal(t, []*types.Vote{prevotes[0]}, votes)
+
+	// a sends its prevote bit array again since it received a prevote, but b
+	// has nothing left to send
+	sent, votes = reconcile(a, b)
+	require.Equal(t, 1, sent)
+	require.Empty(t, votes)
+
+	// unchanged bit arrays are not sent again
+	sent, _ = reconcile(a, b)
+	require.Zero(t, sent)
+
+	require.True(t, a.state.Votes.Prevotes(0).HasAll())
+	require.True(t, b.state.Votes.Prevotes(0).HasAll())
+}
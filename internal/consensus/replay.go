@@ -206,6 +206,13 @@ type Handshaker struct {
 	genDoc       *types.GenesisDoc
 	logger       log.Logger
 
+	// maximum number of blocks to replay for an app behind the store, 0 if
+	// unlimited
+	maxRewindDepth int64
+
+	// if set, the signatures of the commits of replayed blocks aren't verified
+	skipSigVerification bool
+
 	nBlocks int // number of blocks applied to the state
 }
 
@@ -233,6 +240,25 @@ func (h *Handshaker) SetEventBus(eventBus types.BlockEventPublisher) {
 	h.eventBus = eventBus
 }
 
+// SetMaxRewindDepth sets the maximum number of heights the app may be behind
+// the block store, e.g. because it rolled its state back, for the handshake to
+// replay the missing blocks. Beyond it, the handshake fails with
+// sm.ErrAppRewindTooDeep. If not called, or if 0, the depth is unlimited.
+func (h *Handshaker) SetMaxRewindDepth(depth int64) {
+	h.maxRewindDepth = depth
+}
+
+// SetSkipSignatureVerification sets whether the final block replayed from the
+// block store, which was validated before it was saved, is applied to the state
+// without verifying the signatures of its last commit again. It is still checked
+// to link up with the state. The blocks replayed before it are executed without
+// being validated against the state either way; if set, they are additionally
+// checked to be consistent with each other, without verifying signatures. If not
+// called, signatures of the final block are verified.
+func (h *Handshaker) SetSkipSignatureVerification(skip bool) {
+	h.skipSigVerification = skip
+}
+
 // NBlocks returns the number of blocks applied to the state.
 func (h *Handshaker) NBlocks() int {
 	return h.nBlocks
@@ -268,7 +294,7 @@ func (h *Handshaker) Handshake(proxyApp proxy.AppConns) error {
 	// Replay blocks up to the latest in the blockstore.
 	_, err = h.ReplayBlocks(h.initialState, appHash, blockHeight, proxyApp)
 	if err != nil {
-		return fmt.Errorf("error on replay: %v", err)
+		return fmt.Errorf("error on replay: %w", err)
 	}
 
 	h.logger.Info("Completed ABCI Handshake - Tendermint and App are synced",
@@ -300,24 +326,19 @@ func (h *Handshaker) ReplayBlocks(
 		"stateHeight",
 		stateBlockHeight)
 
+	// Refuse to replay more blocks than allowed, before anything is sent to the
+	// app, including InitChain.
+	if h.maxRewindDepth > 0 && storeBlockHeight-appBlockHeight > h.maxRewindDepth {
+		return appHash, sm.ErrAppRewindTooDeep{
+			AppHeight:   appBlockHeight,
+			StoreHeight: storeBlockHeight,
+			MaxDepth:    h.maxRewindDepth,
+		}
+	}
+
 	// If appBlockHeight == 0 it means that we are at genesis and hence should send InitChain.
 	if appBlockHeight == 0 {
-		validators := make([]*types.Validator, len(h.genDoc.Validators))
-		for i, val := range h.genDoc.Validators {
-			validators[i] = types.NewValidator(val.PubKey, val.Power)
-		}
-		validatorSet := types.NewValidatorSet(validators)
-		nextVals := types.TM2PB.ValidatorUpdates(validatorSet)
-		pbParams := h.genDoc.ConsensusParams.ToProto()
-		req := abci.RequestInitChain{
-			Time:            h.genDoc.GenesisTime,
-			ChainId:         h.genDoc.ChainID,
-			InitialHeight:   h.genDoc.InitialHeight,
-			ConsensusParams: &pbParams,
-			Validators:      nextVals,
-			AppStateBytes:   h.genDoc.AppState,
-		}
-		res, err := proxyApp.Consensus().InitChainSync(context.Background(), req)
+		res, err := proxyApp.Consensus().InitChainSync(context.Background(), initChainRequest(h.genDoc))
 		if err != nil {
 			return nil, err
 		}
@@ -435,6 +456,25 @@ func (h *Handshaker) ReplayBlocks(
 		appBlockHeight, storeBlockHeight, stateBlockHeight))
 }
 
+// initChainRequest returns the InitChain request for the genesis doc.
+func initChainRequest(genDoc *types.GenesisDoc) abci.RequestInitChain {
+	validators := make([]*types.Validator, len(genDoc.Validators))
+	for i, val := range genDoc.Validators {
+		validators[i] = types.NewValidator(val.PubKey, val.Power)
+	}
+	validatorSet := types.NewValidatorSet(validators)
+	nextVals := types.TM2PB.ValidatorUpdates(validatorSet)
+	pbParams := genDoc.ConsensusParams.ToProto()
+	return abci.RequestInitChain{
+		Time:            genDoc.GenesisTime,
+		ChainId:         genDoc.ChainID,
+		InitialHeight:   genDoc.InitialHeight,
+		ConsensusParams: &pbParams,
+		Validators:      nextVals,
+		AppStateBytes:   genDoc.AppState,
+	}
+}
+
 func (h *Handshaker) replayBlocks(
 	state sm.State,
 	proxyApp proxy.AppConns,
@@ -468,6 +508,14 @@ func (h *Handshaker) replayBlocks(
 		if len(appHash) > 0 {
 			assertAppHashEqualsOneFromBlock(appHash, block)
 		}
+		// These blocks are executed without being validated against the state.
+		// If the final block isn't fully validated either, at least check that
+		// the blocks in the store are consistent with each other.
+		if h.skipSigVerification {
+			if err := h.checkReplayedBlock(block); err != nil {
+				return nil, err
+			}
+		}
 
 		if i == finalBlock && !mutateState {
 			// We emit events for the index services at the final block due to the sync issue when
@@ -504,6 +552,40 @@ func (h *Handshaker) replayBlocks(
 	return appHash, nil
 }
 
+// checkReplayedBlock checks the structural integrity of a block replayed from
+// the block store without being validated against the state: that it hashes to
+// the ID it was saved with, links to the block below it and that its last
+// commit is one for that block by +2/3 of the last validators. The signatures
+// of the commit aren't verified.
+func (h *Handshaker) checkReplayedBlock(block *types.Block) error {
+	meta := h.store.LoadBlockMeta(block.Height)
+	if meta == nil {
+		return sm.ErrInvalidBlock(fmt.Errorf("no block meta for replayed block at height %d", block.Height))
+	}
+	if hash := block.Hash(); !bytes.Equal(hash, meta.BlockID.Hash) {
+		return sm.ErrInvalidBlock(fmt.Errorf("replayed block at height %d: hash %X doesn't match block ID %v",
+			block.Height, hash, meta.BlockID))
+	}
+	if block.Height == h.genDoc.InitialHeight {
+		return nil
+	}
+
+	// the block below may have been pruned, in which case only its commit is checked
+	if lastMeta := h.store.LoadBlockMeta(block.Height - 1); lastMeta != nil &&
+		!block.LastBlockID.Equals(lastMeta.BlockID) {
+		return sm.ErrInvalidBlock(fmt.Errorf("replayed block at height %d: last block ID %v doesn't match %v",
+			block.Height, block.LastBlockID, lastMeta.BlockID))
+	}
+	lastVals, err := h.stateStore.LoadValidators(block.Height - 1)
+	if err != nil {
+		return err
+	}
+	if err := lastVals.VerifyCommitStructure(block.LastBlockID, block.Height-1, block.LastCommit); err != nil {
+		return sm.ErrInvalidBlock(fmt.Errorf("replayed block at height %d: %w", block.Height, err))
+	}
+	return nil
+}
+
 // ApplyBlock on the proxyApp with the last block.
 func (h *Handshaker) replayBlock(state sm.State, height int64, proxyApp proxy.AppConnConsensus) (sm.State, error) {
 	block := h.store.LoadBlock(height)
@@ -515,7 +597,11 @@ func (h *Handshaker) replayBlock(state sm.State, height int64, proxyApp proxy.Ap
 	blockExec.SetEventBus(h.eventBus)
 
 	var err error
-	state, err = blockExec.ApplyBlock(state, meta.BlockID, block)
+	if h.skipSigVerification {
+		state, err = blockExec.ApplyLocalBlock(state, meta.BlockID, block)
+	} else {
+		state, err = blockExec.ApplyBlock(state, meta.BlockID, block)
+	}
 	if err != nil {
 		return sm.State{}, err
 	}
@@ -325,6 +325,8 @@ func newConsensusStateForReplay(config cfg.BaseConfig, csConfig *cfg.ConsensusCo
 
 	handshaker := NewHandshaker(stateStore, state, blockStore, gdoc)
 	handshaker.SetEventBus(eventBus)
+	handshaker.SetMaxRewindDepth(csConfig.MaxRewindDepth)
+	handshaker.SetSkipSignatureVerification(csConfig.ReplaySkipSignatureVerification)
 	err = handshaker.Handshake(proxyApp)
 	if err != nil {
 		tmos.Exit(fmt.Sprintf("Error on handshake: %v", err))
@@ -3,6 +3,7 @@ package consensus
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -24,6 +25,7 @@ import (
 	cfg "github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/crypto"
 	cryptoenc "github.com/tendermint/tendermint/crypto/encoding"
+	"github.com/tendermint/tendermint/crypto/tmhash"
 	mempl "github.com/tendermint/tendermint/internal/mempool"
 	"github.com/tendermint/tendermint/internal/test/factory"
 	"github.com/tendermint/tendermint/libs/log"
@@ -1001,6 +1003,192 @@ func TestHandshakePanicsIfAppReturnsWrongAppHash(t *testing.T) {
 	}
 }
 
+func TestHandshakeMaxRewindDepth(t *testing.T) {
+	config := ResetConfig("handshake_test_")
+	t.Cleanup(func() { os.RemoveAll(config.RootDir) })
+	privVal, err := privval.LoadFilePV(config.PrivValidator.KeyFile(), config.PrivValidator.StateFile())
+	require.NoError(t, err)
+	pubKey, err := privVal.GetPubKey(context.Background())
+	require.NoError(t, err)
+	stateDB, state, store := stateAndStore(config, pubKey, 0x0)
+	stateStore := sm.NewStore(stateDB)
+	genDoc, _ := sm.MakeGenesisDocFromFile(config.GenesisFile())
+	state.LastValidators = state.Validators.Copy()
+	store.chain = sf.MakeBlocks(5, &state, privVal)
+	// the validator sets of the replayed blocks
+	require.NoError(t, stateStore.SaveValidatorSets(1, 5, state.Validators))
+
+	testcases := map[string]struct {
+		appHeight      byte
+		maxRewindDepth int64
+		expectErr      bool
+	}{
+		"synced app":              {5, 1, false},
+		"app one height behind":   {4, 1, false},
+		"rewind within the limit": {2, 3, false},
+		"rewind beyond the limit": {1, 3, true},
+		"unlimited rewind":        {1, 0, false},
+	}
+	for desc, tc := range testcases {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			app := &rewoundApp{height: tc.appHeight}
+			proxyApp := proxy.NewAppConns(proxy.NewLocalClientCreator(app))
+			require.NoError(t, proxyApp.Start())
+			t.Cleanup(func() {
+				if err := proxyApp.Stop(); err != nil {
+					t.Error(err)
+				}
+			})
+
+			h := NewHandshaker(stateStore, state, store, genDoc)
+			h.SetMaxRewindDepth(tc.maxRewindDepth)
+			err := h.Handshake(proxyApp)
+			if !tc.expectErr {
+				require.NoError(t, err)
+				require.Equal(t, byte(5), app.height)
+				return
+			}
+
+			var rewindErr sm.ErrAppRewindTooDeep
+			require.True(t, errors.As(err, &rewindErr), "unexpected error %v", err)
+			require.Equal(t, sm.ErrAppRewindTooDeep{AppHeight: 1, StoreHeight: 5, MaxDepth: 3}, rewindErr)
+			// no block was replayed
+			require.Equal(t, tc.appHeight, app.height)
+		})
+	}
+}
+
+func TestHandshakeChecksReplayedBlocks(t *testing.T) {
+	config := ResetConfig("handshake_test_")
+	t.Cleanup(func() { os.RemoveAll(config.RootDir) })
+	privVal, err := privval.LoadFilePV(config.PrivValidator.KeyFile(), config.PrivValidator.StateFile())
+	require.NoError(t, err)
+	pubKey, err := privVal.GetPubKey(context.Background())
+	require.NoError(t, err)
+	stateDB, state, store := stateAndStore(config, pubKey, 0x0)
+	stateStore := sm.NewStore(stateDB)
+	genDoc, _ := sm.MakeGenesisDocFromFile(config.GenesisFile())
+	state.LastValidators = state.Validators.Copy()
+	chain := sf.MakeBlocks(5, &state, privVal)
+	require.NoError(t, stateStore.SaveValidatorSets(1, 5, state.Validators))
+
+	// copyBlock returns a copy of the block at the given height to corrupt
+	copyBlock := func(height int64) *types.Block {
+		pb, err := chain[height-1].ToProto()
+		require.NoError(t, err)
+		block, err := types.BlockFromProto(pb)
+		require.NoError(t, err)
+		return block
+	}
+
+	testcases := map[string]struct {
+		skipSigVerification bool
+		corrupt             func() *types.Block
+		expectErr           bool
+	}{
+		"intact blocks": {true, nil, false},
+		"unsigned last commit": {true, func() *types.Block {
+			block := copyBlock(3)
+			block.LastCommit.Signatures[0] = types.NewCommitSigAbsent()
+			return block
+		}, true},
+		"last block ID not linking": {true, func() *types.Block {
+			block := copyBlock(3)
+			block.LastBlockID.Hash = tmrand.Bytes(tmhash.Size)
+			return block
+		}, true},
+		// the replayed blocks aren't validated against the state
+		"unchecked without the flag": {false, func() *types.Block {
+			block := copyBlock(3)
+			block.LastCommit.Signatures[0] = types.NewCommitSigAbsent()
+			return block
+		}, false},
+	}
+	for desc, tc := range testcases {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			store.chain = append([]*types.Block(nil), chain...)
+			if tc.corrupt != nil {
+				store.chain[2] = tc.corrupt()
+			}
+			// the app is far enough behind for all the blocks to be replayed
+			// without being applied to the state
+			app := &rewoundApp{height: 1}
+			proxyApp := proxy.NewAppConns(proxy.NewLocalClientCreator(app))
+			require.NoError(t, proxyApp.Start())
+			t.Cleanup(func() {
+				if err := proxyApp.Stop(); err != nil {
+					t.Error(err)
+				}
+			})
+
+			h := NewHandshaker(stateStore, state, store, genDoc)
+			h.SetSkipSignatureVerification(tc.skipSigVerification)
+			err := h.Handshake(proxyApp)
+			if tc.expectErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), "replayed block at height 3")
+				// the blocks below the corrupted one were replayed
+				require.Equal(t, byte(2), app.height)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, byte(5), app.height)
+		})
+	}
+}
+
+func BenchmarkHandshake(b *testing.B) {
+	config := ResetConfig("handshake_bench_")
+	b.Cleanup(func() { os.RemoveAll(config.RootDir) })
+	privVal, err := privval.LoadFilePV(config.PrivValidator.KeyFile(), config.PrivValidator.StateFile())
+	require.NoError(b, err)
+	pubKey, err := privVal.GetPubKey(context.Background())
+	require.NoError(b, err)
+	stateDB, state, store := stateAndStore(config, pubKey, 0x0)
+	stateStore := sm.NewStore(stateDB)
+	genDoc, _ := sm.MakeGenesisDocFromFile(config.GenesisFile())
+	state.LastValidators = state.Validators.Copy()
+	store.chain = sf.MakeBlocks(100, &state, privVal)
+	require.NoError(b, stateStore.SaveValidatorSets(1, 100, state.Validators))
+
+	for _, skip := range []bool{false, true} {
+		skip := skip
+		b.Run(fmt.Sprintf("skip-signature-verification=%v", skip), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				app := &rewoundApp{height: 1}
+				proxyApp := proxy.NewAppConns(proxy.NewLocalClientCreator(app))
+				require.NoError(b, proxyApp.Start())
+
+				h := NewHandshaker(stateStore, state, store, genDoc)
+				h.SetSkipSignatureVerification(skip)
+				require.NoError(b, h.Handshake(proxyApp))
+				require.NoError(b, proxyApp.Stop())
+			}
+		})
+	}
+}
+
+// rewoundApp is an app whose state was rolled back to a given height, and
+// which commits the app hashes of the blocks made by sf.MakeBlocks.
+type rewoundApp struct {
+	abci.BaseApplication
+	height byte
+}
+
+func (app *rewoundApp) Info(req abci.RequestInfo) abci.ResponseInfo {
+	return abci.ResponseInfo{
+		LastBlockHeight:  int64(app.height),
+		LastBlockAppHash: []byte{app.height},
+	}
+}
+
+func (app *rewoundApp) Commit() abci.ResponseCommit {
+	app.height++
+	return abci.ResponseCommit{Data: []byte{app.height}}
+}
+
 type badApp struct {
 	abci.BaseApplication
 	numBlocks           byte
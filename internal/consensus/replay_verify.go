@@ -0,0 +1,100 @@
+package consensus
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/proxy"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+// VerifyReplay is an offline check of the blocks in the block store: it
+// replays them against the app behind proxyApp, normally a fresh instance, and
+// checks that the app hash after each block matches the one recorded in the
+// next header, or in the state for the latest block. Replay starts at the
+// first block the app hasn't committed, which must be at most fromHeight, and
+// stops after toHeight. Blocks replayed below fromHeight, to bring the app up
+// to it, are checked as well, since any later result would be meaningless if
+// they diverged.
+//
+// The first height whose app hash diverges is reported as an
+// sm.ErrAppHashDivergence. The state and block stores are only read from, so
+// this can be run on the stores of a stopped node, but not against its app.
+func VerifyReplay(
+	logger log.Logger,
+	stateStore sm.Store,
+	blockStore sm.BlockStore,
+	genDoc *types.GenesisDoc,
+	proxyApp proxy.AppConns,
+	fromHeight, toHeight int64,
+) error {
+	res, err := proxyApp.Query().InfoSync(context.Background(), proxy.RequestInfo)
+	if err != nil {
+		return fmt.Errorf("error calling Info: %w", err)
+	}
+	appHeight := res.LastBlockHeight
+
+	firstHeight := appHeight + 1
+	if appHeight == 0 {
+		firstHeight = genDoc.InitialHeight
+	}
+
+	switch {
+	case fromHeight < genDoc.InitialHeight || fromHeight > toHeight:
+		return fmt.Errorf("invalid height range %d-%d", fromHeight, toHeight)
+	case toHeight > blockStore.Height():
+		return fmt.Errorf("height %d is above the block store height %d", toHeight, blockStore.Height())
+	case firstHeight > fromHeight:
+		return fmt.Errorf("app is already at height %d, can't replay from height %d", appHeight, fromHeight)
+	case firstHeight < blockStore.Base():
+		return sm.ErrAppBlockHeightTooLow{AppHeight: appHeight, StoreBase: blockStore.Base()}
+	}
+
+	state, err := stateStore.Load()
+	if err != nil {
+		return err
+	}
+
+	if appHeight == 0 {
+		if _, err := proxyApp.Consensus().InitChainSync(context.Background(), initChainRequest(genDoc)); err != nil {
+			return fmt.Errorf("error calling InitChain: %w", err)
+		}
+	}
+
+	for height := firstHeight; height <= toHeight; height++ {
+		block := blockStore.LoadBlock(height)
+		if block == nil {
+			return sm.ErrUnknownBlock{Height: height}
+		}
+
+		var expected []byte
+		switch {
+		case height < blockStore.Height():
+			meta := blockStore.LoadBlockMeta(height + 1)
+			if meta == nil {
+				return sm.ErrUnknownBlock{Height: height + 1}
+			}
+			expected = meta.Header.AppHash
+		case height == state.LastBlockHeight:
+			expected = state.AppHash
+		default:
+			return fmt.Errorf("no app hash is recorded for height %d", height)
+		}
+
+		logger.Info("replaying block", "height", height)
+		appHash, err := sm.ExecCommitBlock(
+			nil, proxyApp.Consensus(), block, logger, stateStore, genDoc.InitialHeight, state)
+		if err != nil {
+			return fmt.Errorf("error replaying block %d: %w", height, err)
+		}
+
+		if !bytes.Equal(appHash, expected) {
+			return sm.ErrAppHashDivergence{Height: height, Core: expected, App: appHash}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package consensus
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/privval"
+	"github.com/tendermint/tendermint/proxy"
+	sm "github.com/tendermint/tendermint/state"
+	sf "github.com/tendermint/tendermint/state/test/factory"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestVerifyReplay(t *testing.T) {
+	config := ResetConfig("verify_replay_test_")
+	t.Cleanup(func() { os.RemoveAll(config.RootDir) })
+	privVal, err := privval.LoadFilePV(config.PrivValidator.KeyFile(), config.PrivValidator.StateFile())
+	require.NoError(t, err)
+	pubKey, err := privVal.GetPubKey(context.Background())
+	require.NoError(t, err)
+	stateDB, state, store := stateAndStore(config, pubKey, 0x0)
+	stateStore := sm.NewStore(stateDB)
+	genDoc, err := sm.MakeGenesisDocFromFile(config.GenesisFile())
+	require.NoError(t, err)
+	state.LastValidators = state.Validators.Copy()
+	store.chain = sf.MakeBlocks(5, &state, privVal)
+	require.NoError(t, stateStore.Save(state))
+	require.NoError(t, stateStore.SaveValidatorSets(1, 5, state.Validators))
+
+	// tampered is a copy of the chain where the app hash recorded by the
+	// header of block 4, i.e. the one resulting from block 3, is wrong.
+	tampered := newMockBlockStore(config, state.ConsensusParams)
+	tampered.chain = append([]*types.Block{}, store.chain...)
+	pbBlock, err := store.chain[3].ToProto()
+	require.NoError(t, err)
+	tampered.chain[3], err = types.BlockFromProto(pbBlock)
+	require.NoError(t, err)
+	tampered.chain[3].AppHash = []byte{0xff}
+
+	testcases := map[string]struct {
+		store      *mockBlockStore
+		appHeight  byte
+		fromHeight int64
+		toHeight   int64
+		divergence int64 // 0 if none
+		expectErr  bool
+	}{
+		"whole chain":               {store, 0, 1, 5, 0, false},
+		"partial range":             {store, 0, 2, 4, 0, false},
+		"restored app":              {store, 2, 3, 5, 0, false},
+		"tampered app hash":         {tampered, 0, 2, 5, 3, true},
+		"range below tampered hash": {tampered, 0, 1, 2, 0, false},
+		"app beyond range":          {store, 3, 2, 5, 0, true},
+		"range beyond store":        {store, 0, 1, 6, 0, true},
+		"empty range":               {store, 0, 3, 2, 0, true},
+	}
+	for desc, tc := range testcases {
+		tc := tc
+		t.Run(desc, func(t *testing.T) {
+			app := &rewoundApp{height: tc.appHeight}
+			proxyApp := proxy.NewAppConns(proxy.NewLocalClientCreator(app))
+			require.NoError(t, proxyApp.Start())
+			t.Cleanup(func() {
+				if err := proxyApp.Stop(); err != nil {
+					t.Error(err)
+				}
+			})
+
+			err := VerifyReplay(log.TestingLogger(), stateStore, tc.store, genDoc, proxyApp, tc.fromHeight, tc.toHeight)
+			if !tc.expectErr {
+				require.NoError(t, err)
+				require.EqualValues(t, tc.toHeight, app.height)
+			} else {
+				require.Error(t, err)
+			}
+
+			if tc.divergence > 0 {
+				var divergence sm.ErrAppHashDivergence
+				require.True(t, errors.As(err, &divergence), "unexpected error %v", err)
+				require.Equal(t, sm.ErrAppHashDivergence{
+					Height: tc.divergence,
+					Core:   []byte{0xff},
+					App:    []byte{byte(tc.divergence)},
+				}, divergence)
+			}
+
+			// the stored state is left untouched
+			stored, err := stateStore.Load()
+			require.NoError(t, err)
+			require.Equal(t, state, stored)
+		})
+	}
+}
@@ -6,8 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"math"
+	mrand "math/rand"
 	"os"
 	"runtime/debug"
+	"sync/atomic"
 	"time"
 
 	"github.com/gogo/protobuf/proto"
@@ -23,6 +26,7 @@ import (
 	"github.com/tendermint/tendermint/libs/log"
 	tmmath "github.com/tendermint/tendermint/libs/math"
 	tmos "github.com/tendermint/tendermint/libs/os"
+	tmrand "github.com/tendermint/tendermint/libs/rand"
 	"github.com/tendermint/tendermint/libs/service"
 	tmtime "github.com/tendermint/tendermint/libs/time"
 	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
@@ -42,6 +46,15 @@ var (
 
 var msgQueueSize = 1000
 
+// maxTimeoutCommit is the largest timeout commit that can be set at runtime.
+const maxTimeoutCommit = time.Minute
+
+// maxBufferedProposalRound is the highest round of the next height for which a
+// proposal is buffered. Finding the proposer of a round takes time linear in
+// the round, and proposals for later rounds are unlikely to arrive before the
+// node reaches the height anyway.
+const maxBufferedProposalRound = 10
+
 // msgs from the reactor which may update the state
 type msgInfo struct {
 	Msg    Message    `json:"msg"`
@@ -143,6 +156,32 @@ type State struct {
 
 	// wait the channel event happening for shutting down the state gracefully
 	onStopCh chan *cstypes.RoundState
+
+	// verified vote signatures, shared by the vote sets of all heights so that
+	// gossiped votes aren't verified more than once (nil if disabled)
+	sigCache *types.SignatureCache
+
+	// proposals for future heights, applied once we reach them
+	proposalBuffer *proposalBuffer
+
+	// recently added votes, whose exact duplicates are dropped
+	voteReplayWindow *voteReplayWindow
+
+	// audits the proposers of committed blocks against their voting power
+	proposerAudit *proposerAudit
+
+	// adds random jitter to the propose, prevote and precommit timeouts
+	timeoutJitter *timeoutJitter
+
+	// timeoutCommit is the timeout commit used for the current height, and
+	// nextTimeoutCommit an override set at runtime which takes effect at the
+	// next height (nil if none is pending)
+	timeoutCommit     time.Duration
+	nextTimeoutCommit *time.Duration
+
+	// whether we're catching up with our peers, in which case we neither
+	// propose nor vote (accessed atomically, see setCatchingUp)
+	catchingUp int32
 }
 
 // StateOption sets an optional parameter on the State.
@@ -174,6 +213,12 @@ func NewState(
 		evsw:             tmevents.NewEventSwitch(),
 		metrics:          NopMetrics(),
 		onStopCh:         make(chan *cstypes.RoundState),
+		sigCache:         types.NewSignatureCache(config.SignatureCacheSize),
+		timeoutCommit:    config.TimeoutCommit,
+		proposalBuffer:   newProposalBuffer(config.ProposalBufferSize),
+		voteReplayWindow: newVoteReplayWindow(config.VoteReplayWindow),
+		proposerAudit:    newProposerAudit(config.ProposerAuditWindow),
+		timeoutJitter:    newTimeoutJitter(config.TimeoutJitter, tmrand.NewRand()),
 	}
 
 	// set function defaults (may be overwritten before calling Start)
@@ -198,6 +243,21 @@ func NewState(
 	return cs
 }
 
+// SetTimeoutCommit overrides the configured timeout commit at runtime. The new
+// value takes effect from the next height on, never within the current one. It
+// returns the height at which it takes effect.
+func (cs *State) SetTimeoutCommit(timeoutCommit time.Duration) (int64, error) {
+	if timeoutCommit < 0 || timeoutCommit > maxTimeoutCommit {
+		return 0, fmt.Errorf("timeout commit must be between 0 and %v, got %v", maxTimeoutCommit, timeoutCommit)
+	}
+
+	cs.mtx.Lock()
+	defer cs.mtx.Unlock()
+
+	cs.nextTimeoutCommit = &timeoutCommit
+	return cs.Height + 1, nil
+}
+
 // SetLogger implements Service.
 func (cs *State) SetLogger(l log.Logger) {
 	cs.BaseService.Logger = l
@@ -215,6 +275,15 @@ func StateMetrics(metrics *Metrics) StateOption {
 	return func(cs *State) { cs.metrics = metrics }
 }
 
+// StateTimeoutJitterSeed seeds the random jitter added to the timeouts, which
+// is otherwise seeded randomly, e.g. so that tests are reproducible.
+func StateTimeoutJitterSeed(seed int64) StateOption {
+	return func(cs *State) {
+		// nolint:gosec // G404: Use of weak random number generator
+		cs.timeoutJitter = newTimeoutJitter(cs.config.TimeoutJitter, mrand.New(mrand.NewSource(seed)))
+	}
+}
+
 // String returns a string.
 func (cs *State) String() string {
 	// better not to access shared variables
@@ -437,6 +506,23 @@ func (cs *State) OnStop() {
 	// WAL is stopped in receiveRoutine.
 }
 
+// OnReset implements service.Service. It allows the state to be started again
+// after it has been stopped to hand off to fast sync.
+func (cs *State) OnReset() error {
+	if err := cs.evsw.Reset(); err != nil {
+		return err
+	}
+	if err := cs.timeoutTicker.Reset(); err != nil {
+		return err
+	}
+
+	// the WAL was stopped on exiting the receiveRoutine, and is reopened on start
+	cs.wal = nilWAL{}
+	cs.onStopCh = make(chan *cstypes.RoundState)
+	cs.done = make(chan struct{})
+	return nil
+}
+
 // Wait waits for the the main routine to return.
 // NOTE: be sure to Stop() the event switch and drain
 // any event channels or this may deadlock
@@ -454,6 +540,7 @@ func (cs *State) OpenWAL(walFile string) (WAL, error) {
 	}
 
 	wal.SetLogger(cs.Logger.With("wal", walFile))
+	wal.SetMaxSize(cs.config.WalMaxSize, cs.config.WalForceCompaction)
 
 	if err := wal.Start(); err != nil {
 		cs.Logger.Error("failed to start WAL", "err", err)
@@ -661,6 +748,13 @@ func (cs *State) updateToState(state sm.State) {
 		height = state.InitialHeight
 	}
 
+	// Apply a timeout commit override only on a height boundary.
+	if cs.nextTimeoutCommit != nil {
+		cs.timeoutCommit = *cs.nextTimeoutCommit
+		cs.nextTimeoutCommit = nil
+		cs.Logger.Info("applied timeout commit override", "height", height, "timeout_commit", cs.timeoutCommit)
+	}
+
 	// RoundState fields
 	cs.updateHeight(height)
 	cs.updateRoundStep(0, cstypes.RoundStepNewHeight)
@@ -671,9 +765,9 @@ func (cs *State) updateToState(state sm.State) {
 		// to be gathered for the first block.
 		// And alternative solution that relies on clocks:
 		// cs.StartTime = state.LastBlockTime.Add(timeoutCommit)
-		cs.StartTime = cs.config.Commit(tmtime.Now())
+		cs.StartTime = tmtime.Now().Add(cs.timeoutCommit)
 	} else {
-		cs.StartTime = cs.config.Commit(cs.CommitTime)
+		cs.StartTime = cs.CommitTime.Add(cs.timeoutCommit)
 	}
 
 	cs.Validators = validators
@@ -686,13 +780,18 @@ func (cs *State) updateToState(state sm.State) {
 	cs.ValidRound = -1
 	cs.ValidBlock = nil
 	cs.ValidBlockParts = nil
-	cs.Votes = cstypes.NewHeightVoteSet(state.ChainID, height, validators)
+	cs.Votes = cstypes.NewHeightVoteSetWithCache(state.ChainID, height, validators, cs.sigCache)
 	cs.CommitRound = -1
 	cs.LastValidators = state.LastValidators
 	cs.TriggeredTimeoutPrecommit = false
 
 	cs.state = state
 
+	// Now that we have the validator set, apply any proposal received for this
+	// height before we got here.
+	cs.proposalBuffer.prune(height)
+	cs.applyBufferedProposal()
+
 	// Finally, broadcast RoundState
 	cs.newStep()
 }
@@ -1012,6 +1111,7 @@ func (cs *State) enterNewRound(height int64, round int32) {
 		cs.Proposal = nil
 		cs.ProposalBlock = nil
 		cs.ProposalBlockParts = nil
+		cs.applyBufferedProposal()
 	}
 
 	cs.Votes.SetRound(tmmath.SafeAddInt32(round, 1)) // also track next round (round+1) to allow round-skipping
@@ -1083,7 +1183,8 @@ func (cs *State) enterPropose(height int64, round int32) {
 	}()
 
 	// If we don't get the proposal and all block parts quick enough, enterPrevote
-	cs.scheduleTimeout(cs.config.Propose(round), height, round, cstypes.RoundStepPropose)
+	timeout := cs.timeoutJitter.apply(cs.config.Propose(round))
+	cs.scheduleTimeout(timeout, height, round, cstypes.RoundStepPropose)
 
 	// Nothing more to do if we're not a validator
 	if cs.privValidator == nil {
@@ -1109,6 +1210,11 @@ func (cs *State) enterPropose(height int64, round int32) {
 	}
 
 	if cs.isProposer(address) {
+		if cs.isCatchingUp() {
+			logger.Debug("propose step; our turn to propose, but catching up with peers", "proposer", address)
+			return
+		}
+
 		logger.Debug(
 			"propose step; our turn to propose",
 			"proposer", address,
@@ -1321,7 +1427,8 @@ func (cs *State) enterPrevoteWait(height int64, round int32) {
 	}()
 
 	// Wait for some more prevotes; enterPrecommit
-	cs.scheduleTimeout(cs.config.Prevote(round), height, round, cstypes.RoundStepPrevoteWait)
+	timeout := cs.timeoutJitter.apply(cs.config.Prevote(round))
+	cs.scheduleTimeout(timeout, height, round, cstypes.RoundStepPrevoteWait)
 }
 
 // Enter: `timeoutPrevote` after any +2/3 prevotes.
@@ -1480,7 +1587,8 @@ func (cs *State) enterPrecommitWait(height int64, round int32) {
 	}()
 
 	// wait for some more precommits; enterNewRound
-	cs.scheduleTimeout(cs.config.Precommit(round), height, round, cstypes.RoundStepPrecommitWait)
+	timeout := cs.timeoutJitter.apply(cs.config.Precommit(round))
+	cs.scheduleTimeout(timeout, height, round, cstypes.RoundStepPrecommitWait)
 }
 
 // Enter: +2/3 precommits for block
@@ -1672,6 +1780,9 @@ func (cs *State) finalizeCommit(height int64) {
 
 	// must be called before we update state
 	cs.RecordMetrics(height, block)
+	if deviations := cs.proposerAudit.record(cs.Validators, block.ProposerAddress); deviations != nil {
+		cs.reportProposerAudit(deviations)
+	}
 
 	// NewHeightStep!
 	cs.updateToState(stateCopy)
@@ -1783,9 +1894,55 @@ func (cs *State) RecordMetrics(height int64, block *types.Block) {
 	cs.metrics.CommittedHeight.Set(float64(block.Height))
 }
 
+// reportProposerAudit reports the deviations of the validators' shares of
+// proposals from their shares of the voting power over the last audit window.
+func (cs *State) reportProposerAudit(deviations map[string]float64) {
+	var (
+		maxAddress   string
+		maxDeviation float64
+	)
+	for address, deviation := range deviations {
+		cs.metrics.ProposerDeviation.With("validator_address", address).Set(deviation)
+		if math.Abs(deviation) >= math.Abs(maxDeviation) {
+			maxAddress, maxDeviation = address, deviation
+		}
+	}
+
+	cs.Logger.Info(
+		"audited proposer selection",
+		"height", cs.Height,
+		"window", cs.config.ProposerAuditWindow,
+		"max_deviation", maxDeviation,
+		"validator", maxAddress,
+	)
+}
+
 //-----------------------------------------------------------------------------
 
 func (cs *State) defaultSetProposal(proposal *types.Proposal) error {
+	// A proposal for the next height can't be set until we reach it, so buffer
+	// it until then. Proposals further ahead are dropped, as we don't know the
+	// validators of their heights yet.
+	if proposal.Height > cs.Height {
+		if proposal.Height != cs.Height+1 || proposal.Round > maxBufferedProposalRound {
+			return nil
+		}
+
+		verified := false
+		if proposer := cs.nextHeightProposer(proposal.Round); proposer != nil {
+			if !cs.isSignedBy(proposal, proposer) {
+				return ErrInvalidProposalSignature
+			}
+			verified = true
+		}
+
+		if cs.proposalBuffer.add(proposal, verified) {
+			cs.Logger.Debug("buffered proposal for next height", "height", cs.Height, "proposal", proposal,
+				"verified", verified)
+		}
+		return nil
+	}
+
 	// Already have one
 	// TODO: possibly catch double proposals
 	if cs.Proposal != nil {
@@ -1824,6 +1981,43 @@ func (cs *State) defaultSetProposal(proposal *types.Proposal) error {
 	return nil
 }
 
+// nextHeightProposer returns the proposer of the given round of the next
+// height, or nil if the validators of the next height aren't known.
+func (cs *State) nextHeightProposer(round int32) *types.Validator {
+	if cs.state.NextValidators.IsNilOrEmpty() {
+		return nil
+	}
+
+	validators := cs.state.NextValidators.Copy()
+	if round > 0 {
+		validators.IncrementProposerPriority(round)
+	}
+	return validators.GetProposer()
+}
+
+// isSignedBy returns true if the proposal is signed by the given proposer.
+func (cs *State) isSignedBy(proposal *types.Proposal, proposer *types.Validator) bool {
+	return proposer.PubKey.VerifySignature(
+		types.ProposalSignBytes(cs.state.ChainID, proposal.ToProto()), proposal.Signature,
+	)
+}
+
+// applyBufferedProposal sets the proposal buffered for the current height and
+// round, if any, once it is verified against the proposer of the round.
+func (cs *State) applyBufferedProposal() {
+	proposer := cs.Validators.GetProposer()
+	proposal := cs.proposalBuffer.take(cs.Height, cs.Round, func(proposal *types.Proposal) bool {
+		return cs.isSignedBy(proposal, proposer)
+	})
+	if proposal == nil {
+		return
+	}
+
+	if err := cs.setProposal(proposal); err != nil {
+		cs.Logger.Info("failed applying buffered proposal", "proposal", proposal, "err", err)
+	}
+}
+
 // NOTE: block is not necessarily valid.
 // Asynchronously triggers either enterPrevote (before we timeout of propose) or tryFinalizeCommit,
 // once we have the full block.
@@ -1926,7 +2120,16 @@ func (cs *State) addProposalBlockPart(msg *BlockPartMessage, peerID p2p.NodeID)
 
 // Attempt to add the vote. if its a duplicate signature, dupeout the validator
 func (cs *State) tryAddVote(vote *types.Vote, peerID p2p.NodeID) (bool, error) {
+	if cs.voteReplayWindow.has(vote) {
+		cs.Logger.Debug("dropping duplicate vote", "vote", vote, "peer", peerID)
+		cs.metrics.DuplicateVotes.Add(1)
+		return false, nil
+	}
+
 	added, err := cs.addVote(vote, peerID)
+	if added {
+		cs.voteReplayWindow.add(vote)
+	}
 	if err != nil {
 		// If the vote height is off, we'll just ignore it,
 		// But if it's a conflicting sig, add it to the cs.evpool.
@@ -2241,6 +2444,12 @@ func (cs *State) signAddVote(msgType tmproto.SignedMsgType, hash []byte, header
 		return nil
 	}
 
+	// Nor if it's catching up with its peers, as it's only voting for heights
+	// they're long past.
+	if cs.isCatchingUp() {
+		return nil
+	}
+
 	// TODO: pass pubKey to signVote
 	vote, err := cs.signVote(msgType, hash, header)
 	if err == nil {
@@ -2253,6 +2462,20 @@ func (cs *State) signAddVote(msgType tmproto.SignedMsgType, hash []byte, header
 	return nil
 }
 
+// setCatchingUp sets whether we're catching up with our peers, see
+// catchUpDetector. While catching up, we neither propose nor vote.
+func (cs *State) setCatchingUp(catchingUp bool) {
+	var v int32
+	if catchingUp {
+		v = 1
+	}
+	atomic.StoreInt32(&cs.catchingUp, v)
+}
+
+func (cs *State) isCatchingUp() bool {
+	return atomic.LoadInt32(&cs.catchingUp) == 1
+}
+
 // updatePrivValidatorPubKey get's the private validator public key and
 // memoizes it. This func returns an error if the private validator is not
 // responding or responds with an error.
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-kit/kit/metrics/generic"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -249,6 +250,94 @@ func TestStateBadProposal(t *testing.T) {
 	signAddVotes(config, cs1, tmproto.PrecommitType, propBlock.Hash(), propBlock.MakePartSet(partSize).Header(), vs2)
 }
 
+func TestStateBufferFutureProposal(t *testing.T) {
+	config := configSetup(t)
+
+	cs1, vss := randState(config, 4)
+	height, round := cs1.Height, cs1.Round
+
+	newRoundCh := subscribe(cs1.eventBus, types.EventQueryNewRound)
+	proposalCh := subscribe(cs1.eventBus, types.EventQueryCompleteProposal)
+
+	startTestRound(cs1, height, round)
+	ensureNewRound(newRoundCh, height, round)
+	ensureNewProposal(proposalCh, height, round)
+	rs := cs1.GetRoundState()
+
+	signProposal := func(vs *validatorStub, height int64) *types.Proposal {
+		blockID := types.BlockID{Hash: tmrand.Bytes(32), PartSetHeader: types.PartSetHeader{Total: 1, Hash: tmrand.Bytes(32)}}
+		proposal := types.NewProposal(height, 0, -1, blockID)
+		p := proposal.ToProto()
+		require.NoError(t, vs.SignProposal(context.Background(), cs1.GetState().ChainID, p))
+		proposal.Signature = p.Signature
+		return proposal
+	}
+
+	// a proposal for the next height that is not signed by its proposer is
+	// dropped, rather than taking the place of the real one
+	require.NoError(t, cs1.SetProposal(signProposal(vss[2], height+1), "peer"))
+
+	// vs2 proposes at the next height, which we haven't reached yet
+	proposal := signProposal(vss[1], height+1)
+	require.NoError(t, cs1.SetProposal(proposal, "peer"))
+
+	signAddVotes(config, cs1, tmproto.PrecommitType, rs.ProposalBlock.Hash(), rs.ProposalBlockParts.Header(), vss[1:]...)
+
+	// the proposal is applied once we reach its height
+	ensureNewRound(newRoundCh, height+1, 0)
+	require.Equal(t, proposal, cs1.GetRoundState().Proposal)
+}
+
+// conflictingVotesRecorder is an evidence pool counting the conflicting votes
+// reported to it.
+type conflictingVotesRecorder struct {
+	reports int
+}
+
+func (r *conflictingVotesRecorder) ReportConflictingVotes(voteA, voteB *types.Vote) {
+	r.reports++
+}
+
+func TestStateDropsDuplicateVotes(t *testing.T) {
+	config := configSetup(t)
+
+	cs1, vss := randState(config, 4)
+	evpool := &conflictingVotesRecorder{}
+	cs1.evpool = evpool
+	duplicateVotes := generic.NewCounter("duplicate_votes")
+	cs1.metrics.DuplicateVotes = duplicateVotes
+
+	newBlockID := func() types.BlockID {
+		return types.BlockID{Hash: tmrand.Bytes(32), PartSetHeader: types.PartSetHeader{Total: 1, Hash: tmrand.Bytes(32)}}
+	}
+	blockID := newBlockID()
+	vote := signVote(vss[1], config, tmproto.PrevoteType, blockID.Hash, blockID.PartSetHeader)
+
+	added, err := cs1.tryAddVote(vote, "peer")
+	require.NoError(t, err)
+	require.True(t, added)
+	require.Zero(t, duplicateVotes.Value())
+
+	// the same vote gossiped again is dropped before being verified
+	added, err = cs1.tryAddVote(vote.Copy(), "peer")
+	require.NoError(t, err)
+	require.False(t, added)
+	require.Equal(t, float64(1), duplicateVotes.Value())
+
+	// a conflicting vote for another block in the same slot is still
+	// processed, and reported every time it is received since it isn't added
+	blockID = newBlockID()
+	conflicting := signVote(vss[1], config, tmproto.PrevoteType, blockID.Hash, blockID.PartSetHeader)
+	for i := 1; i <= 2; i++ {
+		added, err = cs1.tryAddVote(conflicting, "peer")
+		var conflictErr *types.ErrVoteConflictingVotes
+		require.ErrorAs(t, err, &conflictErr)
+		require.False(t, added)
+		require.Equal(t, i, evpool.reports)
+	}
+	require.Equal(t, float64(1), duplicateVotes.Value())
+}
+
 func TestStateOversizedBlock(t *testing.T) {
 	config := configSetup(t)
 
@@ -354,6 +443,41 @@ func TestStateFullRound1(t *testing.T) {
 	validateLastPrecommit(t, cs, vss[0], propBlockHash)
 }
 
+func TestStateSetTimeoutCommit(t *testing.T) {
+	config := configSetup(t)
+
+	cs, _ := randState(config, 1)
+	cs.config.SkipTimeoutCommit = false
+	height, round := cs.Height, cs.Round
+
+	// out of range values are rejected
+	_, err := cs.SetTimeoutCommit(-time.Millisecond)
+	require.Error(t, err)
+	_, err = cs.SetTimeoutCommit(maxTimeoutCommit + time.Millisecond)
+	require.Error(t, err)
+
+	// the override doesn't apply to the current height
+	startTime := cs.GetRoundState().StartTime
+	timeoutCommit := cs.config.TimeoutCommit + 50*time.Millisecond
+	nextHeight, err := cs.SetTimeoutCommit(timeoutCommit)
+	require.NoError(t, err)
+	require.Equal(t, height+1, nextHeight)
+	require.Equal(t, startTime, cs.GetRoundState().StartTime)
+
+	newBlockCh := subscribe(cs.eventBus, types.EventQueryNewBlock)
+	newRoundCh := subscribe(cs.eventBus, types.EventQueryNewRound)
+
+	startTestRound(cs, height, round)
+	ensureNewRound(newRoundCh, height, round)
+	ensureNewBlock(newBlockCh, height)
+
+	// the next height starts timeoutCommit after the commit
+	ensureNewRound(newRoundCh, height+1, 0)
+	rs := cs.GetRoundState()
+	require.Equal(t, timeoutCommit, rs.StartTime.Sub(rs.CommitTime))
+	require.False(t, time.Now().Before(rs.StartTime))
+}
+
 // nil is proposed, so prevote and precommit nil
 func TestStateFullRoundNil(t *testing.T) {
 	config := configSetup(t)
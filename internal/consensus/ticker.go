@@ -17,6 +17,7 @@ var (
 type TimeoutTicker interface {
 	Start() error
 	Stop() error
+	Reset() error
 	Chan() <-chan timeoutInfo       // on which to receive a timeout
 	ScheduleTimeout(ti timeoutInfo) // reset the timer
 
@@ -62,6 +63,12 @@ func (t *timeoutTicker) OnStop() {
 	t.stopTimer()
 }
 
+// OnReset implements service.Service. The timer was stopped along with the
+// timeout routine, so there is nothing to do.
+func (t *timeoutTicker) OnReset() error {
+	return nil
+}
+
 // Chan returns a channel on which timeouts are sent.
 func (t *timeoutTicker) Chan() <-chan timeoutInfo {
 	return t.tockChan
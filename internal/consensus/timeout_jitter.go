@@ -0,0 +1,41 @@
+package consensus
+
+import (
+	mrand "math/rand"
+	"time"
+)
+
+// timeoutJitter adds a random jitter of up to fraction of a timeout to it, so
+// that validators whose clocks are synchronized don't all time out, and gossip
+// their votes, at the same time. The jitter is only ever added, so a timeout
+// is never shorter than configured.
+//
+// It is not thread-safe: the State accesses it under its own mutex.
+type timeoutJitter struct {
+	fraction float64
+	rand     *mrand.Rand
+}
+
+func newTimeoutJitter(fraction float64, rand *mrand.Rand) *timeoutJitter {
+	return &timeoutJitter{
+		fraction: fraction,
+		rand:     rand,
+	}
+}
+
+// maxJitter returns the maximum jitter added to the timeout.
+func (j *timeoutJitter) maxJitter(timeout time.Duration) time.Duration {
+	if j.fraction <= 0 || timeout <= 0 {
+		return 0
+	}
+	return time.Duration(float64(timeout) * j.fraction)
+}
+
+// apply returns the timeout with a random jitter in [0, maxJitter] added.
+func (j *timeoutJitter) apply(timeout time.Duration) time.Duration {
+	max := j.maxJitter(timeout)
+	if max <= 0 {
+		return timeout
+	}
+	return timeout + time.Duration(j.rand.Int63n(int64(max)+1))
+}
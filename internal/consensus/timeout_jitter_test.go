@@ -0,0 +1,46 @@
+package consensus
+
+import (
+	mrand "math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeoutJitter(t *testing.T) {
+	jitter := newTimeoutJitter(0.2, mrand.New(mrand.NewSource(1)))
+
+	jittered := false
+	for _, base := range []time.Duration{time.Millisecond, 10 * time.Millisecond, 3 * time.Second} {
+		max := jitter.maxJitter(base)
+		require.Equal(t, base/5, max)
+		for i := 0; i < 1000; i++ {
+			timeout := jitter.apply(base)
+			require.GreaterOrEqual(t, timeout, base)
+			require.LessOrEqual(t, timeout, base+max)
+			jittered = jittered || timeout != base
+		}
+	}
+	require.True(t, jittered)
+
+	// timeouts of 0 and disabled jitter are left alone
+	require.Zero(t, jitter.apply(0))
+	disabled := newTimeoutJitter(0, mrand.New(mrand.NewSource(1)))
+	require.Equal(t, time.Second, disabled.apply(time.Second))
+}
+
+func TestTimeoutJitter_Seed(t *testing.T) {
+	timeouts := func(seed int64) []time.Duration {
+		jitter := newTimeoutJitter(0.5, mrand.New(mrand.NewSource(seed)))
+		timeouts := make([]time.Duration, 10)
+		for i := range timeouts {
+			timeouts[i] = jitter.apply(time.Second)
+		}
+		return timeouts
+	}
+
+	// the same seed yields the same timeouts, another one different ones
+	require.Equal(t, timeouts(7), timeouts(7))
+	require.NotEqual(t, timeouts(7), timeouts(8))
+}
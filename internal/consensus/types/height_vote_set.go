@@ -39,9 +39,10 @@ We let each peer provide us with up to 2 unexpected "catchup" rounds.
 One for their LastCommit round, and another for the official commit round.
 */
 type HeightVoteSet struct {
-	chainID string
-	height  int64
-	valSet  *types.ValidatorSet
+	chainID  string
+	height   int64
+	valSet   *types.ValidatorSet
+	sigCache *types.SignatureCache
 
 	mtx               sync.Mutex
 	round             int32                  // max tracked round
@@ -50,8 +51,20 @@ type HeightVoteSet struct {
 }
 
 func NewHeightVoteSet(chainID string, height int64, valSet *types.ValidatorSet) *HeightVoteSet {
+	return NewHeightVoteSetWithCache(chainID, height, valSet, nil)
+}
+
+// NewHeightVoteSetWithCache returns a HeightVoteSet whose vote sets share the
+// given signature cache, which may be nil.
+func NewHeightVoteSetWithCache(
+	chainID string,
+	height int64,
+	valSet *types.ValidatorSet,
+	sigCache *types.SignatureCache,
+) *HeightVoteSet {
 	hvs := &HeightVoteSet{
-		chainID: chainID,
+		chainID:  chainID,
+		sigCache: sigCache,
 	}
 	hvs.Reset(height, valSet)
 	return hvs
@@ -104,8 +117,9 @@ func (hvs *HeightVoteSet) addRound(round int32) {
 		panic("addRound() for an existing round")
 	}
 	// log.Debug("addRound(round)", "round", round)
-	prevotes := types.NewVoteSet(hvs.chainID, hvs.height, round, tmproto.PrevoteType, hvs.valSet)
-	precommits := types.NewVoteSet(hvs.chainID, hvs.height, round, tmproto.PrecommitType, hvs.valSet)
+	prevotes := types.NewVoteSetWithCache(hvs.chainID, hvs.height, round, tmproto.PrevoteType, hvs.valSet, hvs.sigCache)
+	precommits := types.NewVoteSetWithCache(
+		hvs.chainID, hvs.height, round, tmproto.PrecommitType, hvs.valSet, hvs.sigCache)
 	hvs.roundVoteSets[round] = RoundVoteSet{
 		Prevotes:   prevotes,
 		Precommits: precommits,
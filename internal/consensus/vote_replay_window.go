@@ -0,0 +1,93 @@
+package consensus
+
+import (
+	"bytes"
+	"container/list"
+
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+// voteFingerprint identifies the slot a validator votes in.
+type voteFingerprint struct {
+	address   string
+	height    int64
+	round     int32
+	votesType tmproto.SignedMsgType
+}
+
+// voteReplayWindow holds the last vote processed in each of the most recent
+// size slots, so that exact duplicates of it, which may be gossiped again
+// long after, are dropped before their signature is verified again. A vote
+// that differs from the one held for its slot in any way, such as a vote for
+// another block, is not a duplicate.
+//
+// It is not thread-safe: the State accesses it under its own mutex.
+type voteReplayWindow struct {
+	size  int
+	votes map[voteFingerprint]*list.Element
+	list  *list.List
+}
+
+type voteReplayEntry struct {
+	fingerprint voteFingerprint
+	vote        *types.Vote
+}
+
+func newVoteReplayWindow(size int) *voteReplayWindow {
+	return &voteReplayWindow{
+		size:  size,
+		votes: make(map[voteFingerprint]*list.Element),
+		list:  list.New(),
+	}
+}
+
+func fingerprintVote(vote *types.Vote) voteFingerprint {
+	return voteFingerprint{
+		address:   string(vote.ValidatorAddress),
+		height:    vote.Height,
+		round:     vote.Round,
+		votesType: vote.Type,
+	}
+}
+
+// has returns true if the vote is an exact duplicate of the one held for its
+// slot.
+func (w *voteReplayWindow) has(vote *types.Vote) bool {
+	e, ok := w.votes[fingerprintVote(vote)]
+	if !ok {
+		return false
+	}
+
+	seen := e.Value.(*voteReplayEntry).vote
+	return seen.ValidatorIndex == vote.ValidatorIndex &&
+		seen.BlockID.Equals(vote.BlockID) &&
+		seen.Timestamp.Equal(vote.Timestamp) &&
+		bytes.Equal(seen.Signature, vote.Signature)
+}
+
+// add records the vote as the last one processed in its slot, evicting the
+// least recently processed slot if the window is full. It must only be called
+// for votes that were verified and added, since a vote ignored for now, e.g.
+// one for a height we haven't reached, must be processed again later.
+func (w *voteReplayWindow) add(vote *types.Vote) {
+	if w.size <= 0 {
+		return
+	}
+
+	fingerprint := fingerprintVote(vote)
+	if e, ok := w.votes[fingerprint]; ok {
+		e.Value.(*voteReplayEntry).vote = vote
+		w.list.MoveToBack(e)
+		return
+	}
+
+	if w.list.Len() >= w.size {
+		if front := w.list.Front(); front != nil {
+			delete(w.votes, front.Value.(*voteReplayEntry).fingerprint)
+			w.list.Remove(front)
+		}
+	}
+
+	w.votes[fingerprint] = w.list.PushBack(&voteReplayEntry{fingerprint: fingerprint, vote: vote})
+}
@@ -0,0 +1,54 @@
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestVoteReplayWindow(t *testing.T) {
+	newVote := func(address string, round int32, hash string) *types.Vote {
+		return &types.Vote{
+			Type:             tmproto.PrevoteType,
+			Height:           1,
+			Round:            round,
+			BlockID:          types.BlockID{Hash: []byte(hash)},
+			Timestamp:        time.Unix(1, 0),
+			ValidatorAddress: []byte(address),
+			Signature:        []byte(address + hash),
+		}
+	}
+
+	window := newVoteReplayWindow(2)
+
+	a, b := newVote("a", 0, "x"), newVote("b", 0, "x")
+	require.False(t, window.has(a))
+	window.add(a)
+	window.add(b)
+
+	// exact duplicates are found, but not other votes in the same slot
+	require.True(t, window.has(newVote("a", 0, "x")))
+	require.False(t, window.has(newVote("a", 0, "y")))
+	resigned := newVote("a", 0, "x")
+	resigned.Timestamp = time.Unix(2, 0)
+	require.False(t, window.has(resigned))
+	require.False(t, window.has(newVote("a", 1, "x")))
+
+	// a later vote replaces the one held for its slot, and the least recently
+	// added slot is evicted once the window is full
+	window.add(newVote("a", 0, "y"))
+	window.add(newVote("c", 0, "x"))
+	require.False(t, window.has(b))
+	require.False(t, window.has(a))
+	require.True(t, window.has(newVote("a", 0, "y")))
+	require.True(t, window.has(newVote("c", 0, "x")))
+
+	// a window of size 0 holds nothing
+	window = newVoteReplayWindow(0)
+	window.add(a)
+	require.False(t, window.has(a))
+}
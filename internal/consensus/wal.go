@@ -12,6 +12,7 @@ import (
 	"github.com/gogo/protobuf/proto"
 
 	auto "github.com/tendermint/tendermint/internal/libs/autofile"
+	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
 	tmjson "github.com/tendermint/tendermint/libs/json"
 	"github.com/tendermint/tendermint/libs/log"
 	tmos "github.com/tendermint/tendermint/libs/os"
@@ -82,6 +83,17 @@ type BaseWAL struct {
 
 	flushTicker   *time.Ticker
 	flushInterval time.Duration
+
+	// maxSize is the total WAL size above which a warning is logged and, if
+	// forceCompaction is set, the WAL is compacted. 0 disables the check.
+	maxSize         int64
+	forceCompaction bool
+
+	mtx tmsync.Mutex
+	// endHeightIndex is the index of the group file holding the last
+	// EndHeightMessage. All files before it can be removed during compaction.
+	endHeightIndex int
+	exceeded       bool
 }
 
 var _ WAL = &BaseWAL{}
@@ -112,6 +124,14 @@ func (wal *BaseWAL) SetFlushInterval(i time.Duration) {
 	wal.flushInterval = i
 }
 
+// SetMaxSize sets the total size above which the WAL logs a warning. If
+// compact is true, the WAL is also compacted down to the last height boundary
+// by removing the files preceding it. It must be called before Start.
+func (wal *BaseWAL) SetMaxSize(maxSize int64, compact bool) {
+	wal.maxSize = maxSize
+	wal.forceCompaction = compact
+}
+
 func (wal *BaseWAL) Group() *auto.Group {
 	return wal.group
 }
@@ -129,6 +149,14 @@ func (wal *BaseWAL) OnStart() error {
 		if err := wal.WriteSync(EndHeightMessage{0}); err != nil {
 			return err
 		}
+	} else if wal.forceCompaction {
+		index, err := wal.searchEndHeightIndex()
+		if err != nil {
+			return err
+		}
+		wal.mtx.Lock()
+		wal.endHeightIndex = index
+		wal.mtx.Unlock()
 	}
 	err = wal.group.Start()
 	if err != nil {
@@ -146,6 +174,7 @@ func (wal *BaseWAL) processFlushTicks() {
 			if err := wal.FlushAndSync(); err != nil {
 				wal.Logger.Error("Periodic WAL flush failed", "err", err)
 			}
+			wal.checkMaxSize()
 		case <-wal.Quit():
 			return
 		}
@@ -186,12 +215,22 @@ func (wal *BaseWAL) Write(msg WALMessage) error {
 		return nil
 	}
 
+	// the head may be rotated while writing, so the index is read beforehand
+	// to err on the side of keeping the file the message ends up in
+	index := wal.group.MaxIndex()
+
 	if err := wal.enc.Encode(&TimedWALMessage{tmtime.Now(), msg}); err != nil {
 		wal.Logger.Error("Error writing msg to consensus wal. WARNING: recover may not be possible for the current height",
 			"err", err, "msg", msg)
 		return err
 	}
 
+	if _, ok := msg.(EndHeightMessage); ok {
+		wal.mtx.Lock()
+		wal.endHeightIndex = index
+		wal.mtx.Unlock()
+	}
+
 	return nil
 }
 
@@ -282,6 +321,92 @@ func (wal *BaseWAL) SearchForEndHeight(
 	return nil, false, nil
 }
 
+// checkMaxSize logs a warning when the WAL grows beyond its maximum size and,
+// if forced compaction is enabled, removes the files preceding the last height
+// boundary. Data of the current height is always kept, thus the WAL can remain
+// above the limit while a single height grows past it.
+func (wal *BaseWAL) checkMaxSize() {
+	if wal.maxSize <= 0 {
+		return
+	}
+
+	size := wal.group.ReadGroupInfo().TotalSize
+
+	wal.mtx.Lock()
+	defer wal.mtx.Unlock()
+
+	if size < wal.maxSize {
+		wal.exceeded = false
+		return
+	}
+	if !wal.exceeded {
+		wal.Logger.Error("WAL exceeds maximum size", "size", size, "max_size", wal.maxSize)
+		wal.exceeded = true
+	}
+
+	if !wal.forceCompaction || wal.endHeightIndex <= wal.group.MinIndex() {
+		return
+	}
+
+	freed, err := wal.group.RemoveFilesBefore(wal.endHeightIndex)
+	if err != nil {
+		wal.Logger.Error("failed to compact WAL", "err", err)
+		return
+	}
+	wal.Logger.Info("compacted WAL", "freed", freed, "size", size-freed, "min_index", wal.group.MinIndex())
+	if size-freed < wal.maxSize {
+		wal.exceeded = false
+	}
+}
+
+// searchEndHeightIndex returns the index of the last group file holding an
+// EndHeightMessage, or the minimum index if there is none.
+func (wal *BaseWAL) searchEndHeightIndex() (int, error) {
+	min, max := wal.group.MinIndex(), wal.group.MaxIndex()
+	for index := max; index > min; index-- {
+		found, err := wal.hasEndHeight(index)
+		if err != nil {
+			return 0, err
+		}
+		if found {
+			return index, nil
+		}
+	}
+	return min, nil
+}
+
+// hasEndHeight returns true if the group file with the given index holds an
+// EndHeightMessage. Corrupted files are considered to hold one, such that
+// compaction never removes the data preceding them.
+func (wal *BaseWAL) hasEndHeight(index int) (bool, error) {
+	gr, err := wal.group.NewReader(index)
+	if err != nil {
+		return false, err
+	}
+	defer gr.Close()
+
+	dec := NewWALDecoder(gr)
+	for {
+		msg, err := dec.Decode()
+		switch {
+		case err == io.EOF:
+			return false, nil
+		case IsDataCorruptionError(err):
+			return true, nil
+		case err != nil:
+			return false, err
+		}
+
+		// the reader moves on to the next file once this one is exhausted
+		if gr.CurIndex() != index {
+			return false, nil
+		}
+		if _, ok := msg.Msg.(EndHeightMessage); ok {
+			return true, nil
+		}
+	}
+}
+
 // A WALEncoder writes custom-encoded WAL messages to an output stream.
 //
 // Format: 4 bytes CRC sum + 4 bytes length + arbitrary-length value
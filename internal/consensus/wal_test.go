@@ -4,8 +4,7 @@ import (
 	"bytes"
 	"crypto/rand"
 	"path/filepath"
-
-	// "sync"
+	"sync"
 	"testing"
 	"time"
 
@@ -209,6 +208,119 @@ func TestWALPeriodicSync(t *testing.T) {
 	}
 }
 
+// errorLogger records the messages logged at error level.
+type errorLogger struct {
+	log.Logger
+
+	mtx    sync.Mutex
+	errors []string
+}
+
+func (l *errorLogger) Error(msg string, keyVals ...interface{}) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.errors = append(l.errors, msg)
+}
+
+func (l *errorLogger) With(keyVals ...interface{}) log.Logger {
+	return l
+}
+
+func (l *errorLogger) Errors() []string {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return l.errors
+}
+
+// writeStuckWAL writes a WAL with three files: the first ending height 0, the
+// second ending height 1, and a head with data of the stuck height 2.
+func writeStuckWAL(t *testing.T, wal *BaseWAL) {
+	t.Helper()
+
+	for height := int64(0); height <= 2; height++ {
+		if height > 0 {
+			wal.Group().RotateFile()
+		}
+		if height < 2 {
+			require.NoError(t, wal.Write(EndHeightMessage{height}))
+		}
+		for i := 0; i < 100; i++ {
+			require.NoError(t, wal.Write(timeoutInfo{Duration: time.Second, Height: height + 1, Round: int32(i)}))
+		}
+	}
+	require.NoError(t, wal.FlushAndSync())
+	require.Equal(t, 2, wal.Group().MaxIndex())
+}
+
+func TestWALMaxSize(t *testing.T) {
+	walFile := filepath.Join(t.TempDir(), "wal")
+	wal, err := NewWAL(walFile)
+	require.NoError(t, err)
+	logger := &errorLogger{Logger: log.NewNopLogger()}
+	wal.SetLogger(logger)
+	wal.SetMaxSize(1, false)
+	writeStuckWAL(t, wal)
+	t.Cleanup(wal.Group().Close)
+
+	// A warning is logged once while the WAL remains above the limit, and
+	// without forced compaction no files are removed.
+	wal.checkMaxSize()
+	wal.checkMaxSize()
+	require.Equal(t, []string{"WAL exceeds maximum size"}, logger.Errors())
+	require.Equal(t, 0, wal.Group().MinIndex())
+
+	// No warning is logged below the limit.
+	wal.SetMaxSize(wal.Group().ReadGroupInfo().TotalSize+1, false)
+	wal.checkMaxSize()
+	require.Len(t, logger.Errors(), 1)
+}
+
+func TestWALMaxSize_ForceCompaction(t *testing.T) {
+	walFile := filepath.Join(t.TempDir(), "wal")
+	wal, err := NewWAL(walFile)
+	require.NoError(t, err)
+	logger := &errorLogger{Logger: log.NewNopLogger()}
+	wal.SetLogger(logger)
+	writeStuckWAL(t, wal)
+	wal.Group().Close()
+
+	// Reopen the WAL with forced compaction, which must locate the last height
+	// boundary on start.
+	wal, err = NewWAL(walFile)
+	require.NoError(t, err)
+	wal.SetLogger(logger)
+	wal.SetMaxSize(1, true)
+	require.NoError(t, wal.Start())
+	t.Cleanup(func() {
+		require.NoError(t, wal.Stop())
+		wal.Wait()
+	})
+
+	// Only the file preceding the last height boundary is removed, and the
+	// data of the current height is kept even though it exceeds the limit.
+	before := wal.Group().ReadGroupInfo().TotalSize
+	wal.checkMaxSize()
+	require.Equal(t, []string{"WAL exceeds maximum size"}, logger.Errors())
+	require.Equal(t, 1, wal.Group().MinIndex())
+	require.Less(t, wal.Group().ReadGroupInfo().TotalSize, before)
+
+	gr, found, err := wal.SearchForEndHeight(1, &WALSearchOptions{})
+	require.NoError(t, err)
+	require.True(t, found)
+	require.NoError(t, gr.Close())
+
+	// Once the stuck height completes, compaction moves up to the new boundary.
+	wal.Group().RotateFile()
+	require.NoError(t, wal.WriteSync(EndHeightMessage{2}))
+	wal.checkMaxSize()
+	require.Equal(t, 3, wal.Group().MinIndex())
+
+	gr, found, err = wal.SearchForEndHeight(2, &WALSearchOptions{})
+	require.NoError(t, err)
+	require.True(t, found)
+	require.NoError(t, gr.Close())
+}
+
 /*
 var initOnce sync.Once
 
@@ -49,11 +49,32 @@ type Pool struct {
 
 	pruningHeight int64
 	pruningTime   time.Time
+
+	// if set, evidence whose timestamp does not match the time of the block
+	// at the evidence height is rejected instead of being rectified
+	strictTimestamps bool
+}
+
+// PoolOption sets an optional parameter on the Pool.
+type PoolOption func(*Pool)
+
+// WithStrictTimestamps configures the pool to reject evidence whose timestamp
+// does not match the time of the committed block at the evidence height. By
+// default, such evidence has its ABCI component regenerated and the rectified
+// evidence is stored instead.
+func WithStrictTimestamps(strict bool) PoolOption {
+	return func(evpool *Pool) { evpool.strictTimestamps = strict }
 }
 
 // NewPool creates an evidence pool. If using an existing evidence store,
 // it will add all pending evidence to the concurrent list.
-func NewPool(logger log.Logger, evidenceDB dbm.DB, stateDB sm.Store, blockStore BlockStore) (*Pool, error) {
+func NewPool(
+	logger log.Logger,
+	evidenceDB dbm.DB,
+	stateDB sm.Store,
+	blockStore BlockStore,
+	options ...PoolOption,
+) (*Pool, error) {
 	state, err := stateDB.Load()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load state: %w", err)
@@ -69,6 +90,10 @@ func NewPool(logger log.Logger, evidenceDB dbm.DB, stateDB sm.Store, blockStore
 		consensusBuffer: make([]duplicateVoteSet, 0),
 	}
 
+	for _, opt := range options {
+		opt(pool)
+	}
+
 	// If pending evidence already in db, in event of prior failure, then check
 	// for expiration, update the size and load it back to the evidenceList.
 	pool.pruningHeight, pool.pruningTime = pool.removeExpiredPendingEvidence()
@@ -43,11 +43,11 @@ const (
 
 	maxMsgSize = 1048576 // 1MB TODO make it configurable
 
-	// broadcast all uncommitted evidence this often. This sets when the reactor
-	// goes back to the start of the list and begins sending the evidence again.
-	// Most evidence should be committed in the very next block that is why we wait
-	// just over the block production rate before sending evidence again.
-	broadcastEvidenceIntervalS = 10
+	// go back to the start of the list of uncommitted evidence this often, to
+	// send again the evidence that is still pending and forget the evidence that
+	// was committed since. Most evidence should be committed in the very next
+	// block that is why we wait just over the block production rate.
+	defaultBroadcastInterval = 10 * time.Second
 )
 
 // Reactor handles evpool evidence broadcasting amongst peers.
@@ -59,12 +59,32 @@ type Reactor struct {
 	peerUpdates *p2p.PeerUpdates
 	closeCh     chan struct{}
 
+	// maxBatchBytes bounds the size of the evidence batched in an envelope.
+	maxBatchBytes int
+
+	// broadcastInterval is how often pending evidence is sent to each peer.
+	broadcastInterval time.Duration
+
 	peerWG sync.WaitGroup
 
 	mtx          tmsync.Mutex
 	peerRoutines map[p2p.NodeID]*tmsync.Closer
 }
 
+// ReactorOption sets an optional parameter on the Reactor.
+type ReactorOption func(*Reactor)
+
+// WithBroadcastInterval sets how often the evidence that is still pending is
+// sent again to each peer, 10 seconds by default. Within an interval, a piece
+// of evidence is sent to a peer once, however many times it was received.
+func WithBroadcastInterval(interval time.Duration) ReactorOption {
+	return func(r *Reactor) {
+		if interval > 0 {
+			r.broadcastInterval = interval
+		}
+	}
+}
+
 // NewReactor returns a reference to a new evidence reactor, which implements the
 // service.Service interface. It accepts a p2p Channel dedicated for handling
 // envelopes with EvidenceList messages.
@@ -73,13 +93,20 @@ func NewReactor(
 	evidenceCh *p2p.Channel,
 	peerUpdates *p2p.PeerUpdates,
 	evpool *Pool,
+	options ...ReactorOption,
 ) *Reactor {
 	r := &Reactor{
-		evpool:       evpool,
-		evidenceCh:   evidenceCh,
-		peerUpdates:  peerUpdates,
-		closeCh:      make(chan struct{}),
-		peerRoutines: make(map[p2p.NodeID]*tmsync.Closer),
+		evpool:            evpool,
+		evidenceCh:        evidenceCh,
+		peerUpdates:       peerUpdates,
+		closeCh:           make(chan struct{}),
+		maxBatchBytes:     int(ChannelShims[EvidenceChannel].Descriptor.MaxSendBytes),
+		broadcastInterval: defaultBroadcastInterval,
+		peerRoutines:      make(map[p2p.NodeID]*tmsync.Closer),
+	}
+
+	for _, option := range options {
+		option(r)
 	}
 
 	r.BaseService = *service.NewBaseService(logger, "Evidence", r)
@@ -130,11 +157,6 @@ func (r *Reactor) handleEvidenceMessage(envelope p2p.Envelope) error {
 
 	switch msg := envelope.Message.(type) {
 	case *tmproto.EvidenceList:
-		// TODO: Refactor the Evidence type to not contain a list since we only ever
-		// send and receive one piece of evidence at a time. Or potentially consider
-		// batching evidence.
-		//
-		// see: https://github.com/tendermint/tendermint/issues/4729
 		for i := 0; i < len(msg.Evidence); i++ {
 			ev, err := types.EvidenceFromProto(&msg.Evidence[i])
 			if err != nil {
@@ -275,19 +297,27 @@ func (r *Reactor) processPeerUpdates() {
 }
 
 // broadcastEvidenceLoop starts a blocking process that continuously reads pieces
-// of evidence off of a linked-list and sends the evidence in a p2p Envelope to
+// of evidence off of a linked-list and sends them in batches of p2p Envelopes to
 // the given peer by ID. This should be invoked in a goroutine per unique peer
 // ID via an appropriate PeerUpdate. The goroutine can be signaled to gracefully
 // exit by either explicitly closing the provided doneCh or by the reactor
 // signaling to stop.
 //
+// Pending evidence is sent to the peer again every broadcast interval, in case
+// the peer could not process it the first time, but only once per interval
+// however many times it was received, such that a flood of duplicate evidence
+// collapses.
+//
 // TODO: This should be refactored so that we do not blindly gossip evidence
-// that the peer has already received or may not be ready for.
+// that the peer may not be ready for.
 //
 // REF: https://github.com/tendermint/tendermint/issues/4727
 func (r *Reactor) broadcastEvidenceLoop(peerID p2p.NodeID, closer *tmsync.Closer) {
 	var next *clist.CElement
 
+	// sent holds the evidence sent to the peer, by evidence hash.
+	sent := make(map[string]sentEvidence)
+
 	defer func() {
 		r.mtx.Lock()
 		delete(r.peerRoutines, peerID)
@@ -321,29 +351,31 @@ func (r *Reactor) broadcastEvidenceLoop(peerID p2p.NodeID, closer *tmsync.Closer
 				// implicitly exit this peer's goroutine.
 				return
 			}
-		}
 
-		ev := next.Value.(types.Evidence)
-		evProto, err := types.EvidenceToProto(ev)
-		if err != nil {
-			panic(fmt.Errorf("failed to convert evidence: %w", err))
+			// forget the sent evidence that was since committed or expired
+			for key, s := range sent {
+				if !r.evpool.isPending(s.ev) {
+					delete(sent, key)
+				}
+			}
 		}
 
+		var batch []tmproto.Evidence
+		batch, next = r.nextEvidenceBatch(next, sent)
+
 		// Send the evidence to the corresponding peer. Note, the peer may be behind
-		// and thus would not be able to process the evidence correctly. Also, the
-		// peer may receive this piece of evidence multiple times if it added and
-		// removed frequently from the broadcasting peer.
-		r.evidenceCh.Out <- p2p.Envelope{
-			To: peerID,
-			Message: &tmproto.EvidenceList{
-				Evidence: []tmproto.Evidence{*evProto},
-			},
+		// and thus would not be able to process the evidence correctly.
+		if len(batch) > 0 {
+			r.evidenceCh.Out <- p2p.Envelope{
+				To:      peerID,
+				Message: &tmproto.EvidenceList{Evidence: batch},
+			}
+			r.Logger.Debug("gossiped evidence to peer", "num_evidence", len(batch), "peer", peerID)
 		}
-		r.Logger.Debug("gossiped evidence to peer", "evidence", ev, "peer", peerID)
 
 		select {
-		case <-time.After(time.Second * broadcastEvidenceIntervalS):
-			// start from the beginning after broadcastEvidenceIntervalS seconds
+		case <-time.After(r.broadcastInterval):
+			// start from the beginning after the broadcast interval
 			next = nil
 
 		case <-next.NextWaitChan():
@@ -361,3 +393,59 @@ func (r *Reactor) broadcastEvidenceLoop(peerID p2p.NodeID, closer *tmsync.Closer
 		}
 	}
 }
+
+// sentEvidence is a piece of evidence sent to a peer, and when it was sent.
+type sentEvidence struct {
+	ev     types.Evidence
+	sentAt time.Time
+}
+
+// nextEvidenceBatch collects the evidence to send to a peer, starting at the
+// given element and following the list for as long as the evidence fits in
+// maxBatchBytes, though a batch always holds at least one piece of evidence.
+// Evidence sent within the broadcast interval, including evidence in the
+// batch, and evidence no longer pending, e.g. because it was committed, is
+// skipped. The sent evidence is recorded in sent. It returns the batch along
+// with the last element consumed, from which the next batch follows.
+func (r *Reactor) nextEvidenceBatch(
+	start *clist.CElement,
+	sent map[string]sentEvidence,
+) ([]tmproto.Evidence, *clist.CElement) {
+	var (
+		batch      []tmproto.Evidence
+		batchBytes int
+		last       = start
+		now        = time.Now()
+	)
+
+	for e := start; e != nil; e = e.Next() {
+		ev := e.Value.(types.Evidence)
+		key := evMapKey(ev)
+
+		if s, ok := sent[key]; ok && now.Sub(s.sentAt) < r.broadcastInterval {
+			last = e
+			continue
+		}
+		if e.Removed() || !r.evpool.isPending(ev) {
+			last = e
+			continue
+		}
+
+		evProto, err := types.EvidenceToProto(ev)
+		if err != nil {
+			panic(fmt.Errorf("failed to convert evidence: %w", err))
+		}
+
+		size := evProto.Size()
+		if len(batch) > 0 && batchBytes+size > r.maxBatchBytes {
+			break
+		}
+
+		batch = append(batch, *evProto)
+		batchBytes += size
+		sent[key] = sentEvidence{ev: ev, sentAt: now}
+		last = e
+	}
+
+	return batch, last
+}
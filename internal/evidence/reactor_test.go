@@ -3,6 +3,7 @@ package evidence_test
 import (
 	"encoding/hex"
 	"math/rand"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -504,6 +505,118 @@ func TestReactorBroadcastEvidence_FullyConnected(t *testing.T) {
 	}
 }
 
+// TestReactorBroadcastEvidence_Deduplicated has several peers send the same
+// evidence, and ensures that it is gossiped once to each peer every broadcast
+// interval, and that committed evidence is no longer gossiped.
+func TestReactorBroadcastEvidence_Deduplicated(t *testing.T) {
+	val := types.NewMockPV()
+	stateStore := initializeValidatorState(t, val, int64(numEvidence)+10)
+	state, err := stateStore.Load()
+	require.NoError(t, err)
+
+	blockStore := &mocks.BlockStore{}
+	blockStore.On("LoadBlockMeta", mock.AnythingOfType("int64")).Return(func(h int64) *types.BlockMeta {
+		if h <= state.LastBlockHeight {
+			return &types.BlockMeta{Header: types.Header{Time: defaultEvidenceTime}}
+		}
+		return nil
+	})
+	pool, err := evidence.NewPool(log.TestingLogger(), dbm.NewMemDB(), stateStore, blockStore)
+	require.NoError(t, err)
+
+	inCh := make(chan p2p.Envelope, 10)
+	outCh := make(chan p2p.Envelope, 100)
+	peerCh := make(chan p2p.PeerUpdate, 10)
+	evidenceCh := p2p.NewChannel(evidence.EvidenceChannel, new(tmproto.EvidenceList),
+		inCh, outCh, make(chan p2p.PeerError, 10))
+	const interval = 500 * time.Millisecond
+	reactor := evidence.NewReactor(log.TestingLogger(), evidenceCh, p2p.NewPeerUpdates(peerCh, 10), pool,
+		evidence.WithBroadcastInterval(interval))
+	require.NoError(t, reactor.Start())
+	t.Cleanup(func() { require.NoError(t, reactor.Stop()) })
+
+	evList := make(types.EvidenceList, numEvidence)
+	evProtos := make([]tmproto.Evidence, 0, 2*numEvidence)
+	for i := range evList {
+		evList[i] = types.NewMockDuplicateVoteEvidenceWithValidator(
+			int64(i+1), defaultEvidenceTime, val, evidenceChainID)
+		evProto, err := types.EvidenceToProto(evList[i])
+		require.NoError(t, err)
+		evProtos = append(evProtos, *evProto, *evProto)
+	}
+
+	// every source sends every piece of evidence twice
+	sources := []p2p.NodeID{
+		p2p.NodeID(strings.Repeat("a", 2*crypto.AddressSize)),
+		p2p.NodeID(strings.Repeat("b", 2*crypto.AddressSize)),
+		p2p.NodeID(strings.Repeat("c", 2*crypto.AddressSize)),
+	}
+	for _, source := range sources {
+		inCh <- p2p.Envelope{From: source, Message: &tmproto.EvidenceList{Evidence: evProtos}}
+	}
+	require.Eventually(t, func() bool { return int(pool.Size()) == numEvidence }, time.Second, 10*time.Millisecond)
+
+	// expectGossip waits for the given peers to be sent the given evidence, and
+	// ensures each piece of evidence is only sent once to each peer, and that
+	// nothing else is sent for a while, well within the broadcast interval.
+	expectGossip := func(peers []p2p.NodeID, evList types.EvidenceList) {
+		t.Helper()
+
+		gossiped := make(map[p2p.NodeID]map[string]int)
+		for _, peer := range peers {
+			gossiped[peer] = make(map[string]int)
+		}
+
+		timeout := time.After(5 * time.Second)
+		for received := 0; received < len(peers)*len(evList); {
+			select {
+			case envelope := <-outCh:
+				require.Contains(t, gossiped, envelope.To)
+				for _, evProto := range envelope.Message.(*tmproto.EvidenceList).Evidence {
+					ev, err := types.EvidenceFromProto(&evProto) // nolint: gosec
+					require.NoError(t, err)
+					gossiped[envelope.To][string(ev.Hash())]++
+					received++
+				}
+			case <-timeout:
+				require.Fail(t, "timed out waiting for evidence to be gossiped")
+			}
+		}
+
+		for peer, evidence := range gossiped {
+			require.Len(t, evidence, len(evList), "peer %v", peer)
+			for _, ev := range evList {
+				require.Equal(t, 1, evidence[string(ev.Hash())], "peer %v", peer)
+			}
+		}
+
+		select {
+		case envelope := <-outCh:
+			require.Fail(t, "unexpected evidence gossiped", "to %v", envelope.To)
+		case <-time.After(interval / 2):
+		}
+	}
+
+	peers := append(sources, p2p.NodeID(strings.Repeat("d", 2*crypto.AddressSize)))
+	for _, peer := range peers {
+		peerCh <- p2p.PeerUpdate{NodeID: peer, Status: p2p.PeerStatusUp}
+	}
+	expectGossip(peers, evList)
+
+	// evidence is gossiped again once the broadcast interval has passed, in
+	// case the peers couldn't process it
+	expectGossip(peers, evList)
+
+	// once committed, evidence is no longer gossiped, either again or to new
+	// peers
+	state.LastBlockHeight++
+	pool.Update(state, evList[:numEvidence/2])
+
+	peers = append(peers, p2p.NodeID(strings.Repeat("e", 2*crypto.AddressSize)))
+	peerCh <- p2p.PeerUpdate{NodeID: peers[len(peers)-1], Status: p2p.PeerStatusUp}
+	expectGossip(peers, evList[numEvidence/2:])
+}
+
 // nolint:lll
 func TestEvidenceListSerialization(t *testing.T) {
 	exampleVote := func(msgType byte) *types.Vote {
@@ -13,6 +13,7 @@ import (
 // verify verifies the evidence fully by checking:
 // - It has not already been committed
 // - it is sufficiently recent (MaxAge)
+// - its timestamp matches the block time at its height (if strict timestamps are enabled)
 // - it is from a key who was a validator at the given height
 // - it is internally consistent with state
 // - it was properly signed by the alleged equivocator and meets the individual evidence verification requirements
@@ -42,6 +43,16 @@ func (evpool *Pool) verify(evidence types.Evidence) error {
 
 	// verify the time of the evidence
 	evTime := blockMeta.Header.Time
+	if evpool.strictTimestamps && !evidence.Time().Equal(evTime) {
+		return types.NewErrInvalidEvidence(
+			evidence,
+			fmt.Errorf(
+				"evidence time (%v) is different to the time of the block at height %d (%v)",
+				evidence.Time(), evidence.Height(), evTime,
+			),
+		)
+	}
+
 	ageDuration := state.LastBlockTime.Sub(evTime)
 
 	// check that the evidence hasn't expired
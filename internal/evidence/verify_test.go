@@ -3,6 +3,7 @@ package evidence_test
 import (
 	"bytes"
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -469,6 +470,75 @@ func TestVerifyDuplicateVoteEvidence(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestVerifyEvidenceTimestamp(t *testing.T) {
+	const (
+		chainID        = "mychain"
+		height   int64 = 10
+		maxBytes int64 = 1024
+	)
+
+	val := types.NewMockPV()
+	valSet := types.NewValidatorSet([]*types.Validator{val.ExtractIntoValidator(1)})
+
+	state := sm.State{
+		ChainID:         chainID,
+		LastBlockTime:   defaultEvidenceTime.Add(1 * time.Minute),
+		LastBlockHeight: 11,
+		ConsensusParams: *types.DefaultConsensusParams(),
+	}
+	stateStore := &smmocks.Store{}
+	stateStore.On("LoadValidators", height).Return(valSet, nil)
+	stateStore.On("Load").Return(state, nil)
+	blockStore := &mocks.BlockStore{}
+	blockStore.On("LoadBlockMeta", height).Return(&types.BlockMeta{Header: types.Header{Time: defaultEvidenceTime}})
+
+	newEvidence := func(evTime time.Time) *types.DuplicateVoteEvidence {
+		ev := types.NewMockDuplicateVoteEvidenceWithValidator(height, evTime, val, chainID)
+		ev.ValidatorPower = 1
+		ev.TotalVotingPower = 1
+		return ev
+	}
+
+	testCases := []struct {
+		name          string
+		strict        bool
+		evTime        time.Time
+		expectErr     bool
+		expectInvalid bool
+		expPending    int
+	}{
+		{"matching timestamp", true, defaultEvidenceTime, false, false, 1},
+		{"forged timestamp", true, defaultEvidenceTime.Add(1 * time.Minute), true, true, 0},
+		{"forged timestamp is rectified when not strict", false, defaultEvidenceTime.Add(1 * time.Minute), true, false, 1},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			pool, err := evidence.NewPool(log.TestingLogger(), dbm.NewMemDB(), stateStore, blockStore,
+				evidence.WithStrictTimestamps(tc.strict))
+			require.NoError(t, err)
+
+			err = pool.AddEvidence(newEvidence(tc.evTime))
+			if tc.expectErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			var invalidErr *types.ErrInvalidEvidence
+			require.Equal(t, tc.expectInvalid, errors.As(err, &invalidErr))
+
+			pending, _ := pool.PendingEvidence(maxBytes)
+			require.Len(t, pending, tc.expPending)
+			for _, ev := range pending {
+				// evidence which made it into the pool always carries the block time
+				require.Equal(t, defaultEvidenceTime, ev.Time())
+			}
+		})
+	}
+}
+
 func makeLunaticEvidence(
 	t *testing.T,
 	height, commonHeight int64,
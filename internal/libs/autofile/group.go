@@ -326,6 +326,34 @@ func (g *Group) RotateFile() {
 	g.maxIndex++
 }
 
+// RemoveFilesBefore removes the files of the group with an index lower than
+// the given one and returns the number of bytes freed. The head is never
+// removed.
+func (g *Group) RemoveFilesBefore(index int) (int64, error) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	if index > g.maxIndex {
+		index = g.maxIndex
+	}
+
+	var freed int64
+	for ; g.minIndex < index; g.minIndex++ {
+		pathToRemove := filePathForIndex(g.Head.Path, g.minIndex, g.maxIndex)
+		fInfo, err := os.Stat(pathToRemove)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return freed, err
+		}
+		if err := os.Remove(pathToRemove); err != nil {
+			return freed, err
+		}
+		freed += fInfo.Size()
+	}
+	return freed, nil
+}
+
 // NewReader returns a new group reader.
 // CONTRACT: Caller must close the returned GroupReader.
 func (g *Group) NewReader(index int) (*GroupReader, error) {
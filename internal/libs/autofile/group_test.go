@@ -289,3 +289,35 @@ func TestMaxIndex(t *testing.T) {
 	// Cleanup
 	destroyTestGroup(t, g)
 }
+
+func TestRemoveFilesBefore(t *testing.T) {
+	g := createTestGroupWithHeadSizeLimit(t, 0)
+
+	for i := 0; i < 3; i++ {
+		err := g.WriteLine("Line")
+		require.NoError(t, err)
+		g.RotateFile()
+	}
+	err := g.WriteLine("Head")
+	require.NoError(t, err)
+	err = g.FlushAndSync()
+	require.NoError(t, err)
+	assertGroupInfo(t, g.ReadGroupInfo(), 0, 3, 20, 5)
+
+	// Remove the first two files.
+	freed, err := g.RemoveFilesBefore(2)
+	require.NoError(t, err)
+	assert.EqualValues(t, 10, freed)
+	assert.Equal(t, 2, g.MinIndex())
+	assertGroupInfo(t, g.ReadGroupInfo(), 2, 3, 10, 5)
+
+	// The head is never removed.
+	freed, err = g.RemoveFilesBefore(10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 5, freed)
+	assert.Equal(t, 3, g.MinIndex())
+	assert.EqualValues(t, 5, g.ReadGroupInfo().TotalSize)
+
+	// Cleanup
+	destroyTestGroup(t, g)
+}
@@ -8,6 +8,10 @@ import (
 var (
 	// ErrTxInCache is returned to the client if we saw tx earlier
 	ErrTxInCache = errors.New("tx already exists in cache")
+
+	// ErrMempoolPaused is the reason transactions are rejected while the
+	// mempool is paused.
+	ErrMempoolPaused = errors.New("mempool is temporarily not accepting new transactions")
 )
 
 // ErrTxTooLarge defines an error when a transaction is too big to be sent in a
@@ -40,6 +44,17 @@ func (e ErrMempoolIsFull) Error() string {
 	)
 }
 
+// ErrGasPriceTooLow defines an error where the gas price of a transaction is
+// below the node's minimum gas price.
+type ErrGasPriceTooLow struct {
+	GasPrice    float64
+	MinGasPrice float64
+}
+
+func (e ErrGasPriceTooLow) Error() string {
+	return fmt.Sprintf("gas price %v is below the minimum gas price %v", e.GasPrice, e.MinGasPrice)
+}
+
 // ErrPreCheck defines an error where a transaction fails a pre-check.
 type ErrPreCheck struct {
 	Reason error
@@ -0,0 +1,133 @@
+package mempool
+
+import (
+	"container/list"
+	"time"
+
+	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
+	"github.com/tendermint/tendermint/internal/p2p"
+)
+
+// GossipCache maintains a thread-safe LRU cache of transactions recently
+// received via gossip, along with the peers each one was received from. Unlike
+// a TxCache, it tracks the provenance of a transaction, such that a transaction
+// received from several peers is checked only once and is not gossiped back to
+// any of them. A transaction is only remembered for the ttl after it was first
+// received, after which it is treated as new again.
+//
+// A nil *GossipCache is valid and never reports a transaction as seen.
+type GossipCache struct {
+	mtx      tmsync.Mutex
+	size     int
+	ttl      time.Duration
+	cacheMap map[[TxKeySize]byte]*list.Element
+	list     *list.List
+
+	now func() time.Time // for testing
+}
+
+type gossipCacheEntry struct {
+	key      [TxKeySize]byte
+	peers    map[p2p.NodeID]struct{}
+	received time.Time
+}
+
+// NewGossipCache returns a GossipCache holding up to size transactions, each
+// for up to ttl after it was first received. A ttl of 0 keeps transactions
+// until they are evicted for newer ones. A nil cache is returned if size is
+// not positive, which disables it.
+func NewGossipCache(size int, ttl time.Duration) *GossipCache {
+	if size <= 0 {
+		return nil
+	}
+
+	return &GossipCache{
+		size:     size,
+		ttl:      ttl,
+		cacheMap: make(map[[TxKeySize]byte]*list.Element, size),
+		list:     list.New(),
+		now:      time.Now,
+	}
+}
+
+// get returns the unexpired entry for the given key, removing it if it has
+// expired. The caller must hold the mutex.
+func (c *GossipCache) get(key [TxKeySize]byte) (*list.Element, bool) {
+	e, ok := c.cacheMap[key]
+	if !ok {
+		return nil, false
+	}
+
+	if c.ttl > 0 && c.now().Sub(e.Value.(*gossipCacheEntry).received) >= c.ttl {
+		delete(c.cacheMap, key)
+		c.list.Remove(e)
+		return nil, false
+	}
+	return e, true
+}
+
+// Push records that the transaction with the given key was received from
+// peerID. It returns true if the transaction was not received from any peer
+// within the ttl.
+func (c *GossipCache) Push(key [TxKeySize]byte, peerID p2p.NodeID) bool {
+	if c == nil {
+		return true
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if e, ok := c.get(key); ok {
+		e.Value.(*gossipCacheEntry).peers[peerID] = struct{}{}
+		c.list.MoveToBack(e)
+		return false
+	}
+
+	if c.list.Len() >= c.size {
+		if front := c.list.Front(); front != nil {
+			delete(c.cacheMap, front.Value.(*gossipCacheEntry).key)
+			c.list.Remove(front)
+		}
+	}
+
+	c.cacheMap[key] = c.list.PushBack(&gossipCacheEntry{
+		key:      key,
+		peers:    map[p2p.NodeID]struct{}{peerID: {}},
+		received: c.now(),
+	})
+	return true
+}
+
+// HasPeer returns true if the transaction with the given key was received from
+// peerID within the ttl.
+func (c *GossipCache) HasPeer(key [TxKeySize]byte, peerID p2p.NodeID) bool {
+	if c == nil {
+		return false
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	e, ok := c.get(key)
+	if !ok {
+		return false
+	}
+	_, ok = e.Value.(*gossipCacheEntry).peers[peerID]
+	return ok
+}
+
+// Remove removes the transaction with the given key from the cache, such that
+// it is checked again the next time it is received.
+func (c *GossipCache) Remove(key [TxKeySize]byte) {
+	if c == nil {
+		return
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if e, ok := c.cacheMap[key]; ok {
+		delete(c.cacheMap, key)
+		c.list.Remove(e)
+	}
+}
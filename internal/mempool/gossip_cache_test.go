@@ -0,0 +1,74 @@
+package mempool
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+)
+
+func TestGossipCache(t *testing.T) {
+	peerA := p2p.NodeID(strings.Repeat("a", 40))
+	peerB := p2p.NodeID(strings.Repeat("b", 40))
+	tx1, tx2, tx3 := TxKey([]byte{1}), TxKey([]byte{2}), TxKey([]byte{3})
+
+	cache := NewGossipCache(2, 0)
+
+	// The first peer to send a tx sees it as new, later ones don't.
+	require.True(t, cache.Push(tx1, peerA))
+	require.False(t, cache.Push(tx1, peerB))
+	require.False(t, cache.Push(tx1, peerA))
+	require.True(t, cache.HasPeer(tx1, peerA))
+	require.True(t, cache.HasPeer(tx1, peerB))
+
+	require.True(t, cache.Push(tx2, peerA))
+	require.False(t, cache.HasPeer(tx2, peerB))
+
+	// tx1 was used more recently than tx2, so tx2 is evicted.
+	require.False(t, cache.Push(tx1, peerA))
+	require.True(t, cache.Push(tx3, peerA))
+	require.False(t, cache.HasPeer(tx2, peerA))
+	require.True(t, cache.HasPeer(tx1, peerA))
+
+	cache.Remove(tx1)
+	require.False(t, cache.HasPeer(tx1, peerA))
+	require.True(t, cache.Push(tx1, peerB))
+
+	// A nil cache, as returned for a size of 0, sees every tx as new.
+	cache = NewGossipCache(0, time.Minute)
+	require.Nil(t, cache)
+	require.True(t, cache.Push(tx1, peerA))
+	require.True(t, cache.Push(tx1, peerA))
+	require.False(t, cache.HasPeer(tx1, peerA))
+	cache.Remove(tx1)
+}
+
+func TestGossipCache_TTL(t *testing.T) {
+	peerA := p2p.NodeID(strings.Repeat("a", 40))
+	peerB := p2p.NodeID(strings.Repeat("b", 40))
+	tx1, tx2 := TxKey([]byte{1}), TxKey([]byte{2})
+
+	now := time.Now()
+	cache := NewGossipCache(10, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	require.True(t, cache.Push(tx1, peerA))
+	now = now.Add(30 * time.Second)
+	require.True(t, cache.Push(tx2, peerA))
+
+	// Receiving a tx again doesn't extend its window.
+	now = now.Add(29 * time.Second)
+	require.False(t, cache.Push(tx1, peerB))
+	require.True(t, cache.HasPeer(tx1, peerB))
+
+	// Once the window has passed, a tx is new again and its peers are
+	// forgotten, while later txs are still within theirs.
+	now = now.Add(time.Second)
+	require.False(t, cache.HasPeer(tx1, peerA))
+	require.True(t, cache.Push(tx1, peerB))
+	require.False(t, cache.HasPeer(tx1, peerA))
+	require.True(t, cache.HasPeer(tx2, peerA))
+}
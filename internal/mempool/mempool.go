@@ -23,6 +23,21 @@ const (
 	MaxActiveIDs = math.MaxUint16
 )
 
+const (
+	// Codespace is the codespace of the CheckTx responses of transactions
+	// rejected by the mempool itself rather than by the application.
+	Codespace = "mempool"
+
+	// CodeTypeGasPriceTooLow is the code of the CheckTx response of a
+	// transaction whose gas price is below the node's minimum gas price.
+	CodeTypeGasPriceTooLow uint32 = 1
+
+	// CodeTypeUnavailable is the code of the CheckTx response of a transaction
+	// rejected because the mempool was paused. The transaction may be
+	// submitted again once the mempool is resumed.
+	CodeTypeUnavailable uint32 = 2
+)
+
 // Mempool defines the mempool interface.
 //
 // Updates to the mempool need to be synchronized with committing a block so
@@ -137,3 +152,84 @@ func PostCheckMaxGas(maxGas int64) PostCheckFunc {
 		return nil
 	}
 }
+
+// GasPrice returns the gas price of a transaction as declared in its CheckTx
+// response, i.e. the fee it pays, which the application reports as its
+// priority, divided by the gas it wants. It is 0 if no gas is wanted.
+func GasPrice(res *abci.ResponseCheckTx) float64 {
+	if res.GasWanted <= 0 {
+		return 0
+	}
+	return float64(res.Priority) / float64(res.GasWanted)
+}
+
+// EnforceMinGasPrice returns ErrGasPriceTooLow if the gas price of a
+// transaction accepted by the application is below minGasPrice. The response
+// is then marked as rejected with Codespace and CodeTypeGasPriceTooLow, so that
+// clients can tell this local policy rejection from one by the application. A
+// minGasPrice of 0 disables the check.
+func EnforceMinGasPrice(minGasPrice float64, res *abci.ResponseCheckTx) error {
+	if minGasPrice <= 0 || res.Code != abci.CodeTypeOK {
+		return nil
+	}
+
+	gasPrice := GasPrice(res)
+	if gasPrice >= minGasPrice {
+		return nil
+	}
+
+	err := ErrGasPriceTooLow{GasPrice: gasPrice, MinGasPrice: minGasPrice}
+	res.Codespace = Codespace
+	res.Code = CodeTypeGasPriceTooLow
+	res.Log = err.Error()
+	return err
+}
+
+// Pauser is implemented by mempools that can be paused, e.g. by the
+// application when it is overloaded, to temporarily reject new transactions
+// without calling CheckTx on the application. Transactions already in the
+// mempool, or whose CheckTx is in flight, are unaffected by a pause, and so
+// are rechecks.
+type Pauser interface {
+	// SetPaused pauses the mempool, or resumes it if paused is false.
+	SetPaused(paused bool)
+	// Paused returns true if the mempool is paused.
+	Paused() bool
+}
+
+// ValidatorSetObserver is implemented by mempools that react to changes of
+// the validator set, e.g. because the application changes the rules
+// transactions must follow when the validator set changes.
+type ValidatorSetObserver interface {
+	// ValidatorSetChanged is called, with the mempool locked, before Update
+	// when the committed block changed the validator set.
+	ValidatorSetChanged()
+}
+
+// TxIterator yields transactions from a mempool one at a time, see
+// IterableMempool.
+type TxIterator interface {
+	// Next returns the next transaction and true, or false once there are no
+	// more transactions within the constraints the iterator was created with.
+	Next() (types.Tx, bool)
+}
+
+// IterableMempool is implemented by mempools that can reap transactions
+// lazily, ordering them only as they are consumed. The block executor reaps
+// proposed transactions with it when the mempool implements it.
+type IterableMempool interface {
+	// ReapIterator returns an iterator over the transactions that
+	// ReapMaxBytesMaxGas(maxBytes, maxGas) would return, in the same order.
+	ReapIterator(maxBytes, maxGas int64) TxIterator
+}
+
+// PausedResponse returns the CheckTx response of a transaction rejected
+// because the mempool is paused, marked with Codespace and
+// CodeTypeUnavailable.
+func PausedResponse() *abci.Response {
+	return abci.ToResponseCheckTx(abci.ResponseCheckTx{
+		Codespace: Codespace,
+		Code:      CodeTypeUnavailable,
+		Log:       ErrMempoolPaused.Error(),
+	})
+}
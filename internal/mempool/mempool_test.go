@@ -0,0 +1,46 @@
+package mempool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+func TestEnforceMinGasPrice(t *testing.T) {
+	testCases := []struct {
+		name        string
+		minGasPrice float64
+		res         abci.ResponseCheckTx
+		gasPrice    float64
+		rejected    bool
+	}{
+		{"disabled", 0, abci.ResponseCheckTx{Priority: 0, GasWanted: 10}, 0, false},
+		{"above", 1.5, abci.ResponseCheckTx{Priority: 20, GasWanted: 10}, 2, false},
+		{"equal", 2, abci.ResponseCheckTx{Priority: 20, GasWanted: 10}, 2, false},
+		{"below", 2.5, abci.ResponseCheckTx{Priority: 20, GasWanted: 10}, 2, true},
+		{"no gas wanted", 1, abci.ResponseCheckTx{Priority: 20}, 0, true},
+		{"rejected by app", 1, abci.ResponseCheckTx{Code: 5, Priority: 20}, 0, false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			res := tc.res
+			require.Equal(t, tc.gasPrice, GasPrice(&res))
+
+			err := EnforceMinGasPrice(tc.minGasPrice, &res)
+			if !tc.rejected {
+				require.NoError(t, err)
+				require.Equal(t, tc.res, res)
+				return
+			}
+
+			require.Equal(t, ErrGasPriceTooLow{GasPrice: tc.gasPrice, MinGasPrice: tc.minGasPrice}, err)
+			require.Equal(t, CodeTypeGasPriceTooLow, res.Code)
+			require.Equal(t, Codespace, res.Codespace)
+			require.Equal(t, err.Error(), res.Log)
+		})
+	}
+}
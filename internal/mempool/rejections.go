@@ -0,0 +1,154 @@
+package mempool
+
+import (
+	"container/list"
+	"time"
+
+	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
+)
+
+// RejectionReason describes why a transaction was rejected from, or later
+// removed from, the mempool.
+type RejectionReason string
+
+const (
+	// RejectedTooLarge is a transaction larger than the maximum tx size.
+	RejectedTooLarge RejectionReason = "too_large"
+	// RejectedPreCheck is a transaction rejected by the pre-check filter.
+	RejectedPreCheck RejectionReason = "pre_check"
+	// RejectedCheckTx is a transaction for which CheckTx returned a non-zero code.
+	RejectedCheckTx RejectionReason = "check_tx"
+	// RejectedPostCheck is a transaction rejected by the post-check filter.
+	RejectedPostCheck RejectionReason = "post_check"
+	// RejectedGasPrice is a transaction whose gas price is below the minimum
+	// gas price.
+	RejectedGasPrice RejectionReason = "gas_price"
+	// RejectedSenderExists is a transaction whose sender already has a
+	// transaction in the mempool.
+	RejectedSenderExists RejectionReason = "sender_exists"
+	// RejectedMempoolFull is a transaction that did not fit in the mempool.
+	RejectedMempoolFull RejectionReason = "mempool_full"
+	// RejectedPaused is a transaction submitted while the mempool was paused.
+	RejectedPaused RejectionReason = "paused"
+	// RejectedEvicted is a transaction evicted from the mempool to make room
+	// for a transaction with a higher priority.
+	RejectedEvicted RejectionReason = "evicted"
+	// RejectedRecheck is a transaction removed from the mempool because it
+	// failed CheckTx again after a block was committed.
+	RejectedRecheck RejectionReason = "recheck"
+	// RejectedValidatorSetChange is a transaction removed from the mempool
+	// because it failed the recheck forced by a change of the validator set.
+	RejectedValidatorSetChange RejectionReason = "validator_set_change"
+)
+
+// TxRejection records why and when a transaction was rejected.
+type TxRejection struct {
+	Reason RejectionReason
+	// Code is the CheckTx response code, if any.
+	Code uint32
+	// Log is the CheckTx response log or the error the transaction was
+	// rejected with, if any.
+	Log string
+	// Height is the mempool height at which the transaction was rejected.
+	Height int64
+	Time   time.Time
+}
+
+// RejectionCache maintains a thread-safe LRU cache of the reasons recent
+// transactions were rejected, keyed by transaction key, for debugging
+// rejections that happen after a transaction was submitted, such as evictions
+// or failed rechecks.
+//
+// A nil *RejectionCache is valid and retains nothing.
+type RejectionCache struct {
+	mtx      tmsync.Mutex
+	size     int
+	cacheMap map[[TxKeySize]byte]*list.Element
+	list     *list.List
+}
+
+type rejectionCacheEntry struct {
+	key       [TxKeySize]byte
+	rejection TxRejection
+}
+
+// NewRejectionCache returns a RejectionCache holding up to size rejections. A
+// nil cache is returned if size is not positive, which disables it.
+func NewRejectionCache(size int) *RejectionCache {
+	if size <= 0 {
+		return nil
+	}
+
+	return &RejectionCache{
+		size:     size,
+		cacheMap: make(map[[TxKeySize]byte]*list.Element, size),
+		list:     list.New(),
+	}
+}
+
+// Add records the rejection of the transaction with the given key, replacing
+// any previous rejection of it.
+func (c *RejectionCache) Add(key [TxKeySize]byte, rejection TxRejection) {
+	if c == nil {
+		return
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if e, ok := c.cacheMap[key]; ok {
+		e.Value.(*rejectionCacheEntry).rejection = rejection
+		c.list.MoveToBack(e)
+		return
+	}
+
+	if c.list.Len() >= c.size {
+		if front := c.list.Front(); front != nil {
+			delete(c.cacheMap, front.Value.(*rejectionCacheEntry).key)
+			c.list.Remove(front)
+		}
+	}
+
+	c.cacheMap[key] = c.list.PushBack(&rejectionCacheEntry{key: key, rejection: rejection})
+}
+
+// Get returns the last rejection of the transaction with the given key, if it
+// is retained.
+func (c *RejectionCache) Get(key [TxKeySize]byte) (TxRejection, bool) {
+	if c == nil {
+		return TxRejection{}, false
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	e, ok := c.cacheMap[key]
+	if !ok {
+		return TxRejection{}, false
+	}
+	return e.Value.(*rejectionCacheEntry).rejection, true
+}
+
+// Remove removes the rejection of the transaction with the given key, e.g.
+// once the transaction has been accepted into the mempool after all.
+func (c *RejectionCache) Remove(key [TxKeySize]byte) {
+	if c == nil {
+		return
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if e, ok := c.cacheMap[key]; ok {
+		delete(c.cacheMap, key)
+		c.list.Remove(e)
+	}
+}
+
+// RejectionReporter is implemented by mempools that retain the reasons
+// transactions were rejected.
+type RejectionReporter interface {
+	// TxRejection returns the last rejection of the transaction with the
+	// given key, if it is retained.
+	TxRejection(key [TxKeySize]byte) (TxRejection, bool)
+}
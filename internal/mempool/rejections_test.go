@@ -0,0 +1,43 @@
+package mempool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRejectionCache(t *testing.T) {
+	tx1, tx2, tx3 := TxKey([]byte{1}), TxKey([]byte{2}), TxKey([]byte{3})
+
+	cache := NewRejectionCache(2)
+
+	cache.Add(tx1, TxRejection{Reason: RejectedCheckTx, Code: 1})
+	cache.Add(tx2, TxRejection{Reason: RejectedMempoolFull})
+
+	rejection, ok := cache.Get(tx1)
+	require.True(t, ok)
+	require.Equal(t, TxRejection{Reason: RejectedCheckTx, Code: 1}, rejection)
+
+	// A later rejection replaces the previous one, and tx1 was rejected more
+	// recently than tx2, so tx2 is evicted.
+	cache.Add(tx1, TxRejection{Reason: RejectedRecheck, Code: 2})
+	cache.Add(tx3, TxRejection{Reason: RejectedEvicted})
+
+	_, ok = cache.Get(tx2)
+	require.False(t, ok)
+	rejection, ok = cache.Get(tx1)
+	require.True(t, ok)
+	require.Equal(t, TxRejection{Reason: RejectedRecheck, Code: 2}, rejection)
+
+	cache.Remove(tx1)
+	_, ok = cache.Get(tx1)
+	require.False(t, ok)
+
+	// A nil cache, as returned for a size of 0, retains nothing.
+	cache = NewRejectionCache(0)
+	require.Nil(t, cache)
+	cache.Add(tx1, TxRejection{Reason: RejectedCheckTx})
+	_, ok = cache.Get(tx1)
+	require.False(t, ok)
+	cache.Remove(tx1)
+}
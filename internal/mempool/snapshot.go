@@ -0,0 +1,73 @@
+package mempool
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/tendermint/tendermint/internal/libs/tempfile"
+	"github.com/tendermint/tendermint/libs/log"
+	protomem "github.com/tendermint/tendermint/proto/tendermint/mempool"
+	"github.com/tendermint/tendermint/types"
+)
+
+// SaveSnapshot writes all transactions currently in the mempool to the file at
+// path, so that they can be restored with LoadSnapshot after a restart. The
+// file is written atomically.
+func SaveSnapshot(path string, mp Mempool) error {
+	txs := mp.ReapMaxTxs(-1)
+
+	msg := protomem.Txs{Txs: make([][]byte, len(txs))}
+	for i, tx := range txs {
+		msg.Txs[i] = tx
+	}
+
+	bz, err := msg.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to marshal mempool snapshot: %w", err)
+	}
+
+	if err := tempfile.WriteFileAtomic(path, bz, 0600); err != nil {
+		return fmt.Errorf("failed to write mempool snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// LoadSnapshot reads the transactions previously written by SaveSnapshot and
+// re-runs CheckTx on each one of them, so that transactions which are no
+// longer valid are dropped. A missing file is not an error. The file is
+// removed once it has been loaded, so a snapshot is only ever restored once.
+//
+// NOTE: Lock/Unlock must NOT be held by the caller.
+func LoadSnapshot(ctx context.Context, logger log.Logger, path string, mp Mempool) error {
+	bz, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read mempool snapshot: %w", err)
+	}
+
+	var msg protomem.Txs
+	if err := msg.Unmarshal(bz); err != nil {
+		return fmt.Errorf("failed to unmarshal mempool snapshot: %w", err)
+	}
+
+	for _, tx := range msg.Txs {
+		if err := mp.CheckTx(ctx, types.Tx(tx), nil, TxInfo{SenderID: UnknownPeerID}); err != nil {
+			logger.Debug("dropping transaction from mempool snapshot", "tx", types.Tx(tx).Hash(), "err", err)
+		}
+	}
+
+	mp.Lock()
+	err = mp.FlushAppConn()
+	mp.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to flush app connection: %w", err)
+	}
+
+	logger.Info("restored mempool snapshot", "num_txs", mp.Size(), "snapshot_txs", len(msg.Txs))
+
+	return os.Remove(path)
+}
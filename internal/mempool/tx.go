@@ -20,6 +20,19 @@ func TxHashFromBytes(tx []byte) []byte {
 	return types.Tx(tx).Hash()
 }
 
+// UnknownSender is the sender under which transactions are indexed whose
+// sender the application didn't attribute in its CheckTx response.
+const UnknownSender = "unknown"
+
+// SenderIndex is implemented by mempools that index pending transactions by
+// the sender the application attributes them to.
+type SenderIndex interface {
+	// TxsBySender returns the pending transactions grouped by sender, in the
+	// order they were received. Transactions without a sender are grouped
+	// under UnknownSender.
+	TxsBySender() map[string]types.Txs
+}
+
 // TxInfo are parameters that get passed when attempting to add a tx to the
 // mempool.
 type TxInfo struct {
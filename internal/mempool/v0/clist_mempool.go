@@ -3,9 +3,11 @@ package v0
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	abci "github.com/tendermint/tendermint/abci/types"
 	cfg "github.com/tendermint/tendermint/config"
@@ -28,6 +30,7 @@ type CListMempool struct {
 	// Atomic integers
 	height   int64 // the last block Update()'d to
 	txsBytes int64 // total size of mempool, in bytes
+	paused   int32 // 1 while new txs are rejected, see SetPaused
 
 	// notify listeners (ie. consensus) when txs are available
 	notifiedTxsAvailable bool
@@ -49,6 +52,12 @@ type CListMempool struct {
 	// serial (ie. by abci responses which are called in serial).
 	recheckCursor *clist.CElement // next expected response
 	recheckEnd    *clist.CElement // re-checking stops here
+	// recheckReason is the reason txs failing the current recheck are
+	// rejected with.
+	recheckReason mempool.RejectionReason
+
+	// Set by ValidatorSetChanged to force a recheck in the next Update.
+	validatorSetChanged bool
 
 	// Map for quick access to txs to record sender in CheckTx.
 	// txsMap: txKey -> CElement
@@ -58,11 +67,17 @@ type CListMempool struct {
 	// This reduces the pressure on the proxyApp.
 	cache mempool.TxCache
 
+	// Keep the reasons recent txs were rejected, if enabled.
+	rejections *mempool.RejectionCache
+
 	logger  log.Logger
 	metrics *mempool.Metrics
 }
 
 var _ mempool.Mempool = &CListMempool{}
+var _ mempool.RejectionReporter = &CListMempool{}
+var _ mempool.Pauser = &CListMempool{}
+var _ mempool.ValidatorSetObserver = &CListMempool{}
 
 // CListMempoolOption sets an optional parameter on the mempool.
 type CListMempoolOption func(*CListMempool)
@@ -85,6 +100,7 @@ func NewCListMempool(
 		recheckEnd:    nil,
 		logger:        log.NewNopLogger(),
 		metrics:       mempool.NopMetrics(),
+		rejections:    mempool.NewRejectionCache(config.RejectionCacheSize),
 	}
 
 	if config.CacheSize > 0 {
@@ -210,21 +226,33 @@ func (mem *CListMempool) CheckTx(
 	// use defer to unlock mutex because application (*local client*) might panic
 	defer mem.updateMtx.RUnlock()
 
+	if mem.Paused() {
+		mem.reject(tx, mempool.RejectedPaused, mempool.CodeTypeUnavailable, mempool.ErrMempoolPaused.Error())
+		if cb != nil {
+			cb(mempool.PausedResponse())
+		}
+		return nil
+	}
+
 	txSize := len(tx)
 
 	if err := mem.isFull(txSize); err != nil {
+		mem.reject(tx, mempool.RejectedMempoolFull, 0, err.Error())
 		return err
 	}
 
 	if txSize > mem.config.MaxTxBytes {
-		return mempool.ErrTxTooLarge{
+		err := mempool.ErrTxTooLarge{
 			Max:    mem.config.MaxTxBytes,
 			Actual: txSize,
 		}
+		mem.reject(tx, mempool.RejectedTooLarge, 0, err.Error())
+		return err
 	}
 
 	if mem.preCheck != nil {
 		if err := mem.preCheck(tx); err != nil {
+			mem.reject(tx, mempool.RejectedPreCheck, 0, err.Error())
 			return mempool.ErrPreCheck{
 				Reason: err,
 			}
@@ -324,6 +352,16 @@ func (mem *CListMempool) reqResCb(
 	}
 }
 
+// addSender records that the tx with the given key was also received from the
+// given sender, such that it isn't gossiped back to it. It is a no-op if the tx
+// isn't in the mempool.
+func (mem *CListMempool) addSender(txKey [mempool.TxKeySize]byte, senderID uint16) {
+	if e, ok := mem.txsMap.Load(txKey); ok {
+		memTx := e.(*clist.CElement).Value.(*mempoolTx)
+		memTx.senders.LoadOrStore(senderID, true)
+	}
+}
+
 // Called from:
 //  - resCbFirstTime (lock not held) if tx is valid
 func (mem *CListMempool) addTx(memTx *mempoolTx) {
@@ -331,6 +369,9 @@ func (mem *CListMempool) addTx(memTx *mempoolTx) {
 	mem.txsMap.Store(mempool.TxKey(memTx.tx), e)
 	atomic.AddInt64(&mem.txsBytes, int64(len(memTx.tx)))
 	mem.metrics.TxSizeBytes.Observe(float64(len(memTx.tx)))
+
+	// the tx may have been rejected before, e.g. when the mempool was full
+	mem.rejections.Remove(mempool.TxKey(memTx.tx))
 }
 
 // Called from:
@@ -357,6 +398,55 @@ func (mem *CListMempool) RemoveTxByKey(txKey [mempool.TxKeySize]byte, removeFrom
 	}
 }
 
+// reject records the rejection of a tx, if rejections are kept.
+func (mem *CListMempool) reject(tx types.Tx, reason mempool.RejectionReason, code uint32, log string) {
+	mem.rejections.Add(mempool.TxKey(tx), mempool.TxRejection{
+		Reason: reason,
+		Code:   code,
+		Log:    log,
+		Height: mem.height,
+		Time:   time.Now().UTC(),
+	})
+}
+
+// rejectBadTx records the rejection of a tx that failed CheckTx, the post-check
+// filter or the minimum gas price. Failed rechecks are recorded with the reason
+// of the recheck, whatever failed.
+func (mem *CListMempool) rejectBadTx(tx types.Tx, res *abci.ResponseCheckTx, postCheckErr error, recheck bool) {
+	reason, log := mempool.RejectedCheckTx, res.Log
+	switch {
+	case errors.As(postCheckErr, &mempool.ErrGasPriceTooLow{}):
+		reason = mempool.RejectedGasPrice
+	case res.Code == abci.CodeTypeOK && postCheckErr != nil:
+		reason, log = mempool.RejectedPostCheck, postCheckErr.Error()
+	}
+	if recheck {
+		reason = mem.recheckReason
+	}
+	mem.reject(tx, reason, res.Code, log)
+}
+
+// SetPaused pauses or resumes the mempool. While paused, CheckTx rejects new
+// txs with mempool.CodeTypeUnavailable. It implements mempool.Pauser.
+func (mem *CListMempool) SetPaused(paused bool) {
+	var v int32
+	if paused {
+		v = 1
+	}
+	atomic.StoreInt32(&mem.paused, v)
+}
+
+// Paused implements mempool.Pauser.
+func (mem *CListMempool) Paused() bool {
+	return atomic.LoadInt32(&mem.paused) == 1
+}
+
+// TxRejection returns the last rejection of the tx with the given key, if
+// rejections are kept. It implements mempool.RejectionReporter.
+func (mem *CListMempool) TxRejection(key [mempool.TxKeySize]byte) (mempool.TxRejection, bool) {
+	return mem.rejections.Get(key)
+}
+
 func (mem *CListMempool) isFull(txSize int) error {
 	var (
 		memSize  = mem.Size()
@@ -391,6 +481,9 @@ func (mem *CListMempool) resCbFirstTime(
 		if mem.postCheck != nil {
 			postCheckErr = mem.postCheck(tx, r.CheckTx)
 		}
+		if postCheckErr == nil {
+			postCheckErr = mempool.EnforceMinGasPrice(mem.config.MinGasPrice, r.CheckTx)
+		}
 		if (r.CheckTx.Code == abci.CodeTypeOK) && postCheckErr == nil {
 			// Check mempool isn't full again to reduce the chance of exceeding the
 			// limits.
@@ -398,6 +491,7 @@ func (mem *CListMempool) resCbFirstTime(
 				// remove from cache (mempool might have a space later)
 				mem.cache.Remove(tx)
 				mem.logger.Error(err.Error())
+				mem.reject(tx, mempool.RejectedMempoolFull, 0, err.Error())
 				return
 			}
 
@@ -426,6 +520,7 @@ func (mem *CListMempool) resCbFirstTime(
 				"err", postCheckErr,
 			)
 			mem.metrics.FailedTxs.Add(1)
+			mem.rejectBadTx(tx, r.CheckTx, postCheckErr, false)
 
 			if !mem.config.KeepInvalidTxsInCache {
 				// remove from cache (it might be good later)
@@ -457,6 +552,9 @@ func (mem *CListMempool) resCbRecheck(req *abci.Request, res *abci.Response) {
 		if mem.postCheck != nil {
 			postCheckErr = mem.postCheck(tx, r.CheckTx)
 		}
+		if postCheckErr == nil {
+			postCheckErr = mempool.EnforceMinGasPrice(mem.config.MinGasPrice, r.CheckTx)
+		}
 		if (r.CheckTx.Code == abci.CodeTypeOK) && postCheckErr == nil {
 			// Good, nothing to do.
 		} else {
@@ -464,6 +562,7 @@ func (mem *CListMempool) resCbRecheck(req *abci.Request, res *abci.Response) {
 			mem.logger.Debug("tx is no longer valid", "tx", mempool.TxHashFromBytes(tx), "res", r, "err", postCheckErr)
 			// NOTE: we remove tx from the cache because it might be good later
 			mem.removeTx(tx, mem.recheckCursor, !mem.config.KeepInvalidTxsInCache)
+			mem.rejectBadTx(tx, r.CheckTx, postCheckErr, true)
 		}
 		if mem.recheckCursor == mem.recheckEnd {
 			mem.recheckCursor = nil
@@ -604,11 +703,21 @@ func (mem *CListMempool) Update(
 		}
 	}
 
+	// A change of the validator set forces a recheck, whose failures are
+	// recorded as such.
+	recheck := mem.config.Recheck
+	mem.recheckReason = mempool.RejectedRecheck
+	if mem.validatorSetChanged {
+		recheck = true
+		mem.recheckReason = mempool.RejectedValidatorSetChange
+		mem.validatorSetChanged = false
+	}
+
 	// Either recheck non-committed txs to see if they became invalid
 	// or just notify there're some txs left.
 	if mem.Size() > 0 {
-		if mem.config.Recheck {
-			mem.logger.Debug("recheck txs", "numtxs", mem.Size(), "height", height)
+		if recheck {
+			mem.logger.Debug("recheck txs", "numtxs", mem.Size(), "height", height, "reason", mem.recheckReason)
 			mem.recheckTxs()
 			// At this point, mem.txs are being rechecked.
 			// mem.recheckCursor re-scans mem.txs and possibly removes some txs.
@@ -624,6 +733,17 @@ func (mem *CListMempool) Update(
 	return nil
 }
 
+// ValidatorSetChanged forces the next Update to recheck all txs if the
+// mempool is configured to recheck on a validator set change. It implements
+// mempool.ValidatorSetObserver.
+//
+// Lock() must be held by the caller during execution.
+func (mem *CListMempool) ValidatorSetChanged() {
+	if mem.config.RecheckOnValidatorSetChange {
+		mem.validatorSetChanged = true
+	}
+}
+
 func (mem *CListMempool) recheckTxs() {
 	if mem.Size() == 0 {
 		panic("recheckTxs is called, but the mempool is empty")
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	mrand "math/rand"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/tendermint/tendermint/abci/example/code"
 	"github.com/tendermint/tendermint/abci/example/counter"
 	"github.com/tendermint/tendermint/abci/example/kvstore"
 	abciserver "github.com/tendermint/tendermint/abci/server"
@@ -263,6 +265,215 @@ func TestMempool_KeepInvalidTxsInCache(t *testing.T) {
 	}
 }
 
+func TestMempool_Snapshot(t *testing.T) {
+	cc := proxy.NewLocalClientCreator(counter.NewApplication(true))
+	mp, cleanup := newMempoolWithApp(cc)
+	defer cleanup()
+
+	txs := make(types.Txs, 10)
+	for i := range txs {
+		txs[i] = make(types.Tx, 8)
+		binary.BigEndian.PutUint64(txs[i], uint64(i))
+		require.NoError(t, mp.CheckTx(context.Background(), txs[i], nil, mempool.TxInfo{}))
+	}
+	require.Equal(t, len(txs), mp.Size())
+
+	path := filepath.Join(t.TempDir(), "mempool.snapshot")
+	require.NoError(t, mempool.SaveSnapshot(path, mp))
+
+	// simulate a restart where the first three transactions have been
+	// committed, so they no longer have a valid nonce
+	app := counter.NewApplication(true)
+	for _, tx := range txs[:3] {
+		require.True(t, app.DeliverTx(abci.RequestDeliverTx{Tx: tx}).IsOK())
+	}
+	restored, cleanupRestored := newMempoolWithApp(proxy.NewLocalClientCreator(app))
+	defer cleanupRestored()
+	require.Zero(t, restored.Size())
+
+	require.NoError(t, mempool.LoadSnapshot(context.Background(), log.TestingLogger(), path, restored))
+	require.Equal(t, txs[3:], restored.ReapMaxTxs(-1))
+
+	// the snapshot is consumed once restored
+	_, err := os.Stat(path)
+	require.True(t, os.IsNotExist(err))
+	require.NoError(t, mempool.LoadSnapshot(context.Background(), log.TestingLogger(), path, restored))
+}
+
+func TestMempool_TxRejection(t *testing.T) {
+	app := counter.NewApplication(true)
+	cc := proxy.NewLocalClientCreator(app)
+	wcfg := cfg.DefaultConfig()
+	wcfg.Mempool.RejectionCacheSize = 10
+	wcfg.Mempool.Size = 1
+	wcfg.Mempool.MaxTxBytes = 10
+	mp, cleanup := newMempoolWithAppAndConfig(cc, wcfg)
+	defer cleanup()
+
+	requireRejection := func(tx types.Tx, reason mempool.RejectionReason, code uint32, height int64) {
+		t.Helper()
+
+		rejection, ok := mp.TxRejection(mempool.TxKey(tx))
+		require.True(t, ok, "tx %X should have been rejected", tx)
+		require.Equal(t, reason, rejection.Reason)
+		require.Equal(t, code, rejection.Code)
+		require.Equal(t, height, rejection.Height)
+	}
+
+	tooLarge := make(types.Tx, 11)
+	require.Error(t, mp.CheckTx(context.Background(), tooLarge, nil, mempool.TxInfo{}))
+	requireRejection(tooLarge, mempool.RejectedTooLarge, 0, 0)
+
+	// the counter app rejects txs larger than 8 bytes
+	badTx := make(types.Tx, 9)
+	require.NoError(t, mp.CheckTx(context.Background(), badTx, nil, mempool.TxInfo{}))
+	requireRejection(badTx, mempool.RejectedCheckTx, code.CodeTypeEncodingError, 0)
+
+	a := make(types.Tx, 8)
+	binary.BigEndian.PutUint64(a, 0)
+	b := make(types.Tx, 8)
+	binary.BigEndian.PutUint64(b, 1)
+
+	require.NoError(t, mp.CheckTx(context.Background(), a, nil, mempool.TxInfo{}))
+	require.Error(t, mp.CheckTx(context.Background(), b, nil, mempool.TxInfo{}))
+	requireRejection(b, mempool.RejectedMempoolFull, 0, 0)
+
+	// a no longer has a valid nonce once it was delivered, so it fails recheck
+	_ = app.DeliverTx(abci.RequestDeliverTx{Tx: a})
+	mp.Lock()
+	require.NoError(t, mp.Update(1, nil, nil, nil, nil))
+	mp.Unlock()
+	require.Zero(t, mp.Size())
+	requireRejection(a, mempool.RejectedRecheck, code.CodeTypeBadNonce, 1)
+
+	// b is accepted now that there is room for it
+	require.NoError(t, mp.CheckTx(context.Background(), b, nil, mempool.TxInfo{}))
+	_, ok := mp.TxRejection(mempool.TxKey(b))
+	require.False(t, ok)
+}
+
+func TestMempool_RecheckOnValidatorSetChange(t *testing.T) {
+	app := counter.NewApplication(true)
+	cc := proxy.NewLocalClientCreator(app)
+	wcfg := cfg.DefaultConfig()
+	wcfg.Mempool.Recheck = false
+	wcfg.Mempool.RecheckOnValidatorSetChange = true
+	wcfg.Mempool.RejectionCacheSize = 10
+	mp, cleanup := newMempoolWithAppAndConfig(cc, wcfg)
+	defer cleanup()
+
+	txs := make(types.Txs, 5)
+	for i := range txs {
+		txs[i] = make(types.Tx, 8)
+		binary.BigEndian.PutUint64(txs[i], uint64(i))
+		require.NoError(t, mp.CheckTx(context.Background(), txs[i], nil, mempool.TxInfo{}))
+	}
+	require.Equal(t, 5, mp.Size())
+
+	// the first two txs no longer have a valid nonce once they were delivered,
+	// but they aren't rechecked as long as the validator set is unchanged
+	for _, tx := range txs[:2] {
+		_ = app.DeliverTx(abci.RequestDeliverTx{Tx: tx})
+	}
+	mp.Lock()
+	require.NoError(t, mp.Update(1, nil, nil, nil, nil))
+	mp.Unlock()
+	require.Equal(t, 5, mp.Size())
+
+	mp.Lock()
+	mp.ValidatorSetChanged()
+	require.NoError(t, mp.Update(2, nil, nil, nil, nil))
+	mp.Unlock()
+	require.Equal(t, 3, mp.Size())
+	for i, tx := range txs {
+		rejection, ok := mp.TxRejection(mempool.TxKey(tx))
+		if i < 2 {
+			require.True(t, ok, "tx %d should have been evicted", i)
+			require.Equal(t, mempool.RejectedValidatorSetChange, rejection.Reason)
+			require.Equal(t, code.CodeTypeBadNonce, rejection.Code)
+			require.EqualValues(t, 2, rejection.Height)
+		} else {
+			require.False(t, ok, "tx %d should have been kept", i)
+		}
+	}
+	require.Equal(t, txs[2:], mp.ReapMaxTxs(-1))
+
+	// the recheck is only forced for the update following the change
+	_ = app.DeliverTx(abci.RequestDeliverTx{Tx: txs[2]})
+	mp.Lock()
+	require.NoError(t, mp.Update(3, nil, nil, nil, nil))
+	mp.Unlock()
+	require.Equal(t, 3, mp.Size())
+}
+
+func TestMempool_ValidatorSetChangedWithoutRecheck(t *testing.T) {
+	app := counter.NewApplication(true)
+	cc := proxy.NewLocalClientCreator(app)
+	wcfg := cfg.DefaultConfig()
+	wcfg.Mempool.Recheck = false
+	mp, cleanup := newMempoolWithAppAndConfig(cc, wcfg)
+	defer cleanup()
+
+	tx := make(types.Tx, 8)
+	require.NoError(t, mp.CheckTx(context.Background(), tx, nil, mempool.TxInfo{}))
+	_ = app.DeliverTx(abci.RequestDeliverTx{Tx: tx})
+
+	// without the option, a change of the validator set doesn't force a recheck
+	mp.Lock()
+	mp.ValidatorSetChanged()
+	require.NoError(t, mp.Update(1, nil, nil, nil, nil))
+	mp.Unlock()
+	require.Equal(t, 1, mp.Size())
+}
+
+func TestMempool_Paused(t *testing.T) {
+	app := counter.NewApplication(true)
+	cc := proxy.NewLocalClientCreator(app)
+	wcfg := cfg.DefaultConfig()
+	wcfg.Mempool.RejectionCacheSize = 10
+	mp, cleanup := newMempoolWithAppAndConfig(cc, wcfg)
+	defer cleanup()
+
+	checkTx := func(tx types.Tx) *abci.ResponseCheckTx {
+		var res *abci.ResponseCheckTx
+		require.NoError(t, mp.CheckTx(context.Background(), tx, func(r *abci.Response) {
+			res = r.GetCheckTx()
+		}, mempool.TxInfo{}))
+		require.NotNil(t, res)
+		return res
+	}
+
+	a := make(types.Tx, 8)
+	binary.BigEndian.PutUint64(a, 0)
+	b := make(types.Tx, 8)
+	binary.BigEndian.PutUint64(b, 1)
+
+	require.Equal(t, abci.CodeTypeOK, checkTx(a).Code)
+
+	// while paused, new txs are rejected without reaching the app, and txs
+	// already in the mempool are unaffected
+	mp.SetPaused(true)
+	require.True(t, mp.Paused())
+	res := checkTx(b)
+	require.Equal(t, mempool.CodeTypeUnavailable, res.Code)
+	require.Equal(t, mempool.Codespace, res.Codespace)
+	require.Equal(t, 1, mp.Size())
+	require.Equal(t, types.Txs{a}, mp.ReapMaxTxs(-1))
+
+	rejection, ok := mp.TxRejection(mempool.TxKey(b))
+	require.True(t, ok)
+	require.Equal(t, mempool.RejectedPaused, rejection.Reason)
+	require.Equal(t, mempool.CodeTypeUnavailable, rejection.Code)
+
+	// once resumed, the rejected tx is accepted when submitted again
+	mp.SetPaused(false)
+	require.False(t, mp.Paused())
+	require.Equal(t, abci.CodeTypeOK, checkTx(b).Code)
+	require.Equal(t, 2, mp.Size())
+	_, ok = mp.TxRejection(mempool.TxKey(b))
+	require.False(t, ok)
+}
+
 func TestTxsAvailable(t *testing.T) {
 	app := kvstore.NewApplication()
 	cc := proxy.NewLocalClientCreator(app)
@@ -36,9 +36,10 @@ type PeerManager interface {
 type Reactor struct {
 	service.BaseService
 
-	config  *cfg.MempoolConfig
-	mempool *CListMempool
-	ids     *mempool.MempoolIDs
+	config      *cfg.MempoolConfig
+	gossipCache *mempool.GossipCache
+	mempool     *CListMempool
+	ids         *mempool.MempoolIDs
 
 	// XXX: Currently, this is the only way to get information about a peer. Ideally,
 	// we rely on message-oriented communication to get necessary peer data.
@@ -69,6 +70,7 @@ func NewReactor(
 
 	r := &Reactor{
 		config:       config,
+		gossipCache:  mempool.NewGossipCache(config.GossipCacheSize, config.GossipCacheTTL),
 		peerMgr:      peerMgr,
 		mempool:      mp,
 		ids:          mempool.NewMempoolIDs(),
@@ -169,7 +171,18 @@ func (r *Reactor) handleMempoolMessage(envelope p2p.Envelope) error {
 		}
 
 		for _, tx := range protoTxs {
+			// txs recently received from any peer have already been checked,
+			// but the sender is recorded so the tx isn't gossiped back to it
+			// once the cache forgets it
+			key := mempool.TxKey(tx)
+			if !r.gossipCache.Push(key, envelope.From) {
+				r.mempool.addSender(key, txInfo.SenderID)
+				continue
+			}
+
 			if err := r.mempool.CheckTx(context.Background(), types.Tx(tx), nil, txInfo); err != nil {
+				// the tx may be accepted later on, e.g. when the mempool is full
+				r.gossipCache.Remove(key)
 				logger.Error("checktx failed for tx", "tx", fmt.Sprintf("%X", mempool.TxHashFromBytes(tx)), "err", err)
 			}
 		}
@@ -357,7 +370,8 @@ func (r *Reactor) broadcastTxRoutine(peerID p2p.NodeID, closer *tmsync.Closer) {
 		// NOTE: Transaction batching was disabled due to:
 		// https://github.com/tendermint/tendermint/issues/5796
 
-		if _, ok := memTx.senders.Load(peerMempoolID); !ok {
+		_, isSender := memTx.senders.Load(peerMempoolID)
+		if !isSender && !r.gossipCache.HasPeer(mempool.TxKey(memTx.tx), peerID) {
 			// Send the mempool tx to the corresponding peer. Note, the peer may be
 			// behind and thus would not be able to process the mempool tx correctly.
 			r.mempoolCh.Out <- p2p.Envelope{
@@ -389,3 +389,154 @@ func TestBroadcastTxForPeerStopsWhenPeerStops(t *testing.T) {
 		NodeID: secondary,
 	}
 }
+
+// checkTxCounter counts the txs the application is asked to check.
+type checkTxCounter struct {
+	*kvstore.Application
+
+	mtx    sync.Mutex
+	checks int
+}
+
+func (app *checkTxCounter) CheckTx(req abci.RequestCheckTx) abci.ResponseCheckTx {
+	app.mtx.Lock()
+	app.checks++
+	app.mtx.Unlock()
+	return app.Application.CheckTx(req)
+}
+
+func TestReactor_GossipCache(t *testing.T) {
+	config := cfg.ResetTestRoot("mempool_test")
+	// disable the tx cache, such that only the gossip cache prevents re-checks
+	config.Mempool.CacheSize = 0
+
+	// Set up a network where only the primary node runs a reactor, such that
+	// the messages it gossips can be read from the other nodes' channels.
+	network := p2ptest.MakeNetwork(t, p2ptest.NetworkOptions{NumNodes: 4})
+	chDesc := p2p.ChannelDescriptor{ID: byte(mempool.MempoolChannel)}
+	channels := network.MakeChannelsNoCleanup(t, chDesc, new(protomem.Message), 10)
+
+	ids := network.NodeIDs()
+	primary, peerA, peerB, peerC := ids[0], ids[1], ids[2], ids[3]
+
+	app := &checkTxCounter{Application: kvstore.NewApplication()}
+	mp, cleanup := newMempoolWithAppAndConfig(proxy.NewLocalClientCreator(app), config)
+	t.Cleanup(cleanup)
+
+	peerUpdates := p2p.NewPeerUpdates(make(chan p2p.PeerUpdate), 1)
+	network.Nodes[primary].PeerManager.Register(peerUpdates)
+
+	reactor := NewReactor(
+		log.TestingLogger(),
+		config.Mempool,
+		network.Nodes[primary].PeerManager,
+		mp,
+		channels[primary],
+		peerUpdates,
+	)
+	require.NoError(t, reactor.Start())
+	t.Cleanup(func() {
+		require.NoError(t, reactor.Stop())
+	})
+
+	// The same tx is received from two peers, but only checked once.
+	tx := types.Tx("gossip=cache")
+	for _, peerID := range []p2p.NodeID{peerA, peerB} {
+		require.NoError(t, reactor.handleMempoolMessage(p2p.Envelope{
+			From:    peerID,
+			Message: &protomem.Txs{Txs: [][]byte{tx}},
+		}))
+	}
+	require.Equal(t, 1, mp.Size())
+	app.mtx.Lock()
+	require.Equal(t, 1, app.checks)
+	app.mtx.Unlock()
+
+	// Once connected, the tx is only gossiped to the peer it wasn't received
+	// from, and only once.
+	network.Start(t)
+
+	p2ptest.RequireReceive(t, channels[peerC], p2p.Envelope{
+		From:    primary,
+		Message: &protomem.Txs{Txs: [][]byte{tx}},
+	})
+	time.Sleep(100 * time.Millisecond)
+	p2ptest.RequireEmpty(t, channels[peerA], channels[peerB], channels[peerC])
+}
+
+// laggingPeers reports all peers at the given height.
+type laggingPeers struct {
+	mtx    sync.Mutex
+	height int64
+}
+
+func (p *laggingPeers) GetHeight(p2p.NodeID) int64 {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return p.height
+}
+
+func (p *laggingPeers) setHeight(height int64) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.height = height
+}
+
+func TestReactor_NoBroadcastToRepeatSender(t *testing.T) {
+	config := cfg.ResetTestRoot("mempool_test")
+
+	mp, cleanup := newMempoolWithAppAndConfig(proxy.NewLocalClientCreator(kvstore.NewApplication()), config)
+	t.Cleanup(cleanup)
+	// txs are added at height 3, and aren't gossiped to peers behind height 2
+	mp.Lock()
+	require.NoError(t, mp.Update(3, nil, abciResponses(0, abci.CodeTypeOK), nil, nil))
+	mp.Unlock()
+
+	outCh := make(chan p2p.Envelope, 3)
+	mempoolCh := p2p.NewChannel(
+		mempool.MempoolChannel,
+		new(protomem.Message),
+		make(chan p2p.Envelope),
+		outCh,
+		make(chan p2p.PeerError, 1),
+	)
+	peerUpdates := p2p.NewPeerUpdates(make(chan p2p.PeerUpdate), 1)
+	peers := &laggingPeers{height: 1}
+
+	reactor := NewReactor(log.TestingLogger(), config.Mempool, peers, mp, mempoolCh, peerUpdates)
+	require.NoError(t, reactor.Start())
+	t.Cleanup(func() {
+		require.NoError(t, reactor.Stop())
+	})
+
+	ids := []p2p.NodeID{"aa", "bb", "cc"}
+	for _, peerID := range ids {
+		reactor.processPeerUpdate(p2p.PeerUpdate{NodeID: peerID, Status: p2p.PeerStatusUp})
+	}
+	peerA, peerB, peerC := ids[0], ids[1], ids[2]
+
+	// The tx is received from A then B while the peers lag behind, such that
+	// it isn't gossiped in between.
+	tx := types.Tx("repeat=sender")
+	for _, peerID := range []p2p.NodeID{peerA, peerB} {
+		require.NoError(t, reactor.handleMempoolMessage(p2p.Envelope{
+			From:    peerID,
+			Message: &protomem.Txs{Txs: [][]byte{tx}},
+		}))
+	}
+	require.Equal(t, 1, mp.Size())
+
+	// Once the gossip cache forgets the tx, e.g. as it expired, the tx is still
+	// only gossiped to the peer it wasn't received from.
+	reactor.gossipCache.Remove(mempool.TxKey(tx))
+	peers.setHeight(3)
+
+	select {
+	case envelope := <-outCh:
+		require.Equal(t, peerC, envelope.To)
+	case <-time.After(time.Second):
+		t.Fatal("tx wasn't gossiped")
+	}
+	time.Sleep(2 * mempool.PeerCatchupSleepIntervalMS * time.Millisecond)
+	require.Empty(t, outCh)
+}
@@ -3,7 +3,9 @@ package v1
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"sync/atomic"
 	"time"
 
@@ -19,6 +21,10 @@ import (
 )
 
 var _ mempool.Mempool = (*TxMempool)(nil)
+var _ mempool.RejectionReporter = (*TxMempool)(nil)
+var _ mempool.Pauser = (*TxMempool)(nil)
+var _ mempool.ValidatorSetObserver = (*TxMempool)(nil)
+var _ mempool.IterableMempool = (*TxMempool)(nil)
 
 // TxMempoolOption sets an optional parameter on the TxMempool.
 type TxMempoolOption func(*TxMempool)
@@ -43,10 +49,17 @@ type TxMempool struct {
 	// sizeBytes defines the total size of the mempool (sum of all tx bytes)
 	sizeBytes int64
 
+	// paused is 1 while new transactions are rejected, see SetPaused
+	paused int32
+
 	// cache defines a fixed-size cache of already seen transactions as this
 	// reduces pressure on the proxyApp.
 	cache mempool.TxCache
 
+	// rejections retains the reasons recent transactions were rejected, if
+	// enabled.
+	rejections *mempool.RejectionCache
+
 	// txStore defines the main storage of valid transactions. Indexes are built
 	// on top of this store.
 	txStore *TxStore
@@ -69,6 +82,14 @@ type TxMempool struct {
 	recheckCursor *clist.CElement // next expected response
 	recheckEnd    *clist.CElement // re-checking stops here
 
+	// recheckReason is the reason transactions failing the current recheck
+	// are rejected with.
+	recheckReason mempool.RejectionReason
+
+	// validatorSetChanged is set by ValidatorSetChanged to force a recheck of
+	// all transactions during the next Update.
+	validatorSetChanged bool
+
 	// priorityIndex defines the priority index of valid transactions via a
 	// thread-safe priority queue.
 	priorityIndex *TxPriorityQueue
@@ -96,6 +117,7 @@ func NewTxMempool(
 		proxyAppConn:  proxyAppConn,
 		height:        height,
 		cache:         mempool.NopTxCache{},
+		rejections:    mempool.NewRejectionCache(cfg.RejectionCacheSize),
 		metrics:       mempool.NopMetrics(),
 		txStore:       NewTxStore(),
 		gossipIndex:   clist.New(),
@@ -134,6 +156,13 @@ func WithMetrics(metrics *mempool.Metrics) TxMempoolOption {
 	return func(txmp *TxMempool) { txmp.metrics = metrics }
 }
 
+// WithTxComparator sets the order in which transactions are reaped, which
+// defaults to DefaultTxComparator. Transactions are still evicted, to make room
+// for one of higher priority, in order of priority.
+func WithTxComparator(less TxComparator) TxMempoolOption {
+	return func(txmp *TxMempool) { txmp.priorityIndex = NewTxPriorityQueueWithComparator(less) }
+}
+
 // Lock obtains a write-lock on the mempool. A caller must be sure to explicitly
 // release the lock when finished.
 func (txmp *TxMempool) Lock() {
@@ -157,6 +186,46 @@ func (txmp *TxMempool) SizeBytes() int64 {
 	return atomic.LoadInt64(&txmp.sizeBytes)
 }
 
+// SetPaused pauses or resumes the mempool. While paused, CheckTx rejects new
+// transactions with mempool.CodeTypeUnavailable, without calling the
+// application. It implements mempool.Pauser.
+func (txmp *TxMempool) SetPaused(paused bool) {
+	var v int32
+	if paused {
+		v = 1
+	}
+	atomic.StoreInt32(&txmp.paused, v)
+}
+
+// Paused implements mempool.Pauser.
+func (txmp *TxMempool) Paused() bool {
+	return atomic.LoadInt32(&txmp.paused) == 1
+}
+
+// TxRejection returns the last rejection of the transaction with the given
+// key, if rejections are retained. It implements mempool.RejectionReporter.
+func (txmp *TxMempool) TxRejection(key [mempool.TxKeySize]byte) (mempool.TxRejection, bool) {
+	return txmp.rejections.Get(key)
+}
+
+// TxsBySender implements mempool.SenderIndex. It is thread-safe.
+func (txmp *TxMempool) TxsBySender() map[string]types.Txs {
+	wTxs := txmp.txStore.GetAllTxs()
+	sort.Slice(wTxs, func(i, j int) bool {
+		return wTxs[i].timestamp.Before(wTxs[j].timestamp)
+	})
+
+	txs := make(map[string]types.Txs)
+	for _, wtx := range wTxs {
+		sender := wtx.sender
+		if sender == "" {
+			sender = mempool.UnknownSender
+		}
+		txs[sender] = append(txs[sender], wtx.tx)
+	}
+	return txs
+}
+
 // FlushAppConn executes FlushSync on the mempool's proxyAppConn.
 //
 // NOTE: The caller must obtain a write-lock via Lock() prior to execution.
@@ -222,16 +291,29 @@ func (txmp *TxMempool) CheckTx(
 	txmp.mtx.RLock()
 	defer txmp.mtx.RUnlock()
 
+	if txmp.Paused() {
+		txmp.reject(
+			mempool.TxKey(tx), mempool.RejectedPaused, mempool.CodeTypeUnavailable, mempool.ErrMempoolPaused.Error(),
+		)
+		if cb != nil {
+			cb(mempool.PausedResponse())
+		}
+		return nil
+	}
+
 	txSize := len(tx)
 	if txSize > txmp.config.MaxTxBytes {
-		return mempool.ErrTxTooLarge{
+		err := mempool.ErrTxTooLarge{
 			Max:    txmp.config.MaxTxBytes,
 			Actual: txSize,
 		}
+		txmp.reject(mempool.TxKey(tx), mempool.RejectedTooLarge, 0, err.Error())
+		return err
 	}
 
 	if txmp.preCheck != nil {
 		if err := txmp.preCheck(tx); err != nil {
+			txmp.reject(mempool.TxKey(tx), mempool.RejectedPreCheck, 0, err.Error())
 			return mempool.ErrPreCheck{
 				Reason: err,
 			}
@@ -313,13 +395,26 @@ func (txmp *TxMempool) Flush() {
 }
 
 // ReapMaxBytesMaxGas returns a list of transactions within the provided size
-// and gas constraints. Transaction are retrieved in priority order.
+// and gas constraints. Transaction are retrieved in the order defined by the
+// mempool's TxComparator, i.e. in priority order by default. Transactions with
+// a gas price below the configured ProposalMinGasPrice are left out, see
+// ReapWithMinGasPrice.
 //
 // NOTE:
 // - A read-lock is acquired.
 // - Transactions returned are not actually removed from the mempool transaction
 //   store or indexes.
 func (txmp *TxMempool) ReapMaxBytesMaxGas(maxBytes, maxGas int64) types.Txs {
+	return txmp.ReapWithMinGasPrice(maxBytes, maxGas, txmp.config.ProposalMinGasPrice)
+}
+
+// ReapWithMinGasPrice is ReapMaxBytesMaxGas, leaving out the transactions with
+// a gas price below minGasPrice. This lets a proposer exclude cheap
+// transactions, e.g. during congestion, while keeping them in the mempool,
+// unlike the mempool's own minimum gas price, which rejects them on CheckTx.
+// Left out transactions don't count towards the size and gas constraints. A
+// minGasPrice of 0 leaves out none.
+func (txmp *TxMempool) ReapWithMinGasPrice(maxBytes, maxGas int64, minGasPrice float64) types.Txs {
 	txmp.mtx.RLock()
 	defer txmp.mtx.RUnlock()
 
@@ -340,8 +435,11 @@ func (txmp *TxMempool) ReapMaxBytesMaxGas(maxBytes, maxGas int64) types.Txs {
 	txs := make([]types.Tx, 0, txmp.priorityIndex.NumTxs())
 	for txmp.priorityIndex.NumTxs() > 0 {
 		wtx := txmp.priorityIndex.PopTx()
-		txs = append(txs, wtx.tx)
 		wTxs = append(wTxs, wtx)
+		if minGasPrice > 0 && wtx.GasPrice() < minGasPrice {
+			continue
+		}
+		txs = append(txs, wtx.tx)
 		size := types.ComputeProtoSizeForTxs([]types.Tx{wtx.tx})
 
 		// Ensure we have capacity for the transaction with respect to the
@@ -364,8 +462,98 @@ func (txmp *TxMempool) ReapMaxBytesMaxGas(maxBytes, maxGas int64) types.Txs {
 	return txs
 }
 
+// ReapIterator returns an iterator over the transactions that
+// ReapMaxBytesMaxGas(maxBytes, maxGas) would return, in the same order. Unlike
+// ReapMaxBytesMaxGas, transactions are only ordered as they are consumed, and
+// neither copied nor popped from the mempool, so a caller that stops early,
+// e.g. once it has accumulated enough transactions by its own measure, doesn't
+// pay for ordering the rest.
+//
+// NOTE:
+// - The iterator holds the transactions in the mempool at the time it is
+//   created: transactions added later aren't yielded, and transactions removed
+//   later still are. Its transactions are therefore always a prefix of those
+//   ReapMaxBytesMaxGas would have returned when it was created.
+// - No lock is held between calls to Next, so the iterator can be abandoned at
+//   any point.
+//
+// It implements mempool.IterableMempool.
+func (txmp *TxMempool) ReapIterator(maxBytes, maxGas int64) mempool.TxIterator {
+	txmp.mtx.RLock()
+	defer txmp.mtx.RUnlock()
+
+	txs := txmp.priorityIndex.view()
+	frontier := &txIndexHeap{txs: txs, less: txmp.priorityIndex.less, indexes: make([]int, 0, 2)}
+	if len(txs) > 0 {
+		frontier.indexes = append(frontier.indexes, 0)
+	}
+
+	return &txIterator{
+		txmp:        txmp,
+		frontier:    frontier,
+		maxBytes:    maxBytes,
+		maxGas:      maxGas,
+		minGasPrice: txmp.config.ProposalMinGasPrice,
+	}
+}
+
+// txIterator yields the transactions of a mempool in reap order, within size
+// and gas constraints, by walking a view of the heap of its priority queue. It
+// is not thread-safe. See ReapIterator.
+type txIterator struct {
+	txmp     *TxMempool
+	frontier *txIndexHeap
+	maxBytes int64
+	maxGas   int64
+
+	// transactions with a lower gas price are skipped, see ReapWithMinGasPrice
+	minGasPrice float64
+
+	totalSize int64
+	totalGas  int64
+	done      bool
+}
+
+// Next returns the next transaction, and true, or false once there are no more
+// transactions or the next one would exceed the size or gas constraints.
+func (it *txIterator) Next() (types.Tx, bool) {
+	if it.done {
+		return nil, false
+	}
+
+	// the view doesn't change, but the transactions' priorities may be
+	// updated on recheck
+	it.txmp.mtx.RLock()
+	defer it.txmp.mtx.RUnlock()
+
+	for it.frontier.Len() > 0 {
+		wtx := it.frontier.txs[it.frontier.indexes[0]]
+		if it.minGasPrice > 0 && wtx.GasPrice() < it.minGasPrice {
+			it.frontier.pop()
+			continue
+		}
+
+		size := types.ComputeProtoSizeForTxs([]types.Tx{wtx.tx})
+		gas := it.totalGas + wtx.gasWanted
+		if (it.maxBytes > -1 && it.totalSize+size > it.maxBytes) || (it.maxGas > -1 && gas > it.maxGas) {
+			// as with ReapMaxBytesMaxGas, no further transaction is considered
+			break
+		}
+
+		it.frontier.pop()
+		it.totalSize += size
+		it.totalGas = gas
+		return wtx.tx, true
+	}
+
+	it.done = true
+	it.frontier = nil
+	return nil, false
+}
+
 // ReapMaxTxs returns a list of transactions within the provided number of
-// transactions bound. Transaction are retrieved in priority order.
+// transactions bound. Transaction are retrieved in the order defined by the
+// mempool's TxComparator, i.e. in priority order by default.
 //
 // NOTE:
 // - A read-lock is acquired.
@@ -443,15 +631,26 @@ func (txmp *TxMempool) Update(
 		}
 	}
 
+	// A change of the validator set forces a re-CheckTx, even if rechecking is
+	// disabled, and the transactions it invalidates are rejected as such.
+	recheck := txmp.config.Recheck
+	txmp.recheckReason = mempool.RejectedRecheck
+	if txmp.validatorSetChanged {
+		recheck = true
+		txmp.recheckReason = mempool.RejectedValidatorSetChange
+		txmp.validatorSetChanged = false
+	}
+
 	// If there any uncommitted transactions left in the mempool, we either
 	// initiate re-CheckTx per remaining transaction or notify that remaining
 	// transactions are left.
 	if txmp.Size() > 0 {
-		if txmp.config.Recheck {
+		if recheck {
 			txmp.logger.Debug(
 				"executing re-CheckTx for all remaining transactions",
 				"num_txs", txmp.Size(),
 				"height", blockHeight,
+				"reason", txmp.recheckReason,
 			)
 			txmp.updateReCheckTxs()
 		} else {
@@ -463,6 +662,18 @@ func (txmp *TxMempool) Update(
 	return nil
 }
 
+// ValidatorSetChanged forces the next Update to re-CheckTx all remaining
+// transactions if the mempool is configured to recheck on a validator set
+// change. It implements mempool.ValidatorSetObserver.
+//
+// NOTE:
+// - The caller must explicitly acquire a write-lock via Lock().
+func (txmp *TxMempool) ValidatorSetChanged() {
+	if txmp.config.RecheckOnValidatorSetChange {
+		txmp.validatorSetChanged = true
+	}
+}
+
 // initTxCallback performs the initial, i.e. the first, callback after CheckTx
 // has been executed by the ABCI application. In other words, initTxCallback is
 // called after executing CheckTx when we see a unique transaction for the first
@@ -493,19 +704,24 @@ func (txmp *TxMempool) initTxCallback(wtx *WrappedTx, res *abci.Response, txInfo
 		if txmp.postCheck != nil {
 			err = txmp.postCheck(wtx.tx, checkTxRes.CheckTx)
 		}
+		if err == nil {
+			err = mempool.EnforceMinGasPrice(txmp.config.MinGasPrice, checkTxRes.CheckTx)
+		}
 
 		if checkTxRes.CheckTx.Code == abci.CodeTypeOK && err == nil {
 			sender := checkTxRes.CheckTx.Sender
 			priority := checkTxRes.CheckTx.Priority
 
 			if len(sender) > 0 {
-				if wtx := txmp.txStore.GetTxBySender(sender); wtx != nil {
+				if existing := txmp.txStore.GetTxBySender(sender); existing != nil {
 					txmp.logger.Error(
 						"rejected incoming good transaction; tx already exists for sender",
-						"tx", fmt.Sprintf("%X", wtx.tx.Hash()),
+						"tx", fmt.Sprintf("%X", existing.tx.Hash()),
 						"sender", sender,
 					)
 					txmp.metrics.RejectedTxs.Add(1)
+					txmp.reject(wtx.hash, mempool.RejectedSenderExists, 0,
+						fmt.Sprintf("tx %X already exists for sender %s", existing.tx.Hash(), sender))
 					return
 				}
 			}
@@ -527,6 +743,7 @@ func (txmp *TxMempool) initTxCallback(wtx *WrappedTx, res *abci.Response, txInfo
 						"err", err.Error(),
 					)
 					txmp.metrics.RejectedTxs.Add(1)
+					txmp.reject(wtx.hash, mempool.RejectedMempoolFull, 0, err.Error())
 					return
 				}
 
@@ -545,6 +762,8 @@ func (txmp *TxMempool) initTxCallback(wtx *WrappedTx, res *abci.Response, txInfo
 						"new_priority", wtx.priority,
 					)
 					txmp.metrics.EvictedTxs.Add(1)
+					txmp.reject(toEvict.hash, mempool.RejectedEvicted, 0,
+						fmt.Sprintf("evicted by tx %X with priority %d", wtx.tx.Hash(), priority))
 				}
 			}
 
@@ -581,6 +800,7 @@ func (txmp *TxMempool) initTxCallback(wtx *WrappedTx, res *abci.Response, txInfo
 			)
 
 			txmp.metrics.FailedTxs.Add(1)
+			txmp.rejectBadTx(wtx.hash, checkTxRes.CheckTx, err, false)
 
 			if !txmp.config.KeepInvalidTxsInCache {
 				txmp.cache.Remove(wtx.tx)
@@ -620,6 +840,9 @@ func (txmp *TxMempool) defaultTxCallback(req *abci.Request, res *abci.Response)
 			if txmp.postCheck != nil {
 				err = txmp.postCheck(tx, checkTxRes.CheckTx)
 			}
+			if err == nil {
+				err = mempool.EnforceMinGasPrice(txmp.config.MinGasPrice, checkTxRes.CheckTx)
+			}
 
 			if checkTxRes.CheckTx.Code == abci.CodeTypeOK && err == nil {
 				wtx.priority = checkTxRes.CheckTx.Priority
@@ -637,6 +860,7 @@ func (txmp *TxMempool) defaultTxCallback(req *abci.Request, res *abci.Response)
 				}
 
 				txmp.removeTx(wtx, !txmp.config.KeepInvalidTxsInCache)
+				txmp.rejectBadTx(wtx.hash, checkTxRes.CheckTx, err, true)
 			}
 		}
 
@@ -729,6 +953,9 @@ func (txmp *TxMempool) insertTx(wtx *WrappedTx) {
 	wtx.gossipEl = gossipEl
 
 	atomic.AddInt64(&txmp.sizeBytes, int64(wtx.Size()))
+
+	// the transaction may have been rejected before, e.g. when the mempool was full
+	txmp.rejections.Remove(wtx.hash)
 }
 
 func (txmp *TxMempool) removeTx(wtx *WrappedTx, removeFromCache bool) {
@@ -751,6 +978,39 @@ func (txmp *TxMempool) removeTx(wtx *WrappedTx, removeFromCache bool) {
 	}
 }
 
+// reject records the rejection of a transaction, if rejections are retained.
+func (txmp *TxMempool) reject(key [mempool.TxKeySize]byte, reason mempool.RejectionReason, code uint32, log string) {
+	txmp.rejections.Add(key, mempool.TxRejection{
+		Reason: reason,
+		Code:   code,
+		Log:    log,
+		Height: txmp.height,
+		Time:   time.Now().UTC(),
+	})
+}
+
+// rejectBadTx records the rejection of a transaction that failed CheckTx, the
+// post-check filter or the minimum gas price. Failed rechecks are recorded with
+// the reason of the recheck, whatever failed.
+func (txmp *TxMempool) rejectBadTx(
+	key [mempool.TxKeySize]byte,
+	res *abci.ResponseCheckTx,
+	postCheckErr error,
+	recheck bool,
+) {
+	reason, log := mempool.RejectedCheckTx, res.Log
+	switch {
+	case errors.As(postCheckErr, &mempool.ErrGasPriceTooLow{}):
+		reason = mempool.RejectedGasPrice
+	case res.Code == abci.CodeTypeOK && postCheckErr != nil:
+		reason, log = mempool.RejectedPostCheck, postCheckErr.Error()
+	}
+	if recheck {
+		reason = txmp.recheckReason
+	}
+	txmp.reject(key, reason, res.Code, log)
+}
+
 func (txmp *TxMempool) notifyTxsAvailable() {
 	if txmp.Size() == 0 {
 		panic("attempt to notify txs available but mempool is empty!")
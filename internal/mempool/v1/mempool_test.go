@@ -3,9 +3,11 @@ package v1
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"sync"
@@ -73,7 +75,12 @@ func (app *application) CheckTx(req abci.RequestCheckTx) abci.ResponseCheckTx {
 func setup(t testing.TB, cacheSize int) *TxMempool {
 	t.Helper()
 
-	app := &application{kvstore.NewApplication()}
+	return setupWithApp(t, &application{kvstore.NewApplication()}, cacheSize)
+}
+
+func setupWithApp(t testing.TB, app abci.Application, cacheSize int) *TxMempool {
+	t.Helper()
+
 	cc := proxy.NewLocalClientCreator(app)
 
 	cfg := config.ResetTestRoot(t.Name())
@@ -271,6 +278,172 @@ func TestTxMempool_ReapMaxBytesMaxGas(t *testing.T) {
 	require.Len(t, reapedTxs, 26)
 }
 
+// gasApplication extends the test application by having transactions want the
+// amount of gas in their key (sender=gas=priority).
+type gasApplication struct {
+	*application
+}
+
+func (app *gasApplication) CheckTx(req abci.RequestCheckTx) abci.ResponseCheckTx {
+	res := app.application.CheckTx(req)
+	if res.Code == code.CodeTypeOK {
+		gas, err := strconv.ParseInt(string(bytes.Split(req.Tx, []byte("="))[1]), 10, 64)
+		if err != nil {
+			return abci.ResponseCheckTx{Code: 102, GasWanted: 1}
+		}
+		res.GasWanted = gas
+	}
+	return res
+}
+
+func TestTxMempool_ReapMaxBytesMaxGas_Comparator(t *testing.T) {
+	txmp := setupWithApp(t, &gasApplication{&application{kvstore.NewApplication()}}, 0)
+
+	// order txs by gas price, i.e. priority per unit of gas, and then by
+	// arrival
+	WithTxComparator(GasPriceTxComparator)(txmp)
+
+	txs := []types.Tx{
+		types.Tx("a=1=10"),  // gas price 10
+		types.Tx("b=4=20"),  // gas price 5
+		types.Tx("c=2=30"),  // gas price 15
+		types.Tx("d=5=100"), // gas price 20
+		types.Tx("e=10=50"), // gas price 5, received after b
+	}
+	for _, tx := range txs {
+		require.NoError(t, txmp.CheckTx(context.Background(), tx, nil, mempool.TxInfo{SenderID: 0}))
+	}
+	require.Equal(t, len(txs), txmp.Size())
+
+	// whereas txs are reaped by priority by default, i.e. d, e, c, b, a
+	byGasPrice := types.Txs{txs[3], txs[2], txs[0], txs[1], txs[4]}
+	require.Equal(t, byGasPrice, txmp.ReapMaxBytesMaxGas(-1, -1))
+	require.Equal(t, byGasPrice[:2], txmp.ReapMaxTxs(2))
+
+	// the gas limit applies to the txs in the comparator's order
+	require.Equal(t, byGasPrice[:3], txmp.ReapMaxBytesMaxGas(-1, 8))
+	require.Equal(t, len(txs), txmp.Size())
+}
+
+func TestTxMempool_ReapWithMinGasPrice(t *testing.T) {
+	txmp := setupWithApp(t, &gasApplication{&application{kvstore.NewApplication()}}, 0)
+
+	txs := []types.Tx{
+		types.Tx("a=1=10"),  // gas price 10
+		types.Tx("b=4=20"),  // gas price 5
+		types.Tx("c=2=30"),  // gas price 15
+		types.Tx("d=5=100"), // gas price 20
+		types.Tx("e=10=50"), // gas price 5
+		types.Tx("f=20=40"), // gas price 2
+	}
+	for _, tx := range txs {
+		require.NoError(t, txmp.CheckTx(context.Background(), tx, nil, mempool.TxInfo{SenderID: 0}))
+	}
+
+	// without a floor, all txs are reaped in priority order
+	byPriority := types.Txs{txs[3], txs[4], txs[5], txs[2], txs[1], txs[0]}
+	require.Equal(t, byPriority, txmp.ReapWithMinGasPrice(-1, -1, 0))
+	require.Equal(t, txmp.ReapMaxBytesMaxGas(-1, -1), txmp.ReapWithMinGasPrice(-1, -1, 0))
+
+	// the txs below the floor are left out, still in priority order
+	aboveFloor := types.Txs{txs[3], txs[2], txs[0]}
+	require.Equal(t, aboveFloor, txmp.ReapWithMinGasPrice(-1, -1, 10))
+	require.Empty(t, txmp.ReapWithMinGasPrice(-1, -1, 21))
+
+	// the limits only apply to the txs above the floor, e.g. e wanting 10 gas
+	// doesn't stop d and c from being reaped
+	require.Equal(t, aboveFloor[:2], txmp.ReapWithMinGasPrice(-1, 7, 10))
+	require.Equal(t, aboveFloor[:2], txmp.ReapWithMinGasPrice(types.ComputeProtoSizeForTxs(aboveFloor[:2]), -1, 10))
+
+	// the txs left out remain in the mempool
+	require.Equal(t, len(txs), txmp.Size())
+	require.Equal(t, byPriority, txmp.ReapMaxBytesMaxGas(-1, -1))
+
+	// the txs reaped for proposals, either way, are above the configured floor
+	txmp.config.ProposalMinGasPrice = 10
+	require.Equal(t, aboveFloor, txmp.ReapMaxBytesMaxGas(-1, -1))
+	require.Equal(t, aboveFloor[:2], txmp.ReapMaxBytesMaxGas(-1, 7))
+
+	var iterated types.Txs
+	it := txmp.ReapIterator(-1, 7)
+	for tx, ok := it.Next(); ok; tx, ok = it.Next() {
+		iterated = append(iterated, tx)
+	}
+	require.Equal(t, aboveFloor[:2], iterated)
+}
+
+func TestTxMempool_ReapIterator(t *testing.T) {
+	txmp := setup(t, 0)
+	tTxs := checkTxs(t, txmp, 100, 0) // all txs request 1 gas unit
+	require.Equal(t, int64(5490), txmp.SizeBytes())
+
+	// iterating over all the txs yields the same txs as reaping them, within
+	// the same constraints
+	for _, limits := range [][2]int64{{-1, -1}, {-1, 50}, {1000, -1}, {1500, 30}} {
+		var iterated types.Txs
+		it := txmp.ReapIterator(limits[0], limits[1])
+		for tx, ok := it.Next(); ok; tx, ok = it.Next() {
+			iterated = append(iterated, tx)
+		}
+		require.Equal(t, txmp.ReapMaxBytesMaxGas(limits[0], limits[1]), iterated, "limits %v", limits)
+
+		// once done, the iterator stays done
+		_, ok := it.Next()
+		require.False(t, ok)
+	}
+
+	// abandoning an iterator partway yields a prefix of the reaped txs
+	it := txmp.ReapIterator(-1, 50)
+	var iterated types.Txs
+	for i := 0; i < 10; i++ {
+		tx, ok := it.Next()
+		require.True(t, ok)
+		iterated = append(iterated, tx)
+	}
+	reaped := txmp.ReapMaxBytesMaxGas(-1, 50)
+	require.Len(t, reaped, 50)
+	require.Equal(t, reaped[:10], iterated)
+
+	// the iterator holds the mempool as it was when created: txs added while
+	// iterating aren't yielded, even if they outrank the txs already yielded,
+	// nor are txs removed and added back yielded twice, and txs removed are
+	// still yielded, so the txs are those that were reaped before the changes
+	reaped = txmp.ReapMaxBytesMaxGas(-1, -1)
+	it = txmp.ReapIterator(-1, -1)
+	iterated = nil
+	for i := 0; i < 10; i++ {
+		tx, ok := it.Next()
+		require.True(t, ok)
+		iterated = append(iterated, tx)
+	}
+	added := types.Tx("sender-added=key=9999")
+	require.NoError(t, txmp.CheckTx(context.Background(), added, nil, mempool.TxInfo{}))
+	readded := txmp.txStore.GetTxByHash(mempool.TxKey(iterated[0]))
+	txmp.Lock()
+	txmp.removeTx(readded, false)
+	txmp.Unlock()
+	require.NoError(t, txmp.CheckTx(context.Background(), iterated[0], nil, mempool.TxInfo{}))
+	removed := reaped[len(reaped)-1]
+	txmp.Lock()
+	txmp.removeTx(txmp.txStore.GetTxByHash(mempool.TxKey(removed)), false)
+	txmp.Unlock()
+	for tx, ok := it.Next(); ok; tx, ok = it.Next() {
+		require.NotEqual(t, added, tx)
+		iterated = append(iterated, tx)
+	}
+	require.Equal(t, reaped, iterated)
+
+	// the mempool is left intact, and its txs can still be removed
+	require.Equal(t, len(tTxs), txmp.Size())
+	require.Len(t, txmp.ReapMaxBytesMaxGas(-1, -1), len(tTxs))
+	require.Contains(t, txmp.ReapMaxBytesMaxGas(-1, -1), added)
+	txmp.Lock()
+	txmp.removeTx(txmp.txStore.GetTxByHash(mempool.TxKey(tTxs[0].tx)), false)
+	txmp.Unlock()
+	require.Equal(t, len(tTxs)-1, txmp.Size())
+	require.Equal(t, txmp.Size(), txmp.priorityIndex.NumTxs())
+}
+
 func TestTxMempool_ReapMaxTxs(t *testing.T) {
 	txmp := setup(t, 0)
 	tTxs := checkTxs(t, txmp, 100, 0)
@@ -368,6 +541,41 @@ func TestTxMempool_CheckTxSameSender(t *testing.T) {
 	require.Equal(t, 1, txmp.Size())
 }
 
+func TestTxMempool_TxsBySender(t *testing.T) {
+	txmp := setup(t, 100)
+
+	// transactions with an empty sender aren't attributed by the application
+	txs := []types.Tx{
+		[]byte("alice=a=10"),
+		[]byte("=x=10"),
+		[]byte("bob=b=10"),
+		[]byte("=y=10"),
+		[]byte("=z=10"),
+	}
+	for _, tx := range txs {
+		require.NoError(t, txmp.CheckTx(context.Background(), tx, nil, mempool.TxInfo{SenderID: 1}))
+	}
+	require.Equal(t, len(txs), txmp.Size())
+
+	require.Equal(t, map[string]types.Txs{
+		"alice":               {txs[0]},
+		"bob":                 {txs[2]},
+		mempool.UnknownSender: {txs[1], txs[3], txs[4]},
+	}, txmp.TxsBySender())
+
+	// committed transactions are no longer indexed
+	txmp.Lock()
+	require.NoError(t, txmp.Update(1, types.Txs{txs[0], txs[3]}, []*abci.ResponseDeliverTx{
+		{Code: abci.CodeTypeOK}, {Code: abci.CodeTypeOK},
+	}, nil, nil))
+	txmp.Unlock()
+
+	require.Equal(t, map[string]types.Txs{
+		"bob":                 {txs[2]},
+		mempool.UnknownSender: {txs[1], txs[4]},
+	}, txmp.TxsBySender())
+}
+
 func TestTxMempool_ConcurrentTxs(t *testing.T) {
 	txmp := setup(t, 100)
 	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
@@ -431,3 +639,266 @@ func TestTxMempool_ConcurrentTxs(t *testing.T) {
 	require.Zero(t, txmp.Size())
 	require.Zero(t, txmp.SizeBytes())
 }
+
+// rejectingApplication extends application by rejecting a configurable set of
+// transactions.
+type rejectingApplication struct {
+	*application
+
+	rejected map[string]bool
+}
+
+func (app *rejectingApplication) CheckTx(req abci.RequestCheckTx) abci.ResponseCheckTx {
+	if app.rejected[string(req.Tx)] {
+		return abci.ResponseCheckTx{Code: 102, GasWanted: 1}
+	}
+
+	return app.application.CheckTx(req)
+}
+
+func TestTxMempool_Snapshot(t *testing.T) {
+	txmp := setup(t, 0)
+	tTxs := checkTxs(t, txmp, 20, mempool.UnknownPeerID)
+	require.Equal(t, len(tTxs), txmp.Size())
+
+	path := filepath.Join(t.TempDir(), "mempool.snapshot")
+	require.NoError(t, mempool.SaveSnapshot(path, txmp))
+
+	// simulate a restart where the first five transactions are no longer valid
+	rejected := make(map[string]bool)
+	for _, tTx := range tTxs[:5] {
+		rejected[string(tTx.tx)] = true
+	}
+
+	app := &rejectingApplication{&application{kvstore.NewApplication()}, rejected}
+	restored := setupWithApp(t, app, 0)
+	require.Zero(t, restored.Size())
+
+	require.NoError(t, mempool.LoadSnapshot(context.Background(), log.TestingLogger(), path, restored))
+	require.Equal(t, len(tTxs)-5, restored.Size())
+
+	for i, tTx := range tTxs {
+		wtx := restored.txStore.GetTxByHash(mempool.TxKey(tTx.tx))
+		if i < 5 {
+			require.Nil(t, wtx, "tx %d should have been dropped", i)
+		} else {
+			require.NotNil(t, wtx, "tx %d should have been restored", i)
+			require.Equal(t, tTx.priority, wtx.priority)
+		}
+	}
+
+	// the snapshot is consumed once restored
+	_, err := os.Stat(path)
+	require.True(t, os.IsNotExist(err))
+	require.NoError(t, mempool.LoadSnapshot(context.Background(), log.TestingLogger(), path, restored))
+}
+
+func TestTxMempool_TxRejection(t *testing.T) {
+	app := &rejectingApplication{&application{kvstore.NewApplication()}, map[string]bool{}}
+	txmp := setupWithApp(t, app, 100)
+	txmp.rejections = mempool.NewRejectionCache(100)
+	txmp.config.Size = 2
+	txmp.postCheck = func(tx types.Tx, _ *abci.ResponseCheckTx) error {
+		if bytes.HasPrefix(tx, []byte("postcheck")) {
+			return errors.New("post-check failed")
+		}
+		return nil
+	}
+
+	requireRejection := func(tx types.Tx, reason mempool.RejectionReason, code uint32, height int64) {
+		t.Helper()
+
+		rejection, ok := txmp.TxRejection(mempool.TxKey(tx))
+		require.True(t, ok, "tx %q should have been rejected", tx)
+		require.Equal(t, reason, rejection.Reason)
+		require.Equal(t, code, rejection.Code)
+		require.Equal(t, height, rejection.Height)
+		require.False(t, rejection.Time.IsZero())
+	}
+	checkTx := func(tx types.Tx) error {
+		return txmp.CheckTx(context.Background(), tx, nil, mempool.TxInfo{SenderID: 1})
+	}
+
+	tooLarge := make(types.Tx, txmp.config.MaxTxBytes+1)
+	require.Error(t, checkTx(tooLarge))
+	requireRejection(tooLarge, mempool.RejectedTooLarge, 0, 0)
+
+	badTx := types.Tx("bad")
+	require.NoError(t, checkTx(badTx))
+	requireRejection(badTx, mempool.RejectedCheckTx, 101, 0)
+
+	postCheckTx := types.Tx("postcheck=aa=10")
+	require.NoError(t, checkTx(postCheckTx))
+	requireRejection(postCheckTx, mempool.RejectedPostCheck, 0, 0)
+
+	tx1 := types.Tx("sender-0=aa=10")
+	require.NoError(t, checkTx(tx1))
+	sameSenderTx := types.Tx("sender-0=bb=10")
+	require.NoError(t, checkTx(sameSenderTx))
+	requireRejection(sameSenderTx, mempool.RejectedSenderExists, 0, 0)
+
+	// the mempool holds two txs, which both have a higher priority than tx3
+	tx2 := types.Tx("sender-1=aa=20")
+	require.NoError(t, checkTx(tx2))
+	tx3 := types.Tx("sender-2=aa=5")
+	require.NoError(t, checkTx(tx3))
+	requireRejection(tx3, mempool.RejectedMempoolFull, 0, 0)
+
+	// tx4 evicts tx1, which has the lowest priority
+	tx4 := types.Tx("sender-3=aa=30")
+	require.NoError(t, checkTx(tx4))
+	requireRejection(tx1, mempool.RejectedEvicted, 0, 0)
+	_, ok := txmp.TxRejection(mempool.TxKey(tx4))
+	require.False(t, ok)
+
+	// tx2 is no longer valid once a block is committed
+	app.rejected[string(tx2)] = true
+	txmp.Lock()
+	require.NoError(t, txmp.Update(1, nil, nil, nil, nil))
+	txmp.Unlock()
+	require.Equal(t, 1, txmp.Size())
+	requireRejection(tx2, mempool.RejectedRecheck, 102, 1)
+
+	// a rejected tx that is accepted later on is no longer reported as rejected
+	require.NoError(t, checkTx(tx3))
+	require.Equal(t, 2, txmp.Size())
+	_, ok = txmp.TxRejection(mempool.TxKey(tx3))
+	require.False(t, ok)
+}
+
+func TestTxMempool_RecheckOnValidatorSetChange(t *testing.T) {
+	app := &rejectingApplication{&application{kvstore.NewApplication()}, map[string]bool{}}
+	txmp := setupWithApp(t, app, 100)
+	txmp.rejections = mempool.NewRejectionCache(100)
+	txmp.config.Recheck = false
+	txmp.config.RecheckOnValidatorSetChange = true
+
+	tTxs := checkTxs(t, txmp, 10, mempool.UnknownPeerID)
+	require.Equal(t, 10, txmp.Size())
+
+	update := func(height int64, validatorSetChanged bool) {
+		t.Helper()
+
+		txmp.Lock()
+		defer txmp.Unlock()
+		if validatorSetChanged {
+			txmp.ValidatorSetChanged()
+		}
+		require.NoError(t, txmp.Update(height, nil, nil, nil, nil))
+	}
+
+	// the application stops accepting the first three transactions, which are
+	// only rechecked once the validator set changes
+	for _, tTx := range tTxs[:3] {
+		app.rejected[string(tTx.tx)] = true
+	}
+	update(1, false)
+	require.Equal(t, 10, txmp.Size())
+
+	update(2, true)
+	require.Equal(t, 7, txmp.Size())
+	for i, tTx := range tTxs {
+		rejection, ok := txmp.TxRejection(mempool.TxKey(tTx.tx))
+		if i < 3 {
+			require.True(t, ok, "tx %d should have been evicted", i)
+			require.Equal(t, mempool.RejectedValidatorSetChange, rejection.Reason)
+			require.EqualValues(t, 102, rejection.Code)
+			require.EqualValues(t, 2, rejection.Height)
+			require.Nil(t, txmp.txStore.GetTxByHash(mempool.TxKey(tTx.tx)))
+		} else {
+			require.False(t, ok, "tx %d should have been kept", i)
+			require.NotNil(t, txmp.txStore.GetTxByHash(mempool.TxKey(tTx.tx)))
+		}
+	}
+
+	// the recheck is only forced for the update following the change
+	app.rejected[string(tTxs[3].tx)] = true
+	update(3, false)
+	require.Equal(t, 7, txmp.Size())
+
+	// and isn't forced at all without the option
+	txmp.config.RecheckOnValidatorSetChange = false
+	update(4, true)
+	require.Equal(t, 7, txmp.Size())
+}
+
+func TestTxMempool_MinGasPrice(t *testing.T) {
+	txmp := setup(t, 100)
+	txmp.config.MinGasPrice = 15
+	txmp.rejections = mempool.NewRejectionCache(100)
+
+	// the test application wants 1 gas for each tx, and its priority is the
+	// fee, so the gas price of a tx is its priority
+	checkTx := func(tx types.Tx) *abci.ResponseCheckTx {
+		var res *abci.ResponseCheckTx
+		require.NoError(t, txmp.CheckTx(context.Background(), tx, func(r *abci.Response) {
+			res = r.GetCheckTx()
+		}, mempool.TxInfo{SenderID: 1}))
+		require.NotNil(t, res)
+		return res
+	}
+
+	below := types.Tx("sender-0=aa=10")
+	res := checkTx(below)
+	require.Equal(t, mempool.CodeTypeGasPriceTooLow, res.Code)
+	require.Equal(t, mempool.Codespace, res.Codespace)
+	require.Nil(t, txmp.txStore.GetTxByHash(mempool.TxKey(below)))
+
+	rejection, ok := txmp.TxRejection(mempool.TxKey(below))
+	require.True(t, ok)
+	require.Equal(t, mempool.RejectedGasPrice, rejection.Reason)
+	require.Equal(t, mempool.CodeTypeGasPriceTooLow, rejection.Code)
+
+	for _, tx := range []types.Tx{types.Tx("sender-1=aa=15"), types.Tx("sender-2=aa=20")} {
+		res = checkTx(tx)
+		require.Equal(t, abci.CodeTypeOK, res.Code)
+		require.Empty(t, res.Codespace)
+		require.NotNil(t, txmp.txStore.GetTxByHash(mempool.TxKey(tx)))
+	}
+	require.Equal(t, 2, txmp.Size())
+
+	// txs that fail CheckTx keep the app's code
+	res = checkTx(types.Tx("bad"))
+	require.Equal(t, uint32(101), res.Code)
+	require.Empty(t, res.Codespace)
+}
+
+func TestTxMempool_Paused(t *testing.T) {
+	txmp := setup(t, 100)
+	txmp.rejections = mempool.NewRejectionCache(100)
+
+	checkTx := func(tx types.Tx) *abci.ResponseCheckTx {
+		var res *abci.ResponseCheckTx
+		require.NoError(t, txmp.CheckTx(context.Background(), tx, func(r *abci.Response) {
+			res = r.GetCheckTx()
+		}, mempool.TxInfo{SenderID: 1}))
+		require.NotNil(t, res)
+		return res
+	}
+
+	accepted := types.Tx("sender-0=aa=10")
+	require.Equal(t, abci.CodeTypeOK, checkTx(accepted).Code)
+
+	// while paused, new txs are rejected without reaching the app, and txs
+	// already in the mempool are unaffected
+	txmp.SetPaused(true)
+	require.True(t, txmp.Paused())
+	tx := types.Tx("sender-1=aa=20")
+	res := checkTx(tx)
+	require.Equal(t, mempool.CodeTypeUnavailable, res.Code)
+	require.Equal(t, mempool.Codespace, res.Codespace)
+	require.Nil(t, txmp.txStore.GetTxByHash(mempool.TxKey(tx)))
+	require.Equal(t, types.Txs{accepted}, txmp.ReapMaxTxs(-1))
+
+	rejection, ok := txmp.TxRejection(mempool.TxKey(tx))
+	require.True(t, ok)
+	require.Equal(t, mempool.RejectedPaused, rejection.Reason)
+	require.Equal(t, mempool.CodeTypeUnavailable, rejection.Code)
+
+	// once resumed, the rejected tx is accepted when submitted again
+	txmp.SetPaused(false)
+	require.False(t, txmp.Paused())
+	require.Equal(t, abci.CodeTypeOK, checkTx(tx).Code)
+	require.NotNil(t, txmp.txStore.GetTxByHash(mempool.TxKey(tx)))
+	require.Equal(t, 2, txmp.Size())
+}
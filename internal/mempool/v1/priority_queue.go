@@ -9,15 +9,57 @@ import (
 
 var _ heap.Interface = (*TxPriorityQueue)(nil)
 
+// TxComparator defines the order in which transactions are reaped from the
+// mempool. It returns true if the transaction a must be reaped before b, and
+// must be a strict weak ordering, i.e. it must return false for both a, b and
+// b, a if neither is to be reaped first.
+type TxComparator func(a, b *WrappedTx) bool
+
+// DefaultTxComparator orders transactions by decreasing priority, and
+// transactions of the same priority in the order the node first received them.
+func DefaultTxComparator(a, b *WrappedTx) bool {
+	// If there exists two transactions with the same priority, consider the one
+	// that we saw the earliest as the higher priority transaction.
+	if a.priority == b.priority {
+		return a.timestamp.Before(b.timestamp)
+	}
+
+	return a.priority > b.priority
+}
+
+// GasPriceTxComparator orders transactions by decreasing gas price, i.e.
+// priority per unit of gas wanted, and transactions of the same gas price in
+// the order the node first received them.
+func GasPriceTxComparator(a, b *WrappedTx) bool {
+	priceA, priceB := a.GasPrice(), b.GasPrice()
+	if priceA == priceB {
+		return a.timestamp.Before(b.timestamp)
+	}
+
+	return priceA > priceB
+}
+
 // TxPriorityQueue defines a thread-safe priority queue for valid transactions.
 type TxPriorityQueue struct {
-	mtx tmsync.RWMutex
-	txs []*WrappedTx
+	mtx  tmsync.RWMutex
+	txs  []*WrappedTx
+	less TxComparator
+
+	// shared is true once txs is handed out by view, in which case it's copied
+	// before the queue is next modified, see unshare
+	shared bool
 }
 
 func NewTxPriorityQueue() *TxPriorityQueue {
+	return NewTxPriorityQueueWithComparator(DefaultTxComparator)
+}
+
+// NewTxPriorityQueueWithComparator returns a priority queue which pops
+// transactions in the order defined by less.
+func NewTxPriorityQueueWithComparator(less TxComparator) *TxPriorityQueue {
 	pq := &TxPriorityQueue{
-		txs: make([]*WrappedTx, 0),
+		txs:  make([]*WrappedTx, 0),
+		less: less,
 	}
 
 	heap.Init(pq)
@@ -80,6 +122,7 @@ func (pq *TxPriorityQueue) NumTxs() int {
 func (pq *TxPriorityQueue) RemoveTx(tx *WrappedTx) {
 	pq.mtx.Lock()
 	defer pq.mtx.Unlock()
+	pq.unshare()
 
 	if tx.heapIndex < len(pq.txs) {
 		heap.Remove(pq, tx.heapIndex)
@@ -90,6 +133,7 @@ func (pq *TxPriorityQueue) RemoveTx(tx *WrappedTx) {
 func (pq *TxPriorityQueue) PushTx(tx *WrappedTx) {
 	pq.mtx.Lock()
 	defer pq.mtx.Unlock()
+	pq.unshare()
 
 	heap.Push(pq, tx)
 }
@@ -98,6 +142,7 @@ func (pq *TxPriorityQueue) PushTx(tx *WrappedTx) {
 func (pq *TxPriorityQueue) PopTx() *WrappedTx {
 	pq.mtx.Lock()
 	defer pq.mtx.Unlock()
+	pq.unshare()
 
 	x := heap.Pop(pq)
 	if x != nil {
@@ -107,6 +152,31 @@ func (pq *TxPriorityQueue) PopTx() *WrappedTx {
 	return nil
 }
 
+// view returns the heap of the queue as it is, which is never modified
+// afterwards: the queue copies its heap before it's next modified instead. This
+// lets iterators walk the heap without holding the queue's lock, at the cost of
+// copying the pointers to the transactions at most once per view, and only if
+// the queue changes meanwhile.
+func (pq *TxPriorityQueue) view() []*WrappedTx {
+	pq.mtx.Lock()
+	defer pq.mtx.Unlock()
+
+	pq.shared = true
+	return pq.txs
+}
+
+// unshare copies the heap of the queue if a view of it was handed out, so that
+// the view isn't modified. The caller must hold the write lock.
+func (pq *TxPriorityQueue) unshare() {
+	if !pq.shared {
+		return
+	}
+	txs := make([]*WrappedTx, len(pq.txs), cap(pq.txs))
+	copy(txs, pq.txs)
+	pq.txs = txs
+	pq.shared = false
+}
+
 // Push implements the Heap interface.
 //
 // NOTE: A caller should never call Push. Use PushTx instead.
@@ -138,17 +208,10 @@ func (pq *TxPriorityQueue) Len() int {
 }
 
 // Less implements the Heap interface. It returns true if the transaction at
-// position i in the queue is of less priority than the transaction at position j.
+// position i in the queue is to be popped before the transaction at position j,
+// as defined by the queue's comparator.
 func (pq *TxPriorityQueue) Less(i, j int) bool {
-	// If there exists two transactions with the same priority, consider the one
-	// that we saw the earliest as the higher priority transaction.
-	if pq.txs[i].priority == pq.txs[j].priority {
-		return pq.txs[i].timestamp.Before(pq.txs[j].timestamp)
-	}
-
-	// We want Pop to give us the highest, not lowest, priority so we use greater
-	// than here.
-	return pq.txs[i].priority > pq.txs[j].priority
+	return pq.less(pq.txs[i], pq.txs[j])
 }
 
 // Swap implements the Heap interface. It swaps two transactions in the queue.
@@ -157,3 +220,46 @@ func (pq *TxPriorityQueue) Swap(i, j int) {
 	pq.txs[i].heapIndex = i
 	pq.txs[j].heapIndex = j
 }
+
+// txIndexHeap is a heap of indexes into a view of a priority queue's heap,
+// ordered by the queue's comparator. It lets an iterator walk the view in
+// order without popping from it, by only holding the transactions it may yield
+// next.
+type txIndexHeap struct {
+	txs     []*WrappedTx
+	less    TxComparator
+	indexes []int
+}
+
+var _ heap.Interface = (*txIndexHeap)(nil)
+
+func (h *txIndexHeap) Len() int { return len(h.indexes) }
+func (h *txIndexHeap) Less(i, j int) bool {
+	return h.less(h.txs[h.indexes[i]], h.txs[h.indexes[j]])
+}
+func (h *txIndexHeap) Swap(i, j int) { h.indexes[i], h.indexes[j] = h.indexes[j], h.indexes[i] }
+
+func (h *txIndexHeap) Push(x interface{}) {
+	h.indexes = append(h.indexes, x.(int))
+}
+
+func (h *txIndexHeap) Pop() interface{} {
+	old := h.indexes
+	n := len(old)
+	item := old[n-1]
+	h.indexes = old[0 : n-1]
+	return item
+}
+
+// pop removes the next transaction in the queue's order from the heap, and
+// pushes its children in the view, which are the only transactions that can be
+// next after it.
+func (h *txIndexHeap) pop() *WrappedTx {
+	index := heap.Pop(h).(int)
+	for _, child := range []int{2*index + 1, 2*index + 2} {
+		if child < len(h.txs) {
+			heap.Push(h, child)
+		}
+	}
+	return h.txs[index]
+}
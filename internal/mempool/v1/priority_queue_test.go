@@ -174,3 +174,28 @@ func TestTxPriorityQueue_RemoveTx(t *testing.T) {
 	})
 	require.Equal(t, numTxs-2, pq.NumTxs())
 }
+
+func TestTxPriorityQueue_View(t *testing.T) {
+	pq := NewTxPriorityQueue()
+	for i := 0; i < 10; i++ {
+		pq.PushTx(&WrappedTx{priority: int64(i)})
+	}
+
+	// the view is left as it was as the queue changes
+	view := pq.view()
+	expect := make([]*WrappedTx, len(view))
+	copy(expect, view)
+	pq.PushTx(&WrappedTx{priority: 100})
+	pq.RemoveTx(pq.txs[5])
+	require.Equal(t, int64(100), pq.PopTx().priority)
+	require.Equal(t, expect, view)
+	require.Equal(t, 9, pq.NumTxs())
+
+	// the queue still orders its transactions, and only copies its heap once
+	// per view
+	txs := pq.txs
+	pq.PushTx(&WrappedTx{priority: 50})
+	require.Equal(t, &txs[0], &pq.txs[0])
+	require.Equal(t, int64(50), pq.PopTx().priority)
+	require.Equal(t, int64(9), pq.PopTx().priority)
+}
@@ -35,9 +35,10 @@ type PeerManager interface {
 type Reactor struct {
 	service.BaseService
 
-	config  *cfg.MempoolConfig
-	mempool *TxMempool
-	ids     *mempool.MempoolIDs
+	config      *cfg.MempoolConfig
+	gossipCache *mempool.GossipCache
+	mempool     *TxMempool
+	ids         *mempool.MempoolIDs
 
 	// XXX: Currently, this is the only way to get information about a peer. Ideally,
 	// we rely on message-oriented communication to get necessary peer data.
@@ -68,6 +69,7 @@ func NewReactor(
 
 	r := &Reactor{
 		config:       config,
+		gossipCache:  mempool.NewGossipCache(config.GossipCacheSize, config.GossipCacheTTL),
 		peerMgr:      peerMgr,
 		mempool:      txmp,
 		ids:          mempool.NewMempoolIDs(),
@@ -168,7 +170,18 @@ func (r *Reactor) handleMempoolMessage(envelope p2p.Envelope) error {
 		}
 
 		for _, tx := range protoTxs {
+			// txs recently received from any peer have already been checked,
+			// but the sender is recorded so the tx isn't gossiped back to it
+			// once the cache forgets it
+			key := mempool.TxKey(tx)
+			if !r.gossipCache.Push(key, envelope.From) {
+				r.mempool.txStore.GetOrSetPeerByTxHash(key, txInfo.SenderID)
+				continue
+			}
+
 			if err := r.mempool.CheckTx(context.Background(), types.Tx(tx), nil, txInfo); err != nil {
+				// the tx may be accepted later on, e.g. when the mempool is full
+				r.gossipCache.Remove(key)
 				logger.Error("checktx failed for tx", "tx", fmt.Sprintf("%X", mempool.TxHashFromBytes(tx)), "err", err)
 			}
 		}
@@ -353,7 +366,7 @@ func (r *Reactor) broadcastTxRoutine(peerID p2p.NodeID, closer *tmsync.Closer) {
 
 		// NOTE: Transaction batching was disabled due to:
 		// https://github.com/tendermint/tendermint/issues/5796
-		if ok := r.mempool.txStore.TxHasPeer(memTx.hash, peerMempoolID); !ok {
+		if !r.mempool.txStore.TxHasPeer(memTx.hash, peerMempoolID) && !r.gossipCache.HasPeer(memTx.hash, peerID) {
 			// Send the mempool tx to the corresponding peer. Note, the peer may be
 			// behind and thus would not be able to process the mempool tx correctly.
 			r.mempoolCh.Out <- p2p.Envelope{
@@ -3,6 +3,7 @@ package v1
 import (
 	"time"
 
+	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/internal/libs/clist"
 	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
 	"github.com/tendermint/tendermint/internal/mempool"
@@ -57,6 +58,38 @@ func (wtx *WrappedTx) Size() int {
 	return len(wtx.tx)
 }
 
+// Tx returns the raw transaction.
+func (wtx *WrappedTx) Tx() types.Tx {
+	return wtx.tx
+}
+
+// Priority returns the transaction's priority as specified by the application.
+func (wtx *WrappedTx) Priority() int64 {
+	return wtx.priority
+}
+
+// GasWanted returns the amount of gas the transaction's sender requires.
+func (wtx *WrappedTx) GasWanted() int64 {
+	return wtx.gasWanted
+}
+
+// GasPrice returns the transaction's gas price, as derived by mempool.GasPrice
+// from its priority and the gas it wants.
+func (wtx *WrappedTx) GasPrice() float64 {
+	return mempool.GasPrice(&abci.ResponseCheckTx{Priority: wtx.priority, GasWanted: wtx.gasWanted})
+}
+
+// Sender returns the transaction's sender as specified by the application, if
+// any.
+func (wtx *WrappedTx) Sender() string {
+	return wtx.sender
+}
+
+// Timestamp returns the time at which the node first received the transaction.
+func (wtx *WrappedTx) Timestamp() time.Time {
+	return wtx.timestamp
+}
+
 // TxStore implements a thread-safe mapping of valid transaction(s).
 //
 // NOTE:
@@ -0,0 +1,120 @@
+package p2p
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-kit/kit/metrics"
+)
+
+// Bandwidth is the number of bytes sent to and received from a peer on a
+// channel, as they went over the wire (i.e. after compression).
+type Bandwidth struct {
+	SentBytes     uint64
+	ReceivedBytes uint64
+}
+
+// bandwidthCounter accumulates the bytes exchanged with a peer on a channel.
+// Its fields must be accessed atomically.
+type bandwidthCounter struct {
+	sent     uint64
+	received uint64
+}
+
+// peerBandwidth holds a peer's bandwidth counters, per channel. A counter is
+// never replaced once created, so the send and receive routines cache them
+// and only take the lock the first time they see a channel.
+type peerBandwidth struct {
+	mtx      sync.RWMutex
+	channels map[ChannelID]*bandwidthCounter
+}
+
+func newPeerBandwidth() *peerBandwidth {
+	return &peerBandwidth{channels: map[ChannelID]*bandwidthCounter{}}
+}
+
+// counter returns the counter for a channel, creating it if needed.
+func (b *peerBandwidth) counter(chID ChannelID) *bandwidthCounter {
+	b.mtx.RLock()
+	c, ok := b.channels[chID]
+	b.mtx.RUnlock()
+	if ok {
+		return c
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if c, ok = b.channels[chID]; !ok {
+		c = &bandwidthCounter{}
+		b.channels[chID] = c
+	}
+	return c
+}
+
+// bandwidth returns a snapshot of the counters.
+func (b *peerBandwidth) bandwidth() map[ChannelID]Bandwidth {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
+
+	bandwidth := make(map[ChannelID]Bandwidth, len(b.channels))
+	for chID, c := range b.channels {
+		bandwidth[chID] = Bandwidth{
+			SentBytes:     atomic.LoadUint64(&c.sent),
+			ReceivedBytes: atomic.LoadUint64(&c.received),
+		}
+	}
+	return bandwidth
+}
+
+// bandwidthCache caches a peer's counters, along with the corresponding
+// metrics, for use by a single routine.
+type bandwidthCache struct {
+	peerID    NodeID
+	bandwidth *peerBandwidth
+	metrics   *Metrics
+	channels  map[ChannelID]*bandwidthCacheEntry
+}
+
+type bandwidthCacheEntry struct {
+	counter  *bandwidthCounter
+	sent     metrics.Counter
+	received metrics.Counter
+}
+
+func newBandwidthCache(peerID NodeID, bandwidth *peerBandwidth, m *Metrics) *bandwidthCache {
+	return &bandwidthCache{
+		peerID:    peerID,
+		bandwidth: bandwidth,
+		metrics:   m,
+		channels:  map[ChannelID]*bandwidthCacheEntry{},
+	}
+}
+
+func (c *bandwidthCache) channel(chID ChannelID) *bandwidthCacheEntry {
+	entry, ok := c.channels[chID]
+	if !ok {
+		labels := []string{"peer_id", string(c.peerID), "ch_id", fmt.Sprint(chID)}
+		entry = &bandwidthCacheEntry{
+			counter:  c.bandwidth.counter(chID),
+			sent:     c.metrics.RouterPeerSendBytesTotal.With(labels...),
+			received: c.metrics.RouterPeerReceiveBytesTotal.With(labels...),
+		}
+		c.channels[chID] = entry
+	}
+	return entry
+}
+
+// addSent counts bytes sent on a channel.
+func (c *bandwidthCache) addSent(chID ChannelID, bytes int) {
+	entry := c.channel(chID)
+	atomic.AddUint64(&entry.counter.sent, uint64(bytes))
+	entry.sent.Add(float64(bytes))
+}
+
+// addReceived counts bytes received on a channel.
+func (c *bandwidthCache) addReceived(chID ChannelID, bytes int) {
+	entry := c.channel(chID)
+	atomic.AddUint64(&entry.counter.received, uint64(bytes))
+	entry.received.Add(float64(bytes))
+}
@@ -51,6 +51,28 @@ const (
 type receiveCbFunc func(chID byte, msgBytes []byte)
 type errorCbFunc func(interface{})
 
+// ErrDisconnected is passed to the onError callback when the peer closed the
+// connection with a PacketDisconnect, giving the reason for it.
+type ErrDisconnected struct {
+	Reason tmp2p.DisconnectReason
+}
+
+func (e ErrDisconnected) Error() string {
+	return fmt.Sprintf("peer disconnected: %v", e.Reason)
+}
+
+// ErrRecvMessageTooLarge is passed to the onError callback when the peer sent
+// a message exceeding the RecvMessageCapacity of its channel.
+type ErrRecvMessageTooLarge struct {
+	ChannelID byte
+	Size      int // size of the message received so far
+	Capacity  int
+}
+
+func (e ErrRecvMessageTooLarge) Error() string {
+	return fmt.Sprintf("received message exceeds available capacity: %v < %v", e.Capacity, e.Size)
+}
+
 /*
 Each peer has one `MConnection` (multiplex connection) instance.
 
@@ -270,6 +292,17 @@ func (c *MConnection) stopServices() (alreadyStopped bool) {
 // .Send() calls will get flushed before closing
 // the connection.
 func (c *MConnection) FlushStop() {
+	c.flushStop(nil)
+}
+
+// FlushStopWithReason is like FlushStop, but once the pending msgs are
+// flushed it also tells the peer why the connection is being closed, by
+// sending it a PacketDisconnect.
+func (c *MConnection) FlushStopWithReason(reason tmp2p.DisconnectReason) {
+	c.flushStop(&tmp2p.PacketDisconnect{Reason: reason})
+}
+
+func (c *MConnection) flushStop(disconnect *tmp2p.PacketDisconnect) {
 	if c.stopServices() {
 		return
 	}
@@ -287,6 +320,12 @@ func (c *MConnection) FlushStop() {
 		for !eof {
 			eof = c.sendSomePacketMsgs()
 		}
+		if disconnect != nil {
+			_, err := protoio.NewDelimitedWriter(c.bufConnWriter).WriteMsg(mustWrapPacket(disconnect))
+			if err != nil {
+				c.Logger.Error("Failed to send PacketDisconnect", "err", err)
+			}
+		}
 		c.flush()
 
 		// Now we can close the connection
@@ -624,6 +663,10 @@ FOR_LOOP:
 			default:
 				// never block
 			}
+		case *tmp2p.Packet_PacketDisconnect:
+			c.Logger.Debug("Receive Disconnect", "reason", pkt.PacketDisconnect.Reason)
+			c.stopForError(ErrDisconnected{Reason: pkt.PacketDisconnect.Reason})
+			break FOR_LOOP
 		case *tmp2p.Packet_PacketMsg:
 			channelID := byte(pkt.PacketMsg.ChannelID)
 			channel, ok := c.channelsIdx[channelID]
@@ -731,8 +774,14 @@ type ChannelDescriptor struct {
 	RecvBufferCapacity int
 
 	// MaxSendBytes defines the maximum number of bytes that can be sent at any
-	// given moment from a Channel to a peer.
+	// given moment from a Channel to a peer. For compressed channels, this
+	// applies to the compressed size of messages.
 	MaxSendBytes uint
+
+	// Compressed enables snappy compression of the messages sent on the
+	// Channel. Messages are only compressed if the peer advertises that it
+	// also compresses the Channel, otherwise they are sent as is.
+	Compressed bool
 }
 
 func (chDesc ChannelDescriptor) FillDefaults() (filled ChannelDescriptor) {
@@ -866,7 +915,7 @@ func (ch *Channel) recvPacketMsg(packet tmp2p.PacketMsg) ([]byte, error) {
 	ch.Logger.Debug("Read PacketMsg", "conn", ch.conn, "packet", packet)
 	var recvCap, recvReceived = ch.desc.RecvMessageCapacity, len(ch.recving) + len(packet.Data)
 	if recvCap < recvReceived {
-		return nil, fmt.Errorf("received message exceeds available capacity: %v < %v", recvCap, recvReceived)
+		return nil, ErrRecvMessageTooLarge{ChannelID: ch.desc.ID, Size: recvReceived, Capacity: recvCap}
 	}
 	ch.recving = append(ch.recving, packet.Data...)
 	if packet.EOF {
@@ -913,6 +962,12 @@ func mustWrapPacket(pb proto.Message) *tmp2p.Packet {
 				PacketMsg: pb,
 			},
 		}
+	case *tmp2p.PacketDisconnect:
+		msg = tmp2p.Packet{
+			Sum: &tmp2p.Packet_PacketDisconnect{
+				PacketDisconnect: pb,
+			},
+		}
 	default:
 		panic(fmt.Errorf("unknown packet type %T", pb))
 	}
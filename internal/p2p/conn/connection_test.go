@@ -145,6 +145,48 @@ func TestMConnectionReceive(t *testing.T) {
 	}
 }
 
+func TestMConnectionFlushStopWithReason(t *testing.T) {
+	server, client := NetPipe()
+	t.Cleanup(closeAll(t, client, server))
+
+	receivedCh := make(chan []byte, 1)
+	errorsCh := make(chan interface{}, 1)
+	onReceive := func(chID byte, msgBytes []byte) {
+		receivedCh <- msgBytes
+	}
+	onError := func(r interface{}) {
+		errorsCh <- r
+	}
+	mconn1 := createMConnectionWithCallbacks(client, onReceive, onError)
+	err := mconn1.Start()
+	require.Nil(t, err)
+	t.Cleanup(stopAll(t, mconn1))
+
+	mconn2 := createTestMConnection(server)
+	err = mconn2.Start()
+	require.Nil(t, err)
+
+	// pending msgs are flushed before the reason is sent
+	msg := []byte("Cyclops")
+	assert.True(t, mconn2.Send(0x01, msg))
+	mconn2.FlushStopWithReason(tmp2p.DisconnectReason_DISCONNECT_REASON_EVICTED)
+
+	select {
+	case receivedBytes := <-receivedCh:
+		assert.Equal(t, msg, receivedBytes)
+	case <-time.After(500 * time.Millisecond):
+		t.Fatalf("Did not receive %s message in 500ms", msg)
+	}
+
+	select {
+	case err := <-errorsCh:
+		assert.Equal(t, ErrDisconnected{Reason: tmp2p.DisconnectReason_DISCONNECT_REASON_EVICTED}, err)
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("Did not receive the disconnect reason in 500ms")
+	}
+	assert.False(t, mconn1.IsRunning())
+}
+
 func TestMConnectionStatus(t *testing.T) {
 	server, client := NetPipe()
 	t.Cleanup(closeAll(t, client, server))
@@ -0,0 +1,69 @@
+package p2p
+
+import (
+	p2pproto "github.com/tendermint/tendermint/proto/tendermint/p2p"
+)
+
+// DisconnectReason is the reason a peer gives for closing a connection, which
+// lets the other side tell a deliberate disconnect from a network failure.
+type DisconnectReason int32
+
+const (
+	// DisconnectReasonUnknown is given when no reason is known, e.g. because
+	// the peer closed the connection without giving one.
+	DisconnectReasonUnknown DisconnectReason = iota
+	// DisconnectReasonEvicted is given when the peer is evicted to stay within
+	// the maximum number of connected peers, e.g. to upgrade to a
+	// better-scored one.
+	DisconnectReasonEvicted
+	// DisconnectReasonIncompatible is given when the peer's node info isn't
+	// compatible with ours.
+	DisconnectReasonIncompatible
+	// DisconnectReasonMisbehaving is given when the peer is evicted because a
+	// reactor reported an error for it.
+	DisconnectReasonMisbehaving
+	// DisconnectReasonShuttingDown is given when the node is shutting down.
+	DisconnectReasonShuttingDown
+)
+
+// String implements fmt.Stringer.
+func (r DisconnectReason) String() string {
+	switch r {
+	case DisconnectReasonEvicted:
+		return "evicted-over-capacity"
+	case DisconnectReasonIncompatible:
+		return "incompatible"
+	case DisconnectReasonMisbehaving:
+		return "misbehaving"
+	case DisconnectReasonShuttingDown:
+		return "shutting-down"
+	default:
+		return "unknown"
+	}
+}
+
+// backOff returns true if a peer disconnecting us for this reason doesn't
+// want us to connect again right away. A peer shutting down will be back once
+// it's restarted, so there is no point in waiting any longer than usual.
+func (r DisconnectReason) backOff() bool {
+	switch r {
+	case DisconnectReasonEvicted, DisconnectReasonIncompatible, DisconnectReasonMisbehaving:
+		return true
+	default:
+		return false
+	}
+}
+
+// ToProto converts the reason to its Protobuf representation.
+func (r DisconnectReason) ToProto() p2pproto.DisconnectReason {
+	return p2pproto.DisconnectReason(r)
+}
+
+// DisconnectReasonFromProto converts a Protobuf disconnect reason. Reasons
+// unknown to us are converted to DisconnectReasonUnknown.
+func DisconnectReasonFromProto(r p2pproto.DisconnectReason) DisconnectReason {
+	if _, ok := p2pproto.DisconnectReason_name[int32(r)]; !ok {
+		return DisconnectReasonUnknown
+	}
+	return DisconnectReason(r)
+}
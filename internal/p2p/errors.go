@@ -3,6 +3,7 @@ package p2p
 import (
 	"fmt"
 	"net"
+	"time"
 )
 
 // ErrFilterTimeout indicates that a filter operation timed out.
@@ -12,6 +13,76 @@ func (e ErrFilterTimeout) Error() string {
 	return "filter timed out"
 }
 
+// ErrDisconnected indicates that a peer closed the connection, giving the
+// reason for it.
+type ErrDisconnected struct {
+	Reason DisconnectReason
+}
+
+func (e ErrDisconnected) Error() string {
+	return fmt.Sprintf("peer disconnected: %v", e.Reason)
+}
+
+// ErrMessageTooLarge indicates that a peer sent a message exceeding the
+// RecvMessageCapacity of its channel, either as received or once
+// decompressed.
+type ErrMessageTooLarge struct {
+	ChannelID ChannelID
+	// size of the message received so far, or its full size once decompressed
+	Size     int
+	Capacity int
+}
+
+func (e ErrMessageTooLarge) Error() string {
+	return fmt.Sprintf("message on channel %v exceeds receive capacity: %v < %v", e.ChannelID, e.Capacity, e.Size)
+}
+
+// ErrPeerClockOffset indicates that a peer was rejected because its clock was
+// too far from ours when handshaking.
+type ErrPeerClockOffset struct {
+	Offset    time.Duration // how far ahead of ours the peer's clock was
+	MaxOffset time.Duration
+}
+
+func (e ErrPeerClockOffset) Error() string {
+	return fmt.Sprintf("peer clock is %v away from ours, more than the maximum of %v", e.Offset, e.MaxOffset)
+}
+
+// ErrPeerIncompatible indicates that a peer was rejected because its node
+// info isn't compatible with ours, e.g. because it is on another network or
+// block version.
+type ErrPeerIncompatible struct {
+	Err error
+}
+
+func (e ErrPeerIncompatible) Error() string {
+	return fmt.Sprintf("incompatible peer: %v", e.Err)
+}
+
+func (e ErrPeerIncompatible) Unwrap() error {
+	return e.Err
+}
+
+// ErrPeerNotConnected indicates that a message couldn't be sent to a peer
+// because it isn't connected.
+type ErrPeerNotConnected struct {
+	PeerID NodeID
+}
+
+func (e ErrPeerNotConnected) Error() string {
+	return fmt.Sprintf("peer %v is not connected", e.PeerID)
+}
+
+// ErrPeerQueueFull indicates that a message couldn't be sent to a peer because
+// its send queue was full.
+type ErrPeerQueueFull struct {
+	PeerID NodeID
+}
+
+func (e ErrPeerQueueFull) Error() string {
+	return fmt.Sprintf("send queue of peer %v is full", e.PeerID)
+}
+
 // ErrRejected indicates that a Peer was rejected carrying additional
 // information as to the reason.
 type ErrRejected struct {
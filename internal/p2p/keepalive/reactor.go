@@ -0,0 +1,292 @@
+package keepalive
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/internal/p2p/conn"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/libs/service"
+	protop2p "github.com/tendermint/tendermint/proto/tendermint/p2p"
+)
+
+var (
+	_ service.Service = (*Reactor)(nil)
+	_ p2p.Wrapper     = (*protop2p.Packet)(nil)
+)
+
+const (
+	// KeepaliveChannel is the channel pings and pongs are exchanged on.
+	KeepaliveChannel = p2p.ChannelID(0x01)
+
+	// a wrapped ping or pong takes up 2 bytes
+	maxMsgSize = 16
+)
+
+// ChannelDescriptor returns the descriptor of the keepalive channel, which has
+// a low priority as its messages aren't urgent: a pong that is late because
+// the connection is busy with other messages still shows it's alive.
+func ChannelDescriptor() conn.ChannelDescriptor {
+	return conn.ChannelDescriptor{
+		ID:                  byte(KeepaliveChannel),
+		Priority:            1,
+		SendQueueCapacity:   10,
+		RecvMessageCapacity: maxMsgSize,
+	}
+}
+
+// Reactor detects dead peers at the application level. It pings every
+// connected peer at a fixed interval and reports a peer that misses a number
+// of consecutive pongs, by failing to respond to a ping within the timeout,
+// as errored, which disconnects it. The peer manager is then free to redial
+// the peer. The reactor also answers the peers' pings.
+//
+// This isn't redundant with the MConnection's own pings: those are answered by
+// the connection's receive routine, so they only show that the connection is
+// alive, and a single late pong drops it. Pings on the keepalive channel go
+// through the peer's router to its keepalive reactor and back, so they also
+// catch a peer whose router has stopped routing messages while its connection
+// stays up, tolerate occasional late pongs on a busy connection, and work
+// over transports without pings of their own, like the memory transport.
+//
+// Only peers that advertise the keepalive channel in their NodeInfo are
+// pinged, as a peer that doesn't know the channel closes the connection when
+// it receives a message on it.
+type Reactor struct {
+	service.BaseService
+
+	keepaliveCh *p2p.Channel
+	peerUpdates *p2p.PeerUpdates
+	closeCh     chan struct{}
+
+	interval  time.Duration
+	timeout   time.Duration
+	maxMissed uint
+
+	mtx sync.Mutex
+	// peers maps connected peers to the channel on which their pongs are
+	// passed to the goroutine pinging them, until they disconnect
+	peers map[p2p.NodeID]chan struct{}
+	// peersDone is closed when a peer disconnects, to stop pinging it
+	peersDone map[p2p.NodeID]chan struct{}
+	// pingers tracks the goroutines pinging peers, which must have returned
+	// before the channel is closed
+	pingers sync.WaitGroup
+}
+
+// NewReactor returns a reference to a new keepalive reactor, which pings peers
+// every interval and disconnects those that missed maxMissed pongs in a row.
+func NewReactor(
+	logger log.Logger,
+	keepaliveCh *p2p.Channel,
+	peerUpdates *p2p.PeerUpdates,
+	interval, timeout time.Duration,
+	maxMissed uint,
+) *Reactor {
+	r := &Reactor{
+		keepaliveCh: keepaliveCh,
+		peerUpdates: peerUpdates,
+		closeCh:     make(chan struct{}),
+		interval:    interval,
+		timeout:     timeout,
+		maxMissed:   maxMissed,
+		peers:       make(map[p2p.NodeID]chan struct{}),
+		peersDone:   make(map[p2p.NodeID]chan struct{}),
+	}
+
+	r.BaseService = *service.NewBaseService(logger, "Keepalive", r)
+	return r
+}
+
+// OnStart starts separate go routines for the keepalive channel and the peer
+// updates.
+func (r *Reactor) OnStart() error {
+	go r.processKeepaliveCh()
+	go r.processPeerUpdates()
+	return nil
+}
+
+// OnStop stops the reactor by signaling to all spawned goroutines to exit and
+// blocking until they all exit.
+func (r *Reactor) OnStop() {
+	close(r.closeCh)
+
+	<-r.keepaliveCh.Done()
+	<-r.peerUpdates.Done()
+}
+
+// processKeepaliveCh implements a blocking event loop where we listen for p2p
+// Envelope messages from the keepaliveCh.
+func (r *Reactor) processKeepaliveCh() {
+	defer r.keepaliveCh.Close()
+
+	for {
+		select {
+		case envelope := <-r.keepaliveCh.In:
+			if err := r.handleMessage(envelope); err != nil {
+				r.Logger.Error("failed to process message", "ch_id", r.keepaliveCh.ID, "envelope", envelope, "err", err)
+				r.keepaliveCh.Error <- p2p.PeerError{
+					NodeID: envelope.From,
+					Err:    err,
+				}
+			}
+
+		case <-r.closeCh:
+			// the pingers send on the channel, so they must be done before
+			// it's closed
+			r.pingers.Wait()
+			r.Logger.Debug("stopped listening on keepalive channel; closing...")
+			return
+		}
+	}
+}
+
+// handleMessage answers a ping with a pong, and passes a pong on to the
+// goroutine pinging the peer.
+func (r *Reactor) handleMessage(envelope p2p.Envelope) error {
+	switch envelope.Message.(type) {
+	case *protop2p.PacketPing:
+		r.keepaliveCh.Out <- p2p.Envelope{
+			To:      envelope.From,
+			Message: &protop2p.PacketPong{},
+		}
+
+	case *protop2p.PacketPong:
+		r.mtx.Lock()
+		pongCh, ok := r.peers[envelope.From]
+		r.mtx.Unlock()
+		if ok {
+			// a pong that is already pending makes another one redundant
+			select {
+			case pongCh <- struct{}{}:
+			default:
+			}
+		}
+
+	default:
+		return fmt.Errorf("received unknown message: %T", envelope.Message)
+	}
+	return nil
+}
+
+// processPeerUpdates initiates a blocking process where we listen for and handle
+// PeerUpdate messages. When the reactor is stopped, we will catch the signal and
+// close the p2p PeerUpdatesCh gracefully.
+func (r *Reactor) processPeerUpdates() {
+	defer r.peerUpdates.Close()
+
+	for {
+		select {
+		case peerUpdate := <-r.peerUpdates.Updates():
+			r.processPeerUpdate(peerUpdate)
+
+		case <-r.closeCh:
+			r.Logger.Debug("stopped listening on peer updates channel; closing...")
+			return
+		}
+	}
+}
+
+// processPeerUpdate starts pinging a peer once it's up, if it advertises the
+// keepalive channel, and stops once it's down.
+func (r *Reactor) processPeerUpdate(peerUpdate p2p.PeerUpdate) {
+	r.Logger.Debug("received peer update", "peer", peerUpdate.NodeID, "status", peerUpdate.Status)
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	switch peerUpdate.Status {
+	case p2p.PeerStatusUp:
+		if _, ok := r.peers[peerUpdate.NodeID]; ok {
+			return
+		}
+		if !peerUpdate.Channels.Contains(KeepaliveChannel) {
+			r.Logger.Debug("peer doesn't advertise the keepalive channel; not pinging it",
+				"peer", peerUpdate.NodeID)
+			return
+		}
+		pongCh := make(chan struct{}, 1)
+		doneCh := make(chan struct{})
+		r.peers[peerUpdate.NodeID] = pongCh
+		r.peersDone[peerUpdate.NodeID] = doneCh
+
+		r.pingers.Add(1)
+		go func() {
+			defer r.pingers.Done()
+			r.pingPeer(peerUpdate.NodeID, pongCh, doneCh)
+		}()
+
+	case p2p.PeerStatusDown:
+		if doneCh, ok := r.peersDone[peerUpdate.NodeID]; ok {
+			close(doneCh)
+		}
+		delete(r.peers, peerUpdate.NodeID)
+		delete(r.peersDone, peerUpdate.NodeID)
+	}
+}
+
+// pingPeer pings a peer every interval, waiting up to the timeout for each
+// pong, until the peer disconnects or misses maxMissed pongs in a row, in
+// which case it's reported as errored.
+func (r *Reactor) pingPeer(peerID p2p.NodeID, pongCh <-chan struct{}, doneCh <-chan struct{}) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	var missed uint
+	for {
+		select {
+		case <-ticker.C:
+		case <-doneCh:
+			return
+		case <-r.closeCh:
+			return
+		}
+
+		// drop a pong that came in too late for the previous ping
+		select {
+		case <-pongCh:
+		default:
+		}
+
+		select {
+		case r.keepaliveCh.Out <- p2p.Envelope{To: peerID, Message: &protop2p.PacketPing{}}:
+		case <-doneCh:
+			return
+		case <-r.closeCh:
+			return
+		}
+
+		timer := time.NewTimer(r.timeout)
+		select {
+		case <-pongCh:
+			missed = 0
+			timer.Stop()
+			continue
+		case <-timer.C:
+		case <-doneCh:
+			timer.Stop()
+			return
+		case <-r.closeCh:
+			timer.Stop()
+			return
+		}
+
+		missed++
+		r.Logger.Debug("peer missed pong", "peer", peerID, "missed", missed)
+		if missed < r.maxMissed {
+			continue
+		}
+
+		select {
+		case r.keepaliveCh.Error <- p2p.PeerError{
+			NodeID: peerID,
+			Err:    fmt.Errorf("peer missed %d consecutive pongs", missed),
+		}:
+		case <-doneCh:
+		case <-r.closeCh:
+		}
+		return
+	}
+}
@@ -0,0 +1,160 @@
+package keepalive_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/internal/p2p/keepalive"
+	"github.com/tendermint/tendermint/libs/log"
+	proto "github.com/tendermint/tendermint/proto/tendermint/p2p"
+)
+
+// channels are those advertised by the peers that run the keepalive reactor
+var channels = p2p.NewChannelIDSet([]byte{byte(keepalive.KeepaliveChannel), 0x20})
+
+const (
+	pingInterval = 50 * time.Millisecond
+	pingTimeout  = 40 * time.Millisecond
+	maxMissed    = 3
+)
+
+type reactorTestSuite struct {
+	reactor *keepalive.Reactor
+	inCh    chan p2p.Envelope
+	outCh   chan p2p.Envelope
+	errCh   chan p2p.PeerError
+	peerCh  chan p2p.PeerUpdate
+}
+
+func setup(t *testing.T) *reactorTestSuite {
+	t.Helper()
+
+	rts := &reactorTestSuite{
+		inCh:   make(chan p2p.Envelope, 2),
+		outCh:  make(chan p2p.Envelope, 2),
+		errCh:  make(chan p2p.PeerError, 2),
+		peerCh: make(chan p2p.PeerUpdate, 2),
+	}
+	channel := p2p.NewChannel(keepalive.KeepaliveChannel, new(proto.Packet), rts.inCh, rts.outCh, rts.errCh)
+	peerUpdates := p2p.NewPeerUpdates(rts.peerCh, 2)
+
+	rts.reactor = keepalive.NewReactor(log.TestingLogger(), channel, peerUpdates,
+		pingInterval, pingTimeout, maxMissed)
+	require.NoError(t, rts.reactor.Start())
+	t.Cleanup(func() {
+		require.NoError(t, rts.reactor.Stop())
+	})
+
+	return rts
+}
+
+// requirePing waits for the reactor to ping the peer.
+func (rts *reactorTestSuite) requirePing(t *testing.T, peerID p2p.NodeID) {
+	t.Helper()
+	select {
+	case envelope := <-rts.outCh:
+		require.Equal(t, peerID, envelope.To)
+		require.Equal(t, &proto.PacketPing{}, envelope.Message)
+	case err := <-rts.errCh:
+		require.Fail(t, "unexpected peer error", err)
+	case <-time.After(time.Second):
+		require.Fail(t, "timed out waiting for ping")
+	}
+}
+
+func TestReactor_NoKeepaliveChannel(t *testing.T) {
+	rts := setup(t)
+	peerID := p2p.NodeID("00ff")
+
+	// a peer that doesn't know the keepalive channel would disconnect us on a
+	// ping, so it's never pinged
+	rts.peerCh <- p2p.PeerUpdate{
+		NodeID:   peerID,
+		Status:   p2p.PeerStatusUp,
+		Channels: p2p.NewChannelIDSet([]byte{0x20, 0x30}),
+	}
+	select {
+	case envelope := <-rts.outCh:
+		require.Fail(t, "unexpected message", envelope)
+	case peerErr := <-rts.errCh:
+		require.Fail(t, "unexpected peer error", peerErr)
+	case <-time.After((maxMissed + 2) * pingInterval):
+	}
+}
+
+func TestReactor_Pong(t *testing.T) {
+	rts := setup(t)
+	peerID := p2p.NodeID("00ff")
+
+	rts.inCh <- p2p.Envelope{From: peerID, Message: &proto.PacketPing{}}
+	select {
+	case envelope := <-rts.outCh:
+		require.Equal(t, p2p.Envelope{To: peerID, Message: &proto.PacketPong{}}, envelope)
+	case <-time.After(time.Second):
+		require.Fail(t, "timed out waiting for pong")
+	}
+}
+
+func TestReactor_MissedPongs(t *testing.T) {
+	rts := setup(t)
+	peerID := p2p.NodeID("00ff")
+	rts.peerCh <- p2p.PeerUpdate{NodeID: peerID, Status: p2p.PeerStatusUp, Channels: channels}
+
+	// a peer that keeps responding stays connected, even if it misses fewer
+	// pongs in a row than the threshold
+	for i := 0; i < 2*maxMissed; i++ {
+		rts.requirePing(t, peerID)
+		if i%maxMissed != 0 {
+			rts.inCh <- p2p.Envelope{From: peerID, Message: &proto.PacketPong{}}
+		}
+	}
+
+	// once the peer stops responding, it's reported after the threshold
+	pings := 0
+	for peerErr := (p2p.PeerError{}); peerErr.Err == nil; {
+		select {
+		case envelope := <-rts.outCh:
+			require.Equal(t, &proto.PacketPing{}, envelope.Message)
+			pings++
+		case peerErr = <-rts.errCh:
+			require.Equal(t, peerID, peerErr.NodeID)
+			require.Contains(t, peerErr.Err.Error(), "missed 3 consecutive pongs")
+		case <-time.After(time.Second):
+			require.Fail(t, "timed out waiting for peer error")
+		}
+	}
+	require.Equal(t, maxMissed, pings)
+
+	// the peer is no longer pinged, and isn't reported again
+	select {
+	case envelope := <-rts.outCh:
+		require.Fail(t, "unexpected message", envelope)
+	case peerErr := <-rts.errCh:
+		require.Fail(t, "unexpected peer error", peerErr)
+	case <-time.After(5 * pingInterval):
+	}
+}
+
+func TestReactor_PeerDown(t *testing.T) {
+	rts := setup(t)
+	peerID := p2p.NodeID("00ff")
+	rts.peerCh <- p2p.PeerUpdate{NodeID: peerID, Status: p2p.PeerStatusUp, Channels: channels}
+	rts.requirePing(t, peerID)
+
+	// a disconnected peer is no longer pinged, nor reported
+	rts.peerCh <- p2p.PeerUpdate{NodeID: peerID, Status: p2p.PeerStatusDown}
+	time.Sleep(pingInterval)
+	for len(rts.outCh) > 0 {
+		<-rts.outCh
+	}
+	select {
+	case envelope := <-rts.outCh:
+		require.Fail(t, "unexpected message", envelope)
+	case peerErr := <-rts.errCh:
+		require.Fail(t, "unexpected peer error", peerErr)
+	case <-time.After((maxMissed + 2) * pingInterval):
+	}
+}
@@ -23,6 +23,18 @@ type Metrics struct {
 	PeerSendBytesTotal metrics.Counter
 	// Pending bytes to be sent to a given peer.
 	PeerPendingSendBytes metrics.Gauge
+	// How far ahead of ours the clock of a given peer was when handshaking
+	// with it, in seconds.
+	PeerClockOffset metrics.Gauge
+
+	// RouterPeerSendBytesTotal defines the number of bytes the router sent to
+	// a given peer on a given p2p Channel, as they went over the wire.
+	RouterPeerSendBytesTotal metrics.Counter
+
+	// RouterPeerReceiveBytesTotal defines the number of bytes the router
+	// received from a given peer on a given p2p Channel, as they went over the
+	// wire.
+	RouterPeerReceiveBytesTotal metrics.Counter
 
 	// RouterPeerQueueRecv defines the time taken to read off of a peer's queue
 	// before sending on the connection.
@@ -43,6 +55,15 @@ type Metrics struct {
 	// PeerQueueMsgSize defines the average size of messages sent over a peer's
 	// queue for a specific flow (i.e. Channel).
 	PeerQueueMsgSize metrics.Gauge
+
+	// RouterChannelRecvMsgSize defines the size of the messages received on a
+	// given p2p Channel, as they went over the wire.
+	RouterChannelRecvMsgSize metrics.Histogram
+
+	// RouterChannelRecvMsgTooLarge defines the number of messages received on
+	// a given p2p Channel that were rejected for exceeding its
+	// RecvMessageCapacity, either as received or once decompressed.
+	RouterChannelRecvMsgTooLarge metrics.Counter
 }
 
 // PrometheusMetrics returns Metrics build using Prometheus client library.
@@ -83,6 +104,26 @@ func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 			Name:      "peer_pending_send_bytes",
 			Help:      "Number of pending bytes to be sent to a given peer.",
 		}, append(labels, "peer_id")).With(labelsAndValues...),
+		PeerClockOffset: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "peer_clock_offset_seconds",
+			Help:      "How far ahead of ours the clock of a given peer was when handshaking with it, negative if it was behind.",
+		}, append(labels, "peer_id")).With(labelsAndValues...),
+
+		RouterPeerSendBytesTotal: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "router_peer_send_bytes_total",
+			Help:      "The number of bytes sent to a given peer on a given p2p Channel, as they went over the wire.",
+		}, append(labels, "peer_id", "ch_id")).With(labelsAndValues...),
+
+		RouterPeerReceiveBytesTotal: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "router_peer_receive_bytes_total",
+			Help:      "The number of bytes received from a given peer on a given p2p Channel, as they went over the wire.",
+		}, append(labels, "peer_id", "ch_id")).With(labelsAndValues...),
 
 		RouterPeerQueueRecv: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
 			Namespace: namespace,
@@ -118,20 +159,41 @@ func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 			Name:      "router_channel_queue_msg_size",
 			Help:      "The size of messages sent over a peer's queue for a specific p2p Channel.",
 		}, append(labels, "ch_id")).With(labelsAndValues...),
+
+		RouterChannelRecvMsgSize: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "router_channel_recv_msg_size_bytes",
+			Help:      "The size in bytes of the messages received on a given p2p Channel, as they went over the wire.",
+			Buckets:   stdprometheus.ExponentialBuckets(64, 4, 10),
+		}, append(labels, "ch_id")).With(labelsAndValues...),
+
+		RouterChannelRecvMsgTooLarge: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "router_channel_recv_msg_too_large_total",
+			Help:      "The number of messages received on a given p2p Channel that exceeded its receive message capacity, either as received or once decompressed.",
+		}, append(labels, "ch_id")).With(labelsAndValues...),
 	}
 }
 
 // NopMetrics returns no-op Metrics.
 func NopMetrics() *Metrics {
 	return &Metrics{
-		Peers:                  discard.NewGauge(),
-		PeerReceiveBytesTotal:  discard.NewCounter(),
-		PeerSendBytesTotal:     discard.NewCounter(),
-		PeerPendingSendBytes:   discard.NewGauge(),
-		RouterPeerQueueRecv:    discard.NewHistogram(),
-		RouterPeerQueueSend:    discard.NewHistogram(),
-		RouterChannelQueueSend: discard.NewHistogram(),
-		PeerQueueDroppedMsgs:   discard.NewCounter(),
-		PeerQueueMsgSize:       discard.NewGauge(),
+		Peers:                       discard.NewGauge(),
+		PeerReceiveBytesTotal:       discard.NewCounter(),
+		PeerSendBytesTotal:          discard.NewCounter(),
+		PeerPendingSendBytes:        discard.NewGauge(),
+		PeerClockOffset:             discard.NewGauge(),
+		RouterPeerSendBytesTotal:    discard.NewCounter(),
+		RouterPeerReceiveBytesTotal: discard.NewCounter(),
+		RouterPeerQueueRecv:         discard.NewHistogram(),
+		RouterPeerQueueSend:         discard.NewHistogram(),
+		RouterChannelQueueSend:      discard.NewHistogram(),
+		PeerQueueDroppedMsgs:        discard.NewCounter(),
+		PeerQueueMsgSize:            discard.NewGauge(),
+
+		RouterChannelRecvMsgSize:     discard.NewHistogram(),
+		RouterChannelRecvMsgTooLarge: discard.NewCounter(),
 	}
 }
@@ -33,6 +33,20 @@ func (_m *Connection) Close() error {
 	return r0
 }
 
+// CloseWithReason provides a mock function with given fields: _a0
+func (_m *Connection) CloseWithReason(_a0 p2p.DisconnectReason) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(p2p.DisconnectReason) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // FlushClose provides a mock function with given fields:
 func (_m *Connection) FlushClose() error {
 	ret := _m.Called()
@@ -3,6 +3,7 @@ package p2p
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/tendermint/tendermint/libs/bytes"
 	tmstrings "github.com/tendermint/tendermint/libs/strings"
@@ -64,6 +65,14 @@ type NodeInfo struct {
 	// ASCIIText fields
 	Moniker string        `json:"moniker"` // arbitrary moniker
 	Other   NodeInfoOther `json:"other"`   // other application specific data
+
+	// Channels whose messages this node compresses, if the peer does as well.
+	CompressedChannels bytes.HexBytes `json:"compressed_channels"`
+
+	// The node's current time, which transports set when handshaking so that
+	// the peer can measure how far apart their clocks are. It is sent over
+	// the authenticated connection, and is zero for peers that don't send it.
+	Time time.Time `json:"-"`
 }
 
 // NodeInfoOther is the misc. applcation specific data
@@ -122,6 +131,19 @@ func (info NodeInfo) Validate() error {
 		channels[ch] = struct{}{}
 	}
 
+	// Validate CompressedChannels - ensure max and check for duplicates.
+	if len(info.CompressedChannels) > maxNumChannels {
+		return fmt.Errorf("info.CompressedChannels is too long (%v). Max is %v",
+			len(info.CompressedChannels), maxNumChannels)
+	}
+	compressed := make(map[byte]struct{})
+	for _, ch := range info.CompressedChannels {
+		if _, ok := compressed[ch]; ok {
+			return fmt.Errorf("info.CompressedChannels contains duplicate channel id %v", ch)
+		}
+		compressed[ch] = struct{}{}
+	}
+
 	// Validate Moniker.
 	if !tmstrings.IsASCIIText(info.Moniker) || tmstrings.ASCIITrim(info.Moniker) == "" {
 		return fmt.Errorf("info.Moniker must be valid non-empty ASCII text without tabs, but got %v", info.Moniker)
@@ -208,6 +230,8 @@ func (info NodeInfo) ToProto() *tmp2p.NodeInfo {
 		TxIndex:    info.Other.TxIndex,
 		RPCAddress: info.Other.RPCAddress,
 	}
+	dni.CompressedChannels = info.CompressedChannels
+	dni.Time = info.Time
 
 	return dni
 }
@@ -232,6 +256,8 @@ func NodeInfoFromProto(pb *tmp2p.NodeInfo) (NodeInfo, error) {
 			TxIndex:    pb.Other.TxIndex,
 			RPCAddress: pb.Other.RPCAddress,
 		},
+		CompressedChannels: pb.CompressedChannels,
+		Time:               pb.Time,
 	}
 
 	return dni, nil
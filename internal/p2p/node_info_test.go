@@ -36,6 +36,8 @@ func TestNodeInfoValidate(t *testing.T) {
 		},
 		{"Duplicate Channel", func(ni *NodeInfo) { ni.Channels = dupChannels }, true},
 		{"Good Channels", func(ni *NodeInfo) { ni.Channels = ni.Channels[:5] }, false},
+		{"Duplicate Compressed Channel", func(ni *NodeInfo) { ni.CompressedChannels = dupChannels }, true},
+		{"Good Compressed Channels", func(ni *NodeInfo) { ni.CompressedChannels = []byte{testCh} }, false},
 
 		{"Invalid NetAddress", func(ni *NodeInfo) { ni.ListenAddr = "not-an-address" }, true},
 		{"Good NetAddress", func(ni *NodeInfo) { ni.ListenAddr = "0.0.0.0:26656" }, false},
@@ -1,9 +1,11 @@
 package p2p
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"math"
 	"math/rand"
 	"sort"
@@ -14,6 +16,7 @@ import (
 	"github.com/google/orderedcode"
 	dbm "github.com/tendermint/tm-db"
 
+	"github.com/tendermint/tendermint/internal/libs/protoio"
 	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
 	p2pproto "github.com/tendermint/tendermint/proto/tendermint/p2p"
 )
@@ -42,12 +45,43 @@ type PeerScore uint8
 
 const (
 	PeerScorePersistent PeerScore = math.MaxUint8 // persistent peers
+
+	// importedPeerScorePenalty is subtracted from the score of peers added by
+	// ImportAddresses until one of their addresses is dialed successfully.
+	importedPeerScorePenalty = 10
 )
 
 // PeerUpdate is a peer update event sent via PeerUpdates.
 type PeerUpdate struct {
 	NodeID NodeID
 	Status PeerStatus
+
+	// Channels are the channels the peer advertised in its NodeInfo, set when
+	// it comes up. Reactors must not send on a channel the peer doesn't know,
+	// as it would disconnect us.
+	Channels ChannelIDSet
+}
+
+// ChannelIDSet is a set of channel IDs.
+type ChannelIDSet map[ChannelID]struct{}
+
+// NewChannelIDSet returns the set of the given channel IDs, as advertised in a
+// NodeInfo, or nil if there are none.
+func NewChannelIDSet(channels []byte) ChannelIDSet {
+	if len(channels) == 0 {
+		return nil
+	}
+	set := make(ChannelIDSet, len(channels))
+	for _, ch := range channels {
+		set[ChannelID(ch)] = struct{}{}
+	}
+	return set
+}
+
+// Contains returns whether the set contains the given channel ID.
+func (cs ChannelIDSet) Contains(ch ChannelID) bool {
+	_, ok := cs[ch]
+	return ok
 }
 
 // PeerUpdates is a peer update subscription with notifications about peer
@@ -104,7 +138,11 @@ type PeerManagerOptions struct {
 	// PersistentPeers are peers that we want to maintain persistent connections
 	// to. These will be scored higher than other peers, and if
 	// MaxConnectedUpgrade is non-zero any lower-scored peers will be evicted if
-	// necessary to make room for these.
+	// necessary to make room for these. They are never evicted to make room
+	// for other peers or to stay within MaxConnected, even if PeerScores
+	// scores them lower, and they are redialed after MinRetryTime rather than
+	// EvictedRetryTime if they evict us. They are still disconnected, and backed off, if they are
+	// incompatible or misbehave.
 	PersistentPeers []NodeID
 
 	// MaxPeers is the maximum number of peers to track information about, i.e.
@@ -145,6 +183,12 @@ type PeerManagerOptions struct {
 	// retry times, to avoid thundering herds. 0 disables jitter.
 	RetryTimeJitter time.Duration
 
+	// EvictedRetryTime is the time to wait before dialing a peer again after
+	// it disconnected us deliberately, i.e. because we were evicted, are
+	// incompatible or misbehaved, rather than redialing it right away only to
+	// be disconnected again. RetryTimeJitter is added to it. 0 disables it.
+	EvictedRetryTime time.Duration
+
 	// PeerScores sets fixed scores for specific peers. It is mainly used
 	// for testing. A score of 0 is ignored.
 	PeerScores map[NodeID]PeerScore
@@ -153,6 +197,23 @@ type PeerManagerOptions struct {
 	// consider private and never gossip.
 	PrivatePeers map[NodeID]struct{}
 
+	// AddressTTL is the maximum time an address can go without a successful
+	// dial before it is removed from the peer store, and thus no longer dialed
+	// or advertised. For addresses that were never successfully dialed, the
+	// window starts when the address is added. Addresses of persistent peers
+	// never expire. This is mostly useful for seed nodes, to keep the crawled
+	// address set fresh. 0 disables expiry.
+	AddressTTL time.Duration
+
+	// AdvertiseDialedOnly only advertises addresses that we have successfully
+	// dialed at least once, so that we don't spread addresses we've merely
+	// been told about, which may be bogus. Other addresses are still dialed.
+	AdvertiseDialedOnly bool
+
+	// Now returns the current time. It is mainly for testing, nil uses
+	// time.Now.
+	Now func() time.Time
+
 	// persistentPeers provides fast PersistentPeers lookups. It is built
 	// by optimize().
 	persistentPeers map[NodeID]bool
@@ -194,6 +255,14 @@ func (o *PeerManagerOptions) Validate() error {
 		}
 	}
 
+	if o.AddressTTL < 0 {
+		return fmt.Errorf("AddressTTL %v can't be negative", o.AddressTTL)
+	}
+
+	if o.EvictedRetryTime < 0 {
+		return fmt.Errorf("EvictedRetryTime %v can't be negative", o.EvictedRetryTime)
+	}
+
 	if o.MaxRetryTimePersistent > 0 {
 		if o.MinRetryTime == 0 {
 			return errors.New("can't set MaxRetryTimePersistent without MinRetryTime")
@@ -242,6 +311,10 @@ func (o *PeerManagerOptions) optimize() {
 // - Disconnected: report peer disconnect, unmark as connected and broadcasts
 //   PeerStatusDown.
 //
+// If the peer disconnects us giving a reason, RemoteDisconnected is reported
+// before Disconnected, and the peer isn't dialed again before
+// EvictedRetryTime if it doesn't want us back right away.
+//
 // For an inbound connection, the flow is as follows:
 // - Accepted: report inbound connection success, mark as connected (errors if
 //   already connected, e.g. by Dialed).
@@ -252,7 +325,8 @@ func (o *PeerManagerOptions) optimize() {
 // When evicting peers, either because peers are explicitly scheduled for
 // eviction or we are connected to too many peers, the flow is as follows:
 // - EvictNext: if marked evict and connected, unmark evict and mark evicting.
-//   If beyond MaxConnected, pick lowest-scored peer and mark evicting.
+//   If beyond MaxConnected, pick lowest-scored peer and mark evicting. The
+//   reason for the eviction is returned to the router along with the peer.
 // - Disconnected: unmark connected, evicting, evict, and broadcast a
 //   PeerStatusDown peer update.
 //
@@ -277,6 +351,7 @@ type PeerManager struct {
 	evictWaker *tmsync.Waker // wakes up EvictNext() on relevant peer changes
 	closeCh    chan struct{} // signal channel for Close()
 	closeOnce  sync.Once
+	createdAt  time.Time // reference time for AddressTTL of loaded addresses
 
 	mtx           sync.Mutex
 	store         *peerStore
@@ -285,8 +360,16 @@ type PeerManager struct {
 	upgrading     map[NodeID]NodeID             // peers claimed for upgrade (DialNext → Dialed/DialFail)
 	connected     map[NodeID]bool               // connected peers (Dialed/Accepted → Disconnected)
 	ready         map[NodeID]bool               // ready peers (Ready → Disconnected)
-	evict         map[NodeID]bool               // peers scheduled for eviction (Connected → EvictNext)
+	evict         map[NodeID]DisconnectReason   // peers scheduled for eviction (Connected → EvictNext)
 	evicting      map[NodeID]bool               // peers being evicted (EvictNext → Disconnected)
+	dialResults   map[NodeID][]*dialWaiter      // redial waiters (Redial → Dialed/DialFailed)
+}
+
+// dialWaiter is a Redial() caller waiting for the result of the next dial of a
+// peer.
+type dialWaiter struct {
+	result chan error
+	done   chan struct{} // closed once the result is sent
 }
 
 // NewPeerManager creates a new peer manager.
@@ -299,6 +382,9 @@ func NewPeerManager(selfID NodeID, peerDB dbm.DB, options PeerManagerOptions) (*
 	}
 
 	options.optimize()
+	if options.Now == nil {
+		options.Now = time.Now
+	}
 
 	store, err := newPeerStore(peerDB)
 	if err != nil {
@@ -312,14 +398,16 @@ func NewPeerManager(selfID NodeID, peerDB dbm.DB, options PeerManagerOptions) (*
 		dialWaker:  tmsync.NewWaker(),
 		evictWaker: tmsync.NewWaker(),
 		closeCh:    make(chan struct{}),
+		createdAt:  options.Now().UTC(),
 
 		store:         store,
 		dialing:       map[NodeID]bool{},
 		upgrading:     map[NodeID]NodeID{},
 		connected:     map[NodeID]bool{},
 		ready:         map[NodeID]bool{},
-		evict:         map[NodeID]bool{},
+		evict:         map[NodeID]DisconnectReason{},
 		evicting:      map[NodeID]bool{},
+		dialResults:   map[NodeID][]*dialWaiter{},
 		subscriptions: map[*PeerUpdates]*PeerUpdates{},
 	}
 	if err = peerManager.configurePeers(); err != nil {
@@ -396,6 +484,52 @@ func (m *PeerManager) prunePeers() error {
 	return nil
 }
 
+// pruneExpiredAddresses removes addresses that haven't been successfully
+// dialed within AddressTTL, and deletes peers that have no addresses left
+// (unless they are currently dialing or connected). The caller must hold the
+// mutex lock.
+func (m *PeerManager) pruneExpiredAddresses() error {
+	if m.options.AddressTTL == 0 {
+		return nil
+	}
+
+	now := m.options.Now()
+	for _, peer := range m.store.List() {
+		if peer.Persistent {
+			continue
+		}
+
+		expired := false
+		for address, addressInfo := range peer.AddressInfo {
+			lastSeen := addressInfo.LastDialSuccess
+			if addressInfo.AddedAt.After(lastSeen) {
+				lastSeen = addressInfo.AddedAt
+			}
+			if lastSeen.IsZero() {
+				lastSeen = m.createdAt
+			}
+			if now.Sub(lastSeen) > m.options.AddressTTL {
+				delete(peer.AddressInfo, address)
+				expired = true
+			}
+		}
+		if !expired {
+			continue
+		}
+
+		var err error
+		if len(peer.AddressInfo) == 0 && !m.dialing[peer.ID] && !m.connected[peer.ID] {
+			err = m.store.Delete(peer.ID)
+		} else {
+			err = m.store.Set(peer)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Add adds a peer to the manager, given as an address. If the peer already
 // exists, the address is added to it if it isn't already present. This will push
 // low scoring peers out of the address book if it exceeds the maximum size.
@@ -421,7 +555,7 @@ func (m *PeerManager) Add(address NodeAddress) (bool, error) {
 	}
 
 	// else add the new address
-	peer.AddressInfo[address] = &peerAddressInfo{Address: address}
+	peer.AddressInfo[address] = &peerAddressInfo{Address: address, AddedAt: m.options.Now().UTC()}
 	if err := m.store.Set(peer); err != nil {
 		return false, err
 	}
@@ -432,6 +566,83 @@ func (m *PeerManager) Add(address NodeAddress) (bool, error) {
 	return true, nil
 }
 
+// Redial forces a fresh connection to a peer at the given address, adding it
+// if it isn't known yet. Any dial backoff of the peer and its address is
+// cleared so that it is dialed right away, and if the peer is connected it is
+// evicted first, to be dialed again once disconnected. The returned channel
+// receives the result of the next dial of the peer: nil if it was dialed and
+// connected, or the reason it wasn't. The result is no longer awaited once ctx
+// is canceled.
+func (m *PeerManager) Redial(ctx context.Context, address NodeAddress) (<-chan error, error) {
+	if _, err := m.Add(address); err != nil {
+		return nil, err
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	peer, ok := m.store.Get(address.NodeID)
+	if !ok { // Peer may have been pruned right after it was added.
+		return nil, fmt.Errorf("peer %v is not in the peer store", address.NodeID)
+	}
+	peer.DialBackoff = time.Time{}
+	if addressInfo, ok := peer.AddressInfo[address]; ok {
+		addressInfo.DialFailures = 0
+		addressInfo.LastDialFailure = time.Time{}
+	}
+	if err := m.store.Set(peer); err != nil {
+		return nil, err
+	}
+
+	waiter := &dialWaiter{result: make(chan error, 1), done: make(chan struct{})}
+	m.dialResults[address.NodeID] = append(m.dialResults[address.NodeID], waiter)
+	go func() {
+		select {
+		case <-ctx.Done():
+			m.mtx.Lock()
+			m.removeDialWaiter(address.NodeID, waiter)
+			m.mtx.Unlock()
+		case <-waiter.done:
+		case <-m.closeCh:
+		}
+	}()
+
+	if m.connected[address.NodeID] && !m.evicting[address.NodeID] {
+		m.evict[address.NodeID] = DisconnectReasonUnknown
+		m.evictWaker.Wake()
+	}
+	m.dialWaker.Wake()
+	return waiter.result, nil
+}
+
+// reportDialResult sends the result of a dial to the Redial() callers waiting
+// for it. The caller must hold the mutex lock.
+func (m *PeerManager) reportDialResult(peerID NodeID, err error) {
+	for _, waiter := range m.dialResults[peerID] {
+		waiter.result <- err
+		close(waiter.done)
+	}
+	delete(m.dialResults, peerID)
+}
+
+// removeDialWaiter deregisters a Redial() caller that no longer awaits the
+// result of the dial of a peer, if it wasn't reported yet. The caller must hold
+// the mutex lock.
+func (m *PeerManager) removeDialWaiter(peerID NodeID, waiter *dialWaiter) {
+	waiters := m.dialResults[peerID]
+	for i, w := range waiters {
+		if w == waiter {
+			waiters = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(waiters) == 0 {
+		delete(m.dialResults, peerID)
+	} else {
+		m.dialResults[peerID] = waiters
+	}
+}
+
 // PeerRatio returns the ratio of peer addresses stored to the maximum size.
 func (m *PeerManager) PeerRatio() float64 {
 	m.mtx.Lock()
@@ -476,10 +687,17 @@ func (m *PeerManager) TryDialNext() (NodeAddress, error) {
 		return NodeAddress{}, nil
 	}
 
+	if err := m.pruneExpiredAddresses(); err != nil {
+		return NodeAddress{}, err
+	}
+
 	for _, peer := range m.store.Ranked() {
 		if m.dialing[peer.ID] || m.connected[peer.ID] {
 			continue
 		}
+		if time.Now().Before(peer.DialBackoff) {
+			continue
+		}
 
 		for _, addressInfo := range peer.AddressInfo {
 			if time.Since(addressInfo.LastDialFailure) < m.retryDelay(addressInfo.DialFailures, peer.Persistent) {
@@ -522,6 +740,7 @@ func (m *PeerManager) DialFailed(address NodeAddress) error {
 			delete(m.upgrading, from) // Unmark failed upgrade attempt.
 		}
 	}
+	m.reportDialResult(address.NodeID, fmt.Errorf("failed to dial peer %v", address))
 
 	peer, ok := m.store.Get(address.NodeID)
 	if !ok { // Peer may have been removed while dialing, ignore.
@@ -562,9 +781,10 @@ func (m *PeerManager) DialFailed(address NodeAddress) error {
 
 // Dialed marks a peer as successfully dialed. Any further connections will be
 // rejected, and once disconnected the peer may be dialed again.
-func (m *PeerManager) Dialed(address NodeAddress) error {
+func (m *PeerManager) Dialed(address NodeAddress) (err error) {
 	m.mtx.Lock()
 	defer m.mtx.Unlock()
+	defer func() { m.reportDialResult(address.NodeID, err) }()
 
 	delete(m.dialing, address.NodeID)
 
@@ -594,8 +814,9 @@ func (m *PeerManager) Dialed(address NodeAddress) error {
 	if !ok {
 		return fmt.Errorf("peer %q was removed while dialing", address.NodeID)
 	}
-	now := time.Now().UTC()
+	now := m.options.Now().UTC()
 	peer.LastConnected = now
+	peer.Imported = false
 	if addressInfo, ok := peer.AddressInfo[address]; ok {
 		addressInfo.DialFailures = 0
 		addressInfo.LastDialSuccess = now
@@ -614,7 +835,7 @@ func (m *PeerManager) Dialed(address NodeAddress) error {
 				upgradeFromPeer = u
 			}
 		}
-		m.evict[upgradeFromPeer] = true
+		m.evict[upgradeFromPeer] = DisconnectReasonEvicted
 	}
 	m.connected[peer.ID] = true
 	m.evictWaker.Wake()
@@ -677,7 +898,7 @@ func (m *PeerManager) Accepted(peerID NodeID) error {
 
 	m.connected[peerID] = true
 	if upgradeFromPeer != "" {
-		m.evict[upgradeFromPeer] = true
+		m.evict[upgradeFromPeer] = DisconnectReasonEvicted
 	}
 	m.evictWaker.Wake()
 	return nil
@@ -686,16 +907,18 @@ func (m *PeerManager) Accepted(peerID NodeID) error {
 // Ready marks a peer as ready, broadcasting status updates to subscribers. The
 // peer must already be marked as connected. This is separate from Dialed() and
 // Accepted() to allow the router to set up its internal queues before reactors
-// start sending messages.
-func (m *PeerManager) Ready(peerID NodeID) {
+// start sending messages. The channels the peer advertised are passed on in the
+// PeerStatusUp update.
+func (m *PeerManager) Ready(peerID NodeID, channels ChannelIDSet) {
 	m.mtx.Lock()
 	defer m.mtx.Unlock()
 
 	if m.connected[peerID] {
 		m.ready[peerID] = true
 		m.broadcast(PeerUpdate{
-			NodeID: peerID,
-			Status: PeerStatusUp,
+			NodeID:   peerID,
+			Status:   PeerStatusUp,
+			Channels: channels,
 		})
 	}
 }
@@ -703,15 +926,22 @@ func (m *PeerManager) Ready(peerID NodeID) {
 // EvictNext returns the next peer to evict (i.e. disconnect). If no evictable
 // peers are found, the call will block until one becomes available.
 func (m *PeerManager) EvictNext(ctx context.Context) (NodeID, error) {
+	id, _, err := m.evictNext(ctx)
+	return id, err
+}
+
+// evictNext is like EvictNext, but also returns the reason for evicting the
+// peer, to give to it when disconnecting it.
+func (m *PeerManager) evictNext(ctx context.Context) (NodeID, DisconnectReason, error) {
 	for {
-		id, err := m.TryEvictNext()
+		id, reason, err := m.tryEvictNext()
 		if err != nil || id != "" {
-			return id, err
+			return id, reason, err
 		}
 		select {
 		case <-m.evictWaker.Sleep():
 		case <-ctx.Done():
-			return "", ctx.Err()
+			return "", DisconnectReasonUnknown, ctx.Err()
 		}
 	}
 }
@@ -719,37 +949,42 @@ func (m *PeerManager) EvictNext(ctx context.Context) (NodeID, error) {
 // TryEvictNext is equivalent to EvictNext, but immediately returns an empty
 // node ID if no evictable peers are found.
 func (m *PeerManager) TryEvictNext() (NodeID, error) {
+	id, _, err := m.tryEvictNext()
+	return id, err
+}
+
+func (m *PeerManager) tryEvictNext() (NodeID, DisconnectReason, error) {
 	m.mtx.Lock()
 	defer m.mtx.Unlock()
 
 	// If any connected peers are explicitly scheduled for eviction, we return a
 	// random one.
-	for peerID := range m.evict {
+	for peerID, reason := range m.evict {
 		delete(m.evict, peerID)
 		if m.connected[peerID] && !m.evicting[peerID] {
 			m.evicting[peerID] = true
-			return peerID, nil
+			return peerID, reason, nil
 		}
 	}
 
 	// If we're below capacity, we don't need to evict anything.
 	if m.options.MaxConnected == 0 ||
 		len(m.connected)-len(m.evicting) <= int(m.options.MaxConnected) {
-		return "", nil
+		return "", DisconnectReasonUnknown, nil
 	}
 
 	// If we're above capacity (shouldn't really happen), just pick the
-	// lowest-ranked peer to evict.
+	// lowest-ranked peer to evict, sparing persistent peers.
 	ranked := m.store.Ranked()
 	for i := len(ranked) - 1; i >= 0; i-- {
 		peer := ranked[i]
-		if m.connected[peer.ID] && !m.evicting[peer.ID] {
+		if m.connected[peer.ID] && !m.evicting[peer.ID] && !peer.Persistent {
 			m.evicting[peer.ID] = true
-			return peer.ID, nil
+			return peer.ID, DisconnectReasonEvicted, nil
 		}
 	}
 
-	return "", nil
+	return "", DisconnectReasonUnknown, nil
 }
 
 // Disconnected unmarks a peer as connected, allowing it to be dialed or
@@ -776,6 +1011,53 @@ func (m *PeerManager) Disconnected(peerID NodeID) {
 	m.dialWaker.Wake()
 }
 
+// RemoteDisconnected reports that the peer disconnected us, giving the reason
+// for it. If the peer doesn't want us to connect again right away, it isn't
+// dialed again before EvictedRetryTime has passed, or before MinRetryTime if it
+// is a persistent peer that merely evicted us. It must be called before
+// Disconnected.
+func (m *PeerManager) RemoteDisconnected(peerID NodeID, reason DisconnectReason) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if !reason.backOff() {
+		return nil
+	}
+	peer, ok := m.store.Get(peerID)
+	if !ok {
+		return nil
+	}
+
+	delay := m.options.EvictedRetryTime
+	if peer.Persistent && reason == DisconnectReasonEvicted {
+		delay = m.options.MinRetryTime
+	}
+	if delay == 0 {
+		return nil
+	}
+	if m.options.RetryTimeJitter > 0 {
+		delay += time.Duration(m.rand.Int63n(int64(m.options.RetryTimeJitter)))
+	}
+	peer.DialBackoff = time.Now().Add(delay)
+	if err := m.store.Set(peer); err != nil {
+		return err
+	}
+
+	// Disconnected wakes up DialNext() right away, which will skip the peer,
+	// so it has to be woken up again once the backoff has elapsed.
+	go func() {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			m.dialWaker.Wake()
+		case <-m.closeCh:
+		}
+	}()
+
+	return nil
+}
+
 // Errored reports a peer error, causing the peer to be evicted if it's
 // currently connected.
 //
@@ -789,7 +1071,7 @@ func (m *PeerManager) Errored(peerID NodeID, err error) {
 	defer m.mtx.Unlock()
 
 	if m.connected[peerID] {
-		m.evict[peerID] = true
+		m.evict[peerID] = DisconnectReasonMisbehaving
 	}
 
 	m.evictWaker.Wake()
@@ -803,6 +1085,10 @@ func (m *PeerManager) Advertise(peerID NodeID, limit uint16) []NodeAddress {
 	m.mtx.Lock()
 	defer m.mtx.Unlock()
 
+	// Errors are only caused by the database, in which case we simply carry
+	// on advertising what we have in memory.
+	_ = m.pruneExpiredAddresses()
+
 	addresses := make([]NodeAddress, 0, limit)
 	for _, peer := range m.store.Ranked() {
 		if peer.ID == peerID {
@@ -815,15 +1101,124 @@ func (m *PeerManager) Advertise(peerID NodeID, limit uint16) []NodeAddress {
 			}
 
 			// only add non-private NodeIDs
-			if _, ok := m.options.PrivatePeers[nodeAddr.NodeID]; !ok {
-				addresses = append(addresses, addressInfo.Address)
+			if _, ok := m.options.PrivatePeers[nodeAddr.NodeID]; ok {
+				continue
 			}
+			if m.options.AdvertiseDialedOnly && addressInfo.LastDialSuccess.IsZero() {
+				continue
+			}
+			addresses = append(addresses, addressInfo.Address)
 		}
 	}
 
 	return addresses
 }
 
+// ExportAddresses returns the peer addresses that have been dialed
+// successfully, along with their dial statistics, encoded for ImportAddresses
+// as length-delimited PeerInfo messages. It's meant to seed the peer store of
+// a new node with a known-good peer set. Private peers are not exported.
+func (m *PeerManager) ExportAddresses() ([]byte, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	buf := &bytes.Buffer{}
+	w := protoio.NewDelimitedWriter(buf)
+	for _, peer := range m.store.Ranked() {
+		if _, ok := m.options.PrivatePeers[peer.ID]; ok {
+			continue
+		}
+		msg := &p2pproto.PeerInfo{ID: string(peer.ID)}
+		for _, addressInfo := range peer.AddressInfo {
+			if !addressInfo.LastDialSuccess.IsZero() {
+				msg.AddressInfo = append(msg.AddressInfo, addressInfo.ToProto())
+			}
+		}
+		if len(msg.AddressInfo) == 0 {
+			continue
+		}
+		if _, err := w.WriteMsg(msg); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// ImportAddresses adds the peer addresses exported by ExportAddresses,
+// possibly on another node, to the peer store, and returns the number of
+// addresses that were added. Known peers and addresses are left as they are.
+//
+// The exported dial statistics aren't trusted: imported addresses are added
+// as if they had never been dialed, and peers that weren't known have their
+// score reduced by importedPeerScorePenalty, and are ranked below the known
+// peers with the same score, until one of their addresses is dialed
+// successfully. Nothing is added if bz is invalid.
+func (m *PeerManager) ImportAddresses(bz []byte) (int, error) {
+	var peers []*peerInfo
+	r := protoio.NewDelimitedReader(bytes.NewReader(bz), len(bz))
+	for {
+		msg := &p2pproto.PeerInfo{}
+		if _, err := r.ReadMsg(msg); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return 0, fmt.Errorf("invalid address book: %w", err)
+		}
+		peer, err := peerInfoFromProto(msg)
+		if err != nil {
+			return 0, fmt.Errorf("invalid address book: %w", err)
+		}
+		for address := range peer.AddressInfo {
+			if err := address.Validate(); err != nil {
+				return 0, fmt.Errorf("invalid address book: %w", err)
+			}
+			if address.NodeID != peer.ID {
+				return 0, fmt.Errorf("invalid address book: address %v of peer %v has another node ID", address, peer.ID)
+			}
+		}
+		peers = append(peers, peer)
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	added := 0
+	now := m.options.Now().UTC()
+	for _, imported := range peers {
+		if imported.ID == m.selfID {
+			continue
+		}
+		peer, ok := m.store.Get(imported.ID)
+		if !ok {
+			peer = m.newPeerInfo(imported.ID)
+			peer.Imported = true
+		}
+
+		changed := false
+		for address := range imported.AddressInfo {
+			if _, ok := peer.AddressInfo[address]; ok {
+				continue
+			}
+			peer.AddressInfo[address] = &peerAddressInfo{Address: address, AddedAt: now}
+			changed = true
+			added++
+		}
+		if !changed {
+			continue
+		}
+		if err := m.store.Set(peer); err != nil {
+			return added, err
+		}
+	}
+
+	if err := m.prunePeers(); err != nil {
+		return added, err
+	}
+	if added > 0 {
+		m.dialWaker.Wake()
+	}
+	return added, nil
+}
+
 // Subscribe subscribes to peer updates. The caller must consume the peer
 // updates in a timely fashion and close the subscription when done, otherwise
 // the PeerManager will halt.
@@ -973,6 +1368,11 @@ func (m *PeerManager) Status(id NodeID) PeerStatus {
 	}
 }
 
+// IsPersistent returns true if the peer is one of the persistent peers.
+func (m *PeerManager) IsPersistent(peerID NodeID) bool {
+	return m.options.isPersistent(peerID)
+}
+
 // findUpgradeCandidate looks for a lower-scored peer that we could evict
 // to make room for the given peer. Returns an empty ID if none is found.
 // If the peer is already being upgraded to, we return that same upgrade.
@@ -987,11 +1387,13 @@ func (m *PeerManager) findUpgradeCandidate(id NodeID, score PeerScore) NodeID {
 	ranked := m.store.Ranked()
 	for i := len(ranked) - 1; i >= 0; i-- {
 		candidate := ranked[i]
+		_, evict := m.evict[candidate.ID]
 		switch {
 		case candidate.Score() >= score:
 			return "" // no further peers can be scored lower, due to sorting
+		case candidate.Persistent:
 		case !m.connected[candidate.ID]:
-		case m.evict[candidate.ID]:
+		case evict:
 		case m.evicting[candidate.ID]:
 		case m.upgrading[candidate.ID] != "":
 		default:
@@ -1201,7 +1603,11 @@ func (s *peerStore) Ranked() []*peerInfo {
 	sort.Slice(s.ranked, func(i, j int) bool {
 		// FIXME: If necessary, consider precomputing scores before sorting,
 		// to reduce the number of Score() calls.
-		return s.ranked[i].Score() > s.ranked[j].Score()
+		scoreI, scoreJ := s.ranked[i].Score(), s.ranked[j].Score()
+		if scoreI == scoreJ {
+			return !s.ranked[i].Imported && s.ranked[j].Imported
+		}
+		return scoreI > scoreJ
 	})
 	return s.ranked
 }
@@ -1216,11 +1622,13 @@ type peerInfo struct {
 	ID            NodeID
 	AddressInfo   map[NodeAddress]*peerAddressInfo
 	LastConnected time.Time
+	Imported      bool // added by ImportAddresses, until dialed successfully
 
 	// These fields are ephemeral, i.e. not persisted to the database.
-	Persistent bool
-	Height     int64
-	FixedScore PeerScore // mainly for tests
+	Persistent  bool
+	Height      int64
+	FixedScore  PeerScore // mainly for tests
+	DialBackoff time.Time // not dialed before then, see RemoteDisconnected
 
 	MutableScore int64 // updated by router
 }
@@ -1231,6 +1639,7 @@ func peerInfoFromProto(msg *p2pproto.PeerInfo) (*peerInfo, error) {
 	p := &peerInfo{
 		ID:          NodeID(msg.ID),
 		AddressInfo: map[NodeAddress]*peerAddressInfo{},
+		Imported:    msg.Imported,
 	}
 	if msg.LastConnected != nil {
 		p.LastConnected = *msg.LastConnected
@@ -1253,6 +1662,7 @@ func (p *peerInfo) ToProto() *p2pproto.PeerInfo {
 	msg := &p2pproto.PeerInfo{
 		ID:            string(p.ID),
 		LastConnected: &p.LastConnected,
+		Imported:      p.Imported,
 	}
 	for _, addressInfo := range p.AddressInfo {
 		msg.AddressInfo = append(msg.AddressInfo, addressInfo.ToProto())
@@ -1286,15 +1696,20 @@ func (p *peerInfo) Score() PeerScore {
 		return PeerScorePersistent
 	}
 
-	if p.MutableScore <= 0 {
+	score := p.MutableScore
+	if p.Imported {
+		score -= importedPeerScorePenalty
+	}
+
+	if score <= 0 {
 		return 0
 	}
 
-	if p.MutableScore >= math.MaxUint8 {
+	if score >= math.MaxUint8 {
 		return PeerScore(math.MaxUint8)
 	}
 
-	return PeerScore(p.MutableScore)
+	return PeerScore(score)
 }
 
 // Validate validates the peer info.
@@ -1311,6 +1726,9 @@ type peerAddressInfo struct {
 	LastDialSuccess time.Time
 	LastDialFailure time.Time
 	DialFailures    uint32 // since last successful dial
+
+	// AddedAt is ephemeral, i.e. not persisted to the database.
+	AddedAt time.Time
 }
 
 // peerAddressInfoFromProto converts a Protobuf PeerAddressInfo message
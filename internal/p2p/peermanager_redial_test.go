@@ -0,0 +1,62 @@
+package p2p
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+)
+
+func TestPeerManager_RedialCanceled(t *testing.T) {
+	selfID := NodeID(strings.Repeat("f", 40))
+	a := NodeAddress{Protocol: "memory", NodeID: NodeID(strings.Repeat("a", 40))}
+
+	peerManager, err := NewPeerManager(selfID, dbm.NewMemDB(), PeerManagerOptions{})
+	require.NoError(t, err)
+	defer peerManager.Close()
+
+	numWaiters := func() int {
+		peerManager.mtx.Lock()
+		defer peerManager.mtx.Unlock()
+		return len(peerManager.dialResults[a.NodeID])
+	}
+
+	// A caller giving up on the dial is deregistered, and doesn't receive its
+	// result, while the others still do.
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled, err := peerManager.Redial(ctx, a)
+	require.NoError(t, err)
+	waiting, err := peerManager.Redial(context.Background(), a)
+	require.NoError(t, err)
+	require.Equal(t, 2, numWaiters())
+
+	cancel()
+	require.Eventually(t, func() bool { return numWaiters() == 1 }, time.Second, 10*time.Millisecond)
+
+	dial, err := peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Equal(t, a, dial)
+	require.NoError(t, peerManager.Dialed(a))
+	require.NoError(t, <-waiting)
+	require.Empty(t, canceled)
+	require.Zero(t, numWaiters())
+
+	// Canceling once the result was reported is a no-op.
+	ctx, cancel = context.WithCancel(context.Background())
+	result, err := peerManager.Redial(ctx, a)
+	require.NoError(t, err)
+	evict, err := peerManager.TryEvictNext()
+	require.NoError(t, err)
+	require.Equal(t, a.NodeID, evict)
+	peerManager.Disconnected(a.NodeID)
+	dial, err = peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Equal(t, a, dial)
+	require.NoError(t, peerManager.Dialed(a))
+	cancel()
+	require.NoError(t, <-result)
+	require.Zero(t, numWaiters())
+}
@@ -78,3 +78,40 @@ func TestPeerScoring(t *testing.T) {
 			"startAt=%d score=%d", start, peerManager.Scores()[id])
 	})
 }
+
+func TestPeerScoring_Imported(t *testing.T) {
+	selfKey := ed25519.GenPrivKeyFromSecret([]byte{0xf9, 0x1b, 0x08, 0xaa, 0x38, 0xee, 0x34, 0xdd})
+	selfID := NodeIDFromPubKey(selfKey.PubKey())
+
+	peerManager, err := NewPeerManager(selfID, dbm.NewMemDB(), PeerManagerOptions{})
+	require.NoError(t, err)
+	defer peerManager.Close()
+
+	known := NodeID(strings.Repeat("a1", 20))
+	added, err := peerManager.Add(NodeAddress{NodeID: known, Protocol: "memory"})
+	require.NoError(t, err)
+	require.True(t, added)
+
+	imported := NodeID(strings.Repeat("b2", 20))
+	peer := peerManager.newPeerInfo(imported)
+	peer.Imported = true
+	peer.AddressInfo[NodeAddress{NodeID: imported, Protocol: "memory"}] = &peerAddressInfo{
+		Address: NodeAddress{NodeID: imported, Protocol: "memory"},
+	}
+	require.NoError(t, peerManager.store.Set(peer))
+
+	// The imported peer scores and ranks below the known one even after more
+	// good updates, until they make up for the penalty.
+	peerManager.processPeerEvent(PeerUpdate{NodeID: known, Status: PeerStatusGood})
+	for i := 0; i < importedPeerScorePenalty; i++ {
+		peerManager.processPeerEvent(PeerUpdate{NodeID: imported, Status: PeerStatusGood})
+	}
+	require.EqualValues(t, 1, peerManager.Scores()[known])
+	require.EqualValues(t, 0, peerManager.Scores()[imported])
+	require.Equal(t, known, peerManager.store.Ranked()[0].ID)
+
+	for i := 0; i < 2; i++ {
+		peerManager.processPeerEvent(PeerUpdate{NodeID: imported, Status: PeerStatusGood})
+	}
+	require.EqualValues(t, 2, peerManager.Scores()[imported])
+}
@@ -93,6 +93,11 @@ func TestPeerManagerOptions_Validate(t *testing.T) {
 		"MaxRetryTimePersistent without MinRetryTime": {p2p.PeerManagerOptions{
 			MaxRetryTimePersistent: 5 * time.Second,
 		}, false},
+
+		// EvictedRetryTime
+		"negative EvictedRetryTime": {p2p.PeerManagerOptions{
+			EvictedRetryTime: -time.Second,
+		}, false},
 	}
 	for name, tc := range testcases {
 		tc := tc
@@ -1268,20 +1273,26 @@ func TestPeerManager_Ready(t *testing.T) {
 	require.NoError(t, peerManager.Accepted(a.NodeID))
 	require.Equal(t, p2p.PeerStatusDown, peerManager.Status(a.NodeID))
 
-	// Marking a as ready should transition it to PeerStatusUp and send an update.
-	peerManager.Ready(a.NodeID)
+	// Marking a as ready should transition it to PeerStatusUp and send an update,
+	// with the channels it advertised.
+	channels := p2p.NewChannelIDSet([]byte{0x01, 0x20})
+	peerManager.Ready(a.NodeID, channels)
 	require.Equal(t, p2p.PeerStatusUp, peerManager.Status(a.NodeID))
+	update := <-sub.Updates()
 	require.Equal(t, p2p.PeerUpdate{
-		NodeID: a.NodeID,
-		Status: p2p.PeerStatusUp,
-	}, <-sub.Updates())
+		NodeID:   a.NodeID,
+		Status:   p2p.PeerStatusUp,
+		Channels: channels,
+	}, update)
+	require.True(t, update.Channels.Contains(0x20))
+	require.False(t, update.Channels.Contains(0x30))
 
 	// Marking an unconnected peer as ready should do nothing.
 	added, err = peerManager.Add(b)
 	require.NoError(t, err)
 	require.True(t, added)
 	require.Equal(t, p2p.PeerStatusDown, peerManager.Status(b.NodeID))
-	peerManager.Ready(b.NodeID)
+	peerManager.Ready(b.NodeID, nil)
 	require.Equal(t, p2p.PeerStatusDown, peerManager.Status(b.NodeID))
 	require.Empty(t, sub.Updates())
 }
@@ -1297,7 +1308,7 @@ func TestPeerManager_EvictNext(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, added)
 	require.NoError(t, peerManager.Accepted(a.NodeID))
-	peerManager.Ready(a.NodeID)
+	peerManager.Ready(a.NodeID, nil)
 
 	// Since there are no peers to evict, EvictNext should block until timeout.
 	timeoutCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
@@ -1330,7 +1341,7 @@ func TestPeerManager_EvictNext_WakeOnError(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, added)
 	require.NoError(t, peerManager.Accepted(a.NodeID))
-	peerManager.Ready(a.NodeID)
+	peerManager.Ready(a.NodeID, nil)
 
 	// Spawn a goroutine to error a peer after a delay.
 	go func() {
@@ -1362,7 +1373,7 @@ func TestPeerManager_EvictNext_WakeOnUpgradeDialed(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, added)
 	require.NoError(t, peerManager.Accepted(a.NodeID))
-	peerManager.Ready(a.NodeID)
+	peerManager.Ready(a.NodeID, nil)
 
 	// Spawn a goroutine to upgrade to b with a delay.
 	go func() {
@@ -1400,7 +1411,7 @@ func TestPeerManager_EvictNext_WakeOnUpgradeAccepted(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, added)
 	require.NoError(t, peerManager.Accepted(a.NodeID))
-	peerManager.Ready(a.NodeID)
+	peerManager.Ready(a.NodeID, nil)
 
 	// Spawn a goroutine to upgrade b with a delay.
 	go func() {
@@ -1432,7 +1443,7 @@ func TestPeerManager_TryEvictNext(t *testing.T) {
 
 	// Connecting to a won't evict anything either.
 	require.NoError(t, peerManager.Accepted(a.NodeID))
-	peerManager.Ready(a.NodeID)
+	peerManager.Ready(a.NodeID, nil)
 
 	// But if a errors it should be evicted.
 	peerManager.Errored(a.NodeID, errors.New("foo"))
@@ -1451,6 +1462,51 @@ func TestPeerManager_TryEvictNext(t *testing.T) {
 	require.Zero(t, evict)
 }
 
+func TestPeerManager_TryEvictNext_Persistent(t *testing.T) {
+	a := p2p.NodeAddress{Protocol: "memory", NodeID: p2p.NodeID(strings.Repeat("a", 40))}
+	b := p2p.NodeAddress{Protocol: "memory", NodeID: p2p.NodeID(strings.Repeat("b", 40))}
+	c := p2p.NodeAddress{Protocol: "memory", NodeID: p2p.NodeID(strings.Repeat("c", 40))}
+
+	// a is persistent, but scored below b and c.
+	peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{
+		PersistentPeers: []p2p.NodeID{a.NodeID},
+		PeerScores: map[p2p.NodeID]p2p.PeerScore{
+			a.NodeID: 1,
+			b.NodeID: 2,
+			c.NodeID: 3,
+		},
+		MaxConnected:        2,
+		MaxConnectedUpgrade: 1,
+	})
+	require.NoError(t, err)
+
+	for _, address := range []p2p.NodeAddress{a, b, c} {
+		added, err := peerManager.Add(address)
+		require.NoError(t, err)
+		require.True(t, added)
+	}
+	require.NoError(t, peerManager.Accepted(a.NodeID))
+	require.NoError(t, peerManager.Accepted(b.NodeID))
+
+	// Accepting c exceeds MaxConnected, which evicts b rather than the
+	// lower-scored but persistent a.
+	require.NoError(t, peerManager.Accepted(c.NodeID))
+	evict, err := peerManager.TryEvictNext()
+	require.NoError(t, err)
+	require.Equal(t, b.NodeID, evict)
+	peerManager.Disconnected(b.NodeID)
+
+	evict, err = peerManager.TryEvictNext()
+	require.NoError(t, err)
+	require.Zero(t, evict)
+
+	// a is still evicted if it misbehaves.
+	peerManager.Errored(a.NodeID, errors.New("foo"))
+	evict, err = peerManager.TryEvictNext()
+	require.NoError(t, err)
+	require.Equal(t, a.NodeID, evict)
+}
+
 func TestPeerManager_Disconnected(t *testing.T) {
 	a := p2p.NodeAddress{Protocol: "memory", NodeID: p2p.NodeID(strings.Repeat("a", 40))}
 
@@ -1477,7 +1533,7 @@ func TestPeerManager_Disconnected(t *testing.T) {
 	_, err = peerManager.Add(a)
 	require.NoError(t, err)
 	require.NoError(t, peerManager.Accepted(a.NodeID))
-	peerManager.Ready(a.NodeID)
+	peerManager.Ready(a.NodeID, nil)
 	require.Equal(t, p2p.PeerStatusUp, peerManager.Status(a.NodeID))
 	require.NotEmpty(t, sub.Updates())
 	require.Equal(t, p2p.PeerUpdate{
@@ -1529,7 +1585,7 @@ func TestPeerManager_Errored(t *testing.T) {
 	require.Zero(t, evict)
 
 	require.NoError(t, peerManager.Accepted(a.NodeID))
-	peerManager.Ready(a.NodeID)
+	peerManager.Ready(a.NodeID, nil)
 	evict, err = peerManager.TryEvictNext()
 	require.NoError(t, err)
 	require.Zero(t, evict)
@@ -1541,6 +1597,144 @@ func TestPeerManager_Errored(t *testing.T) {
 	require.Equal(t, a.NodeID, evict)
 }
 
+func TestPeerManager_RemoteDisconnected(t *testing.T) {
+	a := p2p.NodeAddress{Protocol: "memory", NodeID: p2p.NodeID(strings.Repeat("a", 40))}
+
+	options := p2p.PeerManagerOptions{EvictedRetryTime: 200 * time.Millisecond}
+	peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), options)
+	require.NoError(t, err)
+
+	added, err := peerManager.Add(a)
+	require.NoError(t, err)
+	require.True(t, added)
+
+	// A peer that disconnects because it's shutting down can be dialed again
+	// right away.
+	dial, err := peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Equal(t, a, dial)
+	require.NoError(t, peerManager.Dialed(a))
+	require.NoError(t, peerManager.RemoteDisconnected(a.NodeID, p2p.DisconnectReasonShuttingDown))
+	peerManager.Disconnected(a.NodeID)
+
+	dial, err = peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Equal(t, a, dial)
+
+	// A peer that evicts us is backed off for EvictedRetryTime.
+	require.NoError(t, peerManager.Dialed(a))
+	require.NoError(t, peerManager.RemoteDisconnected(a.NodeID, p2p.DisconnectReasonEvicted))
+	peerManager.Disconnected(a.NodeID)
+	disconnected := time.Now()
+
+	dial, err = peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Zero(t, dial)
+
+	// DialNext is woken up once the backoff has elapsed.
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	dial, err = peerManager.DialNext(ctx)
+	require.NoError(t, err)
+	require.Equal(t, a, dial)
+	require.GreaterOrEqual(t, time.Since(disconnected), options.EvictedRetryTime)
+}
+
+func TestPeerManager_RemoteDisconnected_Persistent(t *testing.T) {
+	a := p2p.NodeAddress{Protocol: "memory", NodeID: p2p.NodeID(strings.Repeat("a", 40))}
+
+	peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{
+		PersistentPeers:  []p2p.NodeID{a.NodeID},
+		MinRetryTime:     100 * time.Millisecond,
+		EvictedRetryTime: time.Hour,
+	})
+	require.NoError(t, err)
+
+	added, err := peerManager.Add(a)
+	require.NoError(t, err)
+	require.True(t, added)
+
+	// A persistent peer that evicts us is dialed again after the normal retry
+	// backoff, rather than EvictedRetryTime.
+	dial, err := peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Equal(t, a, dial)
+	require.NoError(t, peerManager.Dialed(a))
+	require.NoError(t, peerManager.RemoteDisconnected(a.NodeID, p2p.DisconnectReasonEvicted))
+	peerManager.Disconnected(a.NodeID)
+
+	dial, err = peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Zero(t, dial)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	dial, err = peerManager.DialNext(ctx)
+	require.NoError(t, err)
+	require.Equal(t, a, dial)
+
+	// But it's backed off if we're incompatible.
+	require.NoError(t, peerManager.Dialed(a))
+	require.NoError(t, peerManager.RemoteDisconnected(a.NodeID, p2p.DisconnectReasonIncompatible))
+	peerManager.Disconnected(a.NodeID)
+
+	dial, err = peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Zero(t, dial)
+}
+
+func TestPeerManager_Redial(t *testing.T) {
+	a := p2p.NodeAddress{Protocol: "memory", NodeID: p2p.NodeID(strings.Repeat("a", 40))}
+
+	peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{
+		MinRetryTime: time.Hour,
+	})
+	require.NoError(t, err)
+
+	// Redialing an unknown peer adds it, and it's dialed right away.
+	result, err := peerManager.Redial(ctx, a)
+	require.NoError(t, err)
+	require.Equal(t, []p2p.NodeAddress{a}, peerManager.Addresses(a.NodeID))
+	dial, err := peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Equal(t, a, dial)
+
+	// A failed dial is reported to the caller, and retried right away once
+	// redialed, rather than after MinRetryTime.
+	require.NoError(t, peerManager.DialFailed(a))
+	require.Error(t, <-result)
+	dial, err = peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Zero(t, dial)
+
+	result, err = peerManager.Redial(ctx, a)
+	require.NoError(t, err)
+	dial, err = peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Equal(t, a, dial)
+	require.NoError(t, peerManager.Dialed(a))
+	require.NoError(t, <-result)
+
+	// A connected peer is evicted first, and dialed again once disconnected.
+	result, err = peerManager.Redial(ctx, a)
+	require.NoError(t, err)
+	evict, err := peerManager.TryEvictNext()
+	require.NoError(t, err)
+	require.Equal(t, a.NodeID, evict)
+	peerManager.Disconnected(a.NodeID)
+	require.Empty(t, result)
+
+	dial, err = peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Equal(t, a, dial)
+	require.NoError(t, peerManager.Dialed(a))
+	require.NoError(t, <-result)
+
+	// Invalid addresses are rejected.
+	_, err = peerManager.Redial(ctx, p2p.NodeAddress{Protocol: "memory"})
+	require.Error(t, err)
+}
+
 func TestPeerManager_Subscribe(t *testing.T) {
 	a := p2p.NodeAddress{Protocol: "memory", NodeID: p2p.NodeID(strings.Repeat("a", 40))}
 
@@ -1560,7 +1754,7 @@ func TestPeerManager_Subscribe(t *testing.T) {
 	require.NoError(t, peerManager.Accepted(a.NodeID))
 	require.Empty(t, sub.Updates())
 
-	peerManager.Ready(a.NodeID)
+	peerManager.Ready(a.NodeID, nil)
 	require.NotEmpty(t, sub.Updates())
 	require.Equal(t, p2p.PeerUpdate{NodeID: a.NodeID, Status: p2p.PeerStatusUp}, <-sub.Updates())
 
@@ -1577,7 +1771,7 @@ func TestPeerManager_Subscribe(t *testing.T) {
 	require.NoError(t, peerManager.Dialed(a))
 	require.Empty(t, sub.Updates())
 
-	peerManager.Ready(a.NodeID)
+	peerManager.Ready(a.NodeID, nil)
 	require.NotEmpty(t, sub.Updates())
 	require.Equal(t, p2p.PeerUpdate{NodeID: a.NodeID, Status: p2p.PeerStatusUp}, <-sub.Updates())
 
@@ -1617,7 +1811,7 @@ func TestPeerManager_Subscribe_Close(t *testing.T) {
 	require.NoError(t, peerManager.Accepted(a.NodeID))
 	require.Empty(t, sub.Updates())
 
-	peerManager.Ready(a.NodeID)
+	peerManager.Ready(a.NodeID, nil)
 	require.NotEmpty(t, sub.Updates())
 	require.Equal(t, p2p.PeerUpdate{NodeID: a.NodeID, Status: p2p.PeerStatusUp}, <-sub.Updates())
 
@@ -1647,7 +1841,7 @@ func TestPeerManager_Subscribe_Broadcast(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, added)
 	require.NoError(t, peerManager.Accepted(a.NodeID))
-	peerManager.Ready(a.NodeID)
+	peerManager.Ready(a.NodeID, nil)
 
 	expectUp := p2p.PeerUpdate{NodeID: a.NodeID, Status: p2p.PeerStatusUp}
 	require.NotEmpty(t, s1)
@@ -1759,6 +1953,272 @@ func TestPeerManager_Advertise(t *testing.T) {
 	}, peerManager.Advertise(dID, 2))
 }
 
+func TestPeerManager_Advertise_DialedOnly(t *testing.T) {
+	a := p2p.NodeAddress{Protocol: "memory", NodeID: p2p.NodeID(strings.Repeat("a", 40))}
+	b := p2p.NodeAddress{Protocol: "memory", NodeID: p2p.NodeID(strings.Repeat("b", 40))}
+	c := p2p.NodeAddress{Protocol: "memory", NodeID: p2p.NodeID(strings.Repeat("c", 40))}
+
+	db := dbm.NewMemDB()
+	peerManager, err := p2p.NewPeerManager(selfID, db, p2p.PeerManagerOptions{
+		AdvertiseDialedOnly: true,
+	})
+	require.NoError(t, err)
+
+	added, err := peerManager.Add(a)
+	require.NoError(t, err)
+	require.True(t, added)
+	added, err = peerManager.Add(b)
+	require.NoError(t, err)
+	require.True(t, added)
+
+	// Nothing is advertised until it has been dialed.
+	require.Empty(t, peerManager.Advertise(c.NodeID, 100))
+
+	// a is successfully dialed, while dialing b fails. Only a is advertised,
+	// even once disconnected.
+	dial, err := peerManager.TryDialNext()
+	require.NoError(t, err)
+	if dial.NodeID == b.NodeID {
+		require.NoError(t, peerManager.DialFailed(dial))
+		dial, err = peerManager.TryDialNext()
+		require.NoError(t, err)
+		require.Equal(t, a, dial)
+		require.NoError(t, peerManager.Dialed(a))
+	} else {
+		require.Equal(t, a, dial)
+		require.NoError(t, peerManager.Dialed(a))
+		dial, err = peerManager.TryDialNext()
+		require.NoError(t, err)
+		require.Equal(t, b, dial)
+		require.NoError(t, peerManager.DialFailed(dial))
+	}
+	peerManager.Disconnected(a.NodeID)
+	require.Equal(t, []p2p.NodeAddress{a}, peerManager.Advertise(c.NodeID, 100))
+
+	// b is still kept as a peer, to be dialed again.
+	require.ElementsMatch(t, []p2p.NodeID{a.NodeID, b.NodeID}, peerManager.Peers())
+
+	// The dial success is persisted.
+	peerManager.Close()
+	peerManager, err = p2p.NewPeerManager(selfID, db, p2p.PeerManagerOptions{
+		AdvertiseDialedOnly: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []p2p.NodeAddress{a}, peerManager.Advertise(c.NodeID, 100))
+
+	// Without the option, all addresses are advertised.
+	peerManager.Close()
+	peerManager, err = p2p.NewPeerManager(selfID, db, p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	defer peerManager.Close()
+	require.ElementsMatch(t, []p2p.NodeAddress{a, b}, peerManager.Advertise(c.NodeID, 100))
+}
+
+func TestPeerManager_ExportImportAddresses(t *testing.T) {
+	a := p2p.NodeAddress{Protocol: "memory", NodeID: p2p.NodeID(strings.Repeat("a", 40))}
+	b := p2p.NodeAddress{Protocol: "memory", NodeID: p2p.NodeID(strings.Repeat("b", 40))}
+	bTCP := p2p.NodeAddress{Protocol: "tcp", NodeID: b.NodeID, Hostname: "127.0.0.1", Port: 26656}
+	c := p2p.NodeAddress{Protocol: "memory", NodeID: p2p.NodeID(strings.Repeat("c", 40))}
+	d := p2p.NodeAddress{Protocol: "memory", NodeID: p2p.NodeID(strings.Repeat("d", 40))}
+
+	// a and b are dialed successfully by the source, while dialing c fails.
+	source, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	defer source.Close()
+	for _, address := range []p2p.NodeAddress{a, b, c} {
+		added, err := source.Add(address)
+		require.NoError(t, err)
+		require.True(t, added)
+	}
+	for i := 0; i < 3; i++ {
+		dial, err := source.TryDialNext()
+		require.NoError(t, err)
+		if dial == c {
+			require.NoError(t, source.DialFailed(dial))
+		} else {
+			require.NoError(t, source.Dialed(dial))
+		}
+	}
+	source.Disconnected(a.NodeID)
+	source.Disconnected(b.NodeID)
+
+	// Only the dialed addresses are exported.
+	bz, err := source.ExportAddresses()
+	require.NoError(t, err)
+
+	// The target already knows b at another address, and d.
+	target, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{
+		AdvertiseDialedOnly: true,
+	})
+	require.NoError(t, err)
+	defer target.Close()
+	for _, address := range []p2p.NodeAddress{bTCP, d} {
+		added, err := target.Add(address)
+		require.NoError(t, err)
+		require.True(t, added)
+	}
+
+	added, err := target.ImportAddresses(bz)
+	require.NoError(t, err)
+	require.Equal(t, 2, added)
+	require.ElementsMatch(t, []p2p.NodeID{a.NodeID, b.NodeID, d.NodeID}, target.Peers())
+	require.ElementsMatch(t, []p2p.NodeAddress{b, bTCP}, target.Addresses(b.NodeID))
+
+	// Importing is a merge, so nothing is added the second time around.
+	added, err = target.ImportAddresses(bz)
+	require.NoError(t, err)
+	require.Zero(t, added)
+
+	// The imported addresses aren't considered dialed by the target, and the
+	// imported peer a is only dialed after the known ones.
+	require.Empty(t, target.Advertise(c.NodeID, 100))
+	dialed := []p2p.NodeID{}
+	for i := 0; i < 3; i++ {
+		dial, err := target.TryDialNext()
+		require.NoError(t, err)
+		require.NotZero(t, dial)
+		require.NoError(t, target.Dialed(dial))
+		dialed = append(dialed, dial.NodeID)
+	}
+	require.ElementsMatch(t, []p2p.NodeID{b.NodeID, d.NodeID}, dialed[:2])
+	require.Equal(t, a.NodeID, dialed[2])
+	require.Contains(t, target.Advertise(c.NodeID, 100), a)
+
+	// An invalid address book is rejected as a whole.
+	_, err = target.ImportAddresses(append(bz, 0xff))
+	require.Error(t, err)
+}
+
+func TestPeerManager_ImportAddresses_Persisted(t *testing.T) {
+	a := p2p.NodeAddress{Protocol: "memory", NodeID: p2p.NodeID(strings.Repeat("a", 40))}
+	b := p2p.NodeAddress{Protocol: "memory", NodeID: p2p.NodeID(strings.Repeat("b", 40))}
+
+	source, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	defer source.Close()
+	added, err := source.Add(a)
+	require.NoError(t, err)
+	require.True(t, added)
+	dial, err := source.TryDialNext()
+	require.NoError(t, err)
+	require.NoError(t, source.Dialed(dial))
+	bz, err := source.ExportAddresses()
+	require.NoError(t, err)
+
+	db := dbm.NewMemDB()
+	target, err := p2p.NewPeerManager(selfID, db, p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	added, err = target.Add(b)
+	require.NoError(t, err)
+	require.True(t, added)
+	imported, err := target.ImportAddresses(bz)
+	require.NoError(t, err)
+	require.Equal(t, 1, imported)
+	target.Close()
+
+	// a is still considered imported after a restart, and dialed after b.
+	target, err = p2p.NewPeerManager(selfID, db, p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	defer target.Close()
+	dial, err = target.TryDialNext()
+	require.NoError(t, err)
+	require.Equal(t, b, dial)
+	require.NoError(t, target.Dialed(dial))
+	dial, err = target.TryDialNext()
+	require.NoError(t, err)
+	require.Equal(t, a, dial)
+	require.NoError(t, target.Dialed(dial))
+	target.Close()
+
+	// Once dialed, it's no longer considered imported.
+	target, err = p2p.NewPeerManager(selfID, db, p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	defer target.Close()
+	bz, err = target.ExportAddresses()
+	require.NoError(t, err)
+	other, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	defer other.Close()
+	imported, err = other.ImportAddresses(bz)
+	require.NoError(t, err)
+	require.Equal(t, 2, imported)
+}
+
+func TestPeerManager_ExportAddresses_PrivatePeers(t *testing.T) {
+	a := p2p.NodeAddress{Protocol: "memory", NodeID: p2p.NodeID(strings.Repeat("a", 40))}
+	b := p2p.NodeAddress{Protocol: "memory", NodeID: p2p.NodeID(strings.Repeat("b", 40))}
+
+	source, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{
+		PrivatePeers: map[p2p.NodeID]struct{}{b.NodeID: {}},
+	})
+	require.NoError(t, err)
+	defer source.Close()
+	for _, address := range []p2p.NodeAddress{a, b} {
+		added, err := source.Add(address)
+		require.NoError(t, err)
+		require.True(t, added)
+		dial, err := source.TryDialNext()
+		require.NoError(t, err)
+		require.NoError(t, source.Dialed(dial))
+	}
+
+	bz, err := source.ExportAddresses()
+	require.NoError(t, err)
+
+	target, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	defer target.Close()
+	added, err := target.ImportAddresses(bz)
+	require.NoError(t, err)
+	require.Equal(t, 1, added)
+	require.Equal(t, []p2p.NodeID{a.NodeID}, target.Peers())
+}
+
+func TestPeerManager_AddressTTL(t *testing.T) {
+	a := p2p.NodeAddress{Protocol: "memory", NodeID: p2p.NodeID(strings.Repeat("a", 40))}
+	b := p2p.NodeAddress{Protocol: "memory", NodeID: p2p.NodeID(strings.Repeat("b", 40))}
+	c := p2p.NodeAddress{Protocol: "memory", NodeID: p2p.NodeID(strings.Repeat("c", 40))}
+
+	now := time.Now()
+	peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{
+		AddressTTL: time.Hour,
+		Now:        func() time.Time { return now },
+	})
+	require.NoError(t, err)
+	defer peerManager.Close()
+
+	added, err := peerManager.Add(a)
+	require.NoError(t, err)
+	require.True(t, added)
+	added, err = peerManager.Add(b)
+	require.NoError(t, err)
+	require.True(t, added)
+
+	// b is successfully dialed and disconnected half way through the TTL.
+	now = now.Add(30 * time.Minute)
+	dial, err := peerManager.TryDialNext()
+	require.NoError(t, err)
+	for dial.NodeID != b.NodeID {
+		require.NoError(t, peerManager.DialFailed(dial))
+		dial, err = peerManager.TryDialNext()
+		require.NoError(t, err)
+	}
+	require.NoError(t, peerManager.Dialed(b))
+	peerManager.Disconnected(b.NodeID)
+	require.ElementsMatch(t, []p2p.NodeAddress{a, b}, peerManager.Advertise(c.NodeID, 100))
+
+	// Once the TTL of a has passed without a successful dial, it should be
+	// pruned and no longer gossiped, while b is still within its TTL.
+	now = now.Add(31 * time.Minute)
+	require.ElementsMatch(t, []p2p.NodeAddress{b}, peerManager.Advertise(c.NodeID, 100))
+	require.ElementsMatch(t, []p2p.NodeID{b.NodeID}, peerManager.Peers())
+
+	// Eventually, b expires too.
+	now = now.Add(time.Hour)
+	require.Empty(t, peerManager.Advertise(c.NodeID, 100))
+	require.Empty(t, peerManager.Peers())
+}
+
 func TestPeerManager_SetHeight_GetHeight(t *testing.T) {
 	a := p2p.NodeAddress{Protocol: "memory", NodeID: p2p.NodeID(strings.Repeat("a", 40))}
 	b := p2p.NodeAddress{Protocol: "memory", NodeID: p2p.NodeID(strings.Repeat("b", 40))}
@@ -6,7 +6,6 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/gogo/protobuf/proto"
 	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
 	"github.com/tendermint/tendermint/libs/log"
 )
@@ -162,7 +161,7 @@ func (s *pqScheduler) process() {
 			chIDStr := strconv.Itoa(int(e.channelID))
 			pqEnv := &pqEnvelope{
 				envelope:  e,
-				size:      uint(proto.Size(e.Message)),
+				size:      uint(e.size()),
 				priority:  s.chPriorities[e.channelID],
 				timestamp: time.Now().UTC(),
 			}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
 
 	"github.com/tendermint/tendermint/crypto"
 	"github.com/tendermint/tendermint/libs/log"
@@ -38,6 +39,31 @@ type Envelope struct {
 	// onto a stream during channel/peer setup. See:
 	// https://github.com/tendermint/spec/pull/227
 	channelID ChannelID
+
+	// payload is the compressed, serialized message, set by the Router for
+	// outbound messages on channels that are compressed for the receiving peer.
+	payload []byte
+
+	// result is set by Channel.SendReliable for the Router to report whether
+	// the message was enqueued for the peer.
+	result chan<- error
+}
+
+// deliver reports the outcome of routing an envelope sent with
+// Channel.SendReliable, if it was.
+func (e Envelope) deliver(err error) {
+	if e.result != nil {
+		e.result <- err
+	}
+}
+
+// size returns the number of bytes the envelope takes up on the wire, which is
+// the compressed size of the payload if it has been compressed.
+func (e Envelope) size() int {
+	if e.payload != nil {
+		return len(e.payload)
+	}
+	return proto.Size(e.Message)
 }
 
 // PeerError is a peer error reported via Channel.Error.
@@ -103,6 +129,38 @@ func (c *Channel) Done() <-chan struct{} {
 	return c.closeCh
 }
 
+// SendReliable sends an envelope to a single peer like Out does. But where a
+// message sent on Out is dropped if it can't be passed on to the peer, e.g.
+// when the peer has disconnected, SendReliable returns an error so that the
+// caller can retry with another peer: ErrPeerNotConnected if the peer isn't
+// connected, or ErrPeerQueueFull if the peer's send queue is full. It blocks
+// until the Router has attempted to enqueue the message or the context is
+// canceled. A nil error means the message was enqueued, not that it was
+// received by the peer.
+func (c *Channel) SendReliable(ctx context.Context, envelope Envelope) error {
+	if envelope.Broadcast {
+		return errors.New("broadcast messages can't be sent reliably")
+	}
+
+	result := make(chan error, 1)
+	envelope.result = result
+
+	select {
+	case c.Out <- envelope:
+	case <-c.closeCh:
+		return errors.New("channel closed")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // Wrapper is a Protobuf message that can contain a variety of inner messages
 // (e.g. via oneof fields). If a Channel's message type implements Wrapper, the
 // Router will automatically wrap outbound messages and unwrap inbound messages,
@@ -126,10 +184,27 @@ type RouterOptions struct {
 	// DialTimeout is the timeout for dialing a peer. 0 means no timeout.
 	DialTimeout time.Duration
 
+	// PersistentDialTimeout is the timeout for dialing the peer manager's
+	// persistent peers. 0 means DialTimeout is used.
+	PersistentDialTimeout time.Duration
+
+	// BootstrapDialTimeout is the timeout for dialing BootstrapPeers, unless
+	// they are persistent. 0 means DialTimeout is used.
+	BootstrapDialTimeout time.Duration
+
+	// BootstrapPeers are the peers the node was configured to bootstrap from,
+	// as opposed to peers it discovered.
+	BootstrapPeers []NodeID
+
 	// HandshakeTimeout is the timeout for handshaking with a peer. 0 means
 	// no timeout.
 	HandshakeTimeout time.Duration
 
+	// MaxClockOffset is the maximum difference between a peer's clock and
+	// ours, as measured when handshaking, beyond which the peer is rejected.
+	// 0 means that peers aren't rejected based on their clock.
+	MaxClockOffset time.Duration
+
 	// QueueType must be "wdrr" (Weighed Deficit Round Robin), "priority", or
 	// "fifo". Defaults to "fifo".
 	QueueType string
@@ -143,6 +218,17 @@ type RouterOptions struct {
 	// milliseconds, and cannot be less than 1 millisecond.
 	IncomingConnectionWindow time.Duration
 
+	// MaxIncomingHandshakes limits the number of incoming connections that
+	// can be handshaking at once, across all IP addresses. Connections beyond
+	// it wait up to IncomingHandshakeWait for another handshake to complete,
+	// and are closed otherwise. Established peers don't count towards it.
+	// Defaults to 64.
+	MaxIncomingHandshakes uint
+
+	// IncomingHandshakeWait is how long an incoming connection waits for a
+	// handshake slot, see MaxIncomingHandshakes. Defaults to 1 second.
+	IncomingHandshakeWait time.Duration
+
 	// FilterPeerByIP is used by the router to inject filtering
 	// behavior for new incoming connections. The router passes
 	// the remote IP of the incoming connection the port number as
@@ -199,6 +285,25 @@ func (o *RouterOptions) Validate() error {
 		o.MaxIncomingConnectionAttempts = 100
 	}
 
+	if o.MaxIncomingHandshakes == 0 {
+		o.MaxIncomingHandshakes = 64
+	}
+
+	switch {
+	case o.IncomingHandshakeWait == 0:
+		o.IncomingHandshakeWait = time.Second
+	case o.IncomingHandshakeWait < 0:
+		return errors.New("incoming handshake wait can't be negative")
+	}
+
+	if o.DialTimeout < 0 || o.PersistentDialTimeout < 0 || o.BootstrapDialTimeout < 0 {
+		return errors.New("dial timeouts can't be negative")
+	}
+
+	if o.MaxClockOffset < 0 {
+		return errors.New("max clock offset can't be negative")
+	}
+
 	return nil
 }
 
@@ -218,7 +323,8 @@ func (o *RouterOptions) Validate() error {
 //   begins to route messages if successful.
 //
 //   evictPeers(): in a loop, calls PeerManager.EvictNext() to get the next
-//   peer to evict, and disconnects it by closing its message queue.
+//   peer to evict, and disconnects it by closing its message queue. The peer
+//   is told why it was evicted when its connection is closed.
 //
 // When a peer is connected, an outbound peer message queue is registered in
 // peerQueues, and routePeer() is called to spawn off two additional goroutines:
@@ -253,12 +359,18 @@ type Router struct {
 	chDescs            []ChannelDescriptor
 	transports         []Transport
 	connTracker        connectionTracker
+	handshakeSlots     chan struct{} // in-progress incoming handshakes
 	protocolTransports map[Protocol]Transport
+	bootstrapPeers     map[NodeID]bool
 	stopCh             chan struct{} // signals Router shutdown
 
-	peerMtx      sync.RWMutex
-	peerQueues   map[NodeID]queue // outbound messages per peer for all channels
-	queueFactory func(int) queue
+	peerMtx         sync.RWMutex
+	peerQueues      map[NodeID]queue             // outbound messages per peer for all channels
+	peerCompression map[NodeID]map[ChannelID]int // compressed channels per peer
+	peerBandwidth   map[NodeID]*peerBandwidth    // bytes exchanged per peer and channel
+	peerClockOffset map[NodeID]time.Duration     // measured offset of each peer's clock
+	peerEvictions   map[NodeID]DisconnectReason  // reasons given to peers being evicted
+	queueFactory    func(int) queue
 
 	// FIXME: We don't strictly need to use a mutex for this if we seal the
 	// channels on router start. This depends on whether we want to allow
@@ -266,6 +378,11 @@ type Router struct {
 	channelMtx      sync.RWMutex
 	channelQueues   map[ChannelID]queue // inbound messages from all peers to a single channel
 	channelMessages map[ChannelID]proto.Message
+
+	// compressedChannels maps the channels that we advertise as compressed to
+	// their maximum message size. Channels are only compressed with peers
+	// that we handshake with after they were opened.
+	compressedChannels map[ChannelID]int
 }
 
 // NewRouter creates a new Router. The given Transports must already be
@@ -294,15 +411,22 @@ func NewRouter(
 			options.MaxIncomingConnectionAttempts,
 			options.IncomingConnectionWindow,
 		),
+		handshakeSlots:     make(chan struct{}, options.MaxIncomingHandshakes),
 		chDescs:            make([]ChannelDescriptor, 0),
 		transports:         transports,
 		protocolTransports: map[Protocol]Transport{},
+		bootstrapPeers:     map[NodeID]bool{},
 		peerManager:        peerManager,
 		options:            options,
 		stopCh:             make(chan struct{}),
 		channelQueues:      map[ChannelID]queue{},
 		channelMessages:    map[ChannelID]proto.Message{},
 		peerQueues:         map[NodeID]queue{},
+		peerCompression:    map[NodeID]map[ChannelID]int{},
+		peerBandwidth:      map[NodeID]*peerBandwidth{},
+		peerClockOffset:    map[NodeID]time.Duration{},
+		peerEvictions:      map[NodeID]DisconnectReason{},
+		compressedChannels: map[ChannelID]int{},
 	}
 
 	router.BaseService = service.NewBaseService(logger, "router", router)
@@ -314,6 +438,10 @@ func NewRouter(
 
 	router.queueFactory = qf
 
+	for _, peerID := range options.BootstrapPeers {
+		router.bootstrapPeers[peerID] = true
+	}
+
 	for _, transport := range transports {
 		for _, protocol := range transport.Protocols() {
 			if _, ok := router.protocolTransports[protocol]; !ok {
@@ -377,6 +505,12 @@ func (r *Router) OpenChannel(chDesc ChannelDescriptor, messageType proto.Message
 	}
 	r.chDescs = append(r.chDescs, chDesc)
 
+	// advertise compressed channels to peers we handshake with from now on
+	if chDesc.Compressed {
+		r.compressedChannels[id] = chDesc.FillDefaults().RecvMessageCapacity
+		r.nodeInfo.CompressedChannels = append(r.nodeInfo.CompressedChannels, chDesc.ID)
+	}
+
 	queue := r.queueFactory(size)
 	outCh := make(chan Envelope, size)
 	errCh := make(chan PeerError, size)
@@ -432,42 +566,84 @@ func (r *Router) routeChannel(
 				msg := proto.Clone(wrapper)
 				if err := msg.(Wrapper).Wrap(envelope.Message); err != nil {
 					r.Logger.Error("failed to wrap message", "channel", chID, "err", err)
+					envelope.deliver(err)
 					continue
 				}
 
 				envelope.Message = msg
 			}
 
-			// collect peer queues to pass the message via
-			var queues []queue
+			// collect peer queues to pass the message via, along with whether
+			// the channel is compressed for the peer
+			var (
+				queues   []queue
+				compress []bool
+			)
 			if envelope.Broadcast {
 				r.peerMtx.RLock()
 
 				queues = make([]queue, 0, len(r.peerQueues))
-				for _, q := range r.peerQueues {
+				compress = make([]bool, 0, len(r.peerQueues))
+				for peerID, q := range r.peerQueues {
 					queues = append(queues, q)
+					_, compressed := r.peerCompression[peerID][chID]
+					compress = append(compress, compressed)
 				}
 
 				r.peerMtx.RUnlock()
 			} else {
 				r.peerMtx.RLock()
 				q, ok := r.peerQueues[envelope.To]
+				_, compressed := r.peerCompression[envelope.To][chID]
 				r.peerMtx.RUnlock()
 
 				if !ok {
 					r.logger.Debug("dropping message for unconnected peer", "peer", envelope.To, "channel", chID)
+					envelope.deliver(ErrPeerNotConnected{PeerID: envelope.To})
 					continue
 				}
 
 				queues = []queue{q}
+				compress = []bool{compressed}
 			}
 
-			// send message to peers
-			for _, q := range queues {
+			// send message to peers, compressing it at most once
+			var payload []byte
+			for i, q := range queues {
+				peerEnvelope := envelope
+				if compress[i] {
+					if payload == nil {
+						bz, err := proto.Marshal(envelope.Message)
+						if err != nil {
+							r.logger.Error("failed to marshal message", "channel", chID, "err", err)
+							envelope.deliver(err)
+							continue
+						}
+						payload = snappy.Encode(nil, bz)
+					}
+					peerEnvelope.payload = payload
+				}
+
 				start := time.Now().UTC()
 
+				// reliably sent messages are never waited on to be enqueued,
+				// so that the caller learns right away that the peer can't
+				// keep up
+				if envelope.result != nil {
+					select {
+					case q.enqueue() <- peerEnvelope:
+						r.metrics.RouterPeerQueueSend.Observe(time.Since(start).Seconds())
+						envelope.deliver(nil)
+					case <-q.closed():
+						envelope.deliver(ErrPeerNotConnected{PeerID: envelope.To})
+					default:
+						envelope.deliver(ErrPeerQueueFull{PeerID: envelope.To})
+					}
+					continue
+				}
+
 				select {
-				case q.enqueue() <- envelope:
+				case q.enqueue() <- peerEnvelope:
 					r.metrics.RouterPeerQueueSend.Observe(time.Since(start).Seconds())
 
 				case <-q.closed():
@@ -576,7 +752,14 @@ func (r *Router) openConnection(ctx context.Context, conn Connection) {
 	re := conn.RemoteEndpoint()
 	incomingIP := re.IP
 
+	release, ok := r.acquireHandshakeSlot(ctx)
+	if !ok {
+		r.logger.Debug("too many incoming handshakes, dropping connection", "ip", incomingIP.String())
+		return
+	}
+
 	if err := r.filterPeersIP(ctx, incomingIP, re.Port); err != nil {
+		release()
 		r.logger.Debug("peer filtered by IP", "ip", incomingIP.String(), "err", err)
 		return
 	}
@@ -596,12 +779,14 @@ func (r *Router) openConnection(ctx context.Context, conn Connection) {
 	// The Router should do the handshake and have a final ack/fail
 	// message to make sure both ends have accepted the connection, such
 	// that it can be coordinated with the peer manager.
-	peerInfo, _, err := r.handshakePeer(ctx, conn, "")
+	peerInfo, _, clockOffset, err := r.handshakePeer(ctx, conn, "")
+	release()
 	switch {
 	case errors.Is(err, context.Canceled):
 		return
 	case err != nil:
 		r.logger.Error("peer handshake failed", "endpoint", conn, "err", err)
+		rejectHandshake(conn, err)
 		return
 	}
 
@@ -616,7 +801,25 @@ func (r *Router) openConnection(ctx context.Context, conn Connection) {
 		return
 	}
 
-	r.routePeer(peerInfo.NodeID, conn)
+	r.routePeer(peerInfo.NodeID, conn, NewChannelIDSet(peerInfo.Channels),
+		r.negotiateCompression(peerInfo), clockOffset)
+}
+
+// acquireHandshakeSlot waits up to IncomingHandshakeWait for one of the
+// MaxIncomingHandshakes slots to free up, returning a function to release it,
+// or false if none did.
+func (r *Router) acquireHandshakeSlot(ctx context.Context) (func(), bool) {
+	timer := time.NewTimer(r.options.IncomingHandshakeWait)
+	defer timer.Stop()
+
+	select {
+	case r.handshakeSlots <- struct{}{}:
+		return func() { <-r.handshakeSlots }, true
+	case <-timer.C:
+		return nil, false
+	case <-ctx.Done():
+		return nil, false
+	}
 }
 
 // dialPeers maintains outbound connections to peers by dialing them.
@@ -691,13 +894,14 @@ func (r *Router) connectPeer(ctx context.Context, address NodeAddress) {
 		return
 	}
 
-	_, _, err = r.handshakePeer(ctx, conn, address.NodeID)
+	peerInfo, _, clockOffset, err := r.handshakePeer(ctx, conn, address.NodeID)
 	switch {
 	case errors.Is(err, context.Canceled):
 		conn.Close()
 		return
 	case err != nil:
 		r.logger.Error("failed to handshake with peer", "peer", address, "err", err)
+		rejectHandshake(conn, err)
 		if err = r.peerManager.DialFailed(address); err != nil {
 			r.logger.Error("failed to report dial failure", "peer", address, "err", err)
 		}
@@ -714,7 +918,8 @@ func (r *Router) connectPeer(ctx context.Context, address NodeAddress) {
 	}
 
 	// routePeer (also) calls connection close
-	go r.routePeer(address.NodeID, conn)
+	go r.routePeer(address.NodeID, conn, NewChannelIDSet(peerInfo.Channels),
+		r.negotiateCompression(peerInfo), clockOffset)
 }
 
 func (r *Router) getOrMakeQueue(peerID NodeID) queue {
@@ -756,9 +961,9 @@ func (r *Router) dialPeer(ctx context.Context, address NodeAddress) (Connection,
 		}
 
 		dialCtx := ctx
-		if r.options.DialTimeout > 0 {
+		if timeout := r.dialTimeout(address.NodeID); timeout > 0 {
 			var cancel context.CancelFunc
-			dialCtx, cancel = context.WithTimeout(dialCtx, r.options.DialTimeout)
+			dialCtx, cancel = context.WithTimeout(dialCtx, timeout)
 			defer cancel()
 		}
 
@@ -780,32 +985,99 @@ func (r *Router) dialPeer(ctx context.Context, address NodeAddress) (Connection,
 	return nil, errors.New("all endpoints failed")
 }
 
+// dialTimeout returns the timeout for dialing the given peer, depending on
+// whether it is a persistent, bootstrap or discovered peer.
+func (r *Router) dialTimeout(peerID NodeID) time.Duration {
+	switch {
+	case r.peerManager.IsPersistent(peerID):
+		if r.options.PersistentDialTimeout > 0 {
+			return r.options.PersistentDialTimeout
+		}
+	case r.bootstrapPeers[peerID]:
+		if r.options.BootstrapDialTimeout > 0 {
+			return r.options.BootstrapDialTimeout
+		}
+	}
+	return r.options.DialTimeout
+}
+
 // handshakePeer handshakes with a peer, validating the peer's information. If
-// expectID is given, we check that the peer's info matches it.
-func (r *Router) handshakePeer(ctx context.Context, conn Connection, expectID NodeID) (NodeInfo, crypto.PubKey, error) {
+// expectID is given, we check that the peer's info matches it. It also returns
+// the offset of the peer's clock from ours, i.e. how far ahead of us it is as
+// of the handshake, or 0 if the peer didn't send its time. Peers whose node
+// info isn't compatible with ours, or whose clock is more than MaxClockOffset
+// away from ours, are rejected.
+func (r *Router) handshakePeer(
+	ctx context.Context,
+	conn Connection,
+	expectID NodeID,
+) (NodeInfo, crypto.PubKey, time.Duration, error) {
 	if r.options.HandshakeTimeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, r.options.HandshakeTimeout)
 		defer cancel()
 	}
 
-	peerInfo, peerKey, err := conn.Handshake(ctx, r.nodeInfo, r.privKey)
+	r.channelMtx.RLock()
+	nodeInfo := r.nodeInfo
+	r.channelMtx.RUnlock()
+
+	peerInfo, peerKey, err := conn.Handshake(ctx, nodeInfo, r.privKey)
 	if err != nil {
-		return peerInfo, peerKey, err
+		return peerInfo, peerKey, 0, err
+	}
+	// the offset includes the time it took for the peer's info to reach us,
+	// which errs on the side of the peer being behind
+	var clockOffset time.Duration
+	if !peerInfo.Time.IsZero() {
+		clockOffset = time.Until(peerInfo.Time)
 	}
 
 	if err = peerInfo.Validate(); err != nil {
-		return peerInfo, peerKey, fmt.Errorf("invalid handshake NodeInfo: %w", err)
+		return peerInfo, peerKey, 0, fmt.Errorf("invalid handshake NodeInfo: %w", err)
 	}
 	if NodeIDFromPubKey(peerKey) != peerInfo.NodeID {
-		return peerInfo, peerKey, fmt.Errorf("peer's public key did not match its node ID %q (expected %q)",
+		return peerInfo, peerKey, 0, fmt.Errorf("peer's public key did not match its node ID %q (expected %q)",
 			peerInfo.NodeID, NodeIDFromPubKey(peerKey))
 	}
 	if expectID != "" && expectID != peerInfo.NodeID {
-		return peerInfo, peerKey, fmt.Errorf("expected to connect with peer %q, got %q",
+		return peerInfo, peerKey, 0, fmt.Errorf("expected to connect with peer %q, got %q",
 			expectID, peerInfo.NodeID)
 	}
-	return peerInfo, peerKey, nil
+	if err = nodeInfo.CompatibleWith(peerInfo); err != nil {
+		return peerInfo, peerKey, clockOffset, ErrPeerIncompatible{Err: err}
+	}
+	if max := r.options.MaxClockOffset; max > 0 && (clockOffset > max || clockOffset < -max) {
+		return peerInfo, peerKey, clockOffset, ErrPeerClockOffset{Offset: clockOffset, MaxOffset: max}
+	}
+	return peerInfo, peerKey, clockOffset, nil
+}
+
+// rejectHandshake tells a peer that it was rejected as incompatible when
+// handshaking with it, if it was, by closing the connection with that reason.
+// Peers are incompatible if their node info doesn't match ours, or their clock
+// is too far from ours.
+func rejectHandshake(conn Connection, err error) {
+	if errors.As(err, &ErrPeerIncompatible{}) || errors.As(err, &ErrPeerClockOffset{}) {
+		_ = conn.CloseWithReason(DisconnectReasonIncompatible)
+	}
+}
+
+// negotiateCompression returns the channels that are compressed with the
+// given peer, which are the ones that both we and the peer advertise as
+// compressed, mapped to their maximum message size. Peers that don't know
+// about compression advertise none.
+func (r *Router) negotiateCompression(peerInfo NodeInfo) map[ChannelID]int {
+	r.channelMtx.RLock()
+	defer r.channelMtx.RUnlock()
+
+	compressed := make(map[ChannelID]int)
+	for _, ch := range peerInfo.CompressedChannels {
+		if maxSize, ok := r.compressedChannels[ChannelID(ch)]; ok {
+			compressed[ChannelID(ch)] = maxSize
+		}
+	}
+	return compressed
 }
 
 func (r *Router) runWithPeerMutex(fn func() error) error {
@@ -817,14 +1089,33 @@ func (r *Router) runWithPeerMutex(fn func() error) error {
 // routePeer routes inbound and outbound messages between a peer and the reactor
 // channels. It will close the given connection and send queue when done, or if
 // they are closed elsewhere it will cause this method to shut down and return.
-func (r *Router) routePeer(peerID NodeID, conn Connection) {
+// Messages on the compressed channels are compressed and decompressed.
+func (r *Router) routePeer(
+	peerID NodeID,
+	conn Connection,
+	channels ChannelIDSet,
+	compressed map[ChannelID]int,
+	clockOffset time.Duration,
+) {
 	r.metrics.Peers.Add(1)
-	r.peerManager.Ready(peerID)
+	r.metrics.PeerClockOffset.With("peer_id", string(peerID)).Set(clockOffset.Seconds())
+	r.peerManager.Ready(peerID, channels)
+
+	bandwidth := newPeerBandwidth()
+	r.peerMtx.Lock()
+	r.peerCompression[peerID] = compressed
+	r.peerBandwidth[peerID] = bandwidth
+	r.peerClockOffset[peerID] = clockOffset
+	r.peerMtx.Unlock()
 
 	sendQueue := r.getOrMakeQueue(peerID)
 	defer func() {
 		r.peerMtx.Lock()
 		delete(r.peerQueues, peerID)
+		delete(r.peerCompression, peerID)
+		delete(r.peerBandwidth, peerID)
+		delete(r.peerClockOffset, peerID)
+		delete(r.peerEvictions, peerID)
 		r.peerMtx.Unlock()
 
 		sendQueue.close()
@@ -838,40 +1129,92 @@ func (r *Router) routePeer(peerID NodeID, conn Connection) {
 	errCh := make(chan error, 2)
 
 	go func() {
-		errCh <- r.receivePeer(peerID, conn)
+		errCh <- r.receivePeer(peerID, conn, compressed, newBandwidthCache(peerID, bandwidth, r.metrics))
 	}()
 
 	go func() {
-		errCh <- r.sendPeer(peerID, conn, sendQueue)
+		errCh <- r.sendPeer(peerID, conn, sendQueue, newBandwidthCache(peerID, bandwidth, r.metrics))
 	}()
 
 	err := <-errCh
-	_ = conn.Close()
+	r.closePeer(peerID, conn)
 	sendQueue.close()
 
-	if e := <-errCh; err == nil {
-		// The first err was nil, so we update it with the second err, which may
-		// or may not be nil.
+	if e := <-errCh; err == nil || err == io.EOF {
+		// The first err was nil or EOF, so we update it with the second err,
+		// which may or may not be nil, but may tell why the peer disconnected.
 		err = e
 	}
 
-	switch err {
-	case nil, io.EOF:
+	var disconnected ErrDisconnected
+	switch {
+	case err == nil, err == io.EOF:
 		r.logger.Info("peer disconnected", "peer", peerID, "endpoint", conn)
 
+	case errors.As(err, &disconnected):
+		r.logger.Info("peer disconnected", "peer", peerID, "endpoint", conn, "reason", disconnected.Reason)
+		if err := r.peerManager.RemoteDisconnected(peerID, disconnected.Reason); err != nil {
+			r.logger.Error("failed to report peer disconnect", "peer", peerID, "err", err)
+		}
+
 	default:
 		r.logger.Error("peer failure", "peer", peerID, "endpoint", conn, "err", err)
 	}
 }
 
+// closePeer closes the connection to a peer, telling the peer why if it's
+// closed deliberately, i.e. because the peer is evicted or we're shutting down.
+func (r *Router) closePeer(peerID NodeID, conn Connection) {
+	r.peerMtx.RLock()
+	reason, ok := r.peerEvictions[peerID]
+	r.peerMtx.RUnlock()
+
+	select {
+	case <-r.stopCh:
+		reason, ok = DisconnectReasonShuttingDown, true
+	default:
+	}
+
+	if ok {
+		_ = conn.CloseWithReason(reason)
+	} else {
+		_ = conn.Close()
+	}
+}
+
 // receivePeer receives inbound messages from a peer, deserializes them and
-// passes them on to the appropriate channel.
-func (r *Router) receivePeer(peerID NodeID, conn Connection) error {
+// passes them on to the appropriate channel. Messages on compressed channels
+// are decompressed first. The bytes received on known channels are counted.
+func (r *Router) receivePeer(
+	peerID NodeID,
+	conn Connection,
+	compressed map[ChannelID]int,
+	bandwidth *bandwidthCache,
+) error {
 	for {
 		chID, bz, err := conn.ReceiveMessage()
 		if err != nil {
+			var tooLarge ErrMessageTooLarge
+			if errors.As(err, &tooLarge) {
+				r.metrics.RouterChannelRecvMsgTooLarge.With("ch_id", fmt.Sprint(tooLarge.ChannelID)).Add(1)
+			}
 			return err
 		}
+		size := len(bz)
+
+		if maxSize, ok := compressed[chID]; ok {
+			bz, err = decompress(chID, bz, maxSize)
+			if err != nil {
+				var tooLarge ErrMessageTooLarge
+				if errors.As(err, &tooLarge) {
+					r.metrics.RouterChannelRecvMsgTooLarge.With("ch_id", fmt.Sprint(chID)).Add(1)
+				}
+				// honest peers don't send undecodable or oversized messages
+				r.logger.Error("message decompression failed, evicting peer", "peer", peerID, "err", err)
+				r.peerManager.Errored(peerID, err)
+				continue
+			}
+		}
 
 		r.channelMtx.RLock()
 		queue, ok := r.channelQueues[chID]
@@ -882,6 +1225,8 @@ func (r *Router) receivePeer(peerID NodeID, conn Connection) error {
 			r.logger.Debug("dropping message for unknown channel", "peer", peerID, "channel", chID)
 			continue
 		}
+		bandwidth.addReceived(chID, size)
+		r.metrics.RouterChannelRecvMsgSize.With("ch_id", fmt.Sprint(chID)).Observe(float64(size))
 
 		msg := proto.Clone(messageType)
 		if err := proto.Unmarshal(bz, msg); err != nil {
@@ -916,8 +1261,8 @@ func (r *Router) receivePeer(peerID NodeID, conn Connection) error {
 	}
 }
 
-// sendPeer sends queued messages to a peer.
-func (r *Router) sendPeer(peerID NodeID, conn Connection, peerQueue queue) error {
+// sendPeer sends queued messages to a peer, counting the bytes sent.
+func (r *Router) sendPeer(peerID NodeID, conn Connection, peerQueue queue, bandwidth *bandwidthCache) error {
 	for {
 		start := time.Now().UTC()
 
@@ -929,16 +1274,21 @@ func (r *Router) sendPeer(peerID NodeID, conn Connection, peerQueue queue) error
 				continue
 			}
 
-			bz, err := proto.Marshal(envelope.Message)
-			if err != nil {
-				r.logger.Error("failed to marshal message", "peer", peerID, "err", err)
-				continue
+			bz := envelope.payload
+			if bz == nil {
+				var err error
+				bz, err = proto.Marshal(envelope.Message)
+				if err != nil {
+					r.logger.Error("failed to marshal message", "peer", peerID, "err", err)
+					continue
+				}
 			}
 
-			_, err = conn.SendMessage(envelope.channelID, bz)
+			_, err := conn.SendMessage(envelope.channelID, bz)
 			if err != nil {
 				return err
 			}
+			bandwidth.addSent(envelope.channelID, len(bz))
 
 			r.logger.Debug("sent message", "peer", envelope.To, "message", envelope.Message)
 
@@ -951,13 +1301,27 @@ func (r *Router) sendPeer(peerID NodeID, conn Connection, peerQueue queue) error
 	}
 }
 
+// decompress decompresses a message received on the given compressed channel,
+// refusing to do so with ErrMessageTooLarge if the decompressed message would
+// exceed maxSize.
+func decompress(chID ChannelID, bz []byte, maxSize int) ([]byte, error) {
+	size, err := snappy.DecodedLen(bz)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxSize {
+		return nil, ErrMessageTooLarge{ChannelID: chID, Size: size, Capacity: maxSize}
+	}
+	return snappy.Decode(nil, bz)
+}
+
 // evictPeers evicts connected peers as requested by the peer manager.
 func (r *Router) evictPeers() {
 	r.logger.Debug("starting evict routine")
 	ctx := r.stopCtx()
 
 	for {
-		peerID, err := r.peerManager.EvictNext(ctx)
+		peerID, reason, err := r.peerManager.evictNext(ctx)
 
 		switch {
 		case errors.Is(err, context.Canceled):
@@ -969,11 +1333,14 @@ func (r *Router) evictPeers() {
 			return
 		}
 
-		r.logger.Info("evicting peer", "peer", peerID)
+		r.logger.Info("evicting peer", "peer", peerID, "reason", reason)
 
-		r.peerMtx.RLock()
+		r.peerMtx.Lock()
 		queue, ok := r.peerQueues[peerID]
-		r.peerMtx.RUnlock()
+		if ok {
+			r.peerEvictions[peerID] = reason
+		}
+		r.peerMtx.Unlock()
 
 		if ok {
 			queue.close()
@@ -981,6 +1348,33 @@ func (r *Router) evictPeers() {
 	}
 }
 
+// PeerBandwidth returns the number of bytes sent to and received from each
+// connected peer, per channel. The counts are reset when a peer reconnects.
+func (r *Router) PeerBandwidth() map[NodeID]map[ChannelID]Bandwidth {
+	r.peerMtx.RLock()
+	defer r.peerMtx.RUnlock()
+
+	bandwidth := make(map[NodeID]map[ChannelID]Bandwidth, len(r.peerBandwidth))
+	for peerID, b := range r.peerBandwidth {
+		bandwidth[peerID] = b.bandwidth()
+	}
+	return bandwidth
+}
+
+// PeerClockOffsets returns how far ahead of ours the clock of each connected
+// peer was when handshaking with it, which is negative for peers that are
+// behind, and 0 for peers that didn't send their time.
+func (r *Router) PeerClockOffsets() map[NodeID]time.Duration {
+	r.peerMtx.RLock()
+	defer r.peerMtx.RUnlock()
+
+	offsets := make(map[NodeID]time.Duration, len(r.peerClockOffset))
+	for peerID, offset := range r.peerClockOffset {
+		offsets[peerID] = offset
+	}
+	return offsets
+}
+
 // OnStart implements service.Service.
 func (r *Router) OnStart() error {
 	go r.dialPeers()
@@ -19,9 +19,11 @@ func TestConnectionFiltering(t *testing.T) {
 
 	filterByIPCount := 0
 	router := &Router{
-		logger:      logger,
-		connTracker: newConnTracker(1, time.Second),
+		logger:         logger,
+		connTracker:    newConnTracker(1, time.Second),
+		handshakeSlots: make(chan struct{}, 1),
 		options: RouterOptions{
+			IncomingHandshakeWait: time.Second,
 			FilterPeerByIP: func(ctx context.Context, ip net.IP, port uint16) error {
 				filterByIPCount++
 				return errors.New("mock")
@@ -0,0 +1,71 @@
+package p2p
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+// handshakeConnection is a Connection whose handshake returns the given peer
+// info and key.
+type handshakeConnection struct {
+	Connection
+	peerInfo NodeInfo
+	peerKey  crypto.PubKey
+}
+
+func (c *handshakeConnection) Handshake(context.Context, NodeInfo, crypto.PrivKey) (NodeInfo, crypto.PubKey, error) {
+	return c.peerInfo, c.peerKey, nil
+}
+
+func TestRouter_HandshakeClockOffset(t *testing.T) {
+	peerKey := ed25519.GenPrivKey()
+	peerID := NodeIDFromPubKey(peerKey.PubKey())
+
+	router, err := NewRouter(log.TestingLogger(), NopMetrics(), NodeInfo{}, ed25519.GenPrivKey(), nil, nil,
+		RouterOptions{MaxClockOffset: time.Minute})
+	require.NoError(t, err)
+
+	handshake := func(peerTime time.Time) (time.Duration, error) {
+		_, _, offset, err := router.handshakePeer(context.Background(), &handshakeConnection{
+			peerInfo: NodeInfo{NodeID: peerID, ListenAddr: "0.0.0.0:0", Moniker: "peer", Time: peerTime},
+			peerKey:  peerKey.PubKey(),
+		}, peerID)
+		return offset, err
+	}
+
+	// a peer within tolerance is accepted, either way
+	for _, skew := range []time.Duration{0, 30 * time.Second, -30 * time.Second} {
+		offset, err := handshake(time.Now().Add(skew))
+		require.NoError(t, err)
+		require.InDelta(t, skew, offset, float64(time.Second))
+	}
+
+	// as is a peer that doesn't send its time
+	offset, err := handshake(time.Time{})
+	require.NoError(t, err)
+	require.Zero(t, offset)
+
+	// but not a peer whose clock is way off, either way
+	for _, skew := range []time.Duration{time.Hour, -time.Hour} {
+		offset, err := handshake(time.Now().Add(skew))
+		var offsetErr ErrPeerClockOffset
+		require.True(t, errors.As(err, &offsetErr), err)
+		require.InDelta(t, skew, offsetErr.Offset, float64(time.Second))
+		require.Equal(t, time.Minute, offsetErr.MaxOffset)
+		require.Equal(t, offsetErr.Offset, offset)
+	}
+
+	// unless the check is disabled
+	router.options.MaxClockOffset = 0
+	offset, err = handshake(time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.InDelta(t, time.Hour, offset, float64(time.Second))
+}
@@ -5,15 +5,20 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/fortytw2/leaktest"
+	"github.com/go-kit/kit/metrics"
 	"github.com/gogo/protobuf/proto"
 	gogotypes "github.com/gogo/protobuf/types"
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	dbm "github.com/tendermint/tm-db"
@@ -21,6 +26,7 @@ import (
 	"github.com/tendermint/tendermint/crypto"
 	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
 	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/internal/p2p/conn"
 	"github.com/tendermint/tendermint/internal/p2p/mocks"
 	"github.com/tendermint/tendermint/internal/p2p/p2ptest"
 	"github.com/tendermint/tendermint/libs/log"
@@ -237,6 +243,328 @@ func TestRouter_Channel_Broadcast(t *testing.T) {
 	p2ptest.RequireEmpty(t, a, b, c, d)
 }
 
+func TestRouter_PeerBandwidth(t *testing.T) {
+	t.Cleanup(leaktest.Check(t))
+
+	// Create a test network, and open two channels on both nodes.
+	network := p2ptest.MakeNetwork(t, p2ptest.NetworkOptions{NumNodes: 2})
+	network.Start(t)
+
+	ids := network.NodeIDs()
+	aID, bID := ids[0], ids[1]
+	otherDesc := p2ptest.MakeChannelDesc(9)
+	channels := network.MakeChannels(t, chDesc, &p2ptest.Message{}, 0)
+	otherChannels := network.MakeChannels(t, otherDesc, &p2ptest.Message{}, 0)
+
+	// a sends two messages to b on the first channel, and one on the other.
+	// b sends one back on the other channel.
+	foo := &p2ptest.Message{Value: "foo"}
+	hello := &p2ptest.Message{Value: "hello world"}
+	bar := &p2ptest.Message{Value: "barbaz"}
+	for i := 0; i < 2; i++ {
+		p2ptest.RequireSend(t, channels[aID], p2p.Envelope{To: bID, Message: foo})
+		p2ptest.RequireReceive(t, channels[bID], p2p.Envelope{From: aID, Message: foo})
+	}
+	p2ptest.RequireSend(t, otherChannels[aID], p2p.Envelope{To: bID, Message: hello})
+	p2ptest.RequireReceive(t, otherChannels[bID], p2p.Envelope{From: aID, Message: hello})
+	p2ptest.RequireSend(t, otherChannels[bID], p2p.Envelope{To: aID, Message: bar})
+	p2ptest.RequireReceive(t, otherChannels[aID], p2p.Envelope{From: bID, Message: bar})
+
+	chID, otherID := p2p.ChannelID(chDesc.ID), p2p.ChannelID(otherDesc.ID)
+	expectA := map[p2p.NodeID]map[p2p.ChannelID]p2p.Bandwidth{
+		bID: {
+			chID:    {SentBytes: uint64(2 * proto.Size(foo))},
+			otherID: {SentBytes: uint64(proto.Size(hello)), ReceivedBytes: uint64(proto.Size(bar))},
+		},
+	}
+	expectB := map[p2p.NodeID]map[p2p.ChannelID]p2p.Bandwidth{
+		aID: {
+			chID:    {ReceivedBytes: uint64(2 * proto.Size(foo))},
+			otherID: {SentBytes: uint64(proto.Size(bar)), ReceivedBytes: uint64(proto.Size(hello))},
+		},
+	}
+
+	// The sender counts a message once it has been sent, which may be after it
+	// was received.
+	require.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual(expectA, network.Nodes[aID].Router.PeerBandwidth()) &&
+			assert.ObjectsAreEqual(expectB, network.Nodes[bID].Router.PeerBandwidth())
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, expectA, network.Nodes[aID].Router.PeerBandwidth())
+	require.Equal(t, expectB, network.Nodes[bID].Router.PeerBandwidth())
+}
+
+func TestRouter_Channel_Compressed(t *testing.T) {
+	t.Cleanup(leaktest.Check(t))
+
+	// Open a compressed channel on all nodes before connecting them, such that
+	// compression is negotiated during the handshake.
+	network := p2ptest.MakeNetwork(t, p2ptest.NetworkOptions{NumNodes: 3})
+	compressedDesc := chDesc
+	compressedDesc.Compressed = true
+	compressedDesc.RecvMessageCapacity = 1 << 20
+	channels := network.MakeChannels(t, compressedDesc, &p2ptest.Message{}, 0)
+	network.Start(t)
+
+	ids := network.NodeIDs()
+	aID, bID, cID := ids[0], ids[1], ids[2]
+	a, b, c := channels[aID], channels[bID], channels[cID]
+
+	// A large payload should arrive intact, both when sent directly and when
+	// broadcast.
+	value := strings.Repeat("compressible ", 1<<14)
+	p2ptest.RequireSend(t, a, p2p.Envelope{To: bID, Message: &p2ptest.Message{Value: value}})
+	p2ptest.RequireReceive(t, b, p2p.Envelope{From: aID, Message: &p2ptest.Message{Value: value}})
+	p2ptest.RequireEmpty(t, a, b, c)
+
+	p2ptest.RequireSend(t, c, p2p.Envelope{Broadcast: true, Message: &p2ptest.Message{Value: value}})
+	p2ptest.RequireReceive(t, a, p2p.Envelope{From: cID, Message: &p2ptest.Message{Value: value}})
+	p2ptest.RequireReceive(t, b, p2p.Envelope{From: cID, Message: &p2ptest.Message{Value: value}})
+	p2ptest.RequireEmpty(t, a, b, c)
+}
+
+func TestRouter_Channel_CompressedMixed(t *testing.T) {
+	t.Cleanup(leaktest.Check(t))
+
+	// Only a compresses the channel, so the peers must not compress it at all.
+	network := p2ptest.MakeNetwork(t, p2ptest.NetworkOptions{NumNodes: 2})
+	ids := network.NodeIDs()
+	aID, bID := ids[0], ids[1]
+
+	compressedDesc := chDesc
+	compressedDesc.Compressed = true
+	compressedDesc.RecvMessageCapacity = 1 << 20
+	plainDesc := chDesc
+	plainDesc.RecvMessageCapacity = 1 << 20
+	a := network.Nodes[aID].MakeChannel(t, compressedDesc, &p2ptest.Message{}, 0)
+	b := network.Nodes[bID].MakeChannel(t, plainDesc, &p2ptest.Message{}, 0)
+	network.Start(t)
+
+	value := strings.Repeat("compressible ", 1<<14)
+	p2ptest.RequireSend(t, a, p2p.Envelope{To: bID, Message: &p2ptest.Message{Value: value}})
+	p2ptest.RequireReceive(t, b, p2p.Envelope{From: aID, Message: &p2ptest.Message{Value: value}})
+	p2ptest.RequireSend(t, b, p2p.Envelope{To: aID, Message: &p2ptest.Message{Value: value}})
+	p2ptest.RequireReceive(t, a, p2p.Envelope{From: bID, Message: &p2ptest.Message{Value: value}})
+	p2ptest.RequireEmpty(t, a, b)
+}
+
+func TestRouter_Channel_CompressedWire(t *testing.T) {
+	t.Cleanup(leaktest.Check(t))
+
+	// Set up a router with a compressed channel, and a raw peer connection
+	// that advertises the channel as compressed as well.
+	memoryNetwork := p2p.NewMemoryNetwork(log.TestingLogger(), 1)
+	transport := memoryNetwork.CreateTransport(selfID)
+	defer transport.Close()
+	peerTransport := memoryNetwork.CreateTransport(peerID)
+	defer peerTransport.Close()
+
+	tooLarge := labeledCounter{newLabeledMetric()}
+	metrics := p2p.NopMetrics()
+	metrics.RouterChannelRecvMsgTooLarge = tooLarge
+
+	peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	defer peerManager.Close()
+
+	router, err := p2p.NewRouter(
+		log.TestingLogger(),
+		metrics,
+		selfInfo,
+		selfKey,
+		peerManager,
+		[]p2p.Transport{transport},
+		p2p.RouterOptions{},
+	)
+	require.NoError(t, err)
+
+	compressedDesc := chDesc
+	compressedDesc.Compressed = true
+	compressedDesc.RecvMessageCapacity = 1 << 20
+	channel, err := router.OpenChannel(compressedDesc, &p2ptest.Message{}, 0)
+	require.NoError(t, err)
+	defer channel.Close()
+
+	require.NoError(t, router.Start())
+	defer func() {
+		require.NoError(t, router.Stop())
+	}()
+
+	sub := peerManager.Subscribe()
+	defer sub.Close()
+
+	conn, err := peerTransport.Dial(ctx, transport.Endpoints()[0])
+	require.NoError(t, err)
+	defer conn.Close()
+
+	compressedInfo := peerInfo
+	compressedInfo.CompressedChannels = []byte{byte(chID)}
+	info, _, err := conn.Handshake(ctx, compressedInfo, peerKey)
+	require.NoError(t, err)
+	require.Equal(t, []byte{byte(chID)}, []byte(info.CompressedChannels))
+
+	p2ptest.RequireUpdate(t, sub, p2p.PeerUpdate{NodeID: peerID, Status: p2p.PeerStatusUp})
+
+	// Messages sent by the router should be compressed on the wire.
+	msg := &p2ptest.Message{Value: strings.Repeat("compressible ", 1<<14)}
+	bz, err := proto.Marshal(msg)
+	require.NoError(t, err)
+
+	p2ptest.RequireSend(t, channel, p2p.Envelope{To: peerID, Message: msg})
+	recvID, recvBz, err := conn.ReceiveMessage()
+	require.NoError(t, err)
+	require.Equal(t, chID, recvID)
+	require.Less(t, len(recvBz), len(bz))
+	decoded, err := snappy.Decode(nil, recvBz)
+	require.NoError(t, err)
+	require.Equal(t, bz, decoded)
+
+	// Compressed messages sent by the peer should be decompressed by the router.
+	_, err = conn.SendMessage(chID, snappy.Encode(nil, bz))
+	require.NoError(t, err)
+	p2ptest.RequireReceive(t, channel, p2p.Envelope{From: peerID, Message: msg})
+
+	// Messages that decompress beyond the channel's maximum size are dropped,
+	// counted as too large, and the peer is evicted.
+	require.Empty(t, tooLarge.values("ch_id", fmt.Sprint(chID)))
+	oversized, err := proto.Marshal(&p2ptest.Message{Value: strings.Repeat("x", 1<<21)})
+	require.NoError(t, err)
+	_, err = conn.SendMessage(chID, snappy.Encode(nil, oversized))
+	require.NoError(t, err)
+	p2ptest.RequireEmpty(t, channel)
+	require.Eventually(t, func() bool {
+		return len(tooLarge.values("ch_id", fmt.Sprint(chID))) == 1
+	}, time.Second, 10*time.Millisecond)
+	p2ptest.RequireUpdate(t, sub, p2p.PeerUpdate{NodeID: peerID, Status: p2p.PeerStatusDown})
+}
+
+// labeledMetric records the values of a metric by label values.
+type labeledMetric struct {
+	mtx      *sync.Mutex
+	lvs      []string
+	recorded map[string][]float64
+}
+
+func newLabeledMetric() labeledMetric {
+	return labeledMetric{mtx: &sync.Mutex{}, recorded: map[string][]float64{}}
+}
+
+func (m labeledMetric) with(labelValues ...string) labeledMetric {
+	m.lvs = append(m.lvs[:len(m.lvs):len(m.lvs)], labelValues...)
+	return m
+}
+
+func (m labeledMetric) record(value float64) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	key := strings.Join(m.lvs, ",")
+	m.recorded[key] = append(m.recorded[key], value)
+}
+
+// values returns the values recorded with the given label values.
+func (m labeledMetric) values(labelValues ...string) []float64 {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.recorded[strings.Join(labelValues, ",")]
+}
+
+type labeledHistogram struct{ labeledMetric }
+
+func (h labeledHistogram) With(labelValues ...string) metrics.Histogram {
+	return labeledHistogram{h.with(labelValues...)}
+}
+
+func (h labeledHistogram) Observe(value float64) { h.record(value) }
+
+type labeledCounter struct{ labeledMetric }
+
+func (c labeledCounter) With(labelValues ...string) metrics.Counter {
+	return labeledCounter{c.with(labelValues...)}
+}
+
+func (c labeledCounter) Add(delta float64) { c.record(delta) }
+
+func TestRouter_ReceiveMessageSizes(t *testing.T) {
+	t.Cleanup(leaktest.Check(t))
+
+	// Set up a router on an MConnection transport, which rejects messages
+	// exceeding the channel's receive capacity, and a raw peer connection.
+	chDescs := []*p2p.ChannelDescriptor{&chDesc}
+	transport := p2p.NewMConnTransport(log.TestingLogger(), conn.DefaultMConnConfig(), chDescs,
+		p2p.MConnTransportOptions{})
+	require.NoError(t, transport.Listen(p2p.Endpoint{Protocol: p2p.MConnProtocol, IP: net.IPv4(127, 0, 0, 1)}))
+	defer transport.Close()
+	peerTransport := p2p.NewMConnTransport(log.TestingLogger(), conn.DefaultMConnConfig(), chDescs,
+		p2p.MConnTransportOptions{})
+	defer peerTransport.Close()
+
+	sizes := labeledHistogram{newLabeledMetric()}
+	tooLarge := labeledCounter{newLabeledMetric()}
+	metrics := p2p.NopMetrics()
+	metrics.RouterChannelRecvMsgSize = sizes
+	metrics.RouterChannelRecvMsgTooLarge = tooLarge
+
+	peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	defer peerManager.Close()
+
+	router, err := p2p.NewRouter(
+		log.TestingLogger(),
+		metrics,
+		selfInfo,
+		selfKey,
+		peerManager,
+		[]p2p.Transport{transport},
+		p2p.RouterOptions{},
+	)
+	require.NoError(t, err)
+
+	channel, err := router.OpenChannel(chDesc, &p2ptest.Message{}, 0)
+	require.NoError(t, err)
+	defer channel.Close()
+
+	require.NoError(t, router.Start())
+	defer func() {
+		require.NoError(t, router.Stop())
+	}()
+
+	sub := peerManager.Subscribe()
+	defer sub.Close()
+
+	peerConn, err := peerTransport.Dial(ctx, transport.Endpoints()[0])
+	require.NoError(t, err)
+	defer peerConn.Close()
+	_, _, err = peerConn.Handshake(ctx, peerInfo, peerKey)
+	require.NoError(t, err)
+
+	p2ptest.RequireUpdate(t, sub, p2p.PeerUpdate{NodeID: peerID, Status: p2p.PeerStatusUp})
+
+	// The sizes of the messages up to the capacity of 10 bytes are recorded
+	// as they went over the wire.
+	for _, value := range []string{"a", "abcdefgh"} {
+		msg := &p2ptest.Message{Value: value}
+		bz, err := proto.Marshal(msg)
+		require.NoError(t, err)
+		_, err = peerConn.SendMessage(chID, bz)
+		require.NoError(t, err)
+		p2ptest.RequireReceive(t, channel, p2p.Envelope{From: peerID, Message: msg})
+	}
+	require.Equal(t, []float64{3, 10}, sizes.values("ch_id", fmt.Sprint(chID)))
+	require.Empty(t, tooLarge.values("ch_id", fmt.Sprint(chID)))
+
+	// A larger message is rejected, which disconnects the peer.
+	bz, err := proto.Marshal(&p2ptest.Message{Value: "abcdefghi"})
+	require.NoError(t, err)
+	require.Len(t, bz, 11)
+	_, err = peerConn.SendMessage(chID, bz)
+	require.NoError(t, err)
+
+	p2ptest.RequireUpdate(t, sub, p2p.PeerUpdate{NodeID: peerID, Status: p2p.PeerStatusDown})
+	require.Equal(t, []float64{1}, tooLarge.values("ch_id", fmt.Sprint(chID)))
+	require.Equal(t, []float64{3, 10}, sizes.values("ch_id", fmt.Sprint(chID)))
+	p2ptest.RequireEmpty(t, channel)
+}
+
 func TestRouter_Channel_Wrapper(t *testing.T) {
 	t.Cleanup(leaktest.Check(t))
 
@@ -397,6 +725,159 @@ func TestRouter_AcceptPeers(t *testing.T) {
 	}
 }
 
+func TestRouter_AcceptPeers_ClockOffset(t *testing.T) {
+	testcases := map[string]struct {
+		skew time.Duration
+		ok   bool
+	}{
+		"within tolerance": {30 * time.Second, true},
+		"ahead":            {time.Hour, false},
+		"behind":           {-time.Hour, false},
+	}
+	for name, tc := range testcases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Cleanup(leaktest.Check(t))
+
+			// Set up a mock transport that handshakes with a peer whose
+			// clock is skewed.
+			skewedInfo := peerInfo
+			skewedInfo.Time = time.Now().Add(tc.skew)
+
+			closer := tmsync.NewCloser()
+			mockConnection := &mocks.Connection{}
+			mockConnection.On("String").Maybe().Return("mock")
+			mockConnection.On("Handshake", mock.Anything, selfInfo, selfKey).
+				Return(skewedInfo, peerKey.PubKey(), nil)
+			mockConnection.On("Close").Maybe().Return(nil)
+			mockConnection.On("RemoteEndpoint").Return(p2p.Endpoint{})
+			if tc.ok {
+				mockConnection.On("ReceiveMessage").Maybe().Run(func(_ mock.Arguments) {
+					<-closer.Done()
+				}).Return(chID, nil, io.EOF)
+				mockConnection.On("CloseWithReason", p2p.DisconnectReasonShuttingDown).
+					Run(func(_ mock.Arguments) { closer.Close() }).Return(nil)
+			} else {
+				// The peer is told that it's rejected as incompatible.
+				mockConnection.On("CloseWithReason", p2p.DisconnectReasonIncompatible).
+					Run(func(_ mock.Arguments) { closer.Close() }).Return(nil)
+			}
+
+			mockTransport := &mocks.Transport{}
+			mockTransport.On("String").Maybe().Return("mock")
+			mockTransport.On("Protocols").Return([]p2p.Protocol{"mock"})
+			mockTransport.On("Close").Return(nil)
+			mockTransport.On("Accept").Once().Return(mockConnection, nil)
+			mockTransport.On("Accept").Once().Return(nil, io.EOF)
+
+			// Set up and start the router.
+			peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{})
+			require.NoError(t, err)
+			defer peerManager.Close()
+
+			sub := peerManager.Subscribe()
+			defer sub.Close()
+
+			router, err := p2p.NewRouter(
+				log.TestingLogger(),
+				p2p.NopMetrics(),
+				selfInfo,
+				selfKey,
+				peerManager,
+				[]p2p.Transport{mockTransport},
+				p2p.RouterOptions{MaxClockOffset: time.Minute},
+			)
+			require.NoError(t, err)
+			require.NoError(t, router.Start())
+
+			if tc.ok {
+				p2ptest.RequireUpdate(t, sub, p2p.PeerUpdate{
+					NodeID: peerInfo.NodeID,
+					Status: p2p.PeerStatusUp,
+				})
+				offsets := router.PeerClockOffsets()
+				require.Len(t, offsets, 1)
+				require.InDelta(t, tc.skew, offsets[peerInfo.NodeID], float64(time.Second))
+			} else {
+				select {
+				case <-closer.Done():
+				case <-time.After(100 * time.Millisecond):
+					require.Fail(t, "connection not closed")
+				}
+				require.Empty(t, router.PeerClockOffsets())
+			}
+
+			require.NoError(t, router.Stop())
+			mockTransport.AssertExpectations(t)
+			mockConnection.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRouter_AcceptPeers_Incompatible(t *testing.T) {
+	otherNetwork := peerInfo
+	otherNetwork.Network = "other"
+	otherBlock := peerInfo
+	otherBlock.ProtocolVersion.Block = selfInfo.ProtocolVersion.Block + 1
+
+	testcases := map[string]p2p.NodeInfo{
+		"network":       otherNetwork,
+		"block version": otherBlock,
+	}
+	for name, info := range testcases {
+		info := info
+		t.Run(name, func(t *testing.T) {
+			t.Cleanup(leaktest.Check(t))
+
+			// Set up a mock transport that handshakes with an incompatible
+			// peer, which is told that it's rejected as incompatible.
+			closer := tmsync.NewCloser()
+			mockConnection := &mocks.Connection{}
+			mockConnection.On("String").Maybe().Return("mock")
+			mockConnection.On("Handshake", mock.Anything, selfInfo, selfKey).
+				Return(info, peerKey.PubKey(), nil)
+			mockConnection.On("Close").Maybe().Return(nil)
+			mockConnection.On("RemoteEndpoint").Return(p2p.Endpoint{})
+			mockConnection.On("CloseWithReason", p2p.DisconnectReasonIncompatible).
+				Run(func(_ mock.Arguments) { closer.Close() }).Return(nil)
+
+			mockTransport := &mocks.Transport{}
+			mockTransport.On("String").Maybe().Return("mock")
+			mockTransport.On("Protocols").Return([]p2p.Protocol{"mock"})
+			mockTransport.On("Close").Return(nil)
+			mockTransport.On("Accept").Once().Return(mockConnection, nil)
+			mockTransport.On("Accept").Once().Return(nil, io.EOF)
+
+			// Set up and start the router.
+			peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{})
+			require.NoError(t, err)
+			defer peerManager.Close()
+
+			router, err := p2p.NewRouter(
+				log.TestingLogger(),
+				p2p.NopMetrics(),
+				selfInfo,
+				selfKey,
+				peerManager,
+				[]p2p.Transport{mockTransport},
+				p2p.RouterOptions{},
+			)
+			require.NoError(t, err)
+			require.NoError(t, router.Start())
+
+			select {
+			case <-closer.Done():
+			case <-time.After(100 * time.Millisecond):
+				require.Fail(t, "connection not closed")
+			}
+
+			require.NoError(t, router.Stop())
+			mockTransport.AssertExpectations(t)
+			mockConnection.AssertExpectations(t)
+		})
+	}
+}
+
 func TestRouter_AcceptPeers_Error(t *testing.T) {
 	t.Cleanup(leaktest.Check(t))
 
@@ -518,6 +999,71 @@ func TestRouter_AcceptPeers_HeadOfLineBlocking(t *testing.T) {
 	mockConnection.AssertExpectations(t)
 }
 
+func TestRouter_AcceptPeers_MaxIncomingHandshakes(t *testing.T) {
+	t.Cleanup(leaktest.Check(t))
+
+	// Set up a mock transport that returns more connections than the limit,
+	// which block during the handshake. The excess connections should be
+	// closed without handshaking, once they've waited for a slot.
+	var handshakes, closes int32
+	handshakeCh := make(chan struct{})
+
+	mockConnection := &mocks.Connection{}
+	mockConnection.On("String").Maybe().Return("mock")
+	mockConnection.On("Handshake", mock.Anything, selfInfo, selfKey).Run(func(_ mock.Arguments) {
+		atomic.AddInt32(&handshakes, 1)
+		<-handshakeCh
+	}).Return(p2p.NodeInfo{}, nil, io.EOF)
+	mockConnection.On("Close").Run(func(_ mock.Arguments) {
+		atomic.AddInt32(&closes, 1)
+	}).Return(nil)
+	mockConnection.On("RemoteEndpoint").Return(p2p.Endpoint{})
+
+	mockTransport := &mocks.Transport{}
+	mockTransport.On("String").Maybe().Return("mock")
+	mockTransport.On("Protocols").Return([]p2p.Protocol{"mock"})
+	mockTransport.On("Close").Return(nil)
+	mockTransport.On("Accept").Times(5).Return(mockConnection, nil)
+	mockTransport.On("Accept").Once().Return(nil, io.EOF)
+
+	// Set up and start the router.
+	peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	defer peerManager.Close()
+
+	router, err := p2p.NewRouter(
+		log.TestingLogger(),
+		p2p.NopMetrics(),
+		selfInfo,
+		selfKey,
+		peerManager,
+		[]p2p.Transport{mockTransport},
+		p2p.RouterOptions{
+			MaxIncomingHandshakes: 2,
+			IncomingHandshakeWait: 100 * time.Millisecond,
+		},
+	)
+	require.NoError(t, err)
+	require.NoError(t, router.Start())
+
+	// Only 2 connections handshake, and the other 3 are eventually dropped.
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&closes) == 3
+	}, time.Second, 10*time.Millisecond)
+	require.EqualValues(t, 2, atomic.LoadInt32(&handshakes))
+
+	// Once the handshakes complete, the slots are available again.
+	close(handshakeCh)
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&closes) == 5
+	}, time.Second, 10*time.Millisecond)
+	require.EqualValues(t, 2, atomic.LoadInt32(&handshakes))
+
+	require.NoError(t, router.Stop())
+	mockTransport.AssertExpectations(t)
+	mockConnection.AssertExpectations(t)
+}
+
 func TestRouter_DialPeers(t *testing.T) {
 	testcases := map[string]struct {
 		dialID   p2p.NodeID
@@ -618,6 +1164,110 @@ func TestRouter_DialPeers(t *testing.T) {
 	}
 }
 
+func TestRouter_DialTimeout(t *testing.T) {
+	persistent := p2p.NodeAddress{Protocol: "mock", NodeID: p2p.NodeID(strings.Repeat("a", 40))}
+	bootstrap := p2p.NodeAddress{Protocol: "mock", NodeID: p2p.NodeID(strings.Repeat("b", 40))}
+	discovered := p2p.NodeAddress{Protocol: "mock", NodeID: p2p.NodeID(strings.Repeat("c", 40))}
+
+	testcases := map[string]struct {
+		options  p2p.RouterOptions
+		timeouts map[p2p.NodeID]time.Duration
+	}{
+		"per class": {
+			p2p.RouterOptions{
+				DialTimeout:           time.Minute,
+				PersistentDialTimeout: 3 * time.Minute,
+				BootstrapDialTimeout:  2 * time.Minute,
+			},
+			map[p2p.NodeID]time.Duration{
+				persistent.NodeID: 3 * time.Minute,
+				bootstrap.NodeID:  2 * time.Minute,
+				discovered.NodeID: time.Minute,
+			},
+		},
+		"default": {
+			p2p.RouterOptions{DialTimeout: time.Minute},
+			map[p2p.NodeID]time.Duration{
+				persistent.NodeID: time.Minute,
+				bootstrap.NodeID:  time.Minute,
+				discovered.NodeID: time.Minute,
+			},
+		},
+		"none": {
+			p2p.RouterOptions{BootstrapDialTimeout: 2 * time.Minute},
+			map[p2p.NodeID]time.Duration{
+				persistent.NodeID: 0,
+				bootstrap.NodeID:  2 * time.Minute,
+				discovered.NodeID: 0,
+			},
+		},
+	}
+	for name, tc := range testcases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Cleanup(leaktest.Check(t))
+
+			// Set up a mock transport that records the dial timeout of each peer.
+			var mtx sync.Mutex
+			timeouts := map[p2p.NodeID]time.Duration{}
+			mockTransport := &mocks.Transport{}
+			mockTransport.On("String").Maybe().Return("mock")
+			mockTransport.On("Protocols").Return([]p2p.Protocol{"mock"})
+			mockTransport.On("Close").Return(nil)
+			mockTransport.On("Accept").Maybe().Return(nil, io.EOF)
+			mockTransport.On("Dial", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				ctx, endpoint := args.Get(0).(context.Context), args.Get(1).(p2p.Endpoint)
+				var timeout time.Duration
+				if deadline, ok := ctx.Deadline(); ok {
+					timeout = time.Until(deadline)
+				}
+				mtx.Lock()
+				timeouts[p2p.NodeID(endpoint.Path)] = timeout
+				mtx.Unlock()
+			}).Return(nil, io.EOF)
+
+			// The bootstrap peers include the persistent one, which takes precedence.
+			peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{
+				PersistentPeers: []p2p.NodeID{persistent.NodeID},
+			})
+			require.NoError(t, err)
+			defer peerManager.Close()
+
+			for _, address := range []p2p.NodeAddress{persistent, bootstrap, discovered} {
+				added, err := peerManager.Add(address)
+				require.NoError(t, err)
+				require.True(t, added)
+			}
+
+			options := tc.options
+			options.BootstrapPeers = []p2p.NodeID{persistent.NodeID, bootstrap.NodeID}
+			options.DialSleep = func(_ context.Context) {}
+			router, err := p2p.NewRouter(
+				log.TestingLogger(),
+				p2p.NopMetrics(),
+				selfInfo,
+				selfKey,
+				peerManager,
+				[]p2p.Transport{mockTransport},
+				options,
+			)
+			require.NoError(t, err)
+			require.NoError(t, router.Start())
+
+			require.Eventually(t, func() bool {
+				mtx.Lock()
+				defer mtx.Unlock()
+				return len(timeouts) == 3
+			}, 5*time.Second, 10*time.Millisecond)
+			require.NoError(t, router.Stop())
+
+			for peerID, expect := range tc.timeouts {
+				require.InDelta(t, expect, timeouts[peerID], float64(time.Second), "peer %v", peerID)
+			}
+		})
+	}
+}
+
 func TestRouter_DialPeers_Parallel(t *testing.T) {
 	t.Cleanup(leaktest.Check(t))
 
@@ -703,6 +1353,122 @@ func TestRouter_DialPeers_Parallel(t *testing.T) {
 	mockConnection.AssertExpectations(t)
 }
 
+func TestRouter_SendReliable(t *testing.T) {
+	t.Cleanup(leaktest.Check(t))
+
+	// Set up a mock transport to a peer that doesn't read any messages sent
+	// to it until unblocked, so that its send queue fills up.
+	closeCh := make(chan time.Time)
+	closeOnce := sync.Once{}
+	unblockCh := make(chan time.Time)
+	sentCh := make(chan []byte, 1)
+
+	mockConnection := &mocks.Connection{}
+	mockConnection.On("String").Maybe().Return("mock")
+	mockConnection.On("Handshake", mock.Anything, selfInfo, selfKey).
+		Return(peerInfo, peerKey.PubKey(), nil)
+	mockConnection.On("ReceiveMessage").WaitUntil(closeCh).Return(chID, nil, io.EOF)
+	mockConnection.On("SendMessage", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			select {
+			case sentCh <- args.Get(1).([]byte):
+			default:
+			}
+		}).
+		WaitUntil(unblockCh).Return(true, nil)
+	mockConnection.On("RemoteEndpoint").Return(p2p.Endpoint{})
+	mockConnection.On("CloseWithReason", p2p.DisconnectReasonMisbehaving).Run(func(_ mock.Arguments) {
+		closeOnce.Do(func() {
+			close(closeCh)
+		})
+	}).Return(nil)
+	mockConnection.On("Close").Maybe().Return(nil)
+
+	mockTransport := &mocks.Transport{}
+	mockTransport.On("String").Maybe().Return("mock")
+	mockTransport.On("Protocols").Return([]p2p.Protocol{"mock"})
+	mockTransport.On("Close").Return(nil)
+	mockTransport.On("Accept").Once().Return(mockConnection, nil)
+	mockTransport.On("Accept").Once().Return(nil, io.EOF)
+
+	// Set up and start the router, with one channel sending reliably and
+	// another one sending on Out.
+	peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	defer peerManager.Close()
+
+	sub := peerManager.Subscribe()
+	defer sub.Close()
+
+	router, err := p2p.NewRouter(
+		log.TestingLogger(),
+		p2p.NopMetrics(),
+		selfInfo,
+		selfKey,
+		peerManager,
+		[]p2p.Transport{mockTransport},
+		p2p.RouterOptions{},
+	)
+	require.NoError(t, err)
+	require.NoError(t, router.Start())
+
+	reliable, err := router.OpenChannel(chDesc, &p2ptest.Message{}, 0)
+	require.NoError(t, err)
+	otherDesc := chDesc
+	otherDesc.ID = byte(chID + 1)
+	other, err := router.OpenChannel(otherDesc, &p2ptest.Message{}, 0)
+	require.NoError(t, err)
+
+	p2ptest.RequireUpdate(t, sub, p2p.PeerUpdate{
+		NodeID: peerInfo.NodeID,
+		Status: p2p.PeerStatusUp,
+	})
+
+	// Messages to peers that aren't connected, or broadcast, are rejected.
+	err = reliable.SendReliable(ctx, p2p.Envelope{To: selfID, Message: &p2ptest.Message{Value: "x"}})
+	require.Equal(t, p2p.ErrPeerNotConnected{PeerID: selfID}, err)
+	err = reliable.SendReliable(ctx, p2p.Envelope{Broadcast: true, Message: &p2ptest.Message{Value: "x"}})
+	require.Error(t, err)
+
+	// Fill up the peer's send queue, until a message can't be enqueued.
+	for {
+		err = reliable.SendReliable(ctx, p2p.Envelope{To: peerID, Message: &p2ptest.Message{Value: "x"}})
+		if err != nil {
+			break
+		}
+	}
+	require.Equal(t, p2p.ErrPeerQueueFull{PeerID: peerID}, err)
+	var queueFullErr p2p.ErrPeerQueueFull
+	require.True(t, errors.As(err, &queueFullErr))
+
+	// A message sent on Out is accepted without an error, even though it
+	// can't be enqueued either.
+	select {
+	case other.Out <- p2p.Envelope{To: peerID, Message: &p2ptest.Message{Value: "y"}}:
+	case <-time.After(time.Second):
+		require.Fail(t, "send on Out blocked")
+	}
+
+	// Unblock the peer, which then receives the messages.
+	close(unblockCh)
+	bz, err := proto.Marshal(&p2ptest.Message{Value: "x"})
+	require.NoError(t, err)
+	require.Equal(t, bz, <-sentCh)
+
+	peerManager.Errored(peerInfo.NodeID, errors.New("boom"))
+	p2ptest.RequireUpdate(t, sub, p2p.PeerUpdate{
+		NodeID: peerInfo.NodeID,
+		Status: p2p.PeerStatusDown,
+	})
+	sub.Close()
+
+	reliable.Close()
+	other.Close()
+	require.NoError(t, router.Stop())
+	mockTransport.AssertExpectations(t)
+	mockConnection.AssertExpectations(t)
+}
+
 func TestRouter_EvictPeers(t *testing.T) {
 	t.Cleanup(leaktest.Check(t))
 
@@ -716,11 +1482,13 @@ func TestRouter_EvictPeers(t *testing.T) {
 		Return(peerInfo, peerKey.PubKey(), nil)
 	mockConnection.On("ReceiveMessage").WaitUntil(closeCh).Return(chID, nil, io.EOF)
 	mockConnection.On("RemoteEndpoint").Return(p2p.Endpoint{})
-	mockConnection.On("Close").Run(func(_ mock.Arguments) {
+	// The peer is told that it's evicted for misbehaving.
+	mockConnection.On("CloseWithReason", p2p.DisconnectReasonMisbehaving).Run(func(_ mock.Arguments) {
 		closeOnce.Do(func() {
 			close(closeCh)
 		})
 	}).Return(nil)
+	mockConnection.On("Close").Maybe().Return(nil)
 
 	mockTransport := &mocks.Transport{}
 	mockTransport.On("String").Maybe().Return("mock")
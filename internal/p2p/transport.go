@@ -77,7 +77,8 @@ type Connection interface {
 	Handshake(context.Context, NodeInfo, crypto.PrivKey) (NodeInfo, crypto.PubKey, error)
 
 	// ReceiveMessage returns the next message received on the connection,
-	// blocking until one is available. Returns io.EOF if closed.
+	// blocking until one is available. Returns io.EOF if closed, or
+	// ErrDisconnected if the peer closed it giving a reason.
 	ReceiveMessage() (ChannelID, []byte, error)
 
 	// SendMessage sends a message on the connection. Returns io.EOF if closed.
@@ -104,6 +105,11 @@ type Connection interface {
 	// Close closes the connection.
 	Close() error
 
+	// CloseWithReason flushes all pending sends, tells the peer why the
+	// connection is being closed, and then closes it. The peer's
+	// ReceiveMessage returns ErrDisconnected with the reason.
+	CloseWithReason(DisconnectReason) error
+
 	// FlushClose flushes all pending sends and then closes the connection.
 	//
 	// FIXME: This only exists for backwards-compatibility with the current
@@ -9,6 +9,7 @@ import (
 	"net"
 	"strconv"
 	"sync"
+	"time"
 
 	"golang.org/x/net/netutil"
 
@@ -225,6 +226,11 @@ type mConnConnection struct {
 	closeOnce    sync.Once
 
 	mconn *conn.MConnection // set during Handshake()
+
+	// set if the connection was closed for a reason ReceiveMessage must
+	// return, such as the one the peer gave for closing it
+	closeMtx sync.Mutex
+	closeErr error
 }
 
 // mConnMessage passes MConnection messages through internal channels.
@@ -316,6 +322,7 @@ func (c *mConnConnection) handshake(
 
 	var pbPeerInfo p2pproto.NodeInfo
 	errCh := make(chan error, 2)
+	nodeInfo.Time = time.Now()
 	go func() {
 		_, err := protoio.NewDelimitedWriter(secretConn).WriteMsg(nodeInfo.ToProto())
 		errCh <- err
@@ -361,6 +368,29 @@ func (c *mConnConnection) onError(e interface{}) {
 	if !ok {
 		err = fmt.Errorf("%v", err)
 	}
+	var (
+		disconnected conn.ErrDisconnected
+		tooLarge     conn.ErrRecvMessageTooLarge
+		closeErr     error
+	)
+	switch {
+	case errors.As(err, &disconnected):
+		closeErr = ErrDisconnected{Reason: DisconnectReasonFromProto(disconnected.Reason)}
+	case errors.As(err, &tooLarge):
+		closeErr = ErrMessageTooLarge{
+			ChannelID: ChannelID(tooLarge.ChannelID),
+			Size:      tooLarge.Size,
+			Capacity:  tooLarge.Capacity,
+		}
+	}
+	if closeErr != nil {
+		// The error may not make it to ReceiveMessage once the connection is
+		// closed, so it's kept for it in any case.
+		c.closeMtx.Lock()
+		c.closeErr = closeErr
+		c.closeMtx.Unlock()
+		err = closeErr
+	}
 	// We have to close the connection here, since MConnection will have stopped
 	// the service on any errors.
 	_ = c.Close()
@@ -411,6 +441,11 @@ func (c *mConnConnection) ReceiveMessage() (ChannelID, []byte, error) {
 	case err := <-c.errorCh:
 		return 0, nil, err
 	case <-c.closeCh:
+		c.closeMtx.Lock()
+		defer c.closeMtx.Unlock()
+		if c.closeErr != nil {
+			return 0, nil, c.closeErr
+		}
 		return 0, nil, io.EOF
 	case msg := <-c.receiveCh:
 		return msg.channelID, msg.payload, nil
@@ -463,6 +498,20 @@ func (c *mConnConnection) Close() error {
 	return err
 }
 
+// CloseWithReason implements Connection.
+func (c *mConnConnection) CloseWithReason(reason DisconnectReason) error {
+	var err error
+	c.closeOnce.Do(func() {
+		if c.mconn != nil && c.mconn.IsRunning() {
+			c.mconn.FlushStopWithReason(reason.ToProto())
+		} else {
+			err = c.conn.Close()
+		}
+		close(c.closeCh)
+	})
+	return err
+}
+
 // FlushClose implements Connection.
 func (c *mConnConnection) FlushClose() error {
 	var err error
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net"
 	"sync"
+	"time"
 
 	"github.com/tendermint/tendermint/crypto"
 	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
@@ -177,8 +178,10 @@ func (t *MemoryTransport) Dial(ctx context.Context, endpoint Endpoint) (Connecti
 	outCh := make(chan memoryMessage, t.bufferSize)
 	closer := tmsync.NewCloser()
 
-	outConn := newMemoryConnection(t.logger, t.nodeID, peer.nodeID, inCh, outCh, closer)
-	inConn := newMemoryConnection(peer.logger, peer.nodeID, t.nodeID, outCh, inCh, closer)
+	disconnect := &memoryDisconnect{}
+
+	outConn := newMemoryConnection(t.logger, t.nodeID, peer.nodeID, inCh, outCh, closer, disconnect)
+	inConn := newMemoryConnection(peer.logger, peer.nodeID, t.nodeID, outCh, inCh, closer, disconnect)
 
 	select {
 	case peer.acceptCh <- inConn:
@@ -206,9 +209,18 @@ type MemoryConnection struct {
 	localID  NodeID
 	remoteID NodeID
 
-	receiveCh <-chan memoryMessage
-	sendCh    chan<- memoryMessage
-	closer    *tmsync.Closer
+	receiveCh  <-chan memoryMessage
+	sendCh     chan<- memoryMessage
+	closer     *tmsync.Closer
+	disconnect *memoryDisconnect
+}
+
+// memoryDisconnect is shared by both ends of a MemoryConnection, to pass on
+// the reason one of them gave for closing it.
+type memoryDisconnect struct {
+	mtx    sync.Mutex
+	by     NodeID
+	reason DisconnectReason
 }
 
 // memoryMessage is passed internally, containing either a message or handshake.
@@ -229,14 +241,16 @@ func newMemoryConnection(
 	receiveCh <-chan memoryMessage,
 	sendCh chan<- memoryMessage,
 	closer *tmsync.Closer,
+	disconnect *memoryDisconnect,
 ) *MemoryConnection {
 	return &MemoryConnection{
-		logger:    logger.With("remote", remoteID),
-		localID:   localID,
-		remoteID:  remoteID,
-		receiveCh: receiveCh,
-		sendCh:    sendCh,
-		closer:    closer,
+		logger:     logger.With("remote", remoteID),
+		localID:    localID,
+		remoteID:   remoteID,
+		receiveCh:  receiveCh,
+		sendCh:     sendCh,
+		closer:     closer,
+		disconnect: disconnect,
 	}
 }
 
@@ -272,6 +286,7 @@ func (c *MemoryConnection) Handshake(
 	nodeInfo NodeInfo,
 	privKey crypto.PrivKey,
 ) (NodeInfo, crypto.PubKey, error) {
+	nodeInfo.Time = time.Now()
 	select {
 	case c.sendCh <- memoryMessage{nodeInfo: &nodeInfo, pubKey: privKey.PubKey()}:
 		c.logger.Debug("sent handshake", "nodeInfo", nodeInfo)
@@ -301,7 +316,7 @@ func (c *MemoryConnection) ReceiveMessage() (ChannelID, []byte, error) {
 	// may non-deterministically return non-error even when closed.
 	select {
 	case <-c.closer.Done():
-		return 0, nil, io.EOF
+		return 0, nil, c.closedErr()
 	default:
 	}
 
@@ -310,10 +325,21 @@ func (c *MemoryConnection) ReceiveMessage() (ChannelID, []byte, error) {
 		c.logger.Debug("received message", "chID", msg.channelID, "msg", msg.message)
 		return msg.channelID, msg.message, nil
 	case <-c.closer.Done():
-		return 0, nil, io.EOF
+		return 0, nil, c.closedErr()
 	}
 }
 
+// closedErr returns the error to receive once the connection is closed:
+// ErrDisconnected if the peer closed it giving a reason, otherwise io.EOF.
+func (c *MemoryConnection) closedErr() error {
+	c.disconnect.mtx.Lock()
+	defer c.disconnect.mtx.Unlock()
+	if c.disconnect.by == c.remoteID {
+		return ErrDisconnected{Reason: c.disconnect.reason}
+	}
+	return io.EOF
+}
+
 // SendMessage implements Connection.
 func (c *MemoryConnection) SendMessage(chID ChannelID, msg []byte) (bool, error) {
 	// Check close first, since channels are buffered. Otherwise, below select
@@ -366,6 +392,19 @@ func (c *MemoryConnection) Close() error {
 	return nil
 }
 
+// CloseWithReason implements Connection.
+func (c *MemoryConnection) CloseWithReason(reason DisconnectReason) error {
+	c.disconnect.mtx.Lock()
+	select {
+	case <-c.closer.Done():
+	default:
+		c.disconnect.by = c.localID
+		c.disconnect.reason = reason
+	}
+	c.disconnect.mtx.Unlock()
+	return c.Close()
+}
+
 // FlushClose implements Connection.
 func (c *MemoryConnection) FlushClose() error {
 	return c.Close()
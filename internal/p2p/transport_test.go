@@ -262,6 +262,9 @@ func TestConnection_Handshake(t *testing.T) {
 			// Must use assert due to goroutine.
 			peerInfo, peerKey, err := ba.Handshake(ctx, bInfo, bKey)
 			if err == nil {
+				// the peer's current time is passed along as well
+				assert.WithinDuration(t, time.Now(), peerInfo.Time, time.Minute)
+				peerInfo.Time = time.Time{}
 				assert.Equal(t, aInfo, peerInfo)
 				assert.Equal(t, aKey.PubKey(), peerKey)
 			}
@@ -270,6 +273,8 @@ func TestConnection_Handshake(t *testing.T) {
 
 		peerInfo, peerKey, err := ab.Handshake(ctx, aInfo, aKey)
 		require.NoError(t, err)
+		require.WithinDuration(t, time.Now(), peerInfo.Time, time.Minute)
+		peerInfo.Time = time.Time{}
 		require.Equal(t, bInfo, peerInfo)
 		require.Equal(t, bKey.PubKey(), peerKey)
 
@@ -329,6 +334,30 @@ func TestConnection_FlushClose(t *testing.T) {
 	})
 }
 
+func TestConnection_CloseWithReason(t *testing.T) {
+	withTransports(t, func(t *testing.T, makeTransport transportFactory) {
+		a := makeTransport(t)
+		b := makeTransport(t)
+		ab, ba := dialAcceptHandshake(t, a, b)
+
+		err := ab.CloseWithReason(p2p.DisconnectReasonEvicted)
+		require.NoError(t, err)
+
+		// The peer learns why the connection was closed, while we don't
+		// receive our own reason.
+		_, _, err = ba.ReceiveMessage()
+		require.Equal(t, p2p.ErrDisconnected{Reason: p2p.DisconnectReasonEvicted}, err)
+
+		_, _, err = ab.ReceiveMessage()
+		require.Equal(t, io.EOF, err)
+
+		// Closing with another reason once closed has no effect.
+		require.NoError(t, ba.CloseWithReason(p2p.DisconnectReasonShuttingDown))
+		_, _, err = ba.ReceiveMessage()
+		require.Equal(t, p2p.ErrDisconnected{Reason: p2p.DisconnectReasonEvicted}, err)
+	})
+}
+
 func TestConnection_LocalRemoteEndpoint(t *testing.T) {
 	withTransports(t, func(t *testing.T, makeTransport transportFactory) {
 		a := makeTransport(t)
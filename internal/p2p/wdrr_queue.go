@@ -5,7 +5,6 @@ import (
 	"sort"
 	"strconv"
 
-	"github.com/gogo/protobuf/proto"
 	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
 	"github.com/tendermint/tendermint/libs/log"
 )
@@ -170,7 +169,7 @@ func (s *wdrrScheduler) process() {
 		case e := <-s.enqueueCh:
 			// attempt to enqueue the incoming Envelope
 			chIDStr := strconv.Itoa(int(e.channelID))
-			wEnv := wrappedEnvelope{envelope: e, size: uint(proto.Size(e.Message))}
+			wEnv := wrappedEnvelope{envelope: e, size: uint(e.size())}
 			msgSize := wEnv.size
 
 			s.metrics.PeerPendingSendBytes.With("peer_id", string(e.To)).Add(float64(msgSize))
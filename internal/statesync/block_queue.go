@@ -2,17 +2,68 @@ package statesync
 
 import (
 	"container/heap"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+
 	"github.com/tendermint/tendermint/internal/p2p"
 	"github.com/tendermint/tendermint/types"
 )
 
+// retryRateWindow is the period over which the retry rate is measured.
+const retryRateWindow = 10 * time.Second
+
+var (
+	// errTrustPeriodExpired is returned when backfilling reaches a light block
+	// that is older than the trust period allows.
+	errTrustPeriodExpired = errors.New("light block is outside the trust period")
+
+	// errBackfillStopped is returned by a backfill interrupted by the reactor
+	// stopping.
+	errBackfillStopped = errors.New("backfill stopped before completing")
+)
+
+// retryReason is the reason a height is retried, by which the queue counts
+// its retries.
+type retryReason string
+
+const (
+	retryUnknown       retryReason = "unknown"
+	retryNoPeers       retryReason = "no_peers"       // no peer to request the block from
+	retryTimeout       retryReason = "timeout"        // the peer didn't respond in time
+	retryFetchError    retryReason = "fetch_error"    // the request failed otherwise
+	retryMissingBlock  retryReason = "missing_block"  // the peer didn't have the block
+	retryInvalidBlock  retryReason = "invalid_block"  // the block failed ValidateBasic
+	retryInvalidLink   retryReason = "invalid_link"   // the block doesn't hash to the trusted LastBlockID
+	retryInvalidCommit retryReason = "invalid_commit" // the block's commit failed verification
+	retryNoWitnesses   retryReason = "no_witnesses"   // too few witnesses served the block in time
+	retryConflict      retryReason = "conflict"       // a witness served a different block
+)
+
+// StopPredicate reports whether a light block is the terminal block of a
+// backfill, i.e. the last one that needs to be fetched and verified.
+type StopPredicate func(block *types.LightBlock) bool
+
+// stopAtHeightAndTime returns the default StopPredicate, which stops at the
+// first block at or below the stop height with a time before the stop time.
+func stopAtHeightAndTime(stopHeight int64, stopTime time.Time) StopPredicate {
+	return func(block *types.LightBlock) bool {
+		return block.Height <= stopHeight && block.Time.Before(stopTime)
+	}
+}
+
 type lightBlockResponse struct {
 	block *types.LightBlock
 	peer  p2p.NodeID
+
+	// the result of verifying the commit of the block before it was added, if
+	// it was, see verifyCommits
+	commitErr error
 }
 
 // a block queue is used for asynchronously fetching and verifying light blocks
@@ -20,19 +71,46 @@ type blockQueue struct {
 	mtx sync.Mutex
 
 	// cursors to keep track of which heights need to be fetched and verified
+	startHeight  int64
 	fetchHeight  int64
 	verifyHeight int64
 
-	// termination conditions
-	stopHeight int64
-	stopTime   time.Time
-	terminal   *types.LightBlock
+	// termination conditions. The terminal block is the first one that is
+	// found to satisfy the stop predicate, which defaults to the stop height
+	// and time. There are no blocks below the initial height of the chain, so
+	// whatever the predicate, the block at the initial height is terminal and
+	// no lower heights are fetched.
+	stopHeight    int64
+	initialHeight int64
+	stopTime      time.Time
+	stop          StopPredicate
+	terminal      *types.LightBlock
+
+	// light blocks older than the trusted header time minus the trust period
+	// can't be safely verified, so the queue aborts, recording the block as
+	// expired, once the verifying thread is to be served one. A trust period
+	// of 0 disables the check.
+	trustedTime time.Time
+	trustPeriod time.Duration
+	expired     *types.LightBlock
 
 	// track failed heights so we know what blocks to try fetch again
 	failed *maxIntHeap
-	// also count retries to know when to give up
-	retries    int
-	maxRetries int
+	// also count retries to know when to give up, and by reason
+	retries      int
+	maxRetries   int
+	retryReasons map[retryReason]int
+
+	// the times of the retries within the last retryRateWindow, oldest first,
+	// from which the retry rate reported to retryGauge is derived
+	retried    []time.Time
+	retryGauge metrics.Gauge
+
+	// the times at which the heights being fetched were handed out, from which
+	// the fetch times observed by fetchTime are derived
+	requested    map[int64]time.Time
+	fetchTime    metrics.Histogram
+	fetchRetries metrics.Counter
 
 	// store inbound blocks and serve them to a verifying thread via a channel
 	pending  map[int64]lightBlockResponse
@@ -41,26 +119,52 @@ type blockQueue struct {
 	// waiters are workers on idle until a height is required
 	waiters []chan int64
 
+	// if maxFetchers is set, only the first fetchers workers fetch blocks. The
+	// number is adapted within bounds to the outcome of the last fetchers
+	// fetches, counted by fetched and failures, and scaledCh is closed and
+	// replaced whenever it changes to wake up the throttled workers.
+	minFetchers int
+	maxFetchers int
+	fetchers    int
+	fetched     int
+	failures    int
+	scaledCh    chan struct{}
+
 	// this channel is closed once the verification process is complete
 	doneCh chan struct{}
 }
 
 func newBlockQueue(
-	startHeight, stopHeight int64,
-	stopTime time.Time,
+	startHeight, stopHeight, initialHeight int64,
+	stopTime, trustedTime time.Time,
+	trustPeriod time.Duration,
 	maxRetries int,
+	stop StopPredicate,
 ) *blockQueue {
+	if stop == nil {
+		stop = stopAtHeightAndTime(stopHeight, stopTime)
+	}
 	return &blockQueue{
-		stopHeight:   stopHeight,
-		stopTime:     stopTime,
-		fetchHeight:  startHeight,
-		verifyHeight: startHeight,
-		pending:      make(map[int64]lightBlockResponse),
-		failed:       &maxIntHeap{},
-		retries:      0,
-		maxRetries:   maxRetries,
-		waiters:      make([]chan int64, 0),
-		doneCh:       make(chan struct{}),
+		stopHeight:    stopHeight,
+		initialHeight: initialHeight,
+		stopTime:      stopTime,
+		stop:          stop,
+		trustedTime:   trustedTime,
+		trustPeriod:   trustPeriod,
+		startHeight:   startHeight,
+		fetchHeight:   startHeight,
+		verifyHeight:  startHeight,
+		pending:       make(map[int64]lightBlockResponse),
+		failed:        &maxIntHeap{},
+		retries:       0,
+		maxRetries:    maxRetries,
+		retryReasons:  make(map[retryReason]int),
+		retryGauge:    discard.NewGauge(),
+		requested:     make(map[int64]time.Time),
+		fetchTime:     discard.NewHistogram(),
+		fetchRetries:  discard.NewCounter(),
+		waiters:       make([]chan int64, 0),
+		doneCh:        make(chan struct{}),
 	}
 }
 
@@ -77,15 +181,35 @@ func (q *blockQueue) add(l lightBlockResponse) {
 	default:
 	}
 
+	if requested, ok := q.requested[l.block.Height]; ok {
+		q.fetchTime.With("peer_id", string(l.peer)).Observe(time.Since(requested).Seconds())
+		delete(q.requested, l.block.Height)
+	}
+
 	// sometimes more blocks are fetched then what is necessary. If we already
 	// have what we need then ignore this
 	if q.terminal != nil && l.block.Height < q.terminal.Height {
 		return
 	}
 
+	// if the incoming block satisfies the stop predicate, or is the first block
+	// of the chain, then we mark it as the terminal block
+	if q.stop(l.block) || l.block.Height <= q.initialHeight {
+		q.terminate(l.block)
+	}
+	q.scale(false)
+	q.pend(l)
+}
+
+// pend makes a block available to the verifying thread.
+// CONTRACT: must have a write lock.
+func (q *blockQueue) pend(l lightBlockResponse) {
 	// if the block that was returned is at the verify height then the verifier
 	// is already waiting for this block so we send it directly to them
 	if l.block.Height == q.verifyHeight && q.verifyCh != nil {
+		if q.expire(l.block) {
+			return
+		}
 		q.verifyCh <- l
 		close(q.verifyCh)
 		q.verifyCh = nil
@@ -93,11 +217,137 @@ func (q *blockQueue) add(l lightBlockResponse) {
 		// else we add it in the pending bucket
 		q.pending[l.block.Height] = l
 	}
+}
 
-	// Lastly, if the incoming block is past the stop time and stop height then
-	// we mark it as the terminal block
-	if l.block.Height <= q.stopHeight && l.block.Time.Before(q.stopTime) {
-		q.terminal = l.block
+// expire aborts the queue if the block, which is about to be served to the
+// verifying thread, is outside the trust period. It returns true if it did.
+// CONTRACT: must have a write lock.
+func (q *blockQueue) expire(block *types.LightBlock) bool {
+	if q.trustPeriod <= 0 || !block.Time.Before(q.trustedTime.Add(-q.trustPeriod)) {
+		return false
+	}
+	q.expired = block
+	q._closeChannels()
+	return true
+}
+
+// terminate marks the block as the terminal block, and discards the blocks
+// below it that were fetched in the meantime but turned out not to be needed.
+// CONTRACT: must have a write lock.
+func (q *blockQueue) terminate(terminal *types.LightBlock) {
+	q.terminal = terminal
+	for height := range q.pending {
+		if height < terminal.Height {
+			delete(q.pending, height)
+		}
+	}
+	for height := range q.requested {
+		if height < terminal.Height {
+			delete(q.requested, height)
+		}
+	}
+}
+
+// verifyCommits starts the given number of workers verifying the commits of
+// light blocks with verify, in whichever order the blocks arrive, and adding
+// them to the queue along with the result. It returns the function to hand
+// blocks over to the workers with, in place of add, which blocks until one of
+// them is free. As the verifying thread is only passed the result, it's left
+// with the cheap checks of the chain of blocks. The workers stop once the
+// queue is done.
+func (q *blockQueue) verifyCommits(workers int, verify func(*types.LightBlock) error) func(lightBlockResponse) {
+	commitCh := make(chan lightBlockResponse)
+	for i := 0; i < workers; i++ {
+		go func() {
+			for {
+				select {
+				case l := <-commitCh:
+					l.commitErr = verify(l.block)
+					q.add(l)
+
+				case <-q.doneCh:
+					return
+				}
+			}
+		}()
+	}
+
+	return func(l lightBlockResponse) {
+		select {
+		case commitCh <- l:
+		case <-q.doneCh:
+		}
+	}
+}
+
+// scaleFetchers makes the queue adapt the number of workers fetching blocks,
+// starting at max, to how peers respond: once a round of as many fetches as
+// there are workers has completed, the number grows by one if none of them was
+// retried and shrinks by one if at least half of them were, without ever
+// leaving the [min, max] bounds. Workers check throttle before requesting a
+// height. It must be called before any height is requested.
+func (q *blockQueue) scaleFetchers(min, max int) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	q.minFetchers = min
+	q.maxFetchers = max
+	q.fetchers = max
+	q.scaledCh = make(chan struct{})
+}
+
+// concurrency returns the number of workers currently fetching blocks, or 0 if
+// it isn't adapted by the queue.
+func (q *blockQueue) concurrency() int {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	return q.fetchers
+}
+
+// throttle returns nil if the given worker, numbered from 0, may request the
+// next height. Otherwise, the worker is beyond the current number of fetchers
+// and should wait on the returned channel, which is closed once the number
+// changes, before checking again.
+func (q *blockQueue) throttle(worker int) <-chan struct{} {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	if q.maxFetchers == 0 || worker < q.fetchers {
+		return nil
+	}
+	return q.scaledCh
+}
+
+// scale records the outcome of a fetch and, once a round of fetches is
+// complete, adapts the number of fetchers.
+// CONTRACT: must have a write lock.
+func (q *blockQueue) scale(failed bool) {
+	if q.maxFetchers == 0 {
+		return
+	}
+
+	if failed {
+		q.failures++
+	} else {
+		q.fetched++
+	}
+	round := q.fetched + q.failures
+	if round < q.fetchers {
+		return
+	}
+
+	fetchers := q.fetchers
+	switch {
+	case q.failures == 0 && fetchers < q.maxFetchers:
+		fetchers++
+	case 2*q.failures >= round && fetchers > q.minFetchers:
+		fetchers--
+	}
+	q.fetched, q.failures = 0, 0
+
+	if fetchers != q.fetchers {
+		q.fetchers = fetchers
+		close(q.scaledCh)
+		q.scaledCh = make(chan struct{})
 	}
 }
 
@@ -110,14 +360,16 @@ func (q *blockQueue) nextHeight() <-chan int64 {
 	ch := make(chan int64, 1)
 	// if a previous process failed then we pick up this one
 	if q.failed.Len() > 0 {
-		failedHeight := heap.Pop(q.failed)
-		ch <- failedHeight.(int64)
+		failedHeight := heap.Pop(q.failed).(int64)
+		q.requested[failedHeight] = time.Now()
+		ch <- failedHeight
 		close(ch)
 		return ch
 	}
 
-	if q.terminal == nil {
+	if q.terminal == nil && q.fetchHeight >= q.initialHeight {
 		// return and decrement the fetch height
+		q.requested[q.fetchHeight] = time.Now()
 		ch <- q.fetchHeight
 		q.fetchHeight--
 		close(ch)
@@ -153,6 +405,10 @@ func (q *blockQueue) verifyNext() <-chan lightBlockResponse {
 	}
 
 	if lb, ok := q.pending[q.verifyHeight]; ok {
+		if q.expire(lb.block) {
+			close(ch)
+			return ch
+		}
 		ch <- lb
 		close(ch)
 		delete(q.pending, q.verifyHeight)
@@ -165,8 +421,14 @@ func (q *blockQueue) verifyNext() <-chan lightBlockResponse {
 
 // Retry is called when a dispatcher failed to fetch a light block or the
 // fetched light block failed verification. It signals to the queue to add the
-// height back to the request queue
+// height back to the request queue. See retryWithReason.
 func (q *blockQueue) retry(height int64) {
+	q.retryWithReason(height, retryUnknown)
+}
+
+// retryWithReason is retry, counting the retry under the given reason in the
+// counts returned by retryCounts.
+func (q *blockQueue) retryWithReason(height int64, reason retryReason) {
 	q.mtx.Lock()
 	defer q.mtx.Unlock()
 
@@ -181,19 +443,69 @@ func (q *blockQueue) retry(height int64) {
 		return
 	}
 
+	q.scale(true)
+	q.fetchRetries.Add(1)
+	delete(q.requested, height)
 	q.retries++
+	q.retryReasons[reason]++
+	now := time.Now()
+	q.retried = append(q.retried, now)
+	q.retryGauge.Set(q._retryRate(now))
 	if q.retries >= q.maxRetries {
 		q._closeChannels()
 		return
 	}
 
 	if len(q.waiters) > 0 {
+		q.requested[height] = time.Now()
+		q.waiters[0] <- height
+		close(q.waiters[0])
+		q.waiters = q.waiters[1:]
+	} else {
+		heap.Push(q.failed, height)
+	}
+}
+
+// Stalled is called when no light block became verifiable within the verify
+// timeout. It requests the block at the verify height again, which the
+// dispatcher then requests from another peer than the one still busy with
+// it, unless the block was never requested or is already due to be. Unlike
+// retry, this isn't counted towards the maximum number of retries. It returns
+// the height the queue is stalled at.
+func (q *blockQueue) stalled() int64 {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	height := q.verifyHeight
+	select {
+	case <-q.doneCh:
+		return height
+	default:
+	}
+
+	// heights are fetched from the top down, so heights above the fetch
+	// height have been requested
+	if height <= q.fetchHeight {
+		return height
+	}
+	if _, ok := q.pending[height]; ok {
+		return height
+	}
+	for _, failed := range *q.failed {
+		if failed == height {
+			return height
+		}
+	}
+
+	if len(q.waiters) > 0 {
+		q.requested[height] = time.Now()
 		q.waiters[0] <- height
 		close(q.waiters[0])
 		q.waiters = q.waiters[1:]
 	} else {
 		heap.Push(q.failed, height)
 	}
+	return height
 }
 
 // Success is called when a light block has been successfully verified and
@@ -201,15 +513,125 @@ func (q *blockQueue) retry(height int64) {
 func (q *blockQueue) success(height int64) {
 	q.mtx.Lock()
 	defer q.mtx.Unlock()
+	select {
+	case <-q.doneCh:
+	default:
+		q.retryGauge.Set(q._retryRate(time.Now()))
+	}
 	if q.terminal != nil && q.verifyHeight == q.terminal.Height {
 		q._closeChannels()
 	}
 	q.verifyHeight--
 }
 
+// servableBase returns the lowest height of the light blocks verified so far,
+// which are contiguous, or the height above the start height if none was.
+func (q *blockQueue) servableBase() int64 {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	return q.verifyHeight + 1
+}
+
+// BlockQueueSnapshot is the state of a block queue at a given time, to tell
+// what a backfill is waiting on.
+type BlockQueueSnapshot struct {
+	// the heights fetched but not yet verified, in ascending order
+	Pending []int64
+	// the heights handed out to fetchers but not yet fetched, in ascending
+	// order
+	Outstanding []int64
+	// the number of fetchers waiting for a height to fetch
+	Waiters int
+	// the lowest height verified so far, or 0 if none was
+	LowestVerifiedHeight int64
+}
+
+// snapshot returns the current state of the queue. It's safe to call
+// concurrently with the other queue operations.
+func (q *blockQueue) snapshot() BlockQueueSnapshot {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	snapshot := BlockQueueSnapshot{
+		Pending:     make([]int64, 0, len(q.pending)),
+		Outstanding: make([]int64, 0, len(q.requested)),
+		Waiters:     len(q.waiters),
+	}
+	for height := range q.pending {
+		snapshot.Pending = append(snapshot.Pending, height)
+	}
+	for height := range q.requested {
+		snapshot.Outstanding = append(snapshot.Outstanding, height)
+	}
+	sort.Slice(snapshot.Pending, func(i, j int) bool { return snapshot.Pending[i] < snapshot.Pending[j] })
+	sort.Slice(snapshot.Outstanding, func(i, j int) bool { return snapshot.Outstanding[i] < snapshot.Outstanding[j] })
+	if q.verifyHeight < q.startHeight {
+		snapshot.LowestVerifiedHeight = q.verifyHeight + 1
+	}
+	return snapshot
+}
+
+// trackRetryRate makes the queue report its retry rate, in retries per second
+// over the last retryRateWindow, to the gauge whenever a height is retried or
+// verified, and reset it once the queue is closed.
+func (q *blockQueue) trackRetryRate(gauge metrics.Gauge) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	q.retryGauge = gauge
+}
+
+// trackFetchTimes makes the queue observe, for every light block added to it,
+// the time since its height was handed out by nextHeight in the histogram,
+// labeled by the peer that served it, and count the fetches that are retried
+// instead.
+func (q *blockQueue) trackFetchTimes(histogram metrics.Histogram, retries metrics.Counter) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	q.fetchTime = histogram
+	q.fetchRetries = retries
+}
+
+// retryCounts returns a snapshot of the number of retries so far, by reason.
+func (q *blockQueue) retryCounts() map[retryReason]int {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	counts := make(map[retryReason]int, len(q.retryReasons))
+	for reason, count := range q.retryReasons {
+		counts[reason] = count
+	}
+	return counts
+}
+
+// retryRate returns the number of retries per second over the
+// retryRateWindow preceding now.
+func (q *blockQueue) retryRate(now time.Time) float64 {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	return q._retryRate(now)
+}
+
+// CONTRACT: must have a write lock. Use retryRate instead
+func (q *blockQueue) _retryRate(now time.Time) float64 {
+	// forget the retries that fell out of the window
+	since := now.Add(-retryRateWindow)
+	i := 0
+	for i < len(q.retried) && !q.retried[i].After(since) {
+		i++
+	}
+	q.retried = q.retried[i:]
+
+	return float64(len(q.retried)) / retryRateWindow.Seconds()
+}
+
 func (q *blockQueue) error() error {
 	q.mtx.Lock()
 	defer q.mtx.Unlock()
+	if q.expired != nil {
+		return fmt.Errorf("%w: light block %d has time %v, before the trusted time %v minus the "+
+			"trust period %v; target height: %d, stop time: %v", errTrustPeriodExpired, q.expired.Height,
+			q.expired.Time, q.trustedTime, q.trustPeriod, q.stopHeight, q.stopTime)
+	}
 	if q.retries >= q.maxRetries {
 		return fmt.Errorf("max retries to fetch valid blocks exceeded (%d); "+
 			"target height: %d, height reached: %d", q.maxRetries, q.stopHeight, q.verifyHeight)
@@ -227,6 +649,7 @@ func (q *blockQueue) close() {
 // CONTRACT: must have a write lock. Use close instead
 func (q *blockQueue) _closeChannels() {
 	close(q.doneCh)
+	q.retryGauge.Set(0)
 
 	// wait for the channel to be drained
 	select {
@@ -1,16 +1,21 @@
 package statesync
 
 import (
+	"bytes"
+	"fmt"
 	"math/rand"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/generic"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/tendermint/tendermint/internal/p2p"
 	"github.com/tendermint/tendermint/internal/test/factory"
+	"github.com/tendermint/tendermint/types"
 )
 
 var (
@@ -25,7 +30,7 @@ func TestBlockQueueBasic(t *testing.T) {
 	peerID, err := p2p.NewNodeID("0011223344556677889900112233445566778899")
 	require.NoError(t, err)
 
-	queue := newBlockQueue(startHeight, stopHeight, stopTime, 1)
+	queue := newBlockQueue(startHeight, stopHeight, 1, stopTime, time.Time{}, 0, 1, nil)
 	wg := &sync.WaitGroup{}
 
 	// asynchronously fetch blocks and add it to the queue
@@ -72,7 +77,7 @@ func TestBlockQueueWithFailures(t *testing.T) {
 	peerID, err := p2p.NewNodeID("0011223344556677889900112233445566778899")
 	require.NoError(t, err)
 
-	queue := newBlockQueue(startHeight, stopHeight, stopTime, 200)
+	queue := newBlockQueue(startHeight, stopHeight, 1, stopTime, time.Time{}, 0, 200, nil)
 	wg := &sync.WaitGroup{}
 
 	failureRate := 4
@@ -121,7 +126,7 @@ func TestBlockQueueWithFailures(t *testing.T) {
 func TestBlockQueueBlocks(t *testing.T) {
 	peerID, err := p2p.NewNodeID("0011223344556677889900112233445566778899")
 	require.NoError(t, err)
-	queue := newBlockQueue(startHeight, stopHeight, stopTime, 2)
+	queue := newBlockQueue(startHeight, stopHeight, 1, stopTime, time.Time{}, 0, 2, nil)
 	expectedHeight := startHeight
 	retryHeight := stopHeight + 2
 
@@ -168,7 +173,7 @@ loop:
 func TestBlockQueueAcceptsNoMoreBlocks(t *testing.T) {
 	peerID, err := p2p.NewNodeID("0011223344556677889900112233445566778899")
 	require.NoError(t, err)
-	queue := newBlockQueue(startHeight, stopHeight, stopTime, 1)
+	queue := newBlockQueue(startHeight, stopHeight, 1, stopTime, time.Time{}, 0, 1, nil)
 	defer queue.close()
 
 loop:
@@ -188,13 +193,100 @@ loop:
 	require.Len(t, queue.pending, int(startHeight-stopHeight)+1)
 }
 
+func TestBlockQueueSnapshot(t *testing.T) {
+	peerID, err := p2p.NewNodeID("0011223344556677889900112233445566778899")
+	require.NoError(t, err)
+	queue := newBlockQueue(10, 8, 1, stopTime, time.Time{}, 0, 10, nil)
+	defer queue.close()
+
+	// snapshots can be taken concurrently with the other operations
+	closeCh := make(chan struct{})
+	defer close(closeCh)
+	go func() {
+		for {
+			select {
+			case <-closeCh:
+				return
+			default:
+				_ = queue.snapshot()
+			}
+		}
+	}()
+
+	require.Equal(t, BlockQueueSnapshot{Pending: []int64{}, Outstanding: []int64{}}, queue.snapshot())
+
+	// hand out all the heights down to the stop height, and once the terminal
+	// block arrives, make one more worker wait for a height
+	for height := int64(10); height >= 8; height-- {
+		require.Equal(t, height, <-queue.nextHeight())
+	}
+	queue.add(mockLBResp(t, peerID, 8, endTime))
+	waiter := queue.nextHeight()
+	queue.add(mockLBResp(t, peerID, 9, endTime))
+	require.Equal(t, BlockQueueSnapshot{
+		Pending:     []int64{8, 9},
+		Outstanding: []int64{10},
+		Waiters:     1,
+	}, queue.snapshot())
+
+	// once the start height is verified, it's the lowest verified one
+	queue.add(mockLBResp(t, peerID, 10, endTime))
+	resp := <-queue.verifyNext()
+	queue.success(resp.block.Height)
+	require.Equal(t, BlockQueueSnapshot{
+		Pending:              []int64{8, 9},
+		Outstanding:          []int64{},
+		Waiters:              1,
+		LowestVerifiedHeight: 10,
+	}, queue.snapshot())
+
+	// a height that failed verification is handed out to the waiting worker
+	resp = <-queue.verifyNext()
+	queue.retry(resp.block.Height)
+	require.EqualValues(t, 9, <-waiter)
+	require.Equal(t, BlockQueueSnapshot{
+		Pending:              []int64{8},
+		Outstanding:          []int64{9},
+		LowestVerifiedHeight: 10,
+	}, queue.snapshot())
+}
+
+func TestBlockQueueServableBase(t *testing.T) {
+	peerID, err := p2p.NewNodeID("0011223344556677889900112233445566778899")
+	require.NoError(t, err)
+	queue := newBlockQueue(10, 8, 1, stopTime, time.Time{}, 0, 10, nil)
+
+	// nothing below the start height is servable until it's verified
+	require.EqualValues(t, 11, queue.servableBase())
+
+	for height := int64(10); height >= 8; height-- {
+		require.Equal(t, height, <-queue.nextHeight())
+	}
+
+	// fetching a block doesn't advance the base, verifying it does
+	queue.add(mockLBResp(t, peerID, 9, endTime))
+	require.EqualValues(t, 11, queue.servableBase())
+	queue.add(mockLBResp(t, peerID, 10, endTime))
+	queue.add(mockLBResp(t, peerID, 8, endTime))
+	for height := int64(10); height >= 8; height-- {
+		resp := <-queue.verifyNext()
+		require.Equal(t, height, resp.block.Height)
+		require.Equal(t, height+1, queue.servableBase())
+		queue.success(resp.block.Height)
+		require.Equal(t, height, queue.servableBase())
+	}
+
+	// verifying the terminal block closed the queue
+	<-queue.done()
+}
+
 // Test a scenario where more blocks are needed then just the stopheight because
 // we haven't found a block with a small enough time.
 func TestBlockQueueStopTime(t *testing.T) {
 	peerID, err := p2p.NewNodeID("0011223344556677889900112233445566778899")
 	require.NoError(t, err)
 
-	queue := newBlockQueue(startHeight, stopHeight, stopTime, 1)
+	queue := newBlockQueue(startHeight, stopHeight, 1, stopTime, time.Time{}, 0, 1, nil)
 	wg := &sync.WaitGroup{}
 
 	baseTime := stopTime.Add(-50 * time.Second)
@@ -233,9 +325,506 @@ func TestBlockQueueStopTime(t *testing.T) {
 	}
 }
 
-func mockLBResp(t *testing.T, peer p2p.NodeID, height int64, time time.Time) lightBlockResponse {
+// TestBlockQueueStopPredicate checks that a custom stop predicate, here
+// matching a marker in the app hash of a single block, ends the process at that
+// block, whether it is above or below the stop height and stop time.
+func TestBlockQueueStopPredicate(t *testing.T) {
+	peerID, err := p2p.NewNodeID("0011223344556677889900112233445566778899")
+	require.NoError(t, err)
+	marker := []byte("marker")
+
+	testCases := map[string]struct {
+		markerHeight int64
+		blockTime    time.Time
+	}{
+		"above the stop height and time": {150, stopTime.Add(time.Hour)},
+		"below the stop height and time": {60, endTime},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			queue := newBlockQueue(startHeight, stopHeight, 1, stopTime, time.Time{}, 0, 1,
+				func(block *types.LightBlock) bool { return bytes.Equal(block.AppHash, marker) })
+			wg := &sync.WaitGroup{}
+
+			for i := 0; i <= numWorkers; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for {
+						select {
+						case height := <-queue.nextHeight():
+							resp := mockLBResp(t, peerID, height, tc.blockTime)
+							if height == tc.markerHeight {
+								resp.block.AppHash = marker
+							}
+							queue.add(resp)
+						case <-queue.done():
+							return
+						}
+					}
+				}()
+			}
+
+			trackingHeight := startHeight
+			for {
+				select {
+				case resp := <-queue.verifyNext():
+					require.Equal(t, trackingHeight, resp.block.Height)
+					trackingHeight--
+					queue.success(resp.block.Height)
+
+				case <-queue.done():
+					wg.Wait()
+					require.Equal(t, tc.markerHeight-1, trackingHeight)
+					require.NoError(t, queue.error())
+					return
+				}
+			}
+		})
+	}
+}
+
+// TestBlockQueueInitialHeight checks that the block at the initial height ends
+// the process and that no lower heights are fetched, even with a stop
+// predicate that is never satisfied.
+func TestBlockQueueInitialHeight(t *testing.T) {
+	peerID, err := p2p.NewNodeID("0011223344556677889900112233445566778899")
+	require.NoError(t, err)
+	const initialHeight = 50
+
+	queue := newBlockQueue(startHeight, stopHeight, initialHeight, stopTime, time.Time{}, 0, 1,
+		func(*types.LightBlock) bool { return false })
+	wg := &sync.WaitGroup{}
+
+	var (
+		mtx       sync.Mutex
+		minHeight = startHeight
+	)
+	for i := 0; i <= numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case height := <-queue.nextHeight():
+					mtx.Lock()
+					if height < minHeight {
+						minHeight = height
+					}
+					mtx.Unlock()
+					queue.add(mockLBResp(t, peerID, height, endTime))
+				case <-queue.done():
+					return
+				}
+			}
+		}()
+	}
+
+	trackingHeight := startHeight
+	for {
+		select {
+		case resp := <-queue.verifyNext():
+			require.Equal(t, trackingHeight, resp.block.Height)
+			trackingHeight--
+			queue.success(resp.block.Height)
+
+		case <-queue.done():
+			wg.Wait()
+			require.EqualValues(t, initialHeight-1, trackingHeight)
+			require.EqualValues(t, initialHeight, minHeight)
+			require.NoError(t, queue.error())
+			return
+		}
+	}
+}
+
+func TestBlockQueueFetchPastStopHeight(t *testing.T) {
+	peerID, err := p2p.NewNodeID("0011223344556677889900112233445566778899")
+	require.NoError(t, err)
+
+	// as in TestBlockQueueStopTime, the block times are still above the stop
+	// time at the stop height, and fall below it at height 49
+	baseTime := stopTime.Add(-50 * time.Second)
+	blockTime := func(height int64) time.Time {
+		return baseTime.Add(time.Duration(height) * time.Second)
+	}
+
+	testcases := map[string]struct {
+		blockTime func(height int64) time.Time
+		terminal  int64
+	}{
+		"times above stop time": {blockTime, 49},
+		"times below stop time": {func(int64) time.Time { return endTime }, stopHeight},
+	}
+	for name, tc := range testcases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			queue := newBlockQueue(startHeight, stopHeight, 1, stopTime, time.Time{}, 0, 1, nil)
+
+			// the workers keep fetching past the stop height without waiting
+			// for the blocks above it to arrive
+			for height := startHeight; height >= 40; height-- {
+				select {
+				case next := <-queue.nextHeight():
+					require.Equal(t, height, next)
+				default:
+					t.Fatalf("worker stalled at height %d", height)
+				}
+			}
+
+			// the blocks arrive, lowest first, and the ones below the terminal
+			// block are discarded
+			for height := int64(40); height <= startHeight; height++ {
+				queue.add(mockLBResp(t, peerID, height, tc.blockTime(height)))
+			}
+			for height := startHeight; height >= tc.terminal; height-- {
+				resp := <-queue.verifyNext()
+				require.Equal(t, height, resp.block.Height)
+				queue.success(height)
+			}
+			select {
+			case <-queue.done():
+			default:
+				t.Fatal("expected the queue to be done")
+			}
+			require.NoError(t, queue.error())
+
+			queue.mtx.Lock()
+			defer queue.mtx.Unlock()
+			require.Equal(t, tc.terminal, queue.terminal.Height)
+			for height := range queue.pending {
+				require.GreaterOrEqual(t, height, tc.terminal)
+			}
+			require.Empty(t, queue.requested)
+		})
+	}
+}
+
+func TestBlockQueueScaleFetchers(t *testing.T) {
+	peerID, err := p2p.NewNodeID("0011223344556677889900112233445566778899")
+	require.NoError(t, err)
+
+	queue := newBlockQueue(startHeight, stopHeight, 1, stopTime, time.Time{}, 0, 1000, nil)
+	queue.scaleFetchers(2, 5)
+	require.Equal(t, 5, queue.concurrency())
+
+	// fetchRound runs a round of fetches across the current number of
+	// fetchers, the given number of which are retried, and returns the number
+	// of fetchers after it.
+	fetchRound := func(retries int) int {
+		fetchers := queue.concurrency()
+		for i := 0; i < fetchers; i++ {
+			height := <-queue.nextHeight()
+			if i < retries {
+				queue.retry(height)
+			} else {
+				queue.add(mockLBResp(t, peerID, height, endTime))
+			}
+		}
+		return queue.concurrency()
+	}
+
+	// fast peers can't have the number of fetchers grow beyond the maximum
+	require.Nil(t, queue.throttle(4))
+	require.Equal(t, 5, fetchRound(0))
+
+	// a few retries don't make a difference either way
+	require.Equal(t, 5, fetchRound(2))
+
+	// slow peers, whose requests time out, shrink it down to the minimum
+	for _, expected := range []int{4, 3, 2, 2, 2} {
+		require.Equal(t, expected, fetchRound(expected+1))
+	}
+	require.Nil(t, queue.throttle(1))
+	throttled := queue.throttle(2)
+	require.NotNil(t, throttled)
+
+	// once peers respond fast again, it grows back up to the maximum, waking
+	// up the throttled workers as it goes
+	require.Equal(t, 3, fetchRound(0))
+	select {
+	case <-throttled:
+	default:
+		t.Fatal("expected throttled workers to be woken up")
+	}
+	require.Nil(t, queue.throttle(2))
+	require.NotNil(t, queue.throttle(3))
+	for _, expected := range []int{4, 5, 5} {
+		require.Equal(t, expected, fetchRound(0))
+	}
+	require.Nil(t, queue.throttle(4))
+}
+
+func TestBlockQueueStalled(t *testing.T) {
+	peerID, err := p2p.NewNodeID("0011223344556677889900112233445566778899")
+	require.NoError(t, err)
+
+	queue := newBlockQueue(startHeight, stopHeight, 1, stopTime, time.Time{}, 0, 1, nil)
+
+	// the block at the verify height hasn't been requested yet, so there is
+	// nothing to request again
+	require.Equal(t, startHeight, queue.stalled())
+	require.Equal(t, startHeight, <-queue.nextHeight())
+	require.Equal(t, startHeight-1, <-queue.nextHeight())
+	queue.add(mockLBResp(t, peerID, startHeight-1, endTime))
+
+	// the request for the block at the verify height was lost, so once the
+	// queue is stalled it is requested again, without counting as a retry
+	verifyCh := queue.verifyNext()
+	require.Equal(t, startHeight, queue.stalled())
+	require.Equal(t, startHeight, queue.stalled())
+	require.Equal(t, startHeight, <-queue.nextHeight())
+	require.Equal(t, startHeight-2, <-queue.nextHeight(), "height shouldn't be due twice")
+	require.NoError(t, queue.error())
+
+	// the block requested again is served to the verifier that was waiting
+	// all along
+	queue.add(mockLBResp(t, peerID, startHeight, endTime))
+	select {
+	case resp := <-verifyCh:
+		require.Equal(t, startHeight, resp.block.Height)
+	default:
+		t.Fatal("expected the block to be verifiable")
+	}
+	queue.success(startHeight)
+
+	// the next block is already pending, so it isn't requested again
+	require.Equal(t, startHeight-1, queue.stalled())
+	require.Equal(t, startHeight-3, <-queue.nextHeight())
+	queue.close()
+}
+
+func TestBlockQueueRetryRate(t *testing.T) {
+	peerID, err := p2p.NewNodeID("0011223344556677889900112233445566778899")
+	require.NoError(t, err)
+
+	queue := newBlockQueue(startHeight, stopHeight, 1, stopTime, time.Time{}, 0, 1000, nil)
+	gauge := generic.NewGauge("retry_rate")
+	queue.trackRetryRate(gauge)
+
+	// fetch blocks, every other one of which fails the first time
+	for i := 0; i < 10; i++ {
+		height := <-queue.nextHeight()
+		if i%2 == 0 {
+			queue.retry(height)
+			height = <-queue.nextHeight()
+		}
+		queue.add(mockLBResp(t, peerID, height, endTime))
+		resp := <-queue.verifyNext()
+		queue.success(resp.block.Height)
+	}
+	expected := 5 / retryRateWindow.Seconds()
+	require.Equal(t, expected, gauge.Value())
+
+	// more retries raise the rate
+	for i := 0; i < 5; i++ {
+		queue.retry(<-queue.nextHeight())
+	}
+	require.Equal(t, 2*expected, gauge.Value())
+
+	// the retries are forgotten once they're out of the window
+	require.Equal(t, 2*expected, queue.retryRate(time.Now()))
+	require.Zero(t, queue.retryRate(time.Now().Add(retryRateWindow)))
+
+	queue.close()
+	require.Zero(t, gauge.Value())
+}
+
+func TestBlockQueueFetchTimes(t *testing.T) {
+	const delay = 50 * time.Millisecond
+	peerID, err := p2p.NewNodeID("0011223344556677889900112233445566778899")
+	require.NoError(t, err)
+
+	queue := newBlockQueue(startHeight, stopHeight, 1, stopTime, time.Time{}, 0, 1000, nil)
+	histogram := newLabeledHistogram()
+	retries := generic.NewCounter("light_block_fetch_retries_total")
+	queue.trackFetchTimes(histogram, retries)
+
+	// a fetch delayed by the peer is observed once it's added
+	height := <-queue.nextHeight()
+	time.Sleep(delay)
+	queue.add(mockLBResp(t, peerID, height, endTime))
+	observations := histogram.observations("peer_id", string(peerID))
+	require.Len(t, observations, 1)
+	require.GreaterOrEqual(t, observations[0], delay.Seconds())
+
+	// a retried fetch is counted instead, and the time taken to fetch the
+	// height again is observed from when it's handed out again
+	height = <-queue.nextHeight()
+	queue.retry(height)
+	require.Equal(t, float64(1), retries.Value())
+	require.Len(t, histogram.observations("peer_id", string(peerID)), 1)
+
+	require.Equal(t, height, <-queue.nextHeight())
+	queue.add(mockLBResp(t, peerID, height, endTime))
+	observations = histogram.observations("peer_id", string(peerID))
+	require.Len(t, observations, 2)
+	require.Less(t, observations[1], delay.Seconds())
+	queue.close()
+}
+
+func TestBlockQueueRetryReasons(t *testing.T) {
+	queue := newBlockQueue(startHeight, stopHeight, 1, stopTime, time.Time{}, 0, 5, nil)
+	require.Empty(t, queue.retryCounts())
+
+	reasons := []retryReason{retryTimeout, retryInvalidCommit, retryTimeout, retryMissingBlock}
+	for _, reason := range reasons {
+		queue.retryWithReason(<-queue.nextHeight(), reason)
+	}
+	queue.retry(<-queue.nextHeight())
+
+	expected := map[retryReason]int{
+		retryTimeout:       2,
+		retryInvalidCommit: 1,
+		retryMissingBlock:  1,
+		retryUnknown:       1,
+	}
+	counts := queue.retryCounts()
+	require.Equal(t, expected, counts)
+
+	// the counts are a snapshot, and retries once the queue is done, here as
+	// it reached its maximum number of retries, aren't counted
+	counts[retryTimeout] = 100
+	queue.retryWithReason(startHeight, retryTimeout)
+	require.Equal(t, expected, queue.retryCounts())
+	require.Error(t, queue.error())
+
+	require.Equal(t, []interface{}{
+		"invalid_commit", 1, "missing_block", 1, "timeout", 2, "unknown", 1,
+	}, retryCountsKeyvals(expected))
+}
+
+// labeledHistogram is a histogram recording its observations by label values.
+type labeledHistogram struct {
+	mtx    *sync.Mutex
+	values map[string][]float64
+	labels []string
+}
+
+func newLabeledHistogram() *labeledHistogram {
+	return &labeledHistogram{mtx: &sync.Mutex{}, values: make(map[string][]float64)}
+}
+
+func (h *labeledHistogram) With(labelValues ...string) metrics.Histogram {
+	return &labeledHistogram{
+		mtx:    h.mtx,
+		values: h.values,
+		labels: append(append([]string(nil), h.labels...), labelValues...),
+	}
+}
+
+func (h *labeledHistogram) Observe(value float64) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	key := fmt.Sprint(h.labels)
+	h.values[key] = append(h.values[key], value)
+}
+
+func (h *labeledHistogram) observations(labelValues ...string) []float64 {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return h.values[fmt.Sprint(labelValues)]
+}
+
+func mockLBResp(t testing.TB, peer p2p.NodeID, height int64, time time.Time) lightBlockResponse {
 	return lightBlockResponse{
 		block: mockLB(t, height, time, factory.MakeBlockID()),
 		peer:  peer,
 	}
 }
+
+func TestBlockQueueVerifyCommits(t *testing.T) {
+	chain := make(map[int64]*types.LightBlock)
+	for height := startHeight; height >= stopHeight-10; height-- {
+		chain[height] = mockLB(t, height, endTime, factory.MakeBlockID())
+	}
+	// corrupt a few commits, which must fail verification in either mode
+	for _, height := range []int64{startHeight, 150, stopHeight} {
+		chain[height].Commit.Signatures[0].Signature = []byte("invalid")
+	}
+
+	serialVerified, serialInvalid := runBlockQueue(chain, 0)
+	require.Equal(t, []int64{startHeight, 150, stopHeight}, serialInvalid)
+	require.Len(t, serialVerified, int(startHeight-stopHeight)+1)
+
+	for _, workers := range []int{1, 4} {
+		verified, invalid := runBlockQueue(chain, workers)
+		require.Equal(t, serialVerified, verified)
+		require.Equal(t, serialInvalid, invalid)
+	}
+}
+
+func BenchmarkBlockQueueVerifyCommits(b *testing.B) {
+	chain := make(map[int64]*types.LightBlock)
+	for height := startHeight; height >= stopHeight-10; height-- {
+		chain[height] = mockLBWithValidators(b, height, endTime, factory.MakeBlockID(), 30)
+	}
+
+	for _, workers := range []int{0, 4} {
+		workers := workers
+		name := "serial"
+		if workers > 0 {
+			name = fmt.Sprintf("parallel-%d", workers)
+		}
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				runBlockQueue(chain, workers)
+			}
+		})
+	}
+}
+
+// runBlockQueue runs the light blocks of the chain through a block queue,
+// verifying their commits across the given number of workers, or serially in
+// the verifying thread if 0. It returns the heights in the order they were
+// verified, and the heights whose commit failed verification.
+func runBlockQueue(chain map[int64]*types.LightBlock, workers int) (verified, invalid []int64) {
+	peerID := p2p.NodeID("0011223344556677889900112233445566778899")
+	verifyCommit := func(lb *types.LightBlock) error {
+		return lb.ValidatorSet.VerifyCommitLight(factory.DefaultTestChainID, lb.Commit.BlockID, lb.Height, lb.Commit)
+	}
+
+	queue := newBlockQueue(startHeight, stopHeight, 1, stopTime, time.Time{}, 0, 1, nil)
+	add := queue.add
+	if workers > 0 {
+		add = queue.verifyCommits(workers, verifyCommit)
+	}
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i <= numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case height := <-queue.nextHeight():
+					if lb, ok := chain[height]; ok {
+						add(lightBlockResponse{block: lb, peer: peerID})
+					}
+				case <-queue.done():
+					return
+				}
+			}
+		}()
+	}
+
+	for {
+		select {
+		case resp := <-queue.verifyNext():
+			err := resp.commitErr
+			if workers == 0 {
+				err = verifyCommit(resp.block)
+			}
+			if err != nil {
+				invalid = append(invalid, resp.block.Height)
+			}
+			verified = append(verified, resp.block.Height)
+			queue.success(resp.block.Height)
+
+		case <-queue.done():
+			wg.Wait()
+			return verified, invalid
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package statesync
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -13,8 +16,15 @@ import (
 	"github.com/tendermint/tendermint/internal/p2p"
 )
 
-// errDone is returned by chunkQueue.Next() when all chunks have been returned.
-var errDone = errors.New("chunk queue has completed")
+var (
+	// errDone is returned by chunkQueue.Next() when all chunks have been returned.
+	errDone = errors.New("chunk queue has completed")
+	// errChunkMissing is returned by verifySnapshotComplete() when a chunk is missing.
+	errChunkMissing = errors.New("chunk is missing")
+	// errChunkMismatch is returned by verifySnapshotComplete() when a chunk doesn't match the
+	// manifest.
+	errChunkMismatch = errors.New("chunk hash does not match manifest")
+)
 
 // chunk contains data for a chunk.
 type chunk struct {
@@ -32,6 +42,7 @@ type chunkQueue struct {
 	tmsync.Mutex
 	snapshot       *snapshot                  // if this is nil, the queue has been closed
 	dir            string                     // temp dir for on-disk chunk storage
+	persistent     bool                       // whether the chunks are kept on disk when closed
 	chunkFiles     map[uint32]string          // path to temporary chunk file
 	chunkSenders   map[uint32]p2p.NodeID      // the peer who sent the given chunk
 	chunkAllocated map[uint32]bool            // chunks that have been allocated via Allocate()
@@ -61,6 +72,109 @@ func newChunkQueue(snapshot *snapshot, tempDir string) (*chunkQueue, error) {
 	}, nil
 }
 
+// newPersistentChunkQueue creates a new chunk queue for a snapshot, which keeps its chunks, along
+// with the peers that sent them, in a directory within dir named after the snapshot, so that a
+// restore interrupted by a restart resumes with the chunks already fetched. The directories of
+// other snapshots are removed, since a restore only ever resumes the latest one. Chunks are only
+// resumed if the snapshot metadata is a manifest of their SHA-256 hashes, and the ones that don't
+// match it, e.g. because they were corrupted on disk, are discarded to be fetched again. Closing the
+// queue keeps its directory, callers must call Purge() once the chunks are no longer needed.
+func newPersistentChunkQueue(snapshot *snapshot, dir string) (*chunkQueue, error) {
+	if snapshot.Chunks == 0 {
+		return nil, errors.New("snapshot has no chunks")
+	}
+	key := snapshot.Key()
+	name := hex.EncodeToString(key[:])
+	if err := removeStaleSnapshotDirs(dir, name); err != nil {
+		return nil, err
+	}
+	dir = filepath.Join(dir, name)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("unable to create dir for state sync chunks: %w", err)
+	}
+
+	q := &chunkQueue{
+		snapshot:       snapshot,
+		dir:            dir,
+		persistent:     true,
+		chunkFiles:     make(map[uint32]string, snapshot.Chunks),
+		chunkSenders:   make(map[uint32]p2p.NodeID, snapshot.Chunks),
+		chunkAllocated: make(map[uint32]bool, snapshot.Chunks),
+		chunkReturned:  make(map[uint32]bool, snapshot.Chunks),
+		waiters:        make(map[uint32][]chan<- uint32),
+	}
+	manifest, ok := manifestFromMetadata(snapshot)
+	for index := uint32(0); index < snapshot.Chunks; index++ {
+		path := q.chunkPath(index)
+		body, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to load chunk %v: %w", index, err)
+		}
+		sender, err := ioutil.ReadFile(path + ".sender")
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to load sender of chunk %v: %w", index, err)
+		}
+
+		// a chunk that can't be verified, or blamed on its sender, is fetched again
+		bodyHash := sha256.Sum256(body)
+		if !ok || len(sender) == 0 || !bytes.Equal(bodyHash[:], manifest[index]) {
+			if err := removeChunkFiles(path); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		// the chunk was fetched already, so it isn't allocated again
+		q.chunkFiles[index] = path
+		q.chunkSenders[index] = p2p.NodeID(sender)
+		q.chunkAllocated[index] = true
+	}
+
+	return q, nil
+}
+
+// removeStaleSnapshotDirs removes the chunk directories within dir of snapshots other than the
+// one named keep.
+func removeStaleSnapshotDirs(dir string, keep string) error {
+	entries, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("unable to read state sync chunk dir %v: %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || name == keep || len(name) != 2*len(snapshotKey{}) {
+			continue
+		}
+		if _, err := hex.DecodeString(name); err != nil {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(dir, name)); err != nil {
+			return fmt.Errorf("failed to remove stale state sync chunks %v: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// chunkPath returns the path of the file to save the given chunk to.
+func (q *chunkQueue) chunkPath(index uint32) string {
+	return filepath.Join(q.dir, strconv.FormatUint(uint64(index), 10))
+}
+
+// removeChunkFiles removes the file of a chunk and the one of its sender, if any.
+func removeChunkFiles(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove chunk file %v: %w", path, err)
+	}
+	if err := os.Remove(path + ".sender"); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove chunk sender file %v: %w", path, err)
+	}
+	return nil
+}
+
 // Add adds a chunk to the queue. It ignores chunks that already exist, returning false.
 func (q *chunkQueue) Add(chunk *chunk) (bool, error) {
 	if chunk == nil || chunk.Chunk == nil {
@@ -86,11 +200,16 @@ func (q *chunkQueue) Add(chunk *chunk) (bool, error) {
 		return false, nil
 	}
 
-	path := filepath.Join(q.dir, strconv.FormatUint(uint64(chunk.Index), 10))
+	path := q.chunkPath(chunk.Index)
 	err := ioutil.WriteFile(path, chunk.Chunk, 0600)
 	if err != nil {
 		return false, fmt.Errorf("failed to save chunk %v to file %v: %w", chunk.Index, path, err)
 	}
+	if q.persistent {
+		if err := ioutil.WriteFile(path+".sender", []byte(chunk.Sender), 0600); err != nil {
+			return false, fmt.Errorf("failed to save sender of chunk %v: %w", chunk.Index, err)
+		}
+	}
 
 	q.chunkFiles[chunk.Index] = path
 	q.chunkSenders[chunk.Index] = chunk.Sender
@@ -130,11 +249,32 @@ func (q *chunkQueue) Allocate() (uint32, error) {
 	return 0, errDone
 }
 
-// Close closes the chunk queue, cleaning up all temporary files.
+// Close closes the chunk queue, cleaning up all temporary files. The chunks of a persistent
+// queue are kept on disk.
 func (q *chunkQueue) Close() error {
 	q.Lock()
 	defer q.Unlock()
+	return q.close(!q.persistent)
+}
+
+// Purge closes the chunk queue, like Close(), and removes its chunks from disk even if the queue is
+// persistent.
+func (q *chunkQueue) Purge() error {
+	q.Lock()
+	defer q.Unlock()
+
+	if q.snapshot == nil {
+		if err := os.RemoveAll(q.dir); err != nil {
+			return fmt.Errorf("failed to clean up state sync dir %v: %w", q.dir, err)
+		}
+		return nil
+	}
+	return q.close(true)
+}
 
+// close closes the chunk queue, removing its dir if requested. The caller must hold the mutex
+// lock.
+func (q *chunkQueue) close(remove bool) error {
 	if q.snapshot == nil {
 		return nil
 	}
@@ -148,6 +288,9 @@ func (q *chunkQueue) Close() error {
 	q.waiters = nil
 	q.snapshot = nil
 
+	if !remove {
+		return nil
+	}
 	if err := os.RemoveAll(q.dir); err != nil {
 		return fmt.Errorf("failed to clean up state sync tempdir %v: %w", q.dir, err)
 	}
@@ -178,6 +321,11 @@ func (q *chunkQueue) discard(index uint32) error {
 	if err := os.Remove(path); err != nil {
 		return fmt.Errorf("failed to remove chunk %v: %w", index, err)
 	}
+	if q.persistent {
+		if err := removeChunkFiles(path); err != nil {
+			return err
+		}
+	}
 
 	delete(q.chunkFiles, index)
 	delete(q.chunkReturned, index)
@@ -214,6 +362,13 @@ func (q *chunkQueue) GetSender(index uint32) p2p.NodeID {
 	return q.chunkSenders[index]
 }
 
+// Fetched returns the number of chunks in the queue.
+func (q *chunkQueue) Fetched() int {
+	q.Lock()
+	defer q.Unlock()
+	return len(q.chunkFiles)
+}
+
 // Has checks whether a chunk exists in the queue.
 func (q *chunkQueue) Has(index uint32) bool {
 	q.Lock()
@@ -355,3 +510,52 @@ func (q *chunkQueue) WaitFor(index uint32) <-chan uint32 {
 
 	return ch
 }
+
+// snapshotManifest lists the SHA-256 hashes of a snapshot's chunks, by chunk index.
+type snapshotManifest [][]byte
+
+// manifestFromMetadata returns the manifest of a snapshot whose metadata is the concatenation of
+// its chunks' SHA-256 hashes, by chunk index. It returns false if the metadata isn't a manifest,
+// which is up to the app.
+func manifestFromMetadata(snapshot *snapshot) (snapshotManifest, bool) {
+	if snapshot.Chunks == 0 || uint64(len(snapshot.Metadata)) != uint64(snapshot.Chunks)*sha256.Size {
+		return nil, false
+	}
+
+	manifest := make(snapshotManifest, snapshot.Chunks)
+	for i := range manifest {
+		manifest[i] = snapshot.Metadata[i*sha256.Size : (i+1)*sha256.Size]
+	}
+	return manifest, true
+}
+
+// verifySnapshotComplete verifies that the queue holds every chunk listed in the manifest, and
+// that their hashes match it. Otherwise, it returns the index of the first missing or mismatched
+// chunk, along with errChunkMissing or errChunkMismatch respectively.
+func verifySnapshotComplete(manifest snapshotManifest, chunks *chunkQueue) (uint32, error) {
+	chunks.Lock()
+	defer chunks.Unlock()
+
+	if chunks.snapshot == nil {
+		return 0, errors.New("chunk queue is closed")
+	}
+	if uint32(len(manifest)) != chunks.snapshot.Chunks {
+		return 0, fmt.Errorf("manifest has %v chunks, expected %v", len(manifest), chunks.snapshot.Chunks)
+	}
+
+	for i, hash := range manifest {
+		index := uint32(i)
+		chunk, err := chunks.load(index)
+		if err != nil {
+			return index, err
+		}
+		if chunk == nil {
+			return index, fmt.Errorf("chunk %v: %w", index, errChunkMissing)
+		}
+		if chunkHash := sha256.Sum256(chunk.Chunk); !bytes.Equal(chunkHash[:], hash) {
+			return index, fmt.Errorf("chunk %v: %w", index, errChunkMismatch)
+		}
+	}
+
+	return 0, nil
+}
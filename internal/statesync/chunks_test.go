@@ -1,8 +1,11 @@
 package statesync
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -54,6 +57,108 @@ func TestNewChunkQueue_TempDir(t *testing.T) {
 	assert.Len(t, files, 0)
 }
 
+func TestNewPersistentChunkQueue_Resume(t *testing.T) {
+	// the metadata is a manifest of the chunks' hashes
+	metadata := []byte{}
+	for i := 0; i < 6; i++ {
+		hash := sha256.Sum256([]byte{3, 1, byte(i)})
+		metadata = append(metadata, hash[:]...)
+	}
+	snapshot := &snapshot{
+		Height:   3,
+		Format:   1,
+		Chunks:   6,
+		Hash:     []byte{7},
+		Metadata: metadata,
+	}
+	dir := t.TempDir()
+	queue, err := newPersistentChunkQueue(snapshot, dir)
+	require.NoError(t, err)
+	require.Zero(t, queue.Fetched())
+
+	// fetch half of the chunks before restarting
+	for i := uint32(0); i < 3; i++ {
+		index, err := queue.Allocate()
+		require.NoError(t, err)
+		require.Equal(t, i, index)
+		_, err = queue.Add(&chunk{Height: 3, Format: 1, Index: index, Chunk: []byte{3, 1, byte(index)}, Sender: "a"})
+		require.NoError(t, err)
+	}
+	index, err := queue.Allocate()
+	require.NoError(t, err)
+	require.EqualValues(t, 3, index)
+	require.NoError(t, queue.Close())
+
+	// after the restart, only the chunks that weren't fetched are allocated
+	queue, err = newPersistentChunkQueue(snapshot, dir)
+	require.NoError(t, err)
+	require.Equal(t, 3, queue.Fetched())
+	for _, expected := range []uint32{3, 4, 5} {
+		index, err := queue.Allocate()
+		require.NoError(t, err)
+		require.Equal(t, expected, index)
+	}
+	_, err = queue.Allocate()
+	require.Equal(t, errDone, err)
+
+	// resumed chunks keep their sender
+	next, err := queue.Next()
+	require.NoError(t, err)
+	require.Equal(t, []byte{3, 1, 0}, next.Chunk)
+	require.EqualValues(t, "a", next.Sender)
+	require.NoError(t, queue.Close())
+
+	// a chunk that doesn't match the manifest is fetched again, as is one
+	// whose sender is missing
+	key := snapshot.Key()
+	path := filepath.Join(dir, hex.EncodeToString(key[:]))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(path, "1"), []byte{9}, 0600))
+	require.NoError(t, os.Remove(filepath.Join(path, "2.sender")))
+
+	queue, err = newPersistentChunkQueue(snapshot, dir)
+	require.NoError(t, err)
+	require.Equal(t, 1, queue.Fetched())
+	require.True(t, queue.Has(0))
+	require.False(t, queue.Has(1))
+	for _, expected := range []uint32{1, 2, 3, 4, 5} {
+		index, err := queue.Allocate()
+		require.NoError(t, err)
+		require.Equal(t, expected, index)
+	}
+
+	require.NoError(t, queue.Close())
+
+	// chunks aren't resumed if the metadata isn't a manifest
+	unverifiable := *snapshot
+	unverifiable.Metadata = []byte{1}
+	unverifiableQueue, err := newPersistentChunkQueue(&unverifiable, dir)
+	require.NoError(t, err)
+	_, err = unverifiableQueue.Add(&chunk{Height: 3, Format: 1, Index: 0, Chunk: []byte{3, 1, 0}, Sender: "a"})
+	require.NoError(t, err)
+	require.NoError(t, unverifiableQueue.Close())
+	unverifiableQueue, err = newPersistentChunkQueue(&unverifiable, dir)
+	require.NoError(t, err)
+	require.Zero(t, unverifiableQueue.Fetched())
+	require.NoError(t, unverifiableQueue.Close())
+
+	// the chunks of other snapshots were removed
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	queue, err = newPersistentChunkQueue(snapshot, dir)
+	require.NoError(t, err)
+	require.Zero(t, queue.Fetched())
+	files, err = ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	// once purged, the chunks are gone
+	require.NoError(t, queue.Purge())
+	files, err = ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, files)
+}
+
 func TestChunkQueue(t *testing.T) {
 	queue, teardown := setupChunkQueue(t)
 	defer teardown()
@@ -552,3 +657,55 @@ func TestChunkQueue_WaitFor(t *testing.T) {
 	_, ok = <-w
 	assert.False(t, ok)
 }
+
+func TestVerifySnapshotComplete(t *testing.T) {
+	manifest := make(snapshotManifest, 5)
+	for i := range manifest {
+		hash := sha256.Sum256([]byte{3, 1, byte(i)})
+		manifest[i] = hash[:]
+	}
+
+	testcases := map[string]struct {
+		manifest  snapshotManifest
+		chunks    []uint32
+		mismatch  uint32
+		expectIdx uint32
+		expectErr error
+	}{
+		"complete":     {manifest, []uint32{0, 1, 2, 3, 4}, 99, 0, nil},
+		"missing":      {manifest, []uint32{0, 1, 3, 4}, 99, 2, errChunkMissing},
+		"first miss":   {manifest, []uint32{0, 1, 3}, 99, 2, errChunkMissing},
+		"bad hash":     {manifest, []uint32{0, 1, 2, 3, 4}, 3, 3, errChunkMismatch},
+		"miss and bad": {manifest, []uint32{0, 2, 3, 4}, 3, 1, errChunkMissing},
+	}
+	for name, tc := range testcases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			queue, teardown := setupChunkQueue(t)
+			defer teardown()
+
+			for _, index := range tc.chunks {
+				body := []byte{3, 1, byte(index)}
+				if index == tc.mismatch {
+					body = []byte{9}
+				}
+				_, err := queue.Add(&chunk{Height: 3, Format: 1, Index: index, Chunk: body})
+				require.NoError(t, err)
+			}
+
+			index, err := verifySnapshotComplete(tc.manifest, queue)
+			if tc.expectErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tc.expectErr)
+			}
+			require.Equal(t, tc.expectIdx, index)
+		})
+	}
+
+	// The manifest must list all of the snapshot's chunks.
+	queue, teardown := setupChunkQueue(t)
+	defer teardown()
+	_, err := verifySnapshotComplete(manifest[:4], queue)
+	require.Error(t, err)
+}
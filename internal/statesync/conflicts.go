@@ -0,0 +1,140 @@
+package statesync
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/types"
+)
+
+// conflictingBlock is a light block served by a peer when backfilling, that
+// conflicts with one served by another peer at the same height.
+type conflictingBlock struct {
+	block *types.LightBlock
+	peer  p2p.NodeID
+}
+
+// lunaticAttack is a conflicting block with an invalid header, i.e. not
+// derived from the state of the trusted block at the same height, which
+// evidence can only be formed for once the block below it is verified too.
+type lunaticAttack struct {
+	conflicting *types.LightBlock
+	trusted     *types.LightBlock
+}
+
+// backfillConflicts tracks the conflicting light blocks found by crossCheck
+// when backfilling. Until the chain of blocks is verified down to a height,
+// there's no telling which of the blocks served at it are fake, so they are
+// kept until then, see verified.
+type backfillConflicts struct {
+	mtx     sync.Mutex
+	blocks  map[int64][]conflictingBlock
+	lunatic map[int64][]lunaticAttack // by common height
+}
+
+func newBackfillConflicts() *backfillConflicts {
+	return &backfillConflicts{
+		blocks:  make(map[int64][]conflictingBlock),
+		lunatic: make(map[int64][]lunaticAttack),
+	}
+}
+
+// add records the given conflicting blocks served at the given height.
+func (c *backfillConflicts) add(height int64, blocks ...conflictingBlock) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.blocks[height] = append(c.blocks[height], blocks...)
+}
+
+// peers returns the peers that served any of the conflicting blocks recorded
+// at the given height, which the block is preferably fetched from others than
+// when retried.
+func (c *backfillConflicts) peers(height int64) []p2p.NodeID {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	var peers []p2p.NodeID
+	seen := make(map[p2p.NodeID]bool)
+	for _, conflicting := range c.blocks[height] {
+		if !seen[conflicting.peer] {
+			seen[conflicting.peer] = true
+			peers = append(peers, conflicting.peer)
+		}
+	}
+	return peers
+}
+
+// verified is called with every light block once it has been verified, and
+// returns the evidence of the light client attacks that can now be formed,
+// along with the peers that served fake blocks at its height, to be punished.
+// A fake block with an invalid header is a lunatic attack, the evidence for
+// which uses the block below it as the common block, and is therefore only
+// returned once that one is verified as well.
+func (c *backfillConflicts) verified(lb *types.LightBlock) ([]*types.LightClientAttackEvidence, []p2p.NodeID) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	var (
+		evidence []*types.LightClientAttackEvidence
+		peers    []p2p.NodeID
+	)
+	for _, attack := range c.lunatic[lb.Height] {
+		evidence = append(evidence, newLightClientAttackEvidence(attack.conflicting, attack.trusted, lb))
+	}
+	delete(c.lunatic, lb.Height)
+
+	var (
+		hash     = lb.Hash()
+		seen     = make(map[string]bool)
+		punished = make(map[p2p.NodeID]bool)
+	)
+	for _, conflicting := range c.blocks[lb.Height] {
+		fakeHash := conflicting.block.Hash()
+		if bytes.Equal(fakeHash, hash) {
+			continue
+		}
+		if !punished[conflicting.peer] {
+			punished[conflicting.peer] = true
+			peers = append(peers, conflicting.peer)
+		}
+		if seen[string(fakeHash)] {
+			continue
+		}
+		seen[string(fakeHash)] = true
+
+		ev := &types.LightClientAttackEvidence{ConflictingBlock: conflicting.block}
+		if ev.ConflictingHeaderIsInvalid(lb.Header) {
+			c.lunatic[lb.Height-1] = append(c.lunatic[lb.Height-1], lunaticAttack{
+				conflicting: conflicting.block,
+				trusted:     lb,
+			})
+			continue
+		}
+		evidence = append(evidence, newLightClientAttackEvidence(conflicting.block, lb, lb))
+	}
+	delete(c.blocks, lb.Height)
+
+	return evidence, peers
+}
+
+// newLightClientAttackEvidence forms the evidence of a light client attack
+// from the conflicting block, the trusted block at the same height and the
+// common block, the same way the light client does.
+func newLightClientAttackEvidence(conflicted, trusted, common *types.LightBlock) *types.LightClientAttackEvidence {
+	ev := &types.LightClientAttackEvidence{ConflictingBlock: conflicted}
+	// if this is an equivocation or amnesia attack, i.e. the validator sets are
+	// the same, then we use the height of the conflicting block, else if it is
+	// a lunatic attack we use the height of the common block.
+	if ev.ConflictingHeaderIsInvalid(trusted.Header) {
+		ev.CommonHeight = common.Height
+		ev.Timestamp = common.Time
+		ev.TotalVotingPower = common.ValidatorSet.TotalVotingPower()
+	} else {
+		ev.CommonHeight = trusted.Height
+		ev.Timestamp = trusted.Time
+		ev.TotalVotingPower = trusted.ValidatorSet.TotalVotingPower()
+	}
+	ev.ByzantineValidators = ev.GetByzantineValidators(common.ValidatorSet, trusted.SignedHeader)
+	return ev
+}
@@ -0,0 +1,107 @@
+package statesync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/internal/test/factory"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestBackfillConflicts_Equivocation(t *testing.T) {
+	const height int64 = 10
+	blockTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	lastBlockID := factory.MakeBlockID()
+	vals, pv := factory.RandValidatorSet(3, 10)
+
+	// the same validators sign two blocks at the same height, only differing
+	// in their time
+	header, err := factory.MakeHeader(&types.Header{
+		Height:      height,
+		LastBlockID: lastBlockID,
+		Time:        blockTime,
+	})
+	require.NoError(t, err)
+	header.ValidatorsHash = vals.Hash()
+	trusted := signedLightBlock(t, header, vals, pv)
+	fakeHeader := *header
+	fakeHeader.Time = blockTime.Add(time.Second)
+	fake := signedLightBlock(t, &fakeHeader, vals, pv)
+
+	conflicts := newBackfillConflicts()
+	conflicts.add(height,
+		conflictingBlock{block: trusted, peer: "a"},
+		conflictingBlock{block: fake, peer: "b"},
+		conflictingBlock{block: fake, peer: "c"})
+	conflicts.add(height,
+		conflictingBlock{block: trusted, peer: "a"},
+		conflictingBlock{block: fake, peer: "b"})
+
+	// the height is retried avoiding all the peers involved
+	require.ElementsMatch(t, []p2p.NodeID{"a", "b", "c"}, conflicts.peers(height))
+	require.Empty(t, conflicts.peers(height-1))
+
+	// the evidence is formed right away, once, and every peer that served the
+	// fake block is punished, once
+	evidence, peers := conflicts.verified(trusted)
+	require.ElementsMatch(t, []p2p.NodeID{"b", "c"}, peers)
+	require.Len(t, evidence, 1)
+	ev := evidence[0]
+	require.Equal(t, fake.Hash(), ev.ConflictingBlock.Hash())
+	require.Equal(t, height, ev.CommonHeight)
+	require.Equal(t, trusted.Time, ev.Timestamp)
+	require.Equal(t, vals.TotalVotingPower(), ev.TotalVotingPower)
+	require.Len(t, ev.ByzantineValidators, vals.Size())
+
+	// the conflicts are forgotten once resolved
+	evidence, peers = conflicts.verified(trusted)
+	require.Empty(t, evidence)
+	require.Empty(t, peers)
+	require.Empty(t, conflicts.peers(height))
+}
+
+func TestBackfillConflicts_Lunatic(t *testing.T) {
+	const height int64 = 10
+	startTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	chain := buildLightBlockChain(t, height-1, height+1, startTime)
+	fake := mockLB(t, height, chain[height].Time, chain[height].LastBlockID)
+
+	conflicts := newBackfillConflicts()
+	conflicts.add(height,
+		conflictingBlock{block: chain[height], peer: "a"},
+		conflictingBlock{block: fake, peer: "b"})
+
+	// the peer is punished right away, but the evidence is only formed once
+	// the common block below is verified
+	evidence, peers := conflicts.verified(chain[height])
+	require.Equal(t, []p2p.NodeID{"b"}, peers)
+	require.Empty(t, evidence)
+
+	evidence, peers = conflicts.verified(chain[height-1])
+	require.Empty(t, peers)
+	require.Len(t, evidence, 1)
+	ev := evidence[0]
+	require.Equal(t, fake.Hash(), ev.ConflictingBlock.Hash())
+	require.Equal(t, height-1, ev.CommonHeight)
+	require.Equal(t, chain[height-1].Time, ev.Timestamp)
+	require.Equal(t, chain[height-1].ValidatorSet.TotalVotingPower(), ev.TotalVotingPower)
+}
+
+func signedLightBlock(t *testing.T, header *types.Header, vals *types.ValidatorSet,
+	pv []types.PrivValidator) *types.LightBlock {
+	blockID := factory.MakeBlockIDWithHash(header.Hash())
+	voteSet := types.NewVoteSet(factory.DefaultTestChainID, header.Height, 0, tmproto.PrecommitType, vals)
+	commit, err := factory.MakeCommit(blockID, header.Height, 0, voteSet, pv, header.Time)
+	require.NoError(t, err)
+	return &types.LightBlock{
+		SignedHeader: &types.SignedHeader{
+			Header: header,
+			Commit: commit,
+		},
+		ValidatorSet: vals,
+	}
+}
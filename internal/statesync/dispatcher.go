@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	mrand "math/rand"
 	"sync"
 	"time"
 
@@ -20,6 +21,8 @@ var (
 	errNoResponse          = errors.New("peer failed to respond within timeout")
 	errPeerAlreadyBusy     = errors.New("peer is already processing a request")
 	errDisconnected        = errors.New("dispatcher has been disconnected")
+	errConflictingBlock    = errors.New("witness served a conflicting light block")
+	errNotEnoughWitnesses  = errors.New("not enough witnesses served the light block")
 )
 
 // dispatcher keeps a list of peers and allows concurrent requests for light
@@ -62,6 +65,21 @@ func (d *dispatcher) LightBlock(ctx context.Context, height int64) (*types.Light
 	return lb, peer, err
 }
 
+// lightBlockAvoiding is LightBlock, requesting the light block from an
+// available peer other than the given ones if there is any, and from the next
+// peer otherwise.
+func (d *dispatcher) lightBlockAvoiding(
+	ctx context.Context,
+	height int64,
+	avoid []p2p.NodeID,
+) (*types.LightBlock, p2p.NodeID, error) {
+	if peer, ok := d.availablePeers.PopAvoiding(avoid); ok {
+		lb, err := d.lightBlock(ctx, height, peer)
+		return lb, peer, err
+	}
+	return d.LightBlock(ctx, height)
+}
+
 func (d *dispatcher) Providers(chainID string, timeout time.Duration) []provider.Provider {
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
@@ -79,6 +97,13 @@ func (d *dispatcher) Providers(chainID string, timeout time.Duration) []provider
 	return providers
 }
 
+// shufflePeers makes the dispatcher request light blocks from its available
+// peers in random order rather than in turn, so that the load of a sync is
+// spread evenly over the peers however they start out ordered.
+func (d *dispatcher) shufflePeers(rand *mrand.Rand) {
+	d.availablePeers.shuffle(rand)
+}
+
 func (d *dispatcher) stop() {
 	d.mtx.Lock()
 	defer d.mtx.Unlock()
@@ -95,6 +120,21 @@ func (d *dispatcher) start() {
 	d.running = true
 }
 
+// lightBlockFrom requests a light block from a given peer. Unlike lightBlock,
+// the peer doesn't have to be popped from the available peers first: it is
+// taken out of them for the duration of the request.
+func (d *dispatcher) lightBlockFrom(ctx context.Context, height int64, peer p2p.NodeID) (*types.LightBlock, error) {
+	d.availablePeers.Remove(peer)
+	return d.lightBlock(ctx, height, peer)
+}
+
+// popWitnesses pops up to n available peers other than the given one, to be
+// requested a light block from with lightBlock. Unlike LightBlock, it doesn't
+// wait for peers to become available.
+func (d *dispatcher) popWitnesses(n int, except p2p.NodeID) []p2p.NodeID {
+	return d.availablePeers.PopExcept(n, except)
+}
+
 func (d *dispatcher) lightBlock(ctx context.Context, height int64, peer p2p.NodeID) (*types.LightBlock, error) {
 	// dispatch the request to the peer
 	callCh, err := d.dispatch(peer, height)
@@ -149,6 +189,15 @@ func (d *dispatcher) respond(lb *proto.LightBlock, peer p2p.NodeID) error {
 	return nil
 }
 
+// awaiting reports whether a light block was requested from the peer and not
+// responded to yet.
+func (d *dispatcher) awaiting(peer p2p.NodeID) bool {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	_, ok := d.calls[peer]
+	return ok
+}
+
 func (d *dispatcher) addPeer(peer p2p.NodeID) {
 	d.availablePeers.Append(peer)
 }
@@ -257,6 +306,7 @@ type peerlist struct {
 	mtx     sync.Mutex
 	peers   []p2p.NodeID
 	waiting []chan p2p.NodeID
+	rand    *mrand.Rand // if set, peers are popped in random order
 }
 
 func newPeerList() *peerlist {
@@ -285,12 +335,65 @@ func (l *peerlist) Pop() p2p.NodeID {
 		return peer
 	}
 
-	peer := l.peers[0]
-	l.peers = l.peers[1:]
+	index := 0
+	if l.rand != nil {
+		index = l.rand.Intn(len(l.peers))
+	}
+	peer := l.peers[index]
+	l.peers = append(l.peers[:index], l.peers[index+1:]...)
 	l.mtx.Unlock()
 	return peer
 }
 
+// PopExcept pops up to n peers other than the given one, in the order they
+// were appended. Unlike Pop, it returns fewer peers rather than waiting for
+// them to be appended.
+func (l *peerlist) PopExcept(n int, except p2p.NodeID) []p2p.NodeID {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	peers := make([]p2p.NodeID, 0, n)
+	for i := 0; i < len(l.peers) && len(peers) < n; {
+		if l.peers[i] == except {
+			i++
+			continue
+		}
+		peers = append(peers, l.peers[i])
+		l.peers = append(l.peers[:i], l.peers[i+1:]...)
+	}
+	return peers
+}
+
+// PopAvoiding pops the first peer that isn't one of the given ones, and false
+// if there is none. Unlike Pop, it doesn't wait for a peer to be appended.
+func (l *peerlist) PopAvoiding(avoid []p2p.NodeID) (p2p.NodeID, bool) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	for i, peer := range l.peers {
+		avoided := false
+		for _, a := range avoid {
+			if peer == a {
+				avoided = true
+				break
+			}
+		}
+		if !avoided {
+			l.peers = append(l.peers[:i], l.peers[i+1:]...)
+			return peer, true
+		}
+	}
+	return "", false
+}
+
+// shuffle makes Pop return a random peer from the list using the given
+// source of randomness, rather than the peer appended first.
+func (l *peerlist) shuffle(rand *mrand.Rand) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.rand = rand
+}
+
 func (l *peerlist) Append(peer p2p.NodeID) {
 	l.mtx.Lock()
 	defer l.mtx.Unlock()
@@ -3,6 +3,7 @@ package statesync
 import (
 	"context"
 	"fmt"
+	mrand "math/rand"
 	"strings"
 	"sync"
 	"testing"
@@ -107,6 +108,77 @@ func TestPeerListBasic(t *testing.T) {
 
 }
 
+func TestPeerListShuffle(t *testing.T) {
+	peerList := newPeerList()
+	peerList.shuffle(mrand.New(mrand.NewSource(1)))
+	peerSet := createPeerSet(10)
+	for _, peer := range peerSet {
+		peerList.Append(peer)
+	}
+
+	// all peers are popped, but not in the order they were appended
+	popped := make([]p2p.NodeID, 0, len(peerSet))
+	for range peerSet {
+		popped = append(popped, peerList.Pop())
+	}
+	require.ElementsMatch(t, peerSet, popped)
+	require.NotEqual(t, peerSet, popped)
+	require.Zero(t, peerList.Len())
+
+	// peers that are popped and appended back again, as the dispatcher does,
+	// are popped about as often as each other
+	for _, peer := range peerSet {
+		peerList.Append(peer)
+	}
+	const pops = 10000
+	counts := make(map[p2p.NodeID]int)
+	for i := 0; i < pops; i++ {
+		peer := peerList.Pop()
+		counts[peer]++
+		peerList.Append(peer)
+	}
+	mean := pops / len(peerSet)
+	for _, peer := range peerSet {
+		require.InDelta(t, mean, counts[peer], float64(mean)/5, "peer %v", peer)
+	}
+}
+
+func TestPeerListPopExcept(t *testing.T) {
+	peerList := newPeerList()
+	peerSet := createPeerSet(4)
+	for _, peer := range peerSet {
+		peerList.Append(peer)
+	}
+
+	// the excluded peer is skipped and stays in the list
+	require.Equal(t, []p2p.NodeID{peerSet[0], peerSet[2]}, peerList.PopExcept(2, peerSet[1]))
+	require.Equal(t, []p2p.NodeID{peerSet[1], peerSet[3]}, peerList.Peers())
+
+	// fewer peers are popped if there aren't enough, without blocking
+	require.Equal(t, []p2p.NodeID{peerSet[3]}, peerList.PopExcept(2, peerSet[1]))
+	require.Empty(t, peerList.PopExcept(2, peerSet[1]))
+	require.Equal(t, 1, peerList.Len())
+}
+
+func TestPeerListPopAvoiding(t *testing.T) {
+	peerList := newPeerList()
+	peerSet := createPeerSet(3)
+	for _, peer := range peerSet {
+		peerList.Append(peer)
+	}
+
+	// the first peer not avoided is popped, the avoided ones stay in the list
+	peer, ok := peerList.PopAvoiding(peerSet[:2])
+	require.True(t, ok)
+	require.Equal(t, peerSet[2], peer)
+	require.Equal(t, peerSet[:2], peerList.Peers())
+
+	// if all peers are avoided, none is popped, without blocking
+	_, ok = peerList.PopAvoiding(peerSet[:2])
+	require.False(t, ok)
+	require.Equal(t, 2, peerList.Len())
+}
+
 func TestPeerListConcurrent(t *testing.T) {
 	peerList := newPeerList()
 	numPeers := 10
@@ -0,0 +1,248 @@
+package statesync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+	ssproto "github.com/tendermint/tendermint/proto/tendermint/statesync"
+)
+
+// dryRunLightBlockSamples is the number of light blocks a dry run requests
+// from each peer.
+const dryRunLightBlockSamples = 5
+
+// DryRunReport is the outcome of a state sync dry run.
+type DryRunReport struct {
+	// Snapshots are the discovered snapshots whose height could be verified by
+	// the state provider, best first.
+	Snapshots []DryRunSnapshot
+	// Heights are the heights of the light blocks requested from each peer,
+	// sampled between the height of the best snapshot and the height that
+	// backfilling it would stop at. Empty if no snapshot was discovered.
+	Heights []int64
+	// Peers are the results of probing each peer.
+	Peers map[p2p.NodeID]DryRunPeer
+}
+
+// DryRunSnapshot is a snapshot discovered in a dry run.
+type DryRunSnapshot struct {
+	Height uint64
+	Format uint32
+	Chunks uint32
+	// Peers are the peers that advertised the snapshot.
+	Peers []p2p.NodeID
+}
+
+// DryRunPeer is the result of probing a peer in a dry run.
+type DryRunPeer struct {
+	// Snapshots is the number of discovered snapshots the peer advertised.
+	Snapshots int
+	// LightBlocks is the number of sampled light blocks the peer served and
+	// which were verified, before it failed to serve one, if it did.
+	LightBlocks int
+	// Err is the reason the peer failed to serve a sampled light block, if any.
+	Err error
+}
+
+// Feasible returns true if the best snapshot is advertised by at least one
+// peer, and at least one peer served all the sampled light blocks. Chunks are
+// not fetched, so it doesn't tell whether the snapshot can be restored.
+func (r *DryRunReport) Feasible() bool {
+	if len(r.Snapshots) == 0 || len(r.Snapshots[0].Peers) == 0 {
+		return false
+	}
+
+	for _, peer := range r.Peers {
+		if peer.Err == nil && peer.LightBlocks == len(r.Heights) {
+			return true
+		}
+	}
+	return false
+}
+
+// DryRun probes the connected peers for a state sync without applying
+// anything. It discovers snapshots for discoveryTime, as Sync does, then
+// requests a sample of the light blocks needed to backfill the best snapshot
+// from each peer, and verifies them against the state provider. As a dry run
+// uses the snapshots being discovered, it can't run alongside a sync. A
+// discoveryTime of 0 is the minimum discovery time.
+func (r *Reactor) DryRun(
+	ctx context.Context,
+	stateProvider StateProvider,
+	discoveryTime time.Duration,
+) (*DryRunReport, error) {
+	r.mtx.Lock()
+	if r.syncer != nil {
+		r.mtx.Unlock()
+		return nil, errSyncInProgress
+	}
+
+	syncer := newSyncer(
+		r.cfg,
+		r.Logger,
+		r.conn,
+		r.connQuery,
+		stateProvider,
+		r.snapshotCh.Out,
+		r.chunkCh.Out,
+		r.tempDir,
+	)
+	r.syncer = syncer
+	r.mtx.Unlock()
+
+	defer func() {
+		r.mtx.Lock()
+		r.syncer = nil
+		r.mtx.Unlock()
+	}()
+
+	// unlike Sync, there are no previously discovered snapshots to fall back
+	// on, so discovery can't be skipped
+	if discoveryTime == 0 {
+		discoveryTime = minimumDiscoveryTime
+	}
+
+	r.Logger.Info("state sync dry run: discovering snapshots", "discovery_time", discoveryTime)
+	r.snapshotCh.Out <- p2p.Envelope{
+		Broadcast: true,
+		Message:   &ssproto.SnapshotsRequest{},
+	}
+
+	select {
+	case <-time.After(discoveryTime):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-r.closeCh:
+		return nil, errors.New("reactor stopped")
+	}
+
+	report := &DryRunReport{Peers: make(map[p2p.NodeID]DryRunPeer)}
+	for _, snapshot := range syncer.snapshots.Ranked() {
+		peers := syncer.snapshots.GetPeers(snapshot)
+		sort.Slice(peers, func(i, j int) bool { return peers[i] < peers[j] })
+		report.Snapshots = append(report.Snapshots, DryRunSnapshot{
+			Height: snapshot.Height,
+			Format: snapshot.Format,
+			Chunks: snapshot.Chunks,
+			Peers:  peers,
+		})
+		for _, peer := range peers {
+			p := report.Peers[peer]
+			p.Snapshots++
+			report.Peers[peer] = p
+		}
+	}
+
+	if len(report.Snapshots) == 0 {
+		return report, nil
+	}
+
+	best := report.Snapshots[0]
+	state, err := stateProvider.State(ctx, best.Height)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state at height %d: %w", best.Height, err)
+	}
+
+	stopHeight := state.LastBlockHeight - state.ConsensusParams.Evidence.MaxAgeNumBlocks
+	if stopHeight < state.InitialHeight {
+		stopHeight = state.InitialHeight
+	}
+	report.Heights = sampleHeights(state.LastBlockHeight, stopHeight, dryRunLightBlockSamples)
+
+	trustedHashes := make([][]byte, len(report.Heights))
+	for i, height := range report.Heights {
+		commit, err := stateProvider.Commit(ctx, uint64(height))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get commit at height %d: %w", height, err)
+		}
+		trustedHashes[i] = commit.BlockID.Hash
+	}
+
+	// the peer list is modified as requests are dispatched, so copy it first
+	peers := append([]p2p.NodeID(nil), r.dispatcher.availablePeers.Peers()...)
+
+	var (
+		mtx sync.Mutex
+		wg  sync.WaitGroup
+	)
+	for _, peer := range peers {
+		wg.Add(1)
+		go func(peer p2p.NodeID) {
+			defer wg.Done()
+
+			served := 0
+			var err error
+			for i, height := range report.Heights {
+				if err = r.probeLightBlock(ctx, peer, state.ChainID, height, trustedHashes[i]); err != nil {
+					break
+				}
+				served++
+			}
+
+			mtx.Lock()
+			defer mtx.Unlock()
+			p := report.Peers[peer]
+			p.LightBlocks = served
+			p.Err = err
+			report.Peers[peer] = p
+		}(peer)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// probeLightBlock requests the light block at the given height from the peer,
+// and verifies that it is the one with the trusted hash.
+func (r *Reactor) probeLightBlock(
+	ctx context.Context,
+	peer p2p.NodeID,
+	chainID string,
+	height int64,
+	trustedHash []byte,
+) error {
+	lb, err := r.dispatcher.lightBlockFrom(ctx, height, peer)
+	switch {
+	case err != nil:
+		return fmt.Errorf("failed to fetch light block %d: %w", height, err)
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case lb == nil:
+		return fmt.Errorf("light block %d is not available", height)
+	}
+
+	if err := lb.ValidateBasic(chainID); err != nil {
+		return fmt.Errorf("invalid light block %d: %w", height, err)
+	}
+	if !bytes.Equal(lb.Hash(), trustedHash) {
+		return fmt.Errorf("light block %d has hash %X, expected %X", height, lb.Hash(), trustedHash)
+	}
+	return nil
+}
+
+// sampleHeights returns up to n heights spread evenly from startHeight down to
+// stopHeight, both included.
+func sampleHeights(startHeight, stopHeight int64, n int) []int64 {
+	if n <= 1 || startHeight <= stopHeight {
+		return []int64{startHeight}
+	}
+
+	heights := make([]int64, 0, n)
+	for i := 0; i < n; i++ {
+		height := startHeight - (startHeight-stopHeight)*int64(i)/int64(n-1)
+		if len(heights) > 0 && heights[len(heights)-1] == height {
+			continue
+		}
+		heights = append(heights, height)
+	}
+	return heights
+}
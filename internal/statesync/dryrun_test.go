@@ -0,0 +1,203 @@
+package statesync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/internal/statesync/mocks"
+	"github.com/tendermint/tendermint/internal/test/factory"
+	ssproto "github.com/tendermint/tendermint/proto/tendermint/statesync"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestSampleHeights(t *testing.T) {
+	require.Equal(t, []int64{20, 18, 15, 13, 10}, sampleHeights(20, 10, 5))
+	require.Equal(t, []int64{3, 2, 1}, sampleHeights(3, 1, 5))
+	require.Equal(t, []int64{5}, sampleHeights(5, 5, 5))
+	require.Equal(t, []int64{20}, sampleHeights(20, 10, 1))
+}
+
+// dryRunPeer is how a peer behaves in a dry run.
+type dryRunPeer struct {
+	snapshot    bool // whether it advertises the snapshot
+	lightBlocks int  // the number of light blocks it serves before failing
+}
+
+func TestReactor_DryRun(t *testing.T) {
+	const (
+		snapshotHeight int64 = 20
+		stopHeight     int64 = 10
+	)
+	heights := sampleHeights(snapshotHeight, stopHeight, dryRunLightBlockSamples)
+
+	testcases := map[string]struct {
+		peers      map[p2p.NodeID]dryRunPeer
+		feasible   bool
+		noSnapshot bool
+	}{
+		"cooperative peers": {
+			peers: map[p2p.NodeID]dryRunPeer{
+				"a": {snapshot: true, lightBlocks: len(heights)},
+				"b": {snapshot: true, lightBlocks: len(heights)},
+			},
+			feasible: true,
+		},
+		"only some cooperative peers": {
+			peers: map[p2p.NodeID]dryRunPeer{
+				"a": {snapshot: true, lightBlocks: 2},
+				"b": {snapshot: false, lightBlocks: len(heights)},
+				"c": {snapshot: false, lightBlocks: 0},
+			},
+			feasible: true,
+		},
+		"uncooperative peers": {
+			peers: map[p2p.NodeID]dryRunPeer{
+				"a": {snapshot: true, lightBlocks: 0},
+				"b": {snapshot: true, lightBlocks: 3},
+			},
+			feasible: false,
+		},
+		"no snapshots": {
+			peers: map[p2p.NodeID]dryRunPeer{
+				"a": {snapshot: false, lightBlocks: len(heights)},
+			},
+			feasible:   false,
+			noSnapshot: true,
+		},
+	}
+
+	for name, tc := range testcases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			chain := buildLightBlockChain(t, 1, snapshotHeight+1, factory.DefaultTestTime)
+
+			stateProvider := &mocks.StateProvider{}
+			stateProvider.On("AppHash", mock.Anything, uint64(snapshotHeight)).Return([]byte("app_hash"), nil)
+			stateProvider.On("State", mock.Anything, uint64(snapshotHeight)).Return(sm.State{
+				ChainID:         factory.DefaultTestChainID,
+				InitialHeight:   1,
+				LastBlockHeight: snapshotHeight,
+				ConsensusParams: types.ConsensusParams{
+					Evidence: types.EvidenceParams{MaxAgeNumBlocks: snapshotHeight - stopHeight},
+				},
+			}, nil)
+			stateProvider.On("Commit", mock.Anything, mock.AnythingOfType("uint64")).Return(
+				func(_ context.Context, height uint64) *types.Commit {
+					return &types.Commit{BlockID: factory.MakeBlockIDWithHash(chain[int64(height)].Hash())}
+				}, nil)
+
+			rts := setup(t, nil, nil, stateProvider, 100)
+			for peer := range tc.peers {
+				rts.peerUpdateCh <- p2p.PeerUpdate{NodeID: peer, Status: p2p.PeerStatusUp}
+			}
+			retryUntil(t, func() bool { return rts.reactor.dispatcher.availablePeers.Len() == len(tc.peers) }, time.Second)
+
+			closeCh := make(chan struct{})
+			defer close(closeCh)
+			go handleDryRunRequests(t, tc.peers, chain, rts, closeCh)
+
+			report, err := rts.reactor.DryRun(context.Background(), stateProvider, 200*time.Millisecond)
+			require.NoError(t, err)
+			require.Equal(t, tc.feasible, report.Feasible())
+
+			if tc.noSnapshot {
+				require.Empty(t, report.Snapshots)
+				require.Empty(t, report.Heights)
+				return
+			}
+
+			var snapshotPeers []p2p.NodeID
+			for _, peer := range []p2p.NodeID{"a", "b", "c"} {
+				if tc.peers[peer].snapshot {
+					snapshotPeers = append(snapshotPeers, peer)
+				}
+			}
+			require.Len(t, report.Snapshots, 1)
+			require.Equal(t, uint64(snapshotHeight), report.Snapshots[0].Height)
+			require.Equal(t, snapshotPeers, report.Snapshots[0].Peers)
+			require.Equal(t, heights, report.Heights)
+
+			require.Len(t, report.Peers, len(tc.peers))
+			for peerID, peer := range tc.peers {
+				result := report.Peers[peerID]
+				require.Equal(t, peer.lightBlocks, result.LightBlocks, "peer %v", peerID)
+				if peer.lightBlocks < len(heights) {
+					require.Error(t, result.Err, "peer %v", peerID)
+				} else {
+					require.NoError(t, result.Err, "peer %v", peerID)
+				}
+				if peer.snapshot {
+					require.Equal(t, 1, result.Snapshots)
+				} else {
+					require.Zero(t, result.Snapshots)
+				}
+			}
+
+			// the dry run doesn't hold on to the sync
+			rts.reactor.mtx.RLock()
+			require.Nil(t, rts.reactor.syncer)
+			rts.reactor.mtx.RUnlock()
+		})
+	}
+}
+
+// handleDryRunRequests answers the snapshot and light block requests of a dry
+// run as the peers would. Once a peer has served its light blocks, it answers
+// every other request with a block of another chain.
+func handleDryRunRequests(
+	t *testing.T,
+	peers map[p2p.NodeID]dryRunPeer,
+	chain map[int64]*types.LightBlock,
+	rts *reactorTestSuite,
+	closeCh chan struct{},
+) {
+	served := make(map[p2p.NodeID]int)
+	for {
+		select {
+		case envelope := <-rts.snapshotOutCh:
+			if _, ok := envelope.Message.(*ssproto.SnapshotsRequest); !ok || !envelope.Broadcast {
+				continue
+			}
+			for peerID, peer := range peers {
+				if !peer.snapshot {
+					continue
+				}
+				rts.snapshotInCh <- p2p.Envelope{
+					From: peerID,
+					Message: &ssproto.SnapshotsResponse{
+						Height: uint64(len(chain)),
+						Format: 1,
+						Chunks: 1,
+						Hash:   []byte{1},
+					},
+				}
+			}
+
+		case envelope := <-rts.blockOutCh:
+			msg, ok := envelope.Message.(*ssproto.LightBlockRequest)
+			if !ok {
+				continue
+			}
+			lb := chain[int64(msg.Height)]
+			if served[envelope.To] >= peers[envelope.To].lightBlocks {
+				lb = mockLB(t, int64(msg.Height), factory.DefaultTestTime, factory.MakeBlockID())
+			}
+			served[envelope.To]++
+			lbproto, err := lb.ToProto()
+			require.NoError(t, err)
+			rts.blockInCh <- p2p.Envelope{
+				From:    envelope.To,
+				Message: &ssproto.LightBlockResponse{LightBlock: lbproto},
+			}
+
+		case <-closeCh:
+			return
+		}
+	}
+}
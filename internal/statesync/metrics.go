@@ -0,0 +1,80 @@
+package statesync
+
+import (
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// MetricsSubsystem is a subsystem shared by all metrics exposed by this
+	// package.
+	MetricsSubsystem = "statesync"
+)
+
+// Metrics contains metrics exposed by this package.
+type Metrics struct {
+	// The rate at which light blocks are retried when backfilling, in retries
+	// per second over the last 10 seconds. A sustained high rate means the
+	// peers fail to serve valid light blocks.
+	BackfillRetryRate metrics.Gauge
+	// Time taken to fetch a light block when backfilling, from the moment its
+	// height is handed out to a fetcher until it's returned, by peer.
+	LightBlockFetchTime metrics.Histogram
+	// Number of light block fetches that failed or timed out when
+	// backfilling, and were retried.
+	LightBlockFetchRetries metrics.Counter
+	// Number of light blocks fetched when backfilling that a witness served
+	// a different block for at the same height. Each conflict is a light
+	// client attack by either the peer or the witness, which evidence is
+	// submitted for once the block at that height is verified.
+	BackfillConflicts metrics.Counter
+}
+
+// PrometheusMetrics returns Metrics build using Prometheus client library.
+// Optionally, labels can be provided along with their values ("foo",
+// "fooValue").
+func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
+	labels := []string{}
+	for i := 0; i < len(labelsAndValues); i += 2 {
+		labels = append(labels, labelsAndValues[i])
+	}
+	return &Metrics{
+		BackfillRetryRate: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "backfill_retry_rate",
+			Help:      "Rate of light block retries when backfilling, in retries per second.",
+		}, labels).With(labelsAndValues...),
+		LightBlockFetchTime: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "light_block_fetch_time_seconds",
+			Help:      "Time taken to fetch a light block when backfilling, in seconds.",
+			Buckets:   stdprometheus.ExponentialBuckets(0.01, 2, 12),
+		}, append(labels, "peer_id")).With(labelsAndValues...),
+		LightBlockFetchRetries: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "light_block_fetch_retries_total",
+			Help:      "Number of light block fetches retried when backfilling.",
+		}, labels).With(labelsAndValues...),
+		BackfillConflicts: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "backfill_conflicts_total",
+			Help:      "Number of conflicting light blocks served by witnesses when backfilling.",
+		}, labels).With(labelsAndValues...),
+	}
+}
+
+// NopMetrics returns no-op Metrics.
+func NopMetrics() *Metrics {
+	return &Metrics{
+		BackfillRetryRate:      discard.NewGauge(),
+		LightBlockFetchTime:    discard.NewHistogram(),
+		LightBlockFetchRetries: discard.NewCounter(),
+		BackfillConflicts:      discard.NewCounter(),
+	}
+}
@@ -0,0 +1,134 @@
+package statesync
+
+import (
+	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
+	"github.com/tendermint/tendermint/internal/p2p"
+)
+
+// ProviderStats are the chunk fetching statistics of a snapshot provider, for debugging.
+type ProviderStats struct {
+	// Requested is the number of chunk requests sent to the provider.
+	Requested uint64
+	// Received is the number of requested chunks the provider served.
+	Received uint64
+	// TimedOut is the number of chunk requests the provider did not answer in time.
+	TimedOut uint64
+	// Rejected is the number of times the app rejected the provider as a chunk sender.
+	Rejected uint64
+	// Dropped is true if the provider was dropped for being unresponsive or rejected.
+	Dropped bool
+}
+
+// providerState tracks a snapshot provider while fetching chunks.
+type providerState struct {
+	stats ProviderStats
+	// timeouts counts consecutive timeouts, and is reset when a chunk is received.
+	timeouts int
+}
+
+// chunkProviders tracks the snapshot providers chunks are requested from. It balances chunk
+// requests across providers and detects providers that stop serving chunks, such that the
+// syncer can fail over to the remaining ones.
+type chunkProviders struct {
+	mtx         tmsync.Mutex
+	maxTimeouts int
+	providers   map[p2p.NodeID]*providerState
+}
+
+// newChunkProviders creates a new provider tracker, which considers a provider unresponsive
+// after maxTimeouts consecutive chunk request timeouts.
+func newChunkProviders(maxTimeouts int) *chunkProviders {
+	return &chunkProviders{
+		maxTimeouts: maxTimeouts,
+		providers:   make(map[p2p.NodeID]*providerState),
+	}
+}
+
+// get returns the state of a provider, creating it if needed. The caller must hold the mutex lock.
+func (c *chunkProviders) get(peerID p2p.NodeID) *providerState {
+	p, ok := c.providers[peerID]
+	if !ok {
+		p = &providerState{}
+		c.providers[peerID] = p
+	}
+	return p
+}
+
+// Pick picks the provider to request a chunk from amongst the given ones, skipping the ones in
+// exclude unless no others are left. It prefers the provider that was sent the fewest requests,
+// and records the request. It returns an empty ID if there are no providers.
+func (c *chunkProviders) Pick(peers []p2p.NodeID, exclude map[p2p.NodeID]bool) p2p.NodeID {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	var picked p2p.NodeID
+	for _, allowExcluded := range []bool{false, true} {
+		for _, peerID := range peers {
+			if exclude[peerID] && !allowExcluded {
+				continue
+			}
+			if picked == "" || c.get(peerID).stats.Requested < c.get(picked).stats.Requested {
+				picked = peerID
+			}
+		}
+		if picked != "" {
+			break
+		}
+	}
+
+	if picked != "" {
+		c.get(picked).stats.Requested++
+	}
+	return picked
+}
+
+// Received records that a provider served a chunk.
+func (c *chunkProviders) Received(peerID p2p.NodeID) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	p := c.get(peerID)
+	p.stats.Received++
+	p.timeouts = 0
+}
+
+// TimedOut records that a chunk request to a provider timed out. It returns true if the provider
+// has now timed out too many times in a row, and should be dropped.
+func (c *chunkProviders) TimedOut(peerID p2p.NodeID) bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	p := c.get(peerID)
+	p.stats.TimedOut++
+	p.timeouts++
+	return p.timeouts >= c.maxTimeouts
+}
+
+// Rejected records that the app rejected a provider as a chunk sender.
+func (c *chunkProviders) Rejected(peerID p2p.NodeID) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.get(peerID).stats.Rejected++
+	c.get(peerID).stats.Dropped = true
+}
+
+// Dropped records that a provider was dropped.
+func (c *chunkProviders) Dropped(peerID p2p.NodeID) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.get(peerID).stats.Dropped = true
+}
+
+// Stats returns the statistics of all providers chunks were requested from or received from.
+func (c *chunkProviders) Stats() map[p2p.NodeID]ProviderStats {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	stats := make(map[p2p.NodeID]ProviderStats, len(c.providers))
+	for peerID, p := range c.providers {
+		stats[peerID] = p.stats
+	}
+	return stats
+}
@@ -0,0 +1,38 @@
+package statesync
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+)
+
+func TestChunkProviders(t *testing.T) {
+	peerA := p2p.NodeID("aa")
+	peerB := p2p.NodeID("bb")
+	peers := []p2p.NodeID{peerA, peerB}
+
+	providers := newChunkProviders(2)
+
+	// Requests are balanced across providers, skipping excluded ones unless none are left.
+	require.Equal(t, peerA, providers.Pick(peers, nil))
+	require.Equal(t, peerB, providers.Pick(peers, nil))
+	require.Equal(t, peerB, providers.Pick(peers, map[p2p.NodeID]bool{peerA: true}))
+	require.Equal(t, peerA, providers.Pick(peers, map[p2p.NodeID]bool{peerA: true, peerB: true}))
+	require.Equal(t, peerA, providers.Pick(peers, map[p2p.NodeID]bool{}))
+	require.EqualValues(t, "", providers.Pick(nil, nil))
+
+	// Providers are reported unresponsive after consecutive timeouts only.
+	require.False(t, providers.TimedOut(peerA))
+	providers.Received(peerA)
+	require.False(t, providers.TimedOut(peerA))
+	require.True(t, providers.TimedOut(peerA))
+
+	providers.Rejected(peerB)
+
+	require.Equal(t, map[p2p.NodeID]ProviderStats{
+		peerA: {Requested: 3, Received: 1, TimedOut: 3},
+		peerB: {Requested: 2, Rejected: 1, Dropped: true},
+	}, providers.Stats())
+}
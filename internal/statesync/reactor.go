@@ -7,13 +7,16 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"sync"
 	"time"
 
 	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/config"
 	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
 	"github.com/tendermint/tendermint/internal/p2p"
+	tmbytes "github.com/tendermint/tendermint/libs/bytes"
 	"github.com/tendermint/tendermint/libs/log"
+	tmrand "github.com/tendermint/tendermint/libs/rand"
 	"github.com/tendermint/tendermint/libs/service"
 	ssproto "github.com/tendermint/tendermint/proto/tendermint/statesync"
 	"github.com/tendermint/tendermint/proxy"
@@ -99,8 +102,57 @@ const (
 	// maxLightBlockRequestRetries is the amount of retries acceptable before
 	// the backfill process aborts
 	maxLightBlockRequestRetries = 20
+
+	// retryLogInterval is how often backfill logs its retries so far, by
+	// reason, if there are any
+	retryLogInterval = 30 * time.Second
+
+	// witnessRetryInterval is how long backfill waits for enough witnesses to
+	// be available, or before asking witnesses again after some of them
+	// failed to serve a light block
+	witnessRetryInterval = 1 * time.Second
+
+	// defaultWitnessTimeout is how long backfill waits for enough witnesses to
+	// serve a light block before the height is retried, which counts towards
+	// the retry budget, so that a backfill with too few peers to cross-check
+	// blocks against fails rather than hangs
+	defaultWitnessTimeout = 10 * time.Second
+
+	// backgroundFetchers is the number of workers fetching light blocks when
+	// backfilling in the background, which runs at a low priority so as not
+	// to compete with the node syncing and following the chain
+	backgroundFetchers = 1
+
+	// maxBackgroundLightBlockRequestRetries is the amount of retries acceptable
+	// before a background backfill aborts. As the node doesn't wait for it,
+	// it's given more leeway than a backfill that holds up the node.
+	maxBackgroundLightBlockRequestRetries = 200
 )
 
+// EvidencePool is the evidence pool that the evidence of light client attacks
+// detected when backfilling is submitted to.
+type EvidencePool interface {
+	AddEvidence(types.Evidence) error
+}
+
+// ReactorOption sets an optional parameter on the Reactor.
+type ReactorOption func(*Reactor)
+
+// WithBackfillStopPredicate makes backfills stop at the first light block that
+// satisfies the given predicate, rather than at the height and time evidence
+// can no longer be submitted for. Backfills never go below the initial height
+// of the chain either way.
+func WithBackfillStopPredicate(stop StopPredicate) ReactorOption {
+	return func(r *Reactor) { r.backfillStop = stop }
+}
+
+// WithEvidencePool sets the evidence pool that the evidence of light client
+// attacks detected when cross-checking backfilled light blocks against
+// witnesses is submitted to. Without it, the evidence is only logged.
+func WithEvidencePool(evpool EvidencePool) ReactorOption {
+	return func(r *Reactor) { r.evidencePool = evpool }
+}
+
 // Reactor handles state sync, both restoring snapshots for the local node and
 // serving snapshots for other nodes.
 type Reactor struct {
@@ -120,11 +172,33 @@ type Reactor struct {
 	closeCh     chan struct{}
 
 	dispatcher *dispatcher
+	metrics    *Metrics
+
+	// how long backfill waits for enough witnesses to serve a light block,
+	// see defaultWitnessTimeout
+	witnessTimeout time.Duration
+
+	// the stop predicate of backfills, see WithBackfillStopPredicate. If nil,
+	// backfills stop at the evidence stop height and time.
+	backfillStop StopPredicate
+
+	// the evidence pool to submit attacks detected by backfills to, if any
+	evidencePool EvidencePool
 
 	// This will only be set when a state sync is in progress. It is used to feed
 	// received snapshots and chunks into the sync.
-	mtx    tmsync.RWMutex
-	syncer *syncer
+	mtx        tmsync.RWMutex
+	syncer     *syncer
+	cancelSync context.CancelFunc
+
+	// the queue of the backfill in progress, if any, also guarded by mtx
+	backfillQueue *blockQueue
+
+	// the dispatcher of the background backfill in progress, if any, also
+	// guarded by mtx. It has its own peers and calls, so that it can be
+	// stopped along with the backfill without affecting the dispatcher of the
+	// reactor.
+	backfillDispatcher *dispatcher
 }
 
 // NewReactor returns a reference to a new state sync reactor, which implements
@@ -141,6 +215,8 @@ func NewReactor(
 	stateStore sm.Store,
 	blockStore *store.BlockStore,
 	tempDir string,
+	metrics *Metrics,
+	options ...ReactorOption,
 ) *Reactor {
 	r := &Reactor{
 		cfg:         cfg,
@@ -155,6 +231,17 @@ func NewReactor(
 		dispatcher:  newDispatcher(blockCh.Out, lightBlockResponseTimeout),
 		stateStore:  stateStore,
 		blockStore:  blockStore,
+		metrics:     metrics,
+
+		witnessTimeout: defaultWitnessTimeout,
+	}
+
+	if cfg.ShufflePeers {
+		r.dispatcher.shufflePeers(tmrand.NewRand())
+	}
+
+	for _, opt := range options {
+		opt(r)
 	}
 
 	r.BaseService = *service.NewBaseService(logger, "StateSync", r)
@@ -218,9 +305,12 @@ func (r *Reactor) Sync(
 	r.mtx.Lock()
 	if r.syncer != nil {
 		r.mtx.Unlock()
-		return sm.State{}, errors.New("a state sync is already in progress")
+		return sm.State{}, errSyncInProgress
 	}
 
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	r.cancelSync = cancel
 	r.syncer = newSyncer(
 		r.cfg,
 		r.Logger,
@@ -242,14 +332,16 @@ func (r *Reactor) Sync(
 	}
 
 	state, commit, err := r.syncer.SyncAny(ctx, discoveryTime, requestSnapshotsHook)
-	if err != nil {
-		return sm.State{}, err
-	}
 
 	r.mtx.Lock()
 	r.syncer = nil
+	r.cancelSync = nil
 	r.mtx.Unlock()
 
+	if err != nil {
+		return sm.State{}, err
+	}
+
 	err = r.stateStore.Bootstrap(state)
 	if err != nil {
 		return sm.State{}, fmt.Errorf("failed to bootstrap node with new state: %w", err)
@@ -263,11 +355,37 @@ func (r *Reactor) Sync(
 	return state, nil
 }
 
+// Abort aborts the state sync in progress, if any, whether it is still
+// discovering snapshots or restoring one, such that Sync returns errAbort. It
+// returns false if no state sync is in progress.
+func (r *Reactor) Abort() bool {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	if r.cancelSync == nil {
+		return false
+	}
+	r.Logger.Info("Aborting state sync")
+	r.cancelSync()
+	return true
+}
+
 // Backfill sequentially fetches, verifies and stores light blocks in reverse
 // order. It does not stop verifying blocks until reaching a block with a height
-// and time that is less or equal to the stopHeight and stopTime. The
-// trustedBlockID should be of the header at startHeight.
-func (r *Reactor) Backfill(state sm.State) error {
+// and time that is less or equal to the stopHeight and stopTime, or that
+// satisfies the stop predicate set with WithBackfillStopPredicate, or the block
+// at the initial height. The trustedBlockID should be of the header at
+// startHeight. If background backfill is enabled, it runs at a low priority,
+// on its own dispatcher, as the node is expected to go live meanwhile.
+//
+// Backfill returns the height of the lowest verified light block, i.e. the new
+// base of the block store. As the stopTime must also be satisfied, this can be
+// lower than the stopHeight. Backfill fails if it reaches a block that is older
+// than the time of the trusted header minus the trust period, before it
+// satisfies the stopTime, as such blocks can't be safely verified. A backfill
+// interrupted by the context being canceled or the reactor stopping returns an
+// error as well.
+func (r *Reactor) Backfill(ctx context.Context, state sm.State) (int64, error) {
 	params := state.ConsensusParams.Evidence
 	stopHeight := state.LastBlockHeight - params.MaxAgeNumBlocks
 	stopTime := state.LastBlockTime.Add(-params.MaxAgeDuration)
@@ -278,21 +396,22 @@ func (r *Reactor) Backfill(state sm.State) error {
 		stopTime = state.LastBlockTime
 	}
 	return r.backfill(
-		context.Background(),
+		ctx,
 		state.ChainID,
-		state.LastBlockHeight, stopHeight,
+		state.LastBlockHeight, stopHeight, state.InitialHeight,
 		state.LastBlockID,
 		stopTime,
+		state.LastBlockTime,
 	)
 }
 
 func (r *Reactor) backfill(
 	ctx context.Context,
 	chainID string,
-	startHeight, stopHeight int64,
+	startHeight, stopHeight, initialHeight int64,
 	trustedBlockID types.BlockID,
-	stopTime time.Time,
-) error {
+	stopTime, trustedTime time.Time,
+) (int64, error) {
 	r.Logger.Info("starting backfill process...", "startHeight", startHeight,
 		"stopHeight", stopHeight, "trustedBlockID", trustedBlockID)
 
@@ -302,27 +421,110 @@ func (r *Reactor) backfill(
 		lastChangeHeight int64 = startHeight
 	)
 
-	queue := newBlockQueue(startHeight, stopHeight, stopTime, maxLightBlockRequestRetries)
+	// a background backfill runs at a low priority on its own dispatcher,
+	// with fewer fetchers and more retries
+	var (
+		d          = r.dispatcher
+		fetchers   = int(r.cfg.Fetchers)
+		maxRetries = maxLightBlockRequestRetries
+	)
+	if r.cfg.BackgroundBackfill {
+		d = newDispatcher(r.blockCh.Out, lightBlockResponseTimeout)
+		if fetchers > backgroundFetchers {
+			fetchers = backgroundFetchers
+		}
+		maxRetries = maxBackgroundLightBlockRequestRetries
+	}
+
+	queue := newBlockQueue(startHeight, stopHeight, initialHeight, stopTime, trustedTime, r.cfg.TrustPeriod,
+		maxRetries, r.backfillStop)
+	queue.trackRetryRate(r.metrics.BackfillRetryRate)
+
+	r.mtx.Lock()
+	r.backfillQueue = queue
+	if d != r.dispatcher {
+		for _, peer := range r.dispatcher.availablePeers.Peers() {
+			d.addPeer(peer)
+		}
+		r.backfillDispatcher = d
+	}
+	r.mtx.Unlock()
+	defer func() {
+		r.mtx.Lock()
+		r.backfillQueue = nil
+		if d != r.dispatcher {
+			r.backfillDispatcher = nil
+			d.stop()
+		}
+		r.mtx.Unlock()
+	}()
+	queue.trackFetchTimes(r.metrics.LightBlockFetchTime, r.metrics.LightBlockFetchRetries)
+
+	// the conflicts found by cross-checking blocks against witnesses, which
+	// evidence is formed from as the blocks are verified
+	conflicts := newBackfillConflicts()
+
+	// if enabled, the signatures of the commits are verified too. This is
+	// costly but doesn't depend on any other block, so it's done in parallel
+	// as the blocks come in, before they're added to the queue. Each light
+	// block carries the validator set that signed it, which ValidateBasic
+	// checks against its header, so no validator set has to be fetched
+	// separately to verify it.
+	addBlock := queue.add
+	if r.cfg.VerifyWorkers > 0 {
+		addBlock = queue.verifyCommits(int(r.cfg.VerifyWorkers), func(lb *types.LightBlock) error {
+			return lb.ValidatorSet.VerifyCommitLight(chainID, lb.Commit.BlockID, lb.Height, lb.Commit)
+		})
+	}
 
 	// fetch light blocks across four workers. The aim with deploying concurrent
 	// workers is to equate the network messaging time with the verification
 	// time. Ideally we want the verification process to never have to be
 	// waiting on blocks. If it takes 4s to retrieve a block and 1s to verify
-	// it, then steady state involves four workers.
-	for i := 0; i < int(r.cfg.Fetchers); i++ {
-		go func() {
+	// it, then steady state involves four workers. If min-fetchers is set, the
+	// queue instead adapts how many of the workers fetch blocks.
+	if r.cfg.MinFetchers > 0 && int(r.cfg.MinFetchers) < fetchers {
+		queue.scaleFetchers(int(r.cfg.MinFetchers), fetchers)
+	}
+	for i := 0; i < fetchers; i++ {
+		go func(worker int) {
 			for {
+				if throttled := queue.throttle(worker); throttled != nil {
+					select {
+					case <-throttled:
+						continue
+					case <-queue.done():
+						return
+					}
+				}
+
 				select {
 				case height := <-queue.nextHeight():
 					r.Logger.Debug("fetching next block", "height", height)
-					lb, peer, err := r.dispatcher.LightBlock(ctx, height)
+					var (
+						lb   *types.LightBlock
+						peer p2p.NodeID
+						err  error
+					)
+					// a height retried after a conflict is preferably
+					// fetched from peers that weren't part of it
+					if avoid := conflicts.peers(height); len(avoid) > 0 {
+						lb, peer, err = d.lightBlockAvoiding(ctx, height, avoid)
+					} else {
+						lb, peer, err = d.LightBlock(ctx, height)
+					}
 					if err != nil {
-						queue.retry(height)
 						if errors.Is(err, errNoConnectedPeers) {
+							queue.retryWithReason(height, retryNoPeers)
 							r.Logger.Info("backfill: no connected peers to fetch light blocks from; sleeping...",
 								"sleepTime", sleepTime)
 							time.Sleep(sleepTime)
 						} else {
+							if errors.Is(err, errNoResponse) {
+								queue.retryWithReason(height, retryTimeout)
+							} else {
+								queue.retryWithReason(height, retryFetchError)
+							}
 							// we don't punish the peer as it might just have not responded in time
 							r.Logger.Info("backfill: error with fetching light block",
 								"height", height, "err", err)
@@ -331,10 +533,10 @@ func (r *Reactor) backfill(
 					}
 					if lb == nil {
 						r.Logger.Info("backfill: peer didn't have block, fetching from another peer", "height", height)
-						queue.retry(height)
+						queue.retryWithReason(height, retryMissingBlock)
 						// as we are fetching blocks backwards, if this node doesn't have the block it likely doesn't
 						// have any prior ones, thus we remove it from the peer list
-						r.dispatcher.removePeer(peer)
+						d.removePeer(peer)
 						continue
 					}
 
@@ -344,7 +546,7 @@ func (r *Reactor) backfill(
 					if err != nil || lb.Height != height {
 						r.Logger.Info("backfill: fetched light block failed validate basic, removing peer...",
 							"err", err, "height", height)
-						queue.retry(height)
+						queue.retryWithReason(height, retryInvalidBlock)
 						r.blockCh.Error <- p2p.PeerError{
 							NodeID: peer,
 							Err:    fmt.Errorf("received invalid light block: %w", err),
@@ -352,8 +554,31 @@ func (r *Reactor) backfill(
 						continue
 					}
 
+					// cross-check the block against witnesses, and request it
+					// again, from other peers, if they don't all serve the
+					// same one. Once a block the witnesses agree on is
+					// verified, the conflicting ones are known to be fake.
+					if r.cfg.Witnesses > 0 {
+						err := r.crossCheck(ctx, d, queue.done(), chainID, lb, peer, int(r.cfg.Witnesses), conflicts)
+						switch {
+						case errors.Is(err, errConflictingBlock):
+							queue.retryWithReason(height, retryConflict)
+							r.Logger.Info("backfill: conflicting light blocks served, fetching from other peers",
+								"height", height, "err", err)
+							continue
+						case errors.Is(err, errNotEnoughWitnesses):
+							queue.retryWithReason(height, retryNoWitnesses)
+							r.Logger.Info("backfill: failed to cross-check light block against witnesses",
+								"height", height, "err", err)
+							continue
+						case err != nil:
+							// the backfill stopped
+							continue
+						}
+					}
+
 					// add block to queue to be verified
-					queue.add(lightBlockResponse{
+					addBlock(lightBlockResponse{
 						block: lb,
 						peer:  peer,
 					})
@@ -363,38 +588,107 @@ func (r *Reactor) backfill(
 					return
 				}
 			}
-		}()
+		}(i)
+	}
+
+	// detect the verification of light blocks stalling, e.g. because the peer
+	// the next block was requested from never serves it
+	var (
+		stallTimer *time.Timer
+		stallCh    <-chan time.Time
+	)
+	if r.cfg.VerifyTimeout > 0 {
+		stallTimer = time.NewTimer(r.cfg.VerifyTimeout)
+		defer stallTimer.Stop()
+		stallCh = stallTimer.C
 	}
 
+	// periodically log the retries so far, to tell what slows backfill down
+	retryLogTicker := time.NewTicker(retryLogInterval)
+	defer retryLogTicker.Stop()
+
 	// verify all light blocks
+	var verifyCh <-chan lightBlockResponse
 	for {
+		// keep waiting on the same channel across stalls, as the block may be
+		// sent to it at any time
+		if verifyCh == nil {
+			verifyCh = queue.verifyNext()
+		}
+
 		select {
 		case <-r.closeCh:
 			queue.close()
-			return nil
+			return 0, errBackfillStopped
 		case <-ctx.Done():
 			queue.close()
-			return nil
-		case resp := <-queue.verifyNext():
+			return 0, ctx.Err()
+		case <-retryLogTicker.C:
+			if counts := queue.retryCounts(); len(counts) > 0 {
+				r.Logger.Info("backfill: retries so far", retryCountsKeyvals(counts)...)
+			}
+		case <-stallCh:
+			height := queue.stalled()
+			r.Logger.Error("backfill: stalled waiting for a verifiable light block, requesting it again",
+				"height", height, "timeout", r.cfg.VerifyTimeout)
+			stallTimer.Reset(r.cfg.VerifyTimeout)
+		case resp, ok := <-verifyCh:
+			verifyCh = nil
+			if !ok {
+				// the queue was closed, which is handled below
+				continue
+			}
+			if stallTimer != nil {
+				if !stallTimer.Stop() {
+					<-stallTimer.C
+				}
+				stallTimer.Reset(r.cfg.VerifyTimeout)
+			}
+
 			// validate the header hash. We take the last block id of the
 			// previous header (i.e. one height above) as the trusted hash which
 			// we equate to. ValidatorsHash and CommitHash have already been
 			// checked in the `ValidateBasic`
-			if w, g := trustedBlockID.Hash, resp.block.Hash(); !bytes.Equal(w, g) {
+			if err := verifyLink(trustedBlockID, resp.block); err != nil {
 				r.Logger.Info("received invalid light block. header hash doesn't match trusted LastBlockID",
-					"trustedHash", w, "receivedHash", g, "height", resp.block.Height)
+					"err", err, "height", resp.block.Height)
+				r.blockCh.Error <- p2p.PeerError{
+					NodeID: resp.peer,
+					Err:    fmt.Errorf("received invalid light block: %w", err),
+				}
+				queue.retryWithReason(resp.block.Height, retryInvalidLink)
+				continue
+			}
+
+			// reject the block if its commit signatures were verified and found
+			// invalid
+			err := resp.commitErr
+			if err != nil {
+				r.Logger.Info("received invalid light block. commit verification failed",
+					"err", err, "height", resp.block.Height)
 				r.blockCh.Error <- p2p.PeerError{
 					NodeID: resp.peer,
-					Err:    fmt.Errorf("received invalid light block. Expected hash %v, got: %v", w, g),
+					Err:    fmt.Errorf("received invalid light block: %w", err),
 				}
-				queue.retry(resp.block.Height)
+				queue.retryWithReason(resp.block.Height, retryInvalidCommit)
 				continue
 			}
 
+			// when backfilling in the background, the block is served as soon
+			// as its header is stored, so its validator set must be stored
+			// first rather than with those of the other blocks sharing it. The
+			// batches saved below overwrite it.
+			if r.cfg.BackgroundBackfill {
+				err = r.stateStore.SaveValidatorSets(resp.block.Height, resp.block.Height, resp.block.ValidatorSet)
+				if err != nil {
+					return 0, err
+				}
+			}
+
 			// save the signed headers
-			err := r.blockStore.SaveSignedHeader(resp.block.SignedHeader, trustedBlockID)
+			err = r.blockStore.SaveSignedHeader(resp.block.SignedHeader, trustedBlockID)
 			if err != nil {
-				return err
+				return 0, err
 			}
 
 			// check if there has been a change in the validator set
@@ -402,13 +696,35 @@ func (r *Reactor) backfill(
 				// save all the heights that the last validator set was the same
 				err = r.stateStore.SaveValidatorSets(resp.block.Height+1, lastChangeHeight, lastValidatorSet)
 				if err != nil {
-					return err
+					return 0, err
 				}
 
 				// update the lastChangeHeight
 				lastChangeHeight = resp.block.Height
 			}
 
+			// now that the block is verified, the peers that served other
+			// blocks at its height are known to be faulty, and the evidence
+			// of the attacks it is the common block of is submitted. The
+			// evidence pool verifies it against the validator set of the
+			// common block, which must be stored first.
+			evs, faulty := conflicts.verified(resp.block)
+			if len(evs) > 0 {
+				if !r.cfg.BackgroundBackfill {
+					err = r.stateStore.SaveValidatorSets(resp.block.Height, resp.block.Height, resp.block.ValidatorSet)
+					if err != nil {
+						return 0, err
+					}
+				}
+				r.reportEvidence(evs)
+			}
+			for _, peer := range faulty {
+				r.blockCh.Error <- p2p.PeerError{
+					NodeID: peer,
+					Err:    fmt.Errorf("served a conflicting light block at height %d", resp.block.Height),
+				}
+			}
+
 			trustedBlockID = resp.block.LastBlockID
 			queue.success(resp.block.Height)
 			r.Logger.Info("backfill: verified and stored light block", "height", resp.block.Height)
@@ -417,18 +733,208 @@ func (r *Reactor) backfill(
 
 		case <-queue.done():
 			if err := queue.error(); err != nil {
-				return err
+				return 0, err
 			}
 
 			// save the final batch of validators
 			if err := r.stateStore.SaveValidatorSets(queue.terminal.Height, lastChangeHeight, lastValidatorSet); err != nil {
-				return err
+				return 0, err
 			}
 
 			r.Logger.Info("successfully completed backfill process", "endHeight", queue.terminal.Height)
-			return nil
+			return queue.terminal.Height, nil
+		}
+	}
+}
+
+// errInvalidLink is returned when a light block fetched while backfilling
+// doesn't chain to the verified block one height above it.
+type errInvalidLink struct {
+	height      int64            // height of the fetched light block
+	hash        tmbytes.HexBytes // hash of the fetched light block
+	lastBlockID types.BlockID    // LastBlockID of the block above it
+}
+
+func (e errInvalidLink) Error() string {
+	return fmt.Sprintf("light block at height %d doesn't link to the block at height %d: "+
+		"hash %v doesn't match its LastBlockID %v", e.height, e.height+1, e.hash, e.lastBlockID)
+}
+
+// crossCheck requests the given light block, served by peer, from n other
+// peers acting as witnesses, until all of them serve a block with the same
+// hash, waiting for enough witnesses to be available. Until the chain of
+// blocks is verified down to its height, there's no telling which of two
+// conflicting blocks is the fake one, so a conflict is recorded in conflicts,
+// which forms the evidence of the attack once the block is verified, and
+// crossCheck returns an error wrapping errConflictingBlock. If not enough
+// witnesses serve the block within the reactor's witness timeout, it returns
+// an error wrapping errNotEnoughWitnesses.
+func (r *Reactor) crossCheck(
+	ctx context.Context,
+	d *dispatcher,
+	done <-chan struct{},
+	chainID string,
+	lb *types.LightBlock,
+	peer p2p.NodeID,
+	n int,
+	conflicts *backfillConflicts,
+) error {
+	hash := lb.Hash()
+	timeout := time.NewTimer(r.witnessTimeout)
+	defer timeout.Stop()
+	for {
+		witnesses := d.popWitnesses(n, peer)
+		if len(witnesses) < n {
+			for _, witness := range witnesses {
+				d.release(witness)
+			}
+			r.Logger.Debug("backfill: waiting for witnesses to cross-check light block",
+				"height", lb.Height, "available", len(witnesses), "required", n)
+		} else {
+			agreed, err := r.crossCheckWith(ctx, d, chainID, lb, hash, peer, witnesses, conflicts)
+			if err != nil || agreed == n {
+				return err
+			}
+			r.Logger.Debug("backfill: not all witnesses served light block, asking again",
+				"height", lb.Height, "agreed", agreed, "required", n)
+		}
+
+		select {
+		case <-time.After(witnessRetryInterval):
+		case <-timeout.C:
+			return fmt.Errorf("%w: %d required within %v", errNotEnoughWitnesses, n, r.witnessTimeout)
+		case <-done:
+			return errBackfillStopped
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// crossCheckWith requests the given light block, with the given hash, from
+// the given witnesses, and returns how many of them served the same block,
+// or an error wrapping errConflictingBlock if any served another one.
+func (r *Reactor) crossCheckWith(
+	ctx context.Context,
+	d *dispatcher,
+	chainID string,
+	lb *types.LightBlock,
+	hash tmbytes.HexBytes,
+	peer p2p.NodeID,
+	witnesses []p2p.NodeID,
+	conflicts *backfillConflicts,
+) (int, error) {
+	blocks := make([]*types.LightBlock, len(witnesses))
+	errs := make([]error, len(witnesses))
+	var wg sync.WaitGroup
+	for i, witness := range witnesses {
+		wg.Add(1)
+		go func(i int, witness p2p.NodeID) {
+			defer wg.Done()
+			blocks[i], errs[i] = d.lightBlock(ctx, lb.Height, witness)
+		}(i, witness)
+	}
+	wg.Wait()
+
+	var (
+		agreed   int
+		conflict error
+	)
+	for i, witness := range witnesses {
+		witnessBlock, err := blocks[i], errs[i]
+		if err == nil && witnessBlock == nil {
+			err = errors.New("witness didn't have the block")
+		}
+		if err != nil {
+			r.Logger.Debug("backfill: witness failed to serve light block",
+				"height", lb.Height, "witness", witness, "err", err)
+			continue
+		}
+
+		err = witnessBlock.ValidateBasic(chainID)
+		if err == nil && witnessBlock.Height != lb.Height {
+			err = fmt.Errorf("expected height %d, got %d", lb.Height, witnessBlock.Height)
+		}
+		if err != nil {
+			r.Logger.Info("backfill: light block served by witness failed validate basic, removing peer...",
+				"err", err, "height", lb.Height, "witness", witness)
+			r.blockCh.Error <- p2p.PeerError{
+				NodeID: witness,
+				Err:    fmt.Errorf("received invalid light block: %w", err),
+			}
+			continue
+		}
+
+		if !bytes.Equal(witnessBlock.Hash(), hash) {
+			r.Logger.Error("backfill: witness served a conflicting light block",
+				"height", lb.Height, "peer", peer, "block", lb, "witness", witness, "witnessBlock", witnessBlock)
+			r.metrics.BackfillConflicts.Add(1)
+			conflicts.add(lb.Height,
+				conflictingBlock{block: lb, peer: peer},
+				conflictingBlock{block: witnessBlock, peer: witness})
+			conflict = fmt.Errorf("%w: %v served %X, %v served %X",
+				errConflictingBlock, peer, hash, witness, witnessBlock.Hash())
+			continue
 		}
+		agreed++
 	}
+
+	return agreed, conflict
+}
+
+// reportEvidence submits the evidence of light client attacks formed when
+// backfilling to the evidence pool, or logs it if there is none.
+func (r *Reactor) reportEvidence(evidence []*types.LightClientAttackEvidence) {
+	for _, ev := range evidence {
+		if r.evidencePool == nil {
+			r.Logger.Error("backfill: detected light client attack, but no evidence pool to submit it to",
+				"evidence", ev)
+			continue
+		}
+		if err := r.evidencePool.AddEvidence(ev); err != nil {
+			r.Logger.Error("backfill: failed to submit evidence of light client attack",
+				"evidence", ev, "err", err)
+			continue
+		}
+		r.Logger.Info("backfill: submitted evidence of light client attack", "evidence", ev)
+	}
+}
+
+// retryCountsKeyvals returns retry counts by reason as logger keyvals, sorted
+// by reason.
+func retryCountsKeyvals(counts map[retryReason]int) []interface{} {
+	reasons := make([]string, 0, len(counts))
+	for reason := range counts {
+		reasons = append(reasons, string(reason))
+	}
+	sort.Strings(reasons)
+
+	keyvals := make([]interface{}, 0, 2*len(reasons))
+	for _, reason := range reasons {
+		keyvals = append(keyvals, reason, counts[retryReason(reason)])
+	}
+	return keyvals
+}
+
+// verifyLink verifies that the light block is the one the LastBlockID of the
+// block above it, which has already been verified, commits to.
+func verifyLink(lastBlockID types.BlockID, lb *types.LightBlock) error {
+	if hash := lb.Hash(); !bytes.Equal(hash, lastBlockID.Hash) {
+		return errInvalidLink{height: lb.Height, hash: hash, lastBlockID: lastBlockID}
+	}
+	return nil
+}
+
+// responseDispatcher returns the dispatcher that a light block response from
+// the given peer is for: the one of the background backfill in progress if it
+// awaits a response from the peer, or the one of the reactor otherwise.
+func (r *Reactor) responseDispatcher(peer p2p.NodeID) *dispatcher {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if r.backfillDispatcher != nil && r.backfillDispatcher.awaiting(peer) {
+		return r.backfillDispatcher
+	}
+	return r.dispatcher
 }
 
 // Dispatcher exposes the dispatcher so that a state provider can use it for
@@ -437,6 +943,45 @@ func (r *Reactor) Dispatcher() *dispatcher { //nolint:golint
 	return r.dispatcher
 }
 
+// ProviderStats returns the chunk fetching statistics of the snapshot
+// providers of the state sync in progress, or nil if there is none.
+func (r *Reactor) ProviderStats() map[p2p.NodeID]ProviderStats {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	if r.syncer == nil {
+		return nil
+	}
+	return r.syncer.ProviderStats()
+}
+
+// ServableBase returns the lowest height from which light blocks are served
+// while a background backfill is in progress, which advances as the backfill
+// verifies blocks: the lowest height backfilled so far, or the height above
+// the restored state if there is none yet. It returns false if no background
+// backfill is in progress, in which case all stored light blocks are served.
+func (r *Reactor) ServableBase() (int64, bool) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	if r.backfillQueue == nil || !r.cfg.BackgroundBackfill {
+		return 0, false
+	}
+	return r.backfillQueue.servableBase(), true
+}
+
+// BackfillQueue returns a snapshot of the queue of the backfill in progress,
+// and false if there is none.
+func (r *Reactor) BackfillQueue() (BlockQueueSnapshot, bool) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	if r.backfillQueue == nil {
+		return BlockQueueSnapshot{}, false
+	}
+	return r.backfillQueue.snapshot(), true
+}
+
 // handleSnapshotMessage handles envelopes sent from peers on the
 // SnapshotChannel. It returns an error only if the Envelope.Message is unknown
 // for this channel. This should never be called outside of handleMessage.
@@ -620,7 +1165,7 @@ func (r *Reactor) handleLightBlockMessage(envelope p2p.Envelope) error {
 		}
 
 	case *ssproto.LightBlockResponse:
-		if err := r.dispatcher.respond(msg.LightBlock, envelope.From); err != nil {
+		if err := r.responseDispatcher(envelope.From).respond(msg.LightBlock, envelope.From); err != nil {
 			r.Logger.Error("error processing light block response", "err", err)
 			return err
 		}
@@ -719,12 +1264,18 @@ func (r *Reactor) processPeerUpdate(peerUpdate p2p.PeerUpdate) {
 			r.syncer.AddPeer(peerUpdate.NodeID)
 		}
 		r.dispatcher.addPeer(peerUpdate.NodeID)
+		if r.backfillDispatcher != nil {
+			r.backfillDispatcher.addPeer(peerUpdate.NodeID)
+		}
 
 	case p2p.PeerStatusDown:
 		if r.syncer != nil {
 			r.syncer.RemovePeer(peerUpdate.NodeID)
 		}
 		r.dispatcher.removePeer(peerUpdate.NodeID)
+		if r.backfillDispatcher != nil {
+			r.backfillDispatcher.removePeer(peerUpdate.NodeID)
+		}
 	}
 }
 
@@ -790,6 +1341,11 @@ func (r *Reactor) recentSnapshots(n uint32) ([]*snapshot, error) {
 func (r *Reactor) fetchLightBlock(height uint64) (*types.LightBlock, error) {
 	h := int64(height)
 
+	// heights below the servable base may be partially backfilled
+	if base, ok := r.ServableBase(); ok && h < base {
+		return nil, nil
+	}
+
 	blockMeta := r.blockStore.LoadBlockMeta(h)
 	if blockMeta == nil {
 		return nil, nil
@@ -2,6 +2,7 @@ package statesync
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"sync"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	// "github.com/fortytw2/leaktest"
+	"github.com/go-kit/kit/metrics/generic"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	dbm "github.com/tendermint/tm-db"
@@ -59,7 +61,7 @@ type reactorTestSuite struct {
 }
 
 func setup(
-	t *testing.T,
+	t testing.TB,
 	conn *proxymocks.AppConnSnapshot,
 	connQuery *proxymocks.AppConnQuery,
 	stateProvider *mocks.StateProvider,
@@ -136,6 +138,7 @@ func setup(
 		rts.stateStore,
 		rts.blockStore,
 		"",
+		NopMetrics(),
 	)
 
 	// override the dispatcher with one with a shorter timeout
@@ -419,6 +422,30 @@ func TestReactor_Dispatcher(t *testing.T) {
 	}
 }
 
+func TestReactor_Abort(t *testing.T) {
+	rts := setup(t, nil, nil, nil, 2)
+
+	// no state sync is in progress, so there is nothing to abort
+	require.False(t, rts.reactor.Abort())
+
+	// no snapshots are offered, so the state sync keeps discovering them until
+	// it is aborted
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := rts.reactor.Sync(context.Background(), rts.stateProvider, minimumDiscoveryTime)
+		errCh <- err
+	}()
+	require.Eventually(t, rts.reactor.Abort, time.Second, 10*time.Millisecond)
+
+	select {
+	case err := <-errCh:
+		require.Equal(t, errAbort, err)
+	case <-time.After(time.Second):
+		t.Fatal("state sync was not aborted")
+	}
+	require.False(t, rts.reactor.Abort())
+}
+
 func TestReactor_Backfill(t *testing.T) {
 	// test backfill algorithm with varying failure rates [0, 10]
 	failureRates := []int{0, 3, 9}
@@ -427,6 +454,8 @@ func TestReactor_Backfill(t *testing.T) {
 		t.Run(fmt.Sprintf("failure rate: %d", failureRate), func(t *testing.T) {
 			// t.Cleanup(leaktest.Check(t))
 			rts := setup(t, nil, nil, nil, 21)
+			retryRate := &maxGauge{Gauge: generic.NewGauge("retry_rate")}
+			rts.reactor.metrics.BackfillRetryRate = retryRate
 
 			var (
 				startHeight int64 = 20
@@ -459,18 +488,21 @@ func TestReactor_Backfill(t *testing.T) {
 			go handleLightBlockRequests(t, chain, rts.blockOutCh,
 				rts.blockInCh, closeCh, failureRate)
 
-			err := rts.reactor.backfill(
+			base, err := rts.reactor.backfill(
 				context.Background(),
 				factory.DefaultTestChainID,
 				startHeight,
 				stopHeight,
+				1,
 				factory.MakeBlockIDWithHash(chain[startHeight].Header.Hash()),
 				stopTime,
+				chain[startHeight].Time,
 			)
 			if failureRate > 5 {
 				require.Error(t, err)
 			} else {
 				require.NoError(t, err)
+				require.Equal(t, stopHeight, base)
 
 				for height := startHeight; height <= stopHeight; height++ {
 					blockMeta := rts.blockStore.LoadBlockMeta(height)
@@ -480,10 +512,805 @@ func TestReactor_Backfill(t *testing.T) {
 				require.Nil(t, rts.blockStore.LoadBlockMeta(stopHeight-1))
 				require.Nil(t, rts.blockStore.LoadBlockMeta(startHeight+1))
 			}
+
+			// the retry rate rises with failures, and is reset once done
+			if failureRate > 0 {
+				require.Greater(t, retryRate.Max(), 0.0)
+			}
+			require.Zero(t, retryRate.Value())
+		})
+	}
+}
+
+func TestReactor_BackfillStopTime(t *testing.T) {
+	rts := setup(t, nil, nil, nil, 21)
+
+	var (
+		startHeight int64 = 20
+		stopHeight  int64 = 10
+		chainStart        = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	rts.peerUpdateCh <- p2p.PeerUpdate{
+		NodeID: p2p.NodeID("a"),
+		Status: p2p.PeerStatusUp,
+	}
+
+	rts.stateStore.On("SaveValidatorSets", mock.AnythingOfType("int64"), mock.AnythingOfType("int64"),
+		mock.AnythingOfType("*types.ValidatorSet")).Return(nil)
+
+	// blocks are a minute apart, so only blocks at height 6 and below were
+	// created before the stop time
+	chain := buildLightBlockChain(t, 1, startHeight+1, chainStart)
+	stopTime := chain[7].Time
+
+	closeCh := make(chan struct{})
+	defer close(closeCh)
+	go handleLightBlockRequests(t, chain, rts.blockOutCh, rts.blockInCh, closeCh, 0)
+
+	base, err := rts.reactor.backfill(
+		context.Background(),
+		factory.DefaultTestChainID,
+		startHeight,
+		stopHeight,
+		1,
+		factory.MakeBlockIDWithHash(chain[startHeight].Header.Hash()),
+		stopTime,
+		chain[startHeight].Time,
+	)
+	require.NoError(t, err)
+
+	// the returned base is the lowest block stored, below the stop height
+	require.Equal(t, int64(6), base)
+	require.Less(t, base, stopHeight)
+	for height := base; height <= startHeight; height++ {
+		require.NotNil(t, rts.blockStore.LoadBlockMeta(height))
+	}
+	require.Nil(t, rts.blockStore.LoadBlockMeta(base-1))
+}
+
+func TestReactor_BackfillStopPredicate(t *testing.T) {
+	rts := setup(t, nil, nil, nil, 21)
+
+	var (
+		startHeight int64 = 20
+		stopHeight  int64 = 10
+	)
+
+	rts.peerUpdateCh <- p2p.PeerUpdate{
+		NodeID: p2p.NodeID("a"),
+		Status: p2p.PeerStatusUp,
+	}
+
+	rts.stateStore.On("SaveValidatorSets", mock.AnythingOfType("int64"), mock.AnythingOfType("int64"),
+		mock.AnythingOfType("*types.ValidatorSet")).Return(nil)
+
+	// the predicate stops the backfill above the stop height
+	WithBackfillStopPredicate(func(block *types.LightBlock) bool {
+		return block.Height <= 15
+	})(rts.reactor)
+
+	chain := buildLightBlockChain(t, 1, startHeight+1, time.Now())
+
+	closeCh := make(chan struct{})
+	defer close(closeCh)
+	go handleLightBlockRequests(t, chain, rts.blockOutCh, rts.blockInCh, closeCh, 0)
+
+	base, err := rts.reactor.backfill(
+		context.Background(),
+		factory.DefaultTestChainID,
+		startHeight,
+		stopHeight,
+		1,
+		factory.MakeBlockIDWithHash(chain[startHeight].Header.Hash()),
+		chain[stopHeight].Time,
+		chain[startHeight].Time,
+	)
+	require.NoError(t, err)
+	require.Equal(t, int64(15), base)
+	require.Nil(t, rts.blockStore.LoadBlockMeta(base-1))
+}
+
+func TestReactor_BackfillCanceled(t *testing.T) {
+	rts := setup(t, nil, nil, nil, 21)
+
+	rts.peerUpdateCh <- p2p.PeerUpdate{
+		NodeID: p2p.NodeID("a"),
+		Status: p2p.PeerStatusUp,
+	}
+
+	// no light blocks are served, so the backfill only ends once canceled
+	chain := buildLightBlockChain(t, 1, 21, time.Now())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	base, err := rts.reactor.backfill(
+		ctx,
+		factory.DefaultTestChainID,
+		20,
+		10,
+		1,
+		factory.MakeBlockIDWithHash(chain[20].Header.Hash()),
+		chain[10].Time,
+		chain[20].Time,
+	)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Zero(t, base)
+}
+
+func TestReactor_BackfillTrustPeriod(t *testing.T) {
+	rts := setup(t, nil, nil, nil, 21)
+
+	var (
+		startHeight int64 = 20
+		stopHeight  int64 = 10
+		chainStart        = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	rts.peerUpdateCh <- p2p.PeerUpdate{
+		NodeID: p2p.NodeID("a"),
+		Status: p2p.PeerStatusUp,
+	}
+
+	rts.stateStore.On("SaveValidatorSets", mock.AnythingOfType("int64"), mock.AnythingOfType("int64"),
+		mock.AnythingOfType("*types.ValidatorSet")).Return(nil)
+
+	// blocks are a minute apart, so only blocks at height 15 and above are
+	// within the trust period of the trusted header at height 20
+	chain := buildLightBlockChain(t, 1, startHeight+1, chainStart)
+	rts.reactor.cfg.TrustPeriod = 5*time.Minute + 30*time.Second
+
+	closeCh := make(chan struct{})
+	defer close(closeCh)
+	go handleLightBlockRequests(t, chain, rts.blockOutCh, rts.blockInCh, closeCh, 0)
+
+	_, err := rts.reactor.backfill(
+		context.Background(),
+		factory.DefaultTestChainID,
+		startHeight,
+		stopHeight,
+		1,
+		factory.MakeBlockIDWithHash(chain[startHeight].Header.Hash()),
+		chain[1].Time,
+		chain[startHeight].Time,
+	)
+	require.Error(t, err)
+	require.True(t, errors.Is(err, errTrustPeriodExpired), err)
+
+	// backfill stopped at the first block outside the trust period, before
+	// reaching the stop height
+	for height := int64(15); height <= startHeight; height++ {
+		require.NotNil(t, rts.blockStore.LoadBlockMeta(height))
+	}
+	require.Nil(t, rts.blockStore.LoadBlockMeta(14))
+}
+
+func TestReactor_BackfillInvalidLink(t *testing.T) {
+	rts := setup(t, nil, nil, nil, 21)
+
+	var (
+		startHeight int64 = 20
+		stopHeight  int64 = 10
+		stopTime          = time.Date(2020, 1, 1, 0, 100, 0, 0, time.UTC)
+	)
+
+	rts.peerUpdateCh <- p2p.PeerUpdate{
+		NodeID: p2p.NodeID("a"),
+		Status: p2p.PeerStatusUp,
+	}
+
+	rts.stateStore.On("SaveValidatorSets", mock.AnythingOfType("int64"), mock.AnythingOfType("int64"),
+		mock.AnythingOfType("*types.ValidatorSet")).Return(nil)
+
+	// the peer serves a valid block at height 15 that isn't the one the
+	// LastBlockID of the block at height 16 commits to
+	chain := buildLightBlockChain(t, stopHeight-1, startHeight+1, stopTime)
+	fork := mockLB(t, 15, chain[15].Time, chain[15].LastBlockID)
+	chain[15] = fork
+
+	closeCh := make(chan struct{})
+	defer close(closeCh)
+	go handleLightBlockRequests(t, chain, rts.blockOutCh, rts.blockInCh, closeCh, 0)
+
+	_, err := rts.reactor.backfill(
+		context.Background(),
+		factory.DefaultTestChainID,
+		startHeight,
+		stopHeight,
+		1,
+		factory.MakeBlockIDWithHash(chain[startHeight].Header.Hash()),
+		stopTime,
+		chain[startHeight].Time,
+	)
+	require.Error(t, err)
+
+	// the blocks above the broken link were stored, but none below it
+	require.NotNil(t, rts.blockStore.LoadBlockMeta(16))
+	require.Nil(t, rts.blockStore.LoadBlockMeta(15))
+
+	select {
+	case peerErr := <-rts.blockPeerErrCh:
+		require.Equal(t, p2p.NodeID("a"), peerErr.NodeID)
+		var linkErr errInvalidLink
+		require.True(t, errors.As(peerErr.Err, &linkErr), peerErr.Err)
+		require.Equal(t, errInvalidLink{
+			height:      15,
+			hash:        fork.Hash(),
+			lastBlockID: chain[16].LastBlockID,
+		}, linkErr)
+	default:
+		t.Fatal("expected the peer to be reported for the broken link")
+	}
+}
+
+func TestReactor_BackfillStalled(t *testing.T) {
+	rts := setup(t, nil, nil, nil, 21)
+
+	var (
+		startHeight int64 = 20
+		stopHeight  int64 = 10
+		stopTime          = time.Date(2020, 1, 1, 0, 100, 0, 0, time.UTC)
+	)
+
+	// peers that don't respond are only given up on long after verification
+	// is considered stalled
+	rts.reactor.dispatcher = newDispatcher(rts.blockChannel.Out, time.Minute)
+	rts.reactor.cfg.VerifyTimeout = 100 * time.Millisecond
+
+	for _, peer := range []string{"a", "b"} {
+		rts.peerUpdateCh <- p2p.PeerUpdate{
+			NodeID: p2p.NodeID(peer),
+			Status: p2p.PeerStatusUp,
+		}
+	}
+
+	rts.stateStore.On("SaveValidatorSets", mock.AnythingOfType("int64"), mock.AnythingOfType("int64"),
+		mock.AnythingOfType("*types.ValidatorSet")).Return(nil)
+
+	chain := buildLightBlockChain(t, stopHeight-1, startHeight+1, stopTime)
+
+	// the first request for the block at height 15 is never responded to, so
+	// verification stalls at that height
+	const stallHeight = 15
+	closeCh := make(chan struct{})
+	defer close(closeCh)
+	stallPeers := make(chan p2p.NodeID, 2)
+	errCh := make(chan error, 1)
+	go func() {
+		for {
+			select {
+			case envelope := <-rts.blockOutCh:
+				msg, ok := envelope.Message.(*ssproto.LightBlockRequest)
+				if !ok {
+					continue
+				}
+				if msg.Height == stallHeight {
+					stallPeers <- envelope.To
+					if len(stallPeers) == 1 {
+						// the queue shows the height is being fetched
+						if err := checkOutstanding(rts.reactor, stallHeight); err != nil {
+							errCh <- err
+						}
+						continue
+					}
+				}
+				lb, err := chain[int64(msg.Height)].ToProto()
+				if err != nil {
+					errCh <- err
+					return
+				}
+				rts.blockInCh <- p2p.Envelope{
+					From:    envelope.To,
+					Message: &ssproto.LightBlockResponse{LightBlock: lb},
+				}
+			case <-closeCh:
+				return
+			}
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	start := time.Now()
+	base, err := rts.reactor.backfill(
+		ctx,
+		factory.DefaultTestChainID,
+		startHeight,
+		stopHeight,
+		1,
+		factory.MakeBlockIDWithHash(chain[startHeight].Header.Hash()),
+		stopTime,
+		chain[startHeight].Time,
+	)
+	require.NoError(t, err)
+	require.Equal(t, stopHeight, base)
+	require.Less(t, time.Since(start), 10*time.Second, "stall wasn't detected")
+	require.Empty(t, errCh)
+
+	// the stalled block was requested again from the other peer
+	require.Len(t, stallPeers, 2)
+	require.NotEqual(t, <-stallPeers, <-stallPeers)
+	_, ok := rts.reactor.BackfillQueue()
+	require.False(t, ok)
+	for height := stopHeight; height <= startHeight; height++ {
+		require.NotNil(t, rts.blockStore.LoadBlockMeta(height))
+	}
+}
+
+func TestReactor_BackgroundBackfill(t *testing.T) {
+	rts := setup(t, nil, nil, nil, 21)
+	rts.reactor.cfg.BackgroundBackfill = true
+
+	var (
+		startHeight int64 = 20
+		stopHeight  int64 = 10
+		stopTime          = time.Date(2020, 1, 1, 0, 100, 0, 0, time.UTC)
+	)
+
+	rts.peerUpdateCh <- p2p.PeerUpdate{
+		NodeID: p2p.NodeID("a"),
+		Status: p2p.PeerStatusUp,
+	}
+
+	// each block's validator set is saved before its header, and the base
+	// advances as the blocks are verified. Blocks whose header was saved but
+	// that aren't verified yet aren't served.
+	var (
+		bases     []int64
+		notServed []int64
+	)
+	rts.stateStore.On("SaveValidatorSets", mock.AnythingOfType("int64"), mock.AnythingOfType("int64"),
+		mock.AnythingOfType("*types.ValidatorSet")).Return(func(lh, uh int64, vals *types.ValidatorSet) error {
+		base, ok := rts.reactor.ServableBase()
+		require.True(t, ok)
+
+		// light blocks are fetched at a low priority, one at a time, on a
+		// dispatcher of their own
+		rts.reactor.mtx.RLock()
+		d := rts.reactor.backfillDispatcher
+		rts.reactor.mtx.RUnlock()
+		require.NotNil(t, d)
+		require.NotSame(t, rts.reactor.dispatcher, d)
+		d.mtx.Lock()
+		require.LessOrEqual(t, len(d.calls), 1)
+		d.mtx.Unlock()
+		rts.reactor.dispatcher.mtx.Lock()
+		require.Empty(t, rts.reactor.dispatcher.calls)
+		rts.reactor.dispatcher.mtx.Unlock()
+
+		if rts.blockStore.LoadBlockMeta(lh) == nil {
+			require.Equal(t, lh, uh)
+			bases = append(bases, base)
+		} else if height := lh - 1; height < base && rts.blockStore.LoadBlockMeta(height) != nil {
+			lb, err := rts.reactor.fetchLightBlock(uint64(height))
+			require.NoError(t, err)
+			require.Nil(t, lb)
+			notServed = append(notServed, height)
+		}
+		return nil
+	})
+
+	chain := buildLightBlockChain(t, stopHeight-1, startHeight+1, stopTime)
+
+	closeCh := make(chan struct{})
+	defer close(closeCh)
+	go handleLightBlockRequests(t, chain, rts.blockOutCh, rts.blockInCh, closeCh, 0)
+
+	_, ok := rts.reactor.ServableBase()
+	require.False(t, ok)
+
+	base, err := rts.reactor.backfill(
+		context.Background(),
+		factory.DefaultTestChainID,
+		startHeight,
+		stopHeight,
+		1,
+		factory.MakeBlockIDWithHash(chain[startHeight].Header.Hash()),
+		stopTime,
+		chain[startHeight].Time,
+	)
+	require.NoError(t, err)
+	require.Equal(t, stopHeight, base)
+
+	expectBases := []int64{}
+	for height := startHeight; height >= stopHeight; height-- {
+		expectBases = append(expectBases, height+1)
+	}
+	require.Equal(t, expectBases, bases)
+	require.NotEmpty(t, notServed)
+
+	// once done, all stored blocks are served, and the dispatcher of the
+	// backfill is stopped
+	_, ok = rts.reactor.ServableBase()
+	require.False(t, ok)
+	require.Nil(t, rts.reactor.backfillDispatcher)
+	for height := stopHeight; height <= startHeight; height++ {
+		require.NotNil(t, rts.blockStore.LoadBlockMeta(height))
+	}
+}
+
+func TestReactor_BackfillShufflesPeers(t *testing.T) {
+	rts := setup(t, nil, nil, nil, 21)
+	rts.reactor.dispatcher.shufflePeers(rand.New(rand.NewSource(1)))
+
+	var (
+		startHeight int64 = 200
+		stopHeight  int64 = 1
+		stopTime          = time.Date(2020, 1, 1, 0, 100, 0, 0, time.UTC)
+	)
+
+	peers := []p2p.NodeID{"a", "b", "c", "d"}
+	for _, peer := range peers {
+		rts.peerUpdateCh <- p2p.PeerUpdate{
+			NodeID: peer,
+			Status: p2p.PeerStatusUp,
+		}
+	}
+
+	rts.stateStore.On("SaveValidatorSets", mock.AnythingOfType("int64"), mock.AnythingOfType("int64"),
+		mock.AnythingOfType("*types.ValidatorSet")).Return(nil)
+
+	chain := buildLightBlockChain(t, stopHeight, startHeight+1, stopTime)
+
+	// serve all light blocks, counting the requests sent to each peer
+	var (
+		mtx      sync.Mutex
+		requests = make(map[p2p.NodeID]int)
+	)
+	closeCh := make(chan struct{})
+	defer close(closeCh)
+	errCh := make(chan error, 1)
+	go func() {
+		for {
+			select {
+			case envelope := <-rts.blockOutCh:
+				msg := envelope.Message.(*ssproto.LightBlockRequest)
+				mtx.Lock()
+				requests[envelope.To]++
+				mtx.Unlock()
+				lb, err := chain[int64(msg.Height)].ToProto()
+				if err != nil {
+					errCh <- err
+					return
+				}
+				rts.blockInCh <- p2p.Envelope{
+					From:    envelope.To,
+					Message: &ssproto.LightBlockResponse{LightBlock: lb},
+				}
+			case <-closeCh:
+				return
+			}
+		}
+	}()
+
+	_, err := rts.reactor.backfill(
+		context.Background(),
+		factory.DefaultTestChainID,
+		startHeight,
+		stopHeight,
+		1,
+		factory.MakeBlockIDWithHash(chain[startHeight].Header.Hash()),
+		stopTime,
+		chain[startHeight].Time,
+	)
+	require.NoError(t, err)
+	require.Empty(t, errCh)
+
+	// the fetches are spread roughly evenly across the peers
+	mtx.Lock()
+	defer mtx.Unlock()
+	total := 0
+	for _, n := range requests {
+		total += n
+	}
+	require.GreaterOrEqual(t, total, int(startHeight-stopHeight+1))
+	mean := float64(total) / float64(len(peers))
+	for _, peer := range peers {
+		require.InDelta(t, mean, requests[peer], mean/2, "peer %v", peer)
+	}
+}
+
+func TestReactor_BackfillWitnesses(t *testing.T) {
+	const (
+		witnesses            = 2
+		startHeight    int64 = 20
+		stopHeight     int64 = 10
+		conflictHeight int64 = 15
+	)
+	stopTime := time.Date(2020, 1, 1, 0, 100, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		conflict    bool
+		latePeers   bool
+		failedServe bool
+	}{
+		"all witnesses agree":          {false, false, false},
+		"one witness disagrees":        {true, false, false},
+		"witnesses connect late":       {false, true, false},
+		"witness fails to serve block": {false, false, true},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			rts := setup(t, nil, nil, nil, 21)
+			rts.reactor.cfg.Fetchers = 1
+			rts.reactor.cfg.Witnesses = witnesses
+			conflicts := generic.NewCounter("conflicts")
+			rts.reactor.metrics.BackfillConflicts = conflicts
+			retries := generic.NewCounter("retries")
+			rts.reactor.metrics.LightBlockFetchRetries = retries
+			evpool := &mockEvidencePool{}
+			rts.reactor.evidencePool = evpool
+
+			// with late peers, only the peer serving the blocks and one
+			// witness are connected to begin with
+			peers := []p2p.NodeID{"a", "b", "c", "d"}
+			connected := peers
+			if tc.latePeers {
+				connected = peers[:2]
+			}
+			for _, peer := range connected {
+				rts.peerUpdateCh <- p2p.PeerUpdate{
+					NodeID: peer,
+					Status: p2p.PeerStatusUp,
+				}
+			}
+			require.Eventually(t, func() bool {
+				return rts.reactor.dispatcher.availablePeers.Len() == len(connected)
+			}, time.Second, 10*time.Millisecond)
+
+			// the evidence submitted by the time the final batch of
+			// validator sets is saved, once all blocks are verified
+			evidenceBeforeEnd := -1
+			rts.stateStore.On("SaveValidatorSets", mock.AnythingOfType("int64"), mock.AnythingOfType("int64"),
+				mock.AnythingOfType("*types.ValidatorSet")).Return(func(lh, uh int64, vals *types.ValidatorSet) error {
+				if lh == stopHeight {
+					evpool.mtx.Lock()
+					evidenceBeforeEnd = len(evpool.evidence)
+					evpool.mtx.Unlock()
+				}
+				return nil
+			})
+
+			chain := buildLightBlockChain(t, stopHeight-1, startHeight+1, stopTime)
+			fork := mockLB(t, conflictHeight, chain[conflictHeight].Time, chain[conflictHeight].LastBlockID)
+
+			// serve all light blocks, counting the requests for each height
+			// and recording the peers asked for the block at the conflict
+			// height. If there's a conflict, the first witness asked for the
+			// light block at the conflict height serves a different one, and
+			// if a witness fails to serve it, it responds with no block
+			// instead.
+			var (
+				mtx      sync.Mutex
+				requests = make(map[int64]int)
+				asked    []p2p.NodeID
+				forkPeer p2p.NodeID
+			)
+			errCh := make(chan error, 1)
+			closeCh := make(chan struct{})
+			defer close(closeCh)
+			go func() {
+				for {
+					select {
+					case envelope := <-rts.blockOutCh:
+						height := int64(envelope.Message.(*ssproto.LightBlockRequest).Height)
+						mtx.Lock()
+						requests[height]++
+						if height == conflictHeight {
+							asked = append(asked, envelope.To)
+						}
+						served := chain[height]
+						if height == conflictHeight && requests[height] == 2 {
+							switch {
+							case tc.conflict:
+								served = fork
+								forkPeer = envelope.To
+							case tc.failedServe:
+								served = nil
+							}
+						}
+						mtx.Unlock()
+						resp := &ssproto.LightBlockResponse{}
+						if served != nil {
+							lb, err := served.ToProto()
+							if err != nil {
+								errCh <- err
+								return
+							}
+							resp.LightBlock = lb
+						}
+						rts.blockInCh <- p2p.Envelope{
+							From:    envelope.To,
+							Message: resp,
+						}
+					case <-closeCh:
+						return
+					}
+				}
+			}()
+
+			// the backfill waits for the missing witnesses
+			if tc.latePeers {
+				go func() {
+					time.Sleep(2 * witnessRetryInterval)
+					for _, peer := range peers[2:] {
+						rts.peerUpdateCh <- p2p.PeerUpdate{
+							NodeID: peer,
+							Status: p2p.PeerStatusUp,
+						}
+					}
+				}()
+			}
+
+			base, err := rts.reactor.backfill(
+				context.Background(),
+				factory.DefaultTestChainID,
+				startHeight,
+				stopHeight,
+				1,
+				factory.MakeBlockIDWithHash(chain[startHeight].Header.Hash()),
+				stopTime,
+				chain[startHeight].Time,
+			)
+			select {
+			case err := <-errCh:
+				require.NoError(t, err)
+			default:
+			}
+			require.NoError(t, err)
+			require.Equal(t, stopHeight, base)
+			for height := startHeight; height >= stopHeight; height-- {
+				blockMeta := rts.blockStore.LoadBlockMeta(height)
+				require.NotNil(t, blockMeta)
+				require.Equal(t, chain[height].Hash(), blockMeta.BlockID.Hash)
+			}
+
+			// every block was cross-checked, and waiting for witnesses
+			// didn't count as a retry
+			mtx.Lock()
+			defer mtx.Unlock()
+			for height := startHeight; height >= stopHeight; height-- {
+				require.GreaterOrEqual(t, requests[height], witnesses+1, "height %d", height)
+			}
+			if tc.failedServe {
+				require.GreaterOrEqual(t, requests[conflictHeight], 2*witnesses+1)
+			}
+
+			// a conflict is reported and the block retried, from a peer that
+			// wasn't part of the conflict. The conflict is submitted as
+			// evidence of a lunatic attack, with the block below it as the
+			// common block, as soon as that one is verified, and the witness
+			// that served the fork is punished.
+			if tc.conflict {
+				require.EqualValues(t, 1, conflicts.Value())
+				require.EqualValues(t, 1, retries.Value())
+				require.Equal(t, 2*(witnesses+1), requests[conflictHeight])
+				require.NotContains(t, []p2p.NodeID{asked[0], forkPeer}, asked[witnesses+1])
+				rts.stateStore.AssertCalled(t, "SaveValidatorSets", conflictHeight-1, conflictHeight-1,
+					chain[conflictHeight-1].ValidatorSet)
+				require.Equal(t, 1, evidenceBeforeEnd)
+				require.Len(t, evpool.evidence, 1)
+				ev, ok := evpool.evidence[0].(*types.LightClientAttackEvidence)
+				require.True(t, ok)
+				require.Equal(t, fork.Hash(), ev.ConflictingBlock.Hash())
+				require.Equal(t, conflictHeight-1, ev.CommonHeight)
+				require.Equal(t, chain[conflictHeight-1].Time, ev.Timestamp)
+
+				select {
+				case peerErr := <-rts.blockPeerErrCh:
+					require.Equal(t, forkPeer, peerErr.NodeID)
+				default:
+					require.Fail(t, "the witness serving the fork wasn't punished")
+				}
+			} else {
+				require.Zero(t, conflicts.Value())
+				require.Zero(t, retries.Value())
+				require.Empty(t, evpool.evidence)
+			}
+			require.Empty(t, rts.blockPeerErrCh)
 		})
 	}
 }
 
+func TestReactor_BackfillTooFewWitnesses(t *testing.T) {
+	const (
+		startHeight int64 = 20
+		stopHeight  int64 = 10
+	)
+	stopTime := time.Date(2020, 1, 1, 0, 100, 0, 0, time.UTC)
+
+	rts := setup(t, nil, nil, nil, 21)
+	rts.reactor.cfg.Fetchers = 1
+	rts.reactor.cfg.Witnesses = 2
+	rts.reactor.witnessTimeout = 50 * time.Millisecond
+	retries := generic.NewCounter("retries")
+	rts.reactor.metrics.LightBlockFetchRetries = retries
+
+	// there's only one witness for the peer serving the blocks
+	for _, peer := range []p2p.NodeID{"a", "b"} {
+		rts.peerUpdateCh <- p2p.PeerUpdate{
+			NodeID: peer,
+			Status: p2p.PeerStatusUp,
+		}
+	}
+	rts.stateStore.On("SaveValidatorSets", mock.AnythingOfType("int64"), mock.AnythingOfType("int64"),
+		mock.AnythingOfType("*types.ValidatorSet")).Return(nil)
+
+	chain := buildLightBlockChain(t, stopHeight-1, startHeight+1, stopTime)
+	closeCh := make(chan struct{})
+	defer close(closeCh)
+	go handleLightBlockRequests(t, chain, rts.blockOutCh, rts.blockInCh, closeCh, 0)
+
+	// the backfill doesn't hang waiting for witnesses, but fails once the
+	// heights retried for the lack of them exhaust the retry budget
+	_, err := rts.reactor.backfill(
+		context.Background(),
+		factory.DefaultTestChainID,
+		startHeight,
+		stopHeight,
+		1,
+		factory.MakeBlockIDWithHash(chain[startHeight].Header.Hash()),
+		stopTime,
+		chain[startHeight].Time,
+	)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "max retries")
+	require.GreaterOrEqual(t, retries.Value(), float64(maxLightBlockRequestRetries))
+}
+
+// checkOutstanding returns an error unless the reactor is backfilling, with
+// the given height being fetched.
+func checkOutstanding(r *Reactor, height int64) error {
+	queue, ok := r.BackfillQueue()
+	if !ok {
+		return errors.New("not backfilling")
+	}
+	for _, outstanding := range queue.Outstanding {
+		if outstanding == height {
+			return nil
+		}
+	}
+	return fmt.Errorf("height %d not outstanding: %v", height, queue.Outstanding)
+}
+
+// mockEvidencePool is an EvidencePool that records the evidence added to it.
+type mockEvidencePool struct {
+	mtx      sync.Mutex
+	evidence []types.Evidence
+}
+
+func (p *mockEvidencePool) AddEvidence(ev types.Evidence) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.evidence = append(p.evidence, ev)
+	return nil
+}
+
+// maxGauge is a gauge that records the highest value it was set to.
+type maxGauge struct {
+	*generic.Gauge
+
+	mtx sync.Mutex
+	max float64
+}
+
+func (g *maxGauge) Set(value float64) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if value > g.max {
+		g.max = value
+	}
+	g.Gauge.Set(value)
+}
+
+func (g *maxGauge) Max() float64 {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	return g.max
+}
+
 // retryUntil will continue to evaluate fn and will return successfully when true
 // or fail when the timeout is reached.
 func retryUntil(t *testing.T, fn func() bool, timeout time.Duration) {
@@ -560,15 +1387,20 @@ func buildLightBlockChain(t *testing.T, fromHeight, toHeight int64, startTime ti
 	return chain
 }
 
-func mockLB(t *testing.T, height int64, time time.Time,
+func mockLB(t testing.TB, height int64, time time.Time,
 	lastBlockID types.BlockID) *types.LightBlock {
+	return mockLBWithValidators(t, height, time, lastBlockID, 3)
+}
+
+func mockLBWithValidators(t testing.TB, height int64, time time.Time,
+	lastBlockID types.BlockID, numValidators int) *types.LightBlock {
 	header, err := factory.MakeHeader(&types.Header{
 		Height:      height,
 		LastBlockID: lastBlockID,
 		Time:        time,
 	})
 	require.NoError(t, err)
-	vals, pv := factory.RandValidatorSet(3, 10)
+	vals, pv := factory.RandValidatorSet(numValidators, 10)
 	header.ValidatorsHash = vals.Hash()
 	lastBlockID = factory.MakeBlockIDWithHash(header.Hash())
 	voteSet := types.NewVoteSet(factory.DefaultTestChainID, height, 0, tmproto.PrecommitType, vals)
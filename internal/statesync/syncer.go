@@ -25,6 +25,10 @@ const (
 	// minimumDiscoveryTime is the lowest allowable time for a
 	// SyncAny discovery time.
 	minimumDiscoveryTime = 5 * time.Second
+
+	// maxProviderTimeouts is the number of consecutive chunk request timeouts after which a
+	// snapshot provider is dropped, as long as the snapshot has other providers.
+	maxProviderTimeouts = 3
 )
 
 var (
@@ -44,6 +48,11 @@ var (
 	errTimeout = errors.New("timed out waiting for chunk")
 	// errNoSnapshots is returned by SyncAny() if no snapshots are found and discovery is disabled.
 	errNoSnapshots = errors.New("no suitable snapshots found")
+	// errNotEnoughProviders is returned by SyncAny() if no snapshot is offered by enough peers.
+	errNotEnoughProviders = errors.New("no snapshot offered by enough providers")
+	// errSyncInProgress is returned by Sync() if a snapshot is already being restored, since the
+	// app can only restore one snapshot at a time.
+	errSyncInProgress = errors.New("a state sync is already in progress")
 )
 
 // syncer runs a state sync against an ABCI app. Use either SyncAny() to automatically attempt to
@@ -58,11 +67,19 @@ type syncer struct {
 	snapshotCh    chan<- p2p.Envelope
 	chunkCh       chan<- p2p.Envelope
 	tempDir       string
+	chunkDir      string
 	fetchers      int32
 	retryTimeout  time.Duration
+	providers     *chunkProviders
+
+	// the number of peers that must offer a snapshot for it to be restored,
+	// and how long to discover snapshots for one to be, if at all
+	minProviders     int
+	providersTimeout time.Duration
 
-	mtx    tmsync.RWMutex
-	chunks *chunkQueue
+	mtx     tmsync.RWMutex
+	chunks  *chunkQueue // the chunks of the snapshot being restored, if any
+	aborted bool        // whether the restoration in progress was aborted
 }
 
 // newSyncer creates a new syncer.
@@ -75,6 +92,11 @@ func newSyncer(
 	snapshotCh, chunkCh chan<- p2p.Envelope,
 	tempDir string,
 ) *syncer {
+	fetchers := cfg.Fetchers
+	if cfg.ChunkFetchers > 0 {
+		fetchers = cfg.ChunkFetchers
+	}
+
 	return &syncer{
 		logger:        logger,
 		stateProvider: stateProvider,
@@ -84,8 +106,13 @@ func newSyncer(
 		snapshotCh:    snapshotCh,
 		chunkCh:       chunkCh,
 		tempDir:       tempDir,
-		fetchers:      cfg.Fetchers,
+		chunkDir:      cfg.ChunkDir,
+		fetchers:      fetchers,
 		retryTimeout:  cfg.ChunkRequestTimeout,
+		providers:     newChunkProviders(maxProviderTimeouts),
+
+		minProviders:     int(cfg.MinSnapshotProviders),
+		providersTimeout: cfg.SnapshotProvidersTimeout,
 	}
 }
 
@@ -102,6 +129,7 @@ func (s *syncer) AddChunk(chunk *chunk) (bool, error) {
 		return false, err
 	}
 	if added {
+		s.providers.Received(chunk.Sender)
 		s.logger.Debug("Added chunk to queue", "height", chunk.Height, "format", chunk.Format,
 			"chunk", chunk.Index)
 	} else {
@@ -141,9 +169,16 @@ func (s *syncer) RemovePeer(peerID p2p.NodeID) {
 	s.snapshots.RemovePeer(peerID)
 }
 
+// ProviderStats returns the chunk fetching statistics of the snapshot providers.
+func (s *syncer) ProviderStats() map[p2p.NodeID]ProviderStats {
+	return s.providers.Stats()
+}
+
 // SyncAny tries to sync any of the snapshots in the snapshot pool, waiting to discover further
-// snapshots if none were found and discoveryTime > 0. It returns the latest state and block commit
-// which the caller must use to bootstrap the node.
+// snapshots if none were found and discoveryTime > 0. Only the snapshots offered by at least
+// minProviders peers are synced, and it gives up with errNotEnoughProviders if none is after the
+// providers timeout. It returns the latest state and block commit which the caller must use to
+// bootstrap the node.
 func (s *syncer) SyncAny(
 	ctx context.Context,
 	discoveryTime time.Duration,
@@ -155,9 +190,9 @@ func (s *syncer) SyncAny(
 	}
 
 	if discoveryTime > 0 {
-		requestSnapshots()
-		s.logger.Info(fmt.Sprintf("Discovering snapshots for %v", discoveryTime))
-		time.Sleep(discoveryTime)
+		if err := s.discover(ctx, discoveryTime, requestSnapshots); err != nil {
+			return sm.State{}, nil, err
+		}
 	}
 
 	// The app may ask us to retry a snapshot restoration, in which case we need to reuse
@@ -166,24 +201,31 @@ func (s *syncer) SyncAny(
 		snapshot *snapshot
 		chunks   *chunkQueue
 		err      error
+		start    = time.Now()
 	)
 	for {
 		// If not nil, we're going to retry restoration of the same snapshot.
 		if snapshot == nil {
-			snapshot = s.snapshots.Best()
+			snapshot = s.bestSnapshot()
 			chunks = nil
 		}
 		if snapshot == nil {
+			// there may be snapshots, just none offered by enough peers
+			timedOut := discoveryTime == 0 || (s.providersTimeout > 0 && time.Since(start) >= s.providersTimeout)
+			if best := s.snapshots.Best(); best != nil && timedOut {
+				return sm.State{}, nil, fmt.Errorf("%w: best snapshot at height %v offered by %d of %d peers",
+					errNotEnoughProviders, best.Height, len(s.snapshots.GetPeers(best)), s.minProviders)
+			}
 			if discoveryTime == 0 {
 				return sm.State{}, nil, errNoSnapshots
 			}
-			requestSnapshots()
-			s.logger.Info(fmt.Sprintf("Discovering snapshots for %v", discoveryTime))
-			time.Sleep(discoveryTime)
+			if err := s.discover(ctx, discoveryTime, requestSnapshots); err != nil {
+				return sm.State{}, nil, err
+			}
 			continue
 		}
 		if chunks == nil {
-			chunks, err = newChunkQueue(snapshot, s.tempDir)
+			chunks, err = s.newChunkQueue(snapshot)
 			if err != nil {
 				return sm.State{}, nil, fmt.Errorf("failed to create chunk queue: %w", err)
 			}
@@ -193,6 +235,9 @@ func (s *syncer) SyncAny(
 		newState, commit, err := s.Sync(ctx, snapshot, chunks)
 		switch {
 		case err == nil:
+			if err := chunks.Purge(); err != nil {
+				s.logger.Error("Failed to clean up chunk queue", "err", err)
+			}
 			return newState, commit, nil
 
 		case errors.Is(err, errAbort):
@@ -231,7 +276,7 @@ func (s *syncer) SyncAny(
 		}
 
 		// Discard snapshot and chunks for next iteration
-		err = chunks.Close()
+		err = chunks.Purge()
 		if err != nil {
 			s.logger.Error("Failed to clean up chunk queue", "err", err)
 		}
@@ -240,22 +285,116 @@ func (s *syncer) SyncAny(
 	}
 }
 
+// bestSnapshot returns the best snapshot in the pool offered by at least minProviders peers, if
+// any.
+func (s *syncer) bestSnapshot() *snapshot {
+	for _, snapshot := range s.snapshots.Ranked() {
+		if len(s.snapshots.GetPeers(snapshot)) >= s.minProviders {
+			return snapshot
+		}
+	}
+	return nil
+}
+
+// newChunkQueue creates the chunk queue of a snapshot, which is persistent if a chunk dir is
+// configured, in which case the restore resumes with the chunks already fetched, if any.
+func (s *syncer) newChunkQueue(snapshot *snapshot) (*chunkQueue, error) {
+	if s.chunkDir == "" {
+		return newChunkQueue(snapshot, s.tempDir)
+	}
+
+	chunks, err := newPersistentChunkQueue(snapshot, s.chunkDir)
+	if err != nil {
+		return nil, err
+	}
+	if fetched := chunks.Fetched(); fetched > 0 {
+		s.logger.Info("Resuming snapshot restoration with chunks already fetched", "height", snapshot.Height,
+			"format", snapshot.Format, "hash", snapshot.Hash, "chunks", fetched)
+	}
+	return chunks, nil
+}
+
+// discover requests snapshots from peers and waits discoveryTime for them to be discovered. It
+// returns errAbort if the context is canceled while waiting.
+func (s *syncer) discover(ctx context.Context, discoveryTime time.Duration, requestSnapshots func()) error {
+	requestSnapshots()
+	s.logger.Info(fmt.Sprintf("Discovering snapshots for %v", discoveryTime))
+
+	select {
+	case <-time.After(discoveryTime):
+		return nil
+	case <-ctx.Done():
+		return errAbort
+	}
+}
+
 // Sync executes a sync for a specific snapshot, returning the latest state and block commit which
-// the caller must use to bootstrap the node.
+// the caller must use to bootstrap the node. Only one snapshot can be restored at a time, so it
+// returns errSyncInProgress if another one is being restored. The restoration is aborted, and
+// errAbort returned, if the context is canceled or Abort() is called.
 func (s *syncer) Sync(ctx context.Context, snapshot *snapshot, chunks *chunkQueue) (sm.State, *types.Commit, error) {
 	s.mtx.Lock()
 	if s.chunks != nil {
 		s.mtx.Unlock()
-		return sm.State{}, nil, errors.New("a state sync is already in progress")
+		return sm.State{}, nil, errSyncInProgress
 	}
 	s.chunks = chunks
+	s.aborted = false
 	s.mtx.Unlock()
+
+	done := make(chan struct{})
 	defer func() {
+		close(done)
 		s.mtx.Lock()
 		s.chunks = nil
 		s.mtx.Unlock()
 	}()
 
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.Abort()
+		case <-done:
+		}
+	}()
+
+	state, commit, err := s.restore(ctx, snapshot, chunks)
+	if s.isAborted() {
+		s.logger.Info("Snapshot restoration aborted", "height", snapshot.Height, "format", snapshot.Format,
+			"hash", snapshot.Hash)
+		return sm.State{}, nil, errAbort
+	}
+	return state, commit, err
+}
+
+// Abort aborts the snapshot restoration in progress, if any, closing its chunk queue so that
+// Sync() returns errAbort as soon as it is done with the current step. It returns false if no
+// snapshot was being restored.
+func (s *syncer) Abort() bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.chunks == nil {
+		return false
+	}
+	if !s.aborted {
+		s.aborted = true
+		if err := s.chunks.Close(); err != nil {
+			s.logger.Error("Failed to clean up chunk queue", "err", err)
+		}
+	}
+	return true
+}
+
+// isAborted returns whether the snapshot restoration in progress was aborted.
+func (s *syncer) isAborted() bool {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return s.aborted
+}
+
+// restore restores a snapshot for Sync().
+func (s *syncer) restore(ctx context.Context, snapshot *snapshot, chunks *chunkQueue) (sm.State, *types.Commit, error) {
 	// Offer snapshot to ABCI app.
 	err := s.offerSnapshot(ctx, snapshot)
 	if err != nil {
@@ -288,6 +427,11 @@ func (s *syncer) Sync(ctx context.Context, snapshot *snapshot, chunks *chunkQueu
 		return sm.State{}, nil, err
 	}
 
+	// Aborting closes the chunk queue, which also ends applyChunks() without an error.
+	if s.isAborted() {
+		return sm.State{}, nil, errAbort
+	}
+
 	// Verify app and update app version
 	appVersion, err := s.verifyApp(snapshot)
 	if err != nil {
@@ -373,6 +517,7 @@ func (s *syncer) applyChunks(ctx context.Context, chunks *chunkQueue) error {
 			if sender != "" {
 				peerID := p2p.NodeID(sender)
 				s.snapshots.RejectPeer(peerID)
+				s.providers.Rejected(peerID)
 
 				if err := chunks.DiscardSender(peerID); err != nil {
 					return fmt.Errorf("failed to reject sender: %w", err)
@@ -397,11 +542,14 @@ func (s *syncer) applyChunks(ctx context.Context, chunks *chunkQueue) error {
 }
 
 // fetchChunks requests chunks from peers, receiving allocations from the chunk queue. Chunks
-// will be received from the reactor via syncer.AddChunks() to chunkQueue.Add().
+// will be received from the reactor via syncer.AddChunks() to chunkQueue.Add(). When a request
+// times out, the chunk is requested again from a provider that hasn't been tried for it yet.
 func (s *syncer) fetchChunks(ctx context.Context, snapshot *snapshot, chunks *chunkQueue) {
 	var (
 		next  = true
 		index uint32
+		peer  p2p.NodeID
+		tried map[p2p.NodeID]bool
 		err   error
 	)
 
@@ -422,6 +570,7 @@ func (s *syncer) fetchChunks(ctx context.Context, snapshot *snapshot, chunks *ch
 				s.logger.Error("Failed to allocate chunk from queue", "err", err)
 				return
 			}
+			tried = make(map[p2p.NodeID]bool)
 		}
 		s.logger.Info("Fetching snapshot chunk", "height", snapshot.Height,
 			"format", snapshot.Format, "chunk", index, "total", chunks.Size())
@@ -429,7 +578,7 @@ func (s *syncer) fetchChunks(ctx context.Context, snapshot *snapshot, chunks *ch
 		ticker := time.NewTicker(s.retryTimeout)
 		defer ticker.Stop()
 
-		s.requestChunk(snapshot, index)
+		peer = s.requestChunk(snapshot, index, tried)
 
 		select {
 		case <-chunks.WaitFor(index):
@@ -437,6 +586,10 @@ func (s *syncer) fetchChunks(ctx context.Context, snapshot *snapshot, chunks *ch
 
 		case <-ticker.C:
 			next = false
+			if peer != "" {
+				tried[peer] = true
+				s.providerTimedOut(snapshot, peer)
+			}
 
 		case <-ctx.Done():
 			return
@@ -446,13 +599,14 @@ func (s *syncer) fetchChunks(ctx context.Context, snapshot *snapshot, chunks *ch
 	}
 }
 
-// requestChunk requests a chunk from a peer.
-func (s *syncer) requestChunk(snapshot *snapshot, chunk uint32) {
-	peer := s.snapshots.GetPeer(snapshot)
+// requestChunk requests a chunk from a peer, preferring peers not in exclude. It returns the
+// peer the chunk was requested from, or an empty ID if there are no peers for the snapshot.
+func (s *syncer) requestChunk(snapshot *snapshot, chunk uint32, exclude map[p2p.NodeID]bool) p2p.NodeID {
+	peer := s.providers.Pick(s.snapshots.GetPeers(snapshot), exclude)
 	if peer == "" {
 		s.logger.Error("No valid peers found for snapshot", "height", snapshot.Height,
 			"format", snapshot.Format, "hash", snapshot.Hash)
-		return
+		return ""
 	}
 
 	s.logger.Debug(
@@ -471,6 +625,21 @@ func (s *syncer) requestChunk(snapshot *snapshot, chunk uint32) {
 			Index:  chunk,
 		},
 	}
+	return peer
+}
+
+// providerTimedOut records a chunk request timeout for a peer, and drops the peer from the
+// snapshot pool once it has timed out too many times in a row. The last provider of a snapshot
+// is kept, since there is nothing to fail over to.
+func (s *syncer) providerTimedOut(snapshot *snapshot, peer p2p.NodeID) {
+	if !s.providers.TimedOut(peer) || len(s.snapshots.GetPeers(snapshot)) <= 1 {
+		return
+	}
+
+	s.logger.Info("Dropping unresponsive snapshot provider", "peer", peer, "height", snapshot.Height,
+		"format", snapshot.Format, "hash", snapshot.Hash)
+	s.snapshots.RemovePeer(peer)
+	s.providers.Dropped(peer)
 }
 
 // verifyApp verifies the sync, checking the app hash and last block height. It returns the
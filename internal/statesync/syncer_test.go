@@ -2,7 +2,9 @@ package statesync
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -231,6 +233,108 @@ func TestSyncer_SyncAny_abort(t *testing.T) {
 	rts.conn.AssertExpectations(t)
 }
 
+func TestSyncer_SyncAny_minProviders(t *testing.T) {
+	stateProvider := &mocks.StateProvider{}
+	stateProvider.On("AppHash", mock.Anything, mock.Anything).Return([]byte("app_hash"), nil)
+
+	rts := setup(t, nil, nil, stateProvider, 2)
+	rts.syncer.minProviders = 2
+
+	// a snapshot offered by a single peer, and a higher one whose peers don't
+	// agree on its hash, aren't restored
+	s := &snapshot{Height: 1, Format: 1, Chunks: 3, Hash: []byte{1, 2, 3}}
+	high := &snapshot{Height: 2, Format: 1, Chunks: 3, Hash: []byte{1, 2, 3}}
+	forged := &snapshot{Height: 2, Format: 1, Chunks: 3, Hash: []byte{4, 5, 6}}
+	_, err := rts.syncer.AddSnapshot("aa", s)
+	require.NoError(t, err)
+	_, err = rts.syncer.AddSnapshot("bb", high)
+	require.NoError(t, err)
+	_, err = rts.syncer.AddSnapshot("cc", forged)
+	require.NoError(t, err)
+
+	_, _, err = rts.syncer.SyncAny(ctx, 0, func() {})
+	require.ErrorIs(t, err, errNotEnoughProviders)
+	require.Contains(t, err.Error(), "offered by 1 of 2 peers")
+	rts.conn.AssertNotCalled(t, "OfferSnapshotSync", mock.Anything, mock.Anything)
+
+	// once another peer offers the snapshot, it's restored
+	_, err = rts.syncer.AddSnapshot("cc", s)
+	require.NoError(t, err)
+	rts.conn.On("OfferSnapshotSync", ctx, abci.RequestOfferSnapshot{
+		Snapshot: toABCI(s), AppHash: []byte("app_hash"),
+	}).Once().Return(&abci.ResponseOfferSnapshot{Result: abci.ResponseOfferSnapshot_ABORT}, nil)
+
+	_, _, err = rts.syncer.SyncAny(ctx, 0, func() {})
+	require.Equal(t, errAbort, err)
+	rts.conn.AssertExpectations(t)
+}
+
+func TestSyncer_Sync_concurrent(t *testing.T) {
+	s := &snapshot{Height: 1, Format: 1, Chunks: 3, Hash: []byte{1, 2, 3}, trustedAppHash: []byte("app_hash")}
+
+	// the restoration blocks waiting for chunks once the commit is fetched
+	fetched := make(chan struct{}, 1)
+	stateProvider := &mocks.StateProvider{}
+	stateProvider.On("State", mock.Anything, uint64(1)).Return(sm.State{}, nil)
+	stateProvider.On("Commit", mock.Anything, uint64(1)).Run(func(args mock.Arguments) {
+		fetched <- struct{}{}
+	}).Return(&types.Commit{}, nil)
+
+	// the snapshot has no providers, so its chunks are never requested
+	rts := setup(t, nil, nil, stateProvider, 2)
+
+	rts.conn.On("OfferSnapshotSync", mock.Anything, abci.RequestOfferSnapshot{
+		Snapshot: toABCI(s), AppHash: []byte("app_hash"),
+	}).Return(&abci.ResponseOfferSnapshot{Result: abci.ResponseOfferSnapshot_ACCEPT}, nil)
+
+	restore := func(ctx context.Context) <-chan error {
+		errCh := make(chan error, 1)
+		chunks, err := newChunkQueue(s, "")
+		require.NoError(t, err)
+		go func() {
+			defer chunks.Close()
+			_, _, err := rts.syncer.Sync(ctx, s, chunks)
+			errCh <- err
+		}()
+		<-fetched
+		return errCh
+	}
+
+	// no restoration is in progress, so there is nothing to abort
+	require.False(t, rts.syncer.Abort())
+
+	errCh := restore(ctx)
+
+	// a second restoration is rejected while the first one is in progress
+	chunks, err := newChunkQueue(s, "")
+	require.NoError(t, err)
+	defer chunks.Close()
+	_, _, err = rts.syncer.Sync(ctx, s, chunks)
+	require.Equal(t, errSyncInProgress, err)
+
+	// aborting the restoration in progress ends it
+	require.True(t, rts.syncer.Abort())
+	select {
+	case err := <-errCh:
+		require.Equal(t, errAbort, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("snapshot restoration was not aborted")
+	}
+	require.False(t, rts.syncer.Abort())
+
+	// once it ended, another restoration can start, and canceling its context
+	// aborts it as well
+	cctx, cancel := context.WithCancel(ctx)
+	errCh = restore(cctx)
+	cancel()
+	select {
+	case err := <-errCh:
+		require.Equal(t, errAbort, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("snapshot restoration was not aborted")
+	}
+}
+
 func TestSyncer_SyncAny_reject(t *testing.T) {
 	stateProvider := &mocks.StateProvider{}
 	stateProvider.On("AppHash", mock.Anything, mock.Anything).Return([]byte("app_hash"), nil)
@@ -374,6 +478,278 @@ func TestSyncer_SyncAny_abciError(t *testing.T) {
 	rts.conn.AssertExpectations(t)
 }
 
+func TestSyncer_SyncAny_providerFailover(t *testing.T) {
+	state := sm.State{ChainID: "chain", AppHash: []byte("app_hash")}
+	commit := &types.Commit{BlockID: types.BlockID{Hash: []byte("blockhash")}}
+
+	stateProvider := &mocks.StateProvider{}
+	stateProvider.On("AppHash", mock.Anything, uint64(1)).Return(state.AppHash, nil)
+	stateProvider.On("State", mock.Anything, uint64(1)).Return(state, nil)
+	stateProvider.On("Commit", mock.Anything, uint64(1)).Return(commit, nil)
+
+	rts := setup(t, nil, nil, stateProvider, 8)
+	rts.syncer.retryTimeout = 100 * time.Millisecond
+
+	// Both peers provide the snapshot, but peer a goes dark and never serves any chunks, so
+	// its chunks must be refetched from peer b, and peer a dropped.
+	peerAID := p2p.NodeID("aa")
+	peerBID := p2p.NodeID("bb")
+	s := &snapshot{Height: 1, Format: 1, Chunks: 8, Hash: []byte{1, 2, 3}}
+
+	_, err := rts.syncer.AddSnapshot(peerAID, s)
+	require.NoError(t, err)
+	_, err = rts.syncer.AddSnapshot(peerBID, s)
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case e := <-rts.chunkOutCh:
+				msg, ok := e.Message.(*ssproto.ChunkRequest)
+				assert.True(t, ok)
+				if e.To == peerAID {
+					continue
+				}
+				_, _ = rts.syncer.AddChunk(&chunk{
+					Height: msg.Height,
+					Format: msg.Format,
+					Index:  msg.Index,
+					Chunk:  []byte{byte(msg.Index)},
+					Sender: e.To,
+				})
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	rts.conn.On("OfferSnapshotSync", ctx, abci.RequestOfferSnapshot{
+		Snapshot: toABCI(s), AppHash: []byte("app_hash"),
+	}).Once().Return(&abci.ResponseOfferSnapshot{Result: abci.ResponseOfferSnapshot_ACCEPT}, nil)
+	rts.conn.On("ApplySnapshotChunkSync", ctx, mock.Anything).Times(int(s.Chunks)).Run(func(args mock.Arguments) {
+		req := args.Get(1).(abci.RequestApplySnapshotChunk)
+		assert.EqualValues(t, peerBID, req.Sender)
+	}).Return(&abci.ResponseApplySnapshotChunk{Result: abci.ResponseApplySnapshotChunk_ACCEPT}, nil)
+	rts.connQuery.On("InfoSync", ctx, proxy.RequestInfo).Return(&abci.ResponseInfo{
+		LastBlockHeight:  1,
+		LastBlockAppHash: []byte("app_hash"),
+	}, nil)
+
+	_, _, err = rts.syncer.SyncAny(ctx, 0, func() {})
+	require.NoError(t, err)
+	rts.conn.AssertExpectations(t)
+
+	stats := rts.syncer.ProviderStats()
+	require.GreaterOrEqual(t, stats[peerAID].TimedOut, uint64(maxProviderTimeouts))
+	require.Zero(t, stats[peerAID].Received)
+	require.True(t, stats[peerAID].Dropped)
+	require.EqualValues(t, s.Chunks, stats[peerBID].Received)
+	require.False(t, stats[peerBID].Dropped)
+
+	require.Equal(t, []p2p.NodeID{peerBID}, rts.syncer.snapshots.GetPeers(s))
+}
+
+func TestSyncer_SyncAny_resume(t *testing.T) {
+	state := sm.State{ChainID: "chain", AppHash: []byte("app_hash")}
+	commit := &types.Commit{BlockID: types.BlockID{Hash: []byte("blockhash")}}
+
+	stateProvider := &mocks.StateProvider{}
+	stateProvider.On("AppHash", mock.Anything, uint64(1)).Return(state.AppHash, nil)
+	stateProvider.On("State", mock.Anything, uint64(1)).Return(state, nil)
+	stateProvider.On("Commit", mock.Anything, uint64(1)).Return(commit, nil)
+
+	rts := setup(t, nil, nil, stateProvider, 4)
+	rts.syncer.chunkDir = t.TempDir()
+
+	// The snapshot metadata is a manifest of the chunks' hashes.
+	metadata := []byte{}
+	for i := 0; i < 4; i++ {
+		hash := sha256.Sum256([]byte{byte(i)})
+		metadata = append(metadata, hash[:]...)
+	}
+	peerAID := p2p.NodeID("aa")
+	peerBID := p2p.NodeID("bb")
+	s := &snapshot{Height: 1, Format: 1, Chunks: 4, Hash: []byte{1, 2, 3}, Metadata: metadata}
+
+	// Half of the chunks were fetched from peer a before a restart, but chunk 1 was corrupted on
+	// disk since, so only it and the missing ones are fetched again.
+	queue, err := newPersistentChunkQueue(s, rts.syncer.chunkDir)
+	require.NoError(t, err)
+	_, err = queue.Add(&chunk{Height: 1, Format: 1, Index: 0, Chunk: []byte{0}, Sender: peerAID})
+	require.NoError(t, err)
+	_, err = queue.Add(&chunk{Height: 1, Format: 1, Index: 1, Chunk: []byte{9}, Sender: peerAID})
+	require.NoError(t, err)
+	require.NoError(t, queue.Close())
+
+	_, err = rts.syncer.AddSnapshot(peerBID, s)
+	require.NoError(t, err)
+
+	var (
+		requested    []uint32
+		requestedMtx tmsync.Mutex
+	)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case e := <-rts.chunkOutCh:
+				msg, ok := e.Message.(*ssproto.ChunkRequest)
+				assert.True(t, ok)
+				requestedMtx.Lock()
+				requested = append(requested, msg.Index)
+				requestedMtx.Unlock()
+				_, _ = rts.syncer.AddChunk(&chunk{
+					Height: msg.Height,
+					Format: msg.Format,
+					Index:  msg.Index,
+					Chunk:  []byte{byte(msg.Index)},
+					Sender: e.To,
+				})
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	// The resumed chunk keeps its sender, so the app can still reject it.
+	rts.conn.On("OfferSnapshotSync", ctx, abci.RequestOfferSnapshot{
+		Snapshot: toABCI(s), AppHash: []byte("app_hash"),
+	}).Once().Return(&abci.ResponseOfferSnapshot{Result: abci.ResponseOfferSnapshot_ACCEPT}, nil)
+	rts.conn.On("ApplySnapshotChunkSync", ctx, abci.RequestApplySnapshotChunk{
+		Index: 0, Chunk: []byte{0}, Sender: string(peerAID),
+	}).Once().Return(&abci.ResponseApplySnapshotChunk{Result: abci.ResponseApplySnapshotChunk_ACCEPT}, nil)
+	for i := uint32(1); i < s.Chunks; i++ {
+		rts.conn.On("ApplySnapshotChunkSync", ctx, abci.RequestApplySnapshotChunk{
+			Index: i, Chunk: []byte{byte(i)}, Sender: string(peerBID),
+		}).Once().Return(&abci.ResponseApplySnapshotChunk{Result: abci.ResponseApplySnapshotChunk_ACCEPT}, nil)
+	}
+	rts.connQuery.On("InfoSync", ctx, proxy.RequestInfo).Return(&abci.ResponseInfo{
+		LastBlockHeight:  1,
+		LastBlockAppHash: []byte("app_hash"),
+	}, nil)
+
+	_, _, err = rts.syncer.SyncAny(ctx, 0, func() {})
+	require.NoError(t, err)
+	rts.conn.AssertExpectations(t)
+
+	requestedMtx.Lock()
+	require.ElementsMatch(t, []uint32{1, 2, 3}, requested)
+	requestedMtx.Unlock()
+}
+
+func TestSyncer_SyncAny_chunkFetchers(t *testing.T) {
+	const latency = 50 * time.Millisecond
+
+	elapsed := make(map[int32]time.Duration)
+	for _, fetchers := range []int32{1, 2, 4} {
+		maxOutstanding, d := syncWithChunkLatency(t, fetchers, 8, latency)
+		require.EqualValues(t, fetchers, maxOutstanding, "fetchers %d", fetchers)
+		elapsed[fetchers] = d
+	}
+
+	// with chunks taking as long to fetch, the restore takes roughly as many
+	// round trips as there are chunks per fetcher
+	require.Less(t, int64(elapsed[2]), int64(elapsed[1]*3/4))
+	require.Less(t, int64(elapsed[4]), int64(elapsed[2]*3/4))
+}
+
+func BenchmarkSyncer_chunkFetchers(b *testing.B) {
+	for _, fetchers := range []int32{1, 4, 16} {
+		fetchers := fetchers
+		b.Run(fmt.Sprintf("fetchers=%d", fetchers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				syncWithChunkLatency(b, fetchers, 32, 10*time.Millisecond)
+			}
+		})
+	}
+}
+
+// syncWithChunkLatency restores a snapshot of the given number of chunks from
+// two peers, with the given number of chunk fetchers, where each chunk arrives
+// after the given latency. It returns the maximum number of chunk requests
+// that were outstanding at once, and how long the restore took.
+func syncWithChunkLatency(t testing.TB, fetchers, chunks int32, latency time.Duration) (int, time.Duration) {
+	state := sm.State{ChainID: "chain", AppHash: []byte("app_hash")}
+	commit := &types.Commit{BlockID: types.BlockID{Hash: []byte("blockhash")}}
+
+	stateProvider := &mocks.StateProvider{}
+	stateProvider.On("AppHash", mock.Anything, uint64(1)).Return(state.AppHash, nil)
+	stateProvider.On("State", mock.Anything, uint64(1)).Return(state, nil)
+	stateProvider.On("Commit", mock.Anything, uint64(1)).Return(commit, nil)
+
+	rts := setup(t, nil, nil, stateProvider, uint(chunks))
+	rts.syncer.fetchers = fetchers
+	rts.syncer.retryTimeout = time.Minute
+
+	s := &snapshot{Height: 1, Format: 1, Chunks: uint32(chunks), Hash: []byte{1, 2, 3}}
+	for _, peerID := range []p2p.NodeID{"aa", "bb"} {
+		_, err := rts.syncer.AddSnapshot(peerID, s)
+		require.NoError(t, err)
+	}
+
+	var (
+		mtx                         sync.Mutex
+		outstanding, maxOutstanding int
+	)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case e := <-rts.chunkOutCh:
+				msg, ok := e.Message.(*ssproto.ChunkRequest)
+				assert.True(t, ok)
+
+				mtx.Lock()
+				outstanding++
+				if outstanding > maxOutstanding {
+					maxOutstanding = outstanding
+				}
+				mtx.Unlock()
+
+				go func() {
+					time.Sleep(latency)
+					mtx.Lock()
+					outstanding--
+					mtx.Unlock()
+					_, _ = rts.syncer.AddChunk(&chunk{
+						Height: msg.Height,
+						Format: msg.Format,
+						Index:  msg.Index,
+						Chunk:  []byte{byte(msg.Index)},
+						Sender: e.To,
+					})
+				}()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	rts.conn.On("OfferSnapshotSync", ctx, abci.RequestOfferSnapshot{
+		Snapshot: toABCI(s), AppHash: []byte("app_hash"),
+	}).Once().Return(&abci.ResponseOfferSnapshot{Result: abci.ResponseOfferSnapshot_ACCEPT}, nil)
+	rts.conn.On("ApplySnapshotChunkSync", ctx, mock.Anything).Times(int(s.Chunks)).Return(
+		&abci.ResponseApplySnapshotChunk{Result: abci.ResponseApplySnapshotChunk_ACCEPT}, nil)
+	rts.connQuery.On("InfoSync", ctx, proxy.RequestInfo).Return(&abci.ResponseInfo{
+		LastBlockHeight:  1,
+		LastBlockAppHash: []byte("app_hash"),
+	}, nil)
+
+	start := time.Now()
+	_, _, err := rts.syncer.SyncAny(ctx, 0, func() {})
+	require.NoError(t, err)
+	elapsed := time.Since(start)
+	rts.conn.AssertExpectations(t)
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	return maxOutstanding, elapsed
+}
+
 func TestSyncer_offerSnapshot(t *testing.T) {
 	unknownErr := errors.New("unknown error")
 	boom := errors.New("boom")
@@ -631,6 +1007,9 @@ func TestSyncer_applyChunks_RejectSenders(t *testing.T) {
 
 			time.Sleep(50 * time.Millisecond)
 
+			require.EqualValues(t, 1, rts.syncer.ProviderStats()[peerBID].Rejected)
+			require.True(t, rts.syncer.ProviderStats()[peerBID].Dropped)
+
 			s1peers := rts.syncer.snapshots.GetPeers(s1)
 			require.Len(t, s1peers, 2)
 			require.EqualValues(t, "aa", s1peers[0])
@@ -0,0 +1,51 @@
+package pubsub
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+var predicateQueryCount uint64
+
+// PredicateQuery is a Query which further filters the messages matching the
+// query it wraps with a Go function, evaluated by the server for each of them.
+// It lets embedders filter messages on more than their events, which the query
+// language doesn't allow.
+//
+// Matches only evaluates the underlying query, the server calls MatchesMessage
+// instead. Each PredicateQuery is distinct from any other query, including other
+// PredicateQueries with the same underlying query, so subscriptions with a
+// predicate are never shared between clients.
+type PredicateQuery struct {
+	Query
+
+	id        uint64
+	predicate func(msg interface{}, events map[string][]string) bool
+}
+
+// NewPredicateQuery returns a query matching the messages which match q and
+// for which predicate returns true. The predicate is called on the server's
+// goroutine, so it must be fast and must not block.
+func NewPredicateQuery(q Query, predicate func(msg interface{}, events map[string][]string) bool) *PredicateQuery {
+	return &PredicateQuery{
+		Query:     q,
+		id:        atomic.AddUint64(&predicateQueryCount, 1),
+		predicate: predicate,
+	}
+}
+
+// MatchesMessage returns true if the events match the underlying query and the
+// predicate returns true for the message.
+func (q *PredicateQuery) MatchesMessage(msg interface{}, events map[string][]string) (bool, error) {
+	match, err := q.Query.Matches(events)
+	if err != nil || !match {
+		return false, err
+	}
+	return q.predicate(msg, events), nil
+}
+
+// String returns the underlying query along with an identifier for the
+// predicate.
+func (q *PredicateQuery) String() string {
+	return fmt.Sprintf("%s AND predicate(%d)", q.Query.String(), q.id)
+}
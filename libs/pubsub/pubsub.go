@@ -485,7 +485,13 @@ func (state *state) send(msg interface{}, events map[string][]string) error {
 			continue
 		}
 
-		match, err := q.Matches(events)
+		var match bool
+		var err error
+		if pq, ok := q.(*PredicateQuery); ok {
+			match, err = pq.MatchesMessage(msg, events)
+		} else {
+			match, err = q.Matches(events)
+		}
 		if err != nil {
 			return fmt.Errorf("failed to match against query %s: %w", q.String(), err)
 		}
@@ -193,6 +193,44 @@ func TestDifferentClients(t *testing.T) {
 	assert.Zero(t, len(subscription3.Out()))
 }
 
+func TestSubscribePredicate(t *testing.T) {
+	s := pubsub.NewServer()
+	s.SetLogger(log.TestingLogger())
+	err := s.Start()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err := s.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	ctx := context.Background()
+	isLong := func(msg interface{}, _ map[string][]string) bool {
+		return len(msg.(string)) > 6
+	}
+	q := query.MustParse("tm.events.type='NewBlock'")
+	subscription, err := s.Subscribe(ctx, clientID, pubsub.NewPredicateQuery(q, isLong), 2)
+	require.NoError(t, err)
+
+	// the same query with another predicate is a different subscription
+	other, err := s.Subscribe(ctx, clientID, pubsub.NewPredicateQuery(q, isLong))
+	require.NoError(t, err)
+	assert.Equal(t, 2, s.NumClientSubscriptions(clientID))
+
+	events := map[string][]string{"tm.events.type": {"NewBlock"}}
+	for _, msg := range []string{"Iceman", "Kitty Pryde", "Ultimo", "Jean Grey"} {
+		err = s.PublishWithEvents(ctx, msg, events)
+		require.NoError(t, err)
+	}
+	err = s.PublishWithEvents(ctx, "Mister Sinister", map[string][]string{"tm.events.type": {"NewRoundStep"}})
+	require.NoError(t, err)
+
+	assertReceive(t, "Kitty Pryde", subscription.Out())
+	assertReceive(t, "Jean Grey", subscription.Out())
+	assert.Zero(t, len(subscription.Out()))
+	assertReceive(t, "Kitty Pryde", other.Out())
+}
+
 func TestSubscribeDuplicateKeys(t *testing.T) {
 	ctx := context.Background()
 	s := pubsub.NewServer()
@@ -121,6 +121,25 @@ func MaxBlockLag(d time.Duration) Option {
 	}
 }
 
+// MaxBackwardsDepth bounds the number of passes backwards verification may
+// make. A new pass is started every time the primary sends an invalid header
+// and is replaced by a witness. Default: 0 (unlimited).
+func MaxBackwardsDepth(d uint16) Option {
+	return func(c *Client) {
+		c.maxBackwardsDepth = d
+	}
+}
+
+// MaxBackwardsFetches bounds the total number of headers that a single
+// backwards verification may fetch from primaries. If the target height is
+// further away from the first trusted header than the budget allows, the
+// verification fails before fetching anything. Default: 0 (unlimited).
+func MaxBackwardsFetches(n uint32) Option {
+	return func(c *Client) {
+		c.maxBackwardsFetches = n
+	}
+}
+
 // Client represents a light client, connected to a single chain, which gets
 // light blocks from a primary provider, verifies them either sequentially or by
 // skipping some and stores them in a trusted store (usually, a local FS).
@@ -134,6 +153,10 @@ type Client struct {
 	maxClockDrift    time.Duration
 	maxBlockLag      time.Duration
 
+	// See MaxBackwardsDepth and MaxBackwardsFetches options
+	maxBackwardsDepth   uint16
+	maxBackwardsFetches uint32
+
 	// Mutex for locking during changes of the light clients providers
 	providerMutex tmsync.Mutex
 	// Primary provider of new headers.
@@ -842,18 +865,44 @@ func (c *Client) updateTrustedLightBlock(l *types.LightBlock) error {
 // backwards verification (see VerifyHeaderBackwards func in the spec) verifies
 // headers before a trusted header. If a sent header is invalid the primary is
 // replaced with another provider and the operation is repeated.
+//
+// The amount of work is bounded by the MaxBackwardsDepth and
+// MaxBackwardsFetches options. ErrBackwardsLimitExceeded is returned if
+// either of them is exceeded.
 func (c *Client) backwards(
 	ctx context.Context,
 	trustedHeader *types.Header,
 	newHeader *types.Header) error {
 
+	var fetches uint32
+	return c.backwardsWithBudget(ctx, trustedHeader, newHeader, 1, &fetches)
+}
+
+func (c *Client) backwardsWithBudget(
+	ctx context.Context,
+	trustedHeader *types.Header,
+	newHeader *types.Header,
+	depth uint16,
+	fetches *uint32) error {
+
 	var (
 		verifiedHeader = trustedHeader
 		interimHeader  *types.Header
 	)
 
+	if c.maxBackwardsDepth > 0 && depth > c.maxBackwardsDepth {
+		return ErrBackwardsLimitExceeded{Height: verifiedHeader.Height, Depth: depth, Fetches: *fetches}
+	}
+
+	// fail fast if we can't possibly reach the new header within the budget
+	if c.maxBackwardsFetches > 0 &&
+		uint64(*fetches)+uint64(verifiedHeader.Height-newHeader.Height) > uint64(c.maxBackwardsFetches) {
+		return ErrBackwardsLimitExceeded{Height: verifiedHeader.Height, Depth: depth, Fetches: *fetches}
+	}
+
 	for verifiedHeader.Height > newHeader.Height {
 		interimBlock, err := c.lightBlockFromPrimary(ctx, verifiedHeader.Height-1)
+		*fetches++
 		if err != nil {
 			return fmt.Errorf("failed to obtain the header at height #%d: %w", verifiedHeader.Height-1, err)
 		}
@@ -882,7 +931,7 @@ func (c *Client) backwards(
 			}
 
 			// try again with the new primary
-			return c.backwards(ctx, verifiedHeader, newPrimarysBlock.Header)
+			return c.backwardsWithBudget(ctx, verifiedHeader, newPrimarysBlock.Header, depth+1, fetches)
 		}
 		verifiedHeader = interimHeader
 	}
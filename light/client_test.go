@@ -717,6 +717,93 @@ func TestClient_BackwardsVerification(t *testing.T) {
 	}
 }
 
+func TestClient_BackwardsVerificationLimits(t *testing.T) {
+	t.Run("fetch budget", func(t *testing.T) {
+		trustHeader, _ := largeFullNode.LightBlock(ctx, 6)
+		c, err := light.NewClient(
+			ctx,
+			chainID,
+			light.TrustOptions{
+				Period: 4 * time.Minute,
+				Height: trustHeader.Height,
+				Hash:   trustHeader.Hash(),
+			},
+			largeFullNode,
+			[]provider.Provider{largeFullNode},
+			dbs.New(dbm.NewMemDB()),
+			light.Logger(log.TestingLogger()),
+			light.MaxBackwardsFetches(2),
+		)
+		require.NoError(t, err)
+
+		// 6 -> 3 requires 3 headers to be fetched => expect the capped error
+		_, err = c.VerifyLightBlockAtHeight(ctx, 3, bTime.Add(8*time.Minute))
+		require.Error(t, err)
+		assert.IsType(t, light.ErrBackwardsLimitExceeded{}, err)
+
+		// 6 -> 4 is within the budget => expect no error
+		h, err := c.VerifyLightBlockAtHeight(ctx, 4, bTime.Add(8*time.Minute))
+		require.NoError(t, err)
+		assert.EqualValues(t, 4, h.Height)
+	})
+
+	t.Run("depth", func(t *testing.T) {
+		// the primary provides an incorrect interim header at height 2, forcing
+		// the light client to replace it and start another pass
+		newBadPrimary := func() provider.Provider {
+			return mockp.New(
+				chainID,
+				map[int64]*types.SignedHeader{
+					1: h1,
+					2: keys.GenSignedHeader(chainID, 2, bTime.Add(30*time.Minute), nil, vals, vals,
+						hash("app_hash2"), hash("cons_hash23"), hash("results_hash30"), 0, len(keys)),
+					3: h3,
+				},
+				valSet,
+			)
+		}
+
+		testCases := []struct {
+			name   string
+			depth  uint16
+			expErr bool
+		}{
+			{"no replacement allowed", 1, true},
+			{"one replacement allowed", 2, false},
+			{"unlimited", 0, false},
+		}
+
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.name, func(t *testing.T) {
+				c, err := light.NewClient(
+					ctx,
+					chainID,
+					light.TrustOptions{
+						Period: 1 * time.Hour,
+						Height: 3,
+						Hash:   h3.Hash(),
+					},
+					newBadPrimary(),
+					[]provider.Provider{fullNode, fullNode},
+					dbs.New(dbm.NewMemDB()),
+					light.Logger(log.TestingLogger()),
+					light.MaxBackwardsDepth(tc.depth),
+				)
+				require.NoError(t, err)
+
+				_, err = c.VerifyLightBlockAtHeight(ctx, 1, bTime.Add(1*time.Hour).Add(1*time.Second))
+				if tc.expErr {
+					require.Error(t, err)
+					assert.IsType(t, light.ErrBackwardsLimitExceeded{}, err)
+				} else {
+					assert.NoError(t, err)
+				}
+			})
+		}
+	})
+}
+
 func TestClient_NewClientFromTrustedStore(t *testing.T) {
 	// 1) Initiate DB and fill with a "trusted" header
 	db := dbs.New(dbm.NewMemDB())
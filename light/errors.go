@@ -72,6 +72,20 @@ var ErrLightClientAttack = errors.New(`attempted attack detected.
 	Check logs for full evidence and trace`,
 )
 
+// ErrBackwardsLimitExceeded means backwards verification was aborted because
+// it would have exceeded either the maximum depth or the header fetch budget
+// (see MaxBackwardsDepth and MaxBackwardsFetches).
+type ErrBackwardsLimitExceeded struct {
+	Height  int64
+	Depth   uint16
+	Fetches uint32
+}
+
+func (e ErrBackwardsLimitExceeded) Error() string {
+	return fmt.Sprintf("backwards verification limit exceeded at height #%d (depth: %d, fetches: %d)",
+		e.Height, e.Depth, e.Fetches)
+}
+
 // ErrNoWitnesses means that there are not enough witnesses connected to
 // continue running the light client.
 var ErrNoWitnesses = errors.New("no witnesses connected. please reset light client")
@@ -171,7 +171,7 @@ func (p *http) validatorSet(ctx context.Context, height *int64) (*types.Validato
 		// is negative we will keep repeating.
 		attempt := uint16(0)
 		for {
-			res, err := p.client.Validators(ctx, height, &page, &perPage)
+			res, err := p.client.Validators(ctx, height, &page, &perPage, false)
 			switch e := err.(type) {
 			case nil: // success!! Now we validate the response
 				if len(res.Validators) == 0 {
@@ -282,7 +282,8 @@ func (p *http) noBlock(e error) error {
 func (p *http) parseRPCError(e *rpctypes.RPCError) error {
 	switch {
 	// 1) check if the error indicates that the peer doesn't have the block
-	case strings.Contains(e.Data, ctypes.ErrHeightNotAvailable.Error()):
+	case strings.Contains(e.Data, ctypes.ErrHeightNotAvailable.Error()),
+		strings.Contains(e.Data, ctypes.ErrValidatorSetPruned.Error()):
 		return p.noBlock(provider.ErrLightBlockNotFound)
 
 	// 2) check if the height requested is too high
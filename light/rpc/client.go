@@ -494,11 +494,13 @@ func (c *Client) BlockSearch(
 	return c.next.BlockSearch(ctx, query, page, perPage, orderBy)
 }
 
-// Validators fetches and verifies validators.
+// Validators fetches and verifies validators. If prove is true, the result
+// includes a proof that the validator set is the one of the verified header.
 func (c *Client) Validators(
 	ctx context.Context,
 	height *int64,
 	pagePtr, perPagePtr *int,
+	prove bool,
 ) (*ctypes.ResultValidators, error) {
 
 	// Update the light client if we're behind and retrieve the light block at the
@@ -515,6 +517,15 @@ func (c *Client) Validators(
 		return nil, err
 	}
 
+	var proof *types.ValidatorSetProof
+	if prove {
+		p, err := l.Header.ValidatorSetProof()
+		if err != nil {
+			return nil, err
+		}
+		proof = &p
+	}
+
 	skipCount := validateSkipCount(page, perPage)
 	v := l.ValidatorSet.Validators[skipCount : skipCount+tmmath.MinInt(perPage, totalCount-skipCount)]
 
@@ -522,7 +533,8 @@ func (c *Client) Validators(
 		BlockHeight: l.Height,
 		Validators:  v,
 		Count:       len(v),
-		Total:       totalCount}, nil
+		Total:       totalCount,
+		Proof:       proof}, nil
 }
 
 func (c *Client) BroadcastEvidence(ctx context.Context, ev types.Evidence) (*ctypes.ResultBroadcastEvidence, error) {
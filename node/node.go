@@ -21,6 +21,7 @@ import (
 	"github.com/tendermint/tendermint/internal/evidence"
 	"github.com/tendermint/tendermint/internal/mempool"
 	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/internal/p2p/keepalive"
 	"github.com/tendermint/tendermint/internal/p2p/pex"
 	"github.com/tendermint/tendermint/internal/statesync"
 	"github.com/tendermint/tendermint/libs/log"
@@ -72,10 +73,13 @@ type nodeImpl struct {
 	stateSync         bool                    // whether the node should state sync on startup
 	stateSyncReactor  *statesync.Reactor      // for hosting and restoring state sync snapshots
 	stateSyncProvider statesync.StateProvider // provides state data for bootstrapping a node
+	cancelStateSync   context.CancelFunc      // cancels the state sync and backfill in progress
+	stateSyncDone     <-chan struct{}         // closed once the state sync and backfill are done
 	consensusState    *cs.State               // latest consensus state
 	consensusReactor  *cs.Reactor             // for participating in the consensus
 	pexReactor        *pex.Reactor            // for exchanging peer addresses
 	pexReactorV2      *pex.ReactorV2          // for exchanging peer addresses
+	keepaliveReactor  *keepalive.Reactor      // for detecting dead peers
 	evidenceReactor   *evidence.Reactor
 	evidencePool      *evidence.Pool // tracking evidence
 	proxyApp          proxy.AppConns // connection to the application
@@ -213,7 +217,9 @@ func makeNode(config *cfg.Config,
 	// and replays any blocks as necessary to sync tendermint with the app.
 	consensusLogger := logger.With("module", "consensus")
 	if !stateSync {
-		if err := doHandshake(stateStore, state, blockStore, genDoc, eventBus, proxyApp, consensusLogger); err != nil {
+		if err := doHandshake(stateStore, state, blockStore, genDoc, eventBus, proxyApp,
+			config.Consensus.MaxRewindDepth, config.Consensus.ReplaySkipSignatureVerification,
+			consensusLogger); err != nil {
 			return nil, err
 		}
 
@@ -247,7 +253,7 @@ func makeNode(config *cfg.Config,
 		return nil, fmt.Errorf("failed to create peer manager: %w", err)
 	}
 
-	csMetrics, p2pMetrics, memplMetrics, smMetrics := defaultMetricsProvider(config.Instrumentation)(genDoc.ChainID)
+	csMetrics, p2pMetrics, memplMetrics, smMetrics, ssMetrics := defaultMetricsProvider(config.Instrumentation)(genDoc.ChainID)
 
 	router, err := createRouter(p2pLogger, p2pMetrics, nodeInfo, nodeKey.PrivKey,
 		peerManager, transport, getRouterConfig(config, proxyApp))
@@ -346,6 +352,8 @@ func makeNode(config *cfg.Config,
 		stateStore,
 		blockStore,
 		config.StateSync.TempDir,
+		ssMetrics,
+		statesync.WithEvidencePool(evPool),
 	)
 
 	// add the channel descriptors to both the transports
@@ -372,10 +380,11 @@ func makeNode(config *cfg.Config,
 	// Note we currently use the addrBook regardless at least for AddOurAddress
 
 	var (
-		pexReactor   *pex.Reactor
-		pexReactorV2 *pex.ReactorV2
-		sw           *p2p.Switch
-		addrBook     pex.AddrBook
+		pexReactor       *pex.Reactor
+		pexReactorV2     *pex.ReactorV2
+		keepaliveReactor *keepalive.Reactor
+		sw               *p2p.Switch
+		addrBook         pex.AddrBook
 	)
 
 	pexCh := pex.ChannelDescriptor()
@@ -387,6 +396,15 @@ func makeNode(config *cfg.Config,
 		if err != nil {
 			return nil, err
 		}
+
+		if config.P2P.PingInterval > 0 {
+			keepaliveCh := keepalive.ChannelDescriptor()
+			transport.AddChannelDescriptors([]*p2p.ChannelDescriptor{&keepaliveCh})
+			keepaliveReactor, err = createKeepaliveReactor(config, logger, peerManager, router)
+			if err != nil {
+				return nil, err
+			}
+		}
 	} else {
 		// setup Transport and Switch
 		sw = createSwitch(
@@ -443,6 +461,7 @@ func makeNode(config *cfg.Config,
 		stateSync:        stateSync,
 		pexReactor:       pexReactor,
 		pexReactorV2:     pexReactorV2,
+		keepaliveReactor: keepaliveReactor,
 		evidenceReactor:  evReactor,
 		evidencePool:     evPool,
 		proxyApp:         proxyApp,
@@ -632,6 +651,13 @@ func (n *nodeImpl) OnStart() error {
 			return err
 		}
 
+		// Restore the mempool from a previous run, if enabled.
+		if path := n.config.Mempool.SnapshotFilePath(); path != "" {
+			if err := mempool.LoadSnapshot(context.Background(), n.Logger, path, n.mempool); err != nil {
+				n.Logger.Error("failed to restore mempool snapshot", "err", err)
+			}
+		}
+
 		// Start the real mempool reactor separately since the switch uses the shim.
 		if err := n.mempoolReactor.Start(); err != nil {
 			return err
@@ -643,6 +669,12 @@ func (n *nodeImpl) OnStart() error {
 		}
 	}
 
+	if n.keepaliveReactor != nil {
+		if err := n.keepaliveReactor.Start(); err != nil {
+			return err
+		}
+	}
+
 	if n.config.P2P.DisableLegacy && n.pexReactorV2 != nil {
 		if err := n.pexReactorV2.Start(); err != nil {
 			return err
@@ -669,11 +701,15 @@ func (n *nodeImpl) OnStart() error {
 			return fmt.Errorf("unable to derive state: %w", err)
 		}
 
-		err = startStateSync(n.stateSyncReactor, bcR, n.consensusReactor, n.stateSyncProvider,
+		ctx, cancel := context.WithCancel(context.Background())
+		done, err := startStateSync(ctx, n.stateSyncReactor, bcR, n.consensusReactor, n.stateSyncProvider,
 			n.config.StateSync, n.config.FastSyncMode, n.stateStore, n.blockStore, state)
 		if err != nil {
+			cancel()
 			return fmt.Errorf("failed to start state sync: %w", err)
 		}
+		n.cancelStateSync = cancel
+		n.stateSyncDone = done
 	}
 
 	return nil
@@ -692,6 +728,13 @@ func (n *nodeImpl) OnStop() {
 		n.Logger.Error("Error closing indexerService", "err", err)
 	}
 
+	// cancel the state sync and backfill in progress, if any, and wait for
+	// them to return before stopping the reactors they use
+	if n.cancelStateSync != nil {
+		n.cancelStateSync()
+		<-n.stateSyncDone
+	}
+
 	if n.config.Mode != cfg.ModeSeed {
 		// now stop the reactors
 		if n.config.FastSync.Version == cfg.BlockchainV0 {
@@ -716,6 +759,13 @@ func (n *nodeImpl) OnStop() {
 			n.Logger.Error("failed to stop the mempool reactor", "err", err)
 		}
 
+		// Persist the mempool so it can be restored on the next start, if enabled.
+		if path := n.config.Mempool.SnapshotFilePath(); path != "" {
+			if err := mempool.SaveSnapshot(path, n.mempool); err != nil {
+				n.Logger.Error("failed to save mempool snapshot", "err", err)
+			}
+		}
+
 		// Stop the real evidence reactor separately since the switch uses the shim.
 		if err := n.evidenceReactor.Stop(); err != nil {
 			n.Logger.Error("failed to stop the evidence reactor", "err", err)
@@ -728,6 +778,12 @@ func (n *nodeImpl) OnStop() {
 		}
 	}
 
+	if n.keepaliveReactor != nil {
+		if err := n.keepaliveReactor.Stop(); err != nil {
+			n.Logger.Error("failed to stop the keepalive reactor", "err", err)
+		}
+	}
+
 	if n.config.P2P.DisableLegacy {
 		if err := n.router.Stop(); err != nil {
 			n.Logger.Error("failed to stop router", "err", err)
@@ -778,6 +834,7 @@ func (n *nodeImpl) ConfigureRPC() (*rpccore.Environment, error) {
 		ConsensusState: n.consensusState,
 		P2PPeers:       n.sw,
 		P2PTransport:   n,
+		StateSync:      n.stateSyncReactor,
 
 		GenDoc:           n.genesisDoc,
 		EventSinks:       n.eventSinks,
@@ -796,6 +853,9 @@ func (n *nodeImpl) ConfigureRPC() (*rpccore.Environment, error) {
 		}
 		rpcCoreEnv.PubKey = pubKey
 	}
+	if n.config.P2P.DisableLegacy {
+		rpcCoreEnv.PeerManager = n.peerManager
+	}
 	if err := rpcCoreEnv.InitGenesisChunks(); err != nil {
 		return nil, err
 	}
@@ -1032,14 +1092,16 @@ func (n *nodeImpl) NodeInfo() p2p.NodeInfo {
 }
 
 // startStateSync starts an asynchronous state sync process, then switches to fast sync mode.
-func startStateSync(ssR *statesync.Reactor, bcR fastSyncReactor, conR *cs.Reactor,
+// The returned channel is closed once the state sync and the backfill, even one
+// in the background, are done or were interrupted by canceling ctx.
+func startStateSync(ctx context.Context, ssR *statesync.Reactor, bcR fastSyncReactor, conR *cs.Reactor,
 	stateProvider statesync.StateProvider, config *cfg.StateSyncConfig, fastSync bool,
-	stateStore sm.Store, blockStore *store.BlockStore, state sm.State) error {
+	stateStore sm.Store, blockStore *store.BlockStore, state sm.State) (<-chan struct{}, error) {
 	ssR.Logger.Info("starting state sync...")
 
 	if stateProvider == nil {
 		var err error
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 		defer cancel()
 		stateProvider, err = statesync.NewLightClientStateProvider(
 			ctx,
@@ -1050,37 +1112,115 @@ func startStateSync(ssR *statesync.Reactor, bcR fastSyncReactor, conR *cs.Reacto
 				Hash:   config.TrustHashBytes(),
 			}, ssR.Logger.With("module", "light"))
 		if err != nil {
-			return fmt.Errorf("failed to set up light client state provider: %w", err)
+			return nil, fmt.Errorf("failed to set up light client state provider: %w", err)
 		}
 	}
 
+	done := make(chan struct{})
+	if config.DryRun {
+		go func() {
+			defer close(done)
+			logStateSyncDryRun(ctx, ssR, stateProvider, config.DiscoveryTime)
+		}()
+		return done, nil
+	}
+
 	go func() {
-		state, err := ssR.Sync(context.TODO(), stateProvider, config.DiscoveryTime)
+		defer close(done)
+		state, err := ssR.Sync(ctx, stateProvider, config.DiscoveryTime)
 		if err != nil {
 			ssR.Logger.Error("state sync failed", "err", err)
 			return
 		}
 
-		err = ssR.Backfill(state)
-		if err != nil {
-			ssR.Logger.Error("backfill failed; node has insufficient history to verify all evidence;"+
-				" proceeding optimistically...", "err", err)
-		}
-
-		conR.Metrics.StateSyncing.Set(0)
-		if fastSync {
-			// FIXME Very ugly to have these metrics bleed through here.
-			conR.Metrics.FastSyncing.Set(1)
-			err = bcR.SwitchToFastSync(state)
-			if err != nil {
-				ssR.Logger.Error("failed to switch to fast sync", "err", err)
-				return
+		<-backfillAndGoLive(ctx, ssR.Logger, ssR, state, config.BackgroundBackfill, func() {
+			conR.Metrics.StateSyncing.Set(0)
+			if fastSync {
+				// FIXME Very ugly to have these metrics bleed through here.
+				conR.Metrics.FastSyncing.Set(1)
+				if err := bcR.SwitchToFastSync(state); err != nil {
+					ssR.Logger.Error("failed to switch to fast sync", "err", err)
+				}
+			} else {
+				conR.SwitchToConsensus(state, true)
 			}
-		} else {
-			conR.SwitchToConsensus(state, true)
-		}
+		})
 	}()
-	return nil
+	return done, nil
+}
+
+// backfiller backfills the blocks below a state restored by state sync.
+type backfiller interface {
+	Backfill(ctx context.Context, state sm.State) (int64, error)
+}
+
+// backfillAndGoLive backfills the blocks below the restored state, then calls
+// goLive for the node to switch to fast sync or consensus. If background is
+// true, goLive is called right away and the backfill runs concurrently. The
+// returned channel is closed once the backfill is done. Canceling ctx
+// interrupts the backfill, in which case the node doesn't go live if it was
+// waiting for the backfill, as it is stopping.
+func backfillAndGoLive(
+	ctx context.Context,
+	logger log.Logger,
+	bf backfiller,
+	state sm.State,
+	background bool,
+	goLive func(),
+) <-chan struct{} {
+	done := make(chan struct{})
+	if background {
+		logger.Info("going live before backfilling; backfilling in the background...",
+			"height", state.LastBlockHeight)
+		goLive()
+		go func() {
+			defer close(done)
+			backfill(ctx, logger, bf, state)
+		}()
+		return done
+	}
+
+	defer close(done)
+	backfill(ctx, logger, bf, state)
+	if ctx.Err() != nil {
+		return done
+	}
+	goLive()
+	return done
+}
+
+// backfill runs the backfill below the restored state and logs its outcome.
+// A failed backfill leaves the node with too little history to verify all
+// evidence, but isn't fatal.
+func backfill(ctx context.Context, logger log.Logger, bf backfiller, state sm.State) {
+	base, err := bf.Backfill(ctx, state)
+	if err != nil {
+		logger.Error("backfill failed; node has insufficient history to verify all evidence;"+
+			" proceeding optimistically...", "err", err)
+		return
+	}
+	logger.Info("backfill complete", "base", base)
+}
+
+// logStateSyncDryRun runs a state sync dry run and logs its report. The node
+// is left waiting for a state sync that never starts.
+func logStateSyncDryRun(ctx context.Context, ssR *statesync.Reactor, stateProvider statesync.StateProvider,
+	discoveryTime time.Duration) {
+	report, err := ssR.DryRun(ctx, stateProvider, discoveryTime)
+	if err != nil {
+		ssR.Logger.Error("state sync dry run failed", "err", err)
+		return
+	}
+
+	for _, snapshot := range report.Snapshots {
+		ssR.Logger.Info("state sync dry run: discovered snapshot", "height", snapshot.Height,
+			"format", snapshot.Format, "chunks", snapshot.Chunks, "peers", len(snapshot.Peers))
+	}
+	for peerID, peer := range report.Peers {
+		ssR.Logger.Info("state sync dry run: probed peer", "peer", peerID, "snapshots", peer.Snapshots,
+			"light_blocks", peer.LightBlocks, "sampled_light_blocks", len(report.Heights), "err", peer.Err)
+	}
+	ssR.Logger.Info("state sync dry run completed; disable dry-run to sync", "feasible", report.Feasible())
 }
 
 // genesisDocProvider returns a GenesisDoc.
@@ -1096,20 +1236,24 @@ func defaultGenesisDocProviderFunc(config *cfg.Config) genesisDocProvider {
 	}
 }
 
-// metricsProvider returns a consensus, p2p and mempool Metrics.
-type metricsProvider func(chainID string) (*cs.Metrics, *p2p.Metrics, *mempool.Metrics, *sm.Metrics)
+// metricsProvider returns a consensus, p2p, mempool, state and state sync
+// Metrics.
+type metricsProvider func(chainID string) (*cs.Metrics, *p2p.Metrics, *mempool.Metrics, *sm.Metrics,
+	*statesync.Metrics)
 
 // defaultMetricsProvider returns Metrics build using Prometheus client library
 // if Prometheus is enabled. Otherwise, it returns no-op Metrics.
 func defaultMetricsProvider(config *cfg.InstrumentationConfig) metricsProvider {
-	return func(chainID string) (*cs.Metrics, *p2p.Metrics, *mempool.Metrics, *sm.Metrics) {
+	return func(chainID string) (*cs.Metrics, *p2p.Metrics, *mempool.Metrics, *sm.Metrics,
+		*statesync.Metrics) {
 		if config.Prometheus {
 			return cs.PrometheusMetrics(config.Namespace, "chain_id", chainID),
 				p2p.PrometheusMetrics(config.Namespace, "chain_id", chainID),
 				mempool.PrometheusMetrics(config.Namespace, "chain_id", chainID),
-				sm.PrometheusMetrics(config.Namespace, "chain_id", chainID)
+				sm.PrometheusMetrics(config.Namespace, "chain_id", chainID),
+				statesync.PrometheusMetrics(config.Namespace, "chain_id", chainID)
 		}
-		return cs.NopMetrics(), p2p.NopMetrics(), mempool.NopMetrics(), sm.NopMetrics()
+		return cs.NopMetrics(), p2p.NopMetrics(), mempool.NopMetrics(), sm.NopMetrics(), statesync.NopMetrics()
 	}
 }
 
@@ -1197,7 +1341,20 @@ func createAndStartPrivValidatorGRPCClient(
 
 func getRouterConfig(conf *cfg.Config, proxyApp proxy.AppConns) p2p.RouterOptions {
 	opts := p2p.RouterOptions{
-		QueueType: conf.P2P.QueueType,
+		QueueType:             conf.P2P.QueueType,
+		DialTimeout:           conf.P2P.DialTimeout,
+		PersistentDialTimeout: conf.P2P.PersistentPeersDialTimeout,
+		BootstrapDialTimeout:  conf.P2P.BootstrapPeersDialTimeout,
+		MaxClockOffset:        conf.P2P.MaxClockOffset,
+		MaxIncomingHandshakes: conf.P2P.MaxIncomingHandshakes,
+		IncomingHandshakeWait: conf.P2P.IncomingHandshakeWait,
+	}
+
+	// invalid addresses are rejected when creating the peer manager
+	for _, p := range strings.SplitAndTrimEmpty(conf.P2P.BootstrapPeers, ",", " ") {
+		if address, err := p2p.ParseNodeAddress(p); err == nil {
+			opts.BootstrapPeers = append(opts.BootstrapPeers, address.NodeID)
+		}
 	}
 
 	if conf.P2P.MaxNumInboundPeers > 0 {
@@ -644,3 +644,91 @@ func TestLoadStateFromGenesis(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, state)
 }
+
+// blockingBackfiller is a backfiller that returns base once released, or an
+// error once its context is canceled.
+type blockingBackfiller struct {
+	release chan struct{}
+	base    int64
+}
+
+func (bf blockingBackfiller) Backfill(ctx context.Context, _ sm.State) (int64, error) {
+	select {
+	case <-bf.release:
+		return bf.base, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+func TestBackfillAndGoLive(t *testing.T) {
+	state := sm.State{LastBlockHeight: 100}
+	goLive := func(live chan<- struct{}) func() {
+		return func() { live <- struct{}{} }
+	}
+
+	t.Run("foreground", func(t *testing.T) {
+		bf := blockingBackfiller{release: make(chan struct{}), base: 50}
+		live := make(chan struct{}, 1)
+		go backfillAndGoLive(context.Background(), log.TestingLogger(), bf, state, false, goLive(live))
+
+		// the node only goes live once the backfill is done
+		select {
+		case <-live:
+			require.Fail(t, "went live before the backfill was done")
+		case <-time.After(100 * time.Millisecond):
+		}
+		close(bf.release)
+		<-live
+	})
+
+	t.Run("background", func(t *testing.T) {
+		bf := blockingBackfiller{release: make(chan struct{}), base: 50}
+		live := make(chan struct{}, 1)
+		backfilled := backfillAndGoLive(context.Background(), log.TestingLogger(), bf, state, true, goLive(live))
+
+		// the node goes live before the backfill is done
+		<-live
+		select {
+		case <-backfilled:
+			require.Fail(t, "backfill done before being released")
+		default:
+		}
+		close(bf.release)
+		<-backfilled
+	})
+
+	t.Run("canceled foreground", func(t *testing.T) {
+		bf := blockingBackfiller{release: make(chan struct{}), base: 50}
+		live := make(chan struct{}, 1)
+		ctx, cancel := context.WithCancel(context.Background())
+		returned := make(chan struct{})
+		go func() {
+			defer close(returned)
+			backfillAndGoLive(ctx, log.TestingLogger(), bf, state, false, goLive(live))
+		}()
+
+		// the node doesn't go live once the backfill is interrupted, as it's
+		// stopping
+		cancel()
+		<-returned
+		select {
+		case <-live:
+			require.Fail(t, "went live after the backfill was canceled")
+		default:
+		}
+	})
+
+	t.Run("canceled background", func(t *testing.T) {
+		bf := blockingBackfiller{release: make(chan struct{}), base: 50}
+		live := make(chan struct{}, 1)
+		ctx, cancel := context.WithCancel(context.Background())
+		backfilled := backfillAndGoLive(ctx, log.TestingLogger(), bf, state, true, goLive(live))
+
+		// the backfill in the background is interrupted, e.g. by the node
+		// stopping
+		<-live
+		cancel()
+		<-backfilled
+	})
+}
@@ -24,6 +24,7 @@ import (
 	mempoolv0 "github.com/tendermint/tendermint/internal/mempool/v0"
 	mempoolv1 "github.com/tendermint/tendermint/internal/mempool/v1"
 	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/internal/p2p/keepalive"
 	"github.com/tendermint/tendermint/internal/p2p/pex"
 	"github.com/tendermint/tendermint/internal/statesync"
 	"github.com/tendermint/tendermint/libs/log"
@@ -140,13 +141,17 @@ func doHandshake(
 	genDoc *types.GenesisDoc,
 	eventBus types.BlockEventPublisher,
 	proxyApp proxy.AppConns,
+	maxRewindDepth int64,
+	skipSigVerification bool,
 	consensusLogger log.Logger) error {
 
 	handshaker := cs.NewHandshaker(stateStore, state, blockStore, genDoc)
 	handshaker.SetLogger(consensusLogger)
 	handshaker.SetEventBus(eventBus)
+	handshaker.SetMaxRewindDepth(maxRewindDepth)
+	handshaker.SetSkipSignatureVerification(skipSigVerification)
 	if err := handshaker.Handshake(proxyApp); err != nil {
-		return fmt.Errorf("error during handshake: %v", err)
+		return fmt.Errorf("error during handshake: %w", err)
 	}
 	return nil
 }
@@ -254,6 +259,7 @@ func createMempoolReactor(
 			mempoolv1.WithMetrics(memplMetrics),
 			mempoolv1.WithPreCheck(sm.TxPreCheck(state)),
 			mempoolv1.WithPostCheck(sm.TxPostCheck(state)),
+			mempoolv1.WithTxComparator(mempoolTxComparator(config.Mempool.TxOrder)),
 		)
 
 		reactor := mempoolv1.NewReactor(
@@ -276,6 +282,15 @@ func createMempoolReactor(
 	}
 }
 
+// mempoolTxComparator returns the v1 mempool's TxComparator for the given
+// tx-order option.
+func mempoolTxComparator(txOrder string) mempoolv1.TxComparator {
+	if txOrder == cfg.MempoolTxOrderGasPrice {
+		return mempoolv1.GasPriceTxComparator
+	}
+	return mempoolv1.DefaultTxComparator
+}
+
 func createEvidenceReactor(
 	config *cfg.Config,
 	dbProvider cfg.DBProvider,
@@ -293,7 +308,13 @@ func createEvidenceReactor(
 	logger = logger.With("module", "evidence")
 	reactorShim := p2p.NewReactorShim(logger, "EvidenceShim", evidence.ChannelShims)
 
-	evidencePool, err := evidence.NewPool(logger, evidenceDB, sm.NewStore(stateDB), blockStore)
+	evidencePool, err := evidence.NewPool(
+		logger,
+		evidenceDB,
+		sm.NewStore(stateDB),
+		blockStore,
+		evidence.WithStrictTimestamps(config.Evidence.StrictTimestamps),
+	)
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("creating evidence pool: %w", err)
 	}
@@ -316,6 +337,7 @@ func createEvidenceReactor(
 		channels[evidence.EvidenceChannel],
 		peerUpdates,
 		evidencePool,
+		evidence.WithBroadcastInterval(config.Evidence.BroadcastInterval),
 	)
 
 	return reactorShim, evidenceReactor, evidencePool, nil
@@ -362,6 +384,9 @@ func createBlockchainReactor(
 			return nil, nil, err
 		}
 
+		// hand off to fast sync whenever consensus falls too far behind
+		csReactor.SetFastSyncer(reactor)
+
 		return reactorShim, reactor, nil
 
 	case cfg.BlockchainV2:
@@ -493,7 +518,10 @@ func createPeerManager(
 		MaxRetryTime:           8 * time.Hour,
 		MaxRetryTimePersistent: 5 * time.Minute,
 		RetryTimeJitter:        3 * time.Second,
+		EvictedRetryTime:       time.Minute,
 		PrivatePeers:           privatePeerIDs,
+		AddressTTL:             config.P2P.AddressTTL,
+		AdvertiseDialedOnly:    config.P2P.AdvertiseDialedOnly,
 	}
 
 	peers := []p2p.NodeAddress{}
@@ -707,6 +735,23 @@ func createPEXReactorV2(
 	return pex.NewReactorV2(logger, peerManager, channel, peerUpdates), nil
 }
 
+func createKeepaliveReactor(
+	config *cfg.Config,
+	logger log.Logger,
+	peerManager *p2p.PeerManager,
+	router *p2p.Router,
+) (*keepalive.Reactor, error) {
+
+	channel, err := router.OpenChannel(keepalive.ChannelDescriptor(), &protop2p.Packet{}, 16)
+	if err != nil {
+		return nil, err
+	}
+
+	peerUpdates := peerManager.Subscribe()
+	return keepalive.NewReactor(logger.With("module", "keepalive"), channel, peerUpdates,
+		config.P2P.PingInterval, config.P2P.PingTimeout, config.P2P.MaxMissedPongs), nil
+}
+
 func makeNodeInfo(
 	config *cfg.Config,
 	nodeKey p2p.NodeKey,
@@ -763,6 +808,9 @@ func makeNodeInfo(
 	if config.P2P.PexReactor {
 		nodeInfo.Channels = append(nodeInfo.Channels, pex.PexChannel)
 	}
+	if config.P2P.DisableLegacy && config.P2P.PingInterval > 0 {
+		nodeInfo.Channels = append(nodeInfo.Channels, byte(keepalive.KeepaliveChannel))
+	}
 
 	lAddr := config.P2P.ExternalAddress
 
@@ -0,0 +1,34 @@
+package p2p
+
+import (
+	fmt "fmt"
+
+	proto "github.com/gogo/protobuf/proto"
+)
+
+// Wrap implements the p2p Wrapper interface and wraps a ping or pong packet,
+// as exchanged on the keepalive channel.
+func (m *Packet) Wrap(pb proto.Message) error {
+	switch msg := pb.(type) {
+	case *PacketPing:
+		m.Sum = &Packet_PacketPing{PacketPing: msg}
+	case *PacketPong:
+		m.Sum = &Packet_PacketPong{PacketPong: msg}
+	default:
+		return fmt.Errorf("unknown keepalive message: %T", msg)
+	}
+	return nil
+}
+
+// Unwrap implements the p2p Wrapper interface and unwraps a wrapped ping or
+// pong packet.
+func (m *Packet) Unwrap() (proto.Message, error) {
+	switch msg := m.Sum.(type) {
+	case *Packet_PacketPing:
+		return msg.PacketPing, nil
+	case *Packet_PacketPong:
+		return msg.PacketPong, nil
+	default:
+		return nil, fmt.Errorf("unknown keepalive message: %T", msg)
+	}
+}
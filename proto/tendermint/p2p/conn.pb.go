@@ -24,6 +24,40 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
 
+type DisconnectReason int32
+
+const (
+	DisconnectReason_DISCONNECT_REASON_UNKNOWN       DisconnectReason = 0
+	DisconnectReason_DISCONNECT_REASON_EVICTED       DisconnectReason = 1
+	DisconnectReason_DISCONNECT_REASON_INCOMPATIBLE  DisconnectReason = 2
+	DisconnectReason_DISCONNECT_REASON_MISBEHAVING   DisconnectReason = 3
+	DisconnectReason_DISCONNECT_REASON_SHUTTING_DOWN DisconnectReason = 4
+)
+
+var DisconnectReason_name = map[int32]string{
+	0: "DISCONNECT_REASON_UNKNOWN",
+	1: "DISCONNECT_REASON_EVICTED",
+	2: "DISCONNECT_REASON_INCOMPATIBLE",
+	3: "DISCONNECT_REASON_MISBEHAVING",
+	4: "DISCONNECT_REASON_SHUTTING_DOWN",
+}
+
+var DisconnectReason_value = map[string]int32{
+	"DISCONNECT_REASON_UNKNOWN":       0,
+	"DISCONNECT_REASON_EVICTED":       1,
+	"DISCONNECT_REASON_INCOMPATIBLE":  2,
+	"DISCONNECT_REASON_MISBEHAVING":   3,
+	"DISCONNECT_REASON_SHUTTING_DOWN": 4,
+}
+
+func (x DisconnectReason) String() string {
+	return proto.EnumName(DisconnectReason_name, int32(x))
+}
+
+func (DisconnectReason) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_22474b5527c8fa9f, []int{0}
+}
+
 type PacketPing struct {
 }
 
@@ -156,11 +190,56 @@ func (m *PacketMsg) GetData() []byte {
 	return nil
 }
 
+type PacketDisconnect struct {
+	Reason DisconnectReason `protobuf:"varint,1,opt,name=reason,proto3,enum=tendermint.p2p.DisconnectReason" json:"reason,omitempty"`
+}
+
+func (m *PacketDisconnect) Reset()         { *m = PacketDisconnect{} }
+func (m *PacketDisconnect) String() string { return proto.CompactTextString(m) }
+func (*PacketDisconnect) ProtoMessage()    {}
+func (*PacketDisconnect) Descriptor() ([]byte, []int) {
+	return fileDescriptor_22474b5527c8fa9f, []int{3}
+}
+func (m *PacketDisconnect) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *PacketDisconnect) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_PacketDisconnect.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *PacketDisconnect) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PacketDisconnect.Merge(m, src)
+}
+func (m *PacketDisconnect) XXX_Size() int {
+	return m.Size()
+}
+func (m *PacketDisconnect) XXX_DiscardUnknown() {
+	xxx_messageInfo_PacketDisconnect.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PacketDisconnect proto.InternalMessageInfo
+
+func (m *PacketDisconnect) GetReason() DisconnectReason {
+	if m != nil {
+		return m.Reason
+	}
+	return DisconnectReason_DISCONNECT_REASON_UNKNOWN
+}
+
 type Packet struct {
 	// Types that are valid to be assigned to Sum:
 	//	*Packet_PacketPing
 	//	*Packet_PacketPong
 	//	*Packet_PacketMsg
+	//	*Packet_PacketDisconnect
 	Sum isPacket_Sum `protobuf_oneof:"sum"`
 }
 
@@ -168,7 +247,7 @@ func (m *Packet) Reset()         { *m = Packet{} }
 func (m *Packet) String() string { return proto.CompactTextString(m) }
 func (*Packet) ProtoMessage()    {}
 func (*Packet) Descriptor() ([]byte, []int) {
-	return fileDescriptor_22474b5527c8fa9f, []int{3}
+	return fileDescriptor_22474b5527c8fa9f, []int{4}
 }
 func (m *Packet) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -212,10 +291,14 @@ type Packet_PacketPong struct {
 type Packet_PacketMsg struct {
 	PacketMsg *PacketMsg `protobuf:"bytes,3,opt,name=packet_msg,json=packetMsg,proto3,oneof" json:"packet_msg,omitempty"`
 }
+type Packet_PacketDisconnect struct {
+	PacketDisconnect *PacketDisconnect `protobuf:"bytes,4,opt,name=packet_disconnect,json=packetDisconnect,proto3,oneof" json:"packet_disconnect,omitempty"`
+}
 
-func (*Packet_PacketPing) isPacket_Sum() {}
-func (*Packet_PacketPong) isPacket_Sum() {}
-func (*Packet_PacketMsg) isPacket_Sum()  {}
+func (*Packet_PacketPing) isPacket_Sum()       {}
+func (*Packet_PacketPong) isPacket_Sum()       {}
+func (*Packet_PacketMsg) isPacket_Sum()        {}
+func (*Packet_PacketDisconnect) isPacket_Sum() {}
 
 func (m *Packet) GetSum() isPacket_Sum {
 	if m != nil {
@@ -245,12 +328,20 @@ func (m *Packet) GetPacketMsg() *PacketMsg {
 	return nil
 }
 
+func (m *Packet) GetPacketDisconnect() *PacketDisconnect {
+	if x, ok := m.GetSum().(*Packet_PacketDisconnect); ok {
+		return x.PacketDisconnect
+	}
+	return nil
+}
+
 // XXX_OneofWrappers is for the internal use of the proto package.
 func (*Packet) XXX_OneofWrappers() []interface{} {
 	return []interface{}{
 		(*Packet_PacketPing)(nil),
 		(*Packet_PacketPong)(nil),
 		(*Packet_PacketMsg)(nil),
+		(*Packet_PacketDisconnect)(nil),
 	}
 }
 
@@ -263,7 +354,7 @@ func (m *AuthSigMessage) Reset()         { *m = AuthSigMessage{} }
 func (m *AuthSigMessage) String() string { return proto.CompactTextString(m) }
 func (*AuthSigMessage) ProtoMessage()    {}
 func (*AuthSigMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_22474b5527c8fa9f, []int{4}
+	return fileDescriptor_22474b5527c8fa9f, []int{5}
 }
 func (m *AuthSigMessage) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -307,9 +398,11 @@ func (m *AuthSigMessage) GetSig() []byte {
 }
 
 func init() {
+	proto.RegisterEnum("tendermint.p2p.DisconnectReason", DisconnectReason_name, DisconnectReason_value)
 	proto.RegisterType((*PacketPing)(nil), "tendermint.p2p.PacketPing")
 	proto.RegisterType((*PacketPong)(nil), "tendermint.p2p.PacketPong")
 	proto.RegisterType((*PacketMsg)(nil), "tendermint.p2p.PacketMsg")
+	proto.RegisterType((*PacketDisconnect)(nil), "tendermint.p2p.PacketDisconnect")
 	proto.RegisterType((*Packet)(nil), "tendermint.p2p.Packet")
 	proto.RegisterType((*AuthSigMessage)(nil), "tendermint.p2p.AuthSigMessage")
 }
@@ -317,32 +410,42 @@ func init() {
 func init() { proto.RegisterFile("tendermint/p2p/conn.proto", fileDescriptor_22474b5527c8fa9f) }
 
 var fileDescriptor_22474b5527c8fa9f = []byte{
-	// 395 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x52, 0x3d, 0x8f, 0xd3, 0x40,
-	0x10, 0xf5, 0xe2, 0xbb, 0x1c, 0x99, 0x84, 0x13, 0x5a, 0x51, 0x24, 0xd1, 0xc9, 0x89, 0x5c, 0xa5,
-	0x40, 0xb6, 0x64, 0x44, 0x03, 0xa2, 0xc0, 0x7c, 0x88, 0xd3, 0x29, 0xba, 0xc8, 0x74, 0x34, 0x96,
-	0x3f, 0x96, 0xf5, 0x2a, 0xe7, 0xdd, 0x55, 0x76, 0x5d, 0xf8, 0x5f, 0xf0, 0xb3, 0x8e, 0xee, 0x4a,
-	0xaa, 0x08, 0x39, 0x7f, 0x04, 0x79, 0x1d, 0x88, 0x23, 0x71, 0xdd, 0x7b, 0x33, 0xf3, 0xe6, 0x43,
-	0xf3, 0x60, 0xaa, 0x09, 0xcf, 0xc9, 0xb6, 0x64, 0x5c, 0xfb, 0x32, 0x90, 0x7e, 0x26, 0x38, 0xf7,
-	0xe4, 0x56, 0x68, 0x81, 0x2f, 0x8f, 0x29, 0x4f, 0x06, 0x72, 0xf6, 0x82, 0x0a, 0x2a, 0x4c, 0xca,
-	0x6f, 0x51, 0x57, 0x35, 0xbb, 0xea, 0x35, 0xc8, 0xb6, 0xb5, 0xd4, 0xc2, 0xdf, 0x90, 0x5a, 0x75,
-	0x59, 0x77, 0x0c, 0xb0, 0x4e, 0xb2, 0x0d, 0xd1, 0x6b, 0xc6, 0x69, 0x8f, 0x09, 0x4e, 0xdd, 0x02,
-	0x86, 0x1d, 0x5b, 0x29, 0x8a, 0x5f, 0x02, 0x64, 0x45, 0xc2, 0x39, 0xb9, 0x8b, 0x59, 0x3e, 0x41,
-	0x0b, 0xb4, 0x3c, 0x0f, 0x9f, 0x35, 0xbb, 0xf9, 0xf0, 0x43, 0x17, 0xbd, 0xfe, 0x18, 0x0d, 0x0f,
-	0x05, 0xd7, 0x39, 0x9e, 0x82, 0x4d, 0xc4, 0xf7, 0xc9, 0x93, 0x05, 0x5a, 0x3e, 0x0d, 0x2f, 0x9a,
-	0xdd, 0xdc, 0xfe, 0x74, 0xfb, 0x39, 0x6a, 0x63, 0x18, 0xc3, 0x59, 0x9e, 0xe8, 0x64, 0x62, 0x2f,
-	0xd0, 0x72, 0x1c, 0x19, 0xec, 0xfe, 0x44, 0x30, 0xe8, 0x46, 0xe1, 0x77, 0x30, 0x92, 0x06, 0xc5,
-	0x92, 0x71, 0x6a, 0x06, 0x8d, 0x82, 0x99, 0x77, 0x7a, 0xaa, 0x77, 0xdc, 0xf9, 0x8b, 0x15, 0x81,
-	0xfc, 0xc7, 0xfa, 0x72, 0xc1, 0xa9, 0x59, 0xe0, 0x71, 0xb9, 0x38, 0x91, 0x0b, 0x4e, 0xf1, 0x1b,
-	0x38, 0xb0, 0xb8, 0x54, 0xd4, 0xac, 0x38, 0x0a, 0xa6, 0xff, 0x57, 0xaf, 0x54, 0x2b, 0x1e, 0xca,
-	0xbf, 0x24, 0x3c, 0x07, 0x5b, 0x55, 0xa5, 0x1b, 0xc3, 0xe5, 0xfb, 0x4a, 0x17, 0x5f, 0x19, 0x5d,
-	0x11, 0xa5, 0x12, 0x4a, 0xf0, 0x5b, 0xb8, 0x90, 0x55, 0x1a, 0x6f, 0x48, 0x7d, 0x38, 0xe7, 0xaa,
-	0xdf, 0xb1, 0xfb, 0x89, 0xb7, 0xae, 0xd2, 0x3b, 0x96, 0xdd, 0x90, 0x3a, 0x3c, 0xbb, 0xdf, 0xcd,
-	0xad, 0x68, 0x20, 0xab, 0xf4, 0x86, 0xd4, 0xf8, 0x39, 0xd8, 0x8a, 0x75, 0x87, 0x8c, 0xa3, 0x16,
-	0x86, 0xb7, 0xf7, 0x8d, 0x83, 0x1e, 0x1a, 0x07, 0xfd, 0x6e, 0x1c, 0xf4, 0x63, 0xef, 0x58, 0x0f,
-	0x7b, 0xc7, 0xfa, 0xb5, 0x77, 0xac, 0x6f, 0xaf, 0x29, 0xd3, 0x45, 0x95, 0x7a, 0x99, 0x28, 0xfd,
-	0xde, 0xd7, 0xfb, 0x0e, 0x32, 0xee, 0x38, 0xb5, 0x54, 0x3a, 0x30, 0xd1, 0x57, 0x7f, 0x02, 0x00,
-	0x00, 0xff, 0xff, 0x30, 0xfd, 0xb2, 0x8d, 0x6b, 0x02, 0x00, 0x00,
+	// 550 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x53, 0xcd, 0x6e, 0xda, 0x4e,
+	0x10, 0xb7, 0x31, 0x21, 0x7f, 0x26, 0xf9, 0x47, 0xee, 0xaa, 0x07, 0x88, 0x12, 0x43, 0xdd, 0x4b,
+	0x54, 0x55, 0x46, 0xa2, 0xaa, 0x54, 0xb5, 0xea, 0x01, 0x83, 0x1b, 0xac, 0x04, 0x1b, 0x19, 0x92,
+	0x4a, 0xbd, 0x58, 0xc6, 0x6c, 0x17, 0x2b, 0x61, 0x77, 0x85, 0xed, 0x03, 0x6f, 0xd1, 0x73, 0x9f,
+	0xa5, 0x0f, 0x90, 0x63, 0x8e, 0x3d, 0x45, 0x15, 0x79, 0x91, 0xca, 0x36, 0x02, 0xf3, 0xa1, 0xde,
+	0x66, 0xe6, 0xf7, 0xb1, 0xcc, 0x0f, 0x0f, 0x54, 0x23, 0x4c, 0xc7, 0x78, 0x36, 0x0d, 0x68, 0xd4,
+	0xe0, 0x4d, 0xde, 0xf0, 0x19, 0xa5, 0x1a, 0x9f, 0xb1, 0x88, 0xa1, 0x93, 0x35, 0xa4, 0xf1, 0x26,
+	0x3f, 0x7d, 0x49, 0x18, 0x61, 0x29, 0xd4, 0x48, 0xaa, 0x8c, 0x75, 0x7a, 0x96, 0x33, 0xf0, 0x67,
+	0x73, 0x1e, 0xb1, 0xc6, 0x1d, 0x9e, 0x87, 0x19, 0xaa, 0x1e, 0x03, 0xf4, 0x3d, 0xff, 0x0e, 0x47,
+	0xfd, 0x80, 0x92, 0x5c, 0xc7, 0x28, 0x51, 0x27, 0x50, 0xce, 0xba, 0x5e, 0x48, 0xd0, 0x5b, 0x00,
+	0x7f, 0xe2, 0x51, 0x8a, 0xef, 0xdd, 0x60, 0x5c, 0x11, 0xeb, 0xe2, 0xc5, 0x81, 0xfe, 0xff, 0xe2,
+	0xa9, 0x56, 0x6e, 0x67, 0x53, 0xb3, 0xe3, 0x94, 0x97, 0x04, 0x73, 0x8c, 0xaa, 0x20, 0x61, 0xf6,
+	0xbd, 0x52, 0xa8, 0x8b, 0x17, 0xff, 0xe9, 0x87, 0x8b, 0xa7, 0x9a, 0x64, 0xd8, 0x5f, 0x9c, 0x64,
+	0x86, 0x10, 0x14, 0xc7, 0x5e, 0xe4, 0x55, 0xa4, 0xba, 0x78, 0x71, 0xec, 0xa4, 0xb5, 0x7a, 0x0d,
+	0x72, 0xf6, 0x52, 0x27, 0x08, 0x93, 0x05, 0xb1, 0x1f, 0xa1, 0x0f, 0x50, 0x9a, 0x61, 0x2f, 0x64,
+	0x34, 0x7d, 0xec, 0xa4, 0x59, 0xd7, 0x36, 0xd7, 0xd5, 0xd6, 0x5c, 0x27, 0xe5, 0x39, 0x4b, 0xbe,
+	0xfa, 0xb3, 0x00, 0xa5, 0xcc, 0x0e, 0x7d, 0x86, 0x23, 0x9e, 0x56, 0x2e, 0x0f, 0x28, 0x49, 0x9d,
+	0x8e, 0x9a, 0xa7, 0xdb, 0x4e, 0xeb, 0x04, 0xba, 0x82, 0x03, 0x7c, 0xd5, 0xe5, 0xe5, 0x8c, 0x92,
+	0x4a, 0xe1, 0x9f, 0x72, 0xb6, 0x21, 0x67, 0x94, 0xa0, 0x8f, 0xb0, 0xec, 0xdc, 0x69, 0x48, 0xd2,
+	0x85, 0x8f, 0x9a, 0xd5, 0xfd, 0xea, 0x5e, 0x98, 0x88, 0xcb, 0x7c, 0x95, 0xb7, 0x0d, 0x2f, 0x96,
+	0xda, 0xf1, 0x6a, 0xcf, 0x4a, 0x31, 0xb5, 0xa8, 0xef, 0xb7, 0x58, 0xe7, 0xd1, 0x15, 0x1c, 0x99,
+	0x6f, 0xcd, 0xf4, 0x03, 0x90, 0xc2, 0x78, 0xaa, 0xba, 0x70, 0xd2, 0x8a, 0xa3, 0xc9, 0x20, 0x20,
+	0x3d, 0x1c, 0x86, 0x1e, 0xc1, 0xe8, 0x13, 0x1c, 0xf2, 0x78, 0xe4, 0xde, 0xe1, 0xf9, 0x32, 0x9f,
+	0xb3, 0xbc, 0x7f, 0xf6, 0xc9, 0x68, 0xfd, 0x78, 0x74, 0x1f, 0xf8, 0x57, 0x78, 0xae, 0x17, 0x1f,
+	0x9e, 0x6a, 0x82, 0x53, 0xe2, 0xf1, 0xe8, 0x0a, 0xcf, 0x91, 0x0c, 0x52, 0x18, 0x64, 0xc9, 0x1c,
+	0x3b, 0x49, 0xf9, 0xe6, 0x97, 0x08, 0xf2, 0xf6, 0x5f, 0x83, 0xce, 0xa1, 0xda, 0x31, 0x07, 0x6d,
+	0xdb, 0xb2, 0x8c, 0xf6, 0xd0, 0x75, 0x8c, 0xd6, 0xc0, 0xb6, 0xdc, 0x1b, 0xeb, 0xca, 0xb2, 0xbf,
+	0x5a, 0xb2, 0xb0, 0x1f, 0x36, 0x6e, 0xcd, 0xf6, 0xd0, 0xe8, 0xc8, 0x22, 0x52, 0x41, 0xd9, 0x85,
+	0x4d, 0xab, 0x6d, 0xf7, 0xfa, 0xad, 0xa1, 0xa9, 0x5f, 0x1b, 0x72, 0x01, 0xbd, 0x82, 0xf3, 0x5d,
+	0x4e, 0xcf, 0x1c, 0xe8, 0x46, 0xb7, 0x75, 0x6b, 0x5a, 0x97, 0xb2, 0x84, 0x5e, 0x43, 0x6d, 0x97,
+	0x32, 0xe8, 0xde, 0x0c, 0x87, 0xa6, 0x75, 0xe9, 0x76, 0x92, 0x9f, 0x52, 0xd4, 0xed, 0x87, 0x85,
+	0x22, 0x3e, 0x2e, 0x14, 0xf1, 0xcf, 0x42, 0x11, 0x7f, 0x3c, 0x2b, 0xc2, 0xe3, 0xb3, 0x22, 0xfc,
+	0x7e, 0x56, 0x84, 0x6f, 0xef, 0x49, 0x10, 0x4d, 0xe2, 0x91, 0xe6, 0xb3, 0x69, 0x23, 0x77, 0x53,
+	0xb9, 0x32, 0xbb, 0xbd, 0xcd, 0x83, 0x1d, 0x95, 0xd2, 0xe9, 0xbb, 0xbf, 0x03, 0x00, 0xb4, 0xa6,
+	0x48, 0x5f, 0xc9, 0x03, 0x00, 0x00,
 }
 
 func (m *PacketPing) Marshal() (dAtA []byte, err error) {
@@ -436,6 +539,34 @@ func (m *PacketMsg) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *PacketDisconnect) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PacketDisconnect) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *PacketDisconnect) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Reason != 0 {
+		i = encodeVarintConn(dAtA, i, uint64(m.Reason))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *Packet) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -531,6 +662,27 @@ func (m *Packet_PacketMsg) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	}
 	return len(dAtA) - i, nil
 }
+func (m *Packet_PacketDisconnect) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Packet_PacketDisconnect) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	if m.PacketDisconnect != nil {
+		{
+			size, err := m.PacketDisconnect.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintConn(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x22
+	}
+	return len(dAtA) - i, nil
+}
 func (m *AuthSigMessage) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -619,6 +771,18 @@ func (m *PacketMsg) Size() (n int) {
 	return n
 }
 
+func (m *PacketDisconnect) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Reason != 0 {
+		n += 1 + sovConn(uint64(m.Reason))
+	}
+	return n
+}
+
 func (m *Packet) Size() (n int) {
 	if m == nil {
 		return 0
@@ -667,6 +831,18 @@ func (m *Packet_PacketMsg) Size() (n int) {
 	}
 	return n
 }
+func (m *Packet_PacketDisconnect) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.PacketDisconnect != nil {
+		l = m.PacketDisconnect.Size()
+		n += 1 + l + sovConn(uint64(l))
+	}
+	return n
+}
 func (m *AuthSigMessage) Size() (n int) {
 	if m == nil {
 		return 0
@@ -911,6 +1087,75 @@ func (m *PacketMsg) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *PacketDisconnect) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowConn
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PacketDisconnect: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PacketDisconnect: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Reason", wireType)
+			}
+			m.Reason = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConn
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Reason |= DisconnectReason(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipConn(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthConn
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func (m *Packet) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
@@ -1045,6 +1290,41 @@ func (m *Packet) Unmarshal(dAtA []byte) error {
 			}
 			m.Sum = &Packet_PacketMsg{v}
 			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PacketDisconnect", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowConn
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthConn
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthConn
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &PacketDisconnect{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Sum = &Packet_PacketDisconnect{v}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipConn(dAtA[iNdEx:])
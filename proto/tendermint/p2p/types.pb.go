@@ -88,14 +88,16 @@ func (m *ProtocolVersion) GetApp() uint64 {
 }
 
 type NodeInfo struct {
-	ProtocolVersion ProtocolVersion `protobuf:"bytes,1,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version"`
-	NodeID          string          `protobuf:"bytes,2,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
-	ListenAddr      string          `protobuf:"bytes,3,opt,name=listen_addr,json=listenAddr,proto3" json:"listen_addr,omitempty"`
-	Network         string          `protobuf:"bytes,4,opt,name=network,proto3" json:"network,omitempty"`
-	Version         string          `protobuf:"bytes,5,opt,name=version,proto3" json:"version,omitempty"`
-	Channels        []byte          `protobuf:"bytes,6,opt,name=channels,proto3" json:"channels,omitempty"`
-	Moniker         string          `protobuf:"bytes,7,opt,name=moniker,proto3" json:"moniker,omitempty"`
-	Other           NodeInfoOther   `protobuf:"bytes,8,opt,name=other,proto3" json:"other"`
+	ProtocolVersion    ProtocolVersion `protobuf:"bytes,1,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version"`
+	NodeID             string          `protobuf:"bytes,2,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	ListenAddr         string          `protobuf:"bytes,3,opt,name=listen_addr,json=listenAddr,proto3" json:"listen_addr,omitempty"`
+	Network            string          `protobuf:"bytes,4,opt,name=network,proto3" json:"network,omitempty"`
+	Version            string          `protobuf:"bytes,5,opt,name=version,proto3" json:"version,omitempty"`
+	Channels           []byte          `protobuf:"bytes,6,opt,name=channels,proto3" json:"channels,omitempty"`
+	Moniker            string          `protobuf:"bytes,7,opt,name=moniker,proto3" json:"moniker,omitempty"`
+	Other              NodeInfoOther   `protobuf:"bytes,8,opt,name=other,proto3" json:"other"`
+	CompressedChannels []byte          `protobuf:"bytes,9,opt,name=compressed_channels,json=compressedChannels,proto3" json:"compressed_channels,omitempty"`
+	Time               time.Time       `protobuf:"bytes,10,opt,name=time,proto3,stdtime" json:"time"`
 }
 
 func (m *NodeInfo) Reset()         { *m = NodeInfo{} }
@@ -187,6 +189,20 @@ func (m *NodeInfo) GetOther() NodeInfoOther {
 	return NodeInfoOther{}
 }
 
+func (m *NodeInfo) GetCompressedChannels() []byte {
+	if m != nil {
+		return m.CompressedChannels
+	}
+	return nil
+}
+
+func (m *NodeInfo) GetTime() time.Time {
+	if m != nil {
+		return m.Time
+	}
+	return time.Time{}
+}
+
 type NodeInfoOther struct {
 	TxIndex    string `protobuf:"bytes,1,opt,name=tx_index,json=txIndex,proto3" json:"tx_index,omitempty"`
 	RPCAddress string `protobuf:"bytes,2,opt,name=rpc_address,json=rpcAddress,proto3" json:"rpc_address,omitempty"`
@@ -243,6 +259,7 @@ type PeerInfo struct {
 	ID            string             `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	AddressInfo   []*PeerAddressInfo `protobuf:"bytes,2,rep,name=address_info,json=addressInfo,proto3" json:"address_info,omitempty"`
 	LastConnected *time.Time         `protobuf:"bytes,3,opt,name=last_connected,json=lastConnected,proto3,stdtime" json:"last_connected,omitempty"`
+	Imported      bool               `protobuf:"varint,4,opt,name=imported,proto3" json:"imported,omitempty"`
 }
 
 func (m *PeerInfo) Reset()         { *m = PeerInfo{} }
@@ -299,6 +316,13 @@ func (m *PeerInfo) GetLastConnected() *time.Time {
 	return nil
 }
 
+func (m *PeerInfo) GetImported() bool {
+	if m != nil {
+		return m.Imported
+	}
+	return false
+}
+
 type PeerAddressInfo struct {
 	Address         string     `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
 	LastDialSuccess *time.Time `protobuf:"bytes,2,opt,name=last_dial_success,json=lastDialSuccess,proto3,stdtime" json:"last_dial_success,omitempty"`
@@ -378,46 +402,48 @@ func init() {
 func init() { proto.RegisterFile("tendermint/p2p/types.proto", fileDescriptor_c8a29e659aeca578) }
 
 var fileDescriptor_c8a29e659aeca578 = []byte{
-	// 610 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x54, 0xcd, 0x4e, 0x1b, 0x3d,
-	0x14, 0xcd, 0x24, 0x21, 0x09, 0x37, 0x84, 0xf0, 0x59, 0xe8, 0xd3, 0x10, 0xa9, 0x19, 0x14, 0x36,
-	0xac, 0x26, 0x52, 0xaa, 0x2e, 0xba, 0x64, 0x40, 0xad, 0x22, 0x55, 0x25, 0x9a, 0xa2, 0x2e, 0xda,
-	0xc5, 0x68, 0x32, 0x76, 0x82, 0xc5, 0xc4, 0xb6, 0x3c, 0x4e, 0x4b, 0xdf, 0x82, 0x37, 0xe9, 0x63,
-	0x94, 0x25, 0xcb, 0xae, 0xd2, 0x6a, 0xd8, 0xf6, 0x21, 0x2a, 0xdb, 0x33, 0x40, 0xa2, 0x2e, 0xd8,
-	0xf9, 0xdc, 0xe3, 0x73, 0xee, 0x8f, 0xad, 0x0b, 0x3d, 0x45, 0x18, 0x26, 0x72, 0x41, 0x99, 0x1a,
-	0x8a, 0x91, 0x18, 0xaa, 0x6f, 0x82, 0x64, 0xbe, 0x90, 0x5c, 0x71, 0xb4, 0xfb, 0xc8, 0xf9, 0x62,
-	0x24, 0x7a, 0xfb, 0x73, 0x3e, 0xe7, 0x86, 0x1a, 0xea, 0x93, 0xbd, 0xd5, 0xf3, 0xe6, 0x9c, 0xcf,
-	0x53, 0x32, 0x34, 0x68, 0xba, 0x9c, 0x0d, 0x15, 0x5d, 0x90, 0x4c, 0xc5, 0x0b, 0x61, 0x2f, 0x0c,
-	0x2e, 0xa0, 0x3b, 0xd1, 0x87, 0x84, 0xa7, 0x1f, 0x89, 0xcc, 0x28, 0x67, 0xe8, 0x00, 0x6a, 0x62,
-	0x24, 0x5c, 0xe7, 0xd0, 0x39, 0xae, 0x07, 0xcd, 0x7c, 0xe5, 0xd5, 0x26, 0xa3, 0x49, 0xa8, 0x63,
-	0x68, 0x1f, 0xb6, 0xa6, 0x29, 0x4f, 0xae, 0xdc, 0xaa, 0x26, 0x43, 0x0b, 0xd0, 0x1e, 0xd4, 0x62,
-	0x21, 0xdc, 0x9a, 0x89, 0xe9, 0xe3, 0xe0, 0x47, 0x15, 0x5a, 0xef, 0x39, 0x26, 0x63, 0x36, 0xe3,
-	0x68, 0x02, 0x7b, 0xa2, 0x48, 0x11, 0x7d, 0xb1, 0x39, 0x8c, 0x79, 0x7b, 0xe4, 0xf9, 0xeb, 0x4d,
-	0xf8, 0x1b, 0xa5, 0x04, 0xf5, 0xdb, 0x95, 0x57, 0x09, 0xbb, 0x62, 0xa3, 0xc2, 0x23, 0x68, 0x32,
-	0x8e, 0x49, 0x44, 0xb1, 0x29, 0x64, 0x3b, 0x80, 0x7c, 0xe5, 0x35, 0x4c, 0xc2, 0xb3, 0xb0, 0xa1,
-	0xa9, 0x31, 0x46, 0x1e, 0xb4, 0x53, 0x9a, 0x29, 0xc2, 0xa2, 0x18, 0x63, 0x69, 0xaa, 0xdb, 0x0e,
-	0xc1, 0x86, 0x4e, 0x30, 0x96, 0xc8, 0x85, 0x26, 0x23, 0xea, 0x2b, 0x97, 0x57, 0x6e, 0xdd, 0x90,
-	0x25, 0xd4, 0x4c, 0x59, 0xe8, 0x96, 0x65, 0x0a, 0x88, 0x7a, 0xd0, 0x4a, 0x2e, 0x63, 0xc6, 0x48,
-	0x9a, 0xb9, 0x8d, 0x43, 0xe7, 0x78, 0x27, 0x7c, 0xc0, 0x5a, 0xb5, 0xe0, 0x8c, 0x5e, 0x11, 0xe9,
-	0x36, 0xad, 0xaa, 0x80, 0xe8, 0x35, 0x6c, 0x71, 0x75, 0x49, 0xa4, 0xdb, 0x32, 0x6d, 0xbf, 0xd8,
-	0x6c, 0xbb, 0x1c, 0xd5, 0xb9, 0xbe, 0x54, 0x34, 0x6d, 0x15, 0x83, 0xcf, 0xd0, 0x59, 0x63, 0xd1,
-	0x01, 0xb4, 0xd4, 0x75, 0x44, 0x19, 0x26, 0xd7, 0x66, 0x8a, 0xdb, 0x61, 0x53, 0x5d, 0x8f, 0x35,
-	0x44, 0x43, 0x68, 0x4b, 0x91, 0x98, 0x76, 0x49, 0x96, 0x15, 0xa3, 0xd9, 0xcd, 0x57, 0x1e, 0x84,
-	0x93, 0xd3, 0x13, 0x1b, 0x0d, 0x41, 0x8a, 0xa4, 0x38, 0x0f, 0xbe, 0x3b, 0xd0, 0x9a, 0x10, 0x22,
-	0xcd, 0x33, 0xfd, 0x0f, 0x55, 0x8a, 0xad, 0x65, 0xd0, 0xc8, 0x57, 0x5e, 0x75, 0x7c, 0x16, 0x56,
-	0x29, 0x46, 0x01, 0xec, 0x14, 0x8e, 0x11, 0x65, 0x33, 0xee, 0x56, 0x0f, 0x6b, 0xff, 0x7c, 0x3a,
-	0x42, 0x64, 0xe1, 0xab, 0xed, 0xc2, 0x76, 0xfc, 0x08, 0xd0, 0x5b, 0xd8, 0x4d, 0xe3, 0x4c, 0x45,
-	0x09, 0x67, 0x8c, 0x24, 0x8a, 0x60, 0xf3, 0x1c, 0xed, 0x51, 0xcf, 0xb7, 0xff, 0xd3, 0x2f, 0xff,
-	0xa7, 0x7f, 0x51, 0xfe, 0xcf, 0xa0, 0x7e, 0xf3, 0xcb, 0x73, 0xc2, 0x8e, 0xd6, 0x9d, 0x96, 0xb2,
-	0xc1, 0x1f, 0x07, 0xba, 0x1b, 0x99, 0xf4, 0xdc, 0xcb, 0x96, 0x8b, 0x81, 0x14, 0x10, 0xbd, 0x83,
-	0xff, 0x4c, 0x5a, 0x4c, 0xe3, 0x34, 0xca, 0x96, 0x49, 0x52, 0x8e, 0xe5, 0x39, 0x99, 0xbb, 0x5a,
-	0x7a, 0x46, 0xe3, 0xf4, 0x83, 0x15, 0xae, 0xbb, 0xcd, 0x62, 0x9a, 0x2e, 0x25, 0x79, 0x76, 0x1f,
-	0x0f, 0x6e, 0x6f, 0xac, 0x10, 0x1d, 0x41, 0xe7, 0xa9, 0x51, 0x66, 0xfe, 0x60, 0x27, 0xdc, 0xc1,
-	0x8f, 0x77, 0xb2, 0xe0, 0xfc, 0x36, 0xef, 0x3b, 0x77, 0x79, 0xdf, 0xf9, 0x9d, 0xf7, 0x9d, 0x9b,
-	0xfb, 0x7e, 0xe5, 0xee, 0xbe, 0x5f, 0xf9, 0x79, 0xdf, 0xaf, 0x7c, 0x7a, 0x35, 0xa7, 0xea, 0x72,
-	0x39, 0xf5, 0x13, 0xbe, 0x18, 0x3e, 0xd9, 0x12, 0x4f, 0x17, 0x86, 0xd9, 0x05, 0xeb, 0x1b, 0x64,
-	0xda, 0x30, 0xd1, 0x97, 0x7f, 0x03, 0x00, 0x00, 0xff, 0xff, 0x0b, 0xe9, 0x56, 0xd3, 0x5a, 0x04,
-	0x00, 0x00,
+	// 655 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0xcd, 0x6e, 0xd3, 0x40,
+	0x10, 0x8e, 0xe3, 0x34, 0x3f, 0x93, 0xa6, 0x29, 0x4b, 0x85, 0xdc, 0x48, 0xc4, 0x55, 0x7a, 0xe9,
+	0xc9, 0x96, 0x82, 0x90, 0xe0, 0xd8, 0xb4, 0x02, 0x45, 0x42, 0x34, 0x32, 0x15, 0x07, 0x38, 0x58,
+	0x8e, 0x77, 0x93, 0xae, 0xea, 0x78, 0x57, 0xeb, 0x0d, 0x94, 0xb7, 0xe8, 0xb3, 0xf0, 0x14, 0x95,
+	0xb8, 0xf4, 0xc8, 0x29, 0xa0, 0xf4, 0xca, 0x43, 0xa0, 0xdd, 0xb5, 0x9b, 0x26, 0x42, 0x02, 0x6e,
+	0xf3, 0xcd, 0xec, 0x7c, 0xf3, 0xcd, 0x8f, 0x16, 0x3a, 0x92, 0xa4, 0x98, 0x88, 0x19, 0x4d, 0xa5,
+	0xcf, 0xfb, 0xdc, 0x97, 0x5f, 0x38, 0xc9, 0x3c, 0x2e, 0x98, 0x64, 0x68, 0x67, 0x15, 0xf3, 0x78,
+	0x9f, 0x77, 0xf6, 0xa6, 0x6c, 0xca, 0x74, 0xc8, 0x57, 0x96, 0x79, 0xd5, 0x71, 0xa7, 0x8c, 0x4d,
+	0x13, 0xe2, 0x6b, 0x34, 0x9e, 0x4f, 0x7c, 0x49, 0x67, 0x24, 0x93, 0xd1, 0x8c, 0x9b, 0x07, 0xbd,
+	0x73, 0x68, 0x8f, 0x94, 0x11, 0xb3, 0xe4, 0x3d, 0x11, 0x19, 0x65, 0x29, 0xda, 0x07, 0x9b, 0xf7,
+	0xb9, 0x63, 0x1d, 0x58, 0x47, 0x95, 0x41, 0x6d, 0xb9, 0x70, 0xed, 0x51, 0x7f, 0x14, 0x28, 0x1f,
+	0xda, 0x83, 0xad, 0x71, 0xc2, 0xe2, 0x4b, 0xa7, 0xac, 0x82, 0x81, 0x01, 0x68, 0x17, 0xec, 0x88,
+	0x73, 0xc7, 0xd6, 0x3e, 0x65, 0xf6, 0xbe, 0xda, 0x50, 0x7f, 0xcb, 0x30, 0x19, 0xa6, 0x13, 0x86,
+	0x46, 0xb0, 0xcb, 0xf3, 0x12, 0xe1, 0x27, 0x53, 0x43, 0x93, 0x37, 0xfb, 0xae, 0xb7, 0xde, 0x84,
+	0xb7, 0x21, 0x65, 0x50, 0xb9, 0x59, 0xb8, 0xa5, 0xa0, 0xcd, 0x37, 0x14, 0x1e, 0x42, 0x2d, 0x65,
+	0x98, 0x84, 0x14, 0x6b, 0x21, 0x8d, 0x01, 0x2c, 0x17, 0x6e, 0x55, 0x17, 0x3c, 0x0d, 0xaa, 0x2a,
+	0x34, 0xc4, 0xc8, 0x85, 0x66, 0x42, 0x33, 0x49, 0xd2, 0x30, 0xc2, 0x58, 0x68, 0x75, 0x8d, 0x00,
+	0x8c, 0xeb, 0x18, 0x63, 0x81, 0x1c, 0xa8, 0xa5, 0x44, 0x7e, 0x66, 0xe2, 0xd2, 0xa9, 0xe8, 0x60,
+	0x01, 0x55, 0xa4, 0x10, 0xba, 0x65, 0x22, 0x39, 0x44, 0x1d, 0xa8, 0xc7, 0x17, 0x51, 0x9a, 0x92,
+	0x24, 0x73, 0xaa, 0x07, 0xd6, 0xd1, 0x76, 0x70, 0x8f, 0x55, 0xd6, 0x8c, 0xa5, 0xf4, 0x92, 0x08,
+	0xa7, 0x66, 0xb2, 0x72, 0x88, 0x5e, 0xc2, 0x16, 0x93, 0x17, 0x44, 0x38, 0x75, 0xdd, 0xf6, 0xd3,
+	0xcd, 0xb6, 0x8b, 0x51, 0x9d, 0xa9, 0x47, 0x79, 0xd3, 0x26, 0x03, 0xf9, 0xf0, 0x38, 0x66, 0x33,
+	0x2e, 0x48, 0x96, 0x11, 0x1c, 0xde, 0xd7, 0x6e, 0xe8, 0xda, 0x68, 0x15, 0x3a, 0x29, 0x54, 0xbc,
+	0x80, 0x8a, 0xda, 0xb1, 0x03, 0xba, 0x54, 0xc7, 0x33, 0x07, 0xe0, 0x15, 0x07, 0xe0, 0x9d, 0x17,
+	0x07, 0x30, 0xa8, 0xab, 0x3a, 0xd7, 0x3f, 0x5c, 0x2b, 0xd0, 0x19, 0xbd, 0x8f, 0xd0, 0x5a, 0x13,
+	0x82, 0xf6, 0xa1, 0x2e, 0xaf, 0x42, 0x9a, 0x62, 0x72, 0xa5, 0x17, 0xd6, 0x08, 0x6a, 0xf2, 0x6a,
+	0xa8, 0x20, 0xf2, 0xa1, 0x29, 0x78, 0xac, 0x27, 0x4b, 0xb2, 0x2c, 0xdf, 0xc2, 0xce, 0x72, 0xe1,
+	0x42, 0x30, 0x3a, 0x39, 0x36, 0xde, 0x00, 0x04, 0x8f, 0x73, 0xbb, 0xf7, 0xcd, 0x82, 0xfa, 0x88,
+	0x10, 0xa1, 0x2f, 0xe2, 0x09, 0x94, 0x29, 0x36, 0x94, 0x83, 0xea, 0x72, 0xe1, 0x96, 0x87, 0xa7,
+	0x41, 0x99, 0x62, 0x34, 0x80, 0xed, 0x9c, 0x31, 0xa4, 0xe9, 0x84, 0x39, 0xe5, 0x03, 0xfb, 0x8f,
+	0x57, 0x42, 0x88, 0xc8, 0x79, 0x15, 0x5d, 0xd0, 0x8c, 0x56, 0x00, 0xbd, 0x86, 0x9d, 0x24, 0xca,
+	0x64, 0x18, 0xb3, 0x34, 0x25, 0xb1, 0x24, 0xd8, 0xb1, 0xff, 0x3a, 0x89, 0x8a, 0x9e, 0x42, 0x4b,
+	0xe5, 0x9d, 0x14, 0x69, 0x6a, 0xd5, 0x74, 0xc6, 0x99, 0x50, 0x14, 0xea, 0x3e, 0xea, 0xc1, 0x3d,
+	0xee, 0xfd, 0xb2, 0xa0, 0xbd, 0xa1, 0x42, 0xad, 0xbf, 0x18, 0x47, 0x3e, 0xac, 0x1c, 0xa2, 0x37,
+	0xf0, 0x48, 0x4b, 0xc2, 0x34, 0x4a, 0xc2, 0x6c, 0x1e, 0xc7, 0xc5, 0xc8, 0xfe, 0x45, 0x55, 0x5b,
+	0xa5, 0x9e, 0xd2, 0x28, 0x79, 0x67, 0x12, 0xd7, 0xd9, 0x26, 0x11, 0x4d, 0xe6, 0x82, 0x38, 0xf6,
+	0xff, 0xb2, 0xbd, 0x32, 0x89, 0xe8, 0x10, 0x5a, 0x0f, 0x89, 0x32, 0xdd, 0x6a, 0x2b, 0xd8, 0xc6,
+	0xab, 0x37, 0xd9, 0xe0, 0xec, 0x66, 0xd9, 0xb5, 0x6e, 0x97, 0x5d, 0xeb, 0xe7, 0xb2, 0x6b, 0x5d,
+	0xdf, 0x75, 0x4b, 0xb7, 0x77, 0xdd, 0xd2, 0xf7, 0xbb, 0x6e, 0xe9, 0xc3, 0xf3, 0x29, 0x95, 0x17,
+	0xf3, 0xb1, 0x17, 0xb3, 0x99, 0xff, 0xe0, 0xb3, 0x7a, 0x60, 0x9a, 0x2f, 0x69, 0xfd, 0x23, 0x1b,
+	0x57, 0xb5, 0xf7, 0xd9, 0xef, 0x01, 0x00, 0xda, 0x54, 0x99, 0xc9, 0xe1, 0x04, 0x00, 0x00,
 }
 
 func (m *ProtocolVersion) Marshal() (dAtA []byte, err error) {
@@ -478,6 +504,21 @@ func (m *NodeInfo) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	n1, err1 := github_com_gogo_protobuf_types.StdTimeMarshalTo(m.Time, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(m.Time):])
+	if err1 != nil {
+		return 0, err1
+	}
+	i -= n1
+	i = encodeVarintTypes(dAtA, i, uint64(n1))
+	i--
+	dAtA[i] = 0x52
+	if len(m.CompressedChannels) > 0 {
+		i -= len(m.CompressedChannels)
+		copy(dAtA[i:], m.CompressedChannels)
+		i = encodeVarintTypes(dAtA, i, uint64(len(m.CompressedChannels)))
+		i--
+		dAtA[i] = 0x4a
+	}
 	{
 		size, err := m.Other.MarshalToSizedBuffer(dAtA[:i])
 		if err != nil {
@@ -600,13 +641,23 @@ func (m *PeerInfo) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.Imported {
+		i--
+		if m.Imported {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x20
+	}
 	if m.LastConnected != nil {
-		n3, err3 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.LastConnected, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.LastConnected):])
-		if err3 != nil {
-			return 0, err3
+		n4, err4 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.LastConnected, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.LastConnected):])
+		if err4 != nil {
+			return 0, err4
 		}
-		i -= n3
-		i = encodeVarintTypes(dAtA, i, uint64(n3))
+		i -= n4
+		i = encodeVarintTypes(dAtA, i, uint64(n4))
 		i--
 		dAtA[i] = 0x1a
 	}
@@ -660,22 +711,22 @@ func (m *PeerAddressInfo) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		dAtA[i] = 0x20
 	}
 	if m.LastDialFailure != nil {
-		n4, err4 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.LastDialFailure, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.LastDialFailure):])
-		if err4 != nil {
-			return 0, err4
+		n5, err5 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.LastDialFailure, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.LastDialFailure):])
+		if err5 != nil {
+			return 0, err5
 		}
-		i -= n4
-		i = encodeVarintTypes(dAtA, i, uint64(n4))
+		i -= n5
+		i = encodeVarintTypes(dAtA, i, uint64(n5))
 		i--
 		dAtA[i] = 0x1a
 	}
 	if m.LastDialSuccess != nil {
-		n5, err5 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.LastDialSuccess, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.LastDialSuccess):])
-		if err5 != nil {
-			return 0, err5
+		n6, err6 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.LastDialSuccess, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.LastDialSuccess):])
+		if err6 != nil {
+			return 0, err6
 		}
-		i -= n5
-		i = encodeVarintTypes(dAtA, i, uint64(n5))
+		i -= n6
+		i = encodeVarintTypes(dAtA, i, uint64(n6))
 		i--
 		dAtA[i] = 0x12
 	}
@@ -752,6 +803,12 @@ func (m *NodeInfo) Size() (n int) {
 	}
 	l = m.Other.Size()
 	n += 1 + l + sovTypes(uint64(l))
+	l = len(m.CompressedChannels)
+	if l > 0 {
+		n += 1 + l + sovTypes(uint64(l))
+	}
+	l = github_com_gogo_protobuf_types.SizeOfStdTime(m.Time)
+	n += 1 + l + sovTypes(uint64(l))
 	return n
 }
 
@@ -792,6 +849,9 @@ func (m *PeerInfo) Size() (n int) {
 		l = github_com_gogo_protobuf_types.SizeOfStdTime(*m.LastConnected)
 		n += 1 + l + sovTypes(uint64(l))
 	}
+	if m.Imported {
+		n += 2
+	}
 	return n
 }
 
@@ -1221,6 +1281,73 @@ func (m *NodeInfo) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CompressedChannels", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.CompressedChannels = append(m.CompressedChannels[:0], dAtA[iNdEx:postIndex]...)
+			if m.CompressedChannels == nil {
+				m.CompressedChannels = []byte{}
+			}
+			iNdEx = postIndex
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Time", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := github_com_gogo_protobuf_types.StdTimeUnmarshal(&m.Time, dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTypes(dAtA[iNdEx:])
@@ -1487,6 +1614,26 @@ func (m *PeerInfo) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Imported", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Imported = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTypes(dAtA[iNdEx:])
@@ -537,9 +537,12 @@ func (c *baseRPCClient) Validators(
 	height *int64,
 	page,
 	perPage *int,
+	prove bool,
 ) (*ctypes.ResultValidators, error) {
 	result := new(ctypes.ResultValidators)
-	params := make(map[string]interface{})
+	params := map[string]interface{}{
+		"prove": prove,
+	}
 	if page != nil {
 		params["page"] = page
 	}
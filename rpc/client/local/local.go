@@ -177,8 +177,13 @@ func (c *Local) Commit(ctx context.Context, height *int64) (*ctypes.ResultCommit
 	return c.env.Commit(c.ctx, height)
 }
 
-func (c *Local) Validators(ctx context.Context, height *int64, page, perPage *int) (*ctypes.ResultValidators, error) {
-	return c.env.Validators(c.ctx, height, page, perPage)
+func (c *Local) Validators(
+	ctx context.Context,
+	height *int64,
+	page, perPage *int,
+	prove bool,
+) (*ctypes.ResultValidators, error) {
+	return c.env.Validators(c.ctx, height, page, perPage, prove)
 }
 
 func (c *Local) Tx(ctx context.Context, hash []byte, prove bool) (*ctypes.ResultTx, error) {
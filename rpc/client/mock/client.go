@@ -174,8 +174,13 @@ func (c Client) Commit(ctx context.Context, height *int64) (*ctypes.ResultCommit
 	return c.env.Commit(&rpctypes.Context{}, height)
 }
 
-func (c Client) Validators(ctx context.Context, height *int64, page, perPage *int) (*ctypes.ResultValidators, error) {
-	return c.env.Validators(&rpctypes.Context{}, height, page, perPage)
+func (c Client) Validators(
+	ctx context.Context,
+	height *int64,
+	page, perPage *int,
+	prove bool,
+) (*ctypes.ResultValidators, error) {
+	return c.env.Validators(&rpctypes.Context{}, height, page, perPage, prove)
 }
 
 func (c Client) BroadcastEvidence(ctx context.Context, ev types.Evidence) (*ctypes.ResultBroadcastEvidence, error) {
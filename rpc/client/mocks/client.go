@@ -802,13 +802,13 @@ func (_m *Client) UnsubscribeAll(ctx context.Context, subscriber string) error {
 	return r0
 }
 
-// Validators provides a mock function with given fields: ctx, height, page, perPage
-func (_m *Client) Validators(ctx context.Context, height *int64, page *int, perPage *int) (*coretypes.ResultValidators, error) {
-	ret := _m.Called(ctx, height, page, perPage)
+// Validators provides a mock function with given fields: ctx, height, page, perPage, prove
+func (_m *Client) Validators(ctx context.Context, height *int64, page *int, perPage *int, prove bool) (*coretypes.ResultValidators, error) {
+	ret := _m.Called(ctx, height, page, perPage, prove)
 
 	var r0 *coretypes.ResultValidators
-	if rf, ok := ret.Get(0).(func(context.Context, *int64, *int, *int) *coretypes.ResultValidators); ok {
-		r0 = rf(ctx, height, page, perPage)
+	if rf, ok := ret.Get(0).(func(context.Context, *int64, *int, *int, bool) *coretypes.ResultValidators); ok {
+		r0 = rf(ctx, height, page, perPage, prove)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*coretypes.ResultValidators)
@@ -816,8 +816,8 @@ func (_m *Client) Validators(ctx context.Context, height *int64, page *int, perP
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *int64, *int, *int) error); ok {
-		r1 = rf(ctx, height, page, perPage)
+	if rf, ok := ret.Get(1).(func(context.Context, *int64, *int, *int, bool) error); ok {
+		r1 = rf(ctx, height, page, perPage, prove)
 	} else {
 		r1 = ret.Error(1)
 	}
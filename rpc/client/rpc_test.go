@@ -16,6 +16,7 @@ import (
 
 	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/crypto/tmhash"
 	mempl "github.com/tendermint/tendermint/internal/mempool"
 	tmjson "github.com/tendermint/tendermint/libs/json"
 	"github.com/tendermint/tendermint/libs/log"
@@ -217,15 +218,23 @@ func TestGenesisAndValidators(t *testing.T) {
 		require.Equal(t, 1, len(gen.Genesis.Validators))
 		gval := gen.Genesis.Validators[0]
 
-		// get the current validators
+		// get the current validators, once the first block they can be proven
+		// against is committed
 		h := int64(1)
-		vals, err := c.Validators(ctx, &h, nil, nil)
+		require.NoError(t, client.WaitForHeight(c, h, nil))
+		vals, err := c.Validators(ctx, &h, nil, nil, true)
 		require.Nil(t, err, "%d: %+v", i, err)
 		require.Equal(t, 1, len(vals.Validators))
 		require.Equal(t, 1, vals.Count)
 		require.Equal(t, 1, vals.Total)
 		val := vals.Validators[0]
 
+		// the set is proven against the header
+		block, err := c.Block(ctx, &h)
+		require.NoError(t, err)
+		require.NotNil(t, vals.Proof)
+		require.NoError(t, vals.Proof.Validate(block.BlockID.Hash, types.NewValidatorSet(vals.Validators)))
+
 		// make sure the current set is also the genesis set
 		assert.Equal(t, gval.Power, val.VotingPower)
 		assert.Equal(t, gval.PubKey, val.PubKey)
@@ -248,6 +257,7 @@ func TestGenesisChunked(t *testing.T) {
 			require.NoError(t, err)
 			data, err := base64.StdEncoding.DecodeString(chunk.Data)
 			require.NoError(t, err)
+			require.EqualValues(t, tmhash.Sum(data), chunk.Hash)
 			decoded = append(decoded, string(data))
 
 		}
@@ -30,7 +30,7 @@ func (env *Environment) BlockchainInfo(
 
 	var err error
 	minHeight, maxHeight, err = filterMinMax(
-		env.BlockStore.Base(),
+		env.getBase(),
 		env.BlockStore.Height(),
 		minHeight,
 		maxHeight,
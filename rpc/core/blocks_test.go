@@ -117,9 +117,15 @@ func TestBlockResults(t *testing.T) {
 
 type mockBlockStore struct {
 	height int64
+	base   int64
 }
 
-func (mockBlockStore) Base() int64                                       { return 1 }
+func (store mockBlockStore) Base() int64 {
+	if store.base == 0 {
+		return 1
+	}
+	return store.base
+}
 func (store mockBlockStore) Height() int64                               { return store.height }
 func (store mockBlockStore) Size() int64                                 { return store.height }
 func (mockBlockStore) LoadBaseMeta() *types.BlockMeta                    { return nil }
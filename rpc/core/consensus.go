@@ -1,10 +1,14 @@
 package core
 
 import (
+	"errors"
+	"fmt"
+
 	cm "github.com/tendermint/tendermint/internal/consensus"
 	tmmath "github.com/tendermint/tendermint/libs/math"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+	sm "github.com/tendermint/tendermint/state"
 	"github.com/tendermint/tendermint/types"
 )
 
@@ -14,23 +18,52 @@ import (
 // validators are sorted by their voting power - this is the canonical order
 // for the validators in the set as used in computing their Merkle root.
 //
+// If prove is true, the result includes a Merkle proof that the hash of the
+// whole validator set, rather than of the page of it returned, is the
+// ValidatorsHash of the header at the height. This is only available for
+// heights that were committed.
+//
 // More: https://docs.tendermint.com/master/rpc/#/Info/validators
 func (env *Environment) Validators(
 	ctx *rpctypes.Context,
 	heightPtr *int64,
-	pagePtr, perPagePtr *int) (*ctypes.ResultValidators, error) {
+	pagePtr, perPagePtr *int,
+	prove bool) (*ctypes.ResultValidators, error) {
 
 	// The latest validator that we know is the NextValidator of the last block.
 	height, err := env.getHeight(env.latestUncommittedHeight(), heightPtr)
+	if errors.Is(err, ctypes.ErrHeightNotAvailable) {
+		return nil, fmt.Errorf("%w: %v", ctypes.ErrValidatorSetPruned, err)
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	validators, err := env.StateStore.LoadValidators(height)
+	if errors.As(err, &sm.ErrNoValSetForHeight{}) {
+		return nil, fmt.Errorf("%w (requested height: %d)", ctypes.ErrValidatorSetPruned, height)
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	var proof *types.ValidatorSetProof
+	if prove {
+		meta := env.BlockStore.LoadBlockMeta(height)
+		if meta == nil {
+			return nil, fmt.Errorf("%w: no header to prove the validator set against (requested height: %d)",
+				ctypes.ErrHeightNotAvailable, height)
+		}
+		p, err := meta.Header.ValidatorSetProof()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.Validate(meta.BlockID.Hash, validators); err != nil {
+			return nil, fmt.Errorf("validator set at height %d doesn't match the header: %w", height, err)
+		}
+		proof = &p
+	}
+
 	totalCount := len(validators.Validators)
 	perPage := env.validatePerPage(perPagePtr)
 	page, err := validatePage(pagePtr, perPage, totalCount)
@@ -46,7 +79,8 @@ func (env *Environment) Validators(
 		BlockHeight: height,
 		Validators:  v,
 		Count:       len(v),
-		Total:       totalCount}, nil
+		Total:       totalCount,
+		Proof:       proof}, nil
 }
 
 // DumpConsensusState dumps consensus state.
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	cm "github.com/tendermint/tendermint/internal/consensus"
+	"github.com/tendermint/tendermint/internal/test/factory"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/state/mocks"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestValidatorsWithProof(t *testing.T) {
+	vals, _ := factory.RandValidatorSet(4, 10)
+	header, err := factory.MakeHeader(&types.Header{Height: 5, ValidatorsHash: vals.Hash()})
+	require.NoError(t, err)
+
+	stateStore := &mocks.Store{}
+	stateStore.On("LoadValidators", int64(5)).Return(vals, nil)
+	stateStore.On("LoadValidators", int64(6)).Return(nil, sm.ErrNoValSetForHeight{Height: 6})
+	env := &Environment{
+		StateStore: stateStore,
+		BlockStore: headerBlockStore{
+			mockBlockStore: mockBlockStore{height: 10},
+			base:           3,
+			metas: map[int64]*types.BlockMeta{
+				5: {BlockID: types.BlockID{Hash: header.Hash()}, Header: *header},
+			},
+		},
+		ConsensusReactor: &cm.Reactor{},
+	}
+
+	// The proof of the set at a retained height verifies against the header.
+	height := int64(5)
+	perPage := 2
+	res, err := env.Validators(&rpctypes.Context{}, &height, nil, &perPage, true)
+	require.NoError(t, err)
+	require.Equal(t, 2, res.Count)
+	require.Equal(t, 4, res.Total)
+	require.NotNil(t, res.Proof)
+	require.NoError(t, res.Proof.Validate(header.Hash(), vals))
+
+	// The proof doesn't verify against another header or another set.
+	other, err := factory.MakeHeader(&types.Header{Height: 5, ValidatorsHash: vals.Hash()})
+	require.NoError(t, err)
+	require.Error(t, res.Proof.Validate(other.Hash(), vals))
+	otherVals, _ := factory.RandValidatorSet(4, 10)
+	require.Error(t, res.Proof.Validate(header.Hash(), otherVals))
+
+	// No proof is returned unless requested.
+	res, err = env.Validators(&rpctypes.Context{}, &height, nil, nil, false)
+	require.NoError(t, err)
+	require.Nil(t, res.Proof)
+
+	// Pruned heights are reported as such.
+	height = 2
+	_, err = env.Validators(&rpctypes.Context{}, &height, nil, nil, true)
+	require.True(t, errors.Is(err, ctypes.ErrValidatorSetPruned), err)
+
+	height = 6
+	_, err = env.Validators(&rpctypes.Context{}, &height, nil, nil, false)
+	require.True(t, errors.Is(err, ctypes.ErrValidatorSetPruned), err)
+}
+
+// headerBlockStore is a mockBlockStore with block metas and a base.
+type headerBlockStore struct {
+	mockBlockStore
+
+	base  int64
+	metas map[int64]*types.BlockMeta
+}
+
+func (store headerBlockStore) Base() int64 { return store.base }
+
+func (store headerBlockStore) LoadBlockMeta(height int64) *types.BlockMeta { return store.metas[height] }
@@ -1,6 +1,10 @@
 package core
 
 import (
+	"errors"
+	"time"
+
+	mempl "github.com/tendermint/tendermint/internal/mempool"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
 )
@@ -10,3 +14,32 @@ func (env *Environment) UnsafeFlushMempool(ctx *rpctypes.Context) (*ctypes.Resul
 	env.Mempool.Flush()
 	return &ctypes.ResultUnsafeFlushMempool{}, nil
 }
+
+// UnsafeSetMempoolPaused pauses the mempool, or resumes it if paused is false.
+// While paused, new transactions are rejected with the mempool codespace and
+// mempool.CodeTypeUnavailable, e.g. to relieve an overloaded application.
+// Transactions already in the mempool are unaffected.
+func (env *Environment) UnsafeSetMempoolPaused(
+	ctx *rpctypes.Context,
+	paused bool,
+) (*ctypes.ResultUnsafeSetMempoolPaused, error) {
+	pauser, ok := env.Mempool.(mempl.Pauser)
+	if !ok {
+		return nil, errors.New("mempool can not be paused")
+	}
+	pauser.SetPaused(paused)
+	return &ctypes.ResultUnsafeSetMempoolPaused{Paused: pauser.Paused()}, nil
+}
+
+// UnsafeSetTimeoutCommit overrides the consensus timeout commit, given in
+// nanoseconds. The new value takes effect from the next height on.
+func (env *Environment) UnsafeSetTimeoutCommit(
+	ctx *rpctypes.Context,
+	timeoutCommit time.Duration,
+) (*ctypes.ResultUnsafeSetTimeoutCommit, error) {
+	height, err := env.ConsensusState.SetTimeoutCommit(timeoutCommit)
+	if err != nil {
+		return nil, err
+	}
+	return &ctypes.ResultUnsafeSetTimeoutCommit{Height: height}, nil
+}
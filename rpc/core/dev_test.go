@@ -0,0 +1,69 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/internal/mempool/mock"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+)
+
+func TestUnsafeSetTimeoutCommit(t *testing.T) {
+	cs := &mockConsensus{height: 10}
+	env := &Environment{ConsensusState: cs}
+
+	res, err := env.UnsafeSetTimeoutCommit(&rpctypes.Context{}, 500*time.Millisecond)
+	require.NoError(t, err)
+	require.EqualValues(t, 11, res.Height)
+	require.Equal(t, 500*time.Millisecond, cs.timeoutCommit)
+
+	_, err = env.UnsafeSetTimeoutCommit(&rpctypes.Context{}, -time.Second)
+	require.Error(t, err)
+	require.Equal(t, 500*time.Millisecond, cs.timeoutCommit)
+}
+
+func TestUnsafeSetMempoolPaused(t *testing.T) {
+	mp := &pausableMempool{}
+	env := &Environment{Mempool: mp}
+
+	res, err := env.UnsafeSetMempoolPaused(&rpctypes.Context{}, true)
+	require.NoError(t, err)
+	require.True(t, res.Paused)
+	require.True(t, mp.paused)
+
+	res, err = env.UnsafeSetMempoolPaused(&rpctypes.Context{}, false)
+	require.NoError(t, err)
+	require.False(t, res.Paused)
+	require.False(t, mp.paused)
+
+	env.Mempool = mock.Mempool{}
+	_, err = env.UnsafeSetMempoolPaused(&rpctypes.Context{}, true)
+	require.Error(t, err)
+}
+
+type mockConsensus struct {
+	Consensus
+
+	height        int64
+	timeoutCommit time.Duration
+}
+
+func (cs *mockConsensus) SetTimeoutCommit(timeoutCommit time.Duration) (int64, error) {
+	if timeoutCommit < 0 {
+		return 0, errors.New("negative timeout commit")
+	}
+	cs.timeoutCommit = timeoutCommit
+	return cs.height + 1, nil
+}
+
+type pausableMempool struct {
+	mock.Mempool
+
+	paused bool
+}
+
+func (mp *pausableMempool) SetPaused(paused bool) { mp.paused = paused }
+func (mp *pausableMempool) Paused() bool          { return mp.paused }
@@ -17,6 +17,7 @@ curl 'localhost:26657'
 ```plain
 Available endpoints:
 /abci_info
+/dump_backfill_queue
 /dump_consensus_state
 /genesis
 /net_info
@@ -24,6 +25,7 @@ Available endpoints:
 /status
 /health
 /unconfirmed_txs
+/unsafe_export_address_book
 /unsafe_flush_mempool
 /validators
 
@@ -39,7 +41,13 @@ Endpoints that require arguments:
 /dial_persistent_peers?persistent_peers=_
 /subscribe?event=_
 /tx?hash=_&prove=_
+/tx_rejection?hash=_
+/unconfirmed_txs_by_sender?sender=_&page=_&per_page=_
 /unsubscribe?event=_
+/unsafe_dial_peer?id=_&address=_
+/unsafe_import_address_book?address_book=_
+/unsafe_set_mempool_paused?paused=_
+/unsafe_set_timeout_commit?timeout_commit=_
 ```
 */
 package core
@@ -1,15 +1,19 @@
 package core
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"time"
 
 	cfg "github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/tmhash"
 	"github.com/tendermint/tendermint/internal/consensus"
 	mempl "github.com/tendermint/tendermint/internal/mempool"
 	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/internal/statesync"
+	tmbytes "github.com/tendermint/tendermint/libs/bytes"
 	tmjson "github.com/tendermint/tendermint/libs/json"
 	"github.com/tendermint/tendermint/libs/log"
 	"github.com/tendermint/tendermint/proxy"
@@ -28,6 +32,10 @@ const (
 	// must be less than the server's write timeout (see rpcserver.DefaultConfig)
 	SubscribeTimeout = 5 * time.Second
 
+	// DialPeerTimeout is the maximum time we wait for a peer to be redialed.
+	// must be less than the server's write timeout (see rpcserver.DefaultConfig)
+	DialPeerTimeout = 5 * time.Second
+
 	// genesisChunkSize is the maximum size, in bytes, of each
 	// chunk in the genesis structure for the chunked API
 	genesisChunkSize = 16 * 1024 * 1024 // 16
@@ -42,6 +50,7 @@ type Consensus interface {
 	GetLastHeight() int64
 	GetRoundStateJSON() ([]byte, error)
 	GetRoundStateSimpleJSON() ([]byte, error)
+	SetTimeoutCommit(time.Duration) (int64, error)
 }
 
 type transport interface {
@@ -58,6 +67,17 @@ type peers interface {
 	Peers() p2p.IPeerSet
 }
 
+type peerManager interface {
+	Redial(context.Context, p2p.NodeAddress) (<-chan error, error)
+	ExportAddresses() ([]byte, error)
+	ImportAddresses([]byte) (int, error)
+}
+
+type stateSync interface {
+	BackfillQueue() (statesync.BlockQueueSnapshot, bool)
+	ServableBase() (int64, bool)
+}
+
 //----------------------------------------------
 // Environment contains objects and interfaces used by the RPC. It is expected
 // to be setup once during startup.
@@ -73,6 +93,8 @@ type Environment struct {
 	ConsensusState Consensus
 	P2PPeers       peers
 	P2PTransport   transport
+	PeerManager    peerManager // nil unless the legacy p2p stack is disabled
+	StateSync      stateSync
 
 	// objects
 	PubKey           crypto.PubKey
@@ -86,8 +108,9 @@ type Environment struct {
 
 	Config cfg.RPCConfig
 
-	// cache of chunked genesis data.
-	genChunks []string
+	// cache of chunked genesis data, and the hash of each chunk.
+	genChunks      []string
+	genChunkHashes []tmbytes.HexBytes
 }
 
 //----------------------------------------------
@@ -133,6 +156,10 @@ func (env *Environment) validatePerPage(perPagePtr *int) int {
 // InitGenesisChunks configures the environment and should be called on service
 // startup.
 func (env *Environment) InitGenesisChunks() error {
+	return env.initGenesisChunks(genesisChunkSize)
+}
+
+func (env *Environment) initGenesisChunks(chunkSize int) error {
 	if env.genChunks != nil {
 		return nil
 	}
@@ -146,14 +173,15 @@ func (env *Environment) InitGenesisChunks() error {
 		return err
 	}
 
-	for i := 0; i < len(data); i += genesisChunkSize {
-		end := i + genesisChunkSize
+	for i := 0; i < len(data); i += chunkSize {
+		end := i + chunkSize
 
 		if end > len(data) {
 			end = len(data)
 		}
 
 		env.genChunks = append(env.genChunks, base64.StdEncoding.EncodeToString(data[i:end]))
+		env.genChunkHashes = append(env.genChunkHashes, tmhash.Sum(data[i:end]))
 	}
 
 	return nil
@@ -179,7 +207,7 @@ func (env *Environment) getHeight(latestHeight int64, heightPtr *int64) (int64,
 			return 0, fmt.Errorf("%w (requested height: %d, blockchain height: %d)",
 				ctypes.ErrHeightExceedsChainHead, height, latestHeight)
 		}
-		base := env.BlockStore.Base()
+		base := env.getBase()
 		if height < base {
 			return 0, fmt.Errorf("%w (requested height: %d, base height: %d)", ctypes.ErrHeightNotAvailable, height, base)
 		}
@@ -188,6 +216,20 @@ func (env *Environment) getHeight(latestHeight int64, heightPtr *int64) (int64,
 	return latestHeight, nil
 }
 
+// getBase returns the lowest height blocks are served from: the base of the
+// block store, or the servable base of the background backfill in progress
+// if it's higher, as the blocks below it aren't verified yet.
+func (env *Environment) getBase() int64 {
+	base := env.BlockStore.Base()
+	if env.StateSync == nil {
+		return base
+	}
+	if servableBase, ok := env.StateSync.ServableBase(); ok && servableBase > base {
+		return servableBase
+	}
+	return base
+}
+
 func (env *Environment) latestUncommittedHeight() int64 {
 	nodeIsSyncing := env.ConsensusReactor.WaitSync()
 	if nodeIsSyncing {
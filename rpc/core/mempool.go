@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	abci "github.com/tendermint/tendermint/abci/types"
 	mempl "github.com/tendermint/tendermint/internal/mempool"
+	tmmath "github.com/tendermint/tendermint/libs/math"
 	tmpubsub "github.com/tendermint/tendermint/libs/pubsub"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
@@ -177,3 +179,100 @@ func (env *Environment) CheckTx(ctx *rpctypes.Context, tx types.Tx) (*ctypes.Res
 	}
 	return &ctypes.ResultCheckTx{ResponseCheckTx: *res}, nil
 }
+
+// UnconfirmedTxsBySender returns the unconfirmed transactions of the given
+// sender, as attributed by the application, in the order they were received.
+// If no sender is given, it returns the number of unconfirmed transactions of
+// each sender instead, most first, to find out who is flooding the mempool.
+// Transactions the application doesn't attribute to a sender are reported
+// under the "unknown" sender. Both are paginated.
+// More: https://docs.tendermint.com/master/rpc/#/Info/unconfirmed_txs_by_sender
+func (env *Environment) UnconfirmedTxsBySender(
+	ctx *rpctypes.Context,
+	sender string,
+	pagePtr, perPagePtr *int,
+) (*ctypes.ResultUnconfirmedTxsBySender, error) {
+	index, ok := env.Mempool.(mempl.SenderIndex)
+	if !ok {
+		return nil, errors.New("mempool does not index transactions by sender")
+	}
+	txsBySender := index.TxsBySender()
+
+	if sender != "" {
+		txs := txsBySender[sender]
+
+		totalCount := len(txs)
+		perPage := env.validatePerPage(perPagePtr)
+		page, err := validatePage(pagePtr, perPage, totalCount)
+		if err != nil {
+			return nil, err
+		}
+		skipCount := validateSkipCount(page, perPage)
+		txs = txs[skipCount : skipCount+tmmath.MinInt(perPage, totalCount-skipCount)]
+
+		return &ctypes.ResultUnconfirmedTxsBySender{
+			Sender: sender,
+			Txs:    txs,
+			Count:  len(txs),
+			Total:  totalCount,
+		}, nil
+	}
+
+	senders := make([]ctypes.SenderTxCount, 0, len(txsBySender))
+	for sender, txs := range txsBySender {
+		senders = append(senders, ctypes.SenderTxCount{Sender: sender, Count: len(txs)})
+	}
+	sort.Slice(senders, func(i, j int) bool {
+		if senders[i].Count != senders[j].Count {
+			return senders[i].Count > senders[j].Count
+		}
+		return senders[i].Sender < senders[j].Sender
+	})
+
+	totalCount := len(senders)
+	perPage := env.validatePerPage(perPagePtr)
+	page, err := validatePage(pagePtr, perPage, totalCount)
+	if err != nil {
+		return nil, err
+	}
+	skipCount := validateSkipCount(page, perPage)
+	senders = senders[skipCount : skipCount+tmmath.MinInt(perPage, totalCount-skipCount)]
+
+	return &ctypes.ResultUnconfirmedTxsBySender{
+		Senders: senders,
+		Count:   len(senders),
+		Total:   totalCount,
+	}, nil
+}
+
+// TxRejection returns the reason the transaction with the given hash was last
+// rejected from the mempool, or removed from it without being committed. Only
+// recent rejections are retained, and only if mempool.rejection-cache-size is
+// set.
+// More: https://docs.tendermint.com/master/rpc/#/Info/tx_rejection
+func (env *Environment) TxRejection(ctx *rpctypes.Context, hash []byte) (*ctypes.ResultTxRejection, error) {
+	reporter, ok := env.Mempool.(mempl.RejectionReporter)
+	if !ok {
+		return nil, errors.New("mempool does not retain rejections")
+	}
+
+	if len(hash) != mempl.TxKeySize {
+		return nil, fmt.Errorf("invalid tx hash length %d, expected %d", len(hash), mempl.TxKeySize)
+	}
+	var key [mempl.TxKeySize]byte
+	copy(key[:], hash)
+
+	rejection, ok := reporter.TxRejection(key)
+	if !ok {
+		return nil, fmt.Errorf("no rejection found for tx %X", hash)
+	}
+
+	return &ctypes.ResultTxRejection{
+		Hash:   hash,
+		Reason: string(rejection.Reason),
+		Code:   rejection.Code,
+		Log:    rejection.Log,
+		Height: rejection.Height,
+		Time:   rejection.Time,
+	}, nil
+}
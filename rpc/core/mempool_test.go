@@ -0,0 +1,132 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	mempl "github.com/tendermint/tendermint/internal/mempool"
+	"github.com/tendermint/tendermint/internal/mempool/mock"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestTxRejection(t *testing.T) {
+	tx := types.Tx("rejected")
+	rejection := mempl.TxRejection{
+		Reason: mempl.RejectedEvicted,
+		Log:    "evicted",
+		Height: 5,
+		Time:   time.Now(),
+	}
+	mp := &rejectingMempool{rejections: map[[mempl.TxKeySize]byte]mempl.TxRejection{
+		mempl.TxKey(tx): rejection,
+	}}
+	env := &Environment{Mempool: mp}
+
+	res, err := env.TxRejection(&rpctypes.Context{}, tx.Hash())
+	require.NoError(t, err)
+	require.EqualValues(t, tx.Hash(), res.Hash)
+	require.Equal(t, "evicted", res.Reason)
+	require.Equal(t, rejection.Log, res.Log)
+	require.Equal(t, rejection.Height, res.Height)
+	require.Equal(t, rejection.Time, res.Time)
+
+	_, err = env.TxRejection(&rpctypes.Context{}, types.Tx("other").Hash())
+	require.Error(t, err)
+
+	_, err = env.TxRejection(&rpctypes.Context{}, []byte{1, 2, 3})
+	require.Error(t, err)
+
+	// mempools that do not retain rejections are reported as such
+	env = &Environment{Mempool: mock.Mempool{}}
+	_, err = env.TxRejection(&rpctypes.Context{}, tx.Hash())
+	require.Error(t, err)
+}
+
+func TestUnconfirmedTxsBySender(t *testing.T) {
+	alice := types.Txs{types.Tx("alice1"), types.Tx("alice2"), types.Tx("alice3")}
+	bob := types.Txs{types.Tx("bob1")}
+	unknown := types.Txs{types.Tx("unknown1"), types.Tx("unknown2")}
+	mp := &indexedMempool{txs: map[string]types.Txs{
+		"alice":             alice,
+		"bob":               bob,
+		mempl.UnknownSender: unknown,
+	}}
+	env := &Environment{Mempool: mp}
+	intPtr := func(i int) *int { return &i }
+
+	// the transactions of a sender are paginated
+	res, err := env.UnconfirmedTxsBySender(&rpctypes.Context{}, "alice", intPtr(1), intPtr(2))
+	require.NoError(t, err)
+	require.Equal(t, "alice", res.Sender)
+	require.Equal(t, []types.Tx(alice[:2]), res.Txs)
+	require.Equal(t, 2, res.Count)
+	require.Equal(t, 3, res.Total)
+
+	res, err = env.UnconfirmedTxsBySender(&rpctypes.Context{}, "alice", intPtr(2), intPtr(2))
+	require.NoError(t, err)
+	require.Equal(t, []types.Tx(alice[2:]), res.Txs)
+	require.Equal(t, 1, res.Count)
+	require.Equal(t, 3, res.Total)
+
+	_, err = env.UnconfirmedTxsBySender(&rpctypes.Context{}, "alice", intPtr(3), intPtr(2))
+	require.Error(t, err)
+
+	// unattributed transactions are under the unknown sender
+	res, err = env.UnconfirmedTxsBySender(&rpctypes.Context{}, mempl.UnknownSender, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, []types.Tx(unknown), res.Txs)
+
+	res, err = env.UnconfirmedTxsBySender(&rpctypes.Context{}, "carol", nil, nil)
+	require.NoError(t, err)
+	require.Empty(t, res.Txs)
+	require.Zero(t, res.Total)
+
+	// without a sender, the senders are listed by their number of
+	// transactions, most first
+	res, err = env.UnconfirmedTxsBySender(&rpctypes.Context{}, "", nil, nil)
+	require.NoError(t, err)
+	require.Empty(t, res.Txs)
+	require.Equal(t, []ctypes.SenderTxCount{
+		{Sender: "alice", Count: 3},
+		{Sender: mempl.UnknownSender, Count: 2},
+		{Sender: "bob", Count: 1},
+	}, res.Senders)
+	require.Equal(t, 3, res.Count)
+	require.Equal(t, 3, res.Total)
+
+	res, err = env.UnconfirmedTxsBySender(&rpctypes.Context{}, "", intPtr(2), intPtr(2))
+	require.NoError(t, err)
+	require.Equal(t, []ctypes.SenderTxCount{{Sender: "bob", Count: 1}}, res.Senders)
+	require.Equal(t, 1, res.Count)
+	require.Equal(t, 3, res.Total)
+
+	// mempools that do not index transactions by sender are reported as such
+	env = &Environment{Mempool: mock.Mempool{}}
+	_, err = env.UnconfirmedTxsBySender(&rpctypes.Context{}, "alice", nil, nil)
+	require.Error(t, err)
+}
+
+type indexedMempool struct {
+	mock.Mempool
+
+	txs map[string]types.Txs
+}
+
+func (mp *indexedMempool) TxsBySender() map[string]types.Txs {
+	return mp.txs
+}
+
+type rejectingMempool struct {
+	mock.Mempool
+
+	rejections map[[mempl.TxKeySize]byte]mempl.TxRejection
+}
+
+func (mp *rejectingMempool) TxRejection(key [mempl.TxKeySize]byte) (mempl.TxRejection, bool) {
+	rejection, ok := mp.rejections[key]
+	return rejection, ok
+}
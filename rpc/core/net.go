@@ -1,9 +1,11 @@
 package core
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/tendermint/tendermint/internal/p2p"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
@@ -90,6 +92,103 @@ func (env *Environment) UnsafeDialPeers(
 	return &ctypes.ResultDialPeers{Log: "Dialing peers in progress. See /net_info for details"}, nil
 }
 
+// UnsafeDialPeer drops any connection to the peer with the given node ID and
+// dials it again at the given address (host:port, optionally prefixed by a
+// protocol such as mconn://), adding the address if it isn't known yet. It
+// waits up to DialPeerTimeout for the peer to be dialed, returning the error
+// if the dial fails.
+func (env *Environment) UnsafeDialPeer(
+	ctx *rpctypes.Context,
+	id, address string) (*ctypes.ResultUnsafeDialPeer, error) {
+
+	if env.PeerManager == nil {
+		return &ctypes.ResultUnsafeDialPeer{}, errors.New("redialing requires the legacy p2p stack to be disabled")
+	}
+
+	nodeID, err := p2p.NewNodeID(id)
+	if err != nil {
+		return &ctypes.ResultUnsafeDialPeer{}, fmt.Errorf("%w: invalid node ID %q: %v", ctypes.ErrInvalidRequest, id, err)
+	}
+
+	nodeAddress, err := parsePeerAddress(nodeID, address)
+	if err != nil {
+		return &ctypes.ResultUnsafeDialPeer{}, fmt.Errorf("%w: %v", ctypes.ErrInvalidRequest, err)
+	}
+
+	env.Logger.Info("DialPeer", "peer", nodeAddress)
+	dialCtx, cancel := context.WithCancel(ctx.Context())
+	defer cancel()
+	result, err := env.PeerManager.Redial(dialCtx, nodeAddress)
+	if err != nil {
+		return &ctypes.ResultUnsafeDialPeer{}, err
+	}
+
+	timer := time.NewTimer(DialPeerTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-result:
+		if err != nil {
+			return &ctypes.ResultUnsafeDialPeer{}, err
+		}
+		return &ctypes.ResultUnsafeDialPeer{Log: "Dialed peer"}, nil
+	case <-timer.C:
+		return &ctypes.ResultUnsafeDialPeer{}, fmt.Errorf("timed out waiting for peer %v to be dialed", nodeID)
+	case <-ctx.Context().Done():
+		return &ctypes.ResultUnsafeDialPeer{}, ctx.Context().Err()
+	}
+}
+
+// UnsafeExportAddressBook returns the peer addresses that have been dialed
+// successfully, with their dial statistics, for UnsafeImportAddressBook to
+// seed the address book of another node with.
+func (env *Environment) UnsafeExportAddressBook(ctx *rpctypes.Context) (*ctypes.ResultUnsafeExportAddressBook, error) {
+	if env.PeerManager == nil {
+		return nil, errors.New("exporting the address book requires the legacy p2p stack to be disabled")
+	}
+
+	addressBook, err := env.PeerManager.ExportAddresses()
+	if err != nil {
+		return nil, err
+	}
+	return &ctypes.ResultUnsafeExportAddressBook{AddressBook: addressBook}, nil
+}
+
+// UnsafeImportAddressBook adds the peer addresses exported by
+// UnsafeExportAddressBook to the address book, keeping the known ones as they
+// are. The imported addresses are only trusted once dialed successfully.
+func (env *Environment) UnsafeImportAddressBook(
+	ctx *rpctypes.Context,
+	addressBook []byte,
+) (*ctypes.ResultUnsafeImportAddressBook, error) {
+	if env.PeerManager == nil {
+		return nil, errors.New("importing an address book requires the legacy p2p stack to be disabled")
+	}
+
+	added, err := env.PeerManager.ImportAddresses(addressBook)
+	if err != nil {
+		return nil, err
+	}
+	env.Logger.Info("ImportAddressBook", "added", added)
+	return &ctypes.ResultUnsafeImportAddressBook{Added: added}, nil
+}
+
+// parsePeerAddress parses the address of the peer with the given node ID. The
+// address must not contain a node ID of its own.
+func parsePeerAddress(nodeID p2p.NodeID, address string) (p2p.NodeAddress, error) {
+	if address == "" {
+		return p2p.NodeAddress{}, errors.New("no address provided")
+	}
+	if strings.Contains(address, "@") {
+		return p2p.NodeAddress{}, fmt.Errorf("address %q must not contain a node ID", address)
+	}
+
+	url := string(nodeID) + "@" + address
+	if i := strings.Index(address, "://"); i >= 0 {
+		url = address[:i+3] + string(nodeID) + "@" + address[i+3:]
+	}
+	return p2p.ParseNodeAddress(url)
+}
+
 // Genesis returns genesis file.
 // More: https://docs.tendermint.com/master/rpc/#/Info/genesis
 func (env *Environment) Genesis(ctx *rpctypes.Context) (*ctypes.ResultGenesis, error) {
@@ -100,6 +199,9 @@ func (env *Environment) Genesis(ctx *rpctypes.Context) (*ctypes.ResultGenesis, e
 	return &ctypes.ResultGenesis{Genesis: env.GenDoc}, nil
 }
 
+// GenesisChunked returns the chunk with the given index of the genesis file,
+// along with the total number of chunks and the hash of the chunk. Chunks may
+// be retrieved in any order.
 func (env *Environment) GenesisChunked(ctx *rpctypes.Context, chunk uint) (*ctypes.ResultGenesisChunk, error) {
 	if env.genChunks == nil {
 		return nil, fmt.Errorf("service configuration error, genesis chunks are not initialized")
@@ -112,13 +214,14 @@ func (env *Environment) GenesisChunked(ctx *rpctypes.Context, chunk uint) (*ctyp
 	id := int(chunk)
 
 	if id > len(env.genChunks)-1 {
-		return nil, fmt.Errorf("there are %d chunks, %d is invalid", len(env.genChunks)-1, id)
+		return nil, fmt.Errorf("there are %d chunks, %d is invalid", len(env.genChunks), id)
 	}
 
 	return &ctypes.ResultGenesisChunk{
 		TotalChunks: len(env.genChunks),
 		ChunkNumber: id,
 		Data:        env.genChunks[id],
+		Hash:        env.genChunkHashes[id],
 	}, nil
 }
 
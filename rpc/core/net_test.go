@@ -1,15 +1,26 @@
 package core
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
 
 	cfg "github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/crypto/tmhash"
 	"github.com/tendermint/tendermint/internal/p2p"
+	tmjson "github.com/tendermint/tendermint/libs/json"
 	"github.com/tendermint/tendermint/libs/log"
 	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+	"github.com/tendermint/tendermint/types"
 )
 
 func TestUnsafeDialSeeds(t *testing.T) {
@@ -87,3 +98,166 @@ func TestUnsafeDialPeers(t *testing.T) {
 		}
 	}
 }
+
+func TestUnsafeDialPeer(t *testing.T) {
+	peerManager := &redialPeerManager{}
+	env := &Environment{PeerManager: peerManager}
+	env.Logger = log.TestingLogger()
+
+	testCases := map[string]struct {
+		id       string
+		address  string
+		expected p2p.NodeAddress
+		isErr    bool
+	}{
+		"valid": {
+			id:      "d51fb70907db1c6c2d5237e78379b25cf1a37ab4",
+			address: "127.0.0.1:41198",
+			expected: p2p.NodeAddress{
+				Protocol: "mconn",
+				NodeID:   "d51fb70907db1c6c2d5237e78379b25cf1a37ab4",
+				Hostname: "127.0.0.1",
+				Port:     41198,
+			},
+		},
+		"valid with protocol": {
+			id:      "D51FB70907DB1C6C2D5237E78379B25CF1A37AB4",
+			address: "tcp://127.0.0.1:41198",
+			expected: p2p.NodeAddress{
+				Protocol: "tcp",
+				NodeID:   "d51fb70907db1c6c2d5237e78379b25cf1a37ab4",
+				Hostname: "127.0.0.1",
+				Port:     41198,
+			},
+		},
+		"malformed id":       {id: "d51fb70907db1c6c2d5237e78379b25cf1a37a", address: "127.0.0.1:41198", isErr: true},
+		"non-hex id":         {id: strings.Repeat("z", 40), address: "127.0.0.1:41198", isErr: true},
+		"no address":         {id: "d51fb70907db1c6c2d5237e78379b25cf1a37ab4", isErr: true},
+		"address with an id": {id: "d51fb70907db1c6c2d5237e78379b25cf1a37ab4", address: "a@127.0.0.1:41198", isErr: true},
+	}
+
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			peerManager.redialed = nil
+
+			res, err := env.UnsafeDialPeer(&rpctypes.Context{}, tc.id, tc.address)
+			if tc.isErr {
+				require.Error(t, err)
+				require.Empty(t, peerManager.redialed)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, res)
+			require.Equal(t, []p2p.NodeAddress{tc.expected}, peerManager.redialed)
+		})
+	}
+
+	// a failed dial is reported
+	peerManager.dialErr = errors.New("connection refused")
+	_, err := env.UnsafeDialPeer(&rpctypes.Context{}, "d51fb70907db1c6c2d5237e78379b25cf1a37ab4", "127.0.0.1:41198")
+	require.Equal(t, peerManager.dialErr, err)
+
+	env.PeerManager = nil
+	_, err = env.UnsafeDialPeer(&rpctypes.Context{}, "d51fb70907db1c6c2d5237e78379b25cf1a37ab4", "127.0.0.1:41198")
+	require.Error(t, err)
+}
+
+type redialPeerManager struct {
+	redialed []p2p.NodeAddress
+	dialErr  error
+}
+
+func (m *redialPeerManager) Redial(_ context.Context, address p2p.NodeAddress) (<-chan error, error) {
+	m.redialed = append(m.redialed, address)
+	result := make(chan error, 1)
+	result <- m.dialErr
+	return result, nil
+}
+
+func (m *redialPeerManager) ExportAddresses() ([]byte, error) { return nil, nil }
+
+func (m *redialPeerManager) ImportAddresses([]byte) (int, error) { return 0, nil }
+
+func TestUnsafeExportImportAddressBook(t *testing.T) {
+	selfID := p2p.NodeID(strings.Repeat("0", 40))
+	address := p2p.NodeAddress{Protocol: "memory", NodeID: p2p.NodeID(strings.Repeat("a", 40))}
+
+	source, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	defer source.Close()
+	_, err = source.Add(address)
+	require.NoError(t, err)
+	dial, err := source.TryDialNext()
+	require.NoError(t, err)
+	require.NoError(t, source.Dialed(dial))
+
+	target, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	defer target.Close()
+
+	env := &Environment{PeerManager: source, Logger: log.TestingLogger()}
+	exported, err := env.UnsafeExportAddressBook(&rpctypes.Context{})
+	require.NoError(t, err)
+	require.NotEmpty(t, exported.AddressBook)
+
+	env.PeerManager = target
+	imported, err := env.UnsafeImportAddressBook(&rpctypes.Context{}, exported.AddressBook)
+	require.NoError(t, err)
+	require.Equal(t, 1, imported.Added)
+	require.Equal(t, []p2p.NodeAddress{address}, target.Addresses(address.NodeID))
+
+	_, err = env.UnsafeImportAddressBook(&rpctypes.Context{}, []byte{0xff})
+	require.Error(t, err)
+
+	env.PeerManager = nil
+	_, err = env.UnsafeExportAddressBook(&rpctypes.Context{})
+	require.Error(t, err)
+	_, err = env.UnsafeImportAddressBook(&rpctypes.Context{}, exported.AddressBook)
+	require.Error(t, err)
+}
+
+func TestGenesisChunked(t *testing.T) {
+	genDoc := &types.GenesisDoc{
+		ChainID:       "test-chain",
+		GenesisTime:   time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		InitialHeight: 1,
+		AppState:      json.RawMessage(`{"accounts":["` + strings.Repeat("a", 1000) + `"]}`),
+	}
+	env := &Environment{GenDoc: genDoc}
+	require.NoError(t, env.initGenesisChunks(64))
+
+	first, err := env.GenesisChunked(&rpctypes.Context{}, 0)
+	require.NoError(t, err)
+	require.Greater(t, first.TotalChunks, 10)
+
+	// Retrieve the chunks in reverse order, verifying each of them.
+	chunks := make([][]byte, first.TotalChunks)
+	for i := first.TotalChunks - 1; i >= 0; i-- {
+		chunk, err := env.GenesisChunked(&rpctypes.Context{}, uint(i))
+		require.NoError(t, err)
+		require.Equal(t, i, chunk.ChunkNumber)
+		require.Equal(t, first.TotalChunks, chunk.TotalChunks)
+
+		data, err := base64.StdEncoding.DecodeString(chunk.Data)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(data), 64)
+		require.EqualValues(t, tmhash.Sum(data), chunk.Hash)
+		chunks[i] = data
+	}
+
+	var out types.GenesisDoc
+	require.NoError(t, tmjson.Unmarshal(bytes.Join(chunks, nil), &out))
+	require.Equal(t, genDoc.ChainID, out.ChainID)
+	require.Equal(t, genDoc.AppState, out.AppState)
+	expect, err := tmjson.Marshal(genDoc)
+	require.NoError(t, err)
+	require.Equal(t, expect, bytes.Join(chunks, nil))
+
+	_, err = env.GenesisChunked(&rpctypes.Context{}, uint(first.TotalChunks))
+	require.Error(t, err)
+
+	// The full genesis is too large to be returned at once.
+	_, err = env.Genesis(&rpctypes.Context{})
+	require.Error(t, err)
+}
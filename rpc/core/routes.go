@@ -17,26 +17,29 @@ func (env *Environment) GetRoutes() RoutesMap {
 		"unsubscribe_all": rpc.NewWSRPCFunc(env.UnsubscribeAll, ""),
 
 		// info API
-		"health":               rpc.NewRPCFunc(env.Health, "", false),
-		"status":               rpc.NewRPCFunc(env.Status, "", false),
-		"net_info":             rpc.NewRPCFunc(env.NetInfo, "", false),
-		"blockchain":           rpc.NewRPCFunc(env.BlockchainInfo, "minHeight,maxHeight", true),
-		"genesis":              rpc.NewRPCFunc(env.Genesis, "", true),
-		"genesis_chunked":      rpc.NewRPCFunc(env.GenesisChunked, "chunk", true),
-		"block":                rpc.NewRPCFunc(env.Block, "height", true),
-		"block_by_hash":        rpc.NewRPCFunc(env.BlockByHash, "hash", true),
-		"block_results":        rpc.NewRPCFunc(env.BlockResults, "height", true),
-		"commit":               rpc.NewRPCFunc(env.Commit, "height", true),
-		"check_tx":             rpc.NewRPCFunc(env.CheckTx, "tx", true),
-		"tx":                   rpc.NewRPCFunc(env.Tx, "hash,prove", true),
-		"tx_search":            rpc.NewRPCFunc(env.TxSearch, "query,prove,page,per_page,order_by", false),
-		"block_search":         rpc.NewRPCFunc(env.BlockSearch, "query,page,per_page,order_by", false),
-		"validators":           rpc.NewRPCFunc(env.Validators, "height,page,per_page", true),
-		"dump_consensus_state": rpc.NewRPCFunc(env.DumpConsensusState, "", false),
-		"consensus_state":      rpc.NewRPCFunc(env.GetConsensusState, "", false),
-		"consensus_params":     rpc.NewRPCFunc(env.ConsensusParams, "height", true),
-		"unconfirmed_txs":      rpc.NewRPCFunc(env.UnconfirmedTxs, "limit", false),
-		"num_unconfirmed_txs":  rpc.NewRPCFunc(env.NumUnconfirmedTxs, "", false),
+		"health":                    rpc.NewRPCFunc(env.Health, "", false),
+		"status":                    rpc.NewRPCFunc(env.Status, "", false),
+		"net_info":                  rpc.NewRPCFunc(env.NetInfo, "", false),
+		"blockchain":                rpc.NewRPCFunc(env.BlockchainInfo, "minHeight,maxHeight", true),
+		"genesis":                   rpc.NewRPCFunc(env.Genesis, "", true),
+		"genesis_chunked":           rpc.NewRPCFunc(env.GenesisChunked, "chunk", true),
+		"block":                     rpc.NewRPCFunc(env.Block, "height", true),
+		"block_by_hash":             rpc.NewRPCFunc(env.BlockByHash, "hash", true),
+		"block_results":             rpc.NewRPCFunc(env.BlockResults, "height", true),
+		"commit":                    rpc.NewRPCFunc(env.Commit, "height", true),
+		"check_tx":                  rpc.NewRPCFunc(env.CheckTx, "tx", true),
+		"tx":                        rpc.NewRPCFunc(env.Tx, "hash,prove", true),
+		"tx_search":                 rpc.NewRPCFunc(env.TxSearch, "query,prove,page,per_page,order_by", false),
+		"block_search":              rpc.NewRPCFunc(env.BlockSearch, "query,page,per_page,order_by", false),
+		"validators":                rpc.NewRPCFunc(env.Validators, "height,page,per_page,prove", true),
+		"dump_consensus_state":      rpc.NewRPCFunc(env.DumpConsensusState, "", false),
+		"dump_backfill_queue":       rpc.NewRPCFunc(env.DumpBackfillQueue, "", false),
+		"consensus_state":           rpc.NewRPCFunc(env.GetConsensusState, "", false),
+		"consensus_params":          rpc.NewRPCFunc(env.ConsensusParams, "height", true),
+		"unconfirmed_txs":           rpc.NewRPCFunc(env.UnconfirmedTxs, "limit", false),
+		"num_unconfirmed_txs":       rpc.NewRPCFunc(env.NumUnconfirmedTxs, "", false),
+		"tx_rejection":              rpc.NewRPCFunc(env.TxRejection, "hash", false),
+		"unconfirmed_txs_by_sender": rpc.NewRPCFunc(env.UnconfirmedTxsBySender, "sender,page,per_page", false),
 
 		// tx broadcast API
 		"broadcast_tx_commit": rpc.NewRPCFunc(env.BroadcastTxCommit, "tx", false),
@@ -57,5 +60,10 @@ func (env *Environment) AddUnsafe(routes RoutesMap) {
 	// control API
 	routes["dial_seeds"] = rpc.NewRPCFunc(env.UnsafeDialSeeds, "seeds", false)
 	routes["dial_peers"] = rpc.NewRPCFunc(env.UnsafeDialPeers, "peers,persistent,unconditional,private", false)
+	routes["unsafe_dial_peer"] = rpc.NewRPCFunc(env.UnsafeDialPeer, "id,address", false)
+	routes["unsafe_export_address_book"] = rpc.NewRPCFunc(env.UnsafeExportAddressBook, "", false)
+	routes["unsafe_import_address_book"] = rpc.NewRPCFunc(env.UnsafeImportAddressBook, "address_book", false)
 	routes["unsafe_flush_mempool"] = rpc.NewRPCFunc(env.UnsafeFlushMempool, "", false)
+	routes["unsafe_set_mempool_paused"] = rpc.NewRPCFunc(env.UnsafeSetMempoolPaused, "paused", false)
+	routes["unsafe_set_timeout_commit"] = rpc.NewRPCFunc(env.UnsafeSetTimeoutCommit, "timeout_commit", false)
 }
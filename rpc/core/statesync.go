@@ -0,0 +1,30 @@
+package core
+
+import (
+	"errors"
+
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+)
+
+// DumpBackfillQueue dumps the state of the queue of the light blocks fetched
+// and verified by the backfill in progress, if any.
+// UNSTABLE
+// More: https://docs.tendermint.com/master/rpc/#/Info/dump_backfill_queue
+func (env *Environment) DumpBackfillQueue(ctx *rpctypes.Context) (*ctypes.ResultDumpBackfillQueue, error) {
+	if env.StateSync == nil {
+		return nil, errors.New("state sync is not available")
+	}
+
+	queue, ok := env.StateSync.BackfillQueue()
+	if !ok {
+		return &ctypes.ResultDumpBackfillQueue{}, nil
+	}
+	return &ctypes.ResultDumpBackfillQueue{
+		Backfilling:          true,
+		Pending:              queue.Pending,
+		Outstanding:          queue.Outstanding,
+		Waiters:              queue.Waiters,
+		LowestVerifiedHeight: queue.LowestVerifiedHeight,
+	}, nil
+}
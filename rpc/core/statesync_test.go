@@ -0,0 +1,82 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/internal/statesync"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+)
+
+func TestDumpBackfillQueue(t *testing.T) {
+	env := &Environment{}
+	_, err := env.DumpBackfillQueue(&rpctypes.Context{})
+	require.Error(t, err)
+
+	// not backfilling
+	ss := &mockStateSync{}
+	env.StateSync = ss
+	res, err := env.DumpBackfillQueue(&rpctypes.Context{})
+	require.NoError(t, err)
+	require.False(t, res.Backfilling)
+	require.Empty(t, res.Pending)
+	require.Empty(t, res.Outstanding)
+
+	// backfilling
+	ss.queue = &statesync.BlockQueueSnapshot{
+		Pending:              []int64{8, 7},
+		Outstanding:          []int64{6},
+		Waiters:              2,
+		LowestVerifiedHeight: 9,
+	}
+	res, err = env.DumpBackfillQueue(&rpctypes.Context{})
+	require.NoError(t, err)
+	require.True(t, res.Backfilling)
+	require.Equal(t, []int64{8, 7}, res.Pending)
+	require.Equal(t, []int64{6}, res.Outstanding)
+	require.Equal(t, 2, res.Waiters)
+	require.EqualValues(t, 9, res.LowestVerifiedHeight)
+}
+
+func TestServableBase(t *testing.T) {
+	ss := &mockStateSync{}
+	env := &Environment{BlockStore: mockBlockStore{height: 100}, StateSync: ss}
+
+	// not backfilling, the base of the block store applies
+	require.EqualValues(t, 1, env.getBase())
+	height := int64(1)
+	_, err := env.getHeight(100, &height)
+	require.NoError(t, err)
+
+	// backfilling in the background, the blocks below the servable base
+	// aren't available
+	ss.servableBase = 50
+	require.EqualValues(t, 50, env.getBase())
+	_, err = env.getHeight(100, &height)
+	require.ErrorIs(t, err, ctypes.ErrHeightNotAvailable)
+	height = 50
+	_, err = env.getHeight(100, &height)
+	require.NoError(t, err)
+
+	// the servable base never lowers the base of the block store
+	env.BlockStore = mockBlockStore{height: 100, base: 60}
+	require.EqualValues(t, 60, env.getBase())
+}
+
+type mockStateSync struct {
+	queue        *statesync.BlockQueueSnapshot
+	servableBase int64
+}
+
+func (ss *mockStateSync) BackfillQueue() (statesync.BlockQueueSnapshot, bool) {
+	if ss.queue == nil {
+		return statesync.BlockQueueSnapshot{}, false
+	}
+	return *ss.queue, true
+}
+
+func (ss *mockStateSync) ServableBase() (int64, bool) {
+	return ss.servableBase, ss.servableBase > 0
+}
@@ -20,6 +20,7 @@ var (
 	ErrZeroOrNegativeHeight   = errors.New("height must be greater than zero")
 	ErrHeightExceedsChainHead = errors.New("height must be less than or equal to the head of the node's blockchain")
 	ErrHeightNotAvailable     = errors.New("height is not available")
+	ErrValidatorSetPruned     = errors.New("validator set unavailable for pruned height")
 	// ErrInvalidRequest is used as a wrapper to cover more specific cases where the user has
 	// made an invalid request
 	ErrInvalidRequest = errors.New("invalid request")
@@ -39,11 +40,14 @@ type ResultGenesis struct {
 // ResultGenesisChunk is the output format for the chunked/paginated
 // interface. These chunks are produced by converting the genesis
 // document to JSON and then splitting the resulting payload into
-// 16 megabyte blocks and then base64 encoding each block.
+// 16 megabyte blocks and then base64 encoding each block. Hash is the
+// SHA256 hash of the block before encoding, allowing each chunk to be
+// verified on its own.
 type ResultGenesisChunk struct {
-	ChunkNumber int    `json:"chunk"`
-	TotalChunks int    `json:"total"`
-	Data        string `json:"data"`
+	ChunkNumber int            `json:"chunk"`
+	TotalChunks int            `json:"total"`
+	Data        string         `json:"data"`
+	Hash        bytes.HexBytes `json:"hash"`
 }
 
 // Single block (with meta)
@@ -137,6 +141,21 @@ type ResultDialPeers struct {
 	Log string `json:"log"`
 }
 
+// Log from redialing a peer
+type ResultUnsafeDialPeer struct {
+	Log string `json:"log"`
+}
+
+// Peer addresses exported from the address book
+type ResultUnsafeExportAddressBook struct {
+	AddressBook []byte `json:"address_book"`
+}
+
+// Number of peer addresses added by importing an address book
+type ResultUnsafeImportAddressBook struct {
+	Added int `json:"added"`
+}
+
 // A peer
 type Peer struct {
 	NodeInfo         p2p.NodeInfo         `json:"node_info"`
@@ -153,6 +172,8 @@ type ResultValidators struct {
 	Count int `json:"count"`
 	// Total number of validators
 	Total int `json:"total"`
+	// Proof of the validator set hash against the header, if requested
+	Proof *types.ValidatorSetProof `json:"proof,omitempty"`
 }
 
 // ConsensusParams for given height
@@ -168,6 +189,16 @@ type ResultDumpConsensusState struct {
 	Peers      []PeerStateInfo `json:"peers"`
 }
 
+// Info about the queue of the backfill in progress, if any.
+// UNSTABLE
+type ResultDumpBackfillQueue struct {
+	Backfilling          bool    `json:"backfilling"`
+	Pending              []int64 `json:"pending"`
+	Outstanding          []int64 `json:"outstanding"`
+	Waiters              int     `json:"waiters"`
+	LowestVerifiedHeight int64   `json:"lowest_verified_height"`
+}
+
 // UNSTABLE
 type PeerStateInfo struct {
 	NodeAddress string          `json:"node_address"`
@@ -232,6 +263,32 @@ type ResultUnconfirmedTxs struct {
 	Txs        []types.Tx `json:"txs"`
 }
 
+// Unconfirmed txs of a sender, or the number of unconfirmed txs of each
+// sender if no sender was given
+type ResultUnconfirmedTxsBySender struct {
+	Sender  string          `json:"sender,omitempty"`
+	Txs     []types.Tx      `json:"txs,omitempty"`
+	Senders []SenderTxCount `json:"senders,omitempty"`
+	Count   int             `json:"count"`
+	Total   int             `json:"total"`
+}
+
+// Number of unconfirmed txs of a sender
+type SenderTxCount struct {
+	Sender string `json:"sender"`
+	Count  int    `json:"n_txs"`
+}
+
+// Reason a transaction was rejected from the mempool
+type ResultTxRejection struct {
+	Hash   bytes.HexBytes `json:"hash"`
+	Reason string         `json:"reason"`
+	Code   uint32         `json:"code"`
+	Log    string         `json:"log"`
+	Height int64          `json:"height"`
+	Time   time.Time      `json:"time"`
+}
+
 // Info abci msg
 type ResultABCIInfo struct {
 	Response abci.ResponseInfo `json:"response"`
@@ -247,6 +304,16 @@ type ResultBroadcastEvidence struct {
 	Hash []byte `json:"hash"`
 }
 
+// Height from which a timeout commit override applies
+type ResultUnsafeSetTimeoutCommit struct {
+	Height int64 `json:"height"`
+}
+
+// ResultUnsafeSetMempoolPaused reports whether the mempool is paused.
+type ResultUnsafeSetMempoolPaused struct {
+	Paused bool `json:"paused"`
+}
+
 // empty results
 type (
 	ResultUnsafeFlushMempool struct{}
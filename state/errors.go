@@ -26,12 +26,24 @@ type (
 		StoreBase int64
 	}
 
+	ErrAppRewindTooDeep struct {
+		AppHeight   int64
+		StoreHeight int64
+		MaxDepth    int64
+	}
+
 	ErrLastStateMismatch struct {
 		Height int64
 		Core   []byte
 		App    []byte
 	}
 
+	ErrAppHashDivergence struct {
+		Height int64
+		Core   []byte
+		App    []byte
+	}
+
 	ErrStateMismatch struct {
 		Got      *State
 		Expected *State
@@ -71,6 +83,11 @@ func (e ErrAppBlockHeightTooLow) Error() string {
 	return fmt.Sprintf("app block height (%d) is too far below block store base (%d)", e.AppHeight, e.StoreBase)
 }
 
+func (e ErrAppRewindTooDeep) Error() string {
+	return fmt.Sprintf("app block height (%d) is more than %d blocks below the block store height (%d)",
+		e.AppHeight, e.MaxDepth, e.StoreHeight)
+}
+
 func (e ErrLastStateMismatch) Error() string {
 	return fmt.Sprintf(
 		"latest tendermint block (%d) LastAppHash (%X) does not match app's AppHash (%X)",
@@ -80,6 +97,15 @@ func (e ErrLastStateMismatch) Error() string {
 	)
 }
 
+func (e ErrAppHashDivergence) Error() string {
+	return fmt.Sprintf(
+		"app hash (%X) after replaying block %d does not match the recorded app hash (%X)",
+		e.App,
+		e.Height,
+		e.Core,
+	)
+}
+
 func (e ErrStateMismatch) Error() string {
 	return fmt.Sprintf(
 		"state after replay does not match saved state. Got ----\n%v\nExpected ----\n%v\n",
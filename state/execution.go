@@ -1,6 +1,7 @@
 package state
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -113,7 +114,15 @@ func (blockExec *BlockExecutor) CreateProposalBlock(
 	// Fetch a limited amount of valid txs
 	maxDataBytes := types.MaxDataBytes(maxBytes, evSize, state.Validators.Size())
 
-	txs := blockExec.mempool.ReapMaxBytesMaxGas(maxDataBytes, maxGas)
+	var txs types.Txs
+	if iterable, ok := blockExec.mempool.(mempl.IterableMempool); ok {
+		it := iterable.ReapIterator(maxDataBytes, maxGas)
+		for tx, ok := it.Next(); ok; tx, ok = it.Next() {
+			txs = append(txs, tx)
+		}
+	} else {
+		txs = blockExec.mempool.ReapMaxBytesMaxGas(maxDataBytes, maxGas)
+	}
 
 	return state.MakeBlock(height, txs, commit, evidence, proposerAddr)
 }
@@ -128,7 +137,7 @@ func (blockExec *BlockExecutor) ValidateBlock(state State, block *types.Block) e
 		return nil
 	}
 
-	err := validateBlock(state, block)
+	err := validateBlock(state, block, true)
 	if err != nil {
 		return err
 	}
@@ -157,6 +166,38 @@ func (blockExec *BlockExecutor) ApplyBlock(
 		return state, ErrInvalidBlock(err)
 	}
 
+	return blockExec.applyBlock(state, blockID, block)
+}
+
+// ApplyLocalBlock is ApplyBlock for a block loaded from the local block store,
+// e.g. when replaying blocks on startup. As the block was validated before it
+// was saved, the signatures of its last commit aren't verified again, which
+// is the bulk of the cost of validating it. The block is otherwise validated
+// as usual, including that its hash is the one of the block ID and that its
+// last commit is one for the last block by the last validators. It must never
+// be called with a block received from the network.
+func (blockExec *BlockExecutor) ApplyLocalBlock(
+	state State, blockID types.BlockID, block *types.Block,
+) (State, error) {
+	// the block isn't cached as validated, as it isn't fully validated
+	if hash := block.Hash(); !bytes.Equal(hash, blockID.Hash) {
+		return state, ErrInvalidBlock(fmt.Errorf("block hash %X doesn't match block ID %v", hash, blockID))
+	}
+	if err := validateBlock(state, block, false); err != nil {
+		return state, ErrInvalidBlock(err)
+	}
+	if err := blockExec.evpool.CheckEvidence(block.Evidence.Evidence); err != nil {
+		return state, ErrInvalidBlock(err)
+	}
+
+	return blockExec.applyBlock(state, blockID, block)
+}
+
+// applyBlock executes the validated block against the app and commits it. See
+// ApplyBlock.
+func (blockExec *BlockExecutor) applyBlock(
+	state State, blockID types.BlockID, block *types.Block,
+) (State, error) {
 	startTime := time.Now().UnixNano()
 	abciResponses, err := execBlockOnProxyApp(
 		blockExec.logger, blockExec.proxyApp, block, blockExec.store, state.InitialHeight,
@@ -237,7 +278,7 @@ func (blockExec *BlockExecutor) ApplyBlock(
 }
 
 // Commit locks the mempool, runs the ABCI Commit message, and updates the
-// mempool.
+// mempool, notifying it first if the block changed the validator set.
 // It returns the result of calling abci.Commit (the AppHash) and the height to retain (if any).
 // The Mempool must be locked during commit and update because state is
 // typically reset on Commit and old txs must be replayed against committed
@@ -273,6 +314,14 @@ func (blockExec *BlockExecutor) Commit(
 		"app_hash", fmt.Sprintf("%X", res.Data),
 	)
 
+	// A block whose validator updates were applied to the next validators
+	// changed the validator set.
+	if state.LastHeightValidatorsChanged == block.Height+1+1 {
+		if observer, ok := blockExec.mempool.(mempl.ValidatorSetObserver); ok {
+			observer.ValidatorSetChanged()
+		}
+	}
+
 	// Update mempool.
 	err = blockExec.mempool.Update(
 		block.Height,
@@ -532,6 +581,17 @@ func fireEvents(
 		logger.Error("failed publishing new block header", "err", err)
 	}
 
+	if err := eventBus.PublishEventNewBlockResults(types.EventDataNewBlockResults{
+		Height:                block.Height,
+		TxsResults:            abciResponses.DeliverTxs,
+		BeginBlockEvents:      abciResponses.BeginBlock.Events,
+		EndBlockEvents:        abciResponses.EndBlock.Events,
+		ValidatorUpdates:      abciResponses.EndBlock.ValidatorUpdates,
+		ConsensusParamUpdates: abciResponses.EndBlock.ConsensusParamUpdates,
+	}); err != nil {
+		logger.Error("failed publishing new block results", "err", err)
+	}
+
 	if len(block.Evidence.Evidence) != 0 {
 		for _, ev := range block.Evidence.Evidence {
 			if err := eventBus.PublishEventNewEvidence(types.EventDataNewEvidence{
@@ -14,7 +14,9 @@ import (
 	"github.com/tendermint/tendermint/crypto/ed25519"
 	cryptoenc "github.com/tendermint/tendermint/crypto/encoding"
 	"github.com/tendermint/tendermint/crypto/tmhash"
+	mempl "github.com/tendermint/tendermint/internal/mempool"
 	mmock "github.com/tendermint/tendermint/internal/mempool/mock"
+	"github.com/tendermint/tendermint/internal/test/factory"
 	"github.com/tendermint/tendermint/libs/log"
 	tmtime "github.com/tendermint/tendermint/libs/time"
 	"github.com/tendermint/tendermint/proxy"
@@ -416,6 +418,169 @@ func TestEndBlockValidatorUpdates(t *testing.T) {
 	}
 }
 
+// validatorSetObserverMempool is a mock mempool counting the changes of the
+// validator set it is notified of.
+type validatorSetObserverMempool struct {
+	mmock.Mempool
+
+	changes int
+}
+
+func (mp *validatorSetObserverMempool) ValidatorSetChanged() { mp.changes++ }
+
+// TestCommitNotifiesValidatorSetChange ensures the mempool is notified of the
+// blocks that change the validator set, and only of those.
+func TestCommitNotifiesValidatorSetChange(t *testing.T) {
+	app := &testApp{}
+	cc := proxy.NewLocalClientCreator(app)
+	proxyApp := proxy.NewAppConns(cc)
+	err := proxyApp.Start()
+	require.Nil(t, err)
+	defer proxyApp.Stop() //nolint:errcheck // ignore for tests
+
+	state, stateDB, privVals := makeState(1, 1)
+	stateStore := sm.NewStore(stateDB)
+	blockStore := store.NewBlockStore(dbm.NewMemDB())
+	mp := &validatorSetObserverMempool{}
+	blockExec := sm.NewBlockExecutor(
+		stateStore,
+		log.TestingLogger(),
+		proxyApp.Consensus(),
+		mp,
+		sm.EmptyEvidencePool{},
+		blockStore,
+	)
+
+	pk, err := cryptoenc.PubKeyToProto(ed25519.GenPrivKey().PubKey())
+	require.NoError(t, err)
+
+	lastCommit := types.NewCommit(0, 0, types.BlockID{}, nil)
+	state, _, lastCommit, err = makeAndCommitGoodBlock(
+		state, 1, lastCommit, state.Validators.GetProposer().Address, blockExec, privVals, nil)
+	require.NoError(t, err)
+	require.Zero(t, mp.changes)
+
+	app.ValidatorUpdates = []abci.ValidatorUpdate{{PubKey: pk, Power: 10}}
+	state, _, lastCommit, err = makeAndCommitGoodBlock(
+		state, 2, lastCommit, state.Validators.GetProposer().Address, blockExec, privVals, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, mp.changes)
+
+	// block 3 doesn't change the validator set
+	app.ValidatorUpdates = nil
+	_, _, err = makeAndApplyGoodBlock(state, 3, lastCommit, state.Validators.GetProposer().Address, blockExec, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, mp.changes)
+}
+
+// iterableMempool is a mock mempool reaping its txs through an iterator,
+// recording the constraints it was given.
+type iterableMempool struct {
+	mmock.Mempool
+
+	txs      types.Txs
+	maxBytes int64
+	maxGas   int64
+}
+
+func (mp *iterableMempool) ReapIterator(maxBytes, maxGas int64) mempl.TxIterator {
+	mp.maxBytes, mp.maxGas = maxBytes, maxGas
+	return &sliceTxIterator{txs: mp.txs}
+}
+
+type sliceTxIterator struct {
+	txs types.Txs
+}
+
+func (it *sliceTxIterator) Next() (types.Tx, bool) {
+	if len(it.txs) == 0 {
+		return nil, false
+	}
+	tx := it.txs[0]
+	it.txs = it.txs[1:]
+	return tx, true
+}
+
+// TestCreateProposalBlockIterator ensures proposed txs are reaped through the
+// mempool's iterator when it has one.
+func TestCreateProposalBlockIterator(t *testing.T) {
+	state, stateDB, _ := makeState(1, 1)
+	stateStore := sm.NewStore(stateDB)
+	mp := &iterableMempool{txs: types.Txs{types.Tx("a"), types.Tx("b")}}
+	blockExec := sm.NewBlockExecutor(
+		stateStore,
+		log.TestingLogger(),
+		nil,
+		mp,
+		sm.EmptyEvidencePool{},
+		store.NewBlockStore(dbm.NewMemDB()),
+	)
+
+	lastCommit := types.NewCommit(0, 0, types.BlockID{}, nil)
+	block, _ := blockExec.CreateProposalBlock(1, state, lastCommit, state.Validators.GetProposer().Address)
+	require.Equal(t, mp.txs, block.Txs)
+	require.Equal(t, types.MaxDataBytes(state.ConsensusParams.Block.MaxBytes, 0, state.Validators.Size()), mp.maxBytes)
+	require.Equal(t, state.ConsensusParams.Block.MaxGas, mp.maxGas)
+}
+
+// TestNewBlockResultsEvents ensures a NewBlockResults event is published for
+// each committed block, in height order.
+func TestNewBlockResultsEvents(t *testing.T) {
+	proxyApp := newTestApp()
+	err := proxyApp.Start()
+	require.Nil(t, err)
+	defer proxyApp.Stop() //nolint:errcheck // ignore for tests
+
+	state, stateDB, privVals := makeState(1, 1)
+	stateStore := sm.NewStore(stateDB)
+	blockStore := store.NewBlockStore(dbm.NewMemDB())
+	blockExec := sm.NewBlockExecutor(
+		stateStore,
+		log.TestingLogger(),
+		proxyApp.Consensus(),
+		mmock.Mempool{},
+		sm.EmptyEvidencePool{},
+		blockStore,
+	)
+
+	eventBus := types.NewEventBus()
+	err = eventBus.Start()
+	require.NoError(t, err)
+	defer eventBus.Stop() //nolint:errcheck // ignore for tests
+
+	blockExec.SetEventBus(eventBus)
+
+	resultsSub, err := eventBus.Subscribe(
+		context.Background(),
+		"TestNewBlockResultsEvents",
+		types.EventQueryNewBlockResults,
+		3,
+	)
+	require.NoError(t, err)
+
+	const numBlocks = 3
+	lastCommit := types.NewCommit(0, 0, types.BlockID{}, nil)
+	for height := int64(1); height <= numBlocks; height++ {
+		state, _, lastCommit, err = makeAndCommitGoodBlock(
+			state, height, lastCommit, state.Validators.GetProposer().Address, blockExec, privVals, nil)
+		require.NoError(t, err, "height %d", height)
+	}
+
+	for height := int64(1); height <= numBlocks; height++ {
+		select {
+		case msg := <-resultsSub.Out():
+			event, ok := msg.Data().(types.EventDataNewBlockResults)
+			require.True(t, ok, "Expected event of type EventDataNewBlockResults, got %T", msg.Data())
+			assert.Equal(t, height, event.Height)
+			assert.Len(t, event.TxsResults, len(factory.MakeTenTxs(height)))
+		case <-resultsSub.Canceled():
+			t.Fatalf("resultsSub was canceled (reason: %v)", resultsSub.Err())
+		case <-time.After(1 * time.Second):
+			t.Fatalf("Did not receive EventNewBlockResults for height %d within 1 sec.", height)
+		}
+	}
+}
+
 // TestEndBlockValidatorUpdatesResultingInEmptySet checks that processing validator updates that
 // would result in empty set causes no panic, an error is raised and NextValidators is not updated
 func TestEndBlockValidatorUpdatesResultingInEmptySet(t *testing.T) {
@@ -453,6 +618,114 @@ func TestEndBlockValidatorUpdatesResultingInEmptySet(t *testing.T) {
 	assert.NotEmpty(t, state.NextValidators.Validators)
 }
 
+func TestApplyLocalBlock(t *testing.T) {
+	blockExec, state, commit, privVals := makeExecutorAtHeightOne(t, 4)
+	makeBlock := func(commit *types.Commit) (*types.Block, types.BlockID) {
+		block := sf.MakeBlock(state, 2, commit)
+		return block, types.BlockID{Hash: block.Hash(), PartSetHeader: block.MakePartSet(testPartSize).Header()}
+	}
+
+	otherCommit, err := makeValidCommit(1, factory.MakeBlockID(), state.LastValidators, privVals)
+	require.NoError(t, err)
+	halfCommit := types.NewCommit(commit.Height, commit.Round, commit.BlockID,
+		append([]types.CommitSig{commit.Signatures[0], commit.Signatures[1]},
+			types.NewCommitSigAbsent(), types.NewCommitSigAbsent()))
+
+	testCases := map[string]func() (*types.Block, types.BlockID){
+		"commit for another block": func() (*types.Block, types.BlockID) {
+			return makeBlock(otherCommit)
+		},
+		"commit with too few signatures": func() (*types.Block, types.BlockID) {
+			return makeBlock(halfCommit)
+		},
+		"wrong last block ID": func() (*types.Block, types.BlockID) {
+			block := sf.MakeBlock(state, 2, commit)
+			block.LastBlockID = factory.MakeBlockID()
+			return block, types.BlockID{Hash: block.Hash(), PartSetHeader: block.MakePartSet(testPartSize).Header()}
+		},
+		"tampered data": func() (*types.Block, types.BlockID) {
+			block, blockID := makeBlock(commit)
+			block.Data = types.Data{Txs: types.Txs{types.Tx("tampered")}}
+			return block, blockID
+		},
+		"wrong block ID": func() (*types.Block, types.BlockID) {
+			block, _ := makeBlock(commit)
+			return block, factory.MakeBlockID()
+		},
+	}
+	for name, makeCorruptBlock := range testCases {
+		makeCorruptBlock := makeCorruptBlock
+		t.Run(name, func(t *testing.T) {
+			// structural corruption is caught, even without verifying signatures
+			block, blockID := makeCorruptBlock()
+			_, err := blockExec.ApplyLocalBlock(state, blockID, block)
+			require.Error(t, err)
+		})
+	}
+
+	// a block whose last commit has an invalid signature is only rejected
+	// when signatures are verified
+	badCommit := types.NewCommit(commit.Height, commit.Round, commit.BlockID,
+		append([]types.CommitSig(nil), commit.Signatures...))
+	badCommit.Signatures[3].Signature = make([]byte, len(commit.Signatures[3].Signature))
+	block, blockID := makeBlock(badCommit)
+	_, err = blockExec.ApplyBlock(state, blockID, block)
+	require.Error(t, err)
+	state, err = blockExec.ApplyLocalBlock(state, blockID, block)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, state.LastBlockHeight)
+	require.Equal(t, blockID, state.LastBlockID)
+}
+
+// BenchmarkApplyBlock compares applying a block with a commit from 100
+// validators with and without verifying its signatures, as for a block
+// replayed from the local block store.
+func BenchmarkApplyBlock(b *testing.B) {
+	blockExec, state, commit, _ := makeExecutorAtHeightOne(b, 100)
+	block := sf.MakeBlock(state, 2, commit)
+	blockID := types.BlockID{Hash: block.Hash(), PartSetHeader: block.MakePartSet(testPartSize).Header()}
+
+	benchmarks := map[string]func(sm.State, types.BlockID, *types.Block) (sm.State, error){
+		"verify signatures": blockExec.ApplyBlock,
+		"skip signatures":   blockExec.ApplyLocalBlock,
+	}
+	for name, apply := range benchmarks {
+		apply := apply
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := apply(state, blockID, block); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// makeExecutorAtHeightOne returns a block executor that applied a first block
+// to a state with nVals validators, along with the resulting state and a
+// commit of the block from all of them.
+func makeExecutorAtHeightOne(
+	t testing.TB,
+	nVals int,
+) (*sm.BlockExecutor, sm.State, *types.Commit, map[string]types.PrivValidator) {
+	proxyApp := newTestApp()
+	require.NoError(t, proxyApp.Start())
+	t.Cleanup(func() { _ = proxyApp.Stop() })
+
+	state, stateDB, privVals := makeState(nVals, 1)
+	stateStore := sm.NewStore(stateDB)
+	blockStore := store.NewBlockStore(dbm.NewMemDB())
+	blockExec := sm.NewBlockExecutor(stateStore, log.NewNopLogger(), proxyApp.Consensus(),
+		mmock.Mempool{}, sm.EmptyEvidencePool{}, blockStore)
+
+	proposerAddr := state.Validators.GetProposer().Address
+	state, _, commit, err := makeAndCommitGoodBlock(
+		state, 1, types.NewCommit(0, 0, types.BlockID{}, nil), proposerAddr, blockExec, privVals, nil)
+	require.NoError(t, err)
+	return blockExec, state, commit, privVals
+}
+
 func makeBlockID(hash []byte, partSetSize uint32, partSetHash []byte) types.BlockID {
 	var (
 		h   = make([]byte, tmhash.Size)
@@ -27,6 +27,7 @@ func MakeBlocks(n int, state *sm.State, privVal types.PrivValidator) []*types.Bl
 		prevBlockMeta = types.NewBlockMeta(block, parts)
 
 		// update state
+		state.LastBlockID = prevBlockMeta.BlockID
 		state.AppHash = []byte{appHeight}
 		appHeight++
 		state.LastBlockHeight = height
@@ -11,7 +11,10 @@ import (
 //-----------------------------------------------------
 // Validate block
 
-func validateBlock(state State, block *types.Block) error {
+// validateBlock validates the block against the state. If verifyCommit is
+// false, the signatures of the last commit aren't verified, only that it is a
+// commit for the last block by the last validators.
+func validateBlock(state State, block *types.Block, verifyCommit bool) error {
 	// Validate internal consistency.
 	if err := block.ValidateBasic(); err != nil {
 		return err
@@ -87,12 +90,17 @@ func validateBlock(state State, block *types.Block) error {
 		if len(block.LastCommit.Signatures) != 0 {
 			return errors.New("initial block can't have LastCommit signatures")
 		}
-	} else {
+	} else if verifyCommit {
 		// LastCommit.Signatures length is checked in VerifyCommit.
 		if err := state.LastValidators.VerifyCommit(
 			state.ChainID, state.LastBlockID, block.Height-1, block.LastCommit); err != nil {
 			return err
 		}
+	} else {
+		if err := state.LastValidators.VerifyCommitStructure(
+			state.LastBlockID, block.Height-1, block.LastCommit); err != nil {
+			return err
+		}
 	}
 
 	// NOTE: We can't actually verify it's the right proposer because we don't
@@ -423,6 +423,99 @@ func (bs *BlockStore) batchDelete(
 	return pruned, end, iter.Error()
 }
 
+// Verify checks the integrity of the blocks between fromHeight and toHeight
+// (inclusive), limited to those between the base and height of the store. It
+// returns the heights of the blocks which are missing, or whose parts are
+// missing or don't reassemble to the stored block hash, in increasing order.
+// An error is returned only if the store can't be read.
+//
+// Heights for which only a signed header was saved, as done by state sync
+// backfill, have no parts and are not considered.
+func (bs *BlockStore) Verify(fromHeight, toHeight int64) ([]int64, error) {
+	if fromHeight > toHeight {
+		return nil, fmt.Errorf("from height %d is above to height %d", fromHeight, toHeight)
+	}
+
+	base, height := bs.Base(), bs.Height()
+	if base == 0 {
+		return nil, nil
+	}
+	if fromHeight < base {
+		fromHeight = base
+	}
+	if toHeight > height {
+		toHeight = height
+	}
+
+	var problems []int64
+	for h := fromHeight; h <= toHeight; h++ {
+		ok, err := bs.verifyBlock(h)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			problems = append(problems, h)
+		}
+	}
+	return problems, nil
+}
+
+// verifyBlock returns false if the block at the given height is missing or
+// corrupt.
+func (bs *BlockStore) verifyBlock(height int64) (bool, error) {
+	bz, err := bs.db.Get(blockMetaKey(height))
+	if err != nil {
+		return false, err
+	}
+	if len(bz) == 0 {
+		return false, nil
+	}
+
+	pbbm := new(tmproto.BlockMeta)
+	if err := proto.Unmarshal(bz, pbbm); err != nil {
+		return false, nil
+	}
+	blockMeta, err := types.BlockMetaFromProto(pbbm)
+	if err != nil {
+		return false, nil
+	}
+	if blockMeta.BlockSize < 0 {
+		return true, nil
+	}
+
+	buf := []byte{}
+	partSetHeader := blockMeta.BlockID.PartSetHeader
+	for i := 0; i < int(partSetHeader.Total); i++ {
+		bz, err := bs.db.Get(blockPartKey(height, i))
+		if err != nil {
+			return false, err
+		}
+		if len(bz) == 0 {
+			return false, nil
+		}
+
+		pbpart := new(tmproto.Part)
+		if err := proto.Unmarshal(bz, pbpart); err != nil {
+			return false, nil
+		}
+		part, err := types.PartFromProto(pbpart)
+		if err != nil || part.Index != uint32(i) || part.Proof.Verify(partSetHeader.Hash, part.Bytes) != nil {
+			return false, nil
+		}
+		buf = append(buf, part.Bytes...)
+	}
+
+	pbb := new(tmproto.Block)
+	if err := proto.Unmarshal(buf, pbb); err != nil {
+		return false, nil
+	}
+	block, err := types.BlockFromProto(pbb)
+	if err != nil {
+		return false, nil
+	}
+	return bytes.Equal(block.Hash(), blockMeta.BlockID.Hash), nil
+}
+
 // SaveBlock persists the given block, blockParts, and seenCommit to the underlying db.
 // blockParts: Must be parts of the block
 // seenCommit: The +2/3 precommits that were seen which committed at height.
@@ -425,6 +425,58 @@ func TestPruneBlocks(t *testing.T) {
 	assert.Nil(t, bs.LoadBlock(1501))
 }
 
+func TestBlockStoreVerify(t *testing.T) {
+	config := cfg.ResetTestRoot("blockchain_reactor_test")
+	defer os.RemoveAll(config.RootDir)
+	state, err := sm.MakeGenesisStateFromFile(config.GenesisFile())
+	require.NoError(t, err)
+	bs, db := freshBlockStore()
+
+	problems, err := bs.Verify(1, 10)
+	require.NoError(t, err)
+	require.Empty(t, problems)
+
+	for h := int64(1); h <= 10; h++ {
+		block := factory.MakeBlock(state, h, new(types.Commit))
+		partSet := block.MakePartSet(2)
+		require.Greater(t, partSet.Total(), uint32(2))
+		bs.SaveBlock(block, partSet, makeTestCommit(h, tmtime.Now()))
+	}
+
+	problems, err = bs.Verify(1, 10)
+	require.NoError(t, err)
+	require.Empty(t, problems)
+
+	// delete a part of one block, and a whole other block
+	require.NoError(t, db.Delete(blockPartKey(4, 1)))
+	meta := bs.LoadBlockMeta(7)
+	for i := 0; i < int(meta.BlockID.PartSetHeader.Total); i++ {
+		require.NoError(t, db.Delete(blockPartKey(7, i)))
+	}
+	require.NoError(t, db.Delete(blockMetaKey(7)))
+
+	// replace a part of yet another block with one from a different block
+	bz, err := db.Get(blockPartKey(9, 0))
+	require.NoError(t, err)
+	require.NoError(t, db.Set(blockPartKey(8, 0), bz))
+
+	problems, err = bs.Verify(1, 10)
+	require.NoError(t, err)
+	require.Equal(t, []int64{4, 7, 8}, problems)
+
+	// the range is limited to the base and height of the store
+	problems, err = bs.Verify(0, 100)
+	require.NoError(t, err)
+	require.Equal(t, []int64{4, 7, 8}, problems)
+
+	problems, err = bs.Verify(5, 7)
+	require.NoError(t, err)
+	require.Equal(t, []int64{7}, problems)
+
+	_, err = bs.Verify(7, 5)
+	require.Error(t, err)
+}
+
 func TestLoadBlockMeta(t *testing.T) {
 	bs, db := freshBlockStore()
 	height := int64(10)
@@ -129,7 +129,9 @@ func (app *Application) Commit() abci.ResponseCommit {
 	if err != nil {
 		panic(err)
 	}
-	if app.cfg.SnapshotInterval > 0 && height%app.cfg.SnapshotInterval == 0 {
+	if app.cfg.SnapshotKeysPerBlock > 0 {
+		app.continueSnapshot(height)
+	} else if app.cfg.SnapshotInterval > 0 && height%app.cfg.SnapshotInterval == 0 {
 		snapshot, err := app.snapshots.Create(app.state)
 		if err != nil {
 			panic(err)
@@ -146,6 +148,25 @@ func (app *Application) Commit() abci.ResponseCommit {
 	}
 }
 
+// continueSnapshot writes the next key/value pairs of the snapshot being
+// created, if any, and starts creating a new one at snapshot heights. This
+// spreads the creation of a snapshot across blocks, rather than stalling the
+// commit of the snapshot height.
+func (app *Application) continueSnapshot(height uint64) {
+	if app.cfg.SnapshotInterval > 0 && height%app.cfg.SnapshotInterval == 0 {
+		if err := app.snapshots.Begin(app.state); err != nil {
+			logger.Error("Skipping state sync snapshot", "height", height, "err", err)
+		}
+	}
+	snapshot, err := app.snapshots.Continue(int(app.cfg.SnapshotKeysPerBlock))
+	if err != nil {
+		panic(err)
+	}
+	if snapshot != nil {
+		logger.Info("Created state sync snapshot", "height", snapshot.Height)
+	}
+}
+
 // Query implements ABCI.
 func (app *Application) Query(req abci.RequestQuery) abci.ResponseQuery {
 	return abci.ResponseQuery{
@@ -10,20 +10,21 @@ import (
 
 // Config is the application configuration.
 type Config struct {
-	ChainID          string `toml:"chain_id"`
-	Listen           string
-	Protocol         string
-	Dir              string
-	Mode             string                      `toml:"mode"`
-	PersistInterval  uint64                      `toml:"persist_interval"`
-	SnapshotInterval uint64                      `toml:"snapshot_interval"`
-	RetainBlocks     uint64                      `toml:"retain_blocks"`
-	ValidatorUpdates map[string]map[string]uint8 `toml:"validator_update"`
-	PrivValServer    string                      `toml:"privval_server"`
-	PrivValKey       string                      `toml:"privval_key"`
-	PrivValState     string                      `toml:"privval_state"`
-	Misbehaviors     map[string]string           `toml:"misbehaviors"`
-	KeyType          string                      `toml:"key_type"`
+	ChainID              string `toml:"chain_id"`
+	Listen               string
+	Protocol             string
+	Dir                  string
+	Mode                 string                      `toml:"mode"`
+	PersistInterval      uint64                      `toml:"persist_interval"`
+	SnapshotInterval     uint64                      `toml:"snapshot_interval"`
+	SnapshotKeysPerBlock uint64                      `toml:"snapshot_keys_per_block"`
+	RetainBlocks         uint64                      `toml:"retain_blocks"`
+	ValidatorUpdates     map[string]map[string]uint8 `toml:"validator_update"`
+	PrivValServer        string                      `toml:"privval_server"`
+	PrivValKey           string                      `toml:"privval_key"`
+	PrivValState         string                      `toml:"privval_state"`
+	Misbehaviors         map[string]string           `toml:"misbehaviors"`
+	KeyType              string                      `toml:"key_type"`
 }
 
 // LoadConfig loads the configuration from disk.
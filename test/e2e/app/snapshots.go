@@ -2,13 +2,17 @@
 package main
 
 import (
+	"bufio"
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash"
 	"io/ioutil"
 	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 
 	abci "github.com/tendermint/tendermint/abci/types"
@@ -25,6 +29,21 @@ type SnapshotStore struct {
 	sync.RWMutex
 	dir      string
 	metadata []abci.Snapshot
+	pending  *pendingSnapshot
+}
+
+// pendingSnapshot is a snapshot being created incrementally, from a
+// copy-on-write view of the state at its height. It is written as the same
+// JSON as State.Export, one key/value pair at a time, and hashed alongside
+// in the same way as hashItems.
+type pendingSnapshot struct {
+	height uint64
+	values map[string]string
+	keys   []string // sorted keys of values
+	next   int      // index of the next key to write
+	file   *os.File
+	writer *bufio.Writer
+	hasher hash.Hash
 }
 
 // NewSnapshotStore creates a new snapshot store.
@@ -105,6 +124,139 @@ func (s *SnapshotStore) Create(state *State) (abci.Snapshot, error) {
 	return snapshot, nil
 }
 
+// Begin starts creating a snapshot of the given application state's last
+// committed height incrementally, which Continue writes a few key/value pairs
+// of at a time. The snapshot remains consistent with that height even though
+// later heights are committed in the meantime. Only one snapshot can be
+// created at a time.
+func (s *SnapshotStore) Begin(state *State) error {
+	s.Lock()
+	defer s.Unlock()
+	if s.pending != nil {
+		return fmt.Errorf("snapshot at height %v is still being created", s.pending.height)
+	}
+
+	height, values := state.View()
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	file, err := os.Create(filepath.Join(s.dir, fmt.Sprintf("%v.json.pending", height)))
+	if err != nil {
+		return err
+	}
+	pending := &pendingSnapshot{
+		height: height,
+		values: values,
+		keys:   keys,
+		file:   file,
+		writer: bufio.NewWriter(file),
+		hasher: sha256.New(),
+	}
+	if _, err = pending.writer.WriteString("{"); err != nil {
+		_ = file.Close()
+		return err
+	}
+	s.pending = pending
+	return nil
+}
+
+// Continue writes up to n further key/value pairs of the snapshot being
+// created, if any. Once all of them are written, it completes the snapshot
+// and returns it.
+func (s *SnapshotStore) Continue(n int) (*abci.Snapshot, error) {
+	s.Lock()
+	defer s.Unlock()
+	p := s.pending
+	if p == nil {
+		return nil, nil
+	}
+
+	for ; n > 0 && p.next < len(p.keys); n-- {
+		key := p.keys[p.next]
+		value := p.values[key]
+		if err := p.writePair(key, value, p.next > 0); err != nil {
+			return nil, s.abortPending(err)
+		}
+		_, _ = p.hasher.Write([]byte(key))
+		_, _ = p.hasher.Write([]byte{0})
+		_, _ = p.hasher.Write([]byte(value))
+		_, _ = p.hasher.Write([]byte{0})
+		p.next++
+	}
+	if p.next < len(p.keys) {
+		return nil, nil
+	}
+
+	if _, err := p.writer.WriteString("}"); err != nil {
+		return nil, s.abortPending(err)
+	}
+	if err := p.writer.Flush(); err != nil {
+		return nil, s.abortPending(err)
+	}
+	info, err := p.file.Stat()
+	if err != nil {
+		return nil, s.abortPending(err)
+	}
+	if err = p.file.Close(); err != nil {
+		return nil, s.abortPending(err)
+	}
+	err = os.Rename(p.file.Name(), filepath.Join(s.dir, fmt.Sprintf("%v.json", p.height)))
+	if err != nil {
+		return nil, s.abortPending(err)
+	}
+	s.pending = nil
+
+	snapshot := abci.Snapshot{
+		Height: p.height,
+		Format: 1,
+		Hash:   p.hasher.Sum(nil),
+		Chunks: uint32(math.Ceil(float64(info.Size()) / snapshotChunkSize)),
+	}
+	s.metadata = append(s.metadata, snapshot)
+	if err = s.saveMetadata(); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// writePair writes a key/value pair of the JSON object, preceded by a comma
+// unless it is the first one.
+func (p *pendingSnapshot) writePair(key, value string, comma bool) error {
+	keyBz, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+	valueBz, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if comma {
+		if err = p.writer.WriteByte(','); err != nil {
+			return err
+		}
+	}
+	if _, err = p.writer.Write(keyBz); err != nil {
+		return err
+	}
+	if err = p.writer.WriteByte(':'); err != nil {
+		return err
+	}
+	_, err = p.writer.Write(valueBz)
+	return err
+}
+
+// abortPending discards the snapshot being created after the given error.
+// Does not take out locks, since it's called internally from Continue().
+func (s *SnapshotStore) abortPending(err error) error {
+	_ = s.pending.file.Close()
+	_ = os.Remove(s.pending.file.Name())
+	s.pending = nil
+	return fmt.Errorf("failed to create snapshot: %w", err)
+}
+
 // List lists available snapshots.
 func (s *SnapshotStore) List() ([]*abci.Snapshot, error) {
 	s.RLock()
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+func TestSnapshotStore_Incremental(t *testing.T) {
+	dir := t.TempDir()
+
+	state, err := NewState(filepath.Join(dir, "state.json"), 0)
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		state.Set(fmt.Sprintf("key%v", i), fmt.Sprintf("value%v", i))
+	}
+	height, hash, err := state.Commit()
+	require.NoError(t, err)
+	export, err := state.Export()
+	require.NoError(t, err)
+
+	snapshots, err := NewSnapshotStore(filepath.Join(dir, "snapshots"))
+	require.NoError(t, err)
+	require.NoError(t, snapshots.Begin(state))
+	require.Error(t, snapshots.Begin(state), "only one snapshot can be created at a time")
+
+	// Further blocks modify, delete and add keys while the snapshot is
+	// created, 3 key/value pairs per block.
+	var snapshot *abci.Snapshot
+	blocks := 0
+	for snapshot == nil {
+		blocks++
+		state.Set("key0", fmt.Sprintf("changed%v", blocks))
+		state.Set(fmt.Sprintf("key%v", blocks), "")
+		state.Set(fmt.Sprintf("new%v", blocks), "value")
+		_, _, err = state.Commit()
+		require.NoError(t, err)
+
+		snapshot, err = snapshots.Continue(3)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 4, blocks)
+	require.NotEqual(t, hash, state.Hash)
+
+	// The snapshot is that of the height it was started at.
+	require.Equal(t, height, snapshot.Height)
+	require.Equal(t, hash, snapshot.Hash)
+	listed, err := snapshots.List()
+	require.NoError(t, err)
+	require.Equal(t, []*abci.Snapshot{snapshot}, listed)
+
+	var bz []byte
+	for i := uint32(0); i < snapshot.Chunks; i++ {
+		chunk, err := snapshots.LoadChunk(snapshot.Height, snapshot.Format, i)
+		require.NoError(t, err)
+		bz = append(bz, chunk...)
+	}
+	require.Equal(t, export, bz)
+
+	restored, err := NewState(filepath.Join(dir, "restored.json"), 0)
+	require.NoError(t, err)
+	require.NoError(t, restored.Import(snapshot.Height, bz))
+	require.Equal(t, hash, restored.Hash)
+
+	// Nothing is left to create, and another snapshot can be started.
+	snapshot, err = snapshots.Continue(3)
+	require.NoError(t, err)
+	require.Nil(t, snapshot)
+	require.NoError(t, snapshots.Begin(state))
+}
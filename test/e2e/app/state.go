@@ -23,6 +23,9 @@ type State struct {
 	file            string
 	persistInterval uint64
 	initialHeight   uint64
+	// shared is set once Values is returned by View(), in which case it is
+	// copied before it is next modified.
+	shared bool
 }
 
 // NewState creates a new state.
@@ -93,9 +96,21 @@ func (s *State) Import(height uint64, jsonBytes []byte) error {
 	s.Height = height
 	s.Values = values
 	s.Hash = hashItems(values)
+	s.shared = false
 	return s.save()
 }
 
+// View returns the height and key/value pairs of the last committed state as
+// a copy-on-write view, which remains unchanged as the state is modified
+// afterwards. The returned map must not be modified. It is only consistent
+// with the height if called between Commit and the next Set.
+func (s *State) View() (uint64, map[string]string) {
+	s.Lock()
+	defer s.Unlock()
+	s.shared = true
+	return s.Height, s.Values
+}
+
 // Get fetches a value. A missing value is returned as an empty string.
 func (s *State) Get(key string) string {
 	s.RLock()
@@ -107,6 +122,14 @@ func (s *State) Get(key string) string {
 func (s *State) Set(key, value string) {
 	s.Lock()
 	defer s.Unlock()
+	if s.shared {
+		values := make(map[string]string, len(s.Values))
+		for k, v := range s.Values {
+			values[k] = v
+		}
+		s.Values = values
+		s.shared = false
+	}
 	if value == "" {
 		delete(s.Values, key)
 	} else {
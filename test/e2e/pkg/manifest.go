@@ -122,6 +122,11 @@ type ManifestNode struct {
 	// will take state sync snapshots. Defaults to 0 (disabled).
 	SnapshotInterval uint64 `toml:"snapshot_interval"`
 
+	// SnapshotKeysPerBlock makes the application create each snapshot across
+	// several blocks, writing this many key/value pairs per block. Defaults to
+	// 0, which creates each snapshot while committing its height.
+	SnapshotKeysPerBlock uint64 `toml:"snapshot_keys_per_block"`
+
 	// RetainBlocks specifies the number of recent blocks to retain. Defaults to
 	// 0, which retains all blocks. Must be greater that PersistInterval,
 	// SnapshotInterval and EvidenceAgeHeight.
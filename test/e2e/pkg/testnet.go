@@ -70,28 +70,29 @@ type Testnet struct {
 
 // Node represents a Tendermint node in a testnet.
 type Node struct {
-	Name             string
-	Testnet          *Testnet
-	Mode             Mode
-	PrivvalKey       crypto.PrivKey
-	NodeKey          crypto.PrivKey
-	IP               net.IP
-	ProxyPort        uint32
-	StartAt          int64
-	FastSync         string
-	StateSync        bool
-	Database         string
-	ABCIProtocol     Protocol
-	PrivvalProtocol  Protocol
-	PersistInterval  uint64
-	SnapshotInterval uint64
-	RetainBlocks     uint64
-	Seeds            []*Node
-	PersistentPeers  []*Node
-	Perturbations    []Perturbation
-	LogLevel         string
-	DisableLegacyP2P bool
-	QueueType        string
+	Name                 string
+	Testnet              *Testnet
+	Mode                 Mode
+	PrivvalKey           crypto.PrivKey
+	NodeKey              crypto.PrivKey
+	IP                   net.IP
+	ProxyPort            uint32
+	StartAt              int64
+	FastSync             string
+	StateSync            bool
+	Database             string
+	ABCIProtocol         Protocol
+	PrivvalProtocol      Protocol
+	PersistInterval      uint64
+	SnapshotInterval     uint64
+	SnapshotKeysPerBlock uint64
+	RetainBlocks         uint64
+	Seeds                []*Node
+	PersistentPeers      []*Node
+	Perturbations        []Perturbation
+	LogLevel             string
+	DisableLegacyP2P     bool
+	QueueType            string
 }
 
 // LoadTestnet loads a testnet from a manifest file, using the filename to
@@ -151,26 +152,27 @@ func LoadTestnet(file string) (*Testnet, error) {
 	for _, name := range nodeNames {
 		nodeManifest := manifest.Nodes[name]
 		node := &Node{
-			Name:             name,
-			Testnet:          testnet,
-			PrivvalKey:       keyGen.Generate(manifest.KeyType),
-			NodeKey:          keyGen.Generate("ed25519"),
-			IP:               ipGen.Next(),
-			ProxyPort:        proxyPortGen.Next(),
-			Mode:             ModeValidator,
-			Database:         "goleveldb",
-			ABCIProtocol:     ProtocolBuiltin,
-			PrivvalProtocol:  ProtocolFile,
-			StartAt:          nodeManifest.StartAt,
-			FastSync:         nodeManifest.FastSync,
-			StateSync:        nodeManifest.StateSync,
-			PersistInterval:  1,
-			SnapshotInterval: nodeManifest.SnapshotInterval,
-			RetainBlocks:     nodeManifest.RetainBlocks,
-			Perturbations:    []Perturbation{},
-			LogLevel:         manifest.LogLevel,
-			DisableLegacyP2P: manifest.DisableLegacyP2P,
-			QueueType:        manifest.QueueType,
+			Name:                 name,
+			Testnet:              testnet,
+			PrivvalKey:           keyGen.Generate(manifest.KeyType),
+			NodeKey:              keyGen.Generate("ed25519"),
+			IP:                   ipGen.Next(),
+			ProxyPort:            proxyPortGen.Next(),
+			Mode:                 ModeValidator,
+			Database:             "goleveldb",
+			ABCIProtocol:         ProtocolBuiltin,
+			PrivvalProtocol:      ProtocolFile,
+			StartAt:              nodeManifest.StartAt,
+			FastSync:             nodeManifest.FastSync,
+			StateSync:            nodeManifest.StateSync,
+			PersistInterval:      1,
+			SnapshotInterval:     nodeManifest.SnapshotInterval,
+			SnapshotKeysPerBlock: nodeManifest.SnapshotKeysPerBlock,
+			RetainBlocks:         nodeManifest.RetainBlocks,
+			Perturbations:        []Perturbation{},
+			LogLevel:             manifest.LogLevel,
+			DisableLegacyP2P:     manifest.DisableLegacyP2P,
+			QueueType:            manifest.QueueType,
 		}
 
 		if node.StartAt == testnet.InitialHeight {
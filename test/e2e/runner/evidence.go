@@ -48,7 +48,7 @@ func InjectEvidence(testnet *e2e.Testnet, amount int) error {
 	duplicateVoteHeight := waitHeight
 
 	nValidators := 100
-	valRes, err := client.Validators(context.Background(), &lightEvidenceCommonHeight, nil, &nValidators)
+	valRes, err := client.Validators(context.Background(), &lightEvidenceCommonHeight, nil, &nValidators, false)
 	if err != nil {
 		return err
 	}
@@ -38,7 +38,7 @@ func TestValidator_Sets(t *testing.T) {
 			validators := []*types.Validator{}
 			perPage := 100
 			for page := 1; ; page++ {
-				resp, err := client.Validators(ctx, &(h), &(page), &perPage)
+				resp, err := client.Validators(ctx, &(h), &(page), &perPage, false)
 				require.NoError(t, err)
 				validators = append(validators, resp.Validators...)
 				if len(validators) == resp.Total {
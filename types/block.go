@@ -449,6 +449,43 @@ func (h *Header) Hash() tmbytes.HexBytes {
 	if h == nil || len(h.ValidatorsHash) == 0 {
 		return nil
 	}
+	fields := h.hashFields()
+	if fields == nil {
+		return nil
+	}
+	return merkle.HashFromByteSlices(fields)
+}
+
+// ValidatorSetProof returns a Merkle proof that the ValidatorsHash is part of
+// the header, i.e. of the header hash. It returns an error if the header
+// can't be hashed.
+func (h *Header) ValidatorSetProof() (ValidatorSetProof, error) {
+	if h == nil || len(h.ValidatorsHash) == 0 {
+		return ValidatorSetProof{}, errors.New("header has no validators hash")
+	}
+	fields := h.hashFields()
+	if fields == nil {
+		return ValidatorSetProof{}, errors.New("failed to encode header fields")
+	}
+	root, proofs := merkle.ProofsFromByteSlices(fields)
+	return ValidatorSetProof{
+		RootHash:       root,
+		ValidatorsHash: h.ValidatorsHash,
+		Proof:          *proofs[headerValidatorsHashIndex],
+	}, nil
+}
+
+const (
+	// headerValidatorsHashIndex is the index of the ValidatorsHash in hashFields.
+	headerValidatorsHashIndex = 7
+	// headerHashFieldCount is the number of fields returned by hashFields.
+	headerHashFieldCount = 14
+)
+
+// hashFields returns the encoded header fields that the header hash is the
+// Merkle root of, ordered as they appear in the Header, or nil if they can't
+// be encoded.
+func (h *Header) hashFields() [][]byte {
 	hpb := h.Version.ToProto()
 	hbz, err := hpb.Marshal()
 	if err != nil {
@@ -465,7 +502,7 @@ func (h *Header) Hash() tmbytes.HexBytes {
 	if err != nil {
 		return nil
 	}
-	return merkle.HashFromByteSlices([][]byte{
+	return [][]byte{
 		hbz,
 		cdcEncode(h.ChainID),
 		cdcEncode(h.Height),
@@ -480,7 +517,7 @@ func (h *Header) Hash() tmbytes.HexBytes {
 		cdcEncode(h.LastResultsHash),
 		cdcEncode(h.EvidenceHash),
 		cdcEncode(h.ProposerAddress),
-	})
+	}
 }
 
 // StringIndented returns an indented string representation of the header.
@@ -403,6 +403,32 @@ func TestHeaderHash(t *testing.T) {
 	}
 }
 
+func TestHeaderValidatorSetProof(t *testing.T) {
+	vals, _ := randValidatorPrivValSet(4, 10)
+	header := makeHeaderRandom()
+	header.ValidatorsHash = vals.Hash()
+	header.NextValidatorsHash = vals.Hash()
+
+	proof, err := header.ValidatorSetProof()
+	require.NoError(t, err)
+	require.NoError(t, proof.Validate(header.Hash(), vals))
+
+	// A proof of the NextValidatorsHash, which has the same leaf, doesn't
+	// prove the validator set of the header.
+	_, proofs := merkle.ProofsFromByteSlices(header.hashFields())
+	next := ValidatorSetProof{RootHash: header.Hash(), ValidatorsHash: vals.Hash(), Proof: *proofs[8]}
+	require.NoError(t, next.Proof.Verify(next.RootHash, next.Leaf()))
+	require.Error(t, next.Validate(header.Hash(), vals))
+
+	// A proof claiming a different number of header fields is rejected.
+	wrongTotal := proof
+	wrongTotal.Proof.Total = int64(vals.Size())
+	require.Error(t, wrongTotal.Validate(header.Hash(), vals))
+
+	_, err = (&Header{}).ValidatorSetProof()
+	require.Error(t, err)
+}
+
 func TestMaxHeaderBytes(t *testing.T) {
 	// Construct a UTF-8 string of MaxChainIDLen length using the supplementary
 	// characters.
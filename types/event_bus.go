@@ -159,6 +159,19 @@ func (b *EventBus) PublishEventNewBlockHeader(data EventDataNewBlockHeader) erro
 	return b.pubsub.PublishWithEvents(ctx, data, events)
 }
 
+func (b *EventBus) PublishEventNewBlockResults(data EventDataNewBlockResults) error {
+	// no explicit deadline for publishing events
+	ctx := context.Background()
+
+	resultEvents := append(data.BeginBlockEvents, data.EndBlockEvents...)
+	events := b.validateAndStringifyEvents(resultEvents, b.Logger.With("height", data.Height))
+
+	// add predefined new block results event
+	events[EventTypeKey] = append(events[EventTypeKey], EventNewBlockResults)
+
+	return b.pubsub.PublishWithEvents(ctx, data, events)
+}
+
 func (b *EventBus) PublishEventNewEvidence(evidence EventDataNewEvidence) error {
 	return b.Publish(EventNewEvidence, evidence)
 }
@@ -256,6 +269,10 @@ func (NopEventBus) PublishEventNewBlockHeader(data EventDataNewBlockHeader) erro
 	return nil
 }
 
+func (NopEventBus) PublishEventNewBlockResults(data EventDataNewBlockResults) error {
+	return nil
+}
+
 func (NopEventBus) PublishEventNewEvidence(evidence EventDataNewEvidence) error {
 	return nil
 }
@@ -64,6 +64,52 @@ func TestEventBusPublishEventTx(t *testing.T) {
 	}
 }
 
+func TestEventBusSubscribeTxPredicate(t *testing.T) {
+	eventBus := NewEventBus()
+	err := eventBus.Start()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err := eventBus.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	query := EventQueryTxPredicate(func(edt EventDataTx) bool {
+		return edt.Result.Code == abci.CodeTypeOK
+	})
+	txsSub, err := eventBus.Subscribe(context.Background(), "test", query, 10)
+	require.NoError(t, err)
+
+	for i, code := range []uint32{abci.CodeTypeOK, 1, abci.CodeTypeOK, 2} {
+		err = eventBus.PublishEventTx(EventDataTx{abci.TxResult{
+			Height: 1,
+			Index:  uint32(i),
+			Tx:     Tx(fmt.Sprintf("tx%d", i)),
+			Result: abci.ResponseDeliverTx{Code: code},
+		}})
+		require.NoError(t, err)
+	}
+	err = eventBus.PublishEventNewBlockHeader(EventDataNewBlockHeader{})
+	require.NoError(t, err)
+
+	for _, index := range []uint32{0, 2} {
+		select {
+		case msg := <-txsSub.Out():
+			edt := msg.Data().(EventDataTx)
+			assert.Equal(t, index, edt.Index)
+			assert.Equal(t, abci.CodeTypeOK, edt.Result.Code)
+		case <-time.After(1 * time.Second):
+			t.Fatal("did not receive a transaction after 1 sec.")
+		}
+	}
+
+	select {
+	case msg := <-txsSub.Out():
+		t.Fatalf("received unexpected event %v", msg.Data())
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
 func TestEventBusPublishEventNewBlock(t *testing.T) {
 	eventBus := NewEventBus()
 	err := eventBus.Start()
@@ -277,6 +323,50 @@ func TestEventBusPublishEventNewBlockHeader(t *testing.T) {
 	}
 }
 
+func TestEventBusPublishEventNewBlockResults(t *testing.T) {
+	eventBus := NewEventBus()
+	err := eventBus.Start()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err := eventBus.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	results := EventDataNewBlockResults{
+		Height:     1,
+		TxsResults: []*abci.ResponseDeliverTx{{Data: []byte("foo")}, {Data: []byte("bar")}},
+		BeginBlockEvents: []abci.Event{
+			{Type: "testType", Attributes: []abci.EventAttribute{{Key: "baz", Value: "1"}}},
+		},
+		EndBlockEvents: []abci.Event{
+			{Type: "testType", Attributes: []abci.EventAttribute{{Key: "foz", Value: "2"}}},
+		},
+	}
+
+	// PublishEventNewBlockResults adds the tm.event compositeKey, so the query below should work
+	query := "tm.event='NewBlockResults' AND testType.baz=1 AND testType.foz=2"
+	resultsSub, err := eventBus.Subscribe(context.Background(), "test", tmquery.MustParse(query))
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		msg := <-resultsSub.Out()
+		edt := msg.Data().(EventDataNewBlockResults)
+		assert.Equal(t, results, edt)
+		close(done)
+	}()
+
+	err = eventBus.PublishEventNewBlockResults(results)
+	assert.NoError(t, err)
+
+	select {
+	case <-done:
+	case <-time.After(1 * time.Second):
+		t.Fatal("did not receive block results after 1 sec.")
+	}
+}
+
 func TestEventBusPublishEventNewEvidence(t *testing.T) {
 	eventBus := NewEventBus()
 	err := eventBus.Start()
@@ -0,0 +1,128 @@
+package types
+
+import (
+	"bytes"
+	"container/list"
+
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/tmhash"
+	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
+)
+
+// SignatureCache is a thread-safe LRU cache of signatures that have already
+// been verified. It is used to avoid verifying the same vote signature over
+// and over as it is gossiped between peers.
+//
+// Entries are keyed by the raw signature bytes, but a cache hit additionally
+// requires the public key and the signed message to match the ones that the
+// signature was verified against. Thus, a valid signature replayed with a
+// different message or by a different key is always verified again.
+//
+// A nil *SignatureCache is valid and simply verifies every signature.
+type SignatureCache struct {
+	mtx      tmsync.Mutex
+	size     int
+	cacheMap map[string]*list.Element
+	list     *list.List
+}
+
+type signatureCacheEntry struct {
+	signature string
+	// hash of the public key and the signed message
+	digest []byte
+}
+
+// NewSignatureCache returns a SignatureCache holding up to size signatures. A
+// nil cache is returned if size is not positive, which disables caching.
+func NewSignatureCache(size int) *SignatureCache {
+	if size <= 0 {
+		return nil
+	}
+
+	return &SignatureCache{
+		size:     size,
+		cacheMap: make(map[string]*list.Element, size),
+		list:     list.New(),
+	}
+}
+
+// Verify returns true if sig is a valid signature of msg by pubKey. Signatures
+// found in the cache for the same public key and message are not verified
+// again. Only successfully verified signatures are added to the cache.
+func (c *SignatureCache) Verify(pubKey crypto.PubKey, msg, sig []byte) bool {
+	if c == nil {
+		return pubKey.VerifySignature(msg, sig)
+	}
+
+	digest := signatureDigest(pubKey, msg)
+	if c.has(sig, digest) {
+		return true
+	}
+
+	if !pubKey.VerifySignature(msg, sig) {
+		return false
+	}
+
+	c.push(sig, digest)
+	return true
+}
+
+// Size returns the number of signatures currently held by the cache.
+func (c *SignatureCache) Size() int {
+	if c == nil {
+		return 0
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	return c.list.Len()
+}
+
+func (c *SignatureCache) has(sig, digest []byte) bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	e, ok := c.cacheMap[string(sig)]
+	if !ok {
+		return false
+	}
+
+	if !bytes.Equal(e.Value.(signatureCacheEntry).digest, digest) {
+		return false
+	}
+
+	c.list.MoveToBack(e)
+	return true
+}
+
+func (c *SignatureCache) push(sig, digest []byte) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	key := string(sig)
+	if e, ok := c.cacheMap[key]; ok {
+		e.Value = signatureCacheEntry{signature: key, digest: digest}
+		c.list.MoveToBack(e)
+		return
+	}
+
+	if c.list.Len() >= c.size {
+		if front := c.list.Front(); front != nil {
+			delete(c.cacheMap, front.Value.(signatureCacheEntry).signature)
+			c.list.Remove(front)
+		}
+	}
+
+	c.cacheMap[key] = c.list.PushBack(signatureCacheEntry{signature: key, digest: digest})
+}
+
+func signatureDigest(pubKey crypto.PubKey, msg []byte) []byte {
+	hasher := tmhash.New()
+	// key types never contain a NUL byte and the key length is fixed by its type
+	hasher.Write([]byte(pubKey.Type()))
+	hasher.Write([]byte{0})
+	hasher.Write(pubKey.Bytes())
+	hasher.Write(msg)
+	return hasher.Sum(nil)
+}
@@ -0,0 +1,123 @@
+package types
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	tmtime "github.com/tendermint/tendermint/libs/time"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+)
+
+// countingPubKey counts how many signatures were verified with the key.
+type countingPubKey struct {
+	crypto.PubKey
+	verified int
+}
+
+func (pk *countingPubKey) VerifySignature(msg, sig []byte) bool {
+	pk.verified++
+	return pk.PubKey.VerifySignature(msg, sig)
+}
+
+func TestSignatureCache(t *testing.T) {
+	privKey := ed25519.GenPrivKey()
+	pubKey := &countingPubKey{PubKey: privKey.PubKey()}
+
+	msg := []byte("message")
+	sig, err := privKey.Sign(msg)
+	require.NoError(t, err)
+
+	cache := NewSignatureCache(2)
+
+	// the first time around the signature is verified and cached
+	require.True(t, cache.Verify(pubKey, msg, sig))
+	require.Equal(t, 1, pubKey.verified)
+	require.Equal(t, 1, cache.Size())
+
+	// the second time it is served from the cache
+	require.True(t, cache.Verify(pubKey, msg, sig))
+	require.Equal(t, 1, pubKey.verified)
+
+	// the same signature for another message or key must be verified again
+	require.False(t, cache.Verify(pubKey, []byte("other message"), sig))
+	require.Equal(t, 2, pubKey.verified)
+	require.False(t, cache.Verify(ed25519.GenPrivKey().PubKey(), msg, sig))
+
+	// a tampered signature is never cached
+	badSig := append([]byte{}, sig...)
+	badSig[0] ^= 0xff
+	require.False(t, cache.Verify(pubKey, msg, badSig))
+	require.False(t, cache.Verify(pubKey, msg, badSig))
+	require.Equal(t, 4, pubKey.verified)
+	require.Equal(t, 1, cache.Size())
+
+	// the least recently used signature is evicted once the cache is full
+	for _, m := range [][]byte{[]byte("a"), []byte("b")} {
+		s, err := privKey.Sign(m)
+		require.NoError(t, err)
+		require.True(t, cache.Verify(pubKey, m, s))
+	}
+	require.Equal(t, 2, cache.Size())
+	require.True(t, cache.Verify(pubKey, msg, sig))
+	require.Equal(t, 7, pubKey.verified)
+
+	// a nil cache always verifies
+	var nilCache *SignatureCache
+	require.Nil(t, NewSignatureCache(0))
+	require.True(t, nilCache.Verify(pubKey, msg, sig))
+	require.True(t, nilCache.Verify(pubKey, msg, sig))
+	require.Equal(t, 9, pubKey.verified)
+	require.Equal(t, 0, nilCache.Size())
+}
+
+func TestVoteSet_SignatureCache(t *testing.T) {
+	const (
+		chainID       = "test_chain_id"
+		height  int64 = 1
+		round   int32 = 0
+	)
+
+	privKey := ed25519.GenPrivKey()
+	pubKey := &countingPubKey{PubKey: privKey.PubKey()}
+	privVal := NewMockPVWithParams(privKey, false, false)
+	valSet := NewValidatorSet([]*Validator{NewValidator(pubKey, 10)})
+	cache := NewSignatureCache(10)
+
+	vote := &Vote{
+		ValidatorAddress: pubKey.Address(),
+		ValidatorIndex:   0,
+		Height:           height,
+		Round:            round,
+		Type:             tmproto.PrevoteType,
+		Timestamp:        tmtime.Now(),
+		BlockID:          BlockID{Hash: []byte("blockhash1234567890blockhash1234")},
+	}
+	v := vote.ToProto()
+	require.NoError(t, privVal.SignVote(context.Background(), chainID, v))
+	vote.Signature = v.Signature
+
+	voteSet := NewVoteSetWithCache(chainID, height, round, tmproto.PrevoteType, valSet, cache)
+	added, err := voteSet.AddVote(vote)
+	require.NoError(t, err)
+	require.True(t, added)
+	require.Equal(t, 1, pubKey.verified)
+
+	// receiving the identical vote in another vote set which shares the cache
+	// does not verify the signature again
+	otherVoteSet := NewVoteSetWithCache(chainID, height, round, tmproto.PrevoteType, valSet, cache)
+	added, err = otherVoteSet.AddVote(vote.Copy())
+	require.NoError(t, err)
+	require.True(t, added)
+	require.Equal(t, 1, pubKey.verified)
+
+	// a tampered vote which reuses the cached signature is still rejected
+	tampered := vote.Copy()
+	tampered.Timestamp = tampered.Timestamp.Add(1)
+	_, err = NewVoteSetWithCache(chainID, height, round, tmproto.PrevoteType, valSet, cache).AddVote(tampered)
+	require.ErrorIs(t, err, ErrVoteInvalidSignature)
+	require.Equal(t, 2, pubKey.verified)
+}
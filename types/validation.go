@@ -1,6 +1,7 @@
 package types
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 
@@ -49,6 +50,40 @@ func VerifyCommit(chainID string, vals *ValidatorSet, blockID BlockID,
 		cacheSignBytes, ignore, count, true, true)
 }
 
+// VerifyCommitStructure checks that the given commit is one for blockID at
+// height from the validator set, with the signatures of +2/3 of it for the
+// block, without verifying the signatures themselves.
+//
+// It must only be used for commits that were fully verified before, such as
+// those of the blocks in the local block store.
+func VerifyCommitStructure(vals *ValidatorSet, blockID BlockID, height int64, commit *Commit) error {
+	// run a basic validation of the arguments
+	if err := verifyBasicValsAndCommit(vals, commit, height, blockID); err != nil {
+		return err
+	}
+
+	var talliedVotingPower int64
+	for idx, commitSig := range commit.Signatures {
+		if commitSig.Absent() {
+			continue
+		}
+
+		val := vals.Validators[idx]
+		if !bytes.Equal(val.Address, commitSig.ValidatorAddress) {
+			return fmt.Errorf("wrong validator address (#%d): expected %v, got %v",
+				idx, val.Address, commitSig.ValidatorAddress)
+		}
+		if commitSig.ForBlock() {
+			talliedVotingPower += val.VotingPower
+		}
+	}
+
+	if got, needed := talliedVotingPower, vals.TotalVotingPower()*2/3; got <= needed {
+		return ErrNotEnoughVotingPowerSigned{Got: got, Needed: needed}
+	}
+	return nil
+}
+
 // LIGHT CLIENT VERIFICATION METHODS
 
 // VerifyCommitLight verifies +2/3 of the set had signed the given commit.
@@ -157,6 +157,39 @@ func TestValidatorSet_VerifyCommit_CheckAllSignatures(t *testing.T) {
 	}
 }
 
+func TestValidatorSet_VerifyCommitStructure(t *testing.T) {
+	var (
+		h       = int64(3)
+		blockID = makeBlockIDRandom()
+	)
+
+	voteSet, valSet, vals := randVoteSet(h, 0, tmproto.PrecommitType, 4, 10)
+	commit, err := makeCommit(blockID, h, 0, voteSet, vals, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, valSet.VerifyCommitStructure(blockID, h, commit))
+
+	// signatures aren't verified
+	commit.Signatures[3].Signature = []byte("invalid")
+	require.NoError(t, valSet.VerifyCommitStructure(blockID, h, commit))
+
+	// but the commit must be for the block, at the height, by the set
+	require.Error(t, valSet.VerifyCommitStructure(makeBlockIDRandom(), h, commit))
+	require.Error(t, valSet.VerifyCommitStructure(blockID, h+1, commit))
+	sig := commit.Signatures[3]
+	commit.Signatures[3].ValidatorAddress = commit.Signatures[0].ValidatorAddress
+	err = valSet.VerifyCommitStructure(blockID, h, commit)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "wrong validator address (#3)")
+	}
+
+	// with +2/3 of the voting power for the block
+	commit.Signatures[3] = sig
+	commit.Signatures[2] = NewCommitSigAbsent()
+	require.NoError(t, valSet.VerifyCommitStructure(blockID, h, commit))
+	commit.Signatures[1] = NewCommitSigAbsent()
+	require.IsType(t, ErrNotEnoughVotingPowerSigned{}, valSet.VerifyCommitStructure(blockID, h, commit))
+}
+
 func TestValidatorSet_VerifyCommitLight_ReturnsAsSoonAsMajorityOfVotingPowerSigned(t *testing.T) {
 	var (
 		chainID = "test_chain_id"
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/tendermint/tendermint/crypto/merkle"
+	tmbytes "github.com/tendermint/tendermint/libs/bytes"
 	tmmath "github.com/tendermint/tendermint/libs/math"
 	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
 )
@@ -659,6 +660,12 @@ func (vals *ValidatorSet) VerifyCommit(chainID string, blockID BlockID,
 	return VerifyCommit(chainID, vals, blockID, height, commit)
 }
 
+// VerifyCommitStructure verifies the given commit like VerifyCommit, except
+// for the signatures. See VerifyCommitStructure.
+func (vals *ValidatorSet) VerifyCommitStructure(blockID BlockID, height int64, commit *Commit) error {
+	return VerifyCommitStructure(vals, blockID, height, commit)
+}
+
 // LIGHT CLIENT VERIFICATION METHODS
 
 // VerifyCommitLight verifies +2/3 of the set had signed the given commit.
@@ -868,6 +875,45 @@ func ValidatorSetFromExistingValidators(valz []*Validator) (*ValidatorSet, error
 
 //----------------------------------------
 
+// ValidatorSetProof is a Merkle proof that a validator set hash is the
+// ValidatorsHash of the header with the RootHash, i.e. that the validator set
+// is the one committed to for the height of the header.
+type ValidatorSetProof struct {
+	RootHash       tmbytes.HexBytes `json:"root_hash"`
+	ValidatorsHash tmbytes.HexBytes `json:"validators_hash"`
+	Proof          merkle.Proof     `json:"proof"`
+}
+
+// Leaf returns the encoded validators hash, which is the leaf in the merkle
+// tree of the header fields which this proof refers to.
+func (vp ValidatorSetProof) Leaf() []byte {
+	return cdcEncode(vp.ValidatorsHash)
+}
+
+// Validate verifies the proof. It returns nil if the RootHash matches the
+// headerHash argument, the ValidatorsHash matches the hash of vals, and the
+// proof is internally consistent. Otherwise, it returns a sensible error.
+func (vp ValidatorSetProof) Validate(headerHash []byte, vals *ValidatorSet) error {
+	if !bytes.Equal(headerHash, vp.RootHash) {
+		return errors.New("proof matches different header hash")
+	}
+	if !bytes.Equal(vals.Hash(), vp.ValidatorsHash) {
+		return errors.New("proof matches different validator set hash")
+	}
+	if vp.Proof.Index != headerValidatorsHashIndex {
+		return fmt.Errorf("proof index must be %d, got %d", headerValidatorsHashIndex, vp.Proof.Index)
+	}
+	if vp.Proof.Total != headerHashFieldCount {
+		return fmt.Errorf("proof total must be %d header fields, got %d", headerHashFieldCount, vp.Proof.Total)
+	}
+	if err := vp.Proof.Verify(vp.RootHash, vp.Leaf()); err != nil {
+		return errors.New("proof is not internally consistent")
+	}
+	return nil
+}
+
+//----------------------------------------
+
 // safe addition/subtraction/multiplication
 
 func safeAdd(a, b int64) (int64, bool) {
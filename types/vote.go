@@ -145,11 +145,18 @@ func (vote *Vote) String() string {
 }
 
 func (vote *Vote) Verify(chainID string, pubKey crypto.PubKey) error {
+	return vote.VerifyWithCache(chainID, pubKey, nil)
+}
+
+// VerifyWithCache is the same as Verify, but skips verifying the signature if
+// the cache already holds it for the same public key and sign bytes. Valid
+// signatures are added to the cache. The cache may be nil.
+func (vote *Vote) VerifyWithCache(chainID string, pubKey crypto.PubKey, cache *SignatureCache) error {
 	if !bytes.Equal(pubKey.Address(), vote.ValidatorAddress) {
 		return ErrVoteInvalidValidatorAddress
 	}
 	v := vote.ToProto()
-	if !pubKey.VerifySignature(VoteSignBytes(chainID, v), vote.Signature) {
+	if !cache.Verify(pubKey, VoteSignBytes(chainID, v), vote.Signature) {
 		return ErrVoteInvalidSignature
 	}
 	return nil
@@ -64,6 +64,7 @@ type VoteSet struct {
 	round         int32
 	signedMsgType tmproto.SignedMsgType
 	valSet        *ValidatorSet
+	sigCache      *SignatureCache
 
 	mtx           tmsync.Mutex
 	votesBitArray *bits.BitArray
@@ -77,6 +78,14 @@ type VoteSet struct {
 // Constructs a new VoteSet struct used to accumulate votes for given height/round.
 func NewVoteSet(chainID string, height int64, round int32,
 	signedMsgType tmproto.SignedMsgType, valSet *ValidatorSet) *VoteSet {
+	return NewVoteSetWithCache(chainID, height, round, signedMsgType, valSet, nil)
+}
+
+// NewVoteSetWithCache constructs a new VoteSet which skips verifying vote
+// signatures that are already held by sigCache. The cache is expected to be
+// shared with other vote sets and may be nil.
+func NewVoteSetWithCache(chainID string, height int64, round int32,
+	signedMsgType tmproto.SignedMsgType, valSet *ValidatorSet, sigCache *SignatureCache) *VoteSet {
 	if height == 0 {
 		panic("Cannot make VoteSet for height == 0, doesn't make sense.")
 	}
@@ -86,6 +95,7 @@ func NewVoteSet(chainID string, height int64, round int32,
 		round:         round,
 		signedMsgType: signedMsgType,
 		valSet:        valSet,
+		sigCache:      sigCache,
 		votesBitArray: bits.NewBitArray(valSet.Size()),
 		votes:         make([]*Vote, valSet.Size()),
 		sum:           0,
@@ -200,7 +210,7 @@ func (voteSet *VoteSet) addVote(vote *Vote) (added bool, err error) {
 	}
 
 	// Check signature.
-	if err := vote.Verify(voteSet.chainID, val.PubKey); err != nil {
+	if err := vote.VerifyWithCache(voteSet.chainID, val.PubKey, voteSet.sigCache); err != nil {
 		return false, fmt.Errorf("failed to verify vote with ChainID %s and PubKey %s: %w", voteSet.chainID, val.PubKey, err)
 	}
 